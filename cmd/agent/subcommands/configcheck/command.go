@@ -10,10 +10,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
 	"github.com/DataDog/datadog-agent/comp/core"
@@ -31,8 +34,22 @@ type cliParams struct {
 	*command.GlobalParams
 
 	verbose bool
+	output  string
 }
 
+// diffCliParams are the command-line arguments for the diff subcommand
+type diffCliParams struct {
+	output      string
+	oldSnapshot string
+	newSnapshot string
+}
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+	yamlOutput = "yaml"
+)
+
 // Commands returns a slice of subcommands for the 'agent' command.
 func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	cliParams := &cliParams{
@@ -56,11 +73,37 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		},
 	}
 	configCheckCommand.Flags().BoolVarP(&cliParams.verbose, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().StringVarP(&cliParams.output, "output", "o", textOutput, "output format: text, or json and yaml for a snapshot of the resolved configurations")
+
+	diffCliParams := &diffCliParams{}
+	diffCommand := &cobra.Command{
+		Use:   "diff <old-snapshot> <new-snapshot>",
+		Short: "Compare two snapshots taken with 'configcheck --output json|yaml'",
+		Long:  ``,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			diffCliParams.oldSnapshot = args[0]
+			diffCliParams.newSnapshot = args[1]
+			return fxutil.OneShot(runDiff,
+				fx.Supply(diffCliParams),
+			)
+		},
+	}
+	diffCommand.Flags().StringVarP(&diffCliParams.output, "output", "o", textOutput, "output format: text, json or yaml")
+	configCheckCommand.AddCommand(diffCommand)
 
 	return []*cobra.Command{configCheckCommand}
 }
 
 func run(config config.Component, cliParams *cliParams, _ log.Component) error {
+	switch cliParams.output {
+	case textOutput:
+	case jsonOutput, yamlOutput:
+		return runSnapshot(config, cliParams)
+	default:
+		return fmt.Errorf("unknown output format %q, expected one of %s, %s, %s", cliParams.output, textOutput, jsonOutput, yamlOutput)
+	}
+
 	endpoint, err := apiutil.NewIPCEndpoint(config, "/agent/config-check")
 	if err != nil {
 		return err
@@ -84,3 +127,103 @@ func run(config config.Component, cliParams *cliParams, _ log.Component) error {
 	fmt.Println(b.String())
 	return nil
 }
+
+func runSnapshot(config config.Component, cliParams *cliParams) error {
+	endpoint, err := apiutil.NewIPCEndpoint(config, "/agent/config-check/snapshot")
+	if err != nil {
+		return err
+	}
+
+	res, err := endpoint.DoGet()
+	if err != nil {
+		return fmt.Errorf("the agent ran into an error while getting the config snapshot: %v", err)
+	}
+
+	snapshot := integration.ConfigSnapshot{}
+	err = json.Unmarshal(res, &snapshot)
+	if err != nil {
+		return fmt.Errorf("unable to parse config snapshot: %v", err)
+	}
+
+	return printStructured(os.Stdout, cliParams.output, snapshot)
+}
+
+func runDiff(cliParams *diffCliParams) error {
+	oldSnapshot, err := readSnapshot(cliParams.oldSnapshot)
+	if err != nil {
+		return err
+	}
+	newSnapshot, err := readSnapshot(cliParams.newSnapshot)
+	if err != nil {
+		return err
+	}
+
+	diff := integration.DiffConfigSnapshots(oldSnapshot, newSnapshot)
+	switch cliParams.output {
+	case textOutput:
+		printDiff(os.Stdout, diff)
+		return nil
+	case jsonOutput, yamlOutput:
+		return printStructured(os.Stdout, cliParams.output, diff)
+	default:
+		return fmt.Errorf("unknown output format %q, expected one of %s, %s, %s", cliParams.output, textOutput, jsonOutput, yamlOutput)
+	}
+}
+
+// readSnapshot reads a snapshot in JSON or YAML, JSON being valid YAML
+func readSnapshot(path string) (integration.ConfigSnapshot, error) {
+	snapshot := integration.ConfigSnapshot{}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, fmt.Errorf("unable to read config snapshot: %v", err)
+	}
+
+	if err := yaml.Unmarshal(content, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("unable to parse config snapshot %s: %v", path, err)
+	}
+
+	return snapshot, nil
+}
+
+func printStructured(w io.Writer, output string, v interface{}) error {
+	var out []byte
+	var err error
+	if output == yamlOutput {
+		out, err = yaml.Marshal(v)
+	} else {
+		out, err = json.MarshalIndent(v, "", "  ")
+		out = append(out, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+func printDiff(w io.Writer, diff integration.ConfigSnapshotDiff) {
+	if diff.IsEmpty() {
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+
+	describe := func(e integration.ConfigSnapshotEntry) string {
+		desc := fmt.Sprintf("%s (provider: %s, source: %s", e.Name, e.Provider, e.Source)
+		if e.ServiceID != "" {
+			desc += ", service: " + e.ServiceID
+		}
+		return desc + ")"
+	}
+
+	for _, e := range diff.Added {
+		fmt.Fprintf(w, "%s %s, digest %s\n", color.GreenString("+"), describe(e), e.Digest)
+	}
+	for _, e := range diff.Removed {
+		fmt.Fprintf(w, "%s %s, digest %s\n", color.RedString("-"), describe(e), e.Digest)
+	}
+	for _, c := range diff.Changed {
+		fmt.Fprintf(w, "%s %s, digest %s -> %s\n", color.YellowString("~"), describe(c.New), c.Old.Digest, c.New.Digest)
+	}
+}
@@ -24,5 +24,28 @@ func TestCommand(t *testing.T) {
 		func(cliParams *cliParams, _ core.BundleParams, secretParams secrets.Params) {
 			require.Equal(t, true, cliParams.verbose)
 			require.Equal(t, true, secretParams.Enabled)
+			require.Equal(t, textOutput, cliParams.output)
+		})
+}
+
+func TestSnapshotCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"configcheck", "--output", "json"},
+		run,
+		func(cliParams *cliParams, _ core.BundleParams, _ secrets.Params) {
+			require.Equal(t, jsonOutput, cliParams.output)
+		})
+}
+
+func TestDiffCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"configcheck", "diff", "before.json", "after.yaml", "-o", "yaml"},
+		runDiff,
+		func(cliParams *diffCliParams) {
+			require.Equal(t, "before.json", cliParams.oldSnapshot)
+			require.Equal(t, "after.yaml", cliParams.newSnapshot)
+			require.Equal(t, yamlOutput, cliParams.output)
 		})
 }
@@ -26,11 +26,13 @@ import (
 	snmpscan "github.com/DataDog/datadog-agent/comp/snmpscan/def"
 	snmpscanfx "github.com/DataDog/datadog-agent/comp/snmpscan/fx"
 	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmprec"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 	"net"
 	"os"
 	"strconv"
 
+	"github.com/gosnmp/gosnmp"
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
 )
@@ -41,6 +43,11 @@ const (
 	defaultUseUnconnectedUDPSocket = false
 )
 
+// walkParams are the flags of the walk subcommand that aren't connection parameters
+type walkParams struct {
+	output string
+}
+
 // argsType is an alias so we can inject the args via fx.
 type argsType []string
 
@@ -70,6 +77,7 @@ func confErrf(msg string, args ...any) configErr {
 // Commands returns a slice of subcommands for the 'agent' command.
 func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	connParams := &snmpparse.SNMPConfig{}
+	walkCmdParams := &walkParams{}
 	snmpCmd := &cobra.Command{
 		Use:   "snmp",
 		Short: "Snmp tools",
@@ -80,11 +88,12 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		Use:   "walk <IP Address>[:Port] [OID]",
 		Short: "Perform an snmpwalk.",
 		Long: `Walk the SNMP tree for a device, printing every OID found. If OID is specified, only show that OID and its children.
+		Use --output to save the OIDs to a snmprec file that can be served by 'agent snmp simulate'.
 		Flags that aren't specified will be pulled from the agent SNMP config if possible.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			err := fxutil.OneShot(snmpWalk,
-				fx.Supply(connParams),
+				fx.Supply(connParams, walkCmdParams),
 				fx.Provide(func() argsType { return args }),
 				fx.Supply(core.BundleParams{
 					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
@@ -133,6 +142,8 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	snmpWalkCmd.Flags().IntVarP(&connParams.Timeout, "timeout", "t", defaultTimeout, "Set the request timeout (in seconds)")
 	snmpWalkCmd.Flags().BoolVar(&connParams.UseUnconnectedUDPSocket, "use-unconnected-udp-socket", defaultUseUnconnectedUDPSocket, "If specified, changes net connection to be unconnected UDP socket")
 
+	snmpWalkCmd.Flags().StringVarP(&walkCmdParams.output, "output", "o", "", "Write the OIDs to this snmprec file instead of printing them")
+
 	snmpCmd.AddCommand(snmpWalkCmd)
 
 	// This command does nothing until the backend supports it, so it isn't visible yet.
@@ -198,6 +209,100 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	// This command does nothing until the backend supports it, so it isn't enabled yet.
 	snmpCmd.AddCommand(snmpScanCmd)
 
+	genParams := &generateProfileParams{}
+	snmpGenerateProfileCmd := &cobra.Command{
+		Use:   "generate-profile <MIB file>...",
+		Short: "Generate a profile from MIB files.",
+		Long: `Parse SMIv1/SMIv2 MIB files offline and generate a profile collecting their numeric objects.
+		Metric types are inferred from the objects SYNTAX and table metrics are tagged by their INDEX.
+		Use --object to only collect some tables, scalars or subtrees.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(generateProfile,
+				fx.Supply(genParams),
+				fx.Provide(func() argsType { return args }),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	snmpGenerateProfileCmd.Flags().StringVarP(&genParams.output, "output", "o", "", "Write the profile to this file instead of stdout")
+	snmpGenerateProfileCmd.Flags().StringVar(&genParams.name, "name", "", "Set the profile name (defaults to the name of the first MIB module)")
+	snmpGenerateProfileCmd.Flags().StringSliceVar(&genParams.mibDirs, "mib-dir", nil, "Directory of MIB files used to resolve imports")
+	snmpGenerateProfileCmd.Flags().StringSliceVar(&genParams.objects, "object", nil, "Table, scalar or node to collect, e.g. IF-MIB::ifTable (defaults to all objects)")
+	snmpGenerateProfileCmd.Flags().StringSliceVar(&genParams.sysObjectIDs, "sysobjectid", nil, "sysObjectID pattern matched by the profile")
+	snmpGenerateProfileCmd.Flags().StringSliceVar(&genParams.extends, "extends", nil, "Profile extended by the profile, e.g. _base.yaml")
+	snmpGenerateProfileCmd.Flags().StringVar(&genParams.trapsOutput, "traps-output", "", "Write the trap definitions of the MIB files to this file (json or yaml), in the traps OID resolver format")
+
+	snmpCmd.AddCommand(snmpGenerateProfileCmd)
+
+	simParams := &simulateParams{}
+	snmpSimulateCmd := &cobra.Command{
+		Use:   "simulate <snmprec file or directory>...",
+		Short: "Simulate SNMP devices from snmprec files.",
+		Long: `Serve devices recorded in snmprec files, e.g. by 'agent snmp walk --output', over UDP until interrupted.
+		Each file is served for the SNMP v1/v2c community, or SNMPv3 context, named after the file without its .snmprec extension.
+		SNMPv3 requests are served when --user-name is specified, without context name if only one file is served.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(simulate,
+				fx.Supply(simParams),
+				fx.Provide(func() argsType { return args }),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	snmpSimulateCmd.Flags().StringVar(&simParams.listen, "listen", defaultSimulatorAddress, "UDP address to listen on")
+	snmpSimulateCmd.Flags().StringVar(&simParams.engineID, "engine-id", "", "Set the hex encoded SNMPv3 engine ID (defaults to a random one)")
+	snmpSimulateCmd.Flags().StringVarP(&simParams.username, "user-name", "u", "", "Set the SNMPv3 security name")
+	snmpSimulateCmd.Flags().VarP(Flag(&snmpparse.AuthOpts, &simParams.authProtocol), "auth-protocol", "a",
+		fmt.Sprintf("Set the SNMPv3 authentication protocol (%s)", snmpparse.AuthOpts.OptsStr()))
+	snmpSimulateCmd.Flags().StringVarP(&simParams.authKey, "auth-key", "A", "", "Set the SNMPv3 authentication protocol pass phrase")
+	snmpSimulateCmd.Flags().VarP(Flag(&snmpparse.PrivOpts, &simParams.privProtocol), "priv-protocol", "x",
+		fmt.Sprintf("Set the SNMPv3 privacy protocol (%s)", snmpparse.PrivOpts.OptsStr()))
+	snmpSimulateCmd.Flags().StringVarP(&simParams.privKey, "priv-key", "X", "", "Set the SNMPv3 privacy protocol pass phrase")
+
+	snmpCmd.AddCommand(snmpSimulateCmd)
+
+	topoParams := &topologyParams{}
+	snmpTopologyCmd := &cobra.Command{
+		Use:   "topology",
+		Short: "Export the network topology of the monitored devices.",
+		Long: `Print the topology graph built by the running agent from the LLDP and CDP neighbors of the devices it monitors.
+		Links reported by both of their devices are only listed once, neighbors that aren't monitored are part of the graph too.
+		Topology collection must be enabled with collect_topology in the SNMP check configuration.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := fxutil.OneShot(getTopology,
+				fx.Supply(topoParams),
+				fx.Supply(command.GetDefaultCoreBundleParams(globalParams)),
+				core.Bundle(),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	snmpTopologyCmd.Flags().StringVarP(&topoParams.format, "format", "f", topologyFormatJSON, fmt.Sprintf("Output format (%s or %s)", topologyFormatJSON, topologyFormatDOT))
+	snmpTopologyCmd.Flags().StringVarP(&topoParams.output, "output", "o", "", "Write the topology to this file instead of stdout")
+
+	snmpCmd.AddCommand(snmpTopologyCmd)
+
 	return []*cobra.Command{snmpCmd}
 }
 
@@ -307,8 +412,9 @@ func scanDevice(connParams *snmpparse.SNMPConfig, args argsType, snmpScanner snm
 	return nil
 }
 
-// snmpWalk prints every SNMP value, in the style of the unix snmpwalk command.
-func snmpWalk(connParams *snmpparse.SNMPConfig, args argsType, snmpScanner snmpscan.Component, conf config.Component, logger log.Component) error {
+// snmpWalk prints every SNMP value, in the style of the unix snmpwalk command,
+// or writes them to a snmprec file.
+func snmpWalk(connParams *snmpparse.SNMPConfig, params *walkParams, args argsType, snmpScanner snmpscan.Component, conf config.Component, logger log.Component) error {
 	// Parse args
 	if len(args) == 0 {
 		return confErrf("missing argument: IP address")
@@ -340,7 +446,11 @@ func snmpWalk(connParams *snmpparse.SNMPConfig, args argsType, snmpScanner snmps
 	}
 	defer func() { _ = snmp.Conn.Close() }()
 
-	err = snmpScanner.RunSnmpWalk(snmp, oid)
+	if params.output != "" {
+		err = writeSnmprec(snmp, oid, params.output)
+	} else {
+		err = snmpScanner.RunSnmpWalk(snmp, oid)
+	}
 
 	if err != nil {
 		return fmt.Errorf("unable to walk SNMP agent on %s:%d: %w", connParams.IPAddress, connParams.Port, err)
@@ -348,3 +458,25 @@ func snmpWalk(connParams *snmpparse.SNMPConfig, args argsType, snmpScanner snmps
 
 	return nil
 }
+
+// writeSnmprec walks a device and writes every value to a snmprec file
+func writeSnmprec(snmp *gosnmp.GoSNMP, oid string, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := snmprec.NewWriter(f)
+	count := 0
+	err = snmp.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+		count++
+		return writer.Write(pdu)
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d OIDs to %s\n", count, path)
+	return nil
+}
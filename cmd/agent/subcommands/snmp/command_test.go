@@ -6,13 +6,25 @@
 package snmp
 
 import (
-	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmprec"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpsim"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
@@ -37,6 +49,15 @@ func TestWalkCommand(t *testing.T) {
 			require.Equal(t, argsType{"1.2.3.4", "10.9.8.7"}, args)
 			require.True(t, cliParams.UseUnconnectedUDPSocket)
 		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "walk", "1.2.3.4", "-o", "device.snmprec"},
+		snmpWalk,
+		func(params *walkParams, args argsType) {
+			require.Equal(t, argsType{"1.2.3.4"}, args)
+			require.Equal(t, "device.snmprec", params.output)
+		})
 }
 
 func TestScanCommand(t *testing.T) {
@@ -87,3 +108,172 @@ func TestSplitIP(t *testing.T) {
 		assert.Equal(t, tc.hasPort, hasPort)
 	}
 }
+
+func TestGenerateProfileCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "generate-profile", "VENDOR-MIB.my", "--mib-dir", "/usr/share/snmp/mibs", "--object", "vendorPortTable", "--object", "vendorCpu", "--sysobjectid", "1.3.6.1.4.1.99999.*", "-o", "vendor.yaml"},
+		generateProfile,
+		func(params *generateProfileParams, args argsType) {
+			require.Equal(t, argsType{"VENDOR-MIB.my"}, args)
+			require.Equal(t, []string{"/usr/share/snmp/mibs"}, params.mibDirs)
+			require.Equal(t, []string{"vendorPortTable", "vendorCpu"}, params.objects)
+			require.Equal(t, []string{"1.3.6.1.4.1.99999.*"}, params.sysObjectIDs)
+			require.Equal(t, "vendor.yaml", params.output)
+		})
+}
+
+func TestGenerateProfile(t *testing.T) {
+	dir := t.TempDir()
+	params := &generateProfileParams{
+		output:      filepath.Join(dir, "profile.yaml"),
+		trapsOutput: filepath.Join(dir, "traps.json"),
+		mibDirs:     []string{"../../../../pkg/snmp/mibparse/testdata"},
+		objects:     []string{"testV1Objects"},
+	}
+	err := generateProfile(params, argsType{"../../../../pkg/snmp/mibparse/testdata/DATADOG-TEST-V1-MIB.my"})
+	require.NoError(t, err)
+
+	profile, err := os.ReadFile(params.output)
+	require.NoError(t, err)
+	assert.Equal(t, `name: datadog-test-v1-mib
+metrics:
+- MIB: DATADOG-TEST-V1-MIB
+  symbol:
+    OID: 1.3.6.1.4.1.99998.1.1.0
+    name: testV1Errors
+  metric_type: monotonic_count
+- MIB: DATADOG-TEST-V1-MIB
+  symbol:
+    OID: 1.3.6.1.4.1.99998.1.2.0
+    name: testV1Load
+  metric_type: gauge
+- MIB: DATADOG-TEST-V1-MIB
+  symbol:
+    OID: 1.3.6.1.4.1.99998.1.3.0
+    name: testV1State
+  metric_type: gauge
+`, string(profile))
+
+	traps, err := os.ReadFile(params.trapsOutput)
+	require.NoError(t, err)
+	var trapsDB oidresolver.TrapDBFileContent
+	require.NoError(t, json.Unmarshal(traps, &trapsDB))
+	assert.Equal(t, oidresolver.TrapMetadata{Name: "testV1StateChange", MIBName: "DATADOG-TEST-V1-MIB", Description: "The state changed."}, trapsDB.Traps["1.3.6.1.4.1.99998.0.2"])
+	assert.Equal(t, map[int]string{1: "running", 2: "stopped"}, trapsDB.Variables["1.3.6.1.4.1.99998.1.3"].Enumeration)
+
+	err = generateProfile(&generateProfileParams{}, nil)
+	assert.ErrorAs(t, err, &configErr{})
+}
+
+func TestSimulateCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "simulate", "devices/", "--listen", "0.0.0.0:161", "-u", "user", "-a", "sha", "-A", "authkey", "-x", "aes", "-X", "privkey"},
+		simulate,
+		func(params *simulateParams, args argsType) {
+			require.Equal(t, argsType{"devices/"}, args)
+			require.Equal(t, "0.0.0.0:161", params.listen)
+			require.Equal(t, "user", params.username)
+			require.Equal(t, "SHA", params.authProtocol)
+			require.Equal(t, "AES", params.privProtocol)
+		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "simulate", "device.snmprec"},
+		simulate,
+		func(params *simulateParams, args argsType) {
+			require.Equal(t, argsType{"device.snmprec"}, args)
+			require.Equal(t, defaultSimulatorAddress, params.listen)
+			require.Empty(t, params.username)
+		})
+}
+
+func TestTopologyCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "topology", "-f", "dot", "-o", "topology.dot"},
+		getTopology,
+		func(params *topologyParams) {
+			require.Equal(t, "dot", params.format)
+			require.Equal(t, "topology.dot", params.output)
+		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "topology"},
+		getTopology,
+		func(params *topologyParams) {
+			require.Equal(t, topologyFormatJSON, params.format)
+			require.Empty(t, params.output)
+		})
+}
+
+func TestWriteTopology(t *testing.T) {
+	graph := topology.Graph{
+		Nodes: []topology.Node{
+			{ID: "default:10.0.0.1", Name: "switch1", IPAddress: "10.0.0.1", Monitored: true},
+			{ID: "neighbor:router1", Name: "router1"},
+		},
+		Links: []topology.Link{{
+			Source:      topology.Endpoint{Device: "default:10.0.0.1", Interface: "swp1"},
+			Target:      topology.Endpoint{Device: "neighbor:router1", Interface: "Gi0/1"},
+			SourceTypes: []string{"cdp", "lldp"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTopology(&buf, graph, topologyFormatJSON))
+	var decoded topology.Graph
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, graph, decoded)
+
+	buf.Reset()
+	require.NoError(t, writeTopology(&buf, graph, topologyFormatDOT))
+	assert.Contains(t, buf.String(), `"default:10.0.0.1" -- "neighbor:router1" [taillabel="swp1", headlabel="Gi0/1", label="cdp,lldp"];`)
+
+	err := getTopology(&topologyParams{format: "svg"}, nil)
+	assert.ErrorAs(t, err, &configErr{})
+}
+
+func TestWriteSnmprec(t *testing.T) {
+	devicePath := "../../../../pkg/snmp/snmpsim/testdata/device.snmprec"
+	device, err := snmpsim.LoadDevice(devicePath)
+	require.NoError(t, err)
+	sim, err := snmpsim.Start("127.0.0.1:0", snmpsim.Config{Devices: map[string]*snmpsim.Device{"device": device}})
+	require.NoError(t, err)
+	defer sim.Stop()
+
+	session := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(sim.Addr().Port),
+		Community: "device",
+		Version:   gosnmp.Version2c,
+		Timeout:   time.Second,
+	}
+	require.NoError(t, session.Connect())
+	defer session.Conn.Close()
+
+	output := filepath.Join(t.TempDir(), "walk.snmprec")
+	require.NoError(t, writeSnmprec(session, "", output))
+
+	expected, err := snmprec.ReadFile(devicePath)
+	require.NoError(t, err)
+	walked, err := snmprec.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, expected, walked)
+}
+
+func TestBuildSimulatorUser(t *testing.T) {
+	user, err := buildSimulatorUser(&simulateParams{username: "user", authProtocol: "SHA", authKey: "authkey", privProtocol: "AES", privKey: "privkey"})
+	require.NoError(t, err)
+	assert.Equal(t, &snmpsim.User{Name: "user", AuthProtocol: gosnmp.SHA, AuthKey: "authkey", PrivProtocol: gosnmp.AES, PrivKey: "privkey"}, user)
+
+	user, err = buildSimulatorUser(&simulateParams{username: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, &snmpsim.User{Name: "user", AuthProtocol: gosnmp.NoAuth, PrivProtocol: gosnmp.NoPriv}, user)
+
+	_, err = buildSimulatorUser(&simulateParams{username: "user", privProtocol: "AES", privKey: "privkey"})
+	assert.Error(t, err)
+}
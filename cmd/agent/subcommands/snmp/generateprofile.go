@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/mibparse"
+	"github.com/DataDog/datadog-agent/pkg/snmp/profilegen"
+)
+
+// generateProfileParams are the flags of the generate-profile subcommand
+type generateProfileParams struct {
+	name         string
+	output       string
+	trapsOutput  string
+	mibDirs      []string
+	sysObjectIDs []string
+	extends      []string
+	objects      []string
+}
+
+// generateProfile builds a profile from the MIB files given as arguments.
+// MIB files found in the MIB directories are only used to resolve imports.
+func generateProfile(params *generateProfileParams, args argsType) error {
+	if len(args) == 0 {
+		return confErrf("missing argument: MIB file")
+	}
+
+	mibs := mibparse.NewMIBs()
+	for _, dir := range params.mibDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("unable to read MIB directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if _, err := mibs.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+	var modules []string
+	for _, path := range args {
+		loaded, err := mibs.LoadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, module := range loaded {
+			modules = append(modules, module.Name)
+		}
+	}
+	for _, resolveErr := range mibs.Resolve() {
+		for _, module := range modules {
+			if resolveErr.Module == module {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", resolveErr)
+			}
+		}
+	}
+
+	name := params.name
+	if name == "" {
+		name = strings.ToLower(modules[0])
+	}
+	profile, warnings, err := profilegen.GenerateProfile(mibs, profilegen.Options{
+		Name:         name,
+		SysObjectIDs: params.sysObjectIDs,
+		Extends:      params.extends,
+		Modules:      modules,
+		Objects:      params.objects,
+	})
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to generate profile: %w", err)
+	}
+	profileContent, err := yaml.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("unable to marshal profile: %w", err)
+	}
+	if err := writeOutput(params.output, profileContent); err != nil {
+		return err
+	}
+
+	if params.trapsOutput == "" {
+		return nil
+	}
+	trapsDB := profilegen.GenerateTrapsDB(mibs, modules)
+	var trapsContent []byte
+	if strings.HasSuffix(params.trapsOutput, ".json") {
+		trapsContent, err = json.MarshalIndent(trapsDB, "", "  ")
+	} else {
+		trapsContent, err = yaml.Marshal(trapsDB)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to marshal trap definitions: %w", err)
+	}
+	return writeOutput(params.trapsOutput, trapsContent)
+}
+
+// writeOutput writes content to a file, or to stdout if path is empty
+func writeOutput(path string, content []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpsim"
+)
+
+const (
+	defaultSimulatorAddress = "127.0.0.1:1161"
+	snmprecExtension        = ".snmprec"
+)
+
+// simulateParams are the flags of the simulate subcommand
+type simulateParams struct {
+	listen       string
+	engineID     string
+	username     string
+	authProtocol string
+	authKey      string
+	privProtocol string
+	privKey      string
+}
+
+// simulate serves snmprec files over UDP until interrupted. Each file is served
+// for the community, or SNMPv3 context, named after the file without extension.
+func simulate(params *simulateParams, args argsType) error {
+	if len(args) == 0 {
+		return confErrf("missing argument: snmprec file or directory")
+	}
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*"+snmprecExtension))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no %s file found", snmprecExtension)
+	}
+
+	devices := make(map[string]*snmpsim.Device, len(paths))
+	for _, path := range paths {
+		device, err := snmpsim.LoadDevice(path)
+		if err != nil {
+			return err
+		}
+		community := strings.TrimSuffix(filepath.Base(path), snmprecExtension)
+		if _, ok := devices[community]; ok {
+			return fmt.Errorf("several snmprec files are named %s", community)
+		}
+		devices[community] = device
+	}
+
+	config := snmpsim.Config{Devices: devices}
+	if params.engineID != "" {
+		engineID, err := hex.DecodeString(strings.TrimPrefix(params.engineID, "0x"))
+		if err != nil {
+			return confErrf("invalid engine ID %s: %v", params.engineID, err)
+		}
+		config.EngineID = string(engineID)
+	}
+	if params.username != "" {
+		user, err := buildSimulatorUser(params)
+		if err != nil {
+			return configErr{err}
+		}
+		config.User = user
+	}
+
+	simulator, err := snmpsim.Start(params.listen, config)
+	if err != nil {
+		return fmt.Errorf("unable to start the simulator: %w", err)
+	}
+	defer simulator.Stop()
+	for community := range devices {
+		fmt.Printf("Serving %s on %s\n", community, simulator.Addr())
+	}
+	fmt.Println("Press Ctrl+C to stop")
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	return nil
+}
+
+// buildSimulatorUser returns the SNMPv3 user of the simulator. Protocols are already validated by their flags.
+func buildSimulatorUser(params *simulateParams) (*snmpsim.User, error) {
+	authProtocol, _ := snmpparse.AuthOpts.GetVal(params.authProtocol)
+	privProtocol, _ := snmpparse.PrivOpts.GetVal(params.privProtocol)
+	if privProtocol != gosnmp.NoPriv && authProtocol == gosnmp.NoAuth {
+		return nil, fmt.Errorf("a privacy protocol requires an authentication protocol")
+	}
+	return &snmpsim.User{
+		Name:         params.username,
+		AuthProtocol: authProtocol,
+		AuthKey:      params.authKey,
+		PrivProtocol: privProtocol,
+		PrivKey:      params.privKey,
+	}, nil
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
+)
+
+const (
+	topologyFormatJSON = "json"
+	topologyFormatDOT  = "dot"
+)
+
+// topologyParams are the flags of the topology subcommand
+type topologyParams struct {
+	format string
+	output string
+}
+
+// getTopology prints the topology graph built by the running agent from the
+// LLDP/CDP links of the devices it monitors
+func getTopology(params *topologyParams, conf config.Component) error {
+	if params.format != topologyFormatJSON && params.format != topologyFormatDOT {
+		return confErrf("invalid format %q, expected %s or %s", params.format, topologyFormatJSON, topologyFormatDOT)
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	ipcAddress, err := pkgconfigsetup.GetIPCAddress(pkgconfigsetup.Datadog())
+	if err != nil {
+		return err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/snmp/topology", ipcAddress, pkgconfigsetup.Datadog().GetInt("cmd_port"))
+
+	if err := util.SetAuthToken(conf); err != nil {
+		return err
+	}
+
+	r, err := util.DoGet(c, urlstr, util.LeaveConnectionOpen)
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			err = errors.New(e)
+		}
+		return fmt.Errorf("could not reach agent: %w\nMake sure the agent is running before requesting the topology", err)
+	}
+
+	var graph topology.Graph
+	if err := json.Unmarshal(r, &graph); err != nil {
+		return fmt.Errorf("unable to parse the topology returned by the agent: %w", err)
+	}
+
+	if params.output == "" {
+		return writeTopology(os.Stdout, graph, params.format)
+	}
+	f, err := os.Create(params.output)
+	if err != nil {
+		return err
+	}
+	if err := writeTopology(f, graph, params.format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Topology of %d devices and %d links written in: %s\n", len(graph.Nodes), len(graph.Links), params.output)
+	return nil
+}
+
+// writeTopology writes the graph as indented JSON or in the Graphviz DOT format
+func writeTopology(w io.Writer, graph topology.Graph, format string) error {
+	if format == topologyFormatDOT {
+		return graph.WriteDOT(w)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(graph)
+}
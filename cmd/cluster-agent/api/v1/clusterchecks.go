@@ -34,7 +34,8 @@ func installClusterCheckEndpoints(r *mux.Router, sc clusteragent.ServerContext)
 
 // RebalancePostPayload struct is for the JSON messages received from a client POST request
 type RebalancePostPayload struct {
-	Force bool `json:"force"`
+	Force  bool `json:"force"`
+	DryRun bool `json:"dry_run"`
 }
 
 // postCheckStatus is used by the node-agent's config provider
@@ -112,7 +113,12 @@ func postRebalanceChecks(sc clusteragent.ServerContext) func(w http.ResponseWrit
 			return
 		}
 
-		response, err := sc.ClusterCheckHandler.RebalanceClusterChecks(requestData.Force)
+		var response []cctypes.RebalanceResponse
+		if requestData.DryRun {
+			response, err = sc.ClusterCheckHandler.SimulateRebalanceClusterChecks(requestData.Force)
+		} else {
+			response, err = sc.ClusterCheckHandler.RebalanceClusterChecks(requestData.Force)
+		}
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -151,6 +151,7 @@ func RunCheck(log log.Component, config config.Component, _ secrets.Component, s
 			HostRoot:           os.Getenv("HOST_ROOT"),
 			DockerProvider:     compliance.DefaultDockerProvider,
 			LinuxAuditProvider: compliance.DefaultLinuxAuditProvider,
+			SystemdProvider:    compliance.DefaultSystemdProvider,
 			StatsdClient:       statsdClient,
 		})
 	}
@@ -49,6 +49,7 @@ func StartCompliance(log log.Component, config config.Component, sysprobeconfig
 		HostRoot:           os.Getenv("HOST_ROOT"),
 		DockerProvider:     compliance.DefaultDockerProvider,
 		LinuxAuditProvider: compliance.DefaultLinuxAuditProvider,
+		SystemdProvider:    compliance.DefaultSystemdProvider,
 	}
 
 	if metricsEnabled {
@@ -71,6 +71,7 @@ func commonPolicyCommands(globalParams *command.GlobalParams) []*cobra.Command {
 	commonPolicyCmd.AddCommand(commonCheckPoliciesCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(commonReloadPoliciesCommands(globalParams)...)
 	commonPolicyCmd.AddCommand(downloadPolicyCommands(globalParams)...)
+	commonPolicyCmd.AddCommand(testPolicyCommands(globalParams)...)
 
 	return []*cobra.Command{commonPolicyCmd}
 }
@@ -534,6 +535,10 @@ func eventDataFromJSON(file string) (eval.Event, error) {
 		return nil, err
 	}
 
+	return newEventFromEventData(&eventData)
+}
+
+func newEventFromEventData(eventData *EventData) (eval.Event, error) {
 	kind := secconfig.ParseEvalEventType(eventData.Type)
 	if kind == model.UnknownEventType {
 		return nil, errors.New("unknown event type")
@@ -0,0 +1,417 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	"github.com/DataDog/datadog-agent/pkg/security/security_profile/dump"
+	"github.com/DataDog/datadog-agent/pkg/security/serializers"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+type testPolicyCliParams struct {
+	*command.GlobalParams
+
+	dir          string
+	fixturesFile string
+}
+
+func testPolicyCommands(globalParams *command.GlobalParams) []*cobra.Command {
+	testArgs := &testPolicyCliParams{
+		GlobalParams: globalParams,
+	}
+
+	testCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Evaluate recorded events against the policies and check the expected matches",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return fxutil.OneShot(testPolicies,
+				fx.Supply(testArgs),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths, config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", false)}),
+				core.Bundle(),
+			)
+		},
+	}
+
+	testCmd.Flags().StringVar(&testArgs.dir, "policies-dir", pkgconfigsetup.DefaultRuntimePoliciesDir, "Path to policies directory")
+	testCmd.Flags().StringVar(&testArgs.fixturesFile, "fixtures", "", "YAML or JSON file of the recorded events and of the expected results")
+	_ = testCmd.MarkFlagRequired("fixtures")
+
+	return []*cobra.Command{testCmd}
+}
+
+// PolicyFixtures defines a set of recorded events along with the expected evaluation results
+type PolicyFixtures struct {
+	Fixtures []*PolicyFixture `yaml:"fixtures"`
+}
+
+// PolicyFixture defines a list of events evaluated in order against a fresh rule set
+type PolicyFixture struct {
+	Name   string                   `yaml:"name"`
+	Events []*PolicyFixtureEvent    `yaml:"events"`
+	Expect PolicyFixtureExpectation `yaml:"expect"`
+}
+
+// PolicyFixtureEvent defines an event of a fixture. It is either described inline, in the same format as the
+// `policy eval` event file, or loaded from a file, relative to the fixtures file, holding an event in the format
+// sent by the agent or an activity dump.
+type PolicyFixtureEvent struct {
+	EventData    `yaml:",inline"`
+	File         string `yaml:"file"`
+	ActivityDump string `yaml:"activity_dump"`
+}
+
+// PolicyFixtureExpectation defines the expected results of a fixture. Variables are evaluated in the context of the
+// last event of the fixture.
+type PolicyFixtureExpectation struct {
+	Match     []string                      `yaml:"match"`
+	NoMatch   []string                      `yaml:"no_match"`
+	Variables map[string]interface{}        `yaml:"variables"`
+	Actions   map[string][]rules.ActionName `yaml:"actions"`
+}
+
+// PolicyTestReport defines the report of a policy test run
+type PolicyTestReport struct {
+	Rules    map[eval.RuleID][]*RuleFixtureResult `json:"rules"`
+	Fixtures []*FixtureReport                     `json:"fixtures"`
+	Failures int                                  `json:"failures"`
+}
+
+// RuleFixtureResult defines whether a rule matched a fixture, and whether it was expected
+type RuleFixtureResult struct {
+	Fixture  string `json:"fixture"`
+	Matched  bool   `json:"matched"`
+	Expected *bool  `json:"expected,omitempty"`
+	Success  bool   `json:"success"`
+}
+
+// FixtureReport defines the report of a fixture
+type FixtureReport struct {
+	Name         string                             `json:"name"`
+	EventCount   int                                `json:"event_count"`
+	MatchedRules []eval.RuleID                      `json:"matched_rules"`
+	Actions      map[eval.RuleID][]rules.ActionName `json:"actions,omitempty"`
+	Variables    map[string]interface{}             `json:"variables,omitempty"`
+	Errors       []string                           `json:"errors,omitempty"`
+}
+
+// fixtureListener records the rules matched by the events of a fixture
+type fixtureListener struct {
+	matches map[eval.RuleID]bool
+	actions map[eval.RuleID][]rules.ActionName
+}
+
+func (l *fixtureListener) RuleMatch(rule *rules.Rule, event eval.Event) bool {
+	l.matches[rule.ID] = true
+
+	ctx := eval.NewContext(event)
+	for _, action := range rule.Actions {
+		if action.IsAccepted(ctx) && !slices.Contains(l.actions[rule.ID], action.Def.Name()) {
+			l.actions[rule.ID] = append(l.actions[rule.ID], action.Def.Name())
+		}
+	}
+
+	return true
+}
+
+func (l *fixtureListener) EventDiscarderFound(_ *rules.RuleSet, _ eval.Event, _ eval.Field, _ eval.EventType) {
+}
+
+func loadPolicyFixtures(file string) (*PolicyFixtures, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var fixtures PolicyFixtures
+	if err := yaml.NewDecoder(f).Decode(&fixtures); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode fixtures `%s`: %w", file, err)
+	}
+
+	return &fixtures, nil
+}
+
+// loadFixtureEvents returns the events of a fixture, in order
+func loadFixtureEvents(fixture *PolicyFixture, baseDir string) ([]eval.Event, error) {
+	var events []eval.Event
+
+	for _, fixtureEvent := range fixture.Events {
+		switch {
+		case fixtureEvent.File != "":
+			event, err := serializers.DecodeEvent(filepath.Join(baseDir, fixtureEvent.File))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode event `%s`: %w", fixtureEvent.File, err)
+			}
+			events = append(events, event)
+		case fixtureEvent.ActivityDump != "":
+			ads, err := dump.LoadActivityDumpsFromFiles(filepath.Join(baseDir, fixtureEvent.ActivityDump))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode activity dump `%s`: %w", fixtureEvent.ActivityDump, err)
+			}
+			for _, ad := range ads {
+				ad.ActivityTree.Replay(model.NewFakeEvent, func(event *model.Event) {
+					events = append(events, event)
+				})
+			}
+		case fixtureEvent.Type != "":
+			event, err := newEventFromEventData(&fixtureEvent.EventData)
+			if err != nil {
+				return nil, fmt.Errorf("invalid `%s` event: %w", fixtureEvent.Type, err)
+			}
+			events = append(events, event)
+		default:
+			return nil, errors.New("an event requires either a type, a file or an activity dump")
+		}
+	}
+
+	return events, nil
+}
+
+func newFixtureRuleSet(dir string) (*rules.RuleSet, *eval.Opts, error) {
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	ruleOpts := rules.NewRuleOpts(enabled)
+	evalOpts := newEvalOpts(false)
+	ruleOpts.WithLogger(seclog.DefaultLogger)
+
+	agentVersionFilter, err := newAgentVersionFilter()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create agent version filter: %w", err)
+	}
+
+	loaderOpts := rules.PolicyLoaderOpts{
+		MacroFilters: []rules.MacroFilter{
+			agentVersionFilter,
+		},
+		RuleFilters: []rules.RuleFilter{
+			agentVersionFilter,
+		},
+	}
+
+	provider, err := rules.NewPoliciesDirProvider(dir, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ruleSet := rules.NewRuleSet(&model.Model{}, newFakeEvent, ruleOpts, evalOpts)
+	if err := ruleSet.LoadPolicies(rules.NewPolicyLoader(provider), loaderOpts); err.ErrorOrNil() != nil {
+		return nil, nil, err
+	}
+
+	return ruleSet, evalOpts, nil
+}
+
+// getVariableValue returns the value of a variable in the context of the given event
+func getVariableValue(evalOpts *eval.Opts, name string, event eval.Event) (interface{}, bool) {
+	variable := evalOpts.VariableStore.Get(name)
+	if variable == nil {
+		return nil, false
+	}
+
+	evaluator, ok := variable.GetEvaluator().(eval.Evaluator)
+	if !ok {
+		return nil, false
+	}
+
+	return evaluator.Eval(eval.NewContext(event)), true
+}
+
+// normalizeVariableValue converts the values decoded from the fixtures to the types used by the variables
+func normalizeVariableValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		if values, ok := anySliceToStringSlice(value); ok {
+			return values
+		}
+		ints := make([]int, 0, len(value))
+		for _, v := range value {
+			i, ok := v.(int)
+			if !ok {
+				return value
+			}
+			ints = append(ints, i)
+		}
+		return ints
+	default:
+		return value
+	}
+}
+
+func runPolicyFixture(dir string, baseDir string, fixture *PolicyFixture, report *PolicyTestReport) error {
+	ruleSet, evalOpts, err := newFixtureRuleSet(dir)
+	if err != nil {
+		return err
+	}
+
+	listener := &fixtureListener{
+		matches: make(map[eval.RuleID]bool),
+		actions: make(map[eval.RuleID][]rules.ActionName),
+	}
+	ruleSet.AddListener(listener)
+
+	fixtureReport := &FixtureReport{
+		Name:    fixture.Name,
+		Actions: listener.actions,
+	}
+	report.Fixtures = append(report.Fixtures, fixtureReport)
+
+	fail := func(format string, args ...interface{}) {
+		fixtureReport.Errors = append(fixtureReport.Errors, fmt.Sprintf(format, args...))
+		report.Failures++
+	}
+
+	events, err := loadFixtureEvents(fixture, baseDir)
+	if err != nil {
+		fail("%s", err)
+		return nil
+	}
+	fixtureReport.EventCount = len(events)
+
+	for _, event := range events {
+		ruleSet.Evaluate(event)
+	}
+
+	for ruleID := range listener.matches {
+		fixtureReport.MatchedRules = append(fixtureReport.MatchedRules, ruleID)
+	}
+	sort.Strings(fixtureReport.MatchedRules)
+
+	expectations := make(map[eval.RuleID]bool)
+	for _, ruleID := range fixture.Expect.Match {
+		expectations[ruleID] = true
+	}
+	for _, ruleID := range fixture.Expect.NoMatch {
+		expectations[ruleID] = false
+	}
+
+	for ruleID, expected := range expectations {
+		if _, exists := ruleSet.GetRules()[ruleID]; !exists {
+			fail("unknown rule `%s`", ruleID)
+		}
+		if listener.matches[ruleID] != expected {
+			if expected {
+				fail("rule `%s` didn't match", ruleID)
+			} else {
+				fail("rule `%s` matched", ruleID)
+			}
+		}
+	}
+
+	for ruleID := range ruleSet.GetRules() {
+		result := &RuleFixtureResult{
+			Fixture: fixture.Name,
+			Matched: listener.matches[ruleID],
+			Success: true,
+		}
+		if expected, exists := expectations[ruleID]; exists {
+			result.Expected = &expected
+			result.Success = expected == result.Matched
+		}
+		report.Rules[ruleID] = append(report.Rules[ruleID], result)
+	}
+
+	for ruleID, expectedActions := range fixture.Expect.Actions {
+		for _, action := range expectedActions {
+			if !slices.Contains(listener.actions[ruleID], action) {
+				fail("action `%s` of rule `%s` wasn't triggered", action, ruleID)
+			}
+		}
+	}
+
+	if len(fixture.Expect.Variables) > 0 {
+		fixtureReport.Variables = make(map[string]interface{})
+
+		var lastEvent eval.Event = model.NewFakeEvent()
+		if len(events) > 0 {
+			lastEvent = events[len(events)-1]
+		}
+
+		for name, expected := range fixture.Expect.Variables {
+			value, exists := getVariableValue(evalOpts, name, lastEvent)
+			if !exists {
+				fail("unknown variable `%s`", name)
+				continue
+			}
+			fixtureReport.Variables[name] = value
+
+			if !reflect.DeepEqual(value, normalizeVariableValue(expected)) {
+				fail("variable `%s` is `%v`, expected `%v`", name, value, expected)
+			}
+		}
+	}
+
+	return nil
+}
+
+// runPolicyFixtures evaluates the fixtures against the policies of the given directory
+func runPolicyFixtures(dir string, fixturesFile string) (*PolicyTestReport, error) {
+	fixtures, err := loadPolicyFixtures(fixturesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PolicyTestReport{
+		Rules: make(map[eval.RuleID][]*RuleFixtureResult),
+	}
+
+	baseDir := filepath.Dir(fixturesFile)
+	for _, fixture := range fixtures.Fixtures {
+		if err := runPolicyFixture(dir, baseDir, fixture, report); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func testPolicies(_ log.Component, _ config.Component, _ secrets.Component, testArgs *testPolicyCliParams) error {
+	report, err := runPolicyFixtures(testArgs.dir, testArgs.fixturesFile)
+	if err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", string(output))
+
+	if report.Failures > 0 {
+		return fmt.Errorf("%d policy test expectation(s) failed", report.Failures)
+	}
+
+	return nil
+}
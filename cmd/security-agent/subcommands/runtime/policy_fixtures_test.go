@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/security-agent/command"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const testFixturesPolicy = `---
+version: 1.2.3
+macros:
+  - id: shadow_files
+    expression: '["/etc/shadow", "/etc/gshadow"]'
+rules:
+  - id: curl_exec
+    expression: exec.file.name == "curl"
+    actions:
+      - set:
+          name: curl_seen
+          value: true
+          scope: process
+  - id: shadow_open
+    expression: open.file.path in shadow_files
+    actions:
+      - kill:
+          signal: SIGKILL
+  - id: passwd_chmod
+    expression: chmod.file.path == "/etc/passwd"
+`
+
+const testFixturesSerializedEvent = `{
+  "evt": {"name": "open"},
+  "file": {"path": "/etc/gshadow", "name": "gshadow", "flags": ["O_RDONLY"]},
+  "process": {"pid": 42, "executable": {"path": "/usr/bin/cat", "name": "cat"}}
+}`
+
+const testFixtures = `
+fixtures:
+  - name: curl
+    events:
+      - type: exec
+        values:
+          exec.file.name: curl
+          process.pid: 42
+    expect:
+      match: [curl_exec]
+      no_match: [shadow_open]
+      variables:
+        process.curl_seen: true
+  - name: gshadow
+    events:
+      - file: open_gshadow.json
+    expect:
+      match: [shadow_open]
+      actions:
+        shadow_open: [kill]
+`
+
+func writeTestFixtures(t *testing.T, fixtures string) (string, string) {
+	t.Helper()
+
+	policiesDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(policiesDir, "test.policy"), []byte(testFixturesPolicy), 0644))
+
+	fixturesDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(fixturesDir, "open_gshadow.json"), []byte(testFixturesSerializedEvent), 0644))
+	fixturesFile := filepath.Join(fixturesDir, "fixtures.yaml")
+	require.NoError(t, os.WriteFile(fixturesFile, []byte(fixtures), 0644))
+
+	return policiesDir, fixturesFile
+}
+
+func TestRunPolicyFixtures(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		policiesDir, fixturesFile := writeTestFixtures(t, testFixtures)
+
+		report, err := runPolicyFixtures(policiesDir, fixturesFile)
+		require.NoError(t, err)
+
+		assert.Zero(t, report.Failures, "%+v", report.Fixtures)
+		if assert.Len(t, report.Fixtures, 2) {
+			assert.Equal(t, []string{"curl_exec"}, report.Fixtures[0].MatchedRules)
+			assert.Equal(t, true, report.Fixtures[0].Variables["process.curl_seen"])
+			assert.Equal(t, []string{"shadow_open"}, report.Fixtures[1].MatchedRules)
+		}
+		assert.Len(t, report.Rules["passwd_chmod"], 2)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		policiesDir, fixturesFile := writeTestFixtures(t, `
+fixtures:
+  - name: cat
+    events:
+      - type: exec
+        values:
+          exec.file.name: cat
+    expect:
+      match: [curl_exec]
+      variables:
+        process.curl_seen: true
+  - name: gshadow
+    events:
+      - file: open_gshadow.json
+    expect:
+      no_match: [shadow_open]
+      actions:
+        passwd_chmod: [kill]
+`)
+
+		report, err := runPolicyFixtures(policiesDir, fixturesFile)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, report.Failures)
+		if assert.Len(t, report.Rules["curl_exec"], 2) {
+			assert.False(t, report.Rules["curl_exec"][0].Success)
+		}
+	})
+}
+
+func TestTestPoliciesCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"runtime", "policy", "test", "--fixtures=fixtures.yaml"},
+		testPolicies,
+		func() {})
+}
@@ -98,8 +98,8 @@ func (t *traceroute) RegisterGRPC(_ grpc.ServiceRegistrar) error {
 func (t *traceroute) Close() {}
 
 func logTracerouteRequests(cfg tracerouteutil.Config, client string, runCount uint64, start time.Time) {
-	args := []interface{}{cfg.DestHostname, client, cfg.DestPort, cfg.MaxTTL, cfg.Timeout, cfg.Protocol, runCount, time.Since(start)}
-	msg := "Got request on /traceroute/%s?client_id=%s&port=%d&maxTTL=%d&timeout=%d&protocol=%s (count: %d): retrieved traceroute in %s"
+	args := []interface{}{cfg.DestHostname, client, cfg.DestPort, cfg.MaxTTL, cfg.Timeout, cfg.Protocol, cfg.IPVersion, cfg.NumPaths, runCount, time.Since(start)}
+	msg := "Got request on /traceroute/%s?client_id=%s&port=%d&maxTTL=%d&timeout=%d&protocol=%s&ip_version=%d&num_paths=%d (count: %d): retrieved traceroute in %s"
 	switch {
 	case runCount <= 5, runCount%200 == 0:
 		log.Infof(msg, args...)
@@ -124,6 +124,14 @@ func parseParams(req *http.Request) (tracerouteutil.Config, error) {
 		return tracerouteutil.Config{}, fmt.Errorf("invalid timeout: %s", err)
 	}
 	protocol := req.URL.Query().Get("protocol")
+	ipVersion, err := parseUint(req, "ip_version", 8)
+	if err != nil {
+		return tracerouteutil.Config{}, fmt.Errorf("invalid ip_version: %s", err)
+	}
+	numPaths, err := parseUint(req, "num_paths", 16)
+	if err != nil {
+		return tracerouteutil.Config{}, fmt.Errorf("invalid num_paths: %s", err)
+	}
 
 	return tracerouteutil.Config{
 		DestHostname: host,
@@ -131,6 +139,8 @@ func parseParams(req *http.Request) (tracerouteutil.Config, error) {
 		MaxTTL:       uint8(maxTTL),
 		Timeout:      time.Duration(timeout),
 		Protocol:     payload.Protocol(protocol),
+		IPVersion:    uint8(ipVersion),
+		NumPaths:     uint16(numPaths),
 	}, nil
 }
 
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 	tracerouteutil "github.com/DataDog/datadog-agent/pkg/networkpath/traceroute"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -38,17 +39,32 @@ func TestParseParams(t *testing.T) {
 			name: "all config",
 			host: "1.2.3.4",
 			params: map[string]string{
-				"port":    "42",
-				"max_ttl": "35",
-				"timeout": "1000",
+				"port":       "42",
+				"max_ttl":    "35",
+				"timeout":    "1000",
+				"protocol":   "TCP",
+				"ip_version": "6",
+				"num_paths":  "8",
 			},
 			expectedConfig: tracerouteutil.Config{
 				DestHostname: "1.2.3.4",
 				DestPort:     42,
 				MaxTTL:       35,
 				Timeout:      1000,
+				Protocol:     payload.ProtocolTCP,
+				IPVersion:    6,
+				NumPaths:     8,
 			},
 		},
+		{
+			name: "invalid num_paths",
+			host: "1.2.3.4",
+			params: map[string]string{
+				"num_paths": "70000",
+			},
+			expectedConfig: tracerouteutil.Config{},
+			expectedError:  `invalid num_paths: strconv.ParseUint: parsing "70000": value out of range`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(_ *testing.T) {
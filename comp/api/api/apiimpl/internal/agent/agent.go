@@ -31,6 +31,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/optional"
@@ -56,6 +57,7 @@ func SetupHandlers(
 
 	// TODO: move these to a component that is registerable
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
+	r.HandleFunc("/snmp/topology", getSNMPTopology).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusHandler).Methods("POST")
 	r.HandleFunc("/{component}/configs", componentConfigHandler).Methods("GET")
 	r.HandleFunc("/diagnose", func(w http.ResponseWriter, r *http.Request) {
@@ -114,6 +116,17 @@ func getHealth(w http.ResponseWriter, _ *http.Request) {
 	w.Write(jsonHealth)
 }
 
+func getSNMPTopology(w http.ResponseWriter, _ *http.Request) {
+	jsonTopology, err := json.Marshal(topology.Default().Graph())
+	if err != nil {
+		httputils.SetJSONError(w, log.Errorf("Unable to marshal SNMP topology: %s", err), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonTopology)
+}
+
 func getDiagnose(w http.ResponseWriter, r *http.Request, diagnoseDeps diagnose.SuitesDeps) {
 	var diagCfg diagnosis.Config
 
@@ -232,6 +232,11 @@ func TestSetupHandlers(t *testing.T) {
 			method:   "GET",
 			wantCode: 200,
 		},
+		{
+			route:    "/snmp/topology",
+			method:   "GET",
+			wantCode: 200,
+		},
 	}
 	router := setupRoutes(t)
 	ts := httptest.NewServer(router)
@@ -97,11 +97,12 @@ type AutoConfig struct {
 type provides struct {
 	fx.Out
 
-	Comp           autodiscovery.Component
-	StatusProvider status.InformationProvider
-	Endpoint       api.AgentEndpointProvider
-	EndpointRaw    api.AgentEndpointProvider
-	FlareProvider  flaretypes.Provider
+	Comp             autodiscovery.Component
+	StatusProvider   status.InformationProvider
+	Endpoint         api.AgentEndpointProvider
+	EndpointRaw      api.AgentEndpointProvider
+	SnapshotEndpoint api.AgentEndpointProvider
+	FlareProvider    flaretypes.Provider
 }
 
 // Module defines the fx options for this component.
@@ -118,8 +119,9 @@ func newProvides(deps dependencies) provides {
 		Comp:           c,
 		StatusProvider: status.NewInformationProvider(autodiscoveryStatus.GetProvider(c)),
 
-		Endpoint:      api.NewAgentEndpointProvider(c.(*AutoConfig).writeConfigCheck, "/config-check", "GET"),
-		FlareProvider: flaretypes.NewProvider(c.(*AutoConfig).fillFlare),
+		Endpoint:         api.NewAgentEndpointProvider(c.(*AutoConfig).writeConfigCheck, "/config-check", "GET"),
+		SnapshotEndpoint: api.NewAgentEndpointProvider(c.(*AutoConfig).writeConfigSnapshot, "/config-check/snapshot", "GET"),
+		FlareProvider:    flaretypes.NewProvider(c.(*AutoConfig).fillFlare),
 	}
 }
 
@@ -264,6 +266,48 @@ func (ac *AutoConfig) getRawConfigCheck() integration.ConfigCheckResponse {
 	return response
 }
 
+func (ac *AutoConfig) writeConfigSnapshot(w http.ResponseWriter, _ *http.Request) {
+	jsonSnapshot, err := json.Marshal(ac.GetConfigSnapshot())
+	if err != nil {
+		httputils.SetJSONError(w, err, 500)
+		return
+	}
+
+	w.Write(jsonSnapshot)
+}
+
+// GetConfigSnapshot returns a scrubbed snapshot of the resolved configurations
+// and templates, with the provider, source entity and template digest of each
+// configuration, and any resolution errors.
+func (ac *AutoConfig) GetConfigSnapshot() integration.ConfigSnapshot {
+	var snapshot integration.ConfigSnapshot
+
+	templateDigests := ac.cfgMgr.templateDigests()
+	configs := ac.LoadedConfigs()
+	snapshot.Configs = make([]integration.ConfigSnapshotEntry, 0, len(configs))
+	for i, c := range ac.scrubConfigs(configs) {
+		digest := configs[i].Digest()
+		entry := integration.NewConfigSnapshotEntry(c, digest)
+		entry.TemplateDigest = templateDigests[digest]
+		snapshot.Configs = append(snapshot.Configs, entry)
+	}
+	integration.SortConfigSnapshotEntries(snapshot.Configs)
+
+	resolveWarnings := GetResolveWarnings()
+	for _, templates := range ac.GetUnresolvedTemplates() {
+		for i, tpl := range ac.scrubConfigs(templates) {
+			entry := integration.NewConfigSnapshotEntry(tpl, templates[i].Digest())
+			entry.ResolveErrors = resolveWarnings[tpl.Name]
+			snapshot.Unresolved = append(snapshot.Unresolved, entry)
+		}
+	}
+	integration.SortConfigSnapshotEntries(snapshot.Unresolved)
+
+	snapshot.ConfigErrors = GetConfigErrors()
+
+	return snapshot
+}
+
 func (ac *AutoConfig) scrubConfigs(configs []integration.Config) []integration.Config {
 	scrubbedConfigs := make([]integration.Config, len(configs))
 
@@ -591,6 +591,48 @@ func TestWriteConfigEndpoint(t *testing.T) {
 	}
 }
 
+func TestGetConfigSnapshot(t *testing.T) {
+	deps := createDeps(t)
+	ctx := context.Background()
+
+	mockResolver := MockSecretResolver{t, nil}
+	ac := getAutoConfig(scheduler.NewController(), &mockResolver, deps.WMeta, deps.TaggerComp, deps.LogsComp, deps.Telemetry)
+
+	tpl := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("password: 1234567")},
+	}
+	ac.applyChanges(ac.processNewConfig(tpl))
+
+	// the template is not resolved yet
+	snapshot := ac.GetConfigSnapshot()
+	assert.Empty(t, snapshot.Configs)
+
+	service := dummyService{
+		ID:            "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"redis"},
+	}
+	ac.processNewService(ctx, &service)
+
+	snapshot = ac.GetConfigSnapshot()
+	require.Len(t, snapshot.Configs, 1)
+	entry := snapshot.Configs[0]
+	assert.Equal(t, "redisdb", entry.Name)
+	assert.Equal(t, service.ID, entry.ServiceID)
+	assert.Equal(t, tpl.Digest(), entry.TemplateDigest)
+	assert.Equal(t, []string{"password: \"********\""}, entry.Instances)
+
+	// the digest is computed before scrubbing
+	var resolved integration.Config
+	ac.MapOverLoadedConfigs(func(loadedConfigs map[string]integration.Config) {
+		for _, c := range loadedConfigs {
+			resolved = c
+		}
+	})
+	assert.Equal(t, resolved.Digest(), entry.Digest)
+}
+
 type Deps struct {
 	fx.In
 	WMeta      optional.Option[workloadmeta.Component]
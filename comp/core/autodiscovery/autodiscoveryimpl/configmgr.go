@@ -7,6 +7,7 @@ package autodiscoveryimpl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -46,6 +47,10 @@ type configManager interface {
 	// The call is made with the manager's lock held, so callers should perform
 	// minimal work within f.
 	mapOverLoadedConfigs(func(map[string]integration.Config))
+
+	// templateDigests returns the digest of the template each resolved
+	// config was resolved from, keyed by the digest of the resolved config.
+	templateDigests() map[string]string
 }
 
 // serviceAndADIDs bundles a service and its associated AD identifiers.
@@ -292,6 +297,20 @@ func (cm *reconcilingConfigManager) mapOverLoadedConfigs(f func(map[string]integ
 	f(cm.scheduledConfigs)
 }
 
+// templateDigests implements configManager#templateDigests.
+func (cm *reconcilingConfigManager) templateDigests() map[string]string {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+
+	digests := map[string]string{}
+	for _, resolutions := range cm.serviceResolutions {
+		for templateDigest, resolvedDigest := range resolutions {
+			digests[resolvedDigest] = templateDigest
+		}
+	}
+	return digests
+}
+
 // reconcileService calculates the current set of resolved templates for the
 // given service and calculates the difference from what is currently recorded
 // in cm.serviceResolutions.  It updates cm.serviceResolutions and returns the
@@ -366,6 +385,12 @@ func (cm *reconcilingConfigManager) reconcileService(svcID string) integration.C
 // returns false.
 func (cm *reconcilingConfigManager) resolveTemplateForService(tpl integration.Config, svc listeners.Service) (integration.Config, bool) {
 	config, err := configresolver.Resolve(tpl, svc)
+	if errors.Is(err, configresolver.ErrADConditionNotMatched) {
+		// the template does not apply to this service, this is not an error
+		log.Debugf("Template %s is not applied to service %s: %v", tpl.Name, svc.GetServiceID(), err)
+		errorStats.removeResolveWarnings(tpl.Name)
+		return tpl, false
+	}
 	if err != nil {
 		msg := fmt.Sprintf("error resolving template %s for service %s: %v", tpl.Name, svc.GetServiceID(), err)
 		errorStats.setResolveWarning(tpl.Name, msg)
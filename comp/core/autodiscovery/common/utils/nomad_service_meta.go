@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package utils
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+)
+
+const (
+	// Consul restricts the keys of the service metadata to alphanumeric
+	// characters, dashes and underscores, so dots can't be used in the
+	// metadata of the Nomad services registered in Consul.
+	nomadServiceMetaPrefix = "datadog_ad_"
+)
+
+// ExtractTemplatesFromNomadServiceMeta looks for autodiscovery configurations
+// in the metadata of a Nomad service and returns them if found. The metadata
+// keys are the same as the container labels, with either the
+// `com.datadoghq.ad.` or the `datadog_ad_` prefix, the latter taking
+// precedence.
+func ExtractTemplatesFromNomadServiceMeta(entityName string, meta map[string]string) ([]integration.Config, []error) {
+	labels := make(map[string]string, len(meta))
+	for key, value := range meta {
+		if !strings.HasPrefix(key, nomadServiceMetaPrefix) {
+			labels[key] = value
+		}
+	}
+	for key, value := range meta {
+		if suffix, found := strings.CutPrefix(key, nomadServiceMetaPrefix); found {
+			labels[containerAnnotationPrefix+suffix] = value
+		}
+	}
+
+	return ExtractTemplatesFromContainerLabels(entityName, labels)
+}
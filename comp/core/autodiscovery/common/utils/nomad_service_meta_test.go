@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+)
+
+func TestExtractTemplatesFromNomadServiceMeta(t *testing.T) {
+	const adID = "docker://foobar"
+
+	tests := []struct {
+		name   string
+		meta   map[string]string
+		output []integration.Config
+	}{
+		{
+			name: "no check metadata",
+			meta: map[string]string{
+				"team": "web",
+			},
+			output: nil,
+		},
+		{
+			name: "label keys",
+			meta: map[string]string{
+				"com.datadoghq.ad.check_names":  `["nginx"]`,
+				"com.datadoghq.ad.init_configs": `[{}]`,
+				"com.datadoghq.ad.instances":    `[{"nginx_status_url":"http://%%host%%:%%port%%/status"}]`,
+			},
+			output: []integration.Config{
+				{
+					Name:          "nginx",
+					Instances:     []integration.Data{integration.Data(`{"nginx_status_url":"http://%%host%%:%%port%%/status"}`)},
+					InitConfig:    integration.Data("{}"),
+					ADIdentifiers: []string{adID},
+				},
+			},
+		},
+		{
+			name: "consul compatible keys take precedence",
+			meta: map[string]string{
+				"datadog_ad_checks":            `{"nginx": {"instances": [{"nginx_status_url":"http://%%host%%:%%port%%/status"}]}}`,
+				"com.datadoghq.ad.checks":      `{"apache": {"instances": [{"apache_status_url":"http://%%host%%/server-status?auto"}]}}`,
+				"com.datadoghq.ad.check_names": `["foo"]`,
+			},
+			output: []integration.Config{
+				{
+					Name:          "nginx",
+					Instances:     []integration.Data{integration.Data(`{"nginx_status_url":"http://%%host%%:%%port%%/status"}`)},
+					InitConfig:    integration.Data("{}"),
+					ADIdentifiers: []string{adID},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, errs := ExtractTemplatesFromNomadServiceMeta(adID, tt.meta)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.output, configs)
+		})
+	}
+}
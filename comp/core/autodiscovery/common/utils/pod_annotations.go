@@ -55,12 +55,13 @@ func ExtractTemplatesFromAnnotations(entityName string, annotations map[string]s
 // (ad.datadoghq.com/redis.checks) JSON string into []integration.Config.
 func parseChecksJSON(adIdentifier string, checksJSON string) ([]integration.Config, error) {
 	var namedChecks map[string]struct {
-		Name                    string          `json:"name"`
-		InitConfig              json.RawMessage `json:"init_config"`
-		Instances               []interface{}   `json:"instances"`
-		Logs                    json.RawMessage `json:"logs"`
-		IgnoreAutodiscoveryTags bool            `json:"ignore_autodiscovery_tags"`
-		CheckTagCardinality     string          `json:"check_tag_cardinality"`
+		Name                    string                    `json:"name"`
+		InitConfig              json.RawMessage           `json:"init_config"`
+		Instances               []interface{}             `json:"instances"`
+		Logs                    json.RawMessage           `json:"logs"`
+		IgnoreAutodiscoveryTags bool                      `json:"ignore_autodiscovery_tags"`
+		CheckTagCardinality     string                    `json:"check_tag_cardinality"`
+		ADCondition             []integration.ADCondition `json:"ad_condition"`
 	}
 
 	err := json.Unmarshal([]byte(checksJSON), &namedChecks)
@@ -83,6 +84,7 @@ func parseChecksJSON(adIdentifier string, checksJSON string) ([]integration.Conf
 			InitConfig:              integration.Data(config.InitConfig),
 			ADIdentifiers:           []string{adIdentifier},
 			IgnoreAutodiscoveryTags: config.IgnoreAutodiscoveryTags,
+			ADCondition:             config.ADCondition,
 		}
 
 		c.CheckTagCardinality = config.CheckTagCardinality
@@ -382,6 +382,36 @@ func TestExtractTemplatesFromAnnotations(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "v2 annotations with ad_condition",
+			annotations: map[string]string{
+				"ad.datadoghq.com/foobar.checks": `{
+					"apache": {
+						"instances": [
+							{"apache_status_url":"http://%%host%%/server-status?auto2"}
+						],
+						"ad_condition": [
+							{"value": "%%label_app%%", "equals": "apache"},
+							{"value": "%%image_tag%%", "matches": "^2\\.4", "negate": true}
+						]
+					}
+				}`,
+			},
+			adIdentifier: "foobar",
+			output: []integration.Config{
+				{
+					Name:          "apache",
+					Instances:     []integration.Data{integration.Data(`{"apache_status_url":"http://%%host%%/server-status?auto2"}`)},
+					InitConfig:    integration.Data("{}"),
+					ADIdentifiers: []string{adID},
+					// conditions are compiled when parsed
+					ADCondition: compiledADConditions(
+						integration.ADCondition{Value: "%%label_app%%", Equals: "apache"},
+						integration.ADCondition{Value: "%%image_tag%%", Matches: `^2\.4`, Negate: true},
+					),
+				},
+			},
+		},
 		{
 			name: "v2 annotations with adv1 ignore_ad_tags",
 			annotations: map[string]string{
@@ -515,3 +545,12 @@ func TestExtractCheckIDFromPodAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func compiledADConditions(conditions ...integration.ADCondition) []integration.ADCondition {
+	for i := range conditions {
+		if err := conditions[i].Compile(); err != nil {
+			panic(err)
+		}
+	}
+	return conditions
+}
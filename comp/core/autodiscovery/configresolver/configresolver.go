@@ -32,15 +32,22 @@ import (
 type variableGetter func(ctx context.Context, key string, svc listeners.Service) (string, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"env":      getEnvvar,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":       getHost,
+	"pid":        getPid,
+	"port":       getPort,
+	"hostname":   getHostname,
+	"env":        getEnvvar,
+	"extra":      getAdditionalTplVariables,
+	"kube":       getAdditionalTplVariables,
+	"label":      getLabel,
+	"annotation": getAnnotation,
+	"image":      getImage,
 }
 
+// ErrADConditionNotMatched is returned by Resolve when the service doesn't
+// match the ad_condition of the template.
+var ErrADConditionNotMatched = errors.New("service does not match the template ad_condition")
+
 // NoServiceError represents an error that indicates that there's a problem with a service
 type NoServiceError struct {
 	message string
@@ -89,6 +96,16 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 	copy(resolvedConfig.InitConfig, tpl.InitConfig)
 	copy(resolvedConfig.Instances, tpl.Instances)
 
+	if len(tpl.ADCondition) > 0 {
+		matched, err := matchADCondition(ctx, tpl.ADCondition, svc)
+		if err != nil {
+			return resolvedConfig, fmt.Errorf("invalid ad_condition: %w", err)
+		}
+		if !matched {
+			return resolvedConfig, ErrADConditionNotMatched
+		}
+	}
+
 	if resolvedConfig.IsCheckConfig() && !svc.IsReady(ctx) {
 		return resolvedConfig, errors.New("unable to resolve, service not ready")
 	}
@@ -190,7 +207,7 @@ func listDataToResolve(config *integration.Config) []dataToResolve {
 
 	if config.IsLogConfig() {
 		p := yamlp
-		if config.Provider == names.Container || config.Provider == names.Kubernetes || config.Provider == names.KubeContainer {
+		if config.Provider == names.Container || config.Provider == names.Kubernetes || config.Provider == names.KubeContainer || config.Provider == names.Nomad || config.Provider == names.Systemd {
 			p = jsonp
 		}
 		res = append(res, dataToResolve{
@@ -403,16 +420,17 @@ func resolveStringWithAdHocTemplateVars(ctx context.Context, in string, svc list
 			sb.WriteString(in[varIndexes[i-1][1]:varIndexes[i][0]])
 		}
 
-		varName := in[varIndexes[i][2]:varIndexes[i][3]]
-		varKey := ""
-		if varIndexes[i][4] != -1 {
-			varKey = in[varIndexes[i][4]:varIndexes[i][5]]
-		}
+		varName, varKey, defaultValue, hasDefault := parseTemplateVar(in, varIndexes[i])
 
 		if f, found := templateVariables[varName]; found {
 			resolvedVar, e := f(ctx, varKey, svc)
 			if e != nil {
-				err = e
+				if hasDefault {
+					log.Debugf("using default value for %%%%%s%%%%: %s", varName, e)
+					resolvedVar = defaultValue
+				} else {
+					err = e
+				}
 			}
 			sb.WriteString(resolvedVar)
 		} else {
@@ -446,6 +464,60 @@ func resolveStringWithAdHocTemplateVars(ctx context.Context, in string, svc list
 	return
 }
 
+// parseTemplateVar returns the name, the key and the default value of the
+// template variable matched by varPattern at the given indexes. The default
+// value follows a pipe, as in `‰env_DB_USER|datadog‰`.
+func parseTemplateVar(in string, indexes []int) (name, key, defaultValue string, hasDefault bool) {
+	name = in[indexes[2]:indexes[3]]
+	if indexes[4] != -1 {
+		key = in[indexes[4]:indexes[5]]
+	}
+
+	end := indexes[5]
+	if end == -1 {
+		end = indexes[3]
+	}
+	spec, defaultValue, hasDefault := strings.Cut(in[indexes[2]:end], "|")
+	if !hasDefault {
+		return name, key, "", false
+	}
+	name, key, _ = strings.Cut(spec, "_")
+	return name, key, defaultValue, true
+}
+
+// matchADCondition returns whether the service matches all the predicates of
+// an ad_condition. Predicates whose value can't be resolved don't match.
+func matchADCondition(ctx context.Context, conditions []integration.ADCondition, svc listeners.Service) (bool, error) {
+	for _, cond := range conditions {
+		re, err := cond.MatchesRegexp()
+		if err != nil {
+			return false, err
+		}
+
+		value := strings.ReplaceAll(cond.Value, "%%", "‰")
+		for _, indexes := range varPattern.FindAllStringSubmatchIndex(value, -1) {
+			if name, _, _, _ := parseTemplateVar(value, indexes); templateVariables[name] == nil {
+				return false, fmt.Errorf("invalid %%%%%s%%%% tag in value %q", name, cond.Value)
+			}
+		}
+
+		var matched bool
+		resolved, err := resolveStringWithAdHocTemplateVars(ctx, value, svc, templateVariables)
+		if err != nil {
+			log.Debugf("could not resolve ad_condition value %q for service %s: %s", cond.Value, svc.GetServiceID(), err)
+		} else if resolvedStr := fmt.Sprint(resolved); re != nil {
+			matched = re.MatchString(resolvedStr)
+		} else {
+			matched = resolvedStr == cond.Equals
+		}
+
+		if matched == cond.Negate {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func tagsAdder(tags []string) func(interface{}) error {
 	return func(tree interface{}) error {
 		if len(tags) == 0 {
@@ -631,3 +703,72 @@ func getEnvvar(_ context.Context, envVar string, svc listeners.Service) (string,
 	}
 	return value, nil
 }
+
+// getLabel returns the value of a label of the workload of the service
+func getLabel(_ context.Context, key string, svc listeners.Service) (string, error) {
+	if svc == nil {
+		return "", NewNoServiceError("No service. %%%%label_*%%%% is not allowed")
+	}
+	wsvc, ok := svc.(listeners.WorkloadService)
+	if !ok {
+		return "", fmt.Errorf("labels are not supported for service %s", svc.GetServiceID())
+	}
+	value, found := wsvc.GetLabels()[key]
+	if !found {
+		return "", fmt.Errorf("label %q not found for service %s", key, svc.GetServiceID())
+	}
+	return value, nil
+}
+
+// getAnnotation returns the value of an annotation of the pod of the service
+func getAnnotation(_ context.Context, key string, svc listeners.Service) (string, error) {
+	if svc == nil {
+		return "", NewNoServiceError("No service. %%%%annotation_*%%%% is not allowed")
+	}
+	wsvc, ok := svc.(listeners.WorkloadService)
+	if !ok {
+		return "", fmt.Errorf("annotations are not supported for service %s", svc.GetServiceID())
+	}
+	value, found := wsvc.GetAnnotations()[key]
+	if !found {
+		return "", fmt.Errorf("annotation %q not found for service %s", key, svc.GetServiceID())
+	}
+	return value, nil
+}
+
+// getImage returns the image of the container of the service. The key
+// selects the part of the image: name, short_name, tag or registry. The raw
+// image name is returned when no key is given.
+func getImage(_ context.Context, key string, svc listeners.Service) (string, error) {
+	if svc == nil {
+		return "", NewNoServiceError("No service. %%%%image%%%% is not allowed")
+	}
+	wsvc, ok := svc.(listeners.WorkloadService)
+	if !ok {
+		return "", fmt.Errorf("image is not supported for service %s", svc.GetServiceID())
+	}
+	image, found := wsvc.GetImage()
+	if !found {
+		return "", fmt.Errorf("no image found for service %s", svc.GetServiceID())
+	}
+
+	var value string
+	switch key {
+	case "":
+		value = image.RawName
+	case "name":
+		value = image.Name
+	case "short_name":
+		value = image.ShortName
+	case "tag":
+		value = image.Tag
+	case "registry":
+		value = image.Registry
+	default:
+		return "", fmt.Errorf("invalid image attribute %q for service %s", key, svc.GetServiceID())
+	}
+	if value == "" {
+		return "", fmt.Errorf("image %s not found for service %s", key, svc.GetServiceID())
+	}
+	return value, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/stretchr/testify/assert"
 
@@ -798,6 +799,204 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+type dummyWorkloadService struct {
+	dummyService
+	Labels      map[string]string
+	Annotations map[string]string
+	Image       *workloadmeta.ContainerImage
+}
+
+func (s *dummyWorkloadService) GetLabels() map[string]string {
+	return s.Labels
+}
+
+func (s *dummyWorkloadService) GetAnnotations() map[string]string {
+	return s.Annotations
+}
+
+func (s *dummyWorkloadService) GetImage() (workloadmeta.ContainerImage, bool) {
+	if s.Image == nil {
+		return workloadmeta.ContainerImage{}, false
+	}
+	return *s.Image, true
+}
+
+func TestResolveWorkload(t *testing.T) {
+	t.Setenv("test_envvar_key", "test_value")
+	os.Unsetenv("test_envvar_not_set")
+
+	newService := func() *dummyWorkloadService {
+		return &dummyWorkloadService{
+			dummyService: dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Hosts:         map[string]string{"bridge": "127.0.0.1"},
+				Ports:         newFakeContainerPorts(),
+			},
+			Labels: map[string]string{
+				"app.kubernetes.io/name": "redis",
+				"team":                   "storage",
+			},
+			Annotations: map[string]string{
+				"example.com/db-user": "datadog",
+			},
+			Image: &workloadmeta.ContainerImage{
+				RawName:   "docker.io/library/redis:7.2.4",
+				Name:      "docker.io/library/redis",
+				ShortName: "redis",
+				Registry:  "docker.io",
+				Tag:       "7.2.4",
+			},
+		}
+	}
+
+	testCases := []struct {
+		testName    string
+		tpl         integration.Config
+		svc         listeners.Service
+		out         integration.Config
+		errorString string
+	}{
+		{
+			testName: "default values",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("user: %%env_test_envvar_not_set|datadog%%\npassword: %%env_test_envvar_key|none%%\nport: %%port_metrics|6379%%\nhost: %%host|localhost%%")},
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("host: 127.0.0.1\npassword: test_value\nport: 6379\ntags:\n- foo:bar\nuser: datadog\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "labels, annotations and image",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("name: %%label_app.kubernetes.io/name%%\nuser: %%annotation_example.com/db-user%%\nversion: %%image_tag%%\nimage: %%image_short_name%%\nowner: %%label_owner|unknown%%")},
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("image: redis\nname: redis\nowner: unknown\ntags:\n- foo:bar\nuser: datadog\nversion: 7.2.4\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "label not found",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("owner: %%label_owner%%")},
+			},
+			errorString: "label \"owner\" not found for service a5901276aed1",
+		},
+		{
+			testName: "labels not supported",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("owner: %%label_owner%%")},
+			},
+			errorString: "labels are not supported for service a5901276aed1",
+		},
+		{
+			testName: "ad_condition matched",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				ADCondition: []integration.ADCondition{
+					{Value: "%%image_tag%%", Matches: `^7\.`},
+					{Value: "%%label_team%%", Equals: "storage"},
+					{Value: "%%label_disabled|false%%", Equals: "true", Negate: true},
+				},
+				Instances: []integration.Data{integration.Data("host: %%host%%")},
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("host: 127.0.0.1\ntags:\n- foo:bar\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "ad_condition not matched",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				ADCondition: []integration.ADCondition{
+					{Value: "%%image_tag%%", Matches: `^6\.`},
+				},
+				Instances: []integration.Data{integration.Data("host: %%host%%")},
+			},
+			errorString: ErrADConditionNotMatched.Error(),
+		},
+		{
+			testName: "ad_condition with unresolved value",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				ADCondition: []integration.ADCondition{
+					{Value: "%%label_owner%%", Equals: "storage"},
+				},
+				Instances: []integration.Data{integration.Data("host: %%host%%")},
+			},
+			errorString: ErrADConditionNotMatched.Error(),
+		},
+		{
+			testName: "ad_condition with invalid variable",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				ADCondition: []integration.ADCondition{
+					{Value: "%%foo_bar%%", Equals: "storage"},
+				},
+				Instances: []integration.Data{integration.Data("host: %%host%%")},
+			},
+			errorString: "invalid ad_condition: invalid %%foo%% tag in value \"%%foo_bar%%\"",
+		},
+		{
+			testName: "ad_condition with invalid regular expression",
+			svc:      newService(),
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				ADCondition: []integration.ADCondition{
+					{Value: "%%image_tag%%", Matches: `(`},
+				},
+				Instances: []integration.Data{integration.Data("host: %%host%%")},
+			},
+			errorString: "invalid ad_condition: invalid matches regular expression \"(\": error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.testName), func(t *testing.T) {
+			cfg, err := Resolve(tc.tpl, tc.svc)
+			if tc.errorString != "" {
+				assert.EqualError(t, err, tc.errorString)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.out, cfg)
+			}
+		})
+	}
+}
+
 func newFakeContainerPorts() []listeners.ContainerPort {
 	return []listeners.ContainerPort{
 		{Port: 1, Name: "foo"},
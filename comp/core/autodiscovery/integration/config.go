@@ -7,7 +7,9 @@
 package integration
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -73,6 +75,11 @@ type Config struct {
 	// see ADIdentifiers.  (optional)
 	AdvancedADIdentifiers []AdvancedADIdentifier `json:"advanced_ad_identifiers"` // (include in digest: false)
 
+	// ADCondition is the list of predicates a service must match for this
+	// template to be resolved against it. All the predicates must match.
+	// (optional)
+	ADCondition []ADCondition `json:"ad_condition,omitempty"` // (include in digest: true)
+
 	// Provider is the name of the config provider that issued the config.  If
 	// this is "", then the config is a service config, representing a service
 	// discovered by a listener.
@@ -132,6 +139,80 @@ type AdvancedADIdentifier struct {
 	KubeEndpoints KubeNamespacedName `yaml:"kube_endpoints,omitempty"`
 }
 
+// ADCondition is a predicate evaluated against a service before resolving a
+// template. Value can contain template variables, such as %%image_tag%% or
+// %%label_app%%, which are resolved against the service. The predicate
+// matches if the resolved value is equal to Equals, or matches the Matches
+// regular expression. A value that cannot be resolved never matches.
+type ADCondition struct {
+	Value   string `yaml:"value" json:"value"`
+	Equals  string `yaml:"equals,omitempty" json:"equals,omitempty"`
+	Matches string `yaml:"matches,omitempty" json:"matches,omitempty"`
+	// Negate inverts the result of the predicate
+	Negate bool `yaml:"negate,omitempty" json:"negate,omitempty"`
+
+	// matchesRegexp is Matches, compiled when the condition is parsed
+	matchesRegexp *regexp.Regexp
+}
+
+// Compile validates the condition and compiles its Matches regular
+// expression. It's called when the condition is parsed, so that invalid
+// conditions are reported when the template is loaded.
+func (c *ADCondition) Compile() error {
+	if (c.Equals == "") == (c.Matches == "") {
+		return fmt.Errorf("exactly one of equals or matches must be set for value %q", c.Value)
+	}
+	if c.Matches == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile(c.Matches)
+	if err != nil {
+		return fmt.Errorf("invalid matches regular expression %q: %w", c.Matches, err)
+	}
+	c.matchesRegexp = re
+	return nil
+}
+
+// MatchesRegexp returns the compiled Matches regular expression, nil when
+// the condition uses Equals. Conditions that were not parsed, but built in
+// code, are compiled on every call.
+func (c ADCondition) MatchesRegexp() (*regexp.Regexp, error) {
+	if c.matchesRegexp == nil {
+		if err := c.Compile(); err != nil {
+			return nil, err
+		}
+	}
+	return c.matchesRegexp, nil
+}
+
+// UnmarshalYAML parses and compiles a condition
+func (c *ADCondition) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type rawADCondition ADCondition
+	raw := rawADCondition{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*c = ADCondition(raw)
+	return c.Compile()
+}
+
+// UnmarshalJSON parses and compiles a condition
+func (c *ADCondition) UnmarshalJSON(data []byte) error {
+	type rawADCondition ADCondition
+	raw := rawADCondition{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = ADCondition(raw)
+	return c.Compile()
+}
+
+// String returns a string representation of the condition, used in digests.
+func (c ADCondition) String() string {
+	return fmt.Sprintf("%s|%s|%s|%t", c.Value, c.Equals, c.Matches, c.Negate)
+}
+
 // KubeNamespacedName identifies a kubernetes object.
 type KubeNamespacedName struct {
 	Name      string `yaml:"name"`
@@ -418,6 +499,9 @@ func (c *Config) IntDigest() uint64 {
 	_, _ = h.Write([]byte(c.LogsConfig))
 	_, _ = h.Write([]byte(c.ServiceID))
 	_, _ = h.Write([]byte(strconv.FormatBool(c.IgnoreAutodiscoveryTags)))
+	for _, cond := range c.ADCondition {
+		_, _ = h.Write([]byte(cond.String()))
+	}
 
 	return h.Sum64()
 }
@@ -441,6 +525,9 @@ func (c *Config) FastDigest() uint64 {
 	_, _ = h.Write([]byte(c.LogsConfig))
 	_, _ = h.Write([]byte(c.ServiceID))
 	_, _ = h.Write([]byte(strconv.FormatBool(c.IgnoreAutodiscoveryTags)))
+	for _, cond := range c.ADCondition {
+		_, _ = h.Write([]byte(cond.String()))
+	}
 
 	return h.Sum64()
 }
@@ -478,6 +565,7 @@ func (c *Config) Dump(multiline bool) string {
 	fmt.Fprintf(&b, ws("LogsConfig: %s,"), dataField(c.LogsConfig))
 	fmt.Fprintf(&b, ws("ADIdentifiers: %#v,"), c.ADIdentifiers)
 	fmt.Fprintf(&b, ws("AdvancedADIdentifiers: %#v,"), c.AdvancedADIdentifiers)
+	fmt.Fprintf(&b, ws("ADCondition: %#v,"), c.ADCondition)
 	fmt.Fprintf(&b, ws("Provider: %#v,"), c.Provider)
 	fmt.Fprintf(&b, ws("ServiceID: %#v,"), c.ServiceID)
 	fmt.Fprintf(&b, ws("TaggerEntity: %#v,"), c.TaggerEntity)
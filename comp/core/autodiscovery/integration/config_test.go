@@ -7,6 +7,7 @@ package integration
 
 import (
 	"crypto/rand"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -301,3 +302,20 @@ func BenchmarkID(b *testing.B) {
 	}
 	result = id
 }
+
+func TestADConditionUnmarshal(t *testing.T) {
+	var conditions []ADCondition
+	err := yaml.Unmarshal([]byte("- value: '%%image_tag%%'\n  matches: '^7\\.'\n"), &conditions)
+	assert.NoError(t, err)
+	re, err := conditions[0].MatchesRegexp()
+	assert.NoError(t, err)
+	assert.Same(t, conditions[0].matchesRegexp, re)
+
+	// invalid conditions are reported when parsed
+	err = yaml.Unmarshal([]byte("- value: '%%image_tag%%'\n  matches: '('\n"), &conditions)
+	assert.ErrorContains(t, err, "invalid matches regular expression")
+	err = json.Unmarshal([]byte(`[{"value": "%%image_tag%%", "matches": "("}]`), &conditions)
+	assert.ErrorContains(t, err, "invalid matches regular expression")
+	err = json.Unmarshal([]byte(`[{"value": "%%image_tag%%", "equals": "7", "matches": "^7"}]`), &conditions)
+	assert.ErrorContains(t, err, "exactly one of equals or matches must be set")
+}
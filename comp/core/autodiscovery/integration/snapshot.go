@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package integration
+
+import (
+	"sort"
+	"strings"
+)
+
+// ConfigSnapshot is a machine-readable snapshot of the configurations known
+// to autodiscovery, meant to be stored and compared.
+type ConfigSnapshot struct {
+	// Configs are the scheduled check and logs configurations
+	Configs []ConfigSnapshotEntry `json:"configs" yaml:"configs"`
+
+	// Unresolved are the templates, which are resolved against services
+	Unresolved []ConfigSnapshotEntry `json:"unresolved" yaml:"unresolved"`
+
+	// ConfigErrors are the errors of configurations that could not be
+	// loaded, by configuration name
+	ConfigErrors map[string]string `json:"config_errors,omitempty" yaml:"config_errors,omitempty"`
+}
+
+// ConfigSnapshotEntry describes a configuration in a ConfigSnapshot. The
+// configuration data is scrubbed, the digest is computed before scrubbing.
+type ConfigSnapshotEntry struct {
+	Name           string   `json:"name" yaml:"name"`
+	Digest         string   `json:"digest" yaml:"digest"`
+	Provider       string   `json:"provider" yaml:"provider"`
+	Source         string   `json:"source" yaml:"source"`
+	ServiceID      string   `json:"service_id,omitempty" yaml:"service_id,omitempty"`
+	NodeName       string   `json:"node_name,omitempty" yaml:"node_name,omitempty"`
+	TemplateDigest string   `json:"template_digest,omitempty" yaml:"template_digest,omitempty"`
+	ADIdentifiers  []string `json:"ad_identifiers,omitempty" yaml:"ad_identifiers,omitempty"`
+	ClusterCheck   bool     `json:"cluster_check,omitempty" yaml:"cluster_check,omitempty"`
+	InitConfig     string   `json:"init_config,omitempty" yaml:"init_config,omitempty"`
+	Instances      []string `json:"instances,omitempty" yaml:"instances,omitempty"`
+	LogsConfig     string   `json:"logs_config,omitempty" yaml:"logs_config,omitempty"`
+	ResolveErrors  []string `json:"resolve_errors,omitempty" yaml:"resolve_errors,omitempty"`
+}
+
+// NewConfigSnapshotEntry builds a ConfigSnapshotEntry from a configuration.
+// digest is the digest of the configuration before any scrubbing.
+func NewConfigSnapshotEntry(c Config, digest string) ConfigSnapshotEntry {
+	entry := ConfigSnapshotEntry{
+		Name:          c.Name,
+		Digest:        digest,
+		Provider:      c.Provider,
+		Source:        c.Source,
+		ServiceID:     c.ServiceID,
+		NodeName:      c.NodeName,
+		ADIdentifiers: c.ADIdentifiers,
+		ClusterCheck:  c.ClusterCheck,
+		InitConfig:    string(c.InitConfig),
+		LogsConfig:    string(c.LogsConfig),
+	}
+
+	for _, instance := range c.Instances {
+		entry.Instances = append(entry.Instances, string(instance))
+	}
+
+	return entry
+}
+
+// key identifies a configuration across snapshots, so that a configuration
+// whose content changed can be told apart from an added or removed one.
+func (e ConfigSnapshotEntry) key() string {
+	return strings.Join([]string{e.Provider, e.Source, e.Name, e.ServiceID, e.NodeName}, "|")
+}
+
+// SortConfigSnapshotEntries sorts entries by name, source and service ID to
+// make snapshots stable.
+func SortConfigSnapshotEntries(entries []ConfigSnapshotEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		if entries[i].Source != entries[j].Source {
+			return entries[i].Source < entries[j].Source
+		}
+		if entries[i].ServiceID != entries[j].ServiceID {
+			return entries[i].ServiceID < entries[j].ServiceID
+		}
+		return entries[i].Digest < entries[j].Digest
+	})
+}
+
+// ConfigSnapshotChange is a configuration present in both snapshots of a
+// diff, with a different digest.
+type ConfigSnapshotChange struct {
+	Old ConfigSnapshotEntry `json:"old" yaml:"old"`
+	New ConfigSnapshotEntry `json:"new" yaml:"new"`
+}
+
+// ConfigSnapshotDiff holds the differences between two snapshots
+type ConfigSnapshotDiff struct {
+	Added   []ConfigSnapshotEntry  `json:"added" yaml:"added"`
+	Removed []ConfigSnapshotEntry  `json:"removed" yaml:"removed"`
+	Changed []ConfigSnapshotChange `json:"changed" yaml:"changed"`
+}
+
+// IsEmpty returns true if the snapshots are identical
+func (d ConfigSnapshotDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffConfigSnapshots compares the scheduled configurations of two
+// snapshots. Unresolved templates are only compared through the configs
+// they are resolved to.
+func DiffConfigSnapshots(oldSnapshot, newSnapshot ConfigSnapshot) ConfigSnapshotDiff {
+	var diff ConfigSnapshotDiff
+
+	oldEntries := indexConfigSnapshotEntries(oldSnapshot.Configs)
+	newEntries := indexConfigSnapshotEntries(newSnapshot.Configs)
+
+	for key, newEntry := range newEntries {
+		oldEntry, found := oldEntries[key]
+		switch {
+		case !found:
+			diff.Added = append(diff.Added, newEntry)
+		case oldEntry.Digest != newEntry.Digest:
+			diff.Changed = append(diff.Changed, ConfigSnapshotChange{Old: oldEntry, New: newEntry})
+		}
+	}
+
+	for key, oldEntry := range oldEntries {
+		if _, found := newEntries[key]; !found {
+			diff.Removed = append(diff.Removed, oldEntry)
+		}
+	}
+
+	SortConfigSnapshotEntries(diff.Added)
+	SortConfigSnapshotEntries(diff.Removed)
+	sort.SliceStable(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].New.key() < diff.Changed[j].New.key()
+	})
+
+	return diff
+}
+
+func indexConfigSnapshotEntries(entries []ConfigSnapshotEntry) map[string]ConfigSnapshotEntry {
+	index := make(map[string]ConfigSnapshotEntry, len(entries))
+	for _, entry := range entries {
+		key := entry.key()
+		// Configs with the same identity can only be told apart by content
+		if _, found := index[key]; found {
+			key += "|" + entry.Digest
+		}
+		index[key] = entry
+	}
+	return index
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffConfigSnapshots(t *testing.T) {
+	redis := ConfigSnapshotEntry{Name: "redisdb", Digest: "1", Provider: "container", Source: "container_id://abc", ServiceID: "container_id://abc"}
+	redisChanged := redis
+	redisChanged.Digest = "2"
+	cpu := ConfigSnapshotEntry{Name: "cpu", Digest: "3", Provider: "file", Source: "file:/etc/datadog-agent/conf.d/cpu.d/conf.yaml.default"}
+	nginx := ConfigSnapshotEntry{Name: "nginx", Digest: "4", Provider: "container", Source: "container_id://def", ServiceID: "container_id://def"}
+	httpCheck1 := ConfigSnapshotEntry{Name: "http_check", Digest: "5", Provider: "file", Source: "file:/etc/datadog-agent/conf.d/http_check.d/conf.yaml"}
+	httpCheck2 := httpCheck1
+	httpCheck2.Digest = "6"
+
+	oldSnapshot := ConfigSnapshot{Configs: []ConfigSnapshotEntry{redis, cpu, httpCheck1, httpCheck2}}
+	newSnapshot := ConfigSnapshot{Configs: []ConfigSnapshotEntry{redisChanged, nginx, httpCheck1, httpCheck2}}
+
+	diff := DiffConfigSnapshots(oldSnapshot, newSnapshot)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []ConfigSnapshotEntry{nginx}, diff.Added)
+	assert.Equal(t, []ConfigSnapshotEntry{cpu}, diff.Removed)
+	assert.Equal(t, []ConfigSnapshotChange{{Old: redis, New: redisChanged}}, diff.Changed)
+
+	assert.True(t, DiffConfigSnapshots(newSnapshot, newSnapshot).IsEmpty())
+}
+
+func TestNewConfigSnapshotEntry(t *testing.T) {
+	c := Config{
+		Name:          "redisdb",
+		Provider:      "container",
+		Source:        "container_id://abc",
+		ServiceID:     "container_id://abc",
+		ADIdentifiers: []string{"redis"},
+		InitConfig:    Data("{}"),
+		Instances:     []Data{Data("host: localhost"), Data("host: 127.0.0.1")},
+	}
+
+	assert.Equal(t, ConfigSnapshotEntry{
+		Name:          "redisdb",
+		Digest:        c.Digest(),
+		Provider:      "container",
+		Source:        "container_id://abc",
+		ServiceID:     "container_id://abc",
+		ADIdentifiers: []string{"redis"},
+		InitConfig:    "{}",
+		Instances:     []string{"host: localhost", "host: 127.0.0.1"},
+	}, NewConfigSnapshotEntry(c, c.Digest()))
+}
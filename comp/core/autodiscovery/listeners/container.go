@@ -123,10 +123,14 @@ func (l *ContainerListener) createContainerService(entity workloadmeta.Entity) {
 		ports:    ports,
 		pid:      container.PID,
 		hostname: container.Hostname,
+		labels:   container.Labels,
+		image:    &containerImg,
 		tagger:   l.tagger,
 	}
 
 	if pod != nil {
+		svc.labels = mergeLabels(container.Labels, pod.Labels)
+		svc.annotations = pod.Annotations
 		svc.hosts = map[string]string{"pod": pod.IP}
 		svc.ready = pod.Ready
 
@@ -177,6 +177,7 @@ func TestCreateContainerService(t *testing.T) {
 					service: &service{
 						tagger: taggerComponent,
 						entity: basicContainer,
+						image:  &basicContainer.Image,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"gcr.io/foobar",
@@ -214,6 +215,7 @@ func TestCreateContainerService(t *testing.T) {
 					service: &service{
 						tagger: taggerComponent,
 						entity: runningContainerWithFinishedAtTime,
+						image:  &runningContainerWithFinishedAtTime.Image,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"gcr.io/foobar",
@@ -234,6 +236,7 @@ func TestCreateContainerService(t *testing.T) {
 					service: &service{
 						tagger: taggerComponent,
 						entity: multiplePortsContainer,
+						image:  &multiplePortsContainer.Image,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -261,8 +264,11 @@ func TestCreateContainerService(t *testing.T) {
 			expectedServices: map[string]wlmListenerSvc{
 				"container://foo": {
 					service: &service{
-						tagger: taggerComponent,
-						entity: kubernetesContainer,
+						tagger:      taggerComponent,
+						entity:      kubernetesContainer,
+						labels:      kubernetesContainer.Labels,
+						annotations: pod.Annotations,
+						image:       &kubernetesContainer.Image,
 						adIdentifiers: []string{
 							"docker://foo",
 							"gcr.io/foobar",
@@ -101,6 +101,8 @@ func (l *KubeletListener) createPodService(
 		hosts:         map[string]string{"pod": pod.IP},
 		ports:         ports,
 		ready:         true,
+		labels:        pod.Labels,
+		annotations:   pod.Annotations,
 		tagger:        l.tagger,
 	}
 
@@ -167,7 +169,10 @@ func (l *KubeletListener) createContainerService(
 			"namespace": pod.Namespace,
 			"pod_uid":   pod.ID,
 		},
-		hosts: map[string]string{"pod": pod.IP},
+		hosts:       map[string]string{"pod": pod.IP},
+		labels:      mergeLabels(container.Labels, pod.Labels),
+		annotations: pod.Annotations,
+		image:       &containerImg,
 
 		// Exclude non-running containers (including init containers)
 		// from metrics collection but keep them for collecting logs.
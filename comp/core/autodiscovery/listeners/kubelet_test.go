@@ -248,6 +248,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: basicContainer,
+						image:  &imageWithShortname,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"gcr.io/foobar:latest",
@@ -281,6 +282,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: recentlyStoppedContainer,
+						image:  &basicImage,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -337,6 +339,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: runningContainerWithFinishedAtTime,
+						image:  &basicImage,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -369,6 +372,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: multiplePortsContainer,
+						image:  &basicImage,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -409,7 +413,9 @@ func TestKubeletCreateContainerService(t *testing.T) {
 				"container://foobarquux": {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
-						entity: customIDsContainer,
+						entity:      customIDsContainer,
+						annotations: podWithAnnotations.Annotations,
+						image:       &basicImage,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -454,7 +460,9 @@ func TestKubeletCreateContainerService(t *testing.T) {
 				"container://foobarquux": {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
-						entity: customIDsContainer,
+						entity:      customIDsContainer,
+						annotations: podWithMetricsExcludeAnnotation.Annotations,
+						image:       &basicImage,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -489,7 +497,9 @@ func TestKubeletCreateContainerService(t *testing.T) {
 				"container://foobarquux": {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
-						entity: customIDsContainer,
+						entity:      customIDsContainer,
+						annotations: podWithLogsExcludeAnnotation.Annotations,
+						image:       &basicImage,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/systemd"
 )
 
 // service implements the Service interface and stores data collected from
@@ -35,12 +36,16 @@ type service struct {
 	ready           bool
 	checkNames      []string
 	extraConfig     map[string]string
+	labels          map[string]string
+	annotations     map[string]string
+	image           *workloadmeta.ContainerImage
 	metricsExcluded bool
 	logsExcluded    bool
 	tagger          tagger.Component
 }
 
 var _ Service = &service{}
+var _ WorkloadService = &service{}
 
 // Equal returns whether the two service are equal
 func (s *service) Equal(o Service) bool {
@@ -55,6 +60,8 @@ func (s *service) Equal(o Service) bool {
 		reflect.DeepEqual(s.ports, s2.ports) &&
 		reflect.DeepEqual(s.adIdentifiers, s2.adIdentifiers) &&
 		reflect.DeepEqual(s.checkNames, s2.checkNames) &&
+		reflect.DeepEqual(s.labels, s2.labels) &&
+		reflect.DeepEqual(s.annotations, s2.annotations) &&
 		s.hostname == s2.hostname &&
 		s.pid == s2.pid &&
 		s.ready == s2.ready
@@ -67,6 +74,8 @@ func (s *service) GetServiceID() string {
 		return containers.BuildEntityName(string(e.Runtime), e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToEntityName(e.ID)
+	case *workloadmeta.SystemdUnit:
+		return systemd.BuildEntityName(e.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -215,3 +224,38 @@ func (s *service) GetExtraConfig(key string) (string, error) {
 
 	return result, nil
 }
+
+// GetLabels returns the labels of the container, merged with the labels of
+// its pod, or the labels of the pod.
+func (s *service) GetLabels() map[string]string {
+	return s.labels
+}
+
+// GetAnnotations returns the annotations of the pod of the service.
+func (s *service) GetAnnotations() map[string]string {
+	return s.annotations
+}
+
+// GetImage returns the image of the container of the service.
+func (s *service) GetImage() (workloadmeta.ContainerImage, bool) {
+	if s.image == nil {
+		return workloadmeta.ContainerImage{}, false
+	}
+	return *s.image, true
+}
+
+// mergeLabels returns the labels of a container merged with the labels of
+// its pod, the latter taking precedence.
+func mergeLabels(containerLabels, podLabels map[string]string) map[string]string {
+	if len(containerLabels) == 0 && len(podLabels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(containerLabels)+len(podLabels))
+	for k, v := range containerLabels {
+		labels[k] = v
+	}
+	for k, v := range podLabels {
+		labels[k] = v
+	}
+	return labels
+}
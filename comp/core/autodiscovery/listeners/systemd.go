@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/common/utils"
+	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/systemd"
+)
+
+// SystemdListener listens to the systemd services started on the host
+// through a subscription to the workloadmeta store.
+type SystemdListener struct {
+	workloadmetaListener
+	tagger tagger.Component
+}
+
+// NewSystemdListener returns a new SystemdListener.
+func NewSystemdListener(options ServiceListernerDeps) (ServiceListener, error) {
+	const name = "ad-systemdlistener"
+	l := &SystemdListener{}
+	filter := workloadmeta.NewFilterBuilder().
+		AddKind(workloadmeta.KindSystemdUnit).Build()
+
+	wmetaInstance, ok := options.Wmeta.Get()
+	if !ok {
+		return nil, errors.New("workloadmeta store is not initialized")
+	}
+	var err error
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, filter, l.createSystemdService, wmetaInstance, options.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	l.tagger = options.Tagger
+
+	return l, nil
+}
+
+func (l *SystemdListener) createSystemdService(entity workloadmeta.Entity) {
+	unit := entity.(*workloadmeta.SystemdUnit)
+
+	checkNames, err := utils.ExtractCheckNamesFromContainerLabels(unit.Annotations)
+	if err != nil {
+		log.Errorf("error getting check names from annotations on systemd unit %s: %v", unit.ID, err)
+	}
+
+	svc := &service{
+		entity:        unit,
+		tagsHash:      l.tagger.GetEntityHash(types.NewEntityID(types.SystemdUnit, unit.ID), l.tagger.ChecksCardinality()),
+		adIdentifiers: []string{systemd.BuildEntityName(unit.ID)},
+		// services run on the host, the checks reach them locally
+		hosts:       map[string]string{"host": "127.0.0.1"},
+		pid:         unit.MainPID,
+		ready:       unit.ActiveState == "active",
+		checkNames:  checkNames,
+		annotations: unit.Annotations,
+		tagger:      l.tagger,
+	}
+
+	l.AddService(buildSvcID(unit.GetID()), svc, "")
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/mock"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+func TestCreateSystemdService(t *testing.T) {
+	taggerComponent := mock.SetupFakeTagger(t)
+
+	unit := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "redis.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "redis.service",
+			Annotations: map[string]string{
+				"com.datadoghq.ad.check_names":  `["redisdb"]`,
+				"com.datadoghq.ad.init_configs": `[{}]`,
+				"com.datadoghq.ad.instances":    `[{"host": "%%host%%", "port": 6379}]`,
+			},
+		},
+		ActiveState: "active",
+		MainPID:     1234,
+	}
+
+	listener, wlm := newSystemdListener(t, taggerComponent)
+	listener.createSystemdService(unit)
+
+	wlm.assertServices(map[string]wlmListenerSvc{
+		"systemd_unit://redis.service": {
+			service: &service{
+				entity:        unit,
+				adIdentifiers: []string{"systemd_unit://redis.service"},
+				hosts:         map[string]string{"host": "127.0.0.1"},
+				pid:           1234,
+				ready:         true,
+				checkNames:    []string{"redisdb"},
+				annotations:   unit.Annotations,
+				tagger:        taggerComponent,
+			},
+		},
+	})
+}
+
+func newSystemdListener(t *testing.T, tagger tagger.Component) (*SystemdListener, *testWorkloadmetaListener) {
+	wlm := newTestWorkloadmetaListener(t)
+
+	return &SystemdListener{workloadmetaListener: wlm, tagger: tagger}, wlm
+}
@@ -51,6 +51,15 @@ type Service interface {
 	FilterTemplates(map[string]integration.Config)
 }
 
+// WorkloadService is implemented by the services backed by a workload
+// (container or pod). It exposes the workload metadata that can be used by
+// the template variables and the conditions of the templates.
+type WorkloadService interface {
+	GetLabels() map[string]string                  // labels of the container and of its pod
+	GetAnnotations() map[string]string             // annotations of the pod
+	GetImage() (workloadmeta.ContainerImage, bool) // image of the container
+}
+
 // ServiceListener monitors running services and triggers check (un)scheduling
 //
 // It holds a cache of running services, listens to new/killed services and
@@ -28,6 +28,7 @@ import (
 type configFormat struct {
 	ADIdentifiers           []string                           `yaml:"ad_identifiers"`
 	AdvancedADIdentifiers   []integration.AdvancedADIdentifier `yaml:"advanced_ad_identifiers"`
+	ADCondition             []integration.ADCondition          `yaml:"ad_condition"`
 	ClusterCheck            bool                               `yaml:"cluster_check"`
 	InitConfig              interface{}                        `yaml:"init_config"`
 	MetricConfig            interface{}                        `yaml:"jmx_metrics"`
@@ -424,6 +425,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Copy auto discovery identifiers
 	conf.ADIdentifiers = cf.ADIdentifiers
 	conf.AdvancedADIdentifiers = cf.AdvancedADIdentifiers
+	conf.ADCondition = cf.ADCondition
 
 	// Copy cluster_check status
 	conf.ClusterCheck = cf.ClusterCheck
@@ -301,6 +301,8 @@ func buildEntityName(e workloadmeta.Entity) string {
 		return fmt.Sprintf("%s/%s (%s)", entity.Namespace, entity.Name, entity.ID)
 	case *workloadmeta.Container:
 		return containers.BuildEntityName(string(entity.Runtime), entityID.ID)
+	case *workloadmeta.NomadAllocation:
+		return fmt.Sprintf("%s/%s (%s)", entity.Namespace, entity.Name, entity.ID)
 	default:
 		return fmt.Sprintf("%s://%s", entityID.Kind, entityID.ID)
 	}
@@ -22,10 +22,12 @@ const (
 	KubeServicesFile   = "kubernetes-services-file"
 	KubeEndpoints      = "kubernetes-endpoints"
 	KubeEndpointsFile  = "kubernetes-endpoints-file"
+	Nomad              = "nomad"
 	PrometheusPods     = "prometheus-pods"
 	PrometheusServices = "prometheus-services"
 	RemoteConfig       = "remote-config"
 	SNMP               = "snmp"
+	Systemd            = "systemd"
 	Zookeeper          = "zookeeper"
 )
 
@@ -44,8 +46,10 @@ const (
 	KubeServicesFileRegisterName   = "kube_services_file"
 	KubeEndpointsRegisterName      = "kube_endpoints"
 	KubeEndpointsFileRegisterName  = "kube_endpoints_file"
+	NomadRegisterName              = "nomad"
 	PrometheusPodsRegisterName     = "prometheus_pods"
 	PrometheusServicesRegisterName = "prometheus_services"
 	RemoteConfigRegisterName       = "remote_config"
+	SystemdRegisterName            = "systemd"
 	ZookeeperRegisterName          = "zookeeper"
 )
@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/common/utils"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/telemetry"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// NomadConfigProvider implements the ConfigProvider interface for the
+// services of Nomad allocations. It reads the check templates from the
+// metadata of the services, and targets them at the containers running the
+// tasks declaring the services.
+type NomadConfigProvider struct {
+	workloadmetaStore workloadmeta.Component
+	configErrors      map[string]ErrorMsgSet                   // map[entity name]ErrorMsgSet
+	configCache       map[string]map[string]integration.Config // map[entity name]map[config digest]integration.Config
+	mu                sync.RWMutex
+	telemetryStore    *telemetry.Store
+}
+
+// NewNomadConfigProvider returns a new ConfigProvider subscribed to the Nomad
+// allocations
+func NewNomadConfigProvider(_ *pkgconfigsetup.ConfigurationProviders, wmeta workloadmeta.Component, telemetryStore *telemetry.Store) (ConfigProvider, error) {
+	return &NomadConfigProvider{
+		workloadmetaStore: wmeta,
+		configCache:       make(map[string]map[string]integration.Config),
+		configErrors:      make(map[string]ErrorMsgSet),
+		telemetryStore:    telemetryStore,
+	}, nil
+}
+
+// String returns a string representation of the NomadConfigProvider
+func (n *NomadConfigProvider) String() string {
+	return names.Nomad
+}
+
+// Stream starts listening to workloadmeta to generate configs as they come
+// instead of relying on a periodic call to Collect.
+func (n *NomadConfigProvider) Stream(ctx context.Context) <-chan integration.ConfigChanges {
+	const name = "ad-nomadprovider"
+
+	// outCh must be unbuffered, see ContainerConfigProvider.Stream
+	outCh := make(chan integration.ConfigChanges)
+
+	filter := workloadmeta.NewFilterBuilder().
+		AddKind(workloadmeta.KindNomadAllocation).
+		Build()
+	inCh := n.workloadmetaStore.Subscribe(name, workloadmeta.ConfigProviderPriority, filter)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				n.workloadmetaStore.Unsubscribe(inCh)
+
+			case evBundle, ok := <-inCh:
+				if !ok {
+					return
+				}
+
+				// send changes even when they're empty, as we
+				// need to signal that an event has been
+				// received, for flow control reasons
+				outCh <- n.processEvents(evBundle)
+				evBundle.Acknowledge()
+			}
+		}
+	}()
+
+	return outCh
+}
+
+func (n *NomadConfigProvider) processEvents(evBundle workloadmeta.EventBundle) integration.ConfigChanges {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	changes := integration.ConfigChanges{}
+
+	for _, event := range evBundle.Events {
+		entityName := buildEntityName(event.Entity)
+
+		switch event.Type {
+		case workloadmeta.EventTypeSet:
+			alloc, ok := event.Entity.(*workloadmeta.NomadAllocation)
+			if !ok {
+				log.Errorf("cannot handle entity of kind %s", event.Entity.GetID().Kind)
+				continue
+			}
+
+			configs, err := n.generateConfig(alloc)
+			if err != nil {
+				n.configErrors[entityName] = err
+			} else {
+				delete(n.configErrors, entityName)
+			}
+
+			configCache, ok := n.configCache[entityName]
+			if !ok {
+				configCache = make(map[string]integration.Config)
+				n.configCache[entityName] = configCache
+			}
+
+			configsToUnschedule := make(map[string]integration.Config)
+			for digest, config := range configCache {
+				configsToUnschedule[digest] = config
+			}
+
+			for _, config := range configs {
+				digest := config.Digest()
+				if _, ok := configCache[digest]; ok {
+					delete(configsToUnschedule, digest)
+				} else {
+					configCache[digest] = config
+					changes.ScheduleConfig(config)
+				}
+			}
+
+			for oldDigest, oldConfig := range configsToUnschedule {
+				delete(configCache, oldDigest)
+				changes.UnscheduleConfig(oldConfig)
+			}
+
+		case workloadmeta.EventTypeUnset:
+			oldConfigs, found := n.configCache[entityName]
+			if !found {
+				log.Debugf("entity %q removed from workloadmeta store but not found in cache. skipping", entityName)
+				continue
+			}
+
+			for _, oldConfig := range oldConfigs {
+				changes.UnscheduleConfig(oldConfig)
+			}
+
+			delete(n.configCache, entityName)
+			delete(n.configErrors, entityName)
+
+		default:
+			log.Errorf("cannot handle event of type %d", event.Type)
+		}
+	}
+
+	if n.telemetryStore != nil {
+		n.telemetryStore.Errors.Set(float64(len(n.configErrors)), names.Nomad)
+	}
+
+	return changes
+}
+
+func (n *NomadConfigProvider) generateConfig(alloc *workloadmeta.NomadAllocation) ([]integration.Config, ErrorMsgSet) {
+	var (
+		errs    []error
+		configs []integration.Config
+	)
+
+	taskContainers := make(map[string]*workloadmeta.Container, len(alloc.Tasks))
+	for _, task := range alloc.Tasks {
+		if task.ContainerID == "" {
+			continue
+		}
+
+		container, err := n.workloadmetaStore.GetContainer(task.ContainerID)
+		if err != nil {
+			log.Debugf("Allocation %q has reference to non-existing container %q", alloc.Name, task.ContainerID)
+			continue
+		}
+		taskContainers[task.Name] = container
+	}
+
+	for _, svc := range alloc.Services {
+		container := serviceContainer(svc, taskContainers)
+		if container == nil {
+			// the templates can't target any container, only report
+			// them when there are some
+			if c, _ := utils.ExtractTemplatesFromNomadServiceMeta("", svc.Meta); len(c) > 0 {
+				errs = append(errs, fmt.Errorf("could not find the container running service %q: its task must run in a container, and group services are only supported when a single task of the group does", svc.Name))
+			}
+			continue
+		}
+
+		containerEntityName := containers.BuildEntityName(string(container.Runtime), container.ID)
+		c, errors := utils.ExtractTemplatesFromNomadServiceMeta(containerEntityName, svc.Meta)
+		for _, err := range errors {
+			errs = append(errs, fmt.Errorf("service %q: %w", svc.Name, err))
+		}
+
+		for idx := range c {
+			c[idx].Source = fmt.Sprintf("%s:%s/%s", names.Nomad, alloc.ID, svc.Name)
+		}
+
+		configs = append(configs, c...)
+	}
+
+	var errMsgSet ErrorMsgSet
+	if len(errs) > 0 {
+		errMsgSet = make(ErrorMsgSet)
+		for _, err := range errs {
+			errMsgSet[err.Error()] = struct{}{}
+		}
+	}
+
+	return configs, errMsgSet
+}
+
+// serviceContainer returns the container running the task declaring a
+// service. Group services are attributed to the only task of the group
+// running in a container, if any.
+func serviceContainer(svc workloadmeta.NomadService, taskContainers map[string]*workloadmeta.Container) *workloadmeta.Container {
+	if svc.Task != "" {
+		return taskContainers[svc.Task]
+	}
+
+	if len(taskContainers) != 1 {
+		return nil
+	}
+
+	for _, container := range taskContainers {
+		return container
+	}
+
+	return nil
+}
+
+// GetConfigErrors returns a map of configuration errors for each allocation
+func (n *NomadConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	errors := make(map[string]ErrorMsgSet, len(n.configErrors))
+
+	for entity, errset := range n.configErrors {
+		errors[entity] = errset
+	}
+
+	return errors
+}
@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	workloadmetafxmock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/fx-mock"
+	workloadmetamock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/mock"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const nomadAllocID = "5456bd7a-9fc0-c0dd-6131-cbee77f57577"
+
+func nomadAllocation(services ...workloadmeta.NomadService) *workloadmeta.NomadAllocation {
+	return &workloadmeta.NomadAllocation{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindNomadAllocation,
+			ID:   nomadAllocID,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web.frontend[0]",
+			Namespace: "default",
+		},
+		JobID:     "web",
+		TaskGroup: "frontend",
+		Tasks: []workloadmeta.NomadTask{
+			{Name: "nginx", Driver: "docker", ContainerID: "nginx-id"},
+			{Name: "logrotate", Driver: "exec"},
+		},
+		Services: services,
+	}
+}
+
+var nginxService = workloadmeta.NomadService{
+	Name:      "nginx",
+	PortLabel: "http",
+	Meta: map[string]string{
+		"datadog_ad_check_names":  `["nginx"]`,
+		"datadog_ad_init_configs": `[{}]`,
+		"datadog_ad_instances":    `[{"nginx_status_url":"http://%%host%%:%%port%%/status"}]`,
+	},
+}
+
+var nginxConfig = integration.Config{
+	Name:          "nginx",
+	Instances:     []integration.Data{integration.Data(`{"nginx_status_url":"http://%%host%%:%%port%%/status"}`)},
+	InitConfig:    integration.Data("{}"),
+	ADIdentifiers: []string{"docker://nginx-id"},
+	Source:        "nomad:" + nomadAllocID + "/nginx",
+}
+
+func newTestNomadConfigProvider(t *testing.T) *NomadConfigProvider {
+	store := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		config.MockModule(),
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   "nginx-id",
+		},
+		Runtime: workloadmeta.ContainerRuntimeDocker,
+	})
+
+	return &NomadConfigProvider{
+		workloadmetaStore: store,
+		configCache:       make(map[string]map[string]integration.Config),
+		configErrors:      make(map[string]ErrorMsgSet),
+	}
+}
+
+func TestNomadProcessEvents(t *testing.T) {
+	np := newTestNomadConfigProvider(t)
+
+	tests := []struct {
+		name    string
+		events  []workloadmeta.Event
+		changes integration.ConfigChanges
+	}{
+		{
+			name: "create config",
+			events: []workloadmeta.Event{
+				{
+					Type:   workloadmeta.EventTypeSet,
+					Entity: nomadAllocation(nginxService),
+				},
+			},
+			changes: integration.ConfigChanges{
+				Schedule: []integration.Config{nginxConfig},
+			},
+		},
+		{
+			name: "unchanged config",
+			events: []workloadmeta.Event{
+				{
+					Type:   workloadmeta.EventTypeSet,
+					Entity: nomadAllocation(nginxService),
+				},
+			},
+			changes: integration.ConfigChanges{},
+		},
+		{
+			name: "delete config",
+			events: []workloadmeta.Event{
+				{
+					Type:   workloadmeta.EventTypeUnset,
+					Entity: nomadAllocation(nginxService),
+				},
+			},
+			changes: integration.ConfigChanges{
+				Unschedule: []integration.Config{nginxConfig},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := np.processEvents(workloadmeta.EventBundle{
+				Events: tt.events,
+			})
+
+			assert.Equal(t, tt.changes.Schedule, changes.Schedule)
+			assert.Equal(t, tt.changes.Unschedule, changes.Unschedule)
+		})
+	}
+}
+
+func TestNomadGenerateConfig(t *testing.T) {
+	taskService := nginxService
+	taskService.Task = "nginx"
+
+	tests := []struct {
+		name            string
+		alloc           *workloadmeta.NomadAllocation
+		expectedConfigs []integration.Config
+		expectedErr     ErrorMsgSet
+	}{
+		{
+			name:  "no services",
+			alloc: nomadAllocation(),
+		},
+		{
+			name: "service without templates",
+			alloc: nomadAllocation(workloadmeta.NomadService{
+				Name: "nginx",
+				Meta: map[string]string{"team": "web"},
+			}),
+		},
+		{
+			name:            "group service",
+			alloc:           nomadAllocation(nginxService),
+			expectedConfigs: []integration.Config{nginxConfig},
+		},
+		{
+			name:            "task service",
+			alloc:           nomadAllocation(taskService),
+			expectedConfigs: []integration.Config{nginxConfig},
+		},
+		{
+			name: "service of a task not running in a container",
+			alloc: nomadAllocation(workloadmeta.NomadService{
+				Name: "logrotate",
+				Task: "logrotate",
+				Meta: nginxService.Meta,
+			}),
+			expectedErr: ErrorMsgSet{
+				`could not find the container running service "logrotate": its task must run in a container, and group services are only supported when a single task of the group does`: {},
+			},
+		},
+		{
+			name: "invalid templates",
+			alloc: nomadAllocation(workloadmeta.NomadService{
+				Name: "nginx",
+				Meta: map[string]string{
+					"datadog_ad_check_names": `["nginx"]`,
+					"datadog_ad_instances":   `[{}]`,
+				},
+			}),
+			expectedErr: ErrorMsgSet{
+				`service "nginx": could not extract checks config: missing init_configs key`: {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			np := newTestNomadConfigProvider(t)
+
+			configs, err := np.generateConfig(tt.alloc)
+			assert.Equal(t, tt.expectedErr, err)
+			assert.ElementsMatch(t, tt.expectedConfigs, configs)
+		})
+	}
+}
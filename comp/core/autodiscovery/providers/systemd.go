@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/common/utils"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/telemetry"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/systemd"
+)
+
+// SystemdConfigProvider implements the ConfigProvider interface for the
+// systemd services. It reads the check templates from the annotations
+// declared in the `[X-Datadog]` section of the drop-in files of the units.
+type SystemdConfigProvider struct {
+	workloadmetaStore workloadmeta.Component
+	configErrors      map[string]ErrorMsgSet                   // map[entity name]ErrorMsgSet
+	configCache       map[string]map[string]integration.Config // map[entity name]map[config digest]integration.Config
+	mu                sync.RWMutex
+	telemetryStore    *telemetry.Store
+}
+
+// NewSystemdConfigProvider returns a new ConfigProvider subscribed to the
+// systemd units
+func NewSystemdConfigProvider(_ *pkgconfigsetup.ConfigurationProviders, wmeta workloadmeta.Component, telemetryStore *telemetry.Store) (ConfigProvider, error) {
+	return &SystemdConfigProvider{
+		workloadmetaStore: wmeta,
+		configCache:       make(map[string]map[string]integration.Config),
+		configErrors:      make(map[string]ErrorMsgSet),
+		telemetryStore:    telemetryStore,
+	}, nil
+}
+
+// String returns a string representation of the SystemdConfigProvider
+func (s *SystemdConfigProvider) String() string {
+	return names.Systemd
+}
+
+// Stream starts listening to workloadmeta to generate configs as they come
+// instead of relying on a periodic call to Collect.
+func (s *SystemdConfigProvider) Stream(ctx context.Context) <-chan integration.ConfigChanges {
+	const name = "ad-systemdprovider"
+
+	// outCh must be unbuffered, see ContainerConfigProvider.Stream
+	outCh := make(chan integration.ConfigChanges)
+
+	filter := workloadmeta.NewFilterBuilder().
+		AddKind(workloadmeta.KindSystemdUnit).
+		Build()
+	inCh := s.workloadmetaStore.Subscribe(name, workloadmeta.ConfigProviderPriority, filter)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				s.workloadmetaStore.Unsubscribe(inCh)
+
+			case evBundle, ok := <-inCh:
+				if !ok {
+					return
+				}
+
+				// send changes even when they're empty, as we
+				// need to signal that an event has been
+				// received, for flow control reasons
+				outCh <- s.processEvents(evBundle)
+				evBundle.Acknowledge()
+			}
+		}
+	}()
+
+	return outCh
+}
+
+func (s *SystemdConfigProvider) processEvents(evBundle workloadmeta.EventBundle) integration.ConfigChanges {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes := integration.ConfigChanges{}
+
+	for _, event := range evBundle.Events {
+		entityName := buildEntityName(event.Entity)
+
+		switch event.Type {
+		case workloadmeta.EventTypeSet:
+			unit, ok := event.Entity.(*workloadmeta.SystemdUnit)
+			if !ok {
+				log.Errorf("cannot handle entity of kind %s", event.Entity.GetID().Kind)
+				continue
+			}
+
+			configs, err := s.generateConfig(unit)
+			if err != nil {
+				s.configErrors[entityName] = err
+			} else {
+				delete(s.configErrors, entityName)
+			}
+
+			configCache, ok := s.configCache[entityName]
+			if !ok {
+				configCache = make(map[string]integration.Config)
+				s.configCache[entityName] = configCache
+			}
+
+			configsToUnschedule := make(map[string]integration.Config)
+			for digest, config := range configCache {
+				configsToUnschedule[digest] = config
+			}
+
+			for _, config := range configs {
+				digest := config.Digest()
+				if _, ok := configCache[digest]; ok {
+					delete(configsToUnschedule, digest)
+				} else {
+					configCache[digest] = config
+					changes.ScheduleConfig(config)
+				}
+			}
+
+			for oldDigest, oldConfig := range configsToUnschedule {
+				delete(configCache, oldDigest)
+				changes.UnscheduleConfig(oldConfig)
+			}
+
+		case workloadmeta.EventTypeUnset:
+			oldConfigs, found := s.configCache[entityName]
+			if !found {
+				log.Debugf("entity %q removed from workloadmeta store but not found in cache. skipping", entityName)
+				continue
+			}
+
+			for _, oldConfig := range oldConfigs {
+				changes.UnscheduleConfig(oldConfig)
+			}
+
+			delete(s.configCache, entityName)
+			delete(s.configErrors, entityName)
+
+		default:
+			log.Errorf("cannot handle event of type %d", event.Type)
+		}
+	}
+
+	if s.telemetryStore != nil {
+		s.telemetryStore.Errors.Set(float64(len(s.configErrors)), names.Systemd)
+	}
+
+	return changes
+}
+
+func (s *SystemdConfigProvider) generateConfig(unit *workloadmeta.SystemdUnit) ([]integration.Config, ErrorMsgSet) {
+	configs, errs := utils.ExtractTemplatesFromContainerLabels(systemd.BuildEntityName(unit.ID), unit.Annotations)
+
+	for idx := range configs {
+		configs[idx].Source = fmt.Sprintf("%s:%s", names.Systemd, unit.ID)
+	}
+
+	var errMsgSet ErrorMsgSet
+	if len(errs) > 0 {
+		errMsgSet = make(ErrorMsgSet)
+		for _, err := range errs {
+			errMsgSet[err.Error()] = struct{}{}
+		}
+	}
+
+	return configs, errMsgSet
+}
+
+// GetConfigErrors returns a map of configuration errors for each systemd unit
+func (s *SystemdConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	errors := make(map[string]ErrorMsgSet, len(s.configErrors))
+
+	for entity, errset := range s.configErrors {
+		errors[entity] = errset
+	}
+
+	return errors
+}
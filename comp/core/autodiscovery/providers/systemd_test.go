@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+func systemdUnit(annotations map[string]string) *workloadmeta.SystemdUnit {
+	return &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "redis.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        "redis.service",
+			Annotations: annotations,
+		},
+		ActiveState: "active",
+		MainPID:     1234,
+	}
+}
+
+func TestSystemdProcessEvents(t *testing.T) {
+	sp := &SystemdConfigProvider{
+		configCache:  make(map[string]map[string]integration.Config),
+		configErrors: make(map[string]ErrorMsgSet),
+	}
+
+	redisAnnotations := map[string]string{
+		"com.datadoghq.ad.check_names":  `["redisdb"]`,
+		"com.datadoghq.ad.init_configs": `[{}]`,
+		"com.datadoghq.ad.instances":    `[{"host":"%%host%%","port":6379}]`,
+	}
+
+	redisConfig := integration.Config{
+		Name:          "redisdb",
+		Instances:     []integration.Data{integration.Data(`{"host":"%%host%%","port":6379}`)},
+		InitConfig:    integration.Data("{}"),
+		ADIdentifiers: []string{"systemd_unit://redis.service"},
+		Source:        "systemd:redis.service",
+	}
+
+	tests := []struct {
+		name    string
+		events  []workloadmeta.Event
+		changes integration.ConfigChanges
+		errors  map[string]ErrorMsgSet
+	}{
+		{
+			name: "create config",
+			events: []workloadmeta.Event{
+				{
+					Type:   workloadmeta.EventTypeSet,
+					Entity: systemdUnit(redisAnnotations),
+				},
+			},
+			changes: integration.ConfigChanges{
+				Schedule: []integration.Config{redisConfig},
+			},
+			errors: map[string]ErrorMsgSet{},
+		},
+		{
+			name: "unchanged config",
+			events: []workloadmeta.Event{
+				{
+					Type:   workloadmeta.EventTypeSet,
+					Entity: systemdUnit(redisAnnotations),
+				},
+			},
+			changes: integration.ConfigChanges{},
+			errors:  map[string]ErrorMsgSet{},
+		},
+		{
+			name: "invalid annotations",
+			events: []workloadmeta.Event{
+				{
+					Type: workloadmeta.EventTypeSet,
+					Entity: systemdUnit(map[string]string{
+						"com.datadoghq.ad.check_names":  `["redisdb"]`,
+						"com.datadoghq.ad.init_configs": `[{}]`,
+						"com.datadoghq.ad.instances":    `[{"host":`,
+					}),
+				},
+			},
+			changes: integration.ConfigChanges{
+				Unschedule: []integration.Config{redisConfig},
+			},
+			errors: map[string]ErrorMsgSet{
+				"systemd_unit://redis.service": {
+					"could not extract checks config: in instances: failed to unmarshal JSON: unexpected end of JSON input": struct{}{},
+				},
+			},
+		},
+		{
+			name: "delete config",
+			events: []workloadmeta.Event{
+				{
+					Type:   workloadmeta.EventTypeUnset,
+					Entity: systemdUnit(nil),
+				},
+			},
+			changes: integration.ConfigChanges{},
+			errors:  map[string]ErrorMsgSet{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := sp.processEvents(workloadmeta.EventBundle{
+				Events: tt.events,
+			})
+
+			assert.Equal(t, tt.changes.Schedule, changes.Schedule)
+			assert.Equal(t, tt.changes.Unschedule, changes.Unschedule)
+			assert.Equal(t, tt.errors, sp.GetConfigErrors())
+		})
+	}
+}
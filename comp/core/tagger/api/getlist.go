@@ -69,9 +69,15 @@ func printTaggerEntities(w io.Writer, tr *types.TaggerListResponse) {
 				return tags[i] < tags[j]
 			})
 
+			// tags produced by enrichment rules are followed by the rule name
+			rules := tagItem.Rules[source]
+
 			for i, tag := range tags {
 				tagInfo := strings.Split(tag, ":")
 				fmt.Fprintf(w, "%s:%s", color.BlueString(tagInfo[0]), color.CyanString(strings.Join(tagInfo[1:], ":")))
+				if rule, found := rules[tag]; found {
+					fmt.Fprintf(w, "%s", color.YellowString("(rule:%s)", rule))
+				}
 				if i != len(tags)-1 {
 					fmt.Fprintf(w, " ")
 				}
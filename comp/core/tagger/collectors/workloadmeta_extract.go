@@ -141,6 +141,10 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				tagInfos = append(tagInfos, c.handleKubePod(ev)...)
 			case workloadmeta.KindECSTask:
 				tagInfos = append(tagInfos, c.handleECSTask(ev)...)
+			case workloadmeta.KindNomadAllocation:
+				tagInfos = append(tagInfos, c.handleNomadAllocation(ev)...)
+			case workloadmeta.KindSystemdUnit:
+				tagInfos = append(tagInfos, c.handleSystemdUnit(ev)...)
 			case workloadmeta.KindContainerImageMetadata:
 				tagInfos = append(tagInfos, c.handleContainerImage(ev)...)
 			case workloadmeta.KindKubernetesMetadata:
@@ -512,6 +516,92 @@ func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*types.Ta
 	return tagInfos
 }
 
+func (c *WorkloadMetaCollector) handleNomadAllocation(ev workloadmeta.Event) []*types.TagInfo {
+	alloc := ev.Entity.(*workloadmeta.NomadAllocation)
+
+	allocTags := taglist.NewTagList()
+	allocTags.AddLow(tags.NomadJob, alloc.JobID)
+	allocTags.AddLow(tags.NomadGroup, alloc.TaskGroup)
+	allocTags.AddLow(tags.NomadNamespace, alloc.Namespace)
+	allocTags.AddLow(tags.NomadDC, alloc.Datacenter)
+
+	tagInfos := make([]*types.TagInfo, 0, len(alloc.Tasks))
+	for _, task := range alloc.Tasks {
+		if task.ContainerID == "" {
+			continue
+		}
+
+		container, err := c.store.GetContainer(task.ContainerID)
+		if err != nil {
+			log.Debugf("allocation %q has reference to non-existing container %q", alloc.ID, task.ContainerID)
+			continue
+		}
+
+		c.registerChild(alloc.EntityID, container.EntityID)
+
+		tagList := allocTags.Copy()
+		tagList.AddLow(tags.NomadTask, task.Name)
+
+		low, orch, high, standard := tagList.Compute()
+		tagInfos = append(tagInfos, &types.TagInfo{
+			Source:               allocationSource,
+			EntityID:             common.BuildTaggerEntityID(container.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		})
+	}
+
+	return tagInfos
+}
+
+func (c *WorkloadMetaCollector) handleSystemdUnit(ev workloadmeta.Event) []*types.TagInfo {
+	unit := ev.Entity.(*workloadmeta.SystemdUnit)
+
+	tagList := taglist.NewTagList()
+	tagList.AddLow(tags.SystemdUnit, unit.Name)
+	tagList.AddLow(tags.SystemdSlice, unit.Slice)
+
+	// standard tags from the environment and the annotations of the service
+	c.extractFromMapWithFn(unit.EnvVars, standardEnvKeys, tagList.AddStandard)
+	c.extractFromMapWithFn(unit.Annotations, standardDockerLabels, tagList.AddStandard)
+
+	// custom tags from annotations
+	if annotation, ok := unit.Annotations[autodiscoveryLabelTagsKey]; ok {
+		parseContainerADTagsLabels(tagList, annotation)
+	}
+
+	low, orch, high, standard := tagList.Compute()
+	unitTagInfo := types.TagInfo{
+		Source:               systemdUnitSource,
+		EntityID:             common.BuildTaggerEntityID(unit.EntityID),
+		HighCardTags:         high,
+		OrchestratorCardTags: orch,
+		LowCardTags:          low,
+		StandardTags:         standard,
+	}
+
+	tagInfos := []*types.TagInfo{&unitTagInfo}
+
+	// the main process of the service gets the tags of the unit, so that
+	// they can be looked up from the PID of the data it sends
+	if unit.MainPID != 0 {
+		process := workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   strconv.Itoa(unit.MainPID),
+		}
+
+		c.registerChild(unit.EntityID, process)
+
+		processTagInfo := unitTagInfo
+		processTagInfo.EntityID = common.BuildTaggerEntityID(process)
+		tagInfos = append(tagInfos, &processTagInfo)
+	}
+
+	return tagInfos
+}
+
 func (c *WorkloadMetaCollector) handleGardenContainer(container *workloadmeta.Container) []*types.TagInfo {
 	return []*types.TagInfo{
 		{
@@ -36,6 +36,8 @@ const (
 	processSource        = workloadmetaCollectorName + "-" + string(workloadmeta.KindProcess)
 	kubeMetadataSource   = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesMetadata)
 	deploymentSource     = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesDeployment)
+	allocationSource     = workloadmetaCollectorName + "-" + string(workloadmeta.KindNomadAllocation)
+	systemdUnitSource    = workloadmetaCollectorName + "-" + string(workloadmeta.KindSystemdUnit)
 
 	clusterTagNamePrefix = "kube_cluster_name"
 )
@@ -216,6 +218,8 @@ func mergeMaps(first, second map[string]string) map[string]string {
 func init() {
 	CollectorPriorities[podSource] = types.NodeOrchestrator
 	CollectorPriorities[taskSource] = types.NodeOrchestrator
+	CollectorPriorities[allocationSource] = types.NodeOrchestrator
 	CollectorPriorities[containerSource] = types.NodeRuntime
 	CollectorPriorities[containerImageSource] = types.NodeRuntime
+	CollectorPriorities[systemdUnitSource] = types.NodeRuntime
 }
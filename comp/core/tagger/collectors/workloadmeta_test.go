@@ -1591,6 +1591,129 @@ func TestHandleECSTask(t *testing.T) {
 	}
 }
 
+func TestHandleNomadAllocation(t *testing.T) {
+	const containerID = "foobarquux"
+
+	store := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		config.MockModule(),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   containerID,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx-5456bd7a-9fc0-c0dd-6131-cbee77f57577",
+		},
+	})
+
+	alloc := workloadmeta.NomadAllocation{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindNomadAllocation,
+			ID:   "5456bd7a-9fc0-c0dd-6131-cbee77f57577",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web.frontend[0]",
+			Namespace: "default",
+		},
+		JobID:      "web",
+		TaskGroup:  "frontend",
+		Datacenter: "dc1",
+		Tasks: []workloadmeta.NomadTask{
+			{Name: "nginx", Driver: "docker", ContainerID: containerID},
+			// tasks not running in a container are not tagged
+			{Name: "logrotate", Driver: "exec"},
+			// neither are tasks whose container isn't known yet
+			{Name: "exporter", Driver: "docker", ContainerID: "unknown"},
+		},
+	}
+
+	collector := NewWorkloadMetaCollector(context.Background(), configmock.New(t), store, nil)
+
+	actual := collector.handleNomadAllocation(workloadmeta.Event{
+		Type:   workloadmeta.EventTypeSet,
+		Entity: &alloc,
+	})
+
+	assertTagInfoListEqual(t, []*types.TagInfo{
+		{
+			Source:               allocationSource,
+			EntityID:             types.NewEntityID(types.ContainerID, containerID),
+			HighCardTags:         []string{},
+			OrchestratorCardTags: []string{},
+			LowCardTags: []string{
+				"nomad_dc:dc1",
+				"nomad_group:frontend",
+				"nomad_job:web",
+				"nomad_namespace:default",
+				"nomad_task:nginx",
+			},
+			StandardTags: []string{},
+		},
+	}, actual)
+}
+
+func TestHandleSystemdUnit(t *testing.T) {
+	store := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		config.MockModule(),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+
+	unit := workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "redis.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "redis.service",
+			Annotations: map[string]string{
+				"com.datadoghq.tags.env": "prod",
+				"com.datadoghq.ad.tags":  `["team:storage"]`,
+			},
+		},
+		Slice:   "system.slice",
+		MainPID: 1234,
+		EnvVars: map[string]string{
+			"DD_SERVICE": "redis",
+		},
+	}
+
+	collector := NewWorkloadMetaCollector(context.Background(), configmock.New(t), store, nil)
+
+	actual := collector.handleSystemdUnit(workloadmeta.Event{
+		Type:   workloadmeta.EventTypeSet,
+		Entity: &unit,
+	})
+
+	expectedTagInfo := func(entityID types.EntityID) *types.TagInfo {
+		return &types.TagInfo{
+			Source:               systemdUnitSource,
+			EntityID:             entityID,
+			HighCardTags:         []string{"team:storage"},
+			OrchestratorCardTags: []string{},
+			LowCardTags: []string{
+				"env:prod",
+				"service:redis",
+				"systemd_slice:system.slice",
+				"systemd_unit:redis.service",
+			},
+			StandardTags: []string{
+				"env:prod",
+				"service:redis",
+			},
+		}
+	}
+
+	assertTagInfoListEqual(t, []*types.TagInfo{
+		expectedTagInfo(types.NewEntityID(types.SystemdUnit, "redis.service")),
+		expectedTagInfo(types.NewEntityID(types.Process, "1234")),
+	}, actual)
+}
+
 func TestHandleContainer(t *testing.T) {
 	const (
 		containerName = "foobar"
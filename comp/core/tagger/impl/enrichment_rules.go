@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package taggerimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/rules"
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// enrichmentRulesConfig is the content of a TAGGER_ENRICHMENT_RULES remote
+// config file.
+type enrichmentRulesConfig struct {
+	Rules []rules.Rule `json:"rules"`
+}
+
+// onEnrichmentRulesUpdate is the remote config callback for the tag
+// enrichment rules. The rules of all the configs received are evaluated
+// after the ones of the configuration file, ordered by config path.
+func (t *localTagger) onEnrichmentRulesUpdate(updates map[string]state.RawConfig, applyStateCallback func(string, state.ApplyStatus)) {
+	paths := make([]string, 0, len(updates))
+	for path := range updates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var remoteRules []rules.Rule
+	parseErrors := make(map[string]error)
+
+	for _, path := range paths {
+		var cfg enrichmentRulesConfig
+		if err := json.Unmarshal(updates[path].Config, &cfg); err != nil {
+			parseErrors[path] = fmt.Errorf("could not parse tagger enrichment rules: %w", err)
+			continue
+		}
+		remoteRules = append(remoteRules, cfg.Rules...)
+	}
+
+	err := t.setRemoteRules(remoteRules)
+	if err != nil {
+		log.Errorf("Could not apply tagger enrichment rules received through remote config: %v", err)
+	}
+
+	for _, path := range paths {
+		status := state.ApplyStatus{State: state.ApplyStateAcknowledged}
+		if parseErr, found := parseErrors[path]; found {
+			status = state.ApplyStatus{State: state.ApplyStateError, Error: parseErr.Error()}
+		} else if err != nil {
+			status = state.ApplyStatus{State: state.ApplyStateError, Error: err.Error()}
+		}
+		applyStateCallback(path, status)
+	}
+}
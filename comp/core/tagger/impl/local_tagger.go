@@ -14,12 +14,15 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/tagger/collectors"
 	taggercommon "github.com/DataDog/datadog-agent/comp/core/tagger/common"
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/rules"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/tagstore"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/telemetry"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
 	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
 	taggertypes "github.com/DataDog/datadog-agent/pkg/tagger/types"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // Tagger is the entry class for entity tagging. It hold the tagger collector,
@@ -34,18 +37,49 @@ type localTagger struct {
 	cfg           config.Component
 	collector     *collectors.WorkloadMetaCollector
 
+	// configRules are the enrichment rules defined in the configuration.
+	// They are evaluated before the ones received through remote config.
+	configRules []rules.Rule
+
 	ctx            context.Context
 	cancel         context.CancelFunc
 	telemetryStore *telemetry.Store
 }
 
 func newLocalTagger(cfg config.Component, wmeta workloadmeta.Component, telemetryStore *telemetry.Store) (tagger.Component, error) {
-	return &localTagger{
+	t := &localTagger{
 		tagStore:       tagstore.NewTagStore(cfg, telemetryStore),
 		workloadStore:  wmeta,
 		telemetryStore: telemetryStore,
 		cfg:            cfg,
-	}, nil
+	}
+
+	if cfg.IsSet("tagger_enrichment_rules") {
+		if err := structure.UnmarshalKey(cfg, "tagger_enrichment_rules", &t.configRules); err != nil {
+			log.Errorf("Could not parse tagger_enrichment_rules, ignoring them: %v", err)
+		} else if err := t.setRemoteRules(nil); err != nil {
+			log.Errorf("Invalid tagger_enrichment_rules, ignoring them: %v", err)
+			t.configRules = nil
+		}
+	}
+
+	return t, nil
+}
+
+// setRemoteRules replaces the enrichment rules received through remote
+// config. They are evaluated after the rules defined in the configuration.
+func (t *localTagger) setRemoteRules(remoteRules []rules.Rule) error {
+	all := make([]rules.Rule, 0, len(t.configRules)+len(remoteRules))
+	all = append(all, t.configRules...)
+	all = append(all, remoteRules...)
+
+	engine, err := rules.NewEngine(all)
+	if err != nil {
+		return err
+	}
+
+	t.tagStore.SetRules(engine)
+	return nil
 }
 
 // Start starts the workloadmeta collector and then it is ready for requests.
@@ -22,6 +22,7 @@ import (
 	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
 	workloadmetafxmock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/fx-mock"
 	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
@@ -113,3 +114,86 @@ func TestTag(t *testing.T) {
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"low1", "low2", "orchestrator1", "orchestrator2", "high1", "high2"}, highCardTags)
 }
+
+func TestEnrichmentRules(t *testing.T) {
+	entityID := types.NewEntityID(types.ContainerID, "123")
+
+	store := fxutil.Test[workloadmeta.Component](t, fx.Options(
+		fx.Supply(config.Params{}),
+		fx.Supply(log.Params{}),
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		config.MockModule(),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+
+	tel := fxutil.Test[telemetry.Component](t, telemetryimpl.MockModule())
+	telemetryStore := taggerTelemetry.NewStore(tel)
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("tagger_enrichment_rules", []interface{}{
+		map[string]interface{}{
+			"name": "team-from-namespace",
+			"derive": map[string]interface{}{
+				"tag":    "team",
+				"from":   "kube_namespace",
+				"lookup": map[string]interface{}{"payments": "billing"},
+			},
+		},
+	})
+
+	tagger, err := newLocalTagger(cfg, store, telemetryStore)
+	assert.NoError(t, err)
+	localTagger := tagger.(*localTagger)
+
+	localTagger.tagStore.ProcessTagInfo([]*types.TagInfo{
+		{
+			EntityID:    entityID,
+			Source:      "source",
+			LowCardTags: []string{"kube_namespace:payments", "image_name:backend-api"},
+		},
+	})
+
+	tags, err := localTagger.Tag(entityID, types.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"kube_namespace:payments", "image_name:backend-api", "team:billing"}, tags)
+
+	statuses := map[string]state.ApplyStatus{}
+	applyStateCallback := func(path string, status state.ApplyStatus) {
+		statuses[path] = status
+	}
+
+	localTagger.onEnrichmentRulesUpdate(map[string]state.RawConfig{
+		"datadog/2/TAGGER_ENRICHMENT_RULES/tier/config": {
+			Config: []byte(`{"rules":[{"name":"tier-from-image","derive":{"tag":"tier","from":"image_name","regex":"^(frontend|backend)-","value":"$1"}}]}`),
+		},
+		"datadog/2/TAGGER_ENRICHMENT_RULES/invalid/config": {
+			Config: []byte(`{"rules":`),
+		},
+	}, applyStateCallback)
+
+	assert.Equal(t, state.ApplyStateAcknowledged, statuses["datadog/2/TAGGER_ENRICHMENT_RULES/tier/config"].State)
+	assert.Equal(t, state.ApplyStateError, statuses["datadog/2/TAGGER_ENRICHMENT_RULES/invalid/config"].State)
+
+	tags, err = localTagger.Tag(entityID, types.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"kube_namespace:payments", "image_name:backend-api", "team:billing", "tier:backend"}, tags)
+
+	// an invalid rule set is rejected and the previous rules are kept
+	localTagger.onEnrichmentRulesUpdate(map[string]state.RawConfig{
+		"datadog/2/TAGGER_ENRICHMENT_RULES/duplicated/config": {
+			Config: []byte(`{"rules":[{"name":"team-from-namespace","drop":["image_name"]}]}`),
+		},
+	}, applyStateCallback)
+
+	assert.Equal(t, state.ApplyStateError, statuses["datadog/2/TAGGER_ENRICHMENT_RULES/duplicated/config"].State)
+
+	tags, err = localTagger.Tag(entityID, types.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"kube_namespace:payments", "image_name:backend-api", "team:billing", "tier:backend"}, tags)
+
+	// removing all the remote configs keeps the rules of the configuration
+	localTagger.onEnrichmentRulesUpdate(map[string]state.RawConfig{}, applyStateCallback)
+
+	tags, err = localTagger.Tag(entityID, types.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"kube_namespace:payments", "image_name:backend-api", "team:billing"}, tags)
+}
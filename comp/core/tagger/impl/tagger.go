@@ -34,6 +34,8 @@ import (
 	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
 	compdef "github.com/DataDog/datadog-agent/comp/def"
 	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
+	rctypes "github.com/DataDog/datadog-agent/comp/remote-config/rcclient/types"
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
 	taggertypes "github.com/DataDog/datadog-agent/pkg/tagger/types"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/util/common"
@@ -74,8 +76,9 @@ type Requires struct {
 type Provides struct {
 	compdef.Out
 
-	Comp     tagger.Component
-	Endpoint api.AgentEndpointProvider
+	Comp       tagger.Component
+	Endpoint   api.AgentEndpointProvider
+	RCListener rctypes.ListenerProvider
 }
 
 // datadogConfig contains the configuration specific to Dogstatsd.
@@ -131,9 +134,18 @@ func NewComponent(req Requires) (Provides, error) {
 		return taggerClient.Stop()
 	}})
 
+	// tag enrichment rules can only be updated on the local tagger
+	var rcListener rctypes.ListenerProvider
+	if local, ok := taggerClient.defaultTagger.(*localTagger); ok {
+		rcListener.ListenerProvider = rctypes.RCListener{
+			state.ProductTaggerEnrichmentRules: local.onEnrichmentRulesUpdate,
+		}
+	}
+
 	return Provides{
-		Comp:     taggerClient,
-		Endpoint: api.NewAgentEndpointProvider(taggerClient.writeList, "/tagger-list", "GET"),
+		Comp:       taggerClient,
+		Endpoint:   api.NewAgentEndpointProvider(taggerClient.writeList, "/tagger-list", "GET"),
+		RCListener: rcListener,
 	}, nil
 }
 
@@ -376,6 +388,26 @@ func (t *TaggerWrapper) ResetCaptureTagger() {
 	t.captureTagger = nil
 }
 
+// entityIDFromSocket returns the entity found by the UDS origin detection: a
+// container, or a systemd unit for processes that don't run in a container.
+func entityIDFromSocket(originFromSocket string) (types.EntityID, bool) {
+	if originFromSocket == packets.NoOrigin {
+		return types.EntityID{}, false
+	}
+
+	prefix, id, err := taggercommon.ExtractPrefixAndID(originFromSocket)
+	if err != nil || id == "" {
+		return types.EntityID{}, false
+	}
+
+	switch prefix {
+	case types.ContainerID, types.SystemdUnit:
+		return types.NewEntityID(prefix, id), true
+	default:
+		return types.EntityID{}, false
+	}
+}
+
 // EnrichTags extends a tag list with origin detection tags
 // NOTE(remy): it is not needed to sort/dedup the tags anymore since after the
 // enrichment, the metric and its tags is sent to the context key generator, which
@@ -393,8 +425,6 @@ func (t *TaggerWrapper) EnrichTags(tb tagset.TagsAccumulator, originInfo taggert
 		productOrigin = taggertypes.ProductOriginDogStatsDLegacy
 	}
 
-	containerIDFromSocketCutIndex := len(types.ContainerID) + types.GetSeparatorLengh()
-
 	switch productOrigin {
 	case taggertypes.ProductOriginDogStatsDLegacy:
 		// The following was moved from the dogstatsd package
@@ -431,12 +461,9 @@ func (t *TaggerWrapper) EnrichTags(tb tagset.TagsAccumulator, originInfo taggert
 
 		// We use the UDS socket origin if no origin ID was specify in the tags
 		// or 'dogstatsd_entity_id_precedence' is set to False (default false).
-		if originInfo.ContainerIDFromSocket != packets.NoOrigin &&
-			(originInfo.PodUID == "" || !t.datadogConfig.dogstatsdEntityIDPrecedenceEnabled) &&
-			len(originInfo.ContainerIDFromSocket) > containerIDFromSocketCutIndex {
-			containerID := originInfo.ContainerIDFromSocket[containerIDFromSocketCutIndex:]
-			originFromClient := types.NewEntityID(types.ContainerID, containerID)
-			if err := t.AccumulateTagsFor(originFromClient, cardinality, tb); err != nil {
+		if originFromSocket, ok := entityIDFromSocket(originInfo.ContainerIDFromSocket); ok &&
+			(originInfo.PodUID == "" || !t.datadogConfig.dogstatsdEntityIDPrecedenceEnabled) {
+			if err := t.AccumulateTagsFor(originFromSocket, cardinality, tb); err != nil {
 				t.log.Errorf("%s", err.Error())
 			}
 		}
@@ -460,10 +487,8 @@ func (t *TaggerWrapper) EnrichTags(tb tagset.TagsAccumulator, originInfo taggert
 		}
 	default:
 		// Tag using Local Data
-		if originInfo.ContainerIDFromSocket != packets.NoOrigin && len(originInfo.ContainerIDFromSocket) > containerIDFromSocketCutIndex {
-			containerID := originInfo.ContainerIDFromSocket[containerIDFromSocketCutIndex:]
-			originFromClient := types.NewEntityID(types.ContainerID, containerID)
-			if err := t.AccumulateTagsFor(originFromClient, cardinality, tb); err != nil {
+		if originFromSocket, ok := entityIDFromSocket(originInfo.ContainerIDFromSocket); ok {
+			if err := t.AccumulateTagsFor(originFromSocket, cardinality, tb); err != nil {
 				t.log.Errorf("%s", err.Error())
 			}
 		}
@@ -199,6 +199,30 @@ func TestEnrichTagsOrchestrator(t *testing.T) {
 	assert.Equal(t, []string{"container-low", "container-orch"}, tb.Get())
 }
 
+func TestEnrichTagsSystemdUnit(t *testing.T) {
+	// Create fake tagger
+	c := configmock.New(t)
+	params := tagger.Params{
+		UseFakeTagger: true,
+	}
+	logComponent := logmock.New(t)
+	wmeta := fxutil.Test[workloadmeta.Component](t,
+		fx.Provide(func() log.Component { return logComponent }),
+		fx.Provide(func() config.Component { return c }),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	)
+
+	tagger, err := NewTaggerClient(params, c, wmeta, logComponent, noopTelemetry.GetCompatComponent())
+	assert.NoError(t, err)
+
+	fakeTagger := tagger.defaultTagger.(*FakeTagger)
+
+	fakeTagger.SetTags(types.NewEntityID(types.SystemdUnit, "redis.service"), "fooSource", []string{"systemd_unit:redis.service"}, nil, nil, nil)
+	tb := tagset.NewHashingTagsAccumulator()
+	tagger.EnrichTags(tb, taggertypes.OriginInfo{ContainerIDFromSocket: "systemd_unit://redis.service", Cardinality: "low"})
+	assert.Equal(t, []string{"systemd_unit:redis.service"}, tb.Get())
+}
+
 func TestEnrichTagsOptOut(t *testing.T) {
 	// Create fake tagger
 	c := configmock.New(t)
@@ -0,0 +1,404 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package rules implements the tag enrichment rules evaluated by the tagger
+// on the merged tags of every entity. Rules derive new tags from the tags
+// reported by the collectors, rename tags or drop them.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+)
+
+// Rule is a single tag enrichment rule. A rule applies to an entity only if
+// all the entries of Match match the entity tags. Exactly one of Derive,
+// Rename or Drop must be set.
+type Rule struct {
+	// Name identifies the rule. It's reported as the producer of the tags
+	// the rule adds.
+	Name string `mapstructure:"name" json:"name" yaml:"name"`
+	// Match maps a tag name to a regular expression that must fully match
+	// one of the values of that tag.
+	Match map[string]string `mapstructure:"match" json:"match,omitempty" yaml:"match,omitempty"`
+
+	Derive *DeriveRule `mapstructure:"derive" json:"derive,omitempty" yaml:"derive,omitempty"`
+	Rename *RenameRule `mapstructure:"rename" json:"rename,omitempty" yaml:"rename,omitempty"`
+	Drop   []string    `mapstructure:"drop" json:"drop,omitempty" yaml:"drop,omitempty"`
+}
+
+// DeriveRule adds the tag Tag. When From is set, the value is computed from
+// the value of the From tag, either through the Lookup table or by expanding
+// Value with the submatches of Regex. When From is not set, Value is used
+// as is.
+type DeriveRule struct {
+	Tag         string            `mapstructure:"tag" json:"tag" yaml:"tag"`
+	From        string            `mapstructure:"from" json:"from,omitempty" yaml:"from,omitempty"`
+	Lookup      map[string]string `mapstructure:"lookup" json:"lookup,omitempty" yaml:"lookup,omitempty"`
+	Regex       string            `mapstructure:"regex" json:"regex,omitempty" yaml:"regex,omitempty"`
+	Value       string            `mapstructure:"value" json:"value,omitempty" yaml:"value,omitempty"`
+	Cardinality string            `mapstructure:"cardinality" json:"cardinality,omitempty" yaml:"cardinality,omitempty"`
+}
+
+// RenameRule renames the tag From to To, keeping its value.
+type RenameRule struct {
+	From string `mapstructure:"from" json:"from" yaml:"from"`
+	To   string `mapstructure:"to" json:"to" yaml:"to"`
+}
+
+// Tags holds the tags of an entity, grouped by cardinality.
+type Tags struct {
+	Low          []string
+	Orchestrator []string
+	High         []string
+	Standard     []string
+}
+
+type compiledRule struct {
+	name  string
+	match map[string]*regexp.Regexp
+
+	derive      *DeriveRule
+	deriveRegex *regexp.Regexp
+	cardinality types.TagCardinality
+
+	rename *RenameRule
+	drop   map[string]struct{}
+}
+
+// Engine evaluates an ordered list of rules. A nil Engine doesn't modify
+// tags.
+type Engine struct {
+	rules []compiledRule
+}
+
+// NewEngine validates and compiles the given rules. Rules are evaluated in
+// the order they are given, so a rule can match on the tags produced by the
+// previous ones.
+func NewEngine(rules []Rule) (*Engine, error) {
+	engine := &Engine{}
+	names := make(map[string]struct{}, len(rules))
+
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule #%d: name is required", i)
+		}
+		if _, found := names[rule.Name]; found {
+			return nil, fmt.Errorf("rule %q: duplicated name", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	return engine, nil
+}
+
+func compile(rule Rule) (compiledRule, error) {
+	compiled := compiledRule{
+		name:  rule.Name,
+		match: make(map[string]*regexp.Regexp, len(rule.Match)),
+	}
+
+	for tag, expr := range rule.Match {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return compiled, fmt.Errorf("invalid match expression for tag %q: %w", tag, err)
+		}
+		compiled.match[tag] = re
+	}
+
+	actions := 0
+	if rule.Derive != nil {
+		actions++
+	}
+	if rule.Rename != nil {
+		actions++
+	}
+	if len(rule.Drop) > 0 {
+		actions++
+	}
+	if actions != 1 {
+		return compiled, errors.New("exactly one of derive, rename or drop must be set")
+	}
+
+	switch {
+	case rule.Derive != nil:
+		d := rule.Derive
+		if d.Tag == "" {
+			return compiled, errors.New("derive: tag is required")
+		}
+		if d.From == "" && d.Value == "" {
+			return compiled, errors.New("derive: one of from or value is required")
+		}
+		if d.Lookup != nil && d.Regex != "" {
+			return compiled, errors.New("derive: lookup and regex are mutually exclusive")
+		}
+		if d.From == "" && (d.Lookup != nil || d.Regex != "") {
+			return compiled, errors.New("derive: lookup and regex require from")
+		}
+		if d.Regex != "" {
+			re, err := regexp.Compile(d.Regex)
+			if err != nil {
+				return compiled, fmt.Errorf("derive: invalid regex: %w", err)
+			}
+			compiled.deriveRegex = re
+		}
+
+		compiled.cardinality = types.LowCardinality
+		if d.Cardinality != "" {
+			cardinality, err := types.StringToTagCardinality(d.Cardinality)
+			if err != nil {
+				return compiled, fmt.Errorf("derive: %w", err)
+			}
+			compiled.cardinality = cardinality
+		}
+		compiled.derive = d
+
+	case rule.Rename != nil:
+		if rule.Rename.From == "" || rule.Rename.To == "" {
+			return compiled, errors.New("rename: from and to are required")
+		}
+		compiled.rename = rule.Rename
+
+	default:
+		compiled.drop = make(map[string]struct{}, len(rule.Drop))
+		for _, tag := range rule.Drop {
+			compiled.drop[tag] = struct{}{}
+		}
+	}
+
+	return compiled, nil
+}
+
+// Len returns the number of rules of the engine.
+func (e *Engine) Len() int {
+	if e == nil {
+		return 0
+	}
+	return len(e.rules)
+}
+
+// Apply evaluates the rules against the given tags. It returns the resulting
+// tags and, for every tag added or renamed by a rule, the name of that rule.
+// The input slices are never modified. The returned bool is false when no
+// rule changed the tags, in which case the input is returned as is.
+func (e *Engine) Apply(tags Tags) (Tags, map[string]string, bool) {
+	if e.Len() == 0 {
+		return tags, nil, false
+	}
+
+	state := newTagState(tags)
+	changed := false
+
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if !state.matches(rule.match) {
+			continue
+		}
+
+		switch {
+		case rule.derive != nil:
+			changed = state.derive(rule) || changed
+		case rule.rename != nil:
+			changed = state.rename(rule) || changed
+		default:
+			changed = state.drop(rule) || changed
+		}
+	}
+
+	if !changed {
+		return tags, nil, false
+	}
+
+	return state.tags, state.provenance, true
+}
+
+// tagState holds the tags being modified by the rules. Slices are copied
+// before their first modification so the input is left untouched.
+type tagState struct {
+	tags       Tags
+	copied     bool
+	provenance map[string]string
+}
+
+func newTagState(tags Tags) *tagState {
+	return &tagState{tags: tags}
+}
+
+func (s *tagState) lists() []*[]string {
+	return []*[]string{&s.tags.Low, &s.tags.Orchestrator, &s.tags.High, &s.tags.Standard}
+}
+
+func (s *tagState) copyOnWrite() {
+	if s.copied {
+		return
+	}
+
+	for _, list := range s.lists() {
+		if *list != nil {
+			*list = append([]string(nil), *list...)
+		}
+	}
+	s.provenance = make(map[string]string)
+	s.copied = true
+}
+
+// values returns the values of the given tag name, excluding standard tags
+// since they are duplicated in the other lists.
+func (s *tagState) values(name string) []string {
+	var values []string
+	for _, list := range [][]string{s.tags.Low, s.tags.Orchestrator, s.tags.High} {
+		for _, tag := range list {
+			if tagName, value := splitTag(tag); tagName == name {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func (s *tagState) matches(match map[string]*regexp.Regexp) bool {
+	for name, re := range match {
+		found := false
+		for _, value := range s.values(name) {
+			if re.MatchString(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *tagState) derive(rule *compiledRule) bool {
+	d := rule.derive
+
+	// collected tags take precedence over derived ones
+	if len(s.values(d.Tag)) > 0 {
+		return false
+	}
+
+	value, ok := d.Value, true
+	if d.From != "" {
+		value, ok = s.deriveValue(rule)
+	}
+	if !ok || value == "" {
+		return false
+	}
+
+	s.copyOnWrite()
+
+	tag := d.Tag + ":" + value
+	switch rule.cardinality {
+	case types.HighCardinality:
+		s.tags.High = append(s.tags.High, tag)
+	case types.OrchestratorCardinality:
+		s.tags.Orchestrator = append(s.tags.Orchestrator, tag)
+	default:
+		s.tags.Low = append(s.tags.Low, tag)
+	}
+	s.provenance[tag] = rule.name
+
+	return true
+}
+
+func (s *tagState) deriveValue(rule *compiledRule) (string, bool) {
+	d := rule.derive
+
+	for _, from := range s.values(d.From) {
+		switch {
+		case d.Lookup != nil:
+			if value, ok := d.Lookup[from]; ok {
+				return value, true
+			}
+		case rule.deriveRegex != nil:
+			submatches := rule.deriveRegex.FindStringSubmatchIndex(from)
+			if submatches == nil {
+				continue
+			}
+			template := d.Value
+			if template == "" {
+				template = "$0"
+			}
+			return string(rule.deriveRegex.ExpandString(nil, template, from, submatches)), true
+		default:
+			return from, true
+		}
+	}
+
+	return "", false
+}
+
+func (s *tagState) rename(rule *compiledRule) bool {
+	changed := false
+
+	for _, list := range s.lists() {
+		for i, tag := range *list {
+			name, value := splitTag(tag)
+			if name != rule.rename.From {
+				continue
+			}
+
+			s.copyOnWrite()
+			renamed := rule.rename.To + ":" + value
+			(*list)[i] = renamed
+			delete(s.provenance, tag)
+			s.provenance[renamed] = rule.name
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func (s *tagState) drop(rule *compiledRule) bool {
+	changed := false
+
+	for _, list := range s.lists() {
+		filtered := (*list)[:0:0]
+		dropped := false
+		for _, tag := range *list {
+			name, _ := splitTag(tag)
+			if _, found := rule.drop[name]; found {
+				dropped = true
+				continue
+			}
+			filtered = append(filtered, tag)
+		}
+
+		if !dropped {
+			continue
+		}
+
+		s.copyOnWrite()
+		for _, tag := range *list {
+			name, _ := splitTag(tag)
+			if _, found := rule.drop[name]; found {
+				delete(s.provenance, tag)
+			}
+		}
+		*list = filtered
+		changed = true
+	}
+
+	return changed
+}
+
+func splitTag(tag string) (string, string) {
+	name, value, _ := strings.Cut(tag, ":")
+	return name, value
+}
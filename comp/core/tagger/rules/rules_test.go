@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr string
+	}{
+		{
+			name:    "missing name",
+			rules:   []Rule{{Drop: []string{"foo"}}},
+			wantErr: "name is required",
+		},
+		{
+			name: "duplicated name",
+			rules: []Rule{
+				{Name: "a", Drop: []string{"foo"}},
+				{Name: "a", Drop: []string{"bar"}},
+			},
+			wantErr: "duplicated name",
+		},
+		{
+			name:    "no action",
+			rules:   []Rule{{Name: "a"}},
+			wantErr: "exactly one of derive, rename or drop must be set",
+		},
+		{
+			name: "several actions",
+			rules: []Rule{{
+				Name:   "a",
+				Drop:   []string{"foo"},
+				Rename: &RenameRule{From: "foo", To: "bar"},
+			}},
+			wantErr: "exactly one of derive, rename or drop must be set",
+		},
+		{
+			name: "invalid match",
+			rules: []Rule{{
+				Name:  "a",
+				Match: map[string]string{"foo": "("},
+				Drop:  []string{"foo"},
+			}},
+			wantErr: "invalid match expression",
+		},
+		{
+			name: "lookup and regex",
+			rules: []Rule{{
+				Name:   "a",
+				Derive: &DeriveRule{Tag: "team", From: "kube_namespace", Lookup: map[string]string{}, Regex: ".*"},
+			}},
+			wantErr: "mutually exclusive",
+		},
+		{
+			name: "invalid cardinality",
+			rules: []Rule{{
+				Name:   "a",
+				Derive: &DeriveRule{Tag: "team", Value: "foo", Cardinality: "medium"},
+			}},
+			wantErr: "unsupported value medium",
+		},
+		{
+			name: "valid",
+			rules: []Rule{
+				{Name: "a", Derive: &DeriveRule{Tag: "team", From: "kube_namespace", Lookup: map[string]string{"foo": "bar"}}},
+				{Name: "b", Rename: &RenameRule{From: "team", To: "owner"}},
+				{Name: "c", Drop: []string{"pod_name"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, err := NewEngine(test.rules)
+			if test.wantErr != "" {
+				assert.ErrorContains(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, len(test.rules), engine.Len())
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	input := Tags{
+		Low:          []string{"kube_namespace:payments", "image_name:registry.local/frontend-web", "env:prod"},
+		Orchestrator: []string{"pod_name:web-0"},
+		High:         []string{"container_id:abc"},
+		Standard:     []string{"env:prod"},
+	}
+
+	tests := []struct {
+		name           string
+		rules          []Rule
+		wantTags       Tags
+		wantProvenance map[string]string
+		wantChanged    bool
+	}{
+		{
+			name: "lookup",
+			rules: []Rule{{
+				Name:   "team-from-namespace",
+				Derive: &DeriveRule{Tag: "team", From: "kube_namespace", Lookup: map[string]string{"payments": "billing"}},
+			}},
+			wantTags: Tags{
+				Low:          []string{"kube_namespace:payments", "image_name:registry.local/frontend-web", "env:prod", "team:billing"},
+				Orchestrator: []string{"pod_name:web-0"},
+				High:         []string{"container_id:abc"},
+				Standard:     []string{"env:prod"},
+			},
+			wantProvenance: map[string]string{"team:billing": "team-from-namespace"},
+			wantChanged:    true,
+		},
+		{
+			name: "lookup miss",
+			rules: []Rule{{
+				Name:   "team-from-namespace",
+				Derive: &DeriveRule{Tag: "team", From: "kube_namespace", Lookup: map[string]string{"default": "core"}},
+			}},
+			wantTags: input,
+		},
+		{
+			name: "regex",
+			rules: []Rule{{
+				Name:   "tier-from-image",
+				Derive: &DeriveRule{Tag: "tier", From: "image_name", Regex: `/(?P<tier>frontend|backend)-`, Value: "${tier}", Cardinality: "orchestrator"},
+			}},
+			wantTags: Tags{
+				Low:          []string{"kube_namespace:payments", "image_name:registry.local/frontend-web", "env:prod"},
+				Orchestrator: []string{"pod_name:web-0", "tier:frontend"},
+				High:         []string{"container_id:abc"},
+				Standard:     []string{"env:prod"},
+			},
+			wantProvenance: map[string]string{"tier:frontend": "tier-from-image"},
+			wantChanged:    true,
+		},
+		{
+			name: "static value with match",
+			rules: []Rule{
+				{
+					Name:   "prod-only",
+					Match:  map[string]string{"env": "prod", "kube_namespace": "pay.*"},
+					Derive: &DeriveRule{Tag: "critical", Value: "true"},
+				},
+				{
+					Name:   "no-match",
+					Match:  map[string]string{"env": "pro"},
+					Derive: &DeriveRule{Tag: "other", Value: "true"},
+				},
+			},
+			wantTags: Tags{
+				Low:          []string{"kube_namespace:payments", "image_name:registry.local/frontend-web", "env:prod", "critical:true"},
+				Orchestrator: []string{"pod_name:web-0"},
+				High:         []string{"container_id:abc"},
+				Standard:     []string{"env:prod"},
+			},
+			wantProvenance: map[string]string{"critical:true": "prod-only"},
+			wantChanged:    true,
+		},
+		{
+			name: "collected tags are not overridden",
+			rules: []Rule{{
+				Name:   "static-env",
+				Derive: &DeriveRule{Tag: "env", Value: "staging"},
+			}},
+			wantTags: input,
+		},
+		{
+			name: "rename and drop chained with derive",
+			rules: []Rule{
+				{
+					Name:   "team-from-namespace",
+					Derive: &DeriveRule{Tag: "team", From: "kube_namespace", Lookup: map[string]string{"payments": "billing"}},
+				},
+				{Name: "team-to-owner", Rename: &RenameRule{From: "team", To: "owner"}},
+				{Name: "rename-env", Rename: &RenameRule{From: "env", To: "environment"}},
+				{Name: "drop-pod", Drop: []string{"pod_name", "container_id"}},
+			},
+			wantTags: Tags{
+				Low:          []string{"kube_namespace:payments", "image_name:registry.local/frontend-web", "environment:prod", "owner:billing"},
+				Orchestrator: []string{},
+				High:         []string{},
+				Standard:     []string{"environment:prod"},
+			},
+			wantProvenance: map[string]string{"owner:billing": "team-to-owner", "environment:prod": "rename-env"},
+			wantChanged:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, err := NewEngine(test.rules)
+			require.NoError(t, err)
+
+			tags, provenance, changed := engine.Apply(input)
+			assert.Equal(t, test.wantTags, tags)
+			assert.Equal(t, test.wantProvenance, provenance)
+			assert.Equal(t, test.wantChanged, changed)
+		})
+	}
+
+	// the input must never be modified
+	assert.Equal(t, []string{"kube_namespace:payments", "image_name:registry.local/frontend-web", "env:prod"}, input.Low)
+	assert.Equal(t, []string{"pod_name:web-0"}, input.Orchestrator)
+	assert.Equal(t, []string{"env:prod"}, input.Standard)
+}
+
+func TestApplyNilEngine(t *testing.T) {
+	var engine *Engine
+	input := Tags{Low: []string{"foo:bar"}}
+
+	tags, provenance, changed := engine.Apply(input)
+	assert.Equal(t, input, tags)
+	assert.Nil(t, provenance)
+	assert.False(t, changed)
+}
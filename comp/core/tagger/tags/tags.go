@@ -140,6 +140,11 @@ const (
 	// NomadDC is the tag for the Nomad datacenter
 	NomadDC = "nomad_dc"
 
+	// SystemdUnit is the tag for the systemd unit
+	SystemdUnit = "systemd_unit"
+	// SystemdSlice is the tag for the systemd slice
+	SystemdSlice = "systemd_slice"
+
 	// SwarmService is the tag for the Docker Swarm service
 	SwarmService = "swarm_service"
 	// SwarmNamespace is the tag for the Docker Swarm namespace
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/tagger/collectors"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/rules"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
@@ -45,10 +46,15 @@ type EntityTags interface {
 	getHashedTags(cardinality types.TagCardinality) tagset.HashedTags
 	tagsForSource(source string) *sourceTags
 	tagsBySource() map[string][]string
+	rulesBySource() map[string]map[string]string
 	setTagsForSource(source string, tags sourceTags)
+	collectedTags() rules.Tags
+	getRuleTags() *ruleTags
+	setRuleTags(tags *ruleTags)
 	sources() []string
 	setSourceExpiration(source string, expiryDate time.Time)
 	deleteExpired(time time.Time) bool
+	allSourcesExpired(time time.Time) bool
 	shouldRemove() bool
 }
 
@@ -63,6 +69,7 @@ type EntityTagsWithMultipleSources struct {
 	cachedAll          tagset.HashedTags // Low + orchestrator + high
 	cachedOrchestrator tagset.HashedTags // Low + orchestrator (subslice of cachedAll)
 	cachedLow          tagset.HashedTags // Sub-slice of cachedAll
+	rules              *ruleTags
 }
 
 func newEntityTags(entityID types.EntityID, source string) EntityTags {
@@ -99,6 +106,14 @@ func (e *EntityTagsWithMultipleSources) toEntity() types.Entity {
 }
 
 func (e *EntityTagsWithMultipleSources) getStandard() []string {
+	tags := e.collectedStandard()
+	if e.rules != nil {
+		tags = e.rules.apply(tags, e.rules.added.standardTags)
+	}
+	return tags
+}
+
+func (e *EntityTagsWithMultipleSources) collectedStandard() []string {
 	tags := []string{}
 	for _, t := range e.sourceTags {
 		tags = append(tags, t.standardTags...)
@@ -122,6 +137,31 @@ func (e *EntityTagsWithMultipleSources) computeCache() {
 		return
 	}
 
+	tagList := e.mergeSources()
+	if e.rules != nil {
+		tagList[types.LowCardinality] = e.rules.apply(tagList[types.LowCardinality], e.rules.added.lowCardTags)
+		tagList[types.OrchestratorCardinality] = e.rules.apply(tagList[types.OrchestratorCardinality], e.rules.added.orchestratorCardTags)
+		tagList[types.HighCardinality] = e.rules.apply(tagList[types.HighCardinality], e.rules.added.highCardTags)
+	}
+
+	tags := append(tagList[types.LowCardinality], tagList[types.OrchestratorCardinality]...)
+	tags = append(tags, tagList[types.HighCardinality]...)
+
+	cached := tagset.NewHashedTagsFromSlice(tags)
+
+	lowCardTags := len(tagList[types.LowCardinality])
+	orchCardTags := len(tagList[types.OrchestratorCardinality])
+
+	// Write cache
+	e.cacheValid = true
+	e.cachedAll = cached
+	e.cachedLow = cached.Slice(0, lowCardTags)
+	e.cachedOrchestrator = cached.Slice(0, lowCardTags+orchCardTags)
+}
+
+// mergeSources merges the tags reported by all the sources, grouped by
+// cardinality.
+func (e *EntityTagsWithMultipleSources) mergeSources() map[types.TagCardinality][]string {
 	tagList := make(map[types.TagCardinality][]string)
 	tagMap := make(map[string]types.CollectorPriority)
 
@@ -165,19 +205,27 @@ func (e *EntityTagsWithMultipleSources) computeCache() {
 		insertWithPriority(source, tags.highCardTags, types.HighCardinality)
 	}
 
-	tags := append(tagList[types.LowCardinality], tagList[types.OrchestratorCardinality]...)
-	tags = append(tags, tagList[types.HighCardinality]...)
+	return tagList
+}
 
-	cached := tagset.NewHashedTagsFromSlice(tags)
+func (e *EntityTagsWithMultipleSources) collectedTags() rules.Tags {
+	tagList := e.mergeSources()
 
-	lowCardTags := len(tagList[types.LowCardinality])
-	orchCardTags := len(tagList[types.OrchestratorCardinality])
+	return rules.Tags{
+		Low:          tagList[types.LowCardinality],
+		Orchestrator: tagList[types.OrchestratorCardinality],
+		High:         tagList[types.HighCardinality],
+		Standard:     e.collectedStandard(),
+	}
+}
 
-	// Write cache
-	e.cacheValid = true
-	e.cachedAll = cached
-	e.cachedLow = cached.Slice(0, lowCardTags)
-	e.cachedOrchestrator = cached.Slice(0, lowCardTags+orchCardTags)
+func (e *EntityTagsWithMultipleSources) getRuleTags() *ruleTags {
+	return e.rules
+}
+
+func (e *EntityTagsWithMultipleSources) setRuleTags(tags *ruleTags) {
+	e.rules = tags
+	e.cacheValid = false
 }
 
 func (e *EntityTagsWithMultipleSources) deleteExpired(time time.Time) bool {
@@ -196,6 +244,20 @@ func (e *EntityTagsWithMultipleSources) deleteExpired(time time.Time) bool {
 	return changed
 }
 
+func (e *EntityTagsWithMultipleSources) allSourcesExpired(time time.Time) bool {
+	if len(e.sourceTags) == 0 {
+		return false
+	}
+
+	for _, tags := range e.sourceTags {
+		if !tags.isExpired(time) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (e *EntityTagsWithMultipleSources) shouldRemove() bool {
 	for _, tags := range e.sourceTags {
 		if !tags.expiryDate.IsZero() || !tags.isEmpty() {
@@ -229,9 +291,17 @@ func (e *EntityTagsWithMultipleSources) tagsBySource() map[string][]string {
 		tagsBySource[source] = allTags
 	}
 
+	if e.rules != nil {
+		tagsBySource[rulesSource] = e.rules.addedTags()
+	}
+
 	return tagsBySource
 }
 
+func (e *EntityTagsWithMultipleSources) rulesBySource() map[string]map[string]string {
+	return e.rules.bySource()
+}
+
 func (e *EntityTagsWithMultipleSources) sources() []string {
 	sources := make([]string, 0, len(e.sourceTags))
 	for source := range e.sourceTags {
@@ -263,6 +333,14 @@ type EntityTagsWithSingleSource struct {
 	cachedOrchestrator tagset.HashedTags // Low + orchestrator (subslice of cachedAll)
 	cachedLow          tagset.HashedTags // Sub-slice of cachedAll
 	isExpired          bool
+
+	// rules holds the changes made by enrichment rules to the tags above.
+	// When set, the tags of the entity are read from the rule* caches.
+	rules                  *ruleTags
+	ruleStandardTags       []string
+	ruleCachedAll          tagset.HashedTags
+	ruleCachedOrchestrator tagset.HashedTags
+	ruleCachedLow          tagset.HashedTags
 }
 
 func newEntityTagsWithSingleSource(entityID types.EntityID, source string) *EntityTagsWithSingleSource {
@@ -277,9 +355,11 @@ func (e *EntityTagsWithSingleSource) getEntityID() types.EntityID {
 }
 
 func (e *EntityTagsWithSingleSource) toEntity() types.Entity {
-	cachedAll := e.cachedAll.Get()
-	cachedOrchestrator := e.cachedOrchestrator.Get()
-	cachedLow := e.cachedLow.Get()
+	all, orchestrator, low := e.hashedTags()
+
+	cachedAll := all.Get()
+	cachedOrchestrator := orchestrator.Get()
+	cachedLow := low.Get()
 
 	return types.Entity{
 		ID:           e.entityID,
@@ -293,20 +373,34 @@ func (e *EntityTagsWithSingleSource) toEntity() types.Entity {
 }
 
 func (e *EntityTagsWithSingleSource) getStandard() []string {
+	if e.rules != nil {
+		return e.ruleStandardTags
+	}
 	return e.standardTags
 }
 
 func (e *EntityTagsWithSingleSource) getHashedTags(cardinality types.TagCardinality) tagset.HashedTags {
+	all, orchestrator, low := e.hashedTags()
+
 	switch cardinality {
 	case types.HighCardinality:
-		return e.cachedAll
+		return all
 	case types.OrchestratorCardinality:
-		return e.cachedOrchestrator
+		return orchestrator
 	default:
-		return e.cachedLow
+		return low
 	}
 }
 
+// hashedTags returns the cached tags of the entity, including the changes
+// made by enrichment rules.
+func (e *EntityTagsWithSingleSource) hashedTags() (all, orchestrator, low tagset.HashedTags) {
+	if e.rules != nil {
+		return e.ruleCachedAll, e.ruleCachedOrchestrator, e.ruleCachedLow
+	}
+	return e.cachedAll, e.cachedOrchestrator, e.cachedLow
+}
+
 func (e *EntityTagsWithSingleSource) tagsForSource(source string) *sourceTags {
 	if source != e.source {
 		log.Errorf("Trying to get tags from source %s on entity with source %s", source, e.source)
@@ -323,7 +417,64 @@ func (e *EntityTagsWithSingleSource) tagsForSource(source string) *sourceTags {
 }
 
 func (e *EntityTagsWithSingleSource) tagsBySource() map[string][]string {
-	return map[string][]string{e.source: e.cachedAll.Get()}
+	tagsBySource := map[string][]string{e.source: e.cachedAll.Get()}
+
+	if e.rules != nil {
+		tagsBySource[rulesSource] = e.rules.addedTags()
+	}
+
+	return tagsBySource
+}
+
+func (e *EntityTagsWithSingleSource) rulesBySource() map[string]map[string]string {
+	return e.rules.bySource()
+}
+
+func (e *EntityTagsWithSingleSource) collectedTags() rules.Tags {
+	return rules.Tags{
+		Low:          e.cachedLow.Get(),
+		Orchestrator: e.cachedAll.Slice(e.cachedLow.Len(), e.cachedOrchestrator.Len()).Get(),
+		High:         e.cachedAll.Slice(e.cachedOrchestrator.Len(), e.cachedAll.Len()).Get(),
+		Standard:     e.standardTags,
+	}
+}
+
+func (e *EntityTagsWithSingleSource) getRuleTags() *ruleTags {
+	return e.rules
+}
+
+func (e *EntityTagsWithSingleSource) setRuleTags(tags *ruleTags) {
+	e.rules = tags
+	e.computeRuleCache()
+}
+
+// computeRuleCache caches the tags of the entity after applying the changes
+// made by enrichment rules.
+func (e *EntityTagsWithSingleSource) computeRuleCache() {
+	if e.rules == nil {
+		e.ruleStandardTags = nil
+		e.ruleCachedAll = tagset.HashedTags{}
+		e.ruleCachedOrchestrator = tagset.HashedTags{}
+		e.ruleCachedLow = tagset.HashedTags{}
+		return
+	}
+
+	collected := e.collectedTags()
+	low := e.rules.apply(collected.Low, e.rules.added.lowCardTags)
+	orchestrator := e.rules.apply(collected.Orchestrator, e.rules.added.orchestratorCardTags)
+	high := e.rules.apply(collected.High, e.rules.added.highCardTags)
+
+	all := make([]string, 0, len(low)+len(orchestrator)+len(high))
+	all = append(all, low...)
+	all = append(all, orchestrator...)
+	all = append(all, high...)
+
+	cached := tagset.NewHashedTagsFromSlice(all)
+
+	e.ruleStandardTags = e.rules.apply(collected.Standard, e.rules.added.standardTags)
+	e.ruleCachedAll = cached
+	e.ruleCachedLow = cached.Slice(0, len(low))
+	e.ruleCachedOrchestrator = cached.Slice(0, len(low)+len(orchestrator))
 }
 
 func (e *EntityTagsWithSingleSource) setTagsForSource(source string, tags sourceTags) {
@@ -344,6 +495,8 @@ func (e *EntityTagsWithSingleSource) setTagsForSource(source string, tags source
 	e.cachedAll = cached
 	e.cachedLow = cached.Slice(0, len(tags.lowCardTags))
 	e.cachedOrchestrator = cached.Slice(0, len(tags.lowCardTags)+len(tags.orchestratorCardTags))
+
+	e.computeRuleCache()
 }
 
 func (e *EntityTagsWithSingleSource) sources() []string {
@@ -368,6 +521,10 @@ func (e *EntityTagsWithSingleSource) deleteExpired(time time.Time) bool {
 	return false
 }
 
+func (e *EntityTagsWithSingleSource) allSourcesExpired(time time.Time) bool {
+	return !e.expiryDate.IsZero() && e.expiryDate.Before(time)
+}
+
 func (e *EntityTagsWithSingleSource) shouldRemove() bool {
 	return e.isExpired || e.cachedAll.Len() == 0
 }
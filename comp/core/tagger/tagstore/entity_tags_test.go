@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/DataDog/datadog-agent/comp/core/tagger/rules"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
 )
 
@@ -217,3 +218,45 @@ func TestDeleteExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestSetRuleTags(t *testing.T) {
+	entityTags := newEntityTagsWithSingleSource(testEntityID, testSource)
+
+	entityTags.setTagsForSource(testSource, sourceTags{
+		lowCardTags:          []string{"l1:v1", "service:s1"},
+		orchestratorCardTags: []string{"o1:v1"},
+		standardTags:         []string{"service:s1"},
+	})
+
+	collected := entityTags.collectedTags()
+	entityTags.setRuleTags(newRuleTagsFromDiff(collected, rules.Tags{
+		Low:          []string{"l1:v1", "svc:s1", "team:t1"},
+		Orchestrator: []string{"o1:v1"},
+		Standard:     []string{"svc:s1"},
+	}, map[string]string{"svc:s1": "rename", "team:t1": "derive"}))
+
+	assert.Equal(
+		t,
+		types.Entity{
+			ID:                          testEntityID,
+			LowCardinalityTags:          []string{"l1:v1", "svc:s1", "team:t1"},
+			OrchestratorCardinalityTags: []string{"o1:v1"},
+			HighCardinalityTags:         []string{},
+			StandardTags:                []string{"svc:s1"},
+		},
+		entityTags.toEntity(),
+	)
+
+	// the collected tags are kept apart from the tags produced by the rules
+	assert.Equal(t, collected, entityTags.collectedTags())
+	assert.Equal(t, map[string][]string{
+		testSource:  {"l1:v1", "service:s1", "o1:v1"},
+		rulesSource: {"svc:s1", "team:t1"},
+	}, entityTags.tagsBySource())
+
+	entityTags.setRuleTags(nil)
+
+	assert.Equal(t, []string{"l1:v1", "service:s1"}, entityTags.getHashedTags(types.LowCardinality).Get())
+	assert.Equal(t, []string{"service:s1"}, entityTags.getStandard())
+	assert.Nil(t, entityTags.rulesBySource())
+}
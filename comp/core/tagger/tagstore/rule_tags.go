@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import "github.com/DataDog/datadog-agent/comp/core/tagger/rules"
+
+// rulesSource is the source under which the tags produced by enrichment rules
+// are reported.
+const rulesSource = "rules"
+
+// ruleTags holds the changes made by the enrichment rules to the merged tags
+// of an entity. They're kept apart from the tags reported by the collectors,
+// so the rules can be evaluated again whenever those change.
+type ruleTags struct {
+	// added holds the tags derived or renamed by the rules.
+	added sourceTags
+	// removed holds the collected tags dropped or renamed by the rules.
+	removed map[string]struct{}
+	// producers maps every added tag to the name of the rule that produced
+	// it.
+	producers map[string]string
+}
+
+// newRuleTagsFromDiff returns the difference between the collected tags and the tags
+// enriched by the rules.
+func newRuleTagsFromDiff(collected, enriched rules.Tags, producers map[string]string) *ruleTags {
+	rt := &ruleTags{
+		removed:   make(map[string]struct{}),
+		producers: producers,
+	}
+
+	diff := func(collected, enriched []string) []string {
+		var added []string
+
+		enrichedSet := make(map[string]struct{}, len(enriched))
+		for _, tag := range enriched {
+			enrichedSet[tag] = struct{}{}
+		}
+		collectedSet := make(map[string]struct{}, len(collected))
+		for _, tag := range collected {
+			collectedSet[tag] = struct{}{}
+			if _, found := enrichedSet[tag]; !found {
+				rt.removed[tag] = struct{}{}
+			}
+		}
+		for _, tag := range enriched {
+			if _, found := collectedSet[tag]; !found {
+				added = append(added, tag)
+			}
+		}
+
+		return added
+	}
+
+	rt.added = sourceTags{
+		lowCardTags:          diff(collected.Low, enriched.Low),
+		orchestratorCardTags: diff(collected.Orchestrator, enriched.Orchestrator),
+		highCardTags:         diff(collected.High, enriched.High),
+		standardTags:         diff(collected.Standard, enriched.Standard),
+	}
+
+	return rt
+}
+
+// apply returns the given collected tags without the tags removed by the
+// rules, followed by the given added tags.
+func (rt *ruleTags) apply(collected []string, added []string) []string {
+	tags := make([]string, 0, len(collected)+len(added))
+	for _, tag := range collected {
+		if _, found := rt.removed[tag]; !found {
+			tags = append(tags, tag)
+		}
+	}
+
+	return append(tags, added...)
+}
+
+// addedTags returns the low, orchestrator and high cardinality tags added by
+// the rules.
+func (rt *ruleTags) addedTags() []string {
+	tags := append([]string{}, rt.added.lowCardTags...)
+	tags = append(tags, rt.added.orchestratorCardTags...)
+	return append(tags, rt.added.highCardTags...)
+}
+
+// bySource returns the rules that produced the added tags, reported under the
+// rules source.
+func (rt *ruleTags) bySource() map[string]map[string]string {
+	if rt == nil || len(rt.producers) == 0 {
+		return nil
+	}
+
+	return map[string]map[string]string{rulesSource: rt.producers}
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	genericstore "github.com/DataDog/datadog-agent/comp/core/tagger/generic_store"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/rules"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/subscriber"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/telemetry"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
@@ -45,6 +46,13 @@ type TagStore struct {
 
 	clock clock.Clock
 
+	// rules are the tag enrichment rules applied to the merged tags
+	// reported by the collectors for every entity.
+	rules *rules.Engine
+
+	// tombstones keeps the tags of removed entities for a grace period.
+	tombstones *tombstones
+
 	cfg            config.Component
 	telemetryStore *telemetry.Store
 }
@@ -60,8 +68,12 @@ func newTagStoreWithClock(cfg config.Component, clock clock.Clock, telemetryStor
 		store:               genericstore.NewObjectStore[EntityTags](),
 		subscriptionManager: subscriber.NewSubscriptionManager(telemetryStore),
 		clock:               clock,
-		cfg:                 cfg,
-		telemetryStore:      telemetryStore,
+		tombstones: newTombstones(
+			cfg.GetDuration("tagger_tombstone_grace_period"),
+			cfg.GetInt("tagger_tombstone_max_entities"),
+		),
+		cfg:            cfg,
+		telemetryStore: telemetryStore,
 	}
 }
 
@@ -138,10 +150,12 @@ func (s *TagStore) ProcessTagInfo(tagInfos []*types.TagInfo) {
 			eventType = types.EventTypeAdded
 			storedTags = newEntityTags(info.EntityID, info.Source)
 			s.store.Set(info.EntityID, storedTags)
+			s.tombstones.remove(info.EntityID)
 		}
 
 		s.telemetryStore.UpdatedEntities.Inc()
 		storedTags.setTagsForSource(info.Source, newSt)
+		s.applyRules(storedTags)
 
 		events = append(events, types.EntityEvent{
 			EventType: eventType,
@@ -154,6 +168,54 @@ func (s *TagStore) ProcessTagInfo(tagInfos []*types.TagInfo) {
 	}
 }
 
+// SetRules replaces the tag enrichment rules and re-applies them to the
+// entities already stored. Subscribers are notified of the entities whose
+// tags changed.
+func (s *TagStore) SetRules(engine *rules.Engine) {
+	events := []types.EntityEvent{}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.rules = engine
+
+	s.store.ForEach(nil, func(_ types.EntityID, et EntityTags) {
+		if s.applyRules(et) {
+			s.telemetryStore.UpdatedEntities.Inc()
+			events = append(events, types.EntityEvent{
+				EventType: types.EventTypeModified,
+				Entity:    et.toEntity(),
+			})
+		}
+	})
+
+	if len(events) > 0 {
+		s.notifySubscribers(events)
+	}
+}
+
+// applyRules evaluates the enrichment rules against the merged tags of the
+// given entity, so that rules can match on tags reported by different
+// collectors. It returns whether the tags produced by the rules changed. It
+// must be called with the lock held.
+func (s *TagStore) applyRules(et EntityTags) bool {
+	var newRuleTags *ruleTags
+
+	if s.rules.Len() > 0 {
+		collected := et.collectedTags()
+		if enriched, producers, changed := s.rules.Apply(collected); changed {
+			newRuleTags = newRuleTagsFromDiff(collected, enriched, producers)
+		}
+	}
+
+	if reflect.DeepEqual(et.getRuleTags(), newRuleTags) {
+		return false
+	}
+
+	et.setRuleTags(newRuleTags)
+	return true
+}
+
 func (s *TagStore) collectTelemetry() {
 	// our telemetry package does not seem to have a way to reset a Gauge,
 	// so we need to keep track of all the labels we use, and re-set them
@@ -181,6 +243,8 @@ func (s *TagStore) collectTelemetry() {
 			s.telemetry[prefix][source] = 0
 		}
 	}
+
+	s.telemetryStore.TombstonedEntities.Set(float64(s.tombstones.size()))
 }
 
 // Subscribe returns a channel that receives a slice of events whenever an entity is
@@ -215,7 +279,16 @@ func (s *TagStore) Prune() {
 	now := s.clock.Now()
 	events := []types.EntityEvent{}
 
+	s.tombstones.prune(now)
+
 	s.store.ForEach(nil, func(eid types.EntityID, et EntityTags) {
+		// keep the last known tags of entities about to be removed
+		var lastKnown *types.Entity
+		if s.tombstones.enabled() && et.allSourcesExpired(now) {
+			entity := et.toEntity()
+			lastKnown = &entity
+		}
+
 		changed := et.deleteExpired(now)
 
 		if !changed && !et.shouldRemove() {
@@ -225,11 +298,16 @@ func (s *TagStore) Prune() {
 		if et.shouldRemove() {
 			s.telemetryStore.PrunedEntities.Inc()
 			s.store.Unset(eid)
+			if lastKnown != nil {
+				s.tombstones.add(*lastKnown, now)
+			}
 			events = append(events, types.EntityEvent{
 				EventType: types.EventTypeDeleted,
 				Entity:    et.toEntity(),
 			})
 		} else {
+			// the rules may have matched the tags of the expired sources
+			s.applyRules(et)
 			events = append(events, types.EntityEvent{
 				EventType: types.EventTypeModified,
 				Entity:    et.toEntity(),
@@ -246,7 +324,7 @@ func (s *TagStore) Prune() {
 func (s *TagStore) LookupHashed(entityID types.EntityID, cardinality types.TagCardinality) tagset.HashedTags {
 	s.RLock()
 	defer s.RUnlock()
-	storedTags, present := s.store.Get(entityID)
+	storedTags, present := s.getWithTombstones(entityID)
 
 	if !present {
 		return tagset.HashedTags{}
@@ -262,7 +340,7 @@ func (s *TagStore) LookupHashedWithEntityStr(entityID types.EntityID, cardinalit
 	s.RLock()
 	defer s.RUnlock()
 
-	storedTags, present := s.store.Get(entityID)
+	storedTags, present := s.getWithTombstones(entityID)
 	if !present {
 		return tagset.HashedTags{}
 	}
@@ -277,9 +355,12 @@ func (s *TagStore) Lookup(entityID types.EntityID, cardinality types.TagCardinal
 
 // LookupStandard returns the standard tags recorded for a given entity
 func (s *TagStore) LookupStandard(entityID types.EntityID) ([]string, error) {
-	storedTags, err := s.getEntityTags(entityID)
-	if err != nil {
-		return nil, err
+	s.RLock()
+	defer s.RUnlock()
+
+	storedTags, present := s.getWithTombstones(entityID)
+	if !present {
+		return nil, ErrNotFound
 	}
 
 	return storedTags.getStandard(), nil
@@ -296,7 +377,8 @@ func (s *TagStore) List() types.TaggerListResponse {
 
 	for _, et := range s.store.ListObjects(types.NewMatchAllFilter()) {
 		r.Entities[et.getEntityID().String()] = types.TaggerListEntity{
-			Tags: et.tagsBySource(),
+			Tags:  et.tagsBySource(),
+			Rules: et.rulesBySource(),
 		}
 	}
 
@@ -325,3 +407,18 @@ func (s *TagStore) getEntityTags(entityID types.EntityID) (EntityTags, error) {
 
 	return storedTags, nil
 }
+
+// getWithTombstones returns the tags of the given entity, falling back on
+// the tombstones of removed entities. It must be called with the lock held.
+func (s *TagStore) getWithTombstones(entityID types.EntityID) (EntityTags, bool) {
+	if storedTags, present := s.store.Get(entityID); present {
+		return storedTags, true
+	}
+
+	storedTags, present := s.tombstones.get(entityID, s.clock.Now())
+	if present {
+		s.telemetryStore.TombstoneLookups.Inc()
+	}
+
+	return storedTags, present
+}
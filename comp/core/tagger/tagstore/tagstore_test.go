@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/DataDog/datadog-agent/comp/core/tagger/collectors"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/rules"
 	taggerTelemetry "github.com/DataDog/datadog-agent/comp/core/tagger/telemetry"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
 	"github.com/DataDog/datadog-agent/comp/core/telemetry"
@@ -385,6 +386,134 @@ func (s *StoreTestSuite) TestGetEntity() {
 	)
 }
 
+func (s *StoreTestSuite) TestRules() {
+	entityID := types.NewEntityID(types.ContainerID, "entity-1")
+
+	engine, err := rules.NewEngine([]rules.Rule{
+		{
+			Name: "team-from-namespace",
+			Derive: &rules.DeriveRule{
+				Tag:    "team",
+				From:   "kube_namespace",
+				Lookup: map[string]string{"payments": "billing"},
+			},
+		},
+		{
+			Name: "drop-pod-name",
+			Drop: []string{"pod_name"},
+		},
+	})
+	require.NoError(s.T(), err)
+	s.tagstore.SetRules(engine)
+
+	s.tagstore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:               "source-1",
+			EntityID:             entityID,
+			LowCardTags:          []string{"kube_namespace:payments"},
+			OrchestratorCardTags: []string{"pod_name:web-0"},
+		},
+	})
+
+	assert.ElementsMatch(s.T(), []string{"kube_namespace:payments", "team:billing"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+
+	entity := s.tagstore.List().Entities[entityID.String()]
+	assert.Equal(s.T(), map[string]map[string]string{
+		"rules": {"team:billing": "team-from-namespace"},
+	}, entity.Rules)
+
+	// changing the rules re-applies them to the collected tags
+	engine, err = rules.NewEngine([]rules.Rule{
+		{
+			Name:   "rename-namespace",
+			Rename: &rules.RenameRule{From: "kube_namespace", To: "namespace"},
+		},
+	})
+	require.NoError(s.T(), err)
+	s.tagstore.SetRules(engine)
+
+	assert.ElementsMatch(s.T(), []string{"namespace:payments", "pod_name:web-0"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+
+	entity = s.tagstore.List().Entities[entityID.String()]
+	assert.Equal(s.T(), map[string]map[string]string{
+		"rules": {"namespace:payments": "rename-namespace"},
+	}, entity.Rules)
+
+	// removing the rules restores the collected tags
+	s.tagstore.SetRules(nil)
+
+	assert.ElementsMatch(s.T(), []string{"kube_namespace:payments", "pod_name:web-0"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+	assert.Nil(s.T(), s.tagstore.List().Entities[entityID.String()].Rules)
+}
+
+func (s *StoreTestSuite) TestRulesAcrossSources() {
+	entityID := types.NewEntityID(types.ContainerID, "entity-1")
+
+	engine, err := rules.NewEngine([]rules.Rule{
+		{
+			Name:  "team-from-namespace",
+			Match: map[string]string{"image_name": "nginx"},
+			Derive: &rules.DeriveRule{
+				Tag:    "team",
+				From:   "kube_namespace",
+				Lookup: map[string]string{"payments": "billing"},
+			},
+		},
+	})
+	require.NoError(s.T(), err)
+	s.tagstore.SetRules(engine)
+
+	s.tagstore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:      "source-1",
+			EntityID:    entityID,
+			LowCardTags: []string{"image_name:nginx"},
+		},
+	})
+
+	// the rule needs a tag that's not reported yet
+	assert.ElementsMatch(s.T(), []string{"image_name:nginx"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+
+	s.tagstore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:      "source-2",
+			EntityID:    entityID,
+			LowCardTags: []string{"kube_namespace:payments"},
+		},
+	})
+
+	assert.ElementsMatch(s.T(), []string{"image_name:nginx", "kube_namespace:payments", "team:billing"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+
+	entity := s.tagstore.List().Entities[entityID.String()]
+	assert.Equal(s.T(), []string{"team:billing"}, entity.Tags["rules"])
+	assert.Equal(s.T(), []string{"image_name:nginx"}, entity.Tags["source-1"])
+
+	// tags collected by any source take precedence over derived ones
+	s.tagstore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:      "source-3",
+			EntityID:    entityID,
+			LowCardTags: []string{"team:checkout"},
+		},
+	})
+
+	assert.ElementsMatch(s.T(), []string{"image_name:nginx", "kube_namespace:payments", "team:checkout"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+	assert.Nil(s.T(), s.tagstore.List().Entities[entityID.String()].Rules)
+
+	// the rules are evaluated again when a source expires
+	s.tagstore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:       "source-3",
+			EntityID:     entityID,
+			DeleteEntity: true,
+		},
+	})
+	s.clock.Add(10 * time.Minute)
+	s.tagstore.Prune()
+
+	assert.ElementsMatch(s.T(), []string{"image_name:nginx", "kube_namespace:payments", "team:billing"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, &StoreTestSuite{})
 }
@@ -612,3 +741,96 @@ func checkEvents(t *testing.T, expectations []entityEventExpectation, events []t
 		}
 	}
 }
+
+func TestTombstones(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, telemetryimpl.MockModule())
+	telemetryStore := taggerTelemetry.NewStore(tel)
+	clock := clock.NewMock()
+	clock.Add(time.Since(time.Unix(0, 0)))
+
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("tagger_tombstone_grace_period", 10*time.Minute)
+	mockConfig.SetWithoutSource("tagger_tombstone_max_entities", 1)
+	store := newTagStoreWithClock(mockConfig, clock, telemetryStore)
+
+	entityID1 := types.NewEntityID(types.ContainerID, "test1")
+	entityID2 := types.NewEntityID(types.ContainerID, "test2")
+
+	store.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:       "source",
+			EntityID:     entityID1,
+			LowCardTags:  []string{"low"},
+			HighCardTags: []string{"high"},
+			StandardTags: []string{"service:foo"},
+		},
+		{
+			Source:      "source",
+			EntityID:    entityID2,
+			LowCardTags: []string{"low"},
+		},
+	})
+	store.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:       "source",
+			EntityID:     entityID1,
+			DeleteEntity: true,
+		},
+	})
+
+	// the entity is removed from the store, but still served from its tombstone
+	clock.Add(10 * time.Minute)
+	store.Prune()
+
+	_, err := store.GetEntity(entityID1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ElementsMatch(t, []string{"low", "high"}, store.Lookup(entityID1, types.HighCardinality))
+	assert.ElementsMatch(t, []string{"low"}, store.Lookup(entityID1, types.LowCardinality))
+	standard, err := store.LookupStandard(entityID1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service:foo"}, standard)
+	assert.Equal(t, 1, store.tombstones.size())
+
+	// the oldest tombstone is evicted when the limit is reached
+	store.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:       "source",
+			EntityID:     entityID2,
+			DeleteEntity: true,
+		},
+	})
+	clock.Add(6 * time.Minute)
+	store.Prune()
+
+	assert.Empty(t, store.Lookup(entityID1, types.HighCardinality))
+	assert.ElementsMatch(t, []string{"low"}, store.Lookup(entityID2, types.HighCardinality))
+	assert.Equal(t, 1, store.tombstones.size())
+
+	// an entity added back to the store is not served from its tombstone anymore
+	store.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:      "source",
+			EntityID:    entityID2,
+			LowCardTags: []string{"new"},
+		},
+	})
+	assert.ElementsMatch(t, []string{"new"}, store.Lookup(entityID2, types.HighCardinality))
+	assert.Equal(t, 0, store.tombstones.size())
+
+	// tombstones expire after the grace period
+	store.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:       "source",
+			EntityID:     entityID2,
+			DeleteEntity: true,
+		},
+	})
+	clock.Add(6 * time.Minute)
+	store.Prune()
+	assert.ElementsMatch(t, []string{"new"}, store.Lookup(entityID2, types.HighCardinality))
+
+	clock.Add(11 * time.Minute)
+	store.Prune()
+	assert.Empty(t, store.Lookup(entityID2, types.HighCardinality))
+	assert.Equal(t, 0, store.tombstones.size())
+}
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+)
+
+// tombstoneSource is the source name of the entity tags kept in tombstones.
+const tombstoneSource = "tombstone"
+
+// tombstone holds the last known tags of an entity removed from the store.
+type tombstone struct {
+	tags       *EntityTagsWithSingleSource
+	expiryDate time.Time
+}
+
+// tombstoneEntry is an entry of the eviction queue. The expiry date is used
+// to skip the entries of tombstones that have since been replaced or
+// removed.
+type tombstoneEntry struct {
+	entityID   types.EntityID
+	expiryDate time.Time
+}
+
+// tombstones keeps the tags of the entities removed from the store for a
+// grace period, so that late lookups, for example for the last metrics of a
+// short-lived container, still resolve. The number of tombstones is capped,
+// the oldest ones being evicted first. It is not thread-safe and relies on
+// the lock of the store.
+type tombstones struct {
+	gracePeriod time.Duration
+	maxEntities int
+
+	entities map[types.EntityID]*tombstone
+	// queue holds the tombstones in insertion order. Since they all have the
+	// same grace period, it's also their expiration order.
+	queue []tombstoneEntry
+}
+
+func newTombstones(gracePeriod time.Duration, maxEntities int) *tombstones {
+	return &tombstones{
+		gracePeriod: gracePeriod,
+		maxEntities: maxEntities,
+		entities:    make(map[types.EntityID]*tombstone),
+	}
+}
+
+// enabled returns whether removed entities should be kept as tombstones.
+func (t *tombstones) enabled() bool {
+	return t.gracePeriod > 0 && t.maxEntities > 0
+}
+
+// add keeps the tags of the given entity until the grace period elapses.
+func (t *tombstones) add(entity types.Entity, now time.Time) {
+	if !t.enabled() {
+		return
+	}
+
+	if len(entity.LowCardinalityTags) == 0 && len(entity.OrchestratorCardinalityTags) == 0 &&
+		len(entity.HighCardinalityTags) == 0 && len(entity.StandardTags) == 0 {
+		return
+	}
+
+	tags := newEntityTagsWithSingleSource(entity.ID, tombstoneSource)
+	tags.setTagsForSource(tombstoneSource, sourceTags{
+		lowCardTags:          entity.LowCardinalityTags,
+		orchestratorCardTags: entity.OrchestratorCardinalityTags,
+		highCardTags:         entity.HighCardinalityTags,
+		standardTags:         entity.StandardTags,
+	})
+
+	expiryDate := now.Add(t.gracePeriod)
+	t.entities[entity.ID] = &tombstone{
+		tags:       tags,
+		expiryDate: expiryDate,
+	}
+	t.queue = append(t.queue, tombstoneEntry{entityID: entity.ID, expiryDate: expiryDate})
+
+	for len(t.entities) > t.maxEntities {
+		t.pop()
+	}
+}
+
+// get returns the tags of the tombstone of the given entity.
+func (t *tombstones) get(entityID types.EntityID, now time.Time) (EntityTags, bool) {
+	ts, ok := t.entities[entityID]
+	if !ok || ts.expiryDate.Before(now) {
+		return nil, false
+	}
+
+	return ts.tags, true
+}
+
+// remove deletes the tombstone of the given entity, if any. It's called
+// when the entity is added back to the store.
+func (t *tombstones) remove(entityID types.EntityID) {
+	delete(t.entities, entityID)
+}
+
+// prune deletes the expired tombstones.
+func (t *tombstones) prune(now time.Time) {
+	for len(t.queue) > 0 && t.queue[0].expiryDate.Before(now) {
+		t.pop()
+	}
+}
+
+// size returns the number of tombstones.
+func (t *tombstones) size() int {
+	return len(t.entities)
+}
+
+// pop removes the oldest entry of the queue, and its tombstone if it's still
+// the one the entry was created for.
+func (t *tombstones) pop() {
+	entry := t.queue[0]
+	t.queue[0] = tombstoneEntry{}
+	t.queue = t.queue[1:]
+
+	if ts, ok := t.entities[entry.entityID]; ok && ts.expiryDate.Equal(entry.expiryDate) {
+		delete(t.entities, entry.entityID)
+	}
+}
@@ -40,6 +40,13 @@ type Store struct {
 	// PrunedEntities tracks the number of pruned tagger entities.
 	PrunedEntities telemetry.Gauge
 
+	// TombstonedEntities tracks how many removed entities are kept as
+	// tombstones.
+	TombstonedEntities telemetry.Gauge
+	// TombstoneLookups tracks the number of lookups served from the
+	// tombstones of removed entities.
+	TombstoneLookups telemetry.Counter
+
 	// ClientStreamErrors tracks how many errors were received when streaming
 	// tagger events.
 	ClientStreamErrors telemetry.Counter
@@ -95,6 +102,18 @@ func NewStore(telemetryComp telemetry.Component) *Store {
 				[]string{}, "Number of pruned tagger entities.",
 				telemetry.Options{NoDoubleUnderscoreSep: true}),
 
+			// TombstonedEntities tracks how many removed entities are kept as
+			// tombstones.
+			TombstonedEntities: telemetryComp.NewGaugeWithOpts(subsystem, "tombstoned_entities",
+				[]string{}, "Number of removed entities kept as tombstones.",
+				telemetry.Options{NoDoubleUnderscoreSep: true}),
+
+			// TombstoneLookups tracks the number of lookups served from the
+			// tombstones of removed entities.
+			TombstoneLookups: telemetryComp.NewCounterWithOpts(subsystem, "tombstone_lookups",
+				[]string{}, "Number of lookups served from the tombstones of removed entities.",
+				telemetry.Options{NoDoubleUnderscoreSep: true}),
+
 			// ServerStreamErrors tracks how many errors happened when streaming
 			// out tagger events.
 			ServerStreamErrors: telemetryComp.NewCounterWithOpts(subsystem, "server_stream_errors",
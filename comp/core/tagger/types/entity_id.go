@@ -60,8 +60,12 @@ const (
 	KubernetesMetadata EntityIDPrefix = "kubernetes_metadata"
 	// KubernetesPodUID is the prefix `kubernetes_pod_uid`
 	KubernetesPodUID EntityIDPrefix = "kubernetes_pod_uid"
+	// NomadAllocation is the prefix `nomad_allocation`
+	NomadAllocation EntityIDPrefix = "nomad_allocation"
 	// Process is the prefix `process`
 	Process EntityIDPrefix = "process"
+	// SystemdUnit is the prefix `systemd_unit`
+	SystemdUnit EntityIDPrefix = "systemd_unit"
 	// InternalID is the prefix `internal`
 	InternalID EntityIDPrefix = "internal"
 )
@@ -76,7 +80,9 @@ func AllPrefixesSet() map[EntityIDPrefix]struct{} {
 		KubernetesDeployment:   {},
 		KubernetesMetadata:     {},
 		KubernetesPodUID:       {},
+		NomadAllocation:        {},
 		Process:                {},
+		SystemdUnit:            {},
 		InternalID:             {},
 	}
 }
@@ -57,7 +57,9 @@ func TestFilterBuilderOps(t *testing.T) {
 					ECSTask:                {},
 					KubernetesMetadata:     {},
 					KubernetesPodUID:       {},
+					NomadAllocation:        {},
 					Process:                {},
+					SystemdUnit:            {},
 					InternalID:             {},
 				},
 				cardinality: HighCardinality,
@@ -42,6 +42,9 @@ type TaggerListResponse struct {
 // TaggerListEntity holds the tagging info about an entity
 type TaggerListEntity struct {
 	Tags map[string][]string `json:"tags"`
+	// Rules maps, for every source, the tags produced by enrichment rules
+	// to the name of the rule that produced them.
+	Rules map[string]map[string]string `json:"rules,omitempty"`
 }
 
 // TagInfo holds the tag information for a given entity and source. It's meant
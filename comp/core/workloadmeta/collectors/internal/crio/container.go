@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cri
+
+package crio
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// containerInfo is the verbose information returned by CRI-O along with the
+// container status, under the "info" key.
+type containerInfo struct {
+	PID         int         `json:"pid"`
+	SandboxID   string      `json:"sandboxID"`
+	RuntimeSpec *specs.Spec `json:"runtimeSpec"`
+}
+
+func convertContainer(container *criv1.Container, resp *criv1.ContainerStatusResponse, podUID string) *workloadmeta.Container {
+	status := resp.GetStatus()
+	containerID := container.GetId()
+
+	var info containerInfo
+	if raw, ok := resp.GetInfo()["info"]; ok {
+		if err := json.Unmarshal([]byte(raw), &info); err != nil {
+			log.Debugf("Could not parse verbose status of CRI-O container %s: %v", containerID, err)
+		}
+	}
+
+	// The image name is the one specified by the user, while the image
+	// reference is resolved by the runtime.
+	imageName := status.GetImage().GetImage()
+	if imageName == "" {
+		imageName = container.GetImage().GetImage()
+	}
+	image, err := workloadmeta.NewContainerImage(status.GetImageRef(), imageName)
+	if err != nil {
+		log.Debugf("Could not parse image %q of CRI-O container %s: %v", imageName, containerID, err)
+	}
+
+	entity := &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   containerID,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        status.GetMetadata().GetName(),
+			Namespace:   status.GetLabels()["io.kubernetes.pod.namespace"],
+			Labels:      status.GetLabels(),
+			Annotations: status.GetAnnotations(),
+		},
+		Image:   image,
+		PID:     info.PID,
+		Runtime: workloadmeta.ContainerRuntimeCRIO,
+		State:   convertState(status),
+	}
+
+	if spec := info.RuntimeSpec; spec != nil {
+		entity.Hostname = spec.Hostname
+		if spec.Process != nil {
+			entity.EnvVars = envVars(spec.Process.Env)
+		}
+		if spec.Linux != nil {
+			entity.CgroupPath = spec.Linux.CgroupsPath
+		}
+	}
+
+	if podUID != "" {
+		entity.Owner = &workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   podUID,
+		}
+	}
+
+	return entity
+}
+
+func convertState(status *criv1.ContainerStatus) workloadmeta.ContainerState {
+	state := workloadmeta.ContainerState{
+		Running:    status.GetState() == criv1.ContainerState_CONTAINER_RUNNING,
+		Status:     convertStatus(status.GetState()),
+		CreatedAt:  timestamp(status.GetCreatedAt()),
+		StartedAt:  timestamp(status.GetStartedAt()),
+		FinishedAt: timestamp(status.GetFinishedAt()),
+	}
+
+	if status.GetState() == criv1.ContainerState_CONTAINER_EXITED {
+		exitCode := int64(status.GetExitCode())
+		state.ExitCode = &exitCode
+	}
+
+	return state
+}
+
+func convertStatus(state criv1.ContainerState) workloadmeta.ContainerStatus {
+	switch state {
+	case criv1.ContainerState_CONTAINER_CREATED:
+		return workloadmeta.ContainerStatusCreated
+	case criv1.ContainerState_CONTAINER_RUNNING:
+		return workloadmeta.ContainerStatusRunning
+	case criv1.ContainerState_CONTAINER_EXITED:
+		return workloadmeta.ContainerStatusStopped
+	}
+
+	return workloadmeta.ContainerStatusUnknown
+}
+
+// timestamp converts a CRI timestamp, in nanoseconds, to a time. The zero
+// value means that the timestamp is not set.
+func timestamp(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+func envVars(env []string) map[string]string {
+	res := make(map[string]string)
+
+	filter := containers.EnvVarFilterFromConfig()
+	for _, v := range env {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			continue
+		}
+
+		if filter.IsIncluded(name) {
+			res[name] = value
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cri
+
+// Package crio implements the CRI-O Workloadmeta collector.
+package crio
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/config/env"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/cri"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	collectorID   = "crio"
+	componentName = "workloadmeta-crio"
+)
+
+type collector struct {
+	id      string
+	client  cri.CRIClient
+	store   workloadmeta.Component
+	catalog workloadmeta.AgentType
+
+	// seenContainers and seenImages are the entities notified during the
+	// previous pull, used to detect the deleted ones. Images are indexed
+	// with their references, to detect the re-tagged ones.
+	seenContainers map[workloadmeta.EntityID]struct{}
+	seenImages     map[workloadmeta.EntityID]string
+}
+
+// NewCollector returns a new CRI-O collector provider and an error
+func NewCollector() (workloadmeta.CollectorProvider, error) {
+	return workloadmeta.CollectorProvider{
+		Collector: &collector{
+			id:             collectorID,
+			catalog:        workloadmeta.NodeAgent | workloadmeta.ProcessAgent,
+			seenContainers: make(map[workloadmeta.EntityID]struct{}),
+			seenImages:     make(map[workloadmeta.EntityID]string),
+		},
+	}, nil
+}
+
+// GetFxOptions returns the FX framework options for the collector
+func GetFxOptions() fx.Option {
+	return fx.Provide(NewCollector)
+}
+
+// Start the collector for the provided workloadmeta component
+func (c *collector) Start(_ context.Context, store workloadmeta.Component) error {
+	if !env.IsFeaturePresent(env.Crio) {
+		return dderrors.NewDisabled(componentName, "CRI-O not detected")
+	}
+
+	client, err := cri.GetUtil()
+	if err != nil {
+		return err
+	}
+
+	c.client = client
+	c.store = store
+
+	return nil
+}
+
+// Pull lists the containers and images known by CRI-O, and notifies the
+// store of the ones that were created, updated or deleted since the last pull.
+func (c *collector) Pull(ctx context.Context) error {
+	containerEvents, err := c.pullContainers(ctx)
+	if err != nil {
+		return err
+	}
+
+	imageEvents, err := c.pullImages(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.store.Notify(append(containerEvents, imageEvents...))
+
+	return nil
+}
+
+func (c *collector) GetID() string {
+	return c.id
+}
+
+func (c *collector) GetTargetCatalog() workloadmeta.AgentType {
+	return c.catalog
+}
+
+func (c *collector) pullContainers(ctx context.Context) ([]workloadmeta.CollectorEvent, error) {
+	containers, err := c.client.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sandboxes, err := c.client.ListPodSandboxes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	podUIDs := make(map[string]string, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		podUIDs[sandbox.GetId()] = sandbox.GetMetadata().GetUid()
+	}
+
+	seen := make(map[workloadmeta.EntityID]struct{}, len(containers))
+	events := make([]workloadmeta.CollectorEvent, 0, len(containers))
+
+	for _, container := range containers {
+		status, err := c.client.GetContainerStatus(ctx, container.GetId())
+		if err != nil {
+			// containers are only removed once they are no longer listed,
+			// the store keeps the last known state until the next pull.
+			log.Debugf("Could not get status of CRI-O container %s: %v", container.GetId(), err)
+			containerID := workloadmeta.EntityID{
+				Kind: workloadmeta.KindContainer,
+				ID:   container.GetId(),
+			}
+			if _, ok := c.seenContainers[containerID]; ok {
+				seen[containerID] = struct{}{}
+			}
+			continue
+		}
+
+		entity := convertContainer(container, status, podUIDs[container.GetPodSandboxId()])
+		seen[entity.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: entity,
+		})
+	}
+
+	for seenID := range c.seenContainers {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceRuntime,
+			Entity: &workloadmeta.Container{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seenContainers = seen
+
+	return events, nil
+}
+
+func (c *collector) pullImages(ctx context.Context) ([]workloadmeta.CollectorEvent, error) {
+	images, err := c.client.ListImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[workloadmeta.EntityID]string, len(images))
+	var events []workloadmeta.CollectorEvent
+
+	for _, image := range images {
+		imageID := workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainerImageMetadata,
+			ID:   image.GetId(),
+		}
+
+		// Images are immutable, their metadata only needs to be fetched
+		// once. Their tags can change though.
+		references := imageReferences(image)
+		if previous, ok := c.seenImages[imageID]; ok && previous == references {
+			seen[imageID] = references
+			continue
+		}
+
+		status, err := c.client.GetImageStatus(ctx, image.GetId())
+		if err != nil || status.GetImage() == nil {
+			// images are only removed once they are no longer listed, the
+			// previous metadata is kept and fetched again at the next pull.
+			log.Debugf("Could not get status of CRI-O image %s: %v", image.GetId(), err)
+			if previous, ok := c.seenImages[imageID]; ok {
+				seen[imageID] = previous
+			}
+			continue
+		}
+
+		seen[imageID] = references
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: convertImage(status),
+		})
+	}
+
+	for seenID := range c.seenImages {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceRuntime,
+			Entity: &workloadmeta.ContainerImageMetadata{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seenImages = seen
+
+	return events, nil
+}
@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cri && !windows
+
+package crio
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/cri"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Component
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+// fakeCRIServer is a minimal CRI server, serving the containers, sandboxes
+// and images it has been given along with CRI-O like verbose information.
+type fakeCRIServer struct {
+	criv1.UnimplementedRuntimeServiceServer
+	criv1.UnimplementedImageServiceServer
+
+	mu         sync.Mutex
+	sandboxes  []*criv1.PodSandbox
+	containers map[string]*criv1.ContainerStatusResponse
+	// sandboxIDs maps container IDs to their pod sandbox
+	sandboxIDs map[string]string
+	images     map[string]*criv1.ImageStatusResponse
+
+	imageStatusCalls int
+	// containerStatusErr is returned by ContainerStatus when set
+	containerStatusErr error
+}
+
+func (s *fakeCRIServer) Version(context.Context, *criv1.VersionRequest) (*criv1.VersionResponse, error) {
+	return &criv1.VersionResponse{RuntimeName: "cri-o", RuntimeVersion: "1.30.0"}, nil
+}
+
+func (s *fakeCRIServer) ListPodSandbox(context.Context, *criv1.ListPodSandboxRequest) (*criv1.ListPodSandboxResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &criv1.ListPodSandboxResponse{Items: s.sandboxes}, nil
+}
+
+func (s *fakeCRIServer) ListContainers(context.Context, *criv1.ListContainersRequest) (*criv1.ListContainersResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var containers []*criv1.Container
+	for _, resp := range s.containers {
+		containers = append(containers, &criv1.Container{
+			Id:           resp.Status.Id,
+			PodSandboxId: s.sandboxIDs[resp.Status.Id],
+			Metadata:     resp.Status.Metadata,
+			Image:        resp.Status.Image,
+			ImageRef:     resp.Status.ImageRef,
+			State:        resp.Status.State,
+		})
+	}
+	return &criv1.ListContainersResponse{Containers: containers}, nil
+}
+
+func (s *fakeCRIServer) ContainerStatus(_ context.Context, req *criv1.ContainerStatusRequest) (*criv1.ContainerStatusResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.containerStatusErr != nil {
+		return nil, s.containerStatusErr
+	}
+	resp, ok := s.containers[req.ContainerId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "container %s not found", req.ContainerId)
+	}
+	return resp, nil
+}
+
+func (s *fakeCRIServer) ListImages(context.Context, *criv1.ListImagesRequest) (*criv1.ListImagesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var images []*criv1.Image
+	for _, resp := range s.images {
+		images = append(images, resp.Image)
+	}
+	return &criv1.ListImagesResponse{Images: images}, nil
+}
+
+func (s *fakeCRIServer) ImageStatus(_ context.Context, req *criv1.ImageStatusRequest) (*criv1.ImageStatusResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.imageStatusCalls++
+	resp, ok := s.images[req.Image.Image]
+	if !ok {
+		return &criv1.ImageStatusResponse{}, nil
+	}
+	return resp, nil
+}
+
+func startFakeCRIServer(t *testing.T, fake *fakeCRIServer) cri.CRIClient {
+	socketPath := filepath.Join(t.TempDir(), "crio.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	criv1.RegisterRuntimeServiceServer(server, fake)
+	criv1.RegisterImageServiceServer(server, fake)
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+
+	client, err := cri.NewCRIUtil(socketPath, 5*time.Second, 5*time.Second)
+	require.NoError(t, err)
+
+	return client
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestPull(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	startedAt := createdAt.Add(time.Second)
+	finishedAt := createdAt.Add(time.Hour)
+
+	imageID := "sha256:6c1f0f0b1a3ea5b8f8e0d39bd4ea95c8e3ae3e9c1b0e7fce59ef6e8d1a2f3b4c"
+	imageRef := "docker.io/library/redis@sha256:0f97c1c9daf5b69b93390ccbe8d3e2971617ec4801fd0882c72bf7cad3a13494"
+
+	fake := &fakeCRIServer{
+		sandboxes: []*criv1.PodSandbox{
+			{
+				Id: "sandbox1",
+				Metadata: &criv1.PodSandboxMetadata{
+					Name:      "redis-0",
+					Namespace: "default",
+					Uid:       "pod-uid-1",
+				},
+			},
+		},
+		containers: map[string]*criv1.ContainerStatusResponse{
+			"running": {
+				Status: &criv1.ContainerStatus{
+					Id:        "running",
+					Metadata:  &criv1.ContainerMetadata{Name: "redis"},
+					State:     criv1.ContainerState_CONTAINER_RUNNING,
+					CreatedAt: createdAt.UnixNano(),
+					StartedAt: startedAt.UnixNano(),
+					Image:     &criv1.ImageSpec{Image: "docker.io/library/redis:7.2"},
+					ImageRef:  imageRef,
+					Labels: map[string]string{
+						"io.kubernetes.pod.namespace":  "default",
+						"io.kubernetes.container.name": "redis",
+					},
+					Annotations: map[string]string{
+						"io.kubernetes.container.restartCount": "0",
+					},
+				},
+				Info: map[string]string{
+					"info": mustMarshal(t, containerInfo{
+						PID:       4242,
+						SandboxID: "sandbox1",
+						RuntimeSpec: &specs.Spec{
+							Hostname: "redis-0",
+							Process: &specs.Process{
+								Env: []string{"TEST_ENV=test", "SECRET=hidden"},
+							},
+							Linux: &specs.Linux{
+								CgroupsPath: "kubepods-besteffort-podpod_uid_1.slice:crio:running",
+							},
+						},
+					}),
+				},
+			},
+			"exited": {
+				Status: &criv1.ContainerStatus{
+					Id:         "exited",
+					Metadata:   &criv1.ContainerMetadata{Name: "init"},
+					State:      criv1.ContainerState_CONTAINER_EXITED,
+					CreatedAt:  createdAt.UnixNano(),
+					StartedAt:  startedAt.UnixNano(),
+					FinishedAt: finishedAt.UnixNano(),
+					ExitCode:   1,
+					Image:      &criv1.ImageSpec{Image: "docker.io/library/busybox:latest"},
+					ImageRef:   "docker.io/library/busybox@sha256:3fbc632167424a6d997e74f52b878d7cc478225cffac6bc977eedfe51c7f4e79",
+				},
+			},
+		},
+		sandboxIDs: map[string]string{
+			"running": "sandbox1",
+		},
+		images: map[string]*criv1.ImageStatusResponse{
+			imageID: {
+				Image: &criv1.Image{
+					Id:          imageID,
+					RepoTags:    []string{"docker.io/library/redis:7.2"},
+					RepoDigests: []string{imageRef},
+					Size_:       1024,
+				},
+				Info: map[string]string{
+					"info": mustMarshal(t, imageInfo{
+						Labels: map[string]string{"maintainer": "redis"},
+						ImageSpec: &ocispec.Image{
+							Platform: ocispec.Platform{
+								OS:           "linux",
+								Architecture: "amd64",
+							},
+							RootFS: ocispec.RootFS{
+								Type:    "layers",
+								DiffIDs: []digest.Digest{"sha256:aaaa", "sha256:bbbb"},
+							},
+							History: []ocispec.History{
+								{CreatedBy: "ADD rootfs.tar.gz /"},
+								{CreatedBy: "ENV REDIS_VERSION=7.2", EmptyLayer: true},
+								{CreatedBy: "RUN install-redis"},
+							},
+						},
+					}),
+				},
+			},
+		},
+	}
+
+	store := &fakeWorkloadmetaStore{}
+	c := &collector{
+		client:         startFakeCRIServer(t, fake),
+		store:          store,
+		seenContainers: make(map[workloadmeta.EntityID]struct{}),
+		seenImages:     make(map[workloadmeta.EntityID]string),
+	}
+
+	// First pull: every container and image is notified
+	require.NoError(t, c.Pull(context.Background()))
+
+	containers := make(map[string]*workloadmeta.Container)
+	var images []*workloadmeta.ContainerImageMetadata
+	for _, event := range store.notifiedEvents {
+		assert.Equal(t, workloadmeta.EventTypeSet, event.Type)
+		assert.Equal(t, workloadmeta.SourceRuntime, event.Source)
+		switch entity := event.Entity.(type) {
+		case *workloadmeta.Container:
+			containers[entity.ID] = entity
+		case *workloadmeta.ContainerImageMetadata:
+			images = append(images, entity)
+		}
+	}
+	require.Len(t, containers, 2)
+	require.Len(t, images, 1)
+
+	running := containers["running"]
+	assert.Equal(t, "redis", running.Name)
+	assert.Equal(t, "default", running.Namespace)
+	assert.Equal(t, "7.2", running.Image.Tag)
+	assert.Equal(t, "redis", running.Image.ShortName)
+	assert.Equal(t, imageRef, running.Image.ID)
+	assert.Equal(t, 4242, running.PID)
+	assert.Equal(t, "redis-0", running.Hostname)
+	assert.Equal(t, map[string]string{"TEST_ENV": "test"}, running.EnvVars)
+	assert.Equal(t, "kubepods-besteffort-podpod_uid_1.slice:crio:running", running.CgroupPath)
+	assert.Equal(t, workloadmeta.ContainerRuntimeCRIO, running.Runtime)
+	assert.Equal(t, &workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid-1"}, running.Owner)
+	assert.Equal(t, workloadmeta.ContainerState{
+		Running:   true,
+		Status:    workloadmeta.ContainerStatusRunning,
+		CreatedAt: time.Unix(0, createdAt.UnixNano()),
+		StartedAt: time.Unix(0, startedAt.UnixNano()),
+	}, running.State)
+
+	exited := containers["exited"]
+	exitCode := int64(1)
+	assert.Equal(t, 0, exited.PID)
+	assert.Nil(t, exited.Owner)
+	assert.Equal(t, workloadmeta.ContainerState{
+		Running:    false,
+		Status:     workloadmeta.ContainerStatusStopped,
+		CreatedAt:  time.Unix(0, createdAt.UnixNano()),
+		StartedAt:  time.Unix(0, startedAt.UnixNano()),
+		FinishedAt: time.Unix(0, finishedAt.UnixNano()),
+		ExitCode:   &exitCode,
+	}, exited.State)
+
+	image := images[0]
+	assert.Equal(t, imageID, image.ID)
+	assert.Equal(t, "docker.io/library/redis:7.2", image.Name)
+	assert.Equal(t, []string{"docker.io/library/redis:7.2"}, image.RepoTags)
+	assert.Equal(t, []string{imageRef}, image.RepoDigests)
+	assert.Equal(t, int64(1024), image.SizeBytes)
+	assert.Equal(t, "linux", image.OS)
+	assert.Equal(t, "amd64", image.Architecture)
+	assert.Equal(t, map[string]string{"maintainer": "redis"}, image.Labels)
+	require.Len(t, image.Layers, 2)
+	assert.Equal(t, "sha256:aaaa", image.Layers[0].Digest)
+	assert.Equal(t, "ADD rootfs.tar.gz /", image.Layers[0].History.CreatedBy)
+	assert.Equal(t, "sha256:bbbb", image.Layers[1].Digest)
+	assert.Equal(t, "RUN install-redis", image.Layers[1].History.CreatedBy)
+
+	// Second pull: the containers are notified again, the image isn't
+	// fetched again as it didn't change
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Len(t, store.notifiedEvents, 2)
+	assert.Equal(t, 1, fake.imageStatusCalls)
+
+	// Transient status errors don't unset the containers still listed
+	fake.mu.Lock()
+	fake.containerStatusErr = status.Error(codes.Unavailable, "unavailable")
+	fake.mu.Unlock()
+
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Empty(t, store.notifiedEvents)
+
+	fake.mu.Lock()
+	fake.containerStatusErr = nil
+	fake.mu.Unlock()
+
+	// Third pull: the removed container and image are unset
+	fake.mu.Lock()
+	delete(fake.containers, "exited")
+	delete(fake.images, imageID)
+	fake.mu.Unlock()
+
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.Background()))
+
+	var unset []workloadmeta.EntityID
+	for _, event := range store.notifiedEvents {
+		if event.Type == workloadmeta.EventTypeUnset {
+			unset = append(unset, event.Entity.GetID())
+		}
+	}
+	assert.ElementsMatch(t, []workloadmeta.EntityID{
+		{Kind: workloadmeta.KindContainer, ID: "exited"},
+		{Kind: workloadmeta.KindContainerImageMetadata, ID: imageID},
+	}, unset)
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cri
+
+package crio
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// imageInfo is the verbose information returned by CRI-O along with the
+// image status, under the "info" key.
+type imageInfo struct {
+	Labels    map[string]string `json:"labels"`
+	ImageSpec *ocispec.Image    `json:"imageSpec"`
+}
+
+func convertImage(resp *criv1.ImageStatusResponse) *workloadmeta.ContainerImageMetadata {
+	image := resp.GetImage()
+
+	wlmImage := &workloadmeta.ContainerImageMetadata{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainerImageMetadata,
+			ID:   image.GetId(),
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: preferredName(image),
+		},
+		RepoTags:    image.GetRepoTags(),
+		RepoDigests: image.GetRepoDigests(),
+		SizeBytes:   int64(image.GetSize_()),
+	}
+
+	raw, ok := resp.GetInfo()["info"]
+	if !ok {
+		return wlmImage
+	}
+
+	var info imageInfo
+	if err := json.Unmarshal([]byte(raw), &info); err != nil {
+		log.Debugf("Could not parse verbose status of CRI-O image %s: %v", image.GetId(), err)
+		return wlmImage
+	}
+
+	wlmImage.Labels = info.Labels
+	if spec := info.ImageSpec; spec != nil {
+		wlmImage.OS = spec.OS
+		wlmImage.OSVersion = spec.OSVersion
+		wlmImage.Architecture = spec.Architecture
+		wlmImage.Variant = spec.Variant
+		wlmImage.Layers = getLayersWithHistory(spec)
+		if wlmImage.Labels == nil {
+			wlmImage.Labels = spec.Config.Labels
+		}
+	}
+
+	return wlmImage
+}
+
+// getLayersWithHistory matches the layers of the image with their history.
+// CRI-O doesn't expose the manifest of the image, so the layers are
+// identified by their uncompressed digest (diff ID).
+func getLayersWithHistory(spec *ocispec.Image) []workloadmeta.ContainerImageLayer {
+	var layers []workloadmeta.ContainerImageLayer
+
+	// History objects with emptyLayer = true don't have an associated layer.
+	historyIndex := 0
+	for _, diffID := range spec.RootFS.DiffIDs {
+		historyFound := false
+		for ; historyIndex < len(spec.History); historyIndex++ {
+			if !spec.History[historyIndex].EmptyLayer {
+				historyFound = true
+				break
+			}
+		}
+
+		layer := workloadmeta.ContainerImageLayer{
+			Digest: diffID.String(),
+		}
+		if historyFound {
+			layer.History = &spec.History[historyIndex]
+			historyIndex++
+		}
+
+		layers = append(layers, layer)
+	}
+
+	return layers
+}
+
+// preferredName returns the name used to reference an image, preferring the
+// repo tags over the repo digests.
+func preferredName(image *criv1.Image) string {
+	if len(image.GetRepoTags()) > 0 {
+		return image.GetRepoTags()[0]
+	}
+	if len(image.GetRepoDigests()) > 0 {
+		return image.GetRepoDigests()[0]
+	}
+	return image.GetId()
+}
+
+// imageReferences returns a key identifying the tags and digests of an image.
+func imageReferences(image *criv1.Image) string {
+	refs := make([]string, 0, len(image.GetRepoTags())+len(image.GetRepoDigests()))
+	refs = append(refs, image.GetRepoTags()...)
+	refs = append(refs, image.GetRepoDigests()...)
+	sort.Strings(refs)
+	return strings.Join(refs, ",")
+}
@@ -0,0 +1,239 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package nomad implements the Nomad Workloadmeta collector.
+package nomad
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/nomad"
+)
+
+const (
+	collectorID   = "nomad"
+	componentName = "workloadmeta-nomad"
+
+	// Labels set by the Nomad docker driver on the containers of the tasks
+	allocIDLabel  = "com.hashicorp.nomad.alloc_id"
+	taskNameLabel = "com.hashicorp.nomad.task_name"
+)
+
+type collector struct {
+	id      string
+	client  nomad.Client
+	store   workloadmeta.Component
+	catalog workloadmeta.AgentType
+
+	nodeID     string
+	datacenter string
+
+	// seen are the allocations notified during the previous pull, used to
+	// detect the deleted ones.
+	seen map[workloadmeta.EntityID]struct{}
+}
+
+// NewCollector returns a new Nomad collector provider and an error
+func NewCollector() (workloadmeta.CollectorProvider, error) {
+	return workloadmeta.CollectorProvider{
+		Collector: &collector{
+			id:      collectorID,
+			catalog: workloadmeta.NodeAgent | workloadmeta.ProcessAgent,
+			seen:    make(map[workloadmeta.EntityID]struct{}),
+		},
+	}, nil
+}
+
+// GetFxOptions returns the FX framework options for the collector
+func GetFxOptions() fx.Option {
+	return fx.Provide(NewCollector)
+}
+
+// Start the collector for the provided workloadmeta component
+func (c *collector) Start(ctx context.Context, store workloadmeta.Component) error {
+	agentURL := pkgconfigsetup.Datadog().GetString("nomad_agent_url")
+	if agentURL == "" {
+		return dderrors.NewDisabled(componentName, "nomad_agent_url not set")
+	}
+
+	client := nomad.NewClient(
+		agentURL,
+		pkgconfigsetup.Datadog().GetString("nomad_token"),
+		pkgconfigsetup.Datadog().GetDuration("nomad_query_timeout")*time.Second,
+	)
+
+	agent, err := client.GetAgent(ctx)
+	if err != nil {
+		return err
+	}
+	if agent.Stats.Client == nil || agent.Stats.Client.NodeID == "" {
+		return dderrors.NewDisabled(componentName, "the Nomad agent is not running in client mode")
+	}
+
+	c.client = client
+	c.store = store
+	c.nodeID = agent.Stats.Client.NodeID
+	c.datacenter = agent.Config.Datacenter
+
+	return nil
+}
+
+// Pull lists the allocations placed on the node, and notifies the store of
+// the ones that were created, updated or deleted since the last pull.
+func (c *collector) Pull(ctx context.Context) error {
+	allocs, err := c.client.GetNodeAllocations(ctx, c.nodeID)
+	if err != nil {
+		return err
+	}
+
+	containerIDs := c.taskContainers()
+
+	seen := make(map[workloadmeta.EntityID]struct{}, len(allocs))
+	events := make([]workloadmeta.CollectorEvent, 0, len(allocs))
+
+	for _, alloc := range allocs {
+		if isTerminal(alloc.ClientStatus) {
+			continue
+		}
+
+		entity := c.convertAllocation(alloc, containerIDs)
+		seen[entity.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: entity,
+		})
+	}
+
+	for seenID := range c.seen {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceRuntime,
+			Entity: &workloadmeta.NomadAllocation{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seen = seen
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) GetID() string {
+	return c.id
+}
+
+func (c *collector) GetTargetCatalog() workloadmeta.AgentType {
+	return c.catalog
+}
+
+func (c *collector) convertAllocation(alloc nomad.Allocation, containerIDs map[string]string) *workloadmeta.NomadAllocation {
+	entity := &workloadmeta.NomadAllocation{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindNomadAllocation,
+			ID:   alloc.ID,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      alloc.Name,
+			Namespace: alloc.Namespace,
+		},
+		JobID:        alloc.JobID,
+		TaskGroup:    alloc.TaskGroup,
+		Datacenter:   c.datacenter,
+		NodeName:     alloc.NodeName,
+		ClientStatus: alloc.ClientStatus,
+	}
+
+	if alloc.Job == nil {
+		return entity
+	}
+
+	group := alloc.Job.TaskGroup(alloc.TaskGroup)
+	if group == nil {
+		return entity
+	}
+
+	for _, svc := range group.Services {
+		entity.Services = append(entity.Services, convertService(svc, svc.TaskName))
+	}
+
+	for _, task := range group.Tasks {
+		entity.Tasks = append(entity.Tasks, workloadmeta.NomadTask{
+			Name:        task.Name,
+			Driver:      task.Driver,
+			State:       alloc.TaskStates[task.Name].State,
+			ContainerID: containerIDs[taskKey(alloc.ID, task.Name)],
+		})
+
+		for _, svc := range task.Services {
+			entity.Services = append(entity.Services, convertService(svc, task.Name))
+		}
+	}
+
+	return entity
+}
+
+func convertService(svc nomad.Service, task string) workloadmeta.NomadService {
+	return workloadmeta.NomadService{
+		Name:      svc.Name,
+		Task:      task,
+		Provider:  svc.Provider,
+		PortLabel: svc.PortLabel,
+		Tags:      svc.Tags,
+		Meta:      svc.Meta,
+	}
+}
+
+// taskContainers returns the IDs of the containers running the tasks of
+// allocations, indexed by allocation ID and task name. The docker driver
+// labels its containers with them, other container drivers only name their
+// containers after them.
+func (c *collector) taskContainers() map[string]string {
+	containerIDs := make(map[string]string)
+
+	for _, container := range c.store.ListContainers() {
+		allocID, task := container.Labels[allocIDLabel], container.Labels[taskNameLabel]
+		if allocID != "" && task != "" {
+			containerIDs[taskKey(allocID, task)] = container.ID
+			continue
+		}
+
+		// Container names are <task>-<allocation ID>, and allocation IDs are
+		// UUIDs, which are 36 characters long.
+		name := strings.TrimPrefix(container.Name, "/")
+		if len(name) > 37 && name[len(name)-37] == '-' {
+			containerIDs[taskKey(name[len(name)-36:], name[:len(name)-37])] = container.ID
+		}
+	}
+
+	return containerIDs
+}
+
+func taskKey(allocID, task string) string {
+	return allocID + "/" + task
+}
+
+// isTerminal returns whether the tasks of an allocation with the given client
+// status have all stopped.
+func isTerminal(clientStatus string) bool {
+	switch clientStatus {
+	case "complete", "failed", "lost":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package nomad
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/nomad"
+)
+
+const (
+	nodeID   = "f7476465-4d6e-c0de-26d0-e383c49be941"
+	webAlloc = "5456bd7a-9fc0-c0dd-6131-cbee77f57577"
+	dbAlloc  = "8ba85cef-9a3b-4b54-a3e5-8b1e3f0a4d2c"
+)
+
+type fakeNomadClient struct {
+	allocs []nomad.Allocation
+}
+
+func (c *fakeNomadClient) GetAgent(context.Context) (*nomad.Agent, error) {
+	return &nomad.Agent{Stats: nomad.AgentStats{Client: &nomad.AgentClientStats{NodeID: nodeID}}}, nil
+}
+
+func (c *fakeNomadClient) GetNodeAllocations(_ context.Context, node string) ([]nomad.Allocation, error) {
+	if node != nodeID {
+		return nil, nil
+	}
+	return c.allocs, nil
+}
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Component
+	containers     []*workloadmeta.Container
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) ListContainers() []*workloadmeta.Container {
+	return store.containers
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+func TestPull(t *testing.T) {
+	client := &fakeNomadClient{
+		allocs: []nomad.Allocation{
+			{
+				ID:           webAlloc,
+				Name:         "web.frontend[0]",
+				Namespace:    "default",
+				NodeName:     "nomad-client-1",
+				JobID:        "web",
+				TaskGroup:    "frontend",
+				ClientStatus: "running",
+				Job: &nomad.Job{
+					ID: "web",
+					TaskGroups: []nomad.TaskGroup{
+						{
+							Name: "frontend",
+							Services: []nomad.Service{
+								{Name: "nginx", PortLabel: "http", Meta: map[string]string{"team": "web"}},
+							},
+							Tasks: []nomad.Task{
+								{Name: "nginx", Driver: "docker"},
+								{
+									Name:     "exporter",
+									Driver:   "containerd-driver",
+									Services: []nomad.Service{{Name: "exporter", PortLabel: "metrics"}},
+								},
+								{Name: "logrotate", Driver: "exec"},
+							},
+						},
+					},
+				},
+				TaskStates: map[string]nomad.TaskState{
+					"nginx":     {State: "running"},
+					"exporter":  {State: "running"},
+					"logrotate": {State: "running"},
+				},
+			},
+			{
+				ID:           dbAlloc,
+				Name:         "db.main[0]",
+				Namespace:    "default",
+				JobID:        "db",
+				TaskGroup:    "main",
+				ClientStatus: "complete",
+			},
+		},
+	}
+
+	store := &fakeWorkloadmetaStore{
+		containers: []*workloadmeta.Container{
+			{
+				EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "docker-id"},
+				EntityMeta: workloadmeta.EntityMeta{
+					Name: "nginx-" + webAlloc,
+					Labels: map[string]string{
+						allocIDLabel:  webAlloc,
+						taskNameLabel: "nginx",
+					},
+				},
+			},
+			{
+				EntityID:   workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "containerd-id"},
+				EntityMeta: workloadmeta.EntityMeta{Name: "exporter-" + webAlloc},
+			},
+			{
+				EntityID:   workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "other-id"},
+				EntityMeta: workloadmeta.EntityMeta{Name: "unrelated"},
+			},
+		},
+	}
+
+	c := &collector{
+		client:     client,
+		store:      store,
+		nodeID:     nodeID,
+		datacenter: "dc1",
+		seen:       make(map[workloadmeta.EntityID]struct{}),
+	}
+
+	require.NoError(t, c.Pull(context.Background()))
+
+	expected := &workloadmeta.NomadAllocation{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindNomadAllocation,
+			ID:   webAlloc,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web.frontend[0]",
+			Namespace: "default",
+		},
+		JobID:        "web",
+		TaskGroup:    "frontend",
+		Datacenter:   "dc1",
+		NodeName:     "nomad-client-1",
+		ClientStatus: "running",
+		Tasks: []workloadmeta.NomadTask{
+			{Name: "nginx", Driver: "docker", State: "running", ContainerID: "docker-id"},
+			{Name: "exporter", Driver: "containerd-driver", State: "running", ContainerID: "containerd-id"},
+			{Name: "logrotate", Driver: "exec", State: "running"},
+		},
+		Services: []workloadmeta.NomadService{
+			{Name: "nginx", PortLabel: "http", Meta: map[string]string{"team": "web"}},
+			{Name: "exporter", Task: "exporter", PortLabel: "metrics"},
+		},
+	}
+
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: expected,
+		},
+	}, store.notifiedEvents)
+
+	// The allocation is unset once it has stopped
+	client.allocs[0].ClientStatus = "complete"
+	store.notifiedEvents = nil
+
+	require.NoError(t, c.Pull(context.Background()))
+
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceRuntime,
+			Entity: &workloadmeta.NomadAllocation{
+				EntityID: expected.EntityID,
+			},
+		},
+	}, store.notifiedEvents)
+}
@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+// Package systemd implements the systemd Workloadmeta collector.
+package systemd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.uber.org/fx"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/config/env"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	systemdutil "github.com/DataDog/datadog-agent/pkg/util/systemd"
+)
+
+const (
+	collectorID   = "systemd"
+	componentName = "workloadmeta-systemd"
+
+	serviceSuffix = ".service"
+)
+
+// dbusConn is the subset of the systemd D-Bus API used by the collector
+type dbusConn interface {
+	ListUnitsContext(ctx context.Context) ([]dbus.UnitStatus, error)
+	GetUnitPropertiesContext(ctx context.Context, unit string) (map[string]interface{}, error)
+	GetUnitTypePropertiesContext(ctx context.Context, unit string, unitType string) (map[string]interface{}, error)
+	Close()
+}
+
+type collector struct {
+	id      string
+	store   workloadmeta.Component
+	catalog workloadmeta.AgentType
+
+	connect func() (dbusConn, error)
+	conn    dbusConn
+
+	// hostRoot is the path under which the filesystem of the host is
+	// mounted, to read the drop-in files of the units.
+	hostRoot string
+
+	// seen are the units notified during the previous pull, used to detect
+	// the stopped ones.
+	seen map[workloadmeta.EntityID]struct{}
+}
+
+// NewCollector returns a new systemd collector provider and an error
+func NewCollector() (workloadmeta.CollectorProvider, error) {
+	return workloadmeta.CollectorProvider{
+		Collector: &collector{
+			id:      collectorID,
+			catalog: workloadmeta.NodeAgent | workloadmeta.ProcessAgent,
+			seen:    make(map[workloadmeta.EntityID]struct{}),
+		},
+	}, nil
+}
+
+// GetFxOptions returns the FX framework options for the collector
+func GetFxOptions() fx.Option {
+	return fx.Provide(NewCollector)
+}
+
+// Start the collector for the provided workloadmeta component
+func (c *collector) Start(_ context.Context, store workloadmeta.Component) error {
+	if !pkgconfigsetup.Datadog().GetBool("systemd_units.enabled") {
+		return dderrors.NewDisabled(componentName, "systemd_units.enabled is false")
+	}
+
+	privateSocket := pkgconfigsetup.Datadog().GetString("systemd_units.private_socket")
+	c.connect = func() (dbusConn, error) {
+		return systemdutil.NewConnection(privateSocket)
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+	c.store = store
+	if env.IsContainerized() {
+		c.hostRoot = "/host"
+	}
+
+	return nil
+}
+
+// Pull lists the services started by systemd, and notifies the store of the
+// ones that were started, updated or stopped since the last pull.
+func (c *collector) Pull(ctx context.Context) error {
+	if c.conn == nil {
+		conn, err := c.connect()
+		if err != nil {
+			return err
+		}
+		c.conn = conn
+	}
+
+	units, err := c.conn.ListUnitsContext(ctx)
+	if err != nil {
+		// the connection may have been closed by systemd, for instance
+		// when it was re-executed, connect again during the next pull
+		c.conn.Close()
+		c.conn = nil
+		return err
+	}
+
+	seen := make(map[workloadmeta.EntityID]struct{}, len(units))
+	events := make([]workloadmeta.CollectorEvent, 0, len(units))
+
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, serviceSuffix) || !isStarted(unit.ActiveState) {
+			continue
+		}
+
+		entity, err := c.buildUnit(ctx, unit.Name)
+		if err != nil {
+			// the unit may have been unloaded since it was listed
+			log.Debugf("Could not get properties of systemd unit %s: %v", unit.Name, err)
+			continue
+		}
+
+		seen[entity.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: entity,
+		})
+	}
+
+	for seenID := range c.seen {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceRuntime,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seen = seen
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) GetID() string {
+	return c.id
+}
+
+func (c *collector) GetTargetCatalog() workloadmeta.AgentType {
+	return c.catalog
+}
+
+// isStarted returns whether the processes of a unit with the given active
+// state are running.
+func isStarted(activeState string) bool {
+	switch activeState {
+	case "active", "reloading", "activating", "deactivating":
+		return true
+	}
+	return false
+}
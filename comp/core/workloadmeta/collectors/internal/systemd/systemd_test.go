@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+type fakeUnit struct {
+	status       dbus.UnitStatus
+	unitProps    map[string]interface{}
+	serviceProps map[string]interface{}
+}
+
+type fakeConn struct {
+	units  []fakeUnit
+	err    error
+	closed bool
+}
+
+func (c *fakeConn) ListUnitsContext(context.Context) ([]dbus.UnitStatus, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	statuses := make([]dbus.UnitStatus, 0, len(c.units))
+	for _, unit := range c.units {
+		statuses = append(statuses, unit.status)
+	}
+	return statuses, nil
+}
+
+func (c *fakeConn) GetUnitPropertiesContext(_ context.Context, name string) (map[string]interface{}, error) {
+	for _, unit := range c.units {
+		if unit.status.Name == name {
+			return unit.unitProps, nil
+		}
+	}
+	return nil, errors.New("unit not found")
+}
+
+func (c *fakeConn) GetUnitTypePropertiesContext(_ context.Context, name string, unitType string) (map[string]interface{}, error) {
+	for _, unit := range c.units {
+		if unit.status.Name == name && unitType == "Service" {
+			return unit.serviceProps, nil
+		}
+	}
+	return nil, errors.New("unit not found")
+}
+
+func (c *fakeConn) Close() {
+	c.closed = true
+}
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Component
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+func TestPull(t *testing.T) {
+	hostRoot := t.TempDir()
+	dropInDir := filepath.Join(hostRoot, "etc/systemd/system/redis.service.d")
+	require.NoError(t, os.MkdirAll(dropInDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dropInDir, "10-datadog.conf"), []byte(`
+[Service]
+Environment=DD_SERVICE=redis
+
+[X-Datadog]
+com.datadoghq.ad.check_names=["redisdb"]
+com.datadoghq.tags.env=staging
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dropInDir, "20-override.conf"), []byte(`
+[X-Datadog]
+com.datadoghq.tags.env=prod
+`), 0644))
+
+	conn := &fakeConn{
+		units: []fakeUnit{
+			{
+				status: dbus.UnitStatus{Name: "redis.service", LoadState: "loaded", ActiveState: "active"},
+				unitProps: map[string]interface{}{
+					"Description":  "Redis data structure server",
+					"ActiveState":  "active",
+					"SubState":     "running",
+					"FragmentPath": "/lib/systemd/system/redis.service",
+					"DropInPaths": []string{
+						"/etc/systemd/system/redis.service.d/10-datadog.conf",
+						"/etc/systemd/system/redis.service.d/20-override.conf",
+					},
+				},
+				serviceProps: map[string]interface{}{
+					"MainPID":      uint32(1234),
+					"ControlGroup": "/system.slice/redis.service",
+					"Slice":        "system.slice",
+					"User":         "redis",
+					"Environment":  []string{"DD_SERVICE=redis", "PASSWORD=secret"},
+				},
+			},
+			{
+				status: dbus.UnitStatus{Name: "cron.service", LoadState: "loaded", ActiveState: "inactive"},
+			},
+			{
+				status: dbus.UnitStatus{Name: "dbus.socket", LoadState: "loaded", ActiveState: "active"},
+			},
+		},
+	}
+
+	store := &fakeWorkloadmetaStore{}
+	c := &collector{
+		store:    store,
+		conn:     conn,
+		hostRoot: hostRoot,
+		seen:     make(map[workloadmeta.EntityID]struct{}),
+	}
+
+	require.NoError(t, c.Pull(context.Background()))
+
+	redisID := workloadmeta.EntityID{Kind: workloadmeta.KindSystemdUnit, ID: "redis.service"}
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceRuntime,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: redisID,
+				EntityMeta: workloadmeta.EntityMeta{
+					Name: "redis.service",
+					Annotations: map[string]string{
+						"com.datadoghq.ad.check_names": `["redisdb"]`,
+						"com.datadoghq.tags.env":       "prod",
+					},
+				},
+				Description:  "Redis data structure server",
+				ActiveState:  "active",
+				SubState:     "running",
+				Slice:        "system.slice",
+				CgroupPath:   "/system.slice/redis.service",
+				MainPID:      1234,
+				User:         "redis",
+				FragmentPath: "/lib/systemd/system/redis.service",
+				DropInPaths: []string{
+					"/etc/systemd/system/redis.service.d/10-datadog.conf",
+					"/etc/systemd/system/redis.service.d/20-override.conf",
+				},
+				EnvVars: map[string]string{"DD_SERVICE": "redis"},
+			},
+		},
+	}, store.notifiedEvents)
+
+	// the service is stopped
+	conn.units = conn.units[1:]
+	store.notifiedEvents = nil
+
+	require.NoError(t, c.Pull(context.Background()))
+
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceRuntime,
+			Entity: &workloadmeta.SystemdUnit{EntityID: redisID},
+		},
+	}, store.notifiedEvents)
+}
+
+func TestPullReconnects(t *testing.T) {
+	brokenConn := &fakeConn{err: errors.New("connection closed")}
+	newConn := &fakeConn{}
+
+	c := &collector{
+		store: &fakeWorkloadmetaStore{},
+		conn:  brokenConn,
+		connect: func() (dbusConn, error) {
+			return newConn, nil
+		},
+		seen: make(map[workloadmeta.EntityID]struct{}),
+	}
+
+	assert.Error(t, c.Pull(context.Background()))
+	assert.True(t, brokenConn.closed)
+
+	assert.NoError(t, c.Pull(context.Background()))
+	assert.Equal(t, newConn, c.conn)
+}
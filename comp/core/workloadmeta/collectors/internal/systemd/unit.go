@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/unit"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// annotationsSection is the section of the drop-in files holding the
+// annotations of a unit. systemd ignores the sections prefixed with `X-`.
+const annotationsSection = "X-Datadog"
+
+func (c *collector) buildUnit(ctx context.Context, name string) (*workloadmeta.SystemdUnit, error) {
+	unitProps, err := c.conn.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceProps, err := c.conn.GetUnitTypePropertiesContext(ctx, name, "Service")
+	if err != nil {
+		return nil, err
+	}
+
+	dropInPaths := stringSliceProperty(unitProps, "DropInPaths")
+
+	return &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        name,
+			Annotations: c.readAnnotations(dropInPaths),
+		},
+		Description:  stringProperty(unitProps, "Description"),
+		ActiveState:  stringProperty(unitProps, "ActiveState"),
+		SubState:     stringProperty(unitProps, "SubState"),
+		FragmentPath: stringProperty(unitProps, "FragmentPath"),
+		DropInPaths:  dropInPaths,
+		Slice:        stringProperty(serviceProps, "Slice"),
+		CgroupPath:   stringProperty(serviceProps, "ControlGroup"),
+		MainPID:      int(uint32Property(serviceProps, "MainPID")),
+		User:         stringProperty(serviceProps, "User"),
+		EnvVars:      envVars(stringSliceProperty(serviceProps, "Environment")),
+	}, nil
+}
+
+// readAnnotations returns the annotations declared in the drop-in files of a
+// unit. The drop-in files are sorted by precedence, the last ones override
+// the first ones.
+func (c *collector) readAnnotations(dropInPaths []string) map[string]string {
+	var annotations map[string]string
+
+	for _, path := range dropInPaths {
+		f, err := os.Open(filepath.Join(c.hostRoot, path))
+		if err != nil {
+			log.Debugf("Could not open systemd drop-in file %s: %v", path, err)
+			continue
+		}
+
+		options, err := unit.DeserializeOptions(f)
+		f.Close()
+		if err != nil {
+			log.Debugf("Could not parse systemd drop-in file %s: %v", path, err)
+			continue
+		}
+
+		for _, option := range options {
+			if option.Section != annotationsSection {
+				continue
+			}
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[option.Name] = option.Value
+		}
+	}
+
+	return annotations
+}
+
+func envVars(env []string) map[string]string {
+	if len(env) == 0 {
+		return nil
+	}
+
+	res := make(map[string]string)
+
+	filter := containers.EnvVarFilterFromConfig()
+	for _, v := range env {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			continue
+		}
+
+		if filter.IsIncluded(name) {
+			res[name] = value
+		}
+	}
+
+	return res
+}
+
+func stringProperty(properties map[string]interface{}, name string) string {
+	value, _ := properties[name].(string)
+	return value
+}
+
+func stringSliceProperty(properties map[string]interface{}, name string) []string {
+	value, _ := properties[name].([]string)
+	return value
+}
+
+func uint32Property(properties map[string]interface{}, name string) uint32 {
+	value, _ := properties[name].(uint32)
+	return value
+}
@@ -80,6 +80,22 @@ type Component interface {
 	// kind KindECSTask and the given ID.
 	GetECSTask(id string) (*ECSTask, error)
 
+	// ListNomadAllocations returns metadata about all Nomad allocations,
+	// equivalent to all entities with kind KindNomadAllocation.
+	ListNomadAllocations() []*NomadAllocation
+
+	// GetNomadAllocation returns metadata about a Nomad allocation. It fetches
+	// the entity with kind KindNomadAllocation and the given ID.
+	GetNomadAllocation(id string) (*NomadAllocation, error)
+
+	// ListSystemdUnits returns metadata about all systemd units, equivalent
+	// to all entities with kind KindSystemdUnit.
+	ListSystemdUnits() []*SystemdUnit
+
+	// GetSystemdUnit returns metadata about a systemd unit. It fetches the
+	// entity with kind KindSystemdUnit and the given unit name.
+	GetSystemdUnit(name string) (*SystemdUnit, error)
+
 	// ListImages returns metadata about all known images, equivalent to all
 	// entities with kind KindContainerImageMetadata.
 	ListImages() []*ContainerImageMetadata
@@ -45,6 +45,8 @@ const (
 	KindKubernetesMetadata     Kind = "kubernetes_metadata"
 	KindKubernetesDeployment   Kind = "kubernetes_deployment"
 	KindECSTask                Kind = "ecs_task"
+	KindNomadAllocation        Kind = "nomad_allocation"
+	KindSystemdUnit            Kind = "systemd_unit"
 	KindContainerImageMetadata Kind = "container_image_metadata"
 	KindProcess                Kind = "process"
 )
@@ -1030,6 +1032,183 @@ func (t ECSTask) String(verbose bool) string {
 
 var _ Entity = &ECSTask{}
 
+// NomadAllocation is an Entity representing a Nomad allocation, an instance
+// of a task group of a Nomad job placed on the node.
+type NomadAllocation struct {
+	EntityID
+	EntityMeta
+	JobID        string
+	TaskGroup    string
+	Datacenter   string
+	NodeName     string
+	ClientStatus string
+	Tasks        []NomadTask
+	Services     []NomadService
+}
+
+// NomadTask is a task of a Nomad allocation.
+type NomadTask struct {
+	Name   string
+	Driver string
+	State  string
+	// ContainerID is the ID of the container running the task, when the
+	// task driver runs containers (docker, containerd, podman).
+	ContainerID string
+}
+
+// String returns a string representation of NomadTask.
+func (t NomadTask) String(_ bool) string {
+	return fmt.Sprintln("Name:", t.Name, "Driver:", t.Driver, "State:", t.State, "Container ID:", t.ContainerID)
+}
+
+// NomadService is a service registered by a Nomad allocation.
+type NomadService struct {
+	Name string
+	// Task is the name of the task declaring the service, it is empty for
+	// services declared at the task group level.
+	Task      string
+	Provider  string
+	PortLabel string
+	Tags      []string
+	Meta      map[string]string
+}
+
+// String returns a string representation of NomadService.
+func (s NomadService) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Name:", s.Name, "Task:", s.Task, "Port Label:", s.PortLabel)
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Provider:", s.Provider)
+		_, _ = fmt.Fprintln(&sb, "Tags:", sliceToString(s.Tags))
+		_, _ = fmt.Fprintln(&sb, "Meta:", mapToString(s.Meta))
+	}
+	return sb.String()
+}
+
+// GetID implements Entity#GetID.
+func (a NomadAllocation) GetID() EntityID {
+	return a.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (a *NomadAllocation) Merge(e Entity) error {
+	aa, ok := e.(*NomadAllocation)
+	if !ok {
+		return fmt.Errorf("cannot merge NomadAllocation with different kind %T", e)
+	}
+
+	return merge(a, aa)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (a NomadAllocation) DeepCopy() Entity {
+	cp := deepcopy.Copy(a).(NomadAllocation)
+	return &cp
+}
+
+// String implements Entity#String.
+func (a NomadAllocation) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, a.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, a.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Tasks -----------")
+	for _, t := range a.Tasks {
+		_, _ = fmt.Fprint(&sb, t.String(verbose))
+	}
+
+	if len(a.Services) > 0 {
+		_, _ = fmt.Fprintln(&sb, "----------- Services -----------")
+		for _, svc := range a.Services {
+			_, _ = fmt.Fprint(&sb, svc.String(verbose))
+		}
+	}
+
+	_, _ = fmt.Fprintln(&sb, "----------- Allocation Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Job ID:", a.JobID)
+	_, _ = fmt.Fprintln(&sb, "Task Group:", a.TaskGroup)
+	_, _ = fmt.Fprintln(&sb, "Client Status:", a.ClientStatus)
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Datacenter:", a.Datacenter)
+		_, _ = fmt.Fprintln(&sb, "Node Name:", a.NodeName)
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &NomadAllocation{}
+
+// SystemdUnit is an Entity representing a systemd service unit running on
+// the host. Its annotations are read from the `[X-Datadog]` section of the
+// drop-in files of the unit, which systemd ignores.
+type SystemdUnit struct {
+	EntityID
+	EntityMeta
+	Description  string
+	ActiveState  string
+	SubState     string
+	Slice        string
+	CgroupPath   string
+	MainPID      int
+	User         string
+	FragmentPath string
+	DropInPaths  []string
+	// EnvVars are the environment variables of the unit, filtered with
+	// the same rules as the ones of containers.
+	EnvVars map[string]string
+}
+
+// GetID implements Entity#GetID.
+func (u SystemdUnit) GetID() EntityID {
+	return u.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (u *SystemdUnit) Merge(e Entity) error {
+	uu, ok := e.(*SystemdUnit)
+	if !ok {
+		return fmt.Errorf("cannot merge SystemdUnit with different kind %T", e)
+	}
+
+	return merge(u, uu)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (u SystemdUnit) DeepCopy() Entity {
+	cp := deepcopy.Copy(u).(SystemdUnit)
+	return &cp
+}
+
+// String implements Entity#String.
+func (u SystemdUnit) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Unit Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Description:", u.Description)
+	_, _ = fmt.Fprintln(&sb, "State:", u.ActiveState, "("+u.SubState+")")
+	_, _ = fmt.Fprintln(&sb, "Slice:", u.Slice)
+	_, _ = fmt.Fprintln(&sb, "Main PID:", u.MainPID)
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Cgroup Path:", u.CgroupPath)
+		_, _ = fmt.Fprintln(&sb, "User:", u.User)
+		_, _ = fmt.Fprintln(&sb, "Fragment Path:", u.FragmentPath)
+		_, _ = fmt.Fprintln(&sb, "Drop-In Paths:", sliceToString(u.DropInPaths))
+		_, _ = fmt.Fprintln(&sb, "Env Variables:", mapToString(u.EnvVars))
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &SystemdUnit{}
+
 // ContainerImageMetadata is an Entity that represents container image metadata
 type ContainerImageMetadata struct {
 	EntityID
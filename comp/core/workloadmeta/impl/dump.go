@@ -27,6 +27,10 @@ func (w *workloadmeta) Dump(verbose bool) wmdef.WorkloadDumpResponse {
 			info = e.String(verbose)
 		case *wmdef.ECSTask:
 			info = e.String(verbose)
+		case *wmdef.NomadAllocation:
+			info = e.String(verbose)
+		case *wmdef.SystemdUnit:
+			info = e.String(verbose)
 		case *wmdef.ContainerImageMetadata:
 			info = e.String(verbose)
 		case *wmdef.Process:
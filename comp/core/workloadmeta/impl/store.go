@@ -352,6 +352,52 @@ func (w *workloadmeta) GetECSTask(id string) (*wmdef.ECSTask, error) {
 	return entity.(*wmdef.ECSTask), nil
 }
 
+// ListNomadAllocations implements Store#ListNomadAllocations
+func (w *workloadmeta) ListNomadAllocations() []*wmdef.NomadAllocation {
+	entities := w.listEntitiesByKind(wmdef.KindNomadAllocation)
+
+	allocations := make([]*wmdef.NomadAllocation, 0, len(entities))
+	for _, entity := range entities {
+		allocation := entity.(*wmdef.NomadAllocation)
+		allocations = append(allocations, allocation)
+	}
+
+	return allocations
+}
+
+// GetNomadAllocation implements Store#GetNomadAllocation
+func (w *workloadmeta) GetNomadAllocation(id string) (*wmdef.NomadAllocation, error) {
+	entity, err := w.getEntityByKind(wmdef.KindNomadAllocation, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*wmdef.NomadAllocation), nil
+}
+
+// ListSystemdUnits implements Store#ListSystemdUnits
+func (w *workloadmeta) ListSystemdUnits() []*wmdef.SystemdUnit {
+	entities := w.listEntitiesByKind(wmdef.KindSystemdUnit)
+
+	units := make([]*wmdef.SystemdUnit, 0, len(entities))
+	for _, entity := range entities {
+		unit := entity.(*wmdef.SystemdUnit)
+		units = append(units, unit)
+	}
+
+	return units
+}
+
+// GetSystemdUnit implements Store#GetSystemdUnit
+func (w *workloadmeta) GetSystemdUnit(name string) (*wmdef.SystemdUnit, error) {
+	entity, err := w.getEntityByKind(wmdef.KindSystemdUnit, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*wmdef.SystemdUnit), nil
+}
+
 // ListImages implements Store#ListImages
 func (w *workloadmeta) ListImages() []*wmdef.ContainerImageMetadata {
 	entities := w.listEntitiesByKind(wmdef.KindContainerImageMetadata)
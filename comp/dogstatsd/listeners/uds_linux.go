@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/containers/metrics/provider"
 	"github.com/DataDog/datadog-agent/pkg/util/optional"
+	"github.com/DataDog/datadog-agent/pkg/util/systemd"
 )
 
 const (
@@ -121,8 +122,9 @@ func getEntityForPID(pid int32, capture bool, wmeta optional.Option[workloadmeta
 	}
 }
 
-// entityForPID returns the entity ID for a given PID. It can return
-// errNoContainerMatch if no match is found for the PID.
+// entityForPID returns the entity ID for a given PID: its container, or the
+// systemd service it belongs to when it doesn't run in a container. It can
+// return errNoContainerMatch if no match is found for the PID.
 func entityForPID(pid int32, capture bool, wmeta optional.Option[workloadmeta.Component], state pidmap.Component) (string, error) {
 	if capture {
 		return state.ContainerIDForPID(pid)
@@ -133,8 +135,31 @@ func entityForPID(pid int32, capture bool, wmeta optional.Option[workloadmeta.Co
 		return "", err
 	}
 	if cID == "" {
+		if unit := systemdUnitForPID(pid, wmeta); unit != "" {
+			return types.NewEntityID(types.SystemdUnit, unit).String(), nil
+		}
 		return "", errNoContainerMatch
 	}
 
 	return types.NewEntityID(types.ContainerID, cID).String(), nil
 }
+
+// systemdUnitForPID returns the name of the systemd service running the given
+// PID, if that service is known to workloadmeta.
+func systemdUnitForPID(pid int32, wmeta optional.Option[workloadmeta.Component]) string {
+	store, ok := wmeta.Get()
+	if !ok {
+		return ""
+	}
+
+	unit, err := systemd.ServiceUnitForPID(int(pid))
+	if err != nil || unit == "" {
+		return ""
+	}
+
+	if _, err := store.GetSystemdUnit(unit); err != nil {
+		return ""
+	}
+
+	return unit
+}
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package common
+
+// ExporterRecord contains data reported by an exporter about itself rather than about flows,
+// collected from sFlow counter samples and IPFIX/NetFlow v9 options data
+type ExporterRecord struct {
+	Namespace    string
+	FlowType     FlowType
+	ExporterAddr []byte
+
+	// SamplingRate reported in options data, 0 if not reported
+	SamplingRate uint64
+
+	Interfaces []ExporterInterface
+}
+
+// ExporterInterface contains interface details reported by an exporter
+type ExporterInterface struct {
+	Index       uint32
+	Name        string
+	Description string
+
+	// Counters are only reported by sFlow counter samples
+	Counters *InterfaceCounters
+}
+
+// InterfaceCounters contains interface counters reported by an exporter
+type InterfaceCounters struct {
+	Speed       uint64 // in bits per second
+	AdminStatus uint32 // IF-MIB ifAdminStatus values (1 up, 2 down)
+	OperStatus  uint32 // IF-MIB ifOperStatus values (1 up, 2 down)
+
+	// Values maps counter names (e.g. `in_octets`) to their value,
+	// counters unknown to the exporter are omitted
+	Values map[string]uint64
+}
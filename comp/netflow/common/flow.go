@@ -55,6 +55,10 @@ type Flow struct {
 	InputInterface  uint32 // FLOW KEY
 	OutputInterface uint32
 
+	// Interface names reported by the exporter in options data, added during Flow aggregation processing
+	InputInterfaceName  string
+	OutputInterfaceName string
+
 	// Mac Address
 	SrcMac uint64
 	DstMac uint64
@@ -67,6 +71,10 @@ type Flow struct {
 	SrcReverseDNSHostname string
 	DstReverseDNSHostname string
 
+	// Local ASN/GeoIP/CIDR enrichment added during Flow aggregation processing
+	SrcEnrichment *EndpointEnrichment
+	DstEnrichment *EndpointEnrichment
+
 	// Ethernet information
 	Tos uint32 // FLOW KEY
 
@@ -79,6 +87,16 @@ type Flow struct {
 // AdditionalFields holds additional fields collected
 type AdditionalFields = map[string]any
 
+// EndpointEnrichment contains the information found about
+// a source or destination IP in local databases
+type EndpointEnrichment struct {
+	ASNumber       uint32
+	ASOrganization string
+	CountryISOCode string
+	City           string
+	Labels         []string
+}
+
 // FlowMessageWithAdditionalFields contains a goflow flowmessage and additional fields
 type FlowMessageWithAdditionalFields struct {
 	*flowmessage.FlowMessage
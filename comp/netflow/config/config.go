@@ -8,6 +8,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
@@ -36,6 +37,21 @@ type NetflowConfig struct {
 	PrometheusListenerEnabled bool   `mapstructure:"prometheus_listener_enabled"`
 
 	ReverseDNSEnrichmentEnabled bool `mapstructure:"reverse_dns_enrichment_enabled"`
+
+	Enrichment EnrichmentConfig `mapstructure:"enrichment"`
+}
+
+// EnrichmentConfig contains configuration for the enrichment of flows from local databases
+type EnrichmentConfig struct {
+	ASNDatabasePath   string      `mapstructure:"asn_database_path"`
+	GeoIPDatabasePath string      `mapstructure:"geoip_database_path"`
+	CIDRLabels        []CIDRLabel `mapstructure:"cidr_labels"`
+}
+
+// CIDRLabel contains configuration for a user-defined label added to IPs within a CIDR
+type CIDRLabel struct {
+	CIDR  string `mapstructure:"cidr"`
+	Label string `mapstructure:"label"`
 }
 
 // ListenerConfig contains configuration for a single flow listener
@@ -138,6 +154,15 @@ func (mainConfig *NetflowConfig) SetDefaults(namespace string, logger log.Compon
 		mainConfig.PrometheusListenerAddress = common.DefaultPrometheusListenerAddress
 	}
 
+	for _, cidrLabel := range mainConfig.Enrichment.CIDRLabels {
+		if _, err := netip.ParsePrefix(cidrLabel.CIDR); err != nil {
+			return fmt.Errorf("invalid enrichment CIDR `%s`: %s", cidrLabel.CIDR, err)
+		}
+		if cidrLabel.Label == "" {
+			return fmt.Errorf("missing label for enrichment CIDR `%s`", cidrLabel.CIDR)
+		}
+	}
+
 	return nil
 }
 
@@ -148,6 +148,76 @@ network_devices:
 				ReverseDNSEnrichmentEnabled: false,
 			},
 		},
+		{
+			name: "enrichment",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: netflow9
+    enrichment:
+      asn_database_path: /opt/geoip/GeoLite2-ASN.mmdb
+      geoip_database_path: /opt/geoip/GeoLite2-City.mmdb
+      cidr_labels:
+        - cidr: 10.0.0.0/8
+          label: internal
+        - cidr: 2001:db8::/32
+          label: lab
+`,
+			expectedConfig: NetflowConfig{
+				Enabled:                                true,
+				StopTimeout:                            5,
+				AggregatorBufferSize:                   10000,
+				AggregatorFlushInterval:                300,
+				AggregatorFlowContextTTL:               300,
+				AggregatorPortRollupThreshold:          10,
+				AggregatorRollupTrackerRefreshInterval: 300,
+				PrometheusListenerAddress:              "localhost:9090",
+				Listeners: []ListenerConfig{
+					{
+						FlowType:  common.TypeNetFlow9,
+						BindHost:  "0.0.0.0",
+						Port:      uint16(2055),
+						Workers:   1,
+						Namespace: "default",
+					},
+				},
+				Enrichment: EnrichmentConfig{
+					ASNDatabasePath:   "/opt/geoip/GeoLite2-ASN.mmdb",
+					GeoIPDatabasePath: "/opt/geoip/GeoLite2-City.mmdb",
+					CIDRLabels: []CIDRLabel{
+						{CIDR: "10.0.0.0/8", Label: "internal"},
+						{CIDR: "2001:db8::/32", Label: "lab"},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid enrichment CIDR",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    enrichment:
+      cidr_labels:
+        - cidr: 10.0.0.0/33
+          label: internal
+`,
+			expectedError: "invalid enrichment CIDR `10.0.0.0/33`",
+		},
+		{
+			name: "missing enrichment CIDR label",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    enrichment:
+      cidr_labels:
+        - cidr: 10.0.0.0/8
+`,
+			expectedError: "missing label for enrichment CIDR `10.0.0.0/8`",
+		},
 		{
 			name: "invalid flow type",
 			configYaml: `
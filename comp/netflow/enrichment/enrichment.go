@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package enrichment provides a type for enriching flow endpoints with
+// information found in local databases: ASN and GeoIP MaxMind databases
+// (MMDB format) and user-defined CIDR labels.
+// No network access is needed, lookups only use local files.
+package enrichment
+
+import (
+	"net"
+	"net/netip"
+	"sort"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+)
+
+// asnRecord contains the fields read from GeoLite2-ASN compatible databases
+type asnRecord struct {
+	AutonomousSystemNumber       uint32 `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// geoIPRecord contains the fields read from GeoLite2-Country/City compatible databases
+type geoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+type cidrLabel struct {
+	prefix netip.Prefix
+	label  string
+}
+
+// Enricher looks up flow endpoints IPs in local databases
+type Enricher struct {
+	asnDB      *maxminddb.Reader
+	geoIPDB    *maxminddb.Reader
+	cidrLabels []cidrLabel
+	logger     log.Component
+}
+
+// NewEnricher returns a new Enricher, or nil if no enrichment source is configured.
+// Databases that cannot be opened are logged and skipped.
+func NewEnricher(conf config.EnrichmentConfig, logger log.Component) *Enricher {
+	enricher := &Enricher{
+		logger: logger,
+	}
+	if conf.ASNDatabasePath != "" {
+		enricher.asnDB = openDatabase(conf.ASNDatabasePath, logger)
+	}
+	if conf.GeoIPDatabasePath != "" {
+		enricher.geoIPDB = openDatabase(conf.GeoIPDatabasePath, logger)
+	}
+	for _, cl := range conf.CIDRLabels {
+		prefix, err := netip.ParsePrefix(cl.CIDR)
+		if err != nil {
+			logger.Warnf("ignoring invalid enrichment CIDR `%s`: %s", cl.CIDR, err)
+			continue
+		}
+		enricher.cidrLabels = append(enricher.cidrLabels, cidrLabel{prefix: prefix.Masked(), label: cl.Label})
+	}
+	// most specific CIDRs first, stable to keep configuration order for equal prefix lengths
+	sort.SliceStable(enricher.cidrLabels, func(i, j int) bool {
+		return enricher.cidrLabels[i].prefix.Bits() > enricher.cidrLabels[j].prefix.Bits()
+	})
+
+	if enricher.asnDB == nil && enricher.geoIPDB == nil && len(enricher.cidrLabels) == 0 {
+		return nil
+	}
+	return enricher
+}
+
+func openDatabase(path string, logger log.Component) *maxminddb.Reader {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		logger.Errorf("failed to open enrichment database `%s`: %s", path, err)
+		return nil
+	}
+	logger.Infof("Loaded enrichment database `%s` (type=%s, build=%d)", path, db.Metadata.DatabaseType, db.Metadata.BuildEpoch)
+	return db
+}
+
+// Lookup returns the enrichment found for an IP address, or nil if nothing is found.
+// It is safe to call Lookup on a nil Enricher.
+func (e *Enricher) Lookup(ipAddr []byte) *common.EndpointEnrichment {
+	if e == nil {
+		return nil
+	}
+	addr, ok := netip.AddrFromSlice(ipAddr)
+	if !ok {
+		return nil
+	}
+	addr = addr.Unmap()
+
+	var enrichment common.EndpointEnrichment
+	found := false
+
+	if e.asnDB != nil {
+		var record asnRecord
+		if err := e.asnDB.Lookup(net.IP(addr.AsSlice()), &record); err != nil {
+			e.logger.Debugf("failed to lookup ASN for %s: %s", addr, err)
+		} else if record.AutonomousSystemNumber != 0 {
+			enrichment.ASNumber = record.AutonomousSystemNumber
+			enrichment.ASOrganization = record.AutonomousSystemOrganization
+			found = true
+		}
+	}
+
+	if e.geoIPDB != nil {
+		var record geoIPRecord
+		if err := e.geoIPDB.Lookup(net.IP(addr.AsSlice()), &record); err != nil {
+			e.logger.Debugf("failed to lookup GeoIP for %s: %s", addr, err)
+		} else if record.Country.ISOCode != "" {
+			enrichment.CountryISOCode = record.Country.ISOCode
+			enrichment.City = record.City.Names["en"]
+			found = true
+		}
+	}
+
+	for _, cl := range e.cidrLabels {
+		if cl.prefix.Contains(addr) {
+			enrichment.Labels = append(enrichment.Labels, cl.label)
+			found = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return &enrichment
+}
+
+// Close releases the databases
+func (e *Enricher) Close() {
+	if e == nil {
+		return
+	}
+	for _, db := range []*maxminddb.Reader{e.asnDB, e.geoIPDB} {
+		if db == nil {
+			continue
+		}
+		if err := db.Close(); err != nil {
+			e.logger.Warnf("failed to close enrichment database: %s", err)
+		}
+	}
+}
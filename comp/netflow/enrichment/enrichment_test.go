@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+)
+
+func TestNewEnricher_NotConfigured(t *testing.T) {
+	logger := logmock.New(t)
+	enricher := NewEnricher(config.EnrichmentConfig{}, logger)
+	assert.Nil(t, enricher)
+
+	// nil enricher is safe to use
+	assert.Nil(t, enricher.Lookup([]byte{1, 1, 1, 1}))
+	enricher.Close()
+}
+
+func TestNewEnricher_MissingDatabase(t *testing.T) {
+	logger := logmock.New(t)
+	enricher := NewEnricher(config.EnrichmentConfig{
+		ASNDatabasePath: "testdata/does-not-exist.mmdb",
+	}, logger)
+	assert.Nil(t, enricher)
+}
+
+func TestEnricher_Lookup(t *testing.T) {
+	logger := logmock.New(t)
+	enricher := NewEnricher(config.EnrichmentConfig{
+		ASNDatabasePath:   "testdata/asn.mmdb",
+		GeoIPDatabasePath: "testdata/city.mmdb",
+		CIDRLabels: []config.CIDRLabel{
+			{CIDR: "10.0.0.0/8", Label: "internal"},
+			{CIDR: "10.1.0.0/16", Label: "datacenter-1"},
+			{CIDR: "8.8.8.8/32", Label: "google-dns"},
+			{CIDR: "fd00::/8", Label: "internal-v6"},
+		},
+	}, logger)
+	require.NotNil(t, enricher)
+	defer enricher.Close()
+
+	tests := []struct {
+		name     string
+		ip       []byte
+		expected *common.EndpointEnrichment
+	}{
+		{
+			name: "ASN and city",
+			ip:   net.ParseIP("1.1.1.1").To4(),
+			expected: &common.EndpointEnrichment{
+				ASNumber:       13335,
+				ASOrganization: "CLOUDFLARENET",
+				CountryISOCode: "AU",
+				City:           "Sydney",
+			},
+		},
+		{
+			name: "ASN, country without city and label",
+			ip:   net.ParseIP("8.8.8.8").To4(),
+			expected: &common.EndpointEnrichment{
+				ASNumber:       15169,
+				ASOrganization: "GOOGLE",
+				CountryISOCode: "US",
+				Labels:         []string{"google-dns"},
+			},
+		},
+		{
+			name: "IPv4-mapped IPv6",
+			ip:   net.ParseIP("1.1.1.1").To16(),
+			expected: &common.EndpointEnrichment{
+				ASNumber:       13335,
+				ASOrganization: "CLOUDFLARENET",
+				CountryISOCode: "AU",
+				City:           "Sydney",
+			},
+		},
+		{
+			name: "IPv6 ASN only",
+			ip:   net.ParseIP("2606:4700::1111"),
+			expected: &common.EndpointEnrichment{
+				ASNumber:       13335,
+				ASOrganization: "CLOUDFLARENET",
+			},
+		},
+		{
+			name: "IPv6 city only",
+			ip:   net.ParseIP("2a01:e0a::1"),
+			expected: &common.EndpointEnrichment{
+				CountryISOCode: "FR",
+				City:           "Paris",
+			},
+		},
+		{
+			name: "labels most specific first",
+			ip:   net.ParseIP("10.1.2.3").To4(),
+			expected: &common.EndpointEnrichment{
+				Labels: []string{"datacenter-1", "internal"},
+			},
+		},
+		{
+			name: "IPv6 label",
+			ip:   net.ParseIP("fd00::1"),
+			expected: &common.EndpointEnrichment{
+				Labels: []string{"internal-v6"},
+			},
+		},
+		{
+			name:     "not found",
+			ip:       net.ParseIP("192.168.1.1").To4(),
+			expected: nil,
+		},
+		{
+			name:     "invalid IP",
+			ip:       []byte{1, 2, 3},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, enricher.Lookup(tt.ip))
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/netflow/common"
 	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib"
 )
 
@@ -37,9 +38,12 @@ const metricPrefix = "datadog.netflow."
 // FlowAggregator is used for space and time aggregation of NetFlow flows
 type FlowAggregator struct {
 	flowIn                       chan *common.Flow
+	recordIn                     chan *common.ExporterRecord
 	FlushFlowsToSendInterval     time.Duration // interval for checking flows to flush and send them to EP Forwarder
 	rollupTrackerRefreshInterval time.Duration
 	flowAcc                      *flowAccumulator
+	enricher                     *enrichment.Enricher
+	exporterStore                *exporterStore
 	sender                       sender.Sender
 	epForwarder                  eventplatform.Forwarder
 	stopChan                     chan struct{}
@@ -82,9 +86,13 @@ func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder
 	flushInterval := time.Duration(config.AggregatorFlushInterval) * time.Second
 	flowContextTTL := time.Duration(config.AggregatorFlowContextTTL) * time.Second
 	rollupTrackerRefreshInterval := time.Duration(config.AggregatorRollupTrackerRefreshInterval) * time.Second
+	enricher := enrichment.NewEnricher(config.Enrichment, logger)
 	return &FlowAggregator{
 		flowIn:                       make(chan *common.Flow, config.AggregatorBufferSize),
-		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, logger, rdnsQuerier),
+		recordIn:                     make(chan *common.ExporterRecord, config.AggregatorBufferSize),
+		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, logger, rdnsQuerier, enricher),
+		enricher:                     enricher,
+		exporterStore:                newExporterStore(),
 		FlushFlowsToSendInterval:     flushFlowsToSendInterval,
 		rollupTrackerRefreshInterval: rollupTrackerRefreshInterval,
 		sender:                       sender,
@@ -114,6 +122,7 @@ func (agg *FlowAggregator) Stop() {
 	close(agg.stopChan)
 	<-agg.flushLoopDone
 	<-agg.runDone
+	agg.enricher.Close()
 }
 
 // GetFlowInChan returns flow input chan
@@ -121,6 +130,11 @@ func (agg *FlowAggregator) GetFlowInChan() chan *common.Flow {
 	return agg.flowIn
 }
 
+// GetExporterRecordInChan returns exporter record input chan
+func (agg *FlowAggregator) GetExporterRecordInChan() chan *common.ExporterRecord {
+	return agg.recordIn
+}
+
 func (agg *FlowAggregator) run() {
 	for {
 		select {
@@ -131,12 +145,16 @@ func (agg *FlowAggregator) run() {
 		case flow := <-agg.flowIn:
 			agg.receivedFlowCount.Inc()
 			agg.flowAcc.add(flow)
+		case record := <-agg.recordIn:
+			agg.exporterStore.add(record, agg.TimeNowFunction())
 		}
 	}
 }
 
 func (agg *FlowAggregator) sendFlows(flows []*common.Flow, flushTime time.Time) {
 	for _, flow := range flows {
+		flow.InputInterfaceName = agg.exporterStore.getInterfaceName(flow, flow.InputInterface)
+		flow.OutputInterfaceName = agg.exporterStore.getInterfaceName(flow, flow.OutputInterface)
 		flowPayload := buildPayload(flow, agg.hostname, flushTime)
 
 		// Calling MarshalJSON directly as it's faster than calling json.Marshall
@@ -208,6 +226,25 @@ func (agg *FlowAggregator) sendExporterMetadata(flows []*common.Flow, flushTime
 	}
 }
 
+func (agg *FlowAggregator) sendExporterInterfaceMetadata(flushTime time.Time) {
+	for namespace, interfaces := range agg.exporterStore.getInterfaceMetadata() {
+		metadataPayloads := metadata.BatchPayloads(namespace, "", flushTime, metadata.PayloadMetadataBatchSize, nil, interfaces, nil, nil, nil, nil)
+		for _, payload := range metadataPayloads {
+			payloadBytes, err := json.Marshal(payload)
+			if err != nil {
+				agg.logger.Errorf("Error marshalling device metadata: %s", err)
+				continue
+			}
+			agg.logger.Debugf("netflow exporter interface metadata payload: %s", string(payloadBytes))
+			m := message.NewMessage(payloadBytes, nil, "", 0)
+			err = agg.epForwarder.SendEventPlatformEventBlocking(m, eventplatform.EventTypeNetworkDevicesMetadata)
+			if err != nil {
+				agg.logger.Errorf("Error sending event platform event for netflow exporter interface metadata: %s", err)
+			}
+		}
+	}
+}
+
 func (agg *FlowAggregator) flushLoop() {
 	var flushFlowsToSendTicker <-chan time.Time
 
@@ -274,6 +311,10 @@ func (agg *FlowAggregator) flush() int {
 	}
 	agg.sendExporterMetadata(flowsToFlush, flushTime)
 
+	agg.exporterStore.removeExpired(flushTime)
+	agg.exporterStore.submitMetrics(agg.sender)
+	agg.sendExporterInterfaceMetadata(flushTime)
+
 	flushCount := len(flowsToFlush)
 
 	agg.sender.MonotonicCount("datadog.netflow.aggregator.hash_collisions", float64(agg.flowAcc.hashCollisionFlowCount.Load()), "", nil)
@@ -13,8 +13,27 @@ import (
 	"github.com/DataDog/datadog-agent/comp/netflow/payload"
 )
 
+func buildEndpointEnrichment(endpoint *payload.Endpoint, enrichment *common.EndpointEnrichment) {
+	if enrichment == nil {
+		return
+	}
+	if enrichment.ASNumber != 0 {
+		endpoint.AS = &payload.AS{
+			Number:       enrichment.ASNumber,
+			Organization: enrichment.ASOrganization,
+		}
+	}
+	if enrichment.CountryISOCode != "" {
+		endpoint.Geo = &payload.Geo{
+			CountryISOCode: enrichment.CountryISOCode,
+			City:           enrichment.City,
+		}
+	}
+	endpoint.Labels = enrichment.Labels
+}
+
 func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) payload.FlowPayload {
-	return payload.FlowPayload{
+	flowPayload := payload.FlowPayload{
 		// TODO: Implement Tos
 		FlushTimestamp: flushTime.UnixMilli(),
 		FlowType:       string(aggFlow.FlowType),
@@ -49,11 +68,13 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 		Ingress: payload.ObservationPoint{
 			Interface: payload.Interface{
 				Index: aggFlow.InputInterface,
+				Name:  aggFlow.InputInterfaceName,
 			},
 		},
 		Egress: payload.ObservationPoint{
 			Interface: payload.Interface{
 				Index: aggFlow.OutputInterface,
+				Name:  aggFlow.OutputInterfaceName,
 			},
 		},
 		Host:     hostname,
@@ -63,4 +84,7 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 		},
 		AdditionalFields: aggFlow.AdditionalFields,
 	}
+	buildEndpointEnrichment(&flowPayload.Source, aggFlow.SrcEnrichment)
+	buildEndpointEnrichment(&flowPayload.Destination, aggFlow.DstEnrichment)
+	return flowPayload
 }
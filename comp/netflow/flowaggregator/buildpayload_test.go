@@ -289,3 +289,54 @@ func Test_buildPayload(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildPayload_enrichment(t *testing.T) {
+	curTime := time.Now()
+	flow := common.Flow{
+		FlowType:     common.TypeNetFlow9,
+		ExporterAddr: []byte{127, 0, 0, 1},
+		SrcAddr:      []byte{10, 1, 2, 3},
+		DstAddr:      []byte{8, 8, 8, 8},
+		SrcEnrichment: &common.EndpointEnrichment{
+			Labels: []string{"datacenter-1", "internal"},
+		},
+		DstEnrichment: &common.EndpointEnrichment{
+			ASNumber:       15169,
+			ASOrganization: "GOOGLE",
+			CountryISOCode: "US",
+		},
+	}
+
+	flowPayload := buildPayload(&flow, "my-hostname", curTime)
+
+	assert.Nil(t, flowPayload.Source.AS)
+	assert.Nil(t, flowPayload.Source.Geo)
+	assert.Equal(t, []string{"datacenter-1", "internal"}, flowPayload.Source.Labels)
+	assert.Equal(t, &payload.AS{Number: 15169, Organization: "GOOGLE"}, flowPayload.Destination.AS)
+	assert.Equal(t, &payload.Geo{CountryISOCode: "US"}, flowPayload.Destination.Geo)
+	assert.Nil(t, flowPayload.Destination.Labels)
+
+	destinationJSON, err := json.Marshal(flowPayload.Destination)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"ip\":\"8.8.8.8\",\"port\":\"0\",\"mac\":\"00:00:00:00:00:00\",\"mask\":\"0.0.0.0/0\",\"as\":{\"number\":15169,\"organization\":\"GOOGLE\"},\"geo\":{\"country_iso_code\":\"US\"}}", string(destinationJSON))
+}
+
+func Test_buildPayload_interfaceNames(t *testing.T) {
+	flow := common.Flow{
+		FlowType:            common.TypeIPFIX,
+		ExporterAddr:        []byte{127, 0, 0, 1},
+		InputInterface:      1,
+		InputInterfaceName:  "eth1",
+		OutputInterface:     2,
+		OutputInterfaceName: "",
+	}
+
+	flowPayload := buildPayload(&flow, "my-hostname", time.Now())
+
+	assert.Equal(t, payload.Interface{Index: 1, Name: "eth1"}, flowPayload.Ingress.Interface)
+	assert.Equal(t, payload.Interface{Index: 2}, flowPayload.Egress.Interface)
+
+	ingressJSON, err := json.Marshal(flowPayload.Ingress)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"interface\":{\"index\":1,\"name\":\"eth1\"}}", string(ingressJSON))
+}
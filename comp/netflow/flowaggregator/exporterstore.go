@@ -0,0 +1,246 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+const exporterMetricPrefix = "netflow.exporter."
+
+// interfaceMetricPrefix is the prefix of the interface metrics submitted by the SNMP integration, interface counters
+// reported by exporters are submitted under the same names so that they show up in NDM
+const interfaceMetricPrefix = "snmp."
+
+// interfaceCounterMetrics maps the names of the interface counters reported by exporters to the IF-MIB objects
+// used as metric names by the SNMP integration
+var interfaceCounterMetrics = map[string]string{
+	"in_octets":             "ifHCInOctets",
+	"out_octets":            "ifHCOutOctets",
+	"in_unicast_packets":    "ifHCInUcastPkts",
+	"in_multicast_packets":  "ifHCInMulticastPkts",
+	"in_broadcast_packets":  "ifHCInBroadcastPkts",
+	"in_discards":           "ifInDiscards",
+	"in_errors":             "ifInErrors",
+	"in_unknown_protocols":  "ifInUnknownProtos",
+	"out_unicast_packets":   "ifHCOutUcastPkts",
+	"out_multicast_packets": "ifHCOutMulticastPkts",
+	"out_broadcast_packets": "ifHCOutBroadcastPkts",
+	"out_discards":          "ifOutDiscards",
+	"out_errors":            "ifOutErrors",
+}
+
+// exporterDataTTL is the duration after which data not reported again by an exporter is removed
+const exporterDataTTL = 1 * time.Hour
+
+type exporterKey struct {
+	Namespace  string
+	ExporterIP string
+	FlowType   common.FlowType
+}
+
+type exporterInterface struct {
+	name        string
+	description string
+	counters    *common.InterfaceCounters
+	lastUpdated time.Time
+}
+
+type exporterData struct {
+	samplingRate            uint64
+	samplingRateLastUpdated time.Time
+	interfaces              map[uint32]*exporterInterface
+}
+
+// exporterStore keeps the latest data reported by exporters (sFlow counter samples
+// and IPFIX/NetFlow v9 options data) to submit it as metrics and metadata, and to enrich flows
+type exporterStore struct {
+	exporters map[exporterKey]*exporterData
+	// mutex is needed since `exporterStore.add()` and `exporterStore.flush()` are called by different routines
+	mu sync.RWMutex
+}
+
+func newExporterStore() *exporterStore {
+	return &exporterStore{
+		exporters: make(map[exporterKey]*exporterData),
+	}
+}
+
+func newExporterKey(namespace string, exporterAddr []byte, flowType common.FlowType) exporterKey {
+	return exporterKey{
+		Namespace:  namespace,
+		ExporterIP: net.IP(exporterAddr).String(),
+		FlowType:   flowType,
+	}
+}
+
+func (s *exporterStore) add(record *common.ExporterRecord, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := newExporterKey(record.Namespace, record.ExporterAddr, record.FlowType)
+	exporter, ok := s.exporters[key]
+	if !ok {
+		exporter = &exporterData{
+			interfaces: make(map[uint32]*exporterInterface),
+		}
+		s.exporters[key] = exporter
+	}
+	if record.SamplingRate > 0 {
+		exporter.samplingRate = record.SamplingRate
+		exporter.samplingRateLastUpdated = now
+	}
+	for _, recordInterface := range record.Interfaces {
+		storedInterface, ok := exporter.interfaces[recordInterface.Index]
+		if !ok {
+			storedInterface = &exporterInterface{}
+			exporter.interfaces[recordInterface.Index] = storedInterface
+		}
+		if recordInterface.Name != "" {
+			storedInterface.name = recordInterface.Name
+		}
+		if recordInterface.Description != "" {
+			storedInterface.description = recordInterface.Description
+		}
+		if recordInterface.Counters != nil {
+			storedInterface.counters = recordInterface.Counters
+		}
+		storedInterface.lastUpdated = now
+	}
+}
+
+// getInterfaceName returns the interface name reported by the exporter of a flow, or an empty string if unknown
+func (s *exporterStore) getInterfaceName(flow *common.Flow, index uint32) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exporter, ok := s.exporters[newExporterKey(flow.Namespace, flow.ExporterAddr, flow.FlowType)]
+	if !ok {
+		return ""
+	}
+	if storedInterface, ok := exporter.interfaces[index]; ok {
+		return storedInterface.name
+	}
+	return ""
+}
+
+// removeExpired removes data not reported again by exporters since exporterDataTTL
+func (s *exporterStore) removeExpired(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, exporter := range s.exporters {
+		if exporter.samplingRateLastUpdated.Add(exporterDataTTL).Before(now) {
+			exporter.samplingRate = 0
+		}
+		for index, storedInterface := range exporter.interfaces {
+			if storedInterface.lastUpdated.Add(exporterDataTTL).Before(now) {
+				delete(exporter.interfaces, index)
+			}
+		}
+		if exporter.samplingRate == 0 && len(exporter.interfaces) == 0 {
+			delete(s.exporters, key)
+		}
+	}
+}
+
+// submitMetrics submits sampling rates and interface counters reported by exporters. Interface counters are
+// submitted like the SNMP integration does: as monotonic counts and rates, tagged with the NDM device tags.
+func (s *exporterStore) submitMetrics(sender sender.Sender) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for key, exporter := range s.exporters {
+		if exporter.samplingRate > 0 {
+			exporterTags := []string{"device_namespace:" + key.Namespace, "exporter_ip:" + key.ExporterIP, "flow_type:" + string(key.FlowType)}
+			sender.Gauge(exporterMetricPrefix+"sampling_rate", float64(exporter.samplingRate), "", exporterTags)
+		}
+		deviceTags := []string{
+			"device_namespace:" + key.Namespace,
+			"snmp_device:" + key.ExporterIP,
+			"device_ip:" + key.ExporterIP,
+			"device_id:" + key.Namespace + ":" + key.ExporterIP,
+			"flow_type:" + string(key.FlowType),
+		}
+		for index, storedInterface := range exporter.interfaces {
+			counters := storedInterface.counters
+			if counters == nil {
+				continue
+			}
+			tags := append(copyTags(deviceTags), "interface_index:"+strconv.Itoa(int(index)))
+			if storedInterface.name != "" {
+				tags = append(tags, "interface:"+storedInterface.name)
+			}
+			if counters.Speed > 0 {
+				// ifHighSpeed is in Mbps
+				sender.Gauge(interfaceMetricPrefix+"ifHighSpeed", float64(counters.Speed)/1e6, "", tags)
+			}
+			sender.Gauge(interfaceMetricPrefix+"ifAdminStatus", float64(counters.AdminStatus), "", tags)
+			sender.Gauge(interfaceMetricPrefix+"ifOperStatus", float64(counters.OperStatus), "", tags)
+			for name, value := range counters.Values {
+				metricName, ok := interfaceCounterMetrics[name]
+				if !ok {
+					continue
+				}
+				sender.MonotonicCount(interfaceMetricPrefix+metricName, float64(value), "", tags)
+				sender.Rate(interfaceMetricPrefix+metricName+".rate", float64(value), "", tags)
+			}
+		}
+	}
+}
+
+// getInterfaceMetadata returns the metadata of interfaces reported by exporters, per namespace. The interfaces carry
+// the flow type as raw ID type so that they don't overwrite the interfaces of the same device collected by the SNMP
+// integration, which leaves it empty.
+func (s *exporterStore) getInterfaceMetadata() map[string][]metadata.InterfaceMetadata {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	interfacesPerNamespace := make(map[string][]metadata.InterfaceMetadata)
+	for key, exporter := range s.exporters {
+		deviceID := key.Namespace + ":" + key.ExporterIP
+		for index, storedInterface := range exporter.interfaces {
+			interfaceMetadata := metadata.InterfaceMetadata{
+				DeviceID:    deviceID,
+				Index:       int32(index),
+				RawID:       strconv.Itoa(int(index)),
+				RawIDType:   string(key.FlowType),
+				Name:        storedInterface.name,
+				Description: storedInterface.description,
+			}
+			if storedInterface.name != "" {
+				interfaceMetadata.IDTags = []string{"interface:" + storedInterface.name}
+			}
+			if storedInterface.counters != nil {
+				interfaceMetadata.AdminStatus = metadata.IfAdminStatus(storedInterface.counters.AdminStatus)
+				interfaceMetadata.OperStatus = metadata.IfOperStatus(storedInterface.counters.OperStatus)
+			}
+			interfacesPerNamespace[key.Namespace] = append(interfacesPerNamespace[key.Namespace], interfaceMetadata)
+		}
+	}
+	// sort interfaces to build predictable metadata payloads (consistent batches and orders)
+	for _, interfaces := range interfacesPerNamespace {
+		sort.Slice(interfaces, func(i, j int) bool {
+			if interfaces[i].DeviceID != interfaces[j].DeviceID {
+				return interfaces[i].DeviceID < interfaces[j].DeviceID
+			}
+			return interfaces[i].Index < interfaces[j].Index
+		})
+	}
+	return interfacesPerNamespace
+}
+
+func copyTags(tags []string) []string {
+	return append(make([]string, 0, len(tags)+2), tags...)
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+func Test_exporterStore(t *testing.T) {
+	now := MockTimeNow()
+	store := newExporterStore()
+
+	store.add(&common.ExporterRecord{
+		Namespace:    "default",
+		FlowType:     common.TypeSFlow5,
+		ExporterAddr: []byte{10, 0, 0, 20},
+		Interfaces: []common.ExporterInterface{
+			{
+				Index: 16,
+				Counters: &common.InterfaceCounters{
+					Speed:       100000000,
+					AdminStatus: 1,
+					OperStatus:  2,
+					Values: map[string]uint64{
+						"in_octets":  1000,
+						"out_errors": 3,
+					},
+				},
+			},
+		},
+	}, now)
+	store.add(&common.ExporterRecord{
+		Namespace:    "default",
+		FlowType:     common.TypeIPFIX,
+		ExporterAddr: []byte{127, 0, 0, 1},
+		SamplingRate: 1000,
+		Interfaces: []common.ExporterInterface{
+			{Index: 2, Name: "eth2", Description: "uplink"},
+			{Index: 1, Name: "eth1"},
+		},
+	}, now)
+
+	// interface names
+	flow := &common.Flow{Namespace: "default", FlowType: common.TypeIPFIX, ExporterAddr: []byte{127, 0, 0, 1}}
+	assert.Equal(t, "eth1", store.getInterfaceName(flow, 1))
+	assert.Equal(t, "eth2", store.getInterfaceName(flow, 2))
+	assert.Equal(t, "", store.getInterfaceName(flow, 3))
+	flow.FlowType = common.TypeNetFlow9
+	assert.Equal(t, "", store.getInterfaceName(flow, 1))
+
+	// metrics
+	sender := mocksender.NewMockSender("")
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("Rate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	store.submitMetrics(sender)
+
+	ipfixTags := []string{"device_namespace:default", "exporter_ip:127.0.0.1", "flow_type:ipfix"}
+	sflowTags := []string{"device_namespace:default", "snmp_device:10.0.0.20", "device_ip:10.0.0.20", "device_id:default:10.0.0.20", "flow_type:sflow5", "interface_index:16"}
+	sender.AssertMetric(t, "Gauge", "netflow.exporter.sampling_rate", 1000, "", ipfixTags)
+	sender.AssertMetric(t, "Gauge", "snmp.ifHighSpeed", 100, "", sflowTags)
+	sender.AssertMetric(t, "Gauge", "snmp.ifAdminStatus", 1, "", sflowTags)
+	sender.AssertMetric(t, "Gauge", "snmp.ifOperStatus", 2, "", sflowTags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifHCInOctets", 1000, "", sflowTags)
+	sender.AssertMetric(t, "Rate", "snmp.ifHCInOctets.rate", 1000, "", sflowTags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifOutErrors", 3, "", sflowTags)
+	sender.AssertMetric(t, "Rate", "snmp.ifOutErrors.rate", 3, "", sflowTags)
+	sender.AssertNumberOfCalls(t, "Gauge", 4)
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 2)
+	sender.AssertNumberOfCalls(t, "Rate", 2)
+
+	// metadata
+	assert.Equal(t, map[string][]metadata.InterfaceMetadata{
+		"default": {
+			{DeviceID: "default:10.0.0.20", Index: 16, RawID: "16", RawIDType: "sflow5", AdminStatus: metadata.AdminStatusUp, OperStatus: metadata.OperStatusDown},
+			{DeviceID: "default:127.0.0.1", IDTags: []string{"interface:eth1"}, Index: 1, RawID: "1", RawIDType: "ipfix", Name: "eth1"},
+			{DeviceID: "default:127.0.0.1", IDTags: []string{"interface:eth2"}, Index: 2, RawID: "2", RawIDType: "ipfix", Name: "eth2", Description: "uplink"},
+		},
+	}, store.getInterfaceMetadata())
+
+	// expiration
+	store.add(&common.ExporterRecord{
+		Namespace:    "default",
+		FlowType:     common.TypeIPFIX,
+		ExporterAddr: []byte{127, 0, 0, 1},
+		Interfaces: []common.ExporterInterface{
+			{Index: 1, Name: "eth1-renamed"},
+		},
+	}, now.Add(30*time.Minute))
+	store.removeExpired(now.Add(90 * time.Minute))
+	assert.Len(t, store.exporters, 1)
+	assert.Equal(t, "eth1-renamed", store.getInterfaceName(&common.Flow{Namespace: "default", FlowType: common.TypeIPFIX, ExporterAddr: []byte{127, 0, 0, 1}}, 1))
+	assert.Equal(t, "", store.getInterfaceName(&common.Flow{Namespace: "default", FlowType: common.TypeIPFIX, ExporterAddr: []byte{127, 0, 0, 1}}, 2))
+	assert.Equal(t, uint64(0), store.exporters[exporterKey{Namespace: "default", ExporterIP: "127.0.0.1", FlowType: common.TypeIPFIX}].samplingRate)
+}
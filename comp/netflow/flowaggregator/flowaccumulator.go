@@ -11,6 +11,7 @@ import (
 
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
 	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
 	rdnsquerier "github.com/DataDog/datadog-agent/comp/rdnsquerier/def"
 	"go.uber.org/atomic"
@@ -43,6 +44,7 @@ type flowAccumulator struct {
 
 	logger      log.Component
 	rdnsQuerier rdnsquerier.Component
+	enricher    *enrichment.Enricher
 }
 
 func newFlowContext(flow *common.Flow) flowContext {
@@ -53,7 +55,7 @@ func newFlowContext(flow *common.Flow) flowContext {
 	}
 }
 
-func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, logger log.Component, rdnsQuerier rdnsquerier.Component) *flowAccumulator {
+func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, logger log.Component, rdnsQuerier rdnsquerier.Component, enricher *enrichment.Enricher) *flowAccumulator {
 	return &flowAccumulator{
 		flows:                  make(map[uint64]flowContext),
 		flowFlushInterval:      aggregatorFlushInterval,
@@ -64,6 +66,7 @@ func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowCon
 		hashCollisionFlowCount: atomic.NewUint64(0),
 		logger:                 logger,
 		rdnsQuerier:            rdnsQuerier,
+		enricher:               enricher,
 	}
 }
 
@@ -127,12 +130,14 @@ func (f *flowAccumulator) add(flowToAdd *common.Flow) {
 	if !ok {
 		f.flows[aggHash] = newFlowContext(flowToAdd)
 		f.addRDNSEnrichment(aggHash, flowToAdd.SrcAddr, flowToAdd.DstAddr)
+		f.addLocalEnrichment(flowToAdd)
 		return
 	}
 	if aggFlow.flow == nil {
 		// flowToAdd is for the same hash as an aggregated flow that has been flushed
 		aggFlow.flow = flowToAdd
 		f.addRDNSEnrichment(aggHash, flowToAdd.SrcAddr, flowToAdd.DstAddr)
+		f.addLocalEnrichment(flowToAdd)
 	} else {
 		// use go routine for hash collision detection to avoid blocking critical path
 		go f.detectHashCollision(aggHash, *aggFlow.flow, *flowToAdd)
@@ -193,6 +198,16 @@ func (f *flowAccumulator) setDstReverseDNSHostname(aggHash uint64, hostname stri
 	}
 }
 
+// addLocalEnrichment adds ASN/GeoIP/CIDR label enrichment from local databases,
+// lookups are done once per aggregated flow since aggregated flows share the same addresses
+func (f *flowAccumulator) addLocalEnrichment(flow *common.Flow) {
+	if f.enricher == nil {
+		return
+	}
+	flow.SrcEnrichment = f.enricher.Lookup(flow.SrcAddr)
+	flow.DstEnrichment = f.enricher.Lookup(flow.DstAddr)
+}
+
 func (f *flowAccumulator) addRDNSEnrichment(aggHash uint64, srcAddr []byte, dstAddr []byte) {
 	err := f.rdnsQuerier.GetHostnameAsync(
 		srcAddr,
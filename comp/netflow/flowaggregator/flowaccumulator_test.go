@@ -11,6 +11,8 @@ import (
 
 	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
 	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
 	rdnsquerier "github.com/DataDog/datadog-agent/comp/rdnsquerier/def"
 	rdnsquerierfxmock "github.com/DataDog/datadog-agent/comp/rdnsquerier/fx-mock"
@@ -92,7 +94,7 @@ func Test_flowAccumulator_add(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, nil)
 	acc.add(flowA1)
 	acc.add(flowA2)
 	acc.add(flowB1)
@@ -166,7 +168,7 @@ func Test_flowAccumulator_portRollUp(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false, logger, rdnsQuerier, nil)
 	acc.add(flowA1)
 	acc.add(flowA2)
 
@@ -243,7 +245,7 @@ func Test_flowAccumulator_flush(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, nil)
 	acc.add(flow)
 
 	// Then
@@ -359,7 +361,7 @@ func Test_flowAccumulator_detectHashCollision(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, nil)
 
 	// Then
 	assert.Equal(t, uint64(0), acc.hashCollisionFlowCount.Load())
@@ -380,3 +382,38 @@ func Test_flowAccumulator_detectHashCollision(t *testing.T) {
 	acc.detectHashCollision(aggHash3, *flowA1, *flowB1)
 	assert.Equal(t, uint64(1), acc.hashCollisionFlowCount.Load())
 }
+
+func Test_flowAccumulator_localEnrichment(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+	enricher := enrichment.NewEnricher(config.EnrichmentConfig{
+		CIDRLabels: []config.CIDRLabel{
+			{CIDR: "10.10.10.0/24", Label: "office"},
+		},
+	}, logger)
+
+	// Given
+	flowA1 := &common.Flow{
+		FlowType:     common.TypeNetFlow9,
+		ExporterAddr: []byte{127, 0, 0, 1},
+		Bytes:        20,
+		Packets:      4,
+		SrcAddr:      []byte{10, 10, 10, 10},
+		DstAddr:      []byte{192, 168, 0, 1},
+		IPProtocol:   uint32(6),
+		SrcPort:      2000,
+		DstPort:      80,
+	}
+	flowA2 := *flowA1
+
+	// When
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, enricher)
+	acc.add(flowA1)
+	acc.add(&flowA2)
+
+	// Then
+	wrappedFlow := acc.flows[flowA1.AggregationHash()]
+	assert.Equal(t, uint64(40), wrappedFlow.flow.Bytes)
+	assert.Equal(t, &common.EndpointEnrichment{Labels: []string{"office"}}, wrappedFlow.flow.SrcEnrichment)
+	assert.Nil(t, wrappedFlow.flow.DstEnrichment)
+}
@@ -43,6 +43,11 @@ type FlowRunnableState interface {
 	Shutdown()
 }
 
+// exporterHealthState is implemented by the states tracking the health of their exporters, e.g. StateNetFlow
+type exporterHealthState interface {
+	ExporterHealth() []netflowstate.ExporterHealth
+}
+
 // StartFlowRoutine starts one of the goflow flow routine depending on the flow type
 func StartFlowRoutine(
 	flowType common.FlowType,
@@ -52,12 +57,13 @@ func StartFlowRoutine(
 	namespace string,
 	fieldMappings []config.Mapping,
 	flowInChan chan *common.Flow,
+	recordInChan chan *common.ExporterRecord,
 	logger log.Component,
 	atomicErr *atomic.String,
 	listenerFlowCount *atomic.Int64) (*FlowStateWrapper, error) {
 	var flowState FlowRunnableState
 
-	formatDriver := NewAggregatorFormatDriver(flowInChan, namespace, listenerFlowCount)
+	formatDriver := NewAggregatorFormatDriver(flowInChan, recordInChan, namespace, listenerFlowCount)
 	logrusLogger := GetLogrusLevel(logger)
 	ctx := context.Background()
 
@@ -75,7 +81,7 @@ func StartFlowRoutine(
 		state.TemplateSystem = templateSystem
 		flowState = state
 	case common.TypeSFlow5:
-		state := utils.NewStateSFlow()
+		state := netflowstate.NewStateSFlow()
 		state.Format = formatDriver
 		state.Logger = logrusLogger
 		flowState = state
@@ -107,3 +113,12 @@ func StartFlowRoutine(
 func (s *FlowStateWrapper) Shutdown() {
 	s.State.Shutdown()
 }
+
+// ExporterHealth returns the health of the exporters sending to the listener,
+// only NetFlow v9/IPFIX exporters are tracked
+func (s *FlowStateWrapper) ExporterHealth() []netflowstate.ExporterHealth {
+	if state, ok := s.State.(exporterHealthState); ok {
+		return state.ExporterHealth()
+	}
+	return nil
+}
@@ -18,14 +18,16 @@ import (
 type AggregatorFormatDriver struct {
 	namespace         string
 	flowAggIn         chan *common.Flow
+	recordAggIn       chan *common.ExporterRecord
 	listenerFlowCount *atomic.Int64
 }
 
 // NewAggregatorFormatDriver returns a new AggregatorFormatDriver
-func NewAggregatorFormatDriver(flowAgg chan *common.Flow, namespace string, listenerFlowCount *atomic.Int64) *AggregatorFormatDriver {
+func NewAggregatorFormatDriver(flowAgg chan *common.Flow, recordAgg chan *common.ExporterRecord, namespace string, listenerFlowCount *atomic.Int64) *AggregatorFormatDriver {
 	return &AggregatorFormatDriver{
 		namespace:         namespace,
 		flowAggIn:         flowAgg,
+		recordAggIn:       recordAgg,
 		listenerFlowCount: listenerFlowCount,
 	}
 }
@@ -42,15 +44,18 @@ func (d *AggregatorFormatDriver) Init(context.Context) error {
 
 // Format desc
 func (d *AggregatorFormatDriver) Format(data interface{}) ([]byte, []byte, error) {
-	switch flow := data.(type) {
+	switch msg := data.(type) {
 	case *flowpb.FlowMessage:
 		d.listenerFlowCount.Add(1)
-		d.flowAggIn <- ConvertFlow(flow, d.namespace)
+		d.flowAggIn <- ConvertFlow(msg, d.namespace)
 	case *common.FlowMessageWithAdditionalFields:
 		d.listenerFlowCount.Add(1)
-		d.flowAggIn <- ConvertFlowWithAdditionalFields(flow, d.namespace)
+		d.flowAggIn <- ConvertFlowWithAdditionalFields(msg, d.namespace)
+	case *common.ExporterRecord:
+		msg.Namespace = d.namespace
+		d.recordAggIn <- msg
 	default:
-		return nil, nil, fmt.Errorf("message is not flowpb.FlowMessage or common.FlowMessageWithAdditionalFields")
+		return nil, nil, fmt.Errorf("message is not flowpb.FlowMessage, common.FlowMessageWithAdditionalFields or common.ExporterRecord")
 	}
 
 	return nil, nil, nil
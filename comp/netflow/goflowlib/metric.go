@@ -92,6 +92,54 @@ var metricNameMapping = map[string]mappedMetric{
 		},
 		extraTags: []string{"flow_protocol:netflow"},
 	},
+	"flow_process_nf_sequence_gaps_count": {
+		name:           "processor.sequence_gaps",
+		allowedTagKeys: []string{"router", "version"},
+		keyRemapper: map[string]string{
+			"router": "exporter_ip",
+		},
+		extraTags: []string{"flow_protocol:netflow"},
+	},
+	"flow_process_nf_sequence_lost_count": {
+		name:           "processor.sequence_lost",
+		allowedTagKeys: []string{"router", "version"},
+		keyRemapper: map[string]string{
+			"router": "exporter_ip",
+		},
+		extraTags: []string{"flow_protocol:netflow"},
+	},
+	"flow_process_nf_exporter_restarts_count": {
+		name:           "processor.exporter_restarts",
+		allowedTagKeys: []string{"router", "version"},
+		keyRemapper: map[string]string{
+			"router": "exporter_ip",
+		},
+		extraTags: []string{"flow_protocol:netflow"},
+	},
+	"flow_process_nf_sampling_rate_changes_count": {
+		name:           "processor.sampling_rate_changes",
+		allowedTagKeys: []string{"router", "version"},
+		keyRemapper: map[string]string{
+			"router": "exporter_ip",
+		},
+		extraTags: []string{"flow_protocol:netflow"},
+	},
+	"flow_process_nf_templates": {
+		name:           "processor.templates",
+		allowedTagKeys: []string{"router", "version"},
+		keyRemapper: map[string]string{
+			"router": "exporter_ip",
+		},
+		extraTags: []string{"flow_protocol:netflow"},
+	},
+	"flow_process_nf_missing_templates": {
+		name:           "processor.missing_templates",
+		allowedTagKeys: []string{"router", "version"},
+		keyRemapper: map[string]string{
+			"router": "exporter_ip",
+		},
+		extraTags: []string{"flow_protocol:netflow"},
+	},
 	"flow_traffic_bytes": {
 		name:           "traffic.bytes",
 		allowedTagKeys: []string{"local_port", "remote_ip", "type"},
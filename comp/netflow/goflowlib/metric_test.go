@@ -392,6 +392,120 @@ func TestConvertMetric(t *testing.T) {
 			expectedTags:       []string{"exporter_ip:1.2.3.4", "error:some-error", "flow_protocol:netflow"},
 			expectedErr:        "",
 		},
+		{
+			name: "METRIC flow_process_nf_sequence_gaps_count",
+			metricFamily: &promClient.MetricFamily{
+				Name: proto.String("flow_process_nf_sequence_gaps_count"),
+				Type: promClient.MetricType_COUNTER.Enum(),
+			},
+			metric: &promClient.Metric{
+				Counter: &promClient.Counter{Value: proto.Float64(10)},
+				Label: []*promClient.LabelPair{
+					{Name: proto.String("router"), Value: proto.String("1.2.3.4")},
+					{Name: proto.String("version"), Value: proto.String("10")},
+				},
+			},
+			expectedMetricType: metrics.MonotonicCountType,
+			expectedName:       "processor.sequence_gaps",
+			expectedValue:      10.0,
+			expectedTags:       []string{"exporter_ip:1.2.3.4", "version:10", "flow_protocol:netflow"},
+			expectedErr:        "",
+		},
+		{
+			name: "METRIC flow_process_nf_sequence_lost_count",
+			metricFamily: &promClient.MetricFamily{
+				Name: proto.String("flow_process_nf_sequence_lost_count"),
+				Type: promClient.MetricType_COUNTER.Enum(),
+			},
+			metric: &promClient.Metric{
+				Counter: &promClient.Counter{Value: proto.Float64(10)},
+				Label: []*promClient.LabelPair{
+					{Name: proto.String("router"), Value: proto.String("1.2.3.4")},
+					{Name: proto.String("version"), Value: proto.String("10")},
+				},
+			},
+			expectedMetricType: metrics.MonotonicCountType,
+			expectedName:       "processor.sequence_lost",
+			expectedValue:      10.0,
+			expectedTags:       []string{"exporter_ip:1.2.3.4", "version:10", "flow_protocol:netflow"},
+			expectedErr:        "",
+		},
+		{
+			name: "METRIC flow_process_nf_exporter_restarts_count",
+			metricFamily: &promClient.MetricFamily{
+				Name: proto.String("flow_process_nf_exporter_restarts_count"),
+				Type: promClient.MetricType_COUNTER.Enum(),
+			},
+			metric: &promClient.Metric{
+				Counter: &promClient.Counter{Value: proto.Float64(10)},
+				Label: []*promClient.LabelPair{
+					{Name: proto.String("router"), Value: proto.String("1.2.3.4")},
+					{Name: proto.String("version"), Value: proto.String("10")},
+				},
+			},
+			expectedMetricType: metrics.MonotonicCountType,
+			expectedName:       "processor.exporter_restarts",
+			expectedValue:      10.0,
+			expectedTags:       []string{"exporter_ip:1.2.3.4", "version:10", "flow_protocol:netflow"},
+			expectedErr:        "",
+		},
+		{
+			name: "METRIC flow_process_nf_sampling_rate_changes_count",
+			metricFamily: &promClient.MetricFamily{
+				Name: proto.String("flow_process_nf_sampling_rate_changes_count"),
+				Type: promClient.MetricType_COUNTER.Enum(),
+			},
+			metric: &promClient.Metric{
+				Counter: &promClient.Counter{Value: proto.Float64(10)},
+				Label: []*promClient.LabelPair{
+					{Name: proto.String("router"), Value: proto.String("1.2.3.4")},
+					{Name: proto.String("version"), Value: proto.String("10")},
+				},
+			},
+			expectedMetricType: metrics.MonotonicCountType,
+			expectedName:       "processor.sampling_rate_changes",
+			expectedValue:      10.0,
+			expectedTags:       []string{"exporter_ip:1.2.3.4", "version:10", "flow_protocol:netflow"},
+			expectedErr:        "",
+		},
+		{
+			name: "METRIC flow_process_nf_templates",
+			metricFamily: &promClient.MetricFamily{
+				Name: proto.String("flow_process_nf_templates"),
+				Type: promClient.MetricType_GAUGE.Enum(),
+			},
+			metric: &promClient.Metric{
+				Gauge: &promClient.Gauge{Value: proto.Float64(10)},
+				Label: []*promClient.LabelPair{
+					{Name: proto.String("router"), Value: proto.String("1.2.3.4")},
+					{Name: proto.String("version"), Value: proto.String("10")},
+				},
+			},
+			expectedMetricType: metrics.GaugeType,
+			expectedName:       "processor.templates",
+			expectedValue:      10.0,
+			expectedTags:       []string{"exporter_ip:1.2.3.4", "version:10", "flow_protocol:netflow"},
+			expectedErr:        "",
+		},
+		{
+			name: "METRIC flow_process_nf_missing_templates",
+			metricFamily: &promClient.MetricFamily{
+				Name: proto.String("flow_process_nf_missing_templates"),
+				Type: promClient.MetricType_GAUGE.Enum(),
+			},
+			metric: &promClient.Metric{
+				Gauge: &promClient.Gauge{Value: proto.Float64(10)},
+				Label: []*promClient.LabelPair{
+					{Name: proto.String("router"), Value: proto.String("1.2.3.4")},
+					{Name: proto.String("version"), Value: proto.String("10")},
+				},
+			},
+			expectedMetricType: metrics.GaugeType,
+			expectedName:       "processor.missing_templates",
+			expectedValue:      10.0,
+			expectedTags:       []string{"exporter_ip:1.2.3.4", "version:10", "flow_protocol:netflow"},
+			expectedErr:        "",
+		},
 		{
 			name: "METRIC flow_traffic_bytes",
 			metricFamily: &promClient.MetricFamily{
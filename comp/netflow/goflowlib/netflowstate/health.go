@@ -0,0 +1,389 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package netflowstate
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/netsampler/goflow2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// exporterHealthTTL is the delay after which exporters that stopped sending packets are forgotten
+const exporterHealthTTL = 1 * time.Hour
+
+// exporterEvictionInterval is the minimum delay between two evictions of the exporters not seen for exporterHealthTTL
+const exporterEvictionInterval = 1 * time.Minute
+
+// sequenceThresholds are used to tell lost or reordered packets from exporter restarts.
+// NetFlow v9 sequence numbers count export packets, IPFIX ones count data records.
+var sequenceThresholds = map[uint16]struct {
+	maxReorder uint32 // lower sequence numbers are late packets up to maxReorder, restarts above
+	maxGap     uint32 // higher sequence numbers are lost packets up to maxGap, restarts above
+}{
+	9:  {maxReorder: 100, maxGap: 10000},
+	10: {maxReorder: 1000, maxGap: 1000000},
+}
+
+// timeNow is used to know when exporters were last seen, mocked in tests
+var timeNow = time.Now
+
+var (
+	netflowSequenceGaps = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flow_process_nf_sequence_gaps_count",
+			Help: "NetFlows sequence number gaps.",
+		},
+		[]string{"router", "version"},
+	)
+	netflowSequenceLost = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flow_process_nf_sequence_lost_count",
+			Help: "NetFlows packets (v9) or data records (IPFIX) lost according to sequence numbers.",
+		},
+		[]string{"router", "version"},
+	)
+	netflowExporterRestarts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flow_process_nf_exporter_restarts_count",
+			Help: "NetFlows exporter restarts detected from sequence numbers.",
+		},
+		[]string{"router", "version"},
+	)
+	netflowSamplingRateChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flow_process_nf_sampling_rate_changes_count",
+			Help: "NetFlows exporter sampling rate changes.",
+		},
+		[]string{"router", "version"},
+	)
+	netflowTemplates = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "flow_process_nf_templates",
+			Help: "NetFlows templates received from the exporter.",
+		},
+		[]string{"router", "version"},
+	)
+	netflowMissingTemplates = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "flow_process_nf_missing_templates",
+			Help: "NetFlows templates used by the exporter data sets but not received yet.",
+		},
+		[]string{"router", "version"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(netflowSequenceGaps)
+	prometheus.MustRegister(netflowSequenceLost)
+	prometheus.MustRegister(netflowExporterRestarts)
+	prometheus.MustRegister(netflowSamplingRateChanges)
+	prometheus.MustRegister(netflowTemplates)
+	prometheus.MustRegister(netflowMissingTemplates)
+}
+
+// ExporterHealth is the health of a NetFlow v9/IPFIX exporter observation domain
+type ExporterHealth struct {
+	ExporterIP          string
+	FlowType            common.FlowType
+	ObservationDomainID uint32
+	LastSeen            time.Time
+	Packets             uint64
+	// Templates is the number of templates received
+	Templates int
+	// MissingTemplates are the templates used by data sets but not received yet
+	MissingTemplates []uint16
+	// DroppedDataSets is the number of data sets dropped because their template was missing
+	DroppedDataSets uint64
+	SequenceGaps    uint64
+	// SequenceLost is the number of packets (NetFlow v9) or data records (IPFIX) lost
+	SequenceLost        uint64
+	Restarts            uint64
+	SamplingRate        uint64
+	SamplingRateChanges uint64
+}
+
+type exporterHealthKey struct {
+	exporterIP          string
+	version             uint16
+	observationDomainID uint32
+}
+
+type exporterHealthState struct {
+	health            ExporterHealth
+	templates         map[uint16]struct{}
+	missingTemplates  map[uint16]struct{}
+	nextSequence      uint32
+	nextSequenceKnown bool
+}
+
+// exporterHealthTracker tracks the templates, sequence numbers and sampling rates of exporters
+type exporterHealthTracker struct {
+	mu           sync.Mutex
+	exporters    map[exporterHealthKey]*exporterHealthState
+	lastEviction time.Time
+}
+
+func newExporterHealthTracker() *exporterHealthTracker {
+	return &exporterHealthTracker{exporters: make(map[exporterHealthKey]*exporterHealthState)}
+}
+
+// get returns the state of an exporter, evicting the exporters not seen recently. The lock must be held.
+func (t *exporterHealthTracker) get(exporterIP string, version uint16, observationDomainID uint32) *exporterHealthState {
+	now := timeNow()
+	t.evictStale(now)
+
+	key := exporterHealthKey{exporterIP: exporterIP, version: version, observationDomainID: observationDomainID}
+	state, ok := t.exporters[key]
+	if !ok {
+		flowType := common.TypeNetFlow9
+		if version == 10 {
+			flowType = common.TypeIPFIX
+		}
+		state = &exporterHealthState{
+			health: ExporterHealth{
+				ExporterIP:          exporterIP,
+				FlowType:            flowType,
+				ObservationDomainID: observationDomainID,
+			},
+			templates:        make(map[uint16]struct{}),
+			missingTemplates: make(map[uint16]struct{}),
+		}
+		t.exporters[key] = state
+	}
+	state.health.LastSeen = now
+	return state
+}
+
+// evictStale removes the exporters not seen for exporterHealthTTL and their template gauges, at most
+// once per exporterEvictionInterval. The lock must be held.
+func (t *exporterHealthTracker) evictStale(now time.Time) {
+	if now.Sub(t.lastEviction) < exporterEvictionInterval {
+		return
+	}
+	t.lastEviction = now
+	minLastSeen := now.Add(-exporterHealthTTL)
+	for key, state := range t.exporters {
+		if state.health.LastSeen.Before(minLastSeen) {
+			delete(t.exporters, key)
+			t.updateTemplateGauges(key.exporterIP, key.version)
+		}
+	}
+}
+
+// templateAdded records a template received from an exporter, it returns true if the template was missing
+func (t *exporterHealthTracker) templateAdded(exporterIP string, version uint16, observationDomainID uint32, templateID uint16) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := t.get(exporterIP, version, observationDomainID)
+	state.templates[templateID] = struct{}{}
+	_, wasMissing := state.missingTemplates[templateID]
+	delete(state.missingTemplates, templateID)
+	t.updateTemplateGauges(exporterIP, version)
+	return wasMissing
+}
+
+// templateNotFound records a data set dropped because its template is unknown, it returns true
+// if the template wasn't already missing
+func (t *exporterHealthTracker) templateNotFound(exporterIP string, version uint16, observationDomainID uint32, templateID uint16) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := t.get(exporterIP, version, observationDomainID)
+	state.health.DroppedDataSets++
+	if _, ok := state.missingTemplates[templateID]; ok {
+		return false
+	}
+	state.missingTemplates[templateID] = struct{}{}
+	t.updateTemplateGauges(exporterIP, version)
+	return true
+}
+
+// packetReceived checks the sequence number of a decoded packet, msgDec may be partially decoded
+// when decoded is false. It returns true if the exporter restarted.
+func (t *exporterHealthTracker) packetReceived(exporterIP string, msgDec interface{}, decoded bool) bool {
+	var version uint16
+	var sequence, observationDomainID uint32
+	var increment uint32 = 1
+	switch packet := msgDec.(type) {
+	case netflow.NFv9Packet:
+		version, sequence, observationDomainID = 9, packet.SequenceNumber, packet.SourceId
+	case netflow.IPFIXPacket:
+		version, sequence, observationDomainID = 10, packet.SequenceNumber, packet.ObservationDomainId
+		// IPFIX sequence numbers count the data records sent before the packet
+		increment = 0
+		for _, flowSet := range packet.FlowSets {
+			switch flowSet := flowSet.(type) {
+			case netflow.DataFlowSet:
+				increment += uint32(len(flowSet.Records))
+			case netflow.OptionsDataFlowSet:
+				increment += uint32(len(flowSet.Records))
+			}
+		}
+	default:
+		return false
+	}
+	labels := prometheus.Labels{"router": exporterIP, "version": strconv.Itoa(int(version))}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := t.get(exporterIP, version, observationDomainID)
+	state.health.Packets++
+
+	restarted := false
+	if state.nextSequenceKnown {
+		thresholds := sequenceThresholds[version]
+		diff := int64(int32(sequence - state.nextSequence))
+		switch {
+		case diff > int64(thresholds.maxGap) || diff < -int64(thresholds.maxReorder):
+			restarted = true
+			state.health.Restarts++
+			netflowExporterRestarts.With(labels).Inc()
+		case diff > 0:
+			state.health.SequenceGaps++
+			state.health.SequenceLost += uint64(diff)
+			netflowSequenceGaps.With(labels).Inc()
+			netflowSequenceLost.With(labels).Add(float64(diff))
+		case diff < 0:
+			// late packet, the next sequence number is still the expected one
+			return false
+		}
+	}
+	// the data records of IPFIX packets that couldn't be decoded are unknown, the next
+	// sequence number can't be checked
+	state.nextSequence = sequence + increment
+	state.nextSequenceKnown = decoded || version == 9
+	return restarted
+}
+
+// samplingRateReceived records the sampling rate of an exporter, it returns true if it changed
+func (t *exporterHealthTracker) samplingRateReceived(exporterIP string, version uint16, observationDomainID uint32, samplingRate uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state := t.get(exporterIP, version, observationDomainID)
+	previous := state.health.SamplingRate
+	state.health.SamplingRate = samplingRate
+	if previous == 0 || previous == samplingRate {
+		return false
+	}
+	state.health.SamplingRateChanges++
+	netflowSamplingRateChanges.With(prometheus.Labels{"router": exporterIP, "version": strconv.Itoa(int(version))}).Inc()
+	return true
+}
+
+// health returns the health of the exporters seen recently, sorted by exporter and observation domain
+func (t *exporterHealthTracker) health() []ExporterHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	minLastSeen := timeNow().Add(-exporterHealthTTL)
+	var exporters []ExporterHealth
+	for _, state := range t.exporters {
+		// stale exporters are evicted when packets are received
+		if state.health.LastSeen.Before(minLastSeen) {
+			continue
+		}
+		health := state.health
+		health.Templates = len(state.templates)
+		health.MissingTemplates = make([]uint16, 0, len(state.missingTemplates))
+		for templateID := range state.missingTemplates {
+			health.MissingTemplates = append(health.MissingTemplates, templateID)
+		}
+		sort.Slice(health.MissingTemplates, func(i, j int) bool { return health.MissingTemplates[i] < health.MissingTemplates[j] })
+		exporters = append(exporters, health)
+	}
+	sort.Slice(exporters, func(i, j int) bool {
+		if exporters[i].ExporterIP != exporters[j].ExporterIP {
+			return exporters[i].ExporterIP < exporters[j].ExporterIP
+		}
+		if exporters[i].FlowType != exporters[j].FlowType {
+			return exporters[i].FlowType < exporters[j].FlowType
+		}
+		return exporters[i].ObservationDomainID < exporters[j].ObservationDomainID
+	})
+	return exporters
+}
+
+// updateTemplateGauges sets the template gauges of an exporter, summed over its observation domains.
+// The lock must be held.
+func (t *exporterHealthTracker) updateTemplateGauges(exporterIP string, version uint16) {
+	labels := prometheus.Labels{"router": exporterIP, "version": strconv.Itoa(int(version))}
+	var templates, missingTemplates, domains int
+	for key, state := range t.exporters {
+		if key.exporterIP != exporterIP || key.version != version {
+			continue
+		}
+		domains++
+		templates += len(state.templates)
+		missingTemplates += len(state.missingTemplates)
+	}
+	if domains == 0 {
+		netflowTemplates.Delete(labels)
+		netflowMissingTemplates.Delete(labels)
+		return
+	}
+	netflowTemplates.With(labels).Set(float64(templates))
+	netflowMissingTemplates.With(labels).Set(float64(missingTemplates))
+}
+
+// healthTemplateSystem wraps the goflow template system to track the templates of exporters.
+// The goflow in-memory template system doesn't return an error for unknown templates, the
+// data sets are skipped without notice, so unknown templates are detected here.
+// A healthTemplateSystem is used to decode a single packet.
+type healthTemplateSystem struct {
+	netflow.TemplateWrapper
+	state *StateNetFlow
+	// templateNotFound is set when a data set of the packet is dropped
+	templateNotFound *bool
+}
+
+// GetTemplate returns a template, recording the templates not received yet
+func (w healthTemplateSystem) GetTemplate(version uint16, obsDomainID uint32, templateID uint16) (interface{}, error) {
+	template, err := w.TemplateWrapper.GetTemplate(version, obsDomainID, templateID)
+	if err == nil && template != nil {
+		return template, nil
+	}
+	*w.templateNotFound = true
+	if err == nil {
+		// errors are counted once the packet is decoded
+		utils.NetFlowErrors.With(
+			prometheus.Labels{
+				"router": w.Key,
+				"error":  "template_not_found",
+			}).
+			Inc()
+	}
+	if w.state.exporters.templateNotFound(w.Key, version, obsDomainID, templateID) && w.state.Logger != nil {
+		w.state.Logger.Warnf("NetFlow exporter %s (version %d, observation domain %d) sent data for unknown template %d, its flows are dropped until the template is received",
+			w.Key, version, obsDomainID, templateID)
+	}
+	return template, err
+}
+
+// AddTemplate adds a template, recording its arrival
+func (w healthTemplateSystem) AddTemplate(version uint16, obsDomainID uint32, template interface{}) {
+	w.TemplateWrapper.AddTemplate(version, obsDomainID, template)
+	templateID := templateID(template)
+	if w.state.exporters.templateAdded(w.Key, version, obsDomainID, templateID) && w.state.Logger != nil {
+		w.state.Logger.Infof("NetFlow exporter %s (version %d, observation domain %d) sent missing template %d", w.Key, version, obsDomainID, templateID)
+	}
+}
+
+func templateID(template interface{}) uint16 {
+	switch templateConv := template.(type) {
+	case netflow.IPFIXOptionsTemplateRecord:
+		return templateConv.TemplateId
+	case netflow.NFv9OptionsTemplateRecord:
+		return templateConv.TemplateId
+	case netflow.TemplateRecord:
+		return templateConv.TemplateId
+	}
+	return 0
+}
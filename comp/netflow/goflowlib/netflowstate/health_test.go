@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package netflowstate
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/netsampler/goflow2/decoders/netflow/templates"
+	"github.com/netsampler/goflow2/utils"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// nfv9Packet returns a NetFlow v9 packet of source ID 0
+func nfv9Packet(sequence uint32, flowSets ...[]byte) []byte {
+	packet := binary.BigEndian.AppendUint16(nil, 9)
+	packet = binary.BigEndian.AppendUint16(packet, uint16(len(flowSets)))
+	packet = binary.BigEndian.AppendUint32(packet, 1000) // system uptime
+	packet = binary.BigEndian.AppendUint32(packet, 1700000000)
+	packet = binary.BigEndian.AppendUint32(packet, sequence)
+	packet = binary.BigEndian.AppendUint32(packet, 0)
+	for _, flowSet := range flowSets {
+		packet = append(packet, flowSet...)
+	}
+	return packet
+}
+
+// nfv9TemplateFlowSet returns the template 256, made of the IN_BYTES field
+func nfv9TemplateFlowSet() []byte {
+	return []byte{0, 0, 0, 12, 1, 0, 0, 1, 0, netflow.NFV9_FIELD_IN_BYTES, 0, 4}
+}
+
+// nfv9DataFlowSet returns a data set of template 256
+func nfv9DataFlowSet() []byte {
+	return []byte{1, 0, 0, 8, 0, 0, 3, 232}
+}
+
+func TestNetflowState_ExporterHealth(t *testing.T) {
+	ctx := context.Background()
+	templateSystem, err := templates.FindTemplateSystem(ctx, "memory")
+	require.NoError(t, err, "error with template")
+	defer templateSystem.Close(ctx)
+
+	state := NewStateNetFlow(nil)
+	state.Format = &mockedFormatDriver{}
+	state.Logger = logrus.StandardLogger()
+	state.TemplateSystem = templateSystem
+
+	// goflow metrics are global, other tests count their series
+	t.Cleanup(func() {
+		utils.NetFlowStats.Reset()
+		utils.NetFlowErrors.Reset()
+		utils.NetFlowSetStatsSum.Reset()
+		utils.NetFlowSetRecordsStatsSum.Reset()
+		utils.NetFlowTimeStatsSum.Reset()
+	})
+
+	decode := func(payload []byte) {
+		err := state.DecodeFlow(utils.BaseMessage{Src: net.ParseIP("10.0.0.1"), Payload: payload, RecvTime: time.Now()})
+		require.NoError(t, err, "error handling flow packet")
+	}
+
+	// data received before its template is dropped
+	decode(nfv9Packet(1, nfv9DataFlowSet()))
+
+	health := state.ExporterHealth()
+	require.Len(t, health, 1)
+	assert.Equal(t, []uint16{256}, health[0].MissingTemplates)
+	assert.Equal(t, uint64(1), health[0].DroppedDataSets)
+	assert.Equal(t, 0, health[0].Templates)
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(utils.NetFlowErrors.WithLabelValues("10.0.0.1", "template_not_found")))
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(netflowMissingTemplates.WithLabelValues("10.0.0.1", "9")))
+
+	decode(nfv9Packet(2, nfv9TemplateFlowSet(), nfv9DataFlowSet()))
+	// packets 3 and 4 are lost
+	decode(nfv9Packet(5, nfv9DataFlowSet()))
+
+	health = state.ExporterHealth()
+	require.Len(t, health, 1)
+	assert.Equal(t, "10.0.0.1", health[0].ExporterIP)
+	assert.Equal(t, common.TypeNetFlow9, health[0].FlowType)
+	assert.Equal(t, uint64(3), health[0].Packets)
+	assert.Equal(t, 1, health[0].Templates)
+	assert.Empty(t, health[0].MissingTemplates)
+	assert.Equal(t, uint64(1), health[0].DroppedDataSets)
+	assert.Equal(t, uint64(1), health[0].SequenceGaps)
+	assert.Equal(t, uint64(2), health[0].SequenceLost)
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(netflowTemplates.WithLabelValues("10.0.0.1", "9")))
+	assert.Equal(t, float64(0), promtestutil.ToFloat64(netflowMissingTemplates.WithLabelValues("10.0.0.1", "9")))
+	assert.Equal(t, float64(2), promtestutil.ToFloat64(netflowSequenceLost.WithLabelValues("10.0.0.1", "9")))
+}
+
+func TestExporterHealthTracker_NetFlow9Sequence(t *testing.T) {
+	tracker := newExporterHealthTracker()
+	for _, sequence := range []uint32{10, 11, 13, 12, 14, 20000, 20001} {
+		tracker.packetReceived("10.0.0.2", netflow.NFv9Packet{SequenceNumber: sequence, SourceId: 1}, true)
+	}
+
+	health := tracker.health()
+	require.Len(t, health, 1)
+	assert.Equal(t, uint32(1), health[0].ObservationDomainID)
+	assert.Equal(t, uint64(7), health[0].Packets)
+	// 12 is a late packet, 20000 a restart
+	assert.Equal(t, uint64(1), health[0].SequenceGaps)
+	assert.Equal(t, uint64(1), health[0].SequenceLost)
+	assert.Equal(t, uint64(1), health[0].Restarts)
+}
+
+func TestExporterHealthTracker_IPFIXSequence(t *testing.T) {
+	tracker := newExporterHealthTracker()
+	ipfixPacket := func(sequence uint32, records int) netflow.IPFIXPacket {
+		return netflow.IPFIXPacket{
+			SequenceNumber:      sequence,
+			ObservationDomainId: 5,
+			FlowSets:            []interface{}{netflow.DataFlowSet{Records: make([]netflow.DataRecord, records)}},
+		}
+	}
+
+	// sequence numbers count data records
+	tracker.packetReceived("10.0.0.3", ipfixPacket(100, 3), true)
+	tracker.packetReceived("10.0.0.3", ipfixPacket(103, 2), true)
+	tracker.packetReceived("10.0.0.3", ipfixPacket(110, 0), false)
+	// the data records of the packet that couldn't be decoded are unknown
+	tracker.packetReceived("10.0.0.3", ipfixPacket(500, 1), true)
+	tracker.packetReceived("10.0.0.3", ipfixPacket(501, 1), true)
+
+	health := tracker.health()
+	require.Len(t, health, 1)
+	assert.Equal(t, common.TypeIPFIX, health[0].FlowType)
+	assert.Equal(t, uint64(5), health[0].Packets)
+	assert.Equal(t, uint64(1), health[0].SequenceGaps)
+	assert.Equal(t, uint64(5), health[0].SequenceLost)
+	assert.Equal(t, uint64(0), health[0].Restarts)
+}
+
+func TestExporterHealthTracker_SamplingRate(t *testing.T) {
+	tracker := newExporterHealthTracker()
+	assert.False(t, tracker.samplingRateReceived("10.0.0.4", 10, 0, 1000))
+	assert.False(t, tracker.samplingRateReceived("10.0.0.4", 10, 0, 1000))
+	assert.True(t, tracker.samplingRateReceived("10.0.0.4", 10, 0, 2000))
+
+	health := tracker.health()
+	require.Len(t, health, 1)
+	assert.Equal(t, uint64(2000), health[0].SamplingRate)
+	assert.Equal(t, uint64(1), health[0].SamplingRateChanges)
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(netflowSamplingRateChanges.WithLabelValues("10.0.0.4", "10")))
+}
+
+func TestExporterHealthTracker_Expiration(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tracker := newExporterHealthTracker()
+	assert.True(t, tracker.templateNotFound("10.0.0.5", 9, 0, 256))
+	assert.False(t, tracker.templateNotFound("10.0.0.5", 9, 0, 256))
+	assert.True(t, tracker.templateAdded("10.0.0.5", 9, 0, 256))
+	tracker.templateAdded("10.0.0.5", 9, 1, 300)
+	assert.Equal(t, float64(2), promtestutil.ToFloat64(netflowTemplates.WithLabelValues("10.0.0.5", "9")))
+	assert.Len(t, tracker.health(), 2)
+
+	now = now.Add(exporterHealthTTL + time.Minute)
+	assert.Empty(t, tracker.health())
+	// reading the health doesn't evict the exporters
+	assert.Len(t, tracker.exporters, 2)
+	assert.Equal(t, float64(2), promtestutil.ToFloat64(netflowTemplates.WithLabelValues("10.0.0.5", "9")))
+
+	// the exporters not seen recently are evicted when another exporter is seen
+	tracker.templateAdded("10.0.0.6", 9, 0, 256)
+	assert.Len(t, tracker.exporters, 1)
+	// the gauges of the exporter were removed
+	assert.False(t, netflowTemplates.DeleteLabelValues("10.0.0.5", "9"))
+}
@@ -3,8 +3,9 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2023-present Datadog, Inc.
 
-// Package netflowstate provides a Netflow state manager
-// on top of goflow default producer, to allow additional fields collection.
+// Package netflowstate provides Netflow and sFlow state managers
+// on top of goflow default producer, to allow additional fields collection
+// and the collection of exporter data (options data and counter samples).
 package netflowstate
 
 import (
@@ -45,6 +46,8 @@ type StateNetFlow struct {
 	ctx context.Context
 
 	mappedFieldsConfig map[uint16]config.Mapping
+
+	exporters *exporterHealthTracker
 }
 
 // NewStateNetFlow initializes a new Netflow/IPFIX producer, with the goflow default producer and the additional fields producer
@@ -54,6 +57,7 @@ func NewStateNetFlow(mappingConfs []config.Mapping) *StateNetFlow {
 		samplinglock:       &sync.RWMutex{},
 		sampling:           make(map[string]producer.SamplingRateSystem),
 		mappedFieldsConfig: mapFieldsConfig(mappingConfs),
+		exporters:          newExporterHealthTracker(),
 	}
 }
 
@@ -84,7 +88,16 @@ func (s *StateNetFlow) DecodeFlow(msg interface{}) error {
 	}
 
 	timeTrackStart := time.Now()
-	msgDec, err := netflow.DecodeMessageContext(s.ctx, buf, key, netflow.TemplateWrapper{Ctx: s.ctx, Key: key, Inner: s.TemplateSystem})
+	templateNotFound := false
+	templateSystem := healthTemplateSystem{
+		TemplateWrapper:  netflow.TemplateWrapper{Ctx: s.ctx, Key: key, Inner: s.TemplateSystem},
+		state:            s,
+		templateNotFound: &templateNotFound,
+	}
+	msgDec, err := netflow.DecodeMessageContext(s.ctx, buf, key, templateSystem)
+	if s.exporters.packetReceived(key, msgDec, err == nil && !templateNotFound) && s.Logger != nil {
+		s.Logger.Infof("NetFlow exporter %s restarted, sequence numbers were reset", key)
+	}
 	if err != nil {
 		switch err.(type) {
 		case *netflow.ErrorTemplateNotFound:
@@ -107,6 +120,16 @@ func (s *StateNetFlow) DecodeFlow(msg interface{}) error {
 
 	s.sendTelemetryMetrics(msgDec, key)
 
+	if record := convertOptionsData(msgDec, samplerAddress); record != nil {
+		if record.SamplingRate > 0 {
+			s.samplingRateReceived(key, msgDec, record.SamplingRate)
+		}
+		_, _, err := s.Format.Format(record)
+		if err != nil && s.Logger != nil {
+			s.Logger.Error(err)
+		}
+	}
+
 	flowMessageSet, err := producer.ProcessMessageNetFlowConfig(msgDec, sampling, s.configMapped)
 	if err != nil {
 		s.Logger.Errorf("failed to process netflow packet %s", err)
@@ -154,6 +177,26 @@ func (s *StateNetFlow) DecodeFlow(msg interface{}) error {
 	return nil
 }
 
+// ExporterHealth returns the health of the exporters that sent packets recently: templates, sequence
+// number gaps and sampling rate changes, by observation domain.
+func (s *StateNetFlow) ExporterHealth() []ExporterHealth {
+	return s.exporters.health()
+}
+
+func (s *StateNetFlow) samplingRateReceived(key string, msgDec interface{}, samplingRate uint64) {
+	var version uint16
+	var observationDomainID uint32
+	switch packet := msgDec.(type) {
+	case netflow.NFv9Packet:
+		version, observationDomainID = 9, packet.SourceId
+	case netflow.IPFIXPacket:
+		version, observationDomainID = 10, packet.ObservationDomainId
+	}
+	if s.exporters.samplingRateReceived(key, version, observationDomainID, samplingRate) && s.Logger != nil {
+		s.Logger.Infof("NetFlow exporter %s (version %d, observation domain %d) sampling rate changed to %d", key, version, observationDomainID, samplingRate)
+	}
+}
+
 func (s *StateNetFlow) initConfig() {
 	s.configMapped = producer.NewProducerConfigMapped(s.Config)
 }
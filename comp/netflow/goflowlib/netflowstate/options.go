@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package netflowstate
+
+import (
+	"bytes"
+	"net"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/netsampler/goflow2/producer"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// NetFlow v9 options scope type for interfaces (RFC 3954 section 6.1)
+const nfv9ScopeInterface = 2
+
+// samplingRateFields are the fields carrying the sampling rate, by order of preference,
+// same as the goflow producer: samplingPacketInterval, samplerRandomInterval, samplingInterval
+var samplingRateFields = []uint16{
+	netflow.IPFIX_FIELD_samplingPacketInterval,
+	netflow.IPFIX_FIELD_samplerRandomInterval,
+	netflow.IPFIX_FIELD_samplingInterval,
+}
+
+// convertOptionsData returns the sampling rate and interface names found in NetFlow v9/IPFIX options data,
+// or nil if the packet has no such options data
+func convertOptionsData(msgDec interface{}, exporterAddr net.IP) *common.ExporterRecord {
+	var optionsDataFlowSets []netflow.OptionsDataFlowSet
+	var flowType common.FlowType
+	var scopeInterfaceTypes []uint16
+
+	switch msgDecConv := msgDec.(type) {
+	case netflow.NFv9Packet:
+		_, _, _, optionsDataFlowSets = producer.SplitNetFlowSets(msgDecConv)
+		flowType = common.TypeNetFlow9
+		scopeInterfaceTypes = []uint16{nfv9ScopeInterface}
+	case netflow.IPFIXPacket:
+		_, _, _, optionsDataFlowSets = producer.SplitIPFIXSets(msgDecConv)
+		flowType = common.TypeIPFIX
+		scopeInterfaceTypes = []uint16{netflow.IPFIX_FIELD_ingressInterface, netflow.IPFIX_FIELD_egressInterface}
+	default:
+		return nil
+	}
+
+	record := common.ExporterRecord{
+		FlowType:     flowType,
+		ExporterAddr: exporterAddr,
+	}
+	for _, flowSet := range optionsDataFlowSets {
+		for _, optionsRecord := range flowSet.Records {
+			if record.SamplingRate == 0 {
+				record.SamplingRate = findSamplingRate(optionsRecord.OptionsValues)
+			}
+			if exporterInterface, ok := findInterface(optionsRecord, scopeInterfaceTypes); ok {
+				record.Interfaces = append(record.Interfaces, exporterInterface)
+			}
+		}
+	}
+	if record.SamplingRate == 0 && len(record.Interfaces) == 0 {
+		return nil
+	}
+	return &record
+}
+
+func findSamplingRate(values []netflow.DataField) uint64 {
+	for _, fieldType := range samplingRateFields {
+		if value, ok := findUint(values, fieldType); ok && value > 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+// findInterface returns the interface described by an options record,
+// the interface index is either the record scope or an option value (e.g. Cisco interface table)
+func findInterface(optionsRecord netflow.OptionsDataRecord, scopeInterfaceTypes []uint16) (common.ExporterInterface, bool) {
+	exporterInterface := common.ExporterInterface{
+		Name:        findString(optionsRecord.OptionsValues, netflow.IPFIX_FIELD_interfaceName),
+		Description: findString(optionsRecord.OptionsValues, netflow.IPFIX_FIELD_interfaceDescription),
+	}
+	if exporterInterface.Name == "" && exporterInterface.Description == "" {
+		return exporterInterface, false
+	}
+
+	for _, fieldType := range scopeInterfaceTypes {
+		if index, ok := findUint(optionsRecord.ScopesValues, fieldType); ok {
+			exporterInterface.Index = uint32(index)
+			return exporterInterface, true
+		}
+	}
+	if index, ok := findUint(optionsRecord.OptionsValues, netflow.IPFIX_FIELD_ingressInterface); ok {
+		exporterInterface.Index = uint32(index)
+		return exporterInterface, true
+	}
+	return exporterInterface, false
+}
+
+func findValue(values []netflow.DataField, fieldType uint16) ([]byte, bool) {
+	for _, value := range values {
+		if value.PenProvided || value.Type != fieldType {
+			continue
+		}
+		valueBytes, ok := value.Value.([]byte)
+		return valueBytes, ok
+	}
+	return nil, false
+}
+
+func findUint(values []netflow.DataField, fieldType uint16) (uint64, bool) {
+	valueBytes, ok := findValue(values, fieldType)
+	if !ok {
+		return 0, false
+	}
+	var value uint64
+	if err := producer.DecodeUNumber(valueBytes, &value); err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+func findString(values []netflow.DataField, fieldType uint16) string {
+	valueBytes, ok := findValue(values, fieldType)
+	if !ok {
+		return ""
+	}
+	return string(bytes.TrimRight(valueBytes, "\x00")) // Removing trailing null chars
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package netflowstate
+
+import (
+	"net"
+	"testing"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+func Test_convertOptionsData(t *testing.T) {
+	exporterAddr := net.ParseIP("127.0.0.1").To4()
+
+	tests := []struct {
+		name           string
+		msgDec         interface{}
+		expectedRecord *common.ExporterRecord
+	}{
+		{
+			name: "NetFlow v9 interface scope and sampling rate",
+			msgDec: netflow.NFv9Packet{
+				FlowSets: []interface{}{
+					netflow.OptionsDataFlowSet{
+						Records: []netflow.OptionsDataRecord{
+							{
+								ScopesValues: []netflow.DataField{
+									{Type: nfv9ScopeInterface, Value: []byte{0, 0, 0, 5}},
+								},
+								OptionsValues: []netflow.DataField{
+									{Type: netflow.NFV9_FIELD_IF_NAME, Value: []byte("Gi0/0/1\x00\x00")},
+									{Type: netflow.NFV9_FIELD_IF_DESC, Value: []byte("uplink")},
+								},
+							},
+							{
+								ScopesValues: []netflow.DataField{
+									{Type: 1, Value: []byte{0, 0, 0, 0}},
+								},
+								OptionsValues: []netflow.DataField{
+									{Type: netflow.IPFIX_FIELD_samplingInterval, Value: []byte{0, 0, 3, 232}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedRecord: &common.ExporterRecord{
+				FlowType:     common.TypeNetFlow9,
+				ExporterAddr: exporterAddr,
+				SamplingRate: 1000,
+				Interfaces: []common.ExporterInterface{
+					{Index: 5, Name: "Gi0/0/1", Description: "uplink"},
+				},
+			},
+		},
+		{
+			name: "IPFIX interface index in options values",
+			msgDec: netflow.IPFIXPacket{
+				FlowSets: []interface{}{
+					netflow.OptionsDataFlowSet{
+						Records: []netflow.OptionsDataRecord{
+							{
+								ScopesValues: []netflow.DataField{
+									{Type: netflow.IPFIX_FIELD_exporterIPv4Address, Value: []byte{127, 0, 0, 1}},
+								},
+								OptionsValues: []netflow.DataField{
+									{Type: netflow.IPFIX_FIELD_ingressInterface, Value: []byte{0, 7}},
+									{Type: netflow.IPFIX_FIELD_interfaceName, Value: []byte("eth7")},
+								},
+							},
+							{
+								ScopesValues: []netflow.DataField{
+									{Type: netflow.IPFIX_FIELD_egressInterface, Value: []byte{0, 0, 0, 8}},
+								},
+								OptionsValues: []netflow.DataField{
+									{Type: netflow.IPFIX_FIELD_interfaceName, Value: []byte("eth8")},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedRecord: &common.ExporterRecord{
+				FlowType:     common.TypeIPFIX,
+				ExporterAddr: exporterAddr,
+				Interfaces: []common.ExporterInterface{
+					{Index: 7, Name: "eth7"},
+					{Index: 8, Name: "eth8"},
+				},
+			},
+		},
+		{
+			name: "interface name without index is ignored",
+			msgDec: netflow.IPFIXPacket{
+				FlowSets: []interface{}{
+					netflow.OptionsDataFlowSet{
+						Records: []netflow.OptionsDataRecord{
+							{
+								OptionsValues: []netflow.DataField{
+									{Type: netflow.IPFIX_FIELD_interfaceName, Value: []byte("eth7")},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedRecord: nil,
+		},
+		{
+			name: "no options data",
+			msgDec: netflow.NFv9Packet{
+				FlowSets: []interface{}{
+					netflow.DataFlowSet{},
+				},
+			},
+			expectedRecord: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedRecord, convertOptionsData(tt.msgDec, exporterAddr))
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package netflowstate
+
+import (
+	"bytes"
+	"math"
+	"net"
+	"time"
+
+	"github.com/netsampler/goflow2/decoders/sflow"
+	"github.com/netsampler/goflow2/format"
+	"github.com/netsampler/goflow2/producer"
+	"github.com/netsampler/goflow2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// sFlow uses the maximum value of a counter to report a counter unknown to the agent
+const (
+	sflowUnknownCounter32 = math.MaxUint32
+	sflowUnknownCounter64 = math.MaxUint64
+)
+
+// StateSFlow holds a sFlow producer
+type StateSFlow struct {
+	stopper
+
+	Format format.FormatInterface
+	Logger utils.Logger
+
+	Config       *producer.ProducerConfig
+	configMapped *producer.ProducerConfigMapped
+}
+
+// NewStateSFlow initializes a new sFlow producer, with the goflow default producer
+// and the collection of interface counters from counter samples
+func NewStateSFlow() *StateSFlow {
+	return &StateSFlow{}
+}
+
+// DecodeFlow decodes a sFlow packet, flow samples are formatted into flowmessage.FlowMessage
+// and interface counter samples into common.ExporterRecord
+func (s *StateSFlow) DecodeFlow(msg interface{}) error {
+	pkt := msg.(utils.BaseMessage)
+	buf := bytes.NewBuffer(pkt.Payload)
+	key := pkt.Src.String()
+
+	ts := uint64(time.Now().UTC().Unix())
+	if pkt.SetTime {
+		ts = uint64(pkt.RecvTime.UTC().Unix())
+	}
+
+	timeTrackStart := time.Now()
+	msgDec, err := sflow.DecodeMessage(buf)
+	if err != nil {
+		errorLabel := "error_decoding"
+		switch err.(type) {
+		case *sflow.ErrorVersion:
+			errorLabel = "error_version"
+		case *sflow.ErrorIPVersion:
+			errorLabel = "error_ip_version"
+		case *sflow.ErrorDataFormat:
+			errorLabel = "error_data_format"
+		}
+		utils.SFlowErrors.With(
+			prometheus.Labels{
+				"router": key,
+				"error":  errorLabel,
+			}).
+			Inc()
+		return err
+	}
+
+	if packet, ok := msgDec.(sflow.Packet); ok {
+		s.sendTelemetryMetrics(packet, key)
+
+		if record := convertCounterSamples(packet); record != nil {
+			s.format(record)
+		}
+	}
+
+	flowMessageSet, err := producer.ProcessMessageSFlowConfig(msgDec, s.configMapped)
+	if err != nil && s.Logger != nil {
+		s.Logger.Errorf("failed to process sflow packet %s", err)
+	}
+
+	timeTrackStop := time.Now()
+	utils.DecoderTime.With(
+		prometheus.Labels{
+			"name": "sFlow",
+		}).
+		Observe(float64((timeTrackStop.Sub(timeTrackStart)).Nanoseconds()) / 1000)
+
+	for _, fmsg := range flowMessageSet {
+		fmsg.TimeReceived = ts
+		fmsg.TimeFlowStart = ts
+		fmsg.TimeFlowEnd = ts
+		s.format(fmsg)
+	}
+
+	return nil
+}
+
+func (s *StateSFlow) format(data interface{}) {
+	if s.Format == nil {
+		return
+	}
+	_, _, err := s.Format.Format(data)
+	if err != nil && s.Logger != nil {
+		s.Logger.Error(err)
+	}
+}
+
+func (s *StateSFlow) initConfig() {
+	s.configMapped = producer.NewProducerConfigMapped(s.Config)
+}
+
+// FlowRoutine starts a goflow flow routine
+func (s *StateSFlow) FlowRoutine(workers int, addr string, port int, reuseport bool) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	s.initConfig()
+	return utils.UDPStoppableRoutine(s.stopCh, "sFlow", s.DecodeFlow, workers, addr, port, reuseport, s.Logger)
+}
+
+func (s *StateSFlow) sendTelemetryMetrics(packet sflow.Packet, key string) {
+	agentStr := net.IP(packet.AgentIP).String()
+	utils.SFlowStats.With(
+		prometheus.Labels{
+			"router":  key,
+			"agent":   agentStr,
+			"version": "5",
+		}).
+		Inc()
+
+	for _, samples := range packet.Samples {
+		typeStr := "unknown"
+		countRec := 0
+		switch samplesConv := samples.(type) {
+		case sflow.FlowSample:
+			typeStr = "FlowSample"
+			countRec = len(samplesConv.Records)
+		case sflow.CounterSample:
+			typeStr = "CounterSample"
+			if samplesConv.Header.Format == 4 {
+				typeStr = "Expanded" + typeStr
+			}
+			countRec = len(samplesConv.Records)
+		case sflow.ExpandedFlowSample:
+			typeStr = "ExpandedFlowSample"
+			countRec = len(samplesConv.Records)
+		}
+		labels := prometheus.Labels{
+			"router":  key,
+			"agent":   agentStr,
+			"version": "5",
+			"type":    typeStr,
+		}
+		utils.SFlowSampleStatsSum.With(labels).Inc()
+		utils.SFlowSampleRecordsStatsSum.With(labels).Add(float64(countRec))
+	}
+}
+
+// convertCounterSamples returns the generic interface counters found in a sFlow packet,
+// or nil if the packet has no counter sample
+func convertCounterSamples(packet sflow.Packet) *common.ExporterRecord {
+	var interfaces []common.ExporterInterface
+	for _, sample := range packet.Samples {
+		counterSample, ok := sample.(sflow.CounterSample)
+		if !ok {
+			continue
+		}
+		for _, record := range counterSample.Records {
+			ifCounters, ok := record.Data.(sflow.IfCounters)
+			if !ok {
+				continue
+			}
+			interfaces = append(interfaces, common.ExporterInterface{
+				Index:    ifCounters.IfIndex,
+				Counters: convertIfCounters(ifCounters),
+			})
+		}
+	}
+	if len(interfaces) == 0 {
+		return nil
+	}
+	return &common.ExporterRecord{
+		FlowType:     common.TypeSFlow5,
+		ExporterAddr: packet.AgentIP,
+		Interfaces:   interfaces,
+	}
+}
+
+func convertIfCounters(ifCounters sflow.IfCounters) *common.InterfaceCounters {
+	counters := &common.InterfaceCounters{
+		Values: make(map[string]uint64),
+	}
+	if ifCounters.IfSpeed != sflowUnknownCounter64 {
+		counters.Speed = ifCounters.IfSpeed
+	}
+	// ifStatus bit 0 is the admin status and bit 1 the oper status (0 = down, 1 = up)
+	counters.AdminStatus = 2 - (ifCounters.IfStatus & 1)
+	counters.OperStatus = 2 - ((ifCounters.IfStatus >> 1) & 1)
+
+	for name, value := range map[string]uint64{
+		"in_octets":  ifCounters.IfInOctets,
+		"out_octets": ifCounters.IfOutOctets,
+	} {
+		if value != sflowUnknownCounter64 {
+			counters.Values[name] = value
+		}
+	}
+	for name, value := range map[string]uint32{
+		"in_unicast_packets":    ifCounters.IfInUcastPkts,
+		"in_multicast_packets":  ifCounters.IfInMulticastPkts,
+		"in_broadcast_packets":  ifCounters.IfInBroadcastPkts,
+		"in_discards":           ifCounters.IfInDiscards,
+		"in_errors":             ifCounters.IfInErrors,
+		"in_unknown_protocols":  ifCounters.IfInUnknownProtos,
+		"out_unicast_packets":   ifCounters.IfOutUcastPkts,
+		"out_multicast_packets": ifCounters.IfOutMulticastPkts,
+		"out_broadcast_packets": ifCounters.IfOutBroadcastPkts,
+		"out_discards":          ifCounters.IfOutDiscards,
+		"out_errors":            ifCounters.IfOutErrors,
+	} {
+		if value != sflowUnknownCounter32 {
+			counters.Values[name] = uint64(value)
+		}
+	}
+	return counters
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package netflowstate
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/netsampler/goflow2/decoders/sflow"
+	flowpb "github.com/netsampler/goflow2/pb"
+	"github.com/netsampler/goflow2/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/testutil"
+)
+
+type capturingFormatDriver struct {
+	messages []interface{}
+}
+
+func (c *capturingFormatDriver) Format(data interface{}) ([]byte, []byte, error) {
+	c.messages = append(c.messages, data)
+	return nil, nil, nil
+}
+
+func TestSFlowState_CounterSamples(t *testing.T) {
+	formatDriver := &capturingFormatDriver{}
+	state := NewStateSFlow()
+	state.Format = formatDriver
+	state.Logger = logrus.StandardLogger()
+	state.initConfig()
+
+	flowData, err := testutil.GetSFlow5CounterPacket()
+	require.NoError(t, err, "error getting sflow packet data")
+
+	err = state.DecodeFlow(utils.BaseMessage{
+		Src:      net.ParseIP("127.0.0.1"),
+		Port:     3000,
+		Payload:  flowData,
+		RecvTime: time.Now(),
+	})
+	require.NoError(t, err, "error handling flow packet")
+
+	var records []*common.ExporterRecord
+	flowCount := 0
+	for _, msg := range formatDriver.messages {
+		switch msgConv := msg.(type) {
+		case *common.ExporterRecord:
+			records = append(records, msgConv)
+		case *flowpb.FlowMessage:
+			flowCount++
+		}
+	}
+	assert.Equal(t, 1, flowCount)
+	require.Len(t, records, 1)
+
+	record := records[0]
+	assert.Equal(t, common.TypeSFlow5, record.FlowType)
+	assert.Equal(t, "10.0.0.20", net.IP(record.ExporterAddr).String())
+	assert.Equal(t, []common.ExporterInterface{
+		{
+			Index: 16,
+			Counters: &common.InterfaceCounters{
+				Speed:       100000000,
+				AdminStatus: 1,
+				OperStatus:  1,
+				Values: map[string]uint64{
+					"in_octets":            438498670,
+					"out_octets":           2645366533,
+					"in_unicast_packets":   2227943,
+					"in_multicast_packets": 0,
+					"in_discards":          0,
+					"in_errors":            0,
+					"out_unicast_packets":  37555479,
+					"out_discards":         685,
+					"out_errors":           0,
+				},
+			},
+		},
+	}, record.Interfaces)
+}
+
+func Test_convertIfCounters_status(t *testing.T) {
+	tests := []struct {
+		ifStatus            uint32
+		expectedAdminStatus uint32
+		expectedOperStatus  uint32
+	}{
+		{ifStatus: 0, expectedAdminStatus: 2, expectedOperStatus: 2},
+		{ifStatus: 1, expectedAdminStatus: 1, expectedOperStatus: 2},
+		{ifStatus: 3, expectedAdminStatus: 1, expectedOperStatus: 1},
+	}
+	for _, tt := range tests {
+		counters := convertIfCounters(sflow.IfCounters{IfStatus: tt.ifStatus, IfSpeed: sflowUnknownCounter64})
+		assert.Equal(t, tt.expectedAdminStatus, counters.AdminStatus)
+		assert.Equal(t, tt.expectedOperStatus, counters.OperStatus)
+		assert.Equal(t, uint64(0), counters.Speed)
+	}
+}
@@ -20,11 +20,26 @@ type Exporter struct {
 
 // Endpoint contains source or destination endpoint details
 type Endpoint struct {
-	IP                 string `json:"ip"`
-	Port               string `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
-	Mac                string `json:"mac"`
-	Mask               string `json:"mask"`
-	ReverseDNSHostname string `json:"reverse_dns_hostname,omitempty"`
+	IP                 string   `json:"ip"`
+	Port               string   `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
+	Mac                string   `json:"mac"`
+	Mask               string   `json:"mask"`
+	ReverseDNSHostname string   `json:"reverse_dns_hostname,omitempty"`
+	AS                 *AS      `json:"as,omitempty"`
+	Geo                *Geo     `json:"geo,omitempty"`
+	Labels             []string `json:"labels,omitempty"`
+}
+
+// AS contains autonomous system details
+type AS struct {
+	Number       uint32 `json:"number"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// Geo contains geolocation details
+type Geo struct {
+	CountryISOCode string `json:"country_iso_code,omitempty"`
+	City           string `json:"city,omitempty"`
 }
 
 // NextHop contains next hop details
@@ -35,6 +50,7 @@ type NextHop struct {
 // Interface contains interface details
 type Interface struct {
 	Index uint32 `json:"index"`
+	Name  string `json:"name,omitempty"`
 }
 
 // ObservationPoint contains ingress or egress observation point
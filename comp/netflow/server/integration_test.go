@@ -10,6 +10,8 @@ package server
 import (
 	"context"
 	"net"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -26,6 +28,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/fx"
 
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 
 	"github.com/DataDog/datadog-agent/comp/ndmtmp/forwarder"
@@ -143,6 +146,48 @@ func TestNetFlow_IntegrationTest_SFlow5(t *testing.T) {
 	assertFlowEventsCount(t, port, srv, packetData, 7)
 }
 
+func TestNetFlow_IntegrationTest_SFlow5CounterSamples(t *testing.T) {
+	port, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	var epForwarder forwarder.MockComponent
+	fxutil.Test[Component](t, fx.Options(
+		testOptions,
+		fx.Populate(&epForwarder),
+		fx.Replace(
+			singleListenerConfig("sflow5", port),
+		),
+		setTimeNow,
+	))
+
+	var metadataMu sync.Mutex
+	var metadataPayloads []string
+	epForwarder.EXPECT().SendEventPlatformEventBlocking(gomock.Any(), eventplatform.EventTypeNetworkDevicesNetFlow).Return(nil).AnyTimes()
+	epForwarder.EXPECT().SendEventPlatformEventBlocking(gomock.Any(), "network-devices-metadata").DoAndReturn(func(m *message.Message, _ string) error {
+		metadataMu.Lock()
+		defer metadataMu.Unlock()
+		metadataPayloads = append(metadataPayloads, string(m.GetContent()))
+		return nil
+	}).AnyTimes()
+
+	packetData, err := testutil.GetSFlow5CounterPacket()
+	require.NoError(t, err, "error getting sflow data")
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		err := testutil.SendUDPPacket(port, packetData)
+		assert.NoError(c, err, "error sending udp packet")
+
+		metadataMu.Lock()
+		defer metadataMu.Unlock()
+		found := false
+		for _, payload := range metadataPayloads {
+			if strings.Contains(payload, `"interfaces":[{"device_id":"default:10.0.0.20","id_tags":null,"index":16,"raw_id":"16","raw_id_type":"sflow5","admin_status":1,"oper_status":1}]`) {
+				found = true
+			}
+		}
+		assert.True(c, found, "interface metadata not found in %v", metadataPayloads)
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
 func TestNetFlow_IntegrationTest_AdditionalFields(t *testing.T) {
 	port, err := ndmtestutils.GetFreePort()
 	require.NoError(t, err)
@@ -201,7 +246,7 @@ func BenchmarkNetflowAdditionalFields(b *testing.B) {
 		}
 	}()
 
-	formatDriver := goflowlib.NewAggregatorFormatDriver(flowChan, "bench", listenerFlowCount)
+	formatDriver := goflowlib.NewAggregatorFormatDriver(flowChan, make(chan *common.ExporterRecord), "bench", listenerFlowCount)
 	logrusLogger := logrus.StandardLogger()
 	ctx := context.Background()
 
@@ -34,6 +34,7 @@ func startFlowListener(listenerConfig config.ListenerConfig, flowAgg *flowaggreg
 		listenerConfig.Namespace,
 		listenerConfig.Mapping,
 		flowAgg.GetFlowInChan(),
+		flowAgg.GetExporterRecordInChan(),
 		logger,
 		listenerAtomicErr,
 		listenerFlowCount)
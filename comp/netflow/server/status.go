@@ -11,6 +11,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/core/status"
 	nfconfig "github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib/netflowstate"
 )
 
 //go:embed status_templates
@@ -31,6 +32,24 @@ type netflowListenerStatus struct {
 	Config    nfconfig.ListenerConfig
 	Error     string
 	FlowCount int64
+	Exporters []netflowExporterStatus
+}
+
+// netflowExporterStatus is the health of a NetFlow v9/IPFIX exporter observation domain
+type netflowExporterStatus struct {
+	ExporterIP          string
+	FlowType            string
+	ObservationDomainID uint32
+	LastSeen            int64
+	Packets             uint64
+	Templates           int
+	MissingTemplates    []uint16
+	DroppedDataSets     uint64
+	SequenceGaps        uint64
+	SequenceLost        uint64
+	Restarts            uint64
+	SamplingRate        uint64
+	SamplingRateChanges uint64
 }
 
 // Provider provides the functionality to populate the status output
@@ -77,9 +96,14 @@ func (p Provider) getStatus(stats map[string]interface{}) {
 				Error:  errorString,
 			})
 		} else {
+			var exporters []netflowExporterStatus
+			if listener.flowState != nil {
+				exporters = getExportersStatus(listener.flowState.ExporterHealth())
+			}
 			workingListeners = append(workingListeners, netflowListenerStatus{
 				Config:    listener.config,
 				FlowCount: listener.flowCount.Load(),
+				Exporters: exporters,
 			})
 		}
 	}
@@ -95,6 +119,28 @@ func (p Provider) getStatus(stats map[string]interface{}) {
 	stats["netflowStats"] = status
 }
 
+func getExportersStatus(exportersHealth []netflowstate.ExporterHealth) []netflowExporterStatus {
+	exporters := make([]netflowExporterStatus, 0, len(exportersHealth))
+	for _, health := range exportersHealth {
+		exporters = append(exporters, netflowExporterStatus{
+			ExporterIP:          health.ExporterIP,
+			FlowType:            string(health.FlowType),
+			ObservationDomainID: health.ObservationDomainID,
+			LastSeen:            health.LastSeen.Unix(),
+			Packets:             health.Packets,
+			Templates:           health.Templates,
+			MissingTemplates:    health.MissingTemplates,
+			DroppedDataSets:     health.DroppedDataSets,
+			SequenceGaps:        health.SequenceGaps,
+			SequenceLost:        health.SequenceLost,
+			Restarts:            health.Restarts,
+			SamplingRate:        health.SamplingRate,
+			SamplingRateChanges: health.SamplingRateChanges,
+		})
+	}
+	return exporters
+}
+
 func (p Provider) populateStatus() map[string]interface{} {
 	stats := make(map[string]interface{})
 
@@ -11,8 +11,12 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
 	nfconfig "github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib"
+	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib/netflowstate"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/atomic"
 )
@@ -141,3 +145,67 @@ func TestStatusProvider(t *testing.T) {
 		})
 	}
 }
+
+type fakeNetFlowState struct {
+	health []netflowstate.ExporterHealth
+}
+
+func (s *fakeNetFlowState) FlowRoutine(_ int, _ string, _ int, _ bool) error { return nil }
+
+func (s *fakeNetFlowState) Shutdown() {}
+
+func (s *fakeNetFlowState) ExporterHealth() []netflowstate.ExporterHealth { return s.health }
+
+func TestStatusProviderExporters(t *testing.T) {
+	state := &fakeNetFlowState{health: []netflowstate.ExporterHealth{
+		{
+			ExporterIP:          "10.0.0.1",
+			FlowType:            common.TypeIPFIX,
+			ObservationDomainID: 3,
+			LastSeen:            time.Now(),
+			Packets:             120,
+			Templates:           2,
+			MissingTemplates:    []uint16{256, 300},
+			DroppedDataSets:     7,
+			SequenceGaps:        2,
+			SequenceLost:        40,
+			Restarts:            1,
+			SamplingRate:        1000,
+			SamplingRateChanges: 1,
+		},
+	}}
+	statusProvider := Provider{
+		server: &Server{
+			listeners: []*netflowListener{
+				{
+					flowState: &goflowlib.FlowStateWrapper{State: state},
+					config:    nfconfig.ListenerConfig{BindHost: "hello", FlowType: "ipfix"},
+					error:     atomic.NewString(""),
+					flowCount: atomic.NewInt64(10),
+				},
+			},
+		},
+	}
+
+	stats := make(map[string]interface{})
+	statusProvider.JSON(false, stats)
+	exporters := stats["netflowStats"].(netflowServerStatus).WorkingListenerDetails[0].Exporters
+	assert.Len(t, exporters, 1)
+	assert.Equal(t, "ipfix", exporters[0].FlowType)
+	assert.Equal(t, []uint16{256, 300}, exporters[0].MissingTemplates)
+
+	b := new(bytes.Buffer)
+	assert.NoError(t, statusProvider.Text(false, b))
+	output := strings.Replace(b.String(), "\r\n", "\n", -1)
+	for _, line := range []string{
+		"  Exporter 10.0.0.1 (ipfix, observation domain 3):\n",
+		"    Packets: 120\n    Templates: 2\n    Missing Templates: [256 300]\n    Data Sets Dropped For Missing Templates: 7\n",
+		"    Sequence Gaps: 2 (40 lost)\n    Restarts: 1\n    Sampling Rate: 1000 (1 changes)\n",
+	} {
+		assert.Contains(t, output, line)
+	}
+
+	b.Reset()
+	assert.NoError(t, statusProvider.HTML(false, b))
+	assert.Contains(t, b.String(), "<br>Missing Templates: [256 300]")
+}
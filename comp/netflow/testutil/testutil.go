@@ -267,3 +267,8 @@ func GetNetFlow9Packet() ([]byte, error) {
 func GetSFlow5Packet() ([]byte, error) {
 	return GetPacketFromPCAP(sflowpcapng, layers.LayerTypeEthernet, 1)
 }
+
+// GetSFlow5CounterPacket parses our saved sflow5 packet containing a counter sample.
+func GetSFlow5CounterPacket() ([]byte, error) {
+	return GetPacketFromPCAP(sflowpcapng, layers.LayerTypeEthernet, 0)
+}
@@ -6,9 +6,11 @@
 package npcollectorimpl
 
 import (
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 )
 
 type collectorConfigs struct {
@@ -25,6 +27,8 @@ type collectorConfigs struct {
 	reverseDNSEnabled            bool
 	reverseDNSTimeout            time.Duration
 	networkDevicesNamespace      string
+	protocol                     payload.Protocol
+	ipv6Enabled                  bool
 }
 
 func newConfig(agentConfig config.Component) *collectorConfigs {
@@ -43,6 +47,8 @@ func newConfig(agentConfig config.Component) *collectorConfigs {
 		reverseDNSEnabled:            agentConfig.GetBool("network_path.collector.reverse_dns_enrichment.enabled"),
 		reverseDNSTimeout:            agentConfig.GetDuration("network_path.collector.reverse_dns_enrichment.timeout") * time.Millisecond,
 		networkDevicesNamespace:      agentConfig.GetString("network_devices.namespace"),
+		protocol:                     payload.Protocol(strings.ToUpper(agentConfig.GetString("network_path.collector.protocol"))),
+		ipv6Enabled:                  agentConfig.GetBool("network_path.collector.ipv6_enabled"),
 	}
 }
 
@@ -134,13 +134,16 @@ func (s *npCollectorImpl) ScheduleConns(conns []*model.Connection) {
 	for _, conn := range conns {
 		remoteAddr := conn.Raddr
 		protocol := convertProtocol(conn.GetType())
+		if s.collectorConfigs.protocol != "" {
+			protocol = s.collectorConfigs.protocol
+		}
 		var remotePort uint16
 		// UDP traces should not be done to the active
-		// port
-		if protocol != payload.ProtocolUDP {
+		// port, and ICMP traces don't use ports
+		if protocol != payload.ProtocolUDP && protocol != payload.ProtocolICMP {
 			remotePort = uint16(conn.Raddr.GetPort())
 		}
-		if !shouldScheduleNetworkPathForConn(conn) {
+		if !shouldScheduleNetworkPathForConn(conn, s.collectorConfigs.ipv6Enabled) {
 			s.logger.Tracef("Skipped connection: addr=%s, port=%d, protocol=%s", remoteAddr, remotePort, protocol)
 			continue
 		}
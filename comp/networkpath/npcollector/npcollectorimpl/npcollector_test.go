@@ -480,6 +480,43 @@ func Test_npCollectorImpl_ScheduleConns(t *testing.T) {
 			},
 			expectedLogs: []logCount{},
 		},
+		{
+			name: "ipv6 enabled",
+			agentConfigs: map[string]any{
+				"network_path.connections_monitoring.enabled": true,
+				"network_path.collector.ipv6_enabled":         true,
+			},
+			conns: []*model.Connection{
+				{
+					Laddr:     &model.Addr{Ip: "fd00::1", Port: int32(30000), ContainerId: "testId1"},
+					Raddr:     &model.Addr{Ip: "fd00::2", Port: int32(80)},
+					Direction: model.ConnectionDirection_outgoing,
+					Family:    model.ConnectionFamily_v6,
+					Type:      model.ConnectionType_tcp,
+				},
+			},
+			expectedPathtests: []*common.Pathtest{
+				{Hostname: "fd00::2", Port: uint16(80), Protocol: payload.ProtocolTCP, SourceContainerID: "testId1"},
+			},
+		},
+		{
+			name: "protocol overridden with ICMP",
+			agentConfigs: map[string]any{
+				"network_path.connections_monitoring.enabled": true,
+				"network_path.collector.protocol":             "icmp",
+			},
+			conns: []*model.Connection{
+				{
+					Laddr:     &model.Addr{Ip: "10.0.0.3", Port: int32(30000), ContainerId: "testId1"},
+					Raddr:     &model.Addr{Ip: "10.0.0.4", Port: int32(80)},
+					Direction: model.ConnectionDirection_outgoing,
+					Type:      model.ConnectionType_tcp,
+				},
+			},
+			expectedPathtests: []*common.Pathtest{
+				{Hostname: "10.0.0.4", Port: uint16(0), Protocol: payload.ProtocolICMP, SourceContainerID: "testId1"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -12,7 +12,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 )
 
-func shouldScheduleNetworkPathForConn(conn *model.Connection) bool {
+func shouldScheduleNetworkPathForConn(conn *model.Connection, ipv6Enabled bool) bool {
 	if conn == nil || conn.Direction != model.ConnectionDirection_outgoing {
 		return false
 	}
@@ -20,7 +20,7 @@ func shouldScheduleNetworkPathForConn(conn *model.Connection) bool {
 	if remoteIP.IsLoopback() || conn.IntraHost {
 		return false
 	}
-	return conn.Family == model.ConnectionFamily_v4
+	return conn.Family == model.ConnectionFamily_v4 || (ipv6Enabled && conn.Family == model.ConnectionFamily_v6)
 }
 
 func convertProtocol(connType model.ConnectionType) payload.Protocol {
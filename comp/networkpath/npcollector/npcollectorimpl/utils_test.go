@@ -17,6 +17,7 @@ func Test_shouldScheduleNetworkPathForConn(t *testing.T) {
 	tests := []struct {
 		name           string
 		conn           *model.Connection
+		ipv6Enabled    bool
 		shouldSchedule bool
 	}{
 		{
@@ -58,6 +59,28 @@ func Test_shouldScheduleNetworkPathForConn(t *testing.T) {
 			},
 			shouldSchedule: false,
 		},
+		{
+			name: "should schedule ipv6 if enabled",
+			conn: &model.Connection{
+				Laddr:     &model.Addr{Ip: "fd00::1", Port: int32(30000)},
+				Raddr:     &model.Addr{Ip: "fd00::2", Port: int32(80)},
+				Direction: model.ConnectionDirection_outgoing,
+				Family:    model.ConnectionFamily_v6,
+			},
+			ipv6Enabled:    true,
+			shouldSchedule: true,
+		},
+		{
+			name: "should not schedule ipv6 loopback",
+			conn: &model.Connection{
+				Laddr:     &model.Addr{Ip: "::1", Port: int32(30000)},
+				Raddr:     &model.Addr{Ip: "::1", Port: int32(80)},
+				Direction: model.ConnectionDirection_outgoing,
+				Family:    model.ConnectionFamily_v6,
+			},
+			ipv6Enabled:    true,
+			shouldSchedule: false,
+		},
 		{
 			name: "should not schedule for loopback",
 			conn: &model.Connection{
@@ -82,7 +105,7 @@ func Test_shouldScheduleNetworkPathForConn(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.shouldSchedule, shouldScheduleNetworkPathForConn(tt.conn))
+			assert.Equal(t, tt.shouldSchedule, shouldScheduleNetworkPathForConn(tt.conn, tt.ipv6Enabled))
 		})
 	}
 }
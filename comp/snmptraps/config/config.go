@@ -39,14 +39,47 @@ type UserV3 struct {
 // TrapsConfig contains configuration for SNMP trap listeners.
 // YAML field tags provided for test marshalling purposes.
 type TrapsConfig struct {
-	Enabled               bool     `mapstructure:"enabled" yaml:"enabled"`
-	Port                  uint16   `mapstructure:"port" yaml:"port"`
-	Users                 []UserV3 `mapstructure:"users" yaml:"users"`
-	CommunityStrings      []string `mapstructure:"community_strings" yaml:"community_strings"`
-	BindHost              string   `mapstructure:"bind_host" yaml:"bind_host"`
-	StopTimeout           int      `mapstructure:"stop_timeout" yaml:"stop_timeout"`
-	Namespace             string   `mapstructure:"namespace" yaml:"namespace"`
-	authoritativeEngineID string   `mapstructure:"-" yaml:"-"`
+	Enabled               bool       `mapstructure:"enabled" yaml:"enabled"`
+	Port                  uint16     `mapstructure:"port" yaml:"port"`
+	Users                 []UserV3   `mapstructure:"users" yaml:"users"`
+	CommunityStrings      []string   `mapstructure:"community_strings" yaml:"community_strings"`
+	BindHost              string     `mapstructure:"bind_host" yaml:"bind_host"`
+	StopTimeout           int        `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	Namespace             string     `mapstructure:"namespace" yaml:"namespace"`
+	Rules                 []TrapRule `mapstructure:"rules" yaml:"rules"`
+	authoritativeEngineID string     `mapstructure:"-" yaml:"-"`
+}
+
+// TrapRule turns the traps matching an OID or a name into a metric and/or an event.
+// Trap is either an OID, a trap name or a `MIB::name` reference.
+type TrapRule struct {
+	Trap   string         `mapstructure:"trap" yaml:"trap"`
+	Metric TrapRuleMetric `mapstructure:"metric" yaml:"metric"`
+	Event  TrapRuleEvent  `mapstructure:"event" yaml:"event"`
+	Tags   []TrapRuleTag  `mapstructure:"tags" yaml:"tags"`
+}
+
+// TrapRuleMetric defines the metric sent for each matching trap. Value references a
+// variable by name or OID, the metric value is 1 when it's not set.
+type TrapRuleMetric struct {
+	Name  string `mapstructure:"name" yaml:"name"`
+	Type  string `mapstructure:"type" yaml:"type"`
+	Value string `mapstructure:"value" yaml:"value"`
+}
+
+// TrapRuleEvent defines the Datadog event sent for each matching trap. Title and Text
+// can reference variables with `{{variable}}` placeholders.
+type TrapRuleEvent struct {
+	Title     string `mapstructure:"title" yaml:"title"`
+	Text      string `mapstructure:"text" yaml:"text"`
+	AlertType string `mapstructure:"alert_type" yaml:"alert_type"`
+	Priority  string `mapstructure:"priority" yaml:"priority"`
+}
+
+// TrapRuleTag adds the value of a trap variable, referenced by name or OID, as a tag.
+type TrapRuleTag struct {
+	Tag      string `mapstructure:"tag" yaml:"tag"`
+	Variable string `mapstructure:"variable" yaml:"variable"`
 }
 
 // ReadConfig builds the traps configuration from the Agent configuration.
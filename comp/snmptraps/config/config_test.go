@@ -290,3 +290,34 @@ func TestNamespaceSetBothGloballyAndLocally(t *testing.T) {
 
 	assert.Equal(t, "bar", config.Namespace)
 }
+
+func TestRules(t *testing.T) {
+	config := fxutil.Test[*TrapsConfig](t,
+		testOptions(t),
+		config.MockModule(),
+		fx.Replace(config.MockParams{Overrides: map[string]interface{}{
+			"network_devices.snmp_traps.rules": []interface{}{
+				map[string]interface{}{
+					"trap":   "IF-MIB::linkDown",
+					"metric": map[string]interface{}{"name": "snmp.traps.link_down"},
+					"tags":   []interface{}{map[string]interface{}{"tag": "interface_index", "variable": "ifIndex"}},
+				},
+				map[string]interface{}{
+					"trap":  "1.3.6.1.4.1.9.9.41.2.0.1",
+					"event": map[string]interface{}{"title": "Syslog from {{device_ip}}", "alert_type": "warning"},
+				},
+			},
+		}}),
+	)
+	assert.Equal(t, []TrapRule{
+		{
+			Trap:   "IF-MIB::linkDown",
+			Metric: TrapRuleMetric{Name: "snmp.traps.link_down"},
+			Tags:   []TrapRuleTag{{Tag: "interface_index", Variable: "ifIndex"}},
+		},
+		{
+			Trap:  "1.3.6.1.4.1.9.9.41.2.0.1",
+			Event: TrapRuleEvent{Title: "Syslog from {{device_ip}}", AlertType: "warning"},
+		},
+	}, config.Rules)
+}
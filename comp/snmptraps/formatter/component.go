@@ -8,6 +8,7 @@ package formatter
 
 import (
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/trap"
 )
 
 // team: ndm-core
@@ -15,4 +16,7 @@ import (
 // Component is the component type.
 type Component interface {
 	FormatPacket(packet *packet.SnmpPacket) ([]byte, error)
+	// FormatTrap formats a trap already parsed from the packet, so that it
+	// can be shared with the trap rules
+	FormatTrap(packet *packet.SnmpPacket, trap *trap.Trap) ([]byte, error)
 }
@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/trap"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
@@ -53,3 +54,8 @@ func (f dummyFormatter) FormatPacket(packet *packet.SnmpPacket) ([]byte, error)
 	hexHash := hex.EncodeToString(h.Sum(nil))
 	return []byte(hexHash), nil
 }
+
+// FormatTrap is a dummy formatter method that hashes the SnmpPacket object the trap was parsed from
+func (f dummyFormatter) FormatTrap(packet *packet.SnmpPacket, _ *trap.Trap) ([]byte, error) {
+	return f.FormatPacket(packet)
+}
@@ -19,7 +19,10 @@ import (
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/forwarder"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/listener"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/rules"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/trap"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
@@ -35,21 +38,25 @@ func Module() fxutil.Module {
 // The trapForwarder is an intermediate step between the listener and the epforwarder in order to limit the processing of the listener
 // to the minimum. The forwarder process payloads received by the listener via the trapsIn channel, formats them and finally
 // give them to the epforwarder for sending it to Datadog.
+// Traps matching a configured rule are also turned into metrics and events.
 type trapForwarder struct {
-	trapsIn   packet.PacketsChannel
-	formatter formatter.Component
-	sender    sender.Sender
-	stopChan  chan struct{}
-	logger    log.Component
+	trapsIn     packet.PacketsChannel
+	formatter   formatter.Component
+	oidResolver oidresolver.Component
+	rules       *rules.Engine
+	sender      sender.Sender
+	stopChan    chan struct{}
+	logger      log.Component
 }
 
 type dependencies struct {
 	fx.In
-	Config    config.Component
-	Formatter formatter.Component
-	Demux     demultiplexer.Component
-	Listener  listener.Component
-	Logger    log.Component
+	Config      config.Component
+	Formatter   formatter.Component
+	OIDResolver oidresolver.Component
+	Demux       demultiplexer.Component
+	Listener    listener.Component
+	Logger      log.Component
 }
 
 // newTrapForwarder creates a simple TrapForwarder instance
@@ -58,14 +65,20 @@ func newTrapForwarder(lc fx.Lifecycle, dep dependencies) (forwarder.Component, e
 	if err != nil {
 		return nil, err
 	}
+	conf := dep.Config.Get()
+	rulesEngine, err := rules.NewEngine(conf.Rules, sender, dep.Logger)
+	if err != nil {
+		return nil, err
+	}
 	tf := &trapForwarder{
-		trapsIn:   dep.Listener.Packets(),
-		formatter: dep.Formatter,
-		sender:    sender,
-		stopChan:  make(chan struct{}, 1),
-		logger:    dep.Logger,
+		trapsIn:     dep.Listener.Packets(),
+		formatter:   dep.Formatter,
+		oidResolver: dep.OIDResolver,
+		rules:       rulesEngine,
+		sender:      sender,
+		stopChan:    make(chan struct{}, 1),
+		logger:      dep.Logger,
 	}
-	conf := dep.Config.Get()
 	if conf.Enabled {
 		lc.Append(fx.Hook{
 			OnStart: func(_ context.Context) error {
@@ -114,7 +127,12 @@ func (tf *trapForwarder) run() {
 }
 
 func (tf *trapForwarder) sendTrap(packet *packet.SnmpPacket) {
-	data, err := tf.formatter.FormatPacket(packet)
+	t, err := trap.Parse(packet, tf.oidResolver, tf.sender, tf.logger)
+	if err != nil {
+		tf.logger.Errorf("failed to format packet: %s", err)
+		return
+	}
+	data, err := tf.formatter.FormatTrap(packet, t)
 	if err != nil {
 		tf.logger.Errorf("failed to format packet: %s", err)
 		return
@@ -122,4 +140,5 @@ func (tf *trapForwarder) sendTrap(packet *packet.SnmpPacket) {
 	tf.logger.Tracef("send trap payload: %s", string(data))
 	tf.sender.Count("datadog.snmp_traps.forwarded", 1, "", packet.GetTags())
 	tf.sender.EventPlatformEvent(data, eventplatform.EventTypeSnmpTraps)
+	tf.rules.Process(packet, t)
 }
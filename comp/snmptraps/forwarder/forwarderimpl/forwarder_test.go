@@ -15,12 +15,14 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/config/configimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter/formatterimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/forwarder"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/listener"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/listener/listenerimpl"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver/oidresolverimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/senderhelper"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
@@ -37,21 +39,23 @@ type services struct {
 	Forwarder forwarder.Component
 }
 
-func setUp(t *testing.T) *services {
+func setUp(t *testing.T, options ...fx.Option) *services {
 	t.Helper()
 	s := fxutil.Test[services](t,
 		configimpl.MockModule(),
 		senderhelper.Opts,
 		formatterimpl.MockModule(),
 		listenerimpl.MockModule(),
+		oidresolverimpl.MockModule(),
 		Module(),
+		fx.Options(options...),
 	)
 	return &s
 }
 
-func makeSnmpPacket(trap gosnmp.SnmpTrap) *packet.SnmpPacket {
+func makeSnmpPacket(version gosnmp.SnmpVersion, trap gosnmp.SnmpTrap) *packet.SnmpPacket {
 	gosnmpPacket := &gosnmp.SnmpPacket{
-		Version:   gosnmp.Version2c,
+		Version:   version,
 		Community: "public",
 		Variables: trap.Variables,
 		SnmpTrap:  trap,
@@ -65,7 +69,7 @@ func makeSnmpPacket(trap gosnmp.SnmpTrap) *packet.SnmpPacket {
 
 func TestV1GenericTrapAreForwarder(t *testing.T) {
 	s := setUp(t)
-	packet := makeSnmpPacket(packet.LinkDownv1GenericTrap)
+	packet := makeSnmpPacket(gosnmp.Version1, packet.LinkDownv1GenericTrap)
 	rawEvent, err := s.Formatter.FormatPacket(packet)
 	require.NoError(t, err)
 	s.Listener.Send(packet)
@@ -75,7 +79,7 @@ func TestV1GenericTrapAreForwarder(t *testing.T) {
 
 func TestV1SpecificTrapAreForwarder(t *testing.T) {
 	s := setUp(t)
-	packet := makeSnmpPacket(packet.AlarmActiveStatev1SpecificTrap)
+	packet := makeSnmpPacket(gosnmp.Version1, packet.AlarmActiveStatev1SpecificTrap)
 	rawEvent, err := s.Formatter.FormatPacket(packet)
 	require.NoError(t, err)
 	s.Listener.Send(packet)
@@ -84,7 +88,7 @@ func TestV1SpecificTrapAreForwarder(t *testing.T) {
 }
 func TestV2TrapAreForwarder(t *testing.T) {
 	s := setUp(t)
-	packet := makeSnmpPacket(packet.NetSNMPExampleHeartbeatNotification)
+	packet := makeSnmpPacket(gosnmp.Version2c, packet.NetSNMPExampleHeartbeatNotification)
 	rawEvent, err := s.Formatter.FormatPacket(packet)
 	require.NoError(t, err)
 	s.Listener.Send(packet)
@@ -94,7 +98,17 @@ func TestV2TrapAreForwarder(t *testing.T) {
 
 func TestForwarderTelemetry(t *testing.T) {
 	s := setUp(t)
-	s.Listener.Send(makeSnmpPacket(packet.NetSNMPExampleHeartbeatNotification))
+	s.Listener.Send(makeSnmpPacket(gosnmp.Version2c, packet.NetSNMPExampleHeartbeatNotification))
 	time.Sleep(100 * time.Millisecond)
 	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.forwarded", 1, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2"})
 }
+
+func TestForwarderRules(t *testing.T) {
+	s := setUp(t, fx.Replace(&config.TrapsConfig{Enabled: true, Rules: []config.TrapRule{{
+		Trap:   "NET-SNMP-EXAMPLES-MIB::netSnmpExampleHeartbeatNotification",
+		Metric: config.TrapRuleMetric{Name: "snmp.heartbeat.rate", Type: "gauge", Value: "netSnmpExampleHeartbeatRate"},
+	}}}))
+	s.Listener.Send(makeSnmpPacket(gosnmp.Version2c, packet.NetSNMPExampleHeartbeatNotification))
+	time.Sleep(100 * time.Millisecond)
+	s.Sender.AssertMetric(t, "Gauge", "snmp.heartbeat.rate", 1024, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2"})
+}
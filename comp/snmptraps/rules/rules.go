@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package rules turns SNMP traps into metrics and events according to user
+// defined rules.
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/trap"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+)
+
+const (
+	metricTypeCount = "count"
+	metricTypeGauge = "gauge"
+
+	eventSourceType = "snmp-traps"
+
+	telemetryRulesMatched  = "datadog.snmp_traps.rules.matched"
+	telemetryRulesNoValue  = "datadog.snmp_traps.rules.metric_without_value"
+	telemetryRulesNotFound = "datadog.snmp_traps.rules.variable_not_found"
+)
+
+var placeholderPattern = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// Engine matches traps against the configured rules and sends the resulting
+// metrics and events.
+type Engine struct {
+	rules  []rule
+	sender sender.Sender
+	logger log.Component
+}
+
+type rule struct {
+	conf      config.TrapRule
+	oid       string
+	mibName   string
+	trapName  string
+	alertType event.AlertType
+	priority  event.Priority
+}
+
+// NewEngine validates the rules and returns an Engine applying them.
+func NewEngine(rules []config.TrapRule, sender sender.Sender, logger log.Component) (*Engine, error) {
+	e := &Engine{
+		sender: sender,
+		logger: logger,
+	}
+	for i, conf := range rules {
+		r, err := newRule(conf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trap rule %d: %w", i, err)
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+func newRule(conf config.TrapRule) (rule, error) {
+	r := rule{conf: conf}
+	trapRef := strings.TrimSpace(conf.Trap)
+	switch {
+	case trapRef == "":
+		return r, fmt.Errorf("`trap` is required")
+	case isOID(trapRef):
+		r.oid = oidresolver.NormalizeOID(trapRef)
+	case strings.Contains(trapRef, "::"):
+		parts := strings.SplitN(trapRef, "::", 2)
+		r.mibName, r.trapName = parts[0], parts[1]
+	default:
+		r.trapName = trapRef
+	}
+
+	if conf.Metric.Name == "" && conf.Event.Title == "" {
+		return r, fmt.Errorf("rule for trap %s defines neither a metric name nor an event title", trapRef)
+	}
+	switch conf.Metric.Type {
+	case "", metricTypeCount, metricTypeGauge:
+	default:
+		return r, fmt.Errorf("unsupported metric type %q, expected %s or %s", conf.Metric.Type, metricTypeCount, metricTypeGauge)
+	}
+	for _, tag := range conf.Tags {
+		if tag.Tag == "" || tag.Variable == "" {
+			return r, fmt.Errorf("rule for trap %s has a tag without `tag` or `variable`", trapRef)
+		}
+	}
+
+	r.alertType = event.AlertTypeInfo
+	if conf.Event.AlertType != "" {
+		alertType, err := event.GetAlertTypeFromString(conf.Event.AlertType)
+		if err != nil {
+			return r, err
+		}
+		r.alertType = alertType
+	}
+	r.priority = event.PriorityNormal
+	if conf.Event.Priority != "" {
+		priority, err := event.GetEventPriorityFromString(conf.Event.Priority)
+		if err != nil {
+			return r, err
+		}
+		r.priority = priority
+	}
+	return r, nil
+}
+
+// Process applies every rule matching the trap parsed from the packet.
+func (e *Engine) Process(p *packet.SnmpPacket, t *trap.Trap) {
+	for _, r := range e.rules {
+		if !r.matches(t) {
+			continue
+		}
+		e.count(telemetryRulesMatched, r, p)
+		tags := e.ruleTags(r, p, t)
+		if r.conf.Metric.Name != "" {
+			e.sendMetric(r, p, t, tags)
+		}
+		if r.conf.Event.Title != "" {
+			e.sendEvent(r, p, t, tags)
+		}
+	}
+}
+
+func (r rule) matches(t *trap.Trap) bool {
+	if r.oid != "" {
+		return r.oid == t.OID
+	}
+	if r.mibName != "" && r.mibName != t.MIBName {
+		return false
+	}
+	return r.trapName == t.Name
+}
+
+func (e *Engine) ruleTags(r rule, p *packet.SnmpPacket, t *trap.Trap) []string {
+	tags := p.GetTags()
+	for _, tag := range r.conf.Tags {
+		v, ok := findVariable(t, tag.Variable)
+		if !ok {
+			e.count(telemetryRulesNotFound, r, p)
+			e.logger.Debugf("variable %s not found in trap %s, skipping tag %s", tag.Variable, t.OID, tag.Tag)
+			continue
+		}
+		tags = append(tags, tag.Tag+":"+display(v))
+	}
+	return tags
+}
+
+func (e *Engine) sendMetric(r rule, p *packet.SnmpPacket, t *trap.Trap, tags []string) {
+	value := 1.0
+	if r.conf.Metric.Value != "" {
+		v, ok := findVariable(t, r.conf.Metric.Value)
+		if !ok {
+			e.count(telemetryRulesNotFound, r, p)
+			e.logger.Debugf("variable %s not found in trap %s, skipping metric %s", r.conf.Metric.Value, t.OID, r.conf.Metric.Name)
+			return
+		}
+		value, ok = float(v)
+		if !ok {
+			e.count(telemetryRulesNoValue, r, p)
+			e.logger.Debugf("variable %s of trap %s is not numeric (%v), skipping metric %s", r.conf.Metric.Value, t.OID, v.Value, r.conf.Metric.Name)
+			return
+		}
+	}
+	if r.conf.Metric.Type == metricTypeGauge {
+		e.sender.Gauge(r.conf.Metric.Name, value, "", tags)
+		return
+	}
+	e.sender.Count(r.conf.Metric.Name, value, "", tags)
+}
+
+func (e *Engine) sendEvent(r rule, p *packet.SnmpPacket, t *trap.Trap, tags []string) {
+	e.sender.Event(event.Event{
+		Title:          expand(t, r.conf.Event.Title, p),
+		Text:           expand(t, r.conf.Event.Text, p),
+		Ts:             p.Timestamp / 1000,
+		Priority:       r.priority,
+		Tags:           tags,
+		AlertType:      r.alertType,
+		AggregationKey: t.OID + ":" + p.Addr.IP.String(),
+		SourceTypeName: eventSourceType,
+	})
+}
+
+// count sends a telemetry metric about a rule
+func (e *Engine) count(name string, r rule, p *packet.SnmpPacket) {
+	e.sender.Count(name, 1, "", append(p.GetTags(), "trap:"+r.conf.Trap))
+}
+
+// expand replaces the `{{variable}}` placeholders of a template. `trap_oid`,
+// `trap_name`, `mib` and `device_ip` are always available.
+func expand(t *trap.Trap, template string, p *packet.SnmpPacket) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		ref := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		switch ref {
+		case "trap_oid":
+			return t.OID
+		case "trap_name":
+			return t.Name
+		case "mib":
+			return t.MIBName
+		case "device_ip":
+			return p.Addr.IP.String()
+		}
+		if v, ok := findVariable(t, ref); ok {
+			return display(v)
+		}
+		return ""
+	})
+}
+
+func isOID(s string) bool {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c != '.' && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
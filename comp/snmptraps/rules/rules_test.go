@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver/oidresolverimpl"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/trap"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+)
+
+var trapDB = oidresolver.TrapDBFileContent{
+	Traps: oidresolver.TrapSpec{
+		"1.3.6.1.6.3.1.1.5.3":      oidresolver.TrapMetadata{Name: "linkDown", MIBName: "IF-MIB"},
+		"1.3.6.1.4.1.8072.2.3.0.1": oidresolver.TrapMetadata{Name: "netSnmpExampleHeartbeatNotification", MIBName: "NET-SNMP-EXAMPLES-MIB"},
+	},
+	Variables: oidresolver.VariableSpec{
+		"1.3.6.1.2.1.2.2.1.1":      oidresolver.VariableMetadata{Name: "ifIndex"},
+		"1.3.6.1.2.1.2.2.1.7":      oidresolver.VariableMetadata{Name: "ifAdminStatus", Enumeration: map[int]string{1: "up", 2: "down", 3: "testing"}},
+		"1.3.6.1.2.1.2.2.1.8":      oidresolver.VariableMetadata{Name: "ifOperStatus", Enumeration: map[int]string{1: "up", 2: "down", 3: "testing"}},
+		"1.3.6.1.4.1.8072.2.3.2.1": oidresolver.VariableMetadata{Name: "netSnmpExampleHeartbeatRate"},
+		"1.3.6.1.4.1.8072.2.3.2.2": oidresolver.VariableMetadata{Name: "netSnmpExampleHeartbeatName"},
+		"1.3.6.1.4.1.8072.2.3.2.3": oidresolver.VariableMetadata{Name: "netSnmpExampleHeartbeatFlags", Bits: map[int]string{0: "primary", 2: "degraded"}},
+	},
+}
+
+var (
+	v1Tags = []string{"snmp_version:1", "device_namespace:the_baron", "snmp_device:127.0.0.1"}
+	v2Tags = []string{"snmp_version:2", "device_namespace:totoro", "snmp_device:127.0.0.1"}
+)
+
+func newTestEngine(t *testing.T, rules ...config.TrapRule) (*Engine, *mocksender.MockSender) {
+	sender := mocksender.NewMockSender("snmp-traps-rules")
+	sender.SetupAcceptAll()
+	e, err := NewEngine(rules, sender, logmock.New(t))
+	require.NoError(t, err)
+	return e, sender
+}
+
+// process parses the packet as the forwarder does, the parsing telemetry
+// is sent to a distinct sender, then applies the rules
+func process(t *testing.T, e *Engine, p *packet.SnmpPacket) {
+	sender := mocksender.NewMockSender("snmp-traps-parse")
+	sender.SetupAcceptAll()
+	parsed, err := trap.Parse(p, oidresolverimpl.NewMockResolver(&trapDB), sender, logmock.New(t))
+	require.NoError(t, err)
+	e.Process(p, parsed)
+}
+
+func TestCountByOID(t *testing.T) {
+	e, sender := newTestEngine(t, config.TrapRule{
+		Trap:   "1.3.6.1.6.3.1.1.5.3",
+		Metric: config.TrapRuleMetric{Name: "snmp.traps.link_down"},
+		Tags: []config.TrapRuleTag{
+			{Tag: "interface_index", Variable: "ifIndex"},
+			{Tag: "oper_status", Variable: ".1.3.6.1.2.1.2.2.1.8"},
+			{Tag: "unknown", Variable: "ifAlias"},
+		},
+	})
+	process(t, e, packet.CreateTestV1GenericPacket())
+
+	sender.AssertMetric(t, "Count", "snmp.traps.link_down", 1, "", append(v1Tags, "interface_index:2", "oper_status:down"))
+	sender.AssertMetricNotTaggedWith(t, "Count", "snmp.traps.link_down", []string{"unknown:"})
+	sender.AssertMetric(t, "Count", telemetryRulesNotFound, 1, "", append(v1Tags, "trap:1.3.6.1.6.3.1.1.5.3"))
+}
+
+func TestGaugeByName(t *testing.T) {
+	e, sender := newTestEngine(t, config.TrapRule{
+		Trap:   "NET-SNMP-EXAMPLES-MIB::netSnmpExampleHeartbeatNotification",
+		Metric: config.TrapRuleMetric{Name: "snmp.traps.heartbeat_rate", Type: "gauge", Value: "netSnmpExampleHeartbeatRate"},
+		Tags:   []config.TrapRuleTag{{Tag: "heartbeat", Variable: "netSnmpExampleHeartbeatName"}},
+	}, config.TrapRule{
+		Trap:   "OTHER-MIB::netSnmpExampleHeartbeatNotification",
+		Metric: config.TrapRuleMetric{Name: "snmp.traps.other"},
+	})
+	process(t, e, packet.CreateTestPacket(packet.NetSNMPExampleHeartbeatNotification))
+
+	sender.AssertMetric(t, "Gauge", "snmp.traps.heartbeat_rate", 1024, "", append(v2Tags, "heartbeat:test"))
+	sender.AssertNotCalled(t, "Count", "snmp.traps.other", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBitsTag(t *testing.T) {
+	e, sender := newTestEngine(t, config.TrapRule{
+		Trap:   "netSnmpExampleHeartbeatNotification",
+		Metric: config.TrapRuleMetric{Name: "snmp.traps.heartbeat"},
+		Tags: []config.TrapRuleTag{
+			{Tag: "flags", Variable: "netSnmpExampleHeartbeatFlags"},
+			{Tag: "flags_oid", Variable: "1.3.6.1.4.1.8072.2.3.2.3"},
+		},
+	})
+	process(t, e, packet.CreateTestPacket(gosnmp.SnmpTrap{Variables: []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+		{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.OctetString, Value: "1.3.6.1.4.1.8072.2.3.0.1"},
+		// bits 0, 2 and 5 are set, 5 has no label
+		{Name: "1.3.6.1.4.1.8072.2.3.2.3", Type: gosnmp.OctetString, Value: []byte{0b10100100}},
+	}}))
+
+	sender.AssertMetric(t, "Count", "snmp.traps.heartbeat", 1, "", append(v2Tags, "flags:primary,degraded,5", "flags_oid:primary,degraded,5"))
+}
+
+func TestNonNumericValue(t *testing.T) {
+	e, sender := newTestEngine(t, config.TrapRule{
+		Trap:   "netSnmpExampleHeartbeatNotification",
+		Metric: config.TrapRuleMetric{Name: "snmp.traps.heartbeat", Value: "netSnmpExampleHeartbeatName"},
+	})
+	process(t, e, packet.CreateTestPacket(packet.NetSNMPExampleHeartbeatNotification))
+
+	sender.AssertNotCalled(t, "Count", "snmp.traps.heartbeat", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertMetric(t, "Count", telemetryRulesNoValue, 1, "", v2Tags)
+}
+
+func TestEvent(t *testing.T) {
+	e, sender := newTestEngine(t, config.TrapRule{
+		Trap: "IF-MIB::linkDown",
+		Event: config.TrapRuleEvent{
+			Title:     "{{trap_name}} on {{ device_ip }}",
+			Text:      "Interface {{ifIndex}} is {{ifOperStatus}} (admin {{ifAdminStatus}}){{missing}}",
+			AlertType: "error",
+		},
+		Tags: []config.TrapRuleTag{{Tag: "interface_index", Variable: "ifIndex"}},
+	})
+	p := packet.CreateTestV1GenericPacket()
+	p.Timestamp = 1700000000123
+	process(t, e, p)
+
+	sender.AssertNumberOfCalls(t, "Event", 1)
+	for _, call := range sender.Calls {
+		if call.Method != "Event" {
+			continue
+		}
+		assert.Equal(t, event.Event{
+			Title:          "linkDown on 127.0.0.1",
+			Text:           "Interface 2 is down (admin up)",
+			Ts:             1700000000,
+			Priority:       event.PriorityNormal,
+			Tags:           append(v1Tags, "interface_index:2"),
+			AlertType:      event.AlertTypeError,
+			AggregationKey: "1.3.6.1.6.3.1.1.5.3:127.0.0.1",
+			SourceTypeName: "snmp-traps",
+		}, call.Arguments.Get(0))
+	}
+}
+
+func TestNoMatch(t *testing.T) {
+	e, sender := newTestEngine(t, config.TrapRule{
+		Trap:   "1.3.6.1.6.3.1.1.5.4",
+		Metric: config.TrapRuleMetric{Name: "snmp.traps.link_up"},
+	})
+	process(t, e, packet.CreateTestV1GenericPacket())
+
+	assert.Empty(t, sender.Calls)
+}
+
+func TestInvalidRules(t *testing.T) {
+	for name, rule := range map[string]config.TrapRule{
+		"missing trap":        {Metric: config.TrapRuleMetric{Name: "foo"}},
+		"no metric nor event": {Trap: "linkDown"},
+		"invalid metric type": {Trap: "linkDown", Metric: config.TrapRuleMetric{Name: "foo", Type: "histogram"}},
+		"invalid alert type":  {Trap: "linkDown", Event: config.TrapRuleEvent{Title: "foo", AlertType: "critical"}},
+		"invalid priority":    {Trap: "linkDown", Event: config.TrapRuleEvent{Title: "foo", Priority: "high"}},
+		"tag without name":    {Trap: "linkDown", Metric: config.TrapRuleMetric{Name: "foo"}, Tags: []config.TrapRuleTag{{Variable: "ifIndex"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEngine([]config.TrapRule{rule}, nil, logmock.New(t))
+			assert.Error(t, err)
+		})
+	}
+}
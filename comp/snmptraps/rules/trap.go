@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/trap"
+)
+
+// findVariable returns the first variable matching a name or an OID. Variables
+// carrying an index match the OID of their column.
+func findVariable(t *trap.Trap, ref string) (trap.Variable, bool) {
+	oid := ""
+	if isOID(ref) {
+		oid = oidresolver.NormalizeOID(ref)
+	}
+	for _, v := range t.Variables {
+		if oid != "" && (v.OID == oid || strings.HasPrefix(v.OID, oid+".")) {
+			return v, true
+		}
+		if oid == "" && v.Name != "" && v.Name == ref {
+			return v, true
+		}
+	}
+	return trap.Variable{}, false
+}
+
+// display returns the value used in tags and event texts, enumerations are
+// resolved and the enabled BITS are joined with commas
+func display(v trap.Variable) string {
+	value := v.Value
+	if v.Enriched {
+		value = v.EnrichedValue
+	}
+	switch value := value.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case []interface{}:
+		bits := make([]string, 0, len(value))
+		for _, bit := range value {
+			bits = append(bits, fmt.Sprint(bit))
+		}
+		return strings.Join(bits, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// float returns the numeric value of the variable, used as metric value
+func float(v trap.Variable) (float64, bool) {
+	switch value := v.Value.(type) {
+	case int:
+		return float64(value), true
+	case uint:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case uint32:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float32:
+		return float64(value), true
+	case float64:
+		return value, true
+	case string:
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	case []byte:
+		f, err := strconv.ParseFloat(string(value), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
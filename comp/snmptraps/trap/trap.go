@@ -0,0 +1,301 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package trap parses SNMP trap packets and enriches their OID and variables
+// with the metadata of the traps database. The result is shared by the
+// formatter and the trap rules.
+package trap
+
+import (
+	"fmt"
+
+	"github.com/gosnmp/gosnmp"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+)
+
+const (
+	genericTrapOID       = "1.3.6.1.6.3.1.1.5"
+	sysUpTimeInstanceOID = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOID          = "1.3.6.1.6.3.1.1.4.1.0"
+
+	telemetryTrapsNotEnriched = "datadog.snmp_traps.traps_not_enriched"
+	telemetryVarsNotEnriched  = "datadog.snmp_traps.vars_not_enriched"
+	telemetryIncorrectFormat  = "datadog.snmp_traps.incorrect_format"
+)
+
+// Trap is an SNMP trap with its OID and variables resolved
+type Trap struct {
+	OID string
+	// Name and MIBName are empty when the trap OID is unknown
+	Name    string
+	MIBName string
+	Uptime  uint32
+
+	// IsV1 is set for SNMPv1 traps, which carry the enterprise OID and the
+	// generic and specific trap numbers instead of a trap OID
+	IsV1          bool
+	EnterpriseOID string
+	GenericTrap   int
+	SpecificTrap  int
+
+	// Variables don't include sysUpTime and snmpTrapOID
+	Variables []Variable
+}
+
+// Variable is a trap variable
+type Variable struct {
+	OID  string
+	Type gosnmp.Asn1BER
+	// Value is the value of the variable in the trap payload: the raw value
+	// for enumerations, the hexadecimal string for BITS, and the formatted
+	// value otherwise
+	Value interface{}
+	// Name is empty when the variable OID is unknown
+	Name string
+	// Enriched is set when the variable was resolved. EnrichedValue is then
+	// the label of enumerations, the labels or positions of the enabled BITS,
+	// and the formatted value otherwise.
+	Enriched      bool
+	EnrichedValue interface{}
+}
+
+// Parse resolves the OID and the variables of the trap of a packet. Traps
+// and variables that can't be resolved, and packets that aren't valid traps,
+// are reported in telemetry.
+func Parse(p *packet.SnmpPacket, oidResolver oidresolver.Component, sender sender.Sender, logger log.Component) (*Trap, error) {
+	tags := p.GetTags()
+	t, variables, err := parseOID(p.Content)
+	if err != nil {
+		sender.Count(telemetryIncorrectFormat, 1, "", append(tags, "error:"+err.reason))
+		return nil, err.err
+	}
+
+	trapMetadata, resolveErr := oidResolver.GetTrapMetadata(t.OID)
+	if resolveErr != nil {
+		sender.Count(telemetryTrapsNotEnriched, 1, "", tags)
+		logger.Debugf("unable to resolve OID: %s", resolveErr)
+	} else {
+		t.Name = trapMetadata.Name
+		t.MIBName = trapMetadata.MIBName
+	}
+
+	enrichmentFailed := 0
+	t.Variables = make([]Variable, 0, len(variables))
+	for _, pdu := range variables {
+		v := parseVariable(t.OID, pdu, oidResolver, logger)
+		if !v.Enriched {
+			enrichmentFailed++
+		}
+		t.Variables = append(t.Variables, v)
+	}
+	if enrichmentFailed > 0 {
+		sender.Count(telemetryVarsNotEnriched, float64(enrichmentFailed), "", tags)
+	}
+
+	return t, nil
+}
+
+// formatError is returned when a trap doesn't have the expected variables,
+// reason is used as telemetry tag
+type formatError struct {
+	reason string
+	err    error
+}
+
+// parseOID returns the trap with its OID and uptime, and its variables
+// without sysUpTime and snmpTrapOID
+func parseOID(content *gosnmp.SnmpPacket) (*Trap, []gosnmp.SnmpPDU, *formatError) {
+	if content.Version == gosnmp.Version1 {
+		t := &Trap{
+			Uptime:        uint32(content.Timestamp),
+			IsV1:          true,
+			EnterpriseOID: oidresolver.NormalizeOID(content.Enterprise),
+			GenericTrap:   content.GenericTrap,
+			SpecificTrap:  content.SpecificTrap,
+		}
+		if t.GenericTrap == 6 {
+			// Vendor-specific trap
+			t.OID = fmt.Sprintf("%s.0.%d", t.EnterpriseOID, t.SpecificTrap)
+		} else {
+			// Generic trap
+			t.OID = fmt.Sprintf("%s.%d", genericTrapOID, t.GenericTrap+1)
+		}
+		return t, content.Variables, nil
+	}
+
+	/*
+		An SNMP v2 or v3 trap packet consists in the following variables (PDUs):
+		{sysUpTime.0, snmpTrapOID.0, additionalDataVariables...}
+		See: https://tools.ietf.org/html/rfc3416#section-4.2.6
+	*/
+	variables := content.Variables
+	if len(variables) < 2 {
+		return nil, nil, &formatError{"invalid_variables", fmt.Errorf("expected at least 2 variables, got %d", len(variables))}
+	}
+
+	uptime, err := parseSysUpTime(variables[0])
+	if err != nil {
+		return nil, nil, &formatError{"invalid_sys_uptime", err}
+	}
+
+	trapOID, err := parseSnmpTrapOID(variables[1])
+	if err != nil {
+		return nil, nil, &formatError{"invalid_trap_oid", err}
+	}
+
+	return &Trap{OID: trapOID, Uptime: uptime}, variables[2:], nil
+}
+
+func parseSysUpTime(variable gosnmp.SnmpPDU) (uint32, error) {
+	name := oidresolver.NormalizeOID(variable.Name)
+	if name != sysUpTimeInstanceOID {
+		return 0, fmt.Errorf("expected OID %s, got %s", sysUpTimeInstanceOID, name)
+	}
+
+	value, ok := variable.Value.(uint32)
+	if !ok {
+		return 0, fmt.Errorf("expected uptime to be uint32 (got %v of type %T)", variable.Value, variable.Value)
+	}
+
+	return value, nil
+}
+
+func parseSnmpTrapOID(variable gosnmp.SnmpPDU) (string, error) {
+	name := oidresolver.NormalizeOID(variable.Name)
+	if name != snmpTrapOID {
+		return "", fmt.Errorf("expected OID %s, got %s", snmpTrapOID, name)
+	}
+
+	value := ""
+	switch variable.Value.(type) {
+	case string:
+		value = variable.Value.(string)
+	case []byte:
+		value = string(variable.Value.([]byte))
+	default:
+		return "", fmt.Errorf("expected snmpTrapOID to be a string (got %v of type %T)", variable.Value, variable.Value)
+	}
+
+	return oidresolver.NormalizeOID(value), nil
+}
+
+func parseVariable(trapOID string, variable gosnmp.SnmpPDU, oidResolver oidresolver.Component, logger log.Component) Variable {
+	v := Variable{
+		OID:   oidresolver.NormalizeOID(variable.Name),
+		Type:  variable.Type,
+		Value: variable.Value,
+	}
+
+	varMetadata, err := oidResolver.GetVariableMetadata(trapOID, v.OID)
+	if err != nil {
+		logger.Debugf("unable to enrich variable: %s", err)
+		v.Value = formatValue(variable)
+		return v
+	}
+	v.Name = varMetadata.Name
+
+	if len(varMetadata.Enumeration) > 0 && len(varMetadata.Bits) > 0 {
+		logger.Errorf("Unable to enrich variable, trap variable %q has mappings for both integer enum and bits.", varMetadata.Name)
+	} else if len(varMetadata.Enumeration) > 0 {
+		v.Enriched = true
+		v.EnrichedValue = enrichEnum(variable, varMetadata, logger)
+	} else if len(varMetadata.Bits) > 0 {
+		var hexString string
+		v.Enriched = true
+		v.EnrichedValue, hexString = enrichBits(variable, varMetadata, logger)
+		if hexString != "" {
+			v.Value = hexString
+		}
+	} else {
+		// only format the value if it's not an enum type
+		v.Value = formatValue(variable)
+		v.Enriched = true
+		v.EnrichedValue = v.Value
+	}
+
+	return v
+}
+
+// enrichEnum checks to see if the variable has a mapping in an enum and
+// returns the mapping if it exists, otherwise returns the value unchanged
+func enrichEnum(variable gosnmp.SnmpPDU, varMetadata oidresolver.VariableMetadata, logger log.Component) interface{} {
+	// if we find a mapping set it and return
+	i, ok := variable.Value.(int)
+	if !ok {
+		logger.Warnf("unable to enrich variable %q %s with integer enum, received value was not int, was %T", varMetadata.Name, variable.Name, variable.Value)
+		return variable.Value
+	}
+	if value, ok := varMetadata.Enumeration[i]; ok {
+		return value
+	}
+
+	// if no mapping is found or type is not integer
+	logger.Debugf("unable to find enum mapping for value %d variable %q", i, varMetadata.Name)
+	return variable.Value
+}
+
+// enrichBits checks to see if the variable has a mapping in bits, if so returns the mapping
+// and hex string of bits, if not returns the value unchanged and empty string
+func enrichBits(variable gosnmp.SnmpPDU, varMetadata oidresolver.VariableMetadata, logger log.Component) (interface{}, string) {
+	// do bitwise search
+	bytes, ok := variable.Value.([]byte)
+	if !ok {
+		logger.Warnf("unable to enrich variable %q %s with BITS mapping, received value was not []byte, was %T", varMetadata.Name, variable.Name, variable.Value)
+		return variable.Value, ""
+	}
+	enabledValues := make([]interface{}, 0)
+	for i, b := range bytes {
+		for j := 0; j < 8; j++ {
+			position := j + i*8 // position is the index in the current byte plus 8 * the position in the byte array
+			enabled, err := isBitEnabled(uint8(b), j)
+			if err != nil {
+				logger.Debugf("unable to determine status at position %d: %s", position, err.Error())
+				continue
+			}
+			if enabled {
+				if value, ok := varMetadata.Bits[position]; !ok {
+					logger.Debugf("unable to find enum mapping for value %d variable %q", i, varMetadata.Name)
+					enabledValues = append(enabledValues, position)
+				} else {
+					enabledValues = append(enabledValues, value)
+				}
+			}
+		}
+	}
+
+	hexString := fmt.Sprintf("0x%X", bytes)
+	return enabledValues, hexString
+}
+
+func formatValue(variable gosnmp.SnmpPDU) interface{} {
+	switch variable.Value.(type) {
+	case []byte:
+		return string(variable.Value.([]byte))
+	case string:
+		if variable.Type == gosnmp.ObjectIdentifier {
+			return oidresolver.NormalizeOID(variable.Value.(string))
+		}
+		return variable.Value
+	default:
+		return variable.Value
+	}
+}
+
+// isBitEnabled takes in a uint8 and returns true if
+// the bit at the passed position is 1.
+// Each byte is little endian meaning if
+// you have the binary 10000000, passing position 0
+// would return true and 7 would return false
+func isBitEnabled(n uint8, pos int) (bool, error) {
+	if pos < 0 || pos > 7 {
+		return false, fmt.Errorf("invalid position %d, must be 0-7", pos)
+	}
+	val := n & uint8(1<<(7-pos))
+	return val > 0, nil
+}
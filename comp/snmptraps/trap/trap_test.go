@@ -0,0 +1,323 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package trap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver/oidresolverimpl"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+var trapDB = oidresolver.TrapDBFileContent{
+	Traps: oidresolver.TrapSpec{
+		"1.3.6.1.6.3.1.1.5.3": oidresolver.TrapMetadata{Name: "linkDown", MIBName: "IF-MIB"},
+	},
+	Variables: oidresolver.VariableSpec{
+		"1.3.6.1.2.1.2.2.1.1": oidresolver.VariableMetadata{Name: "ifIndex"},
+		"1.3.6.1.2.1.2.2.1.7": oidresolver.VariableMetadata{Name: "ifAdminStatus", Enumeration: map[int]string{1: "up", 2: "down", 3: "testing"}},
+	},
+}
+
+func TestParseV1(t *testing.T) {
+	sender := mocksender.NewMockSender("snmp-traps")
+	sender.SetupAcceptAll()
+	p := packet.CreateTestV1GenericPacket()
+
+	parsed, err := Parse(p, oidresolverimpl.NewMockResolver(&trapDB), sender, logmock.New(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.3.6.1.6.3.1.1.5.3", parsed.OID)
+	assert.Equal(t, "linkDown", parsed.Name)
+	assert.Equal(t, "IF-MIB", parsed.MIBName)
+	assert.True(t, parsed.IsV1)
+	require.Len(t, parsed.Variables, len(p.Content.Variables))
+	assert.Equal(t, Variable{OID: "1.3.6.1.2.1.2.2.1.1", Type: gosnmp.Integer, Value: 2, Name: "ifIndex", Enriched: true, EnrichedValue: 2}, parsed.Variables[0])
+	assert.Equal(t, Variable{OID: "1.3.6.1.2.1.2.2.1.7", Type: gosnmp.Integer, Value: 1, Name: "ifAdminStatus", Enriched: true, EnrichedValue: "up"}, parsed.Variables[1])
+	assert.False(t, parsed.Variables[2].Enriched)
+	sender.AssertMetric(t, "Count", telemetryVarsNotEnriched, 2, "", p.GetTags())
+}
+
+func TestParseInvalidTrap(t *testing.T) {
+	sender := mocksender.NewMockSender("snmp-traps")
+	sender.SetupAcceptAll()
+	p := packet.CreateTestPacket(gosnmp.SnmpTrap{Variables: []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+		{Name: "1.3.6.1.2.1.2.2.1.1", Type: gosnmp.Integer, Value: 2},
+	}})
+
+	_, err := Parse(p, oidresolverimpl.NewMockResolver(&trapDB), sender, logmock.New(t))
+	require.Error(t, err)
+	sender.AssertMetric(t, "Count", telemetryIncorrectFormat, 1, "", append(p.GetTags(), "error:invalid_trap_oid"))
+}
+
+func TestIsBitEnabled(t *testing.T) {
+	data := []struct {
+		description string
+		input       byte
+		position    int
+		expected    bool
+		errMsg      string
+	}{
+		{
+			description: "negative position should error",
+			input:       0xff,
+			position:    -1,
+			expected:    false,
+			errMsg:      "invalid position",
+		},
+		{
+			description: "position >7 should error",
+			input:       0xff,
+			position:    8,
+			expected:    false,
+			errMsg:      "invalid position",
+		},
+		{
+			description: "position 7 unset should return false",
+			input:       0xfe, // 1111 1110
+			position:    7,
+			expected:    false,
+			errMsg:      "",
+		},
+		{
+			description: "position 7 set should return true",
+			input:       0x01, // 0000 0001
+			position:    7,
+			expected:    true,
+			errMsg:      "",
+		},
+		{
+			description: "position 0 unset should return false",
+			input:       0x7f, // 0111 1111
+			position:    0,
+			expected:    false,
+			errMsg:      "",
+		},
+		{
+			description: "position 0 set should return true",
+			input:       0x80, // 1000 0000
+			position:    0,
+			expected:    true,
+			errMsg:      "",
+		},
+		{
+			description: "position 3 unset should return false",
+			input:       0xef, // 1110 1111
+			position:    3,
+			expected:    false,
+			errMsg:      "",
+		},
+		{
+			description: "position 3 set should return true",
+			input:       0x10, // 0001 0000
+			position:    3,
+			expected:    true,
+			errMsg:      "",
+		},
+		{
+			description: "position 4 unset should return false",
+			input:       0xf7, // 1110 1111
+			position:    4,
+			expected:    false,
+			errMsg:      "",
+		},
+		{
+			description: "position 4 set should return true",
+			input:       0x08, // 0000 1000
+			position:    4,
+			expected:    true,
+			errMsg:      "",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.description, func(t *testing.T) {
+			actual, err := isBitEnabled(d.input, d.position)
+			var errMsg string
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if !strings.Contains(errMsg, d.errMsg) {
+				t.Errorf("error message mismatch, wanted %q, got %q", d.errMsg, errMsg)
+			}
+
+			if actual != d.expected {
+				t.Errorf("result mismatch, wanted %t, got %t", d.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEnrichBits(t *testing.T) {
+	logger := logmock.New(t)
+	data := []struct {
+		description     string
+		variable        gosnmp.SnmpPDU
+		varMetadata     oidresolver.VariableMetadata
+		expectedMapping interface{}
+		expectedHex     string
+	}{
+		{
+			description: "all bits are enrichable and are enriched",
+			variable:    gosnmp.SnmpPDU{Name: ".1.4.3.6.7.3.4.1.4.7", Type: gosnmp.OctetString, Value: []byte{0b11000100, 0b10000001}}, // made up OID, bits 0, 1, 5, 8, and 15 set
+			varMetadata: oidresolver.VariableMetadata{
+				Name: "myDummyVariable",
+				Bits: map[int]string{
+					0:  "test0",
+					1:  "test1",
+					2:  "test2",
+					5:  "test5",
+					8:  "test8",
+					15: "test15",
+				},
+			},
+			expectedMapping: []interface{}{
+				"test0",
+				"test1",
+				"test5",
+				"test8",
+				"test15",
+			},
+			expectedHex: "0xC481",
+		},
+		{
+			description: "no bits are enrichable are returned unenriched",
+			variable:    gosnmp.SnmpPDU{Name: ".1.4.3.6.7.3.4.1.4.7", Type: gosnmp.OctetString, Value: []byte{0b11000100, 0b10000001}}, // made up OID, bits 0, 1, 5, 8, and 15 set
+			varMetadata: oidresolver.VariableMetadata{
+				Name: "myDummyVariable",
+				Bits: map[int]string{
+					2:  "test2",
+					4:  "test4",
+					6:  "test6",
+					14: "test14",
+				},
+			},
+			expectedMapping: []interface{}{
+				0,
+				1,
+				5,
+				8,
+				15,
+			},
+			expectedHex: "0xC481",
+		},
+		{
+			description: "mix of enrichable and unenrichable bits are returned semi-enriched",
+			variable:    gosnmp.SnmpPDU{Name: ".1.4.3.6.7.3.4.1.4.7", Type: gosnmp.OctetString, Value: []byte{0b00111000, 0b01000010}}, // made up OID, bits 2, 3, 4, 9, 14 are set
+			varMetadata: oidresolver.VariableMetadata{
+				Name: "myDummyVariable",
+				Bits: map[int]string{
+					2:  "test2",
+					4:  "test4",
+					6:  "test6",
+					14: "test14",
+				},
+			},
+			expectedMapping: []interface{}{
+				"test2",
+				3,
+				"test4",
+				9,
+				"test14",
+			},
+			expectedHex: "0x3842",
+		},
+		{
+			description: "non-byte array value returns original value unchanged",
+			variable:    gosnmp.SnmpPDU{Name: ".1.4.3.6.7.3.4.1.4.7", Type: gosnmp.OctetString, Value: 42},
+			varMetadata: oidresolver.VariableMetadata{
+				Name: "myDummyVariable",
+				Bits: map[int]string{
+					2:  "test2",
+					4:  "test4",
+					6:  "test6",
+					14: "test14",
+				},
+			},
+			expectedMapping: 42,
+			expectedHex:     "",
+		},
+		{
+			description: "completely zeroed out bits returns zeroed out bits",
+			variable:    gosnmp.SnmpPDU{Name: ".1.4.3.6.7.3.4.1.4.7", Type: gosnmp.OctetString, Value: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+			varMetadata: oidresolver.VariableMetadata{
+				Name: "myDummyVariable",
+				Bits: map[int]string{
+					2:  "test2",
+					4:  "test4",
+					6:  "test6",
+					14: "test14",
+				},
+			},
+			expectedMapping: []interface{}{},
+			expectedHex:     "0x000000000000",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.description, func(t *testing.T) {
+			actualMapping, actualHex := enrichBits(d.variable, d.varMetadata, logger)
+			if diff := cmp.Diff(d.expectedMapping, actualMapping); diff != "" {
+				t.Error(diff)
+			}
+			require.Equal(t, d.expectedHex, actualHex)
+		})
+	}
+}
+
+func TestVariableValueFormat(t *testing.T) {
+	data := []struct {
+		description string
+		variable    gosnmp.SnmpPDU
+		expected    interface{}
+	}{
+
+		{
+			description: "type integer is correctly formatted",
+			variable:    gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.200.1.3.1.7", Type: gosnmp.Integer, Value: 10},
+			expected:    10,
+		},
+		{
+			description: "type OID is normalized",
+			variable:    gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.200.1.3.1.7", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.4"},
+			expected:    "1.3.6.1.6.3.1.1.5.4",
+		},
+		{
+			description: "type OID is normalized only if necessary",
+			variable:    gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.200.1.3.1.7", Type: gosnmp.ObjectIdentifier, Value: "1.3.6.1.6.3.1.1.5.4"},
+			expected:    "1.3.6.1.6.3.1.1.5.4",
+		},
+		{
+			description: "type OID with incorrect value",
+			variable:    gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.200.1.3.1.7", Type: gosnmp.ObjectIdentifier, Value: 1},
+			expected:    1,
+		},
+		{
+			description: "[]byte values are converted to string",
+			variable:    gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.200.1.3.1.7", Type: gosnmp.OctetString, Value: []byte{0x74, 0x65, 0x73, 0x74}},
+			expected:    "test",
+		},
+		{
+			description: "[]byte value is not normalized",
+			variable:    gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.200.1.3.1.7", Type: gosnmp.OctetString, Value: []byte{0x2e, 0x74, 0x65, 0x73, 0x74}},
+			expected:    ".test",
+		},
+	}
+
+	for _, d := range data {
+		require.Equal(t, d.expected, formatValue(d.variable), d.description)
+	}
+}
@@ -45,6 +45,7 @@ type cliParams struct {
 
 	checkName string
 	force     bool
+	dryRun    bool
 	checkID   string
 }
 
@@ -86,6 +87,7 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 	}
 
 	rebalanceCmd.Flags().BoolVarP(&cliParams.force, "force", "f", false, "Use to force rebalance")
+	rebalanceCmd.Flags().BoolVarP(&cliParams.dryRun, "dry-run", "", false, "Only print the checks that would be moved")
 
 	cmd.AddCommand(rebalanceCmd)
 
@@ -128,7 +130,11 @@ func run(_ log.Component, _ config.Component, cliParams *cliParams) error {
 
 func rebalance(_ log.Component, config config.Component, cliParams *cliParams) error {
 
-	fmt.Println("Requesting a cluster check rebalance...")
+	if cliParams.dryRun {
+		fmt.Println("Simulating a cluster check rebalance...")
+	} else {
+		fmt.Println("Requesting a cluster check rebalance...")
+	}
 	c := util.GetClient(false) // FIX: get certificates right then make this true
 	urlstr := fmt.Sprintf("https://localhost:%v/api/v1/clusterchecks/rebalance", pkgconfigsetup.Datadog().GetInt("cluster_agent.cmd_port"))
 
@@ -140,7 +146,8 @@ func rebalance(_ log.Component, config config.Component, cliParams *cliParams) e
 
 	// Construct the POST payload.
 	payload := map[string]bool{
-		"force": cliParams.force,
+		"force":   cliParams.force,
+		"dry_run": cliParams.dryRun,
 	}
 	postData, err := json.Marshal(payload)
 
@@ -168,11 +175,17 @@ func rebalance(_ log.Component, config config.Component, cliParams *cliParams) e
 	checksMoved := make([]types.RebalanceResponse, 0)
 	json.Unmarshal(r, &checksMoved) //nolint:errcheck
 
-	fmt.Printf("%d cluster checks rebalanced successfully\n", len(checksMoved))
+	verb := "moved"
+	if cliParams.dryRun {
+		verb = "would move"
+		fmt.Printf("%d cluster checks would be rebalanced\n", len(checksMoved))
+	} else {
+		fmt.Printf("%d cluster checks rebalanced successfully\n", len(checksMoved))
+	}
 
 	for _, check := range checksMoved {
-		fmt.Printf("Check %s with weight %d moved from node %s to %s. source diff: %d, dest diff: %d\n",
-			check.CheckID, check.CheckWeight, check.SourceNodeName, check.DestNodeName, check.SourceDiff, check.DestDiff)
+		fmt.Printf("Check %s with weight %d %s from node %s to %s. source diff: %d, dest diff: %d\n",
+			check.CheckID, check.CheckWeight, verb, check.SourceNodeName, check.DestNodeName, check.SourceDiff, check.DestDiff)
 	}
 
 	return nil
@@ -45,6 +45,23 @@ func TestRebalance(t *testing.T) {
 		func() {})
 }
 
+func TestRebalanceDryRun(t *testing.T) {
+	commands := []*cobra.Command{
+		MakeCommand(func() GlobalParams {
+			return GlobalParams{}
+		}),
+	}
+
+	fxutil.TestOneShotSubcommand(t,
+		commands,
+		[]string{"clusterchecks", "rebalance", "--dry-run"},
+		rebalance,
+		func(cliParams *cliParams, _ core.BundleParams) {
+			require.True(t, cliParams.dryRun)
+			require.False(t, cliParams.force)
+		})
+}
+
 func TestIsolate(t *testing.T) {
 	commands := []*cobra.Command{
 		MakeCommand(func() GlobalParams {
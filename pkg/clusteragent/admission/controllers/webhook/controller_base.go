@@ -32,6 +32,7 @@ import (
 	configWebhook "github.com/DataDog/datadog-agent/pkg/clusteragent/admission/mutate/config"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/mutate/cwsinstrumentation"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/mutate/tagsfromlabels"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/validate/hygiene"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -104,8 +105,9 @@ func (c *controllerBase) generateWebhooks(wmeta workloadmeta.Component, pa workl
 
 	// Add Validating webhooks.
 	if c.config.isValidationEnabled() {
-		// Future validating webhooks can be added here.
-		validatingWebhooks = []Webhook{}
+		validatingWebhooks = []Webhook{
+			hygiene.NewWebhook(datadogConfig),
+		}
 		webhooks = append(webhooks, validatingWebhooks...)
 	}
 
@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package hygiene
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	adutils "github.com/DataDog/datadog-agent/comp/core/autodiscovery/common/utils"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/common"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
+)
+
+// standardTags maps the unified service tagging tags to their pod label and
+// environment variable.
+var standardTags = map[string]struct {
+	label  string
+	envVar string
+}{
+	"env":     {kubernetes.EnvTagLabelKey, kubernetes.EnvTagEnvVar},
+	"service": {kubernetes.ServiceTagLabelKey, kubernetes.ServiceTagEnvVar},
+	"version": {kubernetes.VersionTagLabelKey, kubernetes.VersionTagEnvVar},
+}
+
+// violations returns the list of problems found in the pod, sorted for
+// stable responses.
+func (w *Webhook) violations(pod *corev1.Pod) []string {
+	if pod.Labels[common.EnabledLabelKey] == "false" {
+		return nil
+	}
+
+	var violations []string
+
+	if w.checkUST {
+		violations = append(violations, w.unifiedServiceTaggingViolations(pod)...)
+	}
+	if w.checkADAnnotations {
+		violations = append(violations, adAnnotationsViolations(pod)...)
+	}
+	if w.checkAPMDogStatsD {
+		violations = append(violations, apmDogStatsDViolations(pod)...)
+	}
+
+	sort.Strings(violations)
+	return violations
+}
+
+// unifiedServiceTaggingViolations checks that the pod has the required
+// tags.datadoghq.com labels, and that the DD_ENV, DD_SERVICE and DD_VERSION
+// environment variables of its containers don't contradict them.
+func (w *Webhook) unifiedServiceTaggingViolations(pod *corev1.Pod) []string {
+	var violations []string
+
+	for _, tag := range w.requiredTags {
+		st, ok := standardTags[tag]
+		if !ok {
+			continue
+		}
+		if pod.Labels[st.label] == "" {
+			violations = append(violations, fmt.Sprintf("missing label %s", st.label))
+		}
+	}
+
+	for _, container := range allContainers(pod) {
+		for _, st := range standardTags {
+			label, found := pod.Labels[st.label]
+			if !found {
+				continue
+			}
+
+			for _, env := range container.Env {
+				if env.Name == st.envVar && env.ValueFrom == nil && env.Value != label {
+					violations = append(violations, fmt.Sprintf("container %s: %s=%q doesn't match label %s=%q", container.Name, st.envVar, env.Value, st.label, label))
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// adAnnotationsViolations checks that the autodiscovery annotations of the
+// pod target existing containers and hold valid check and log
+// configurations.
+func adAnnotationsViolations(pod *corev1.Pod) []string {
+	var violations []string
+
+	containerIdentifiers := make(map[string]struct{})
+	containerNames := make(map[string]struct{})
+	for _, container := range allContainers(pod) {
+		containerNames[container.Name] = struct{}{}
+		containerIdentifiers[container.Name] = struct{}{}
+		if id, found := adutils.ExtractCheckIDFromPodAnnotations(pod.Annotations, container.Name); found {
+			containerIdentifiers[id] = struct{}{}
+		}
+	}
+
+	for _, err := range adutils.ValidateAnnotationsMatching(pod.Annotations, containerIdentifiers, containerNames) {
+		violations = append(violations, err.Error())
+	}
+
+	for id := range containerIdentifiers {
+		_, errs := adutils.ExtractTemplatesFromAnnotations(pod.Name, pod.Annotations, id)
+		for _, err := range errs {
+			violations = append(violations, fmt.Sprintf("invalid autodiscovery annotations for %s: %v", id, err))
+		}
+	}
+
+	return violations
+}
+
+// apmDogStatsDViolations checks the format of the environment variables
+// configuring how tracers and DogStatsD clients reach the Agent.
+func apmDogStatsDViolations(pod *corev1.Pod) []string {
+	var violations []string
+
+	for _, container := range allContainers(pod) {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil {
+				continue
+			}
+
+			var err error
+			switch env.Name {
+			case "DD_AGENT_HOST":
+				err = validateHost(env.Value)
+			case "DD_TRACE_AGENT_PORT", "DD_DOGSTATSD_PORT":
+				err = validatePort(env.Value)
+			case "DD_TRACE_AGENT_URL":
+				err = validateURL(env.Value, "http", "https", "unix")
+			case "DD_DOGSTATSD_URL":
+				err = validateURL(env.Value, "udp", "unix", "unixgram")
+			default:
+				continue
+			}
+
+			if err != nil {
+				violations = append(violations, fmt.Sprintf("container %s: invalid %s %q: %v", container.Name, env.Name, env.Value, err))
+			}
+		}
+	}
+
+	return violations
+}
+
+func validateHost(host string) error {
+	switch {
+	case host == "":
+		return fmt.Errorf("empty host")
+	case strings.Contains(host, "://"):
+		return fmt.Errorf("expected a host, not a URL")
+	case strings.ContainsAny(host, " /"):
+		return fmt.Errorf("expected a host")
+	}
+	return nil
+}
+
+func validatePort(port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return fmt.Errorf("not a number")
+	}
+	if p < 1 || p > 65535 {
+		return fmt.Errorf("out of range")
+	}
+	return nil
+}
+
+func validateURL(rawURL string, schemes ...string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	supported := false
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("unsupported scheme %q, expected one of %s", u.Scheme, strings.Join(schemes, ", "))
+	}
+
+	if strings.HasPrefix(u.Scheme, "unix") {
+		if u.Path == "" {
+			return fmt.Errorf("missing socket path")
+		}
+	} else if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+
+	return nil
+}
+
+func allContainers(pod *corev1.Pod) []corev1.Container {
+	containers := make([]corev1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	return containers
+}
@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+// Package hygiene implements the validating webhook that checks that pods
+// follow the unified service tagging conventions and carry a valid Datadog
+// configuration: well-formed autodiscovery annotations and DogStatsD/APM
+// environment variables.
+package hygiene
+
+import (
+	"fmt"
+	"strings"
+
+	admiv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/DataDog/datadog-agent/cmd/cluster-agent/admission"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/common"
+	validatecommon "github.com/DataDog/datadog-agent/pkg/clusteragent/admission/validate/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	webhookName = "hygiene"
+
+	// namespaceNameLabelKey is the label set by Kubernetes on every
+	// namespace with the name of the namespace.
+	namespaceNameLabelKey = "kubernetes.io/metadata.name"
+)
+
+// Mode defines how the webhook reacts to the violations it finds
+type Mode string
+
+const (
+	// ModeEnforce rejects the pods with violations
+	ModeEnforce Mode = "enforce"
+	// ModeWarn admits the pods with violations and returns the violations
+	// as warnings to the client
+	ModeWarn Mode = "warn"
+	// ModeDryRun admits the pods with violations and only reports them in
+	// the Cluster Agent logs and telemetry
+	ModeDryRun Mode = "dry_run"
+)
+
+// Webhook is the webhook that validates the Datadog configuration of pods
+type Webhook struct {
+	name       string
+	isEnabled  bool
+	endpoint   string
+	resources  []string
+	operations []admissionregistrationv1.OperationType
+
+	mode               Mode
+	enabledNamespaces  []string
+	disabledNamespaces []string
+
+	checkUST           bool
+	requiredTags       []string
+	checkADAnnotations bool
+	checkAPMDogStatsD  bool
+}
+
+// NewWebhook returns a new Webhook
+func NewWebhook(datadogConfig config.Component) *Webhook {
+	mode := Mode(datadogConfig.GetString("admission_controller.validation.hygiene.mode"))
+	switch mode {
+	case ModeEnforce, ModeWarn, ModeDryRun:
+	default:
+		log.Warnf("Invalid admission_controller.validation.hygiene.mode %q, defaulting to %q", mode, ModeWarn)
+		mode = ModeWarn
+	}
+
+	enabledNamespaces := datadogConfig.GetStringSlice("admission_controller.validation.hygiene.enabled_namespaces")
+	disabledNamespaces := datadogConfig.GetStringSlice("admission_controller.validation.hygiene.disabled_namespaces")
+	if len(enabledNamespaces) > 0 && len(disabledNamespaces) > 0 {
+		log.Warnf("admission_controller.validation.hygiene.enabled_namespaces and disabled_namespaces are mutually exclusive, ignoring disabled_namespaces")
+		disabledNamespaces = nil
+	}
+
+	return &Webhook{
+		name:       webhookName,
+		isEnabled:  datadogConfig.GetBool("admission_controller.validation.hygiene.enabled"),
+		endpoint:   datadogConfig.GetString("admission_controller.validation.hygiene.endpoint"),
+		resources:  []string{"pods"},
+		operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+
+		mode:               mode,
+		enabledNamespaces:  enabledNamespaces,
+		disabledNamespaces: disabledNamespaces,
+
+		checkUST:           datadogConfig.GetBool("admission_controller.validation.hygiene.unified_service_tagging.enabled"),
+		requiredTags:       datadogConfig.GetStringSlice("admission_controller.validation.hygiene.unified_service_tagging.required_tags"),
+		checkADAnnotations: datadogConfig.GetBool("admission_controller.validation.hygiene.autodiscovery_annotations.enabled"),
+		checkAPMDogStatsD:  datadogConfig.GetBool("admission_controller.validation.hygiene.apm_dogstatsd_env.enabled"),
+	}
+}
+
+// Name returns the name of the webhook
+func (w *Webhook) Name() string {
+	return w.name
+}
+
+// WebhookType returns the type of the webhook
+func (w *Webhook) WebhookType() common.WebhookType {
+	return common.ValidatingWebhook
+}
+
+// IsEnabled returns whether the webhook is enabled
+func (w *Webhook) IsEnabled() bool {
+	return w.isEnabled
+}
+
+// Endpoint returns the endpoint of the webhook
+func (w *Webhook) Endpoint() string {
+	return w.endpoint
+}
+
+// Resources returns the kubernetes resources for which the webhook should
+// be invoked
+func (w *Webhook) Resources() []string {
+	return w.resources
+}
+
+// Operations returns the operations on the resources specified for which
+// the webhook should be invoked
+func (w *Webhook) Operations() []admissionregistrationv1.OperationType {
+	return w.operations
+}
+
+// LabelSelectors returns the label selectors that specify when the webhook
+// should be invoked. The namespace selector is built from the enabled or
+// disabled namespaces. Pods can opt out with the
+// admission.datadoghq.com/enabled=false label.
+func (w *Webhook) LabelSelectors(useNamespaceSelector bool) (namespaceSelector *metav1.LabelSelector, objectSelector *metav1.LabelSelector) {
+	optOut := metav1.LabelSelectorRequirement{
+		Key:      common.EnabledLabelKey,
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{"false"},
+	}
+
+	var nsRequirements []metav1.LabelSelectorRequirement
+	if len(w.enabledNamespaces) > 0 {
+		nsRequirements = append(nsRequirements, metav1.LabelSelectorRequirement{
+			Key:      namespaceNameLabelKey,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   w.enabledNamespaces,
+		})
+	} else if len(w.disabledNamespaces) > 0 {
+		nsRequirements = append(nsRequirements, metav1.LabelSelectorRequirement{
+			Key:      namespaceNameLabelKey,
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   w.disabledNamespaces,
+		})
+	}
+
+	if useNamespaceSelector {
+		// object selectors aren't supported, the opt-out label is
+		// applied to namespaces, and also checked by the webhook itself
+		return &metav1.LabelSelector{MatchExpressions: append(nsRequirements, optOut)}, nil
+	}
+
+	if len(nsRequirements) > 0 {
+		namespaceSelector = &metav1.LabelSelector{MatchExpressions: nsRequirements}
+	}
+
+	return namespaceSelector, &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{optOut}}
+}
+
+// WebhookFunc returns the function that validates the resources
+func (w *Webhook) WebhookFunc() admission.WebhookFunc {
+	return func(request *admission.Request) *admiv1.AdmissionResponse {
+		var violations []string
+
+		validated, err := validatecommon.Validate(request.Raw, request.Namespace, w.Name(), func(pod *corev1.Pod, _ string, _ dynamic.Interface) (bool, error) {
+			violations = w.violations(pod)
+			// the validation attempts telemetry reports the actual result,
+			// the response is adjusted to the mode below
+			return len(violations) == 0, nil
+		}, request.DynamicClient)
+
+		if err != nil && w.mode != ModeEnforce {
+			log.Warnf("Failed to validate pod %s/%s, admitting it: %v", request.Namespace, request.Name, err)
+			return &admiv1.AdmissionResponse{Allowed: true}
+		}
+
+		response := common.ValidationResponse(validated, err)
+		if len(violations) == 0 {
+			return response
+		}
+
+		log.Infof("Pod %s/%s doesn't follow the Datadog configuration guidelines (mode: %s): %s", request.Namespace, podName(request), w.mode, strings.Join(violations, "; "))
+
+		switch w.mode {
+		case ModeEnforce:
+			response.Result = &metav1.Status{
+				Message: fmt.Sprintf("rejected by the Datadog %s webhook: %s", webhookName, strings.Join(violations, "; ")),
+			}
+		case ModeWarn:
+			response = &admiv1.AdmissionResponse{Allowed: true}
+			for _, violation := range violations {
+				response.Warnings = append(response.Warnings, "datadog: "+violation)
+			}
+		case ModeDryRun:
+			response = &admiv1.AdmissionResponse{Allowed: true}
+		}
+
+		return response
+	}
+}
+
+// podName returns the name of the pod in the request. Pods created by
+// controllers aren't named yet when they are admitted.
+func podName(request *admission.Request) string {
+	if request.Name != "" {
+		return request.Name
+	}
+	return "(unnamed)"
+}
@@ -0,0 +1,249 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver && test
+
+package hygiene
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/cmd/cluster-agent/admission"
+	mutatecommon "github.com/DataDog/datadog-agent/pkg/clusteragent/admission/mutate/common"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+func labelledPod() *corev1.Pod {
+	pod := mutatecommon.FakePodWithContainer("foo-pod", mutatecommon.FakeContainer("app"))
+	pod.Labels = map[string]string{
+		"tags.datadoghq.com/env":     "prod",
+		"tags.datadoghq.com/service": "web",
+		"tags.datadoghq.com/version": "1.2.3",
+	}
+	return pod
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     func() *corev1.Pod
+		want    []string
+		setConf func(mockConfig model.Config)
+	}{
+		{
+			name: "valid pod",
+			pod: func() *corev1.Pod {
+				pod := labelledPod()
+				pod.Annotations = map[string]string{
+					"ad.datadoghq.com/app.checks": `{"http_check": {"instances": [{"url": "http://%%host%%"}]}}`,
+				}
+				pod.Spec.Containers[0].Env = []corev1.EnvVar{
+					mutatecommon.FakeEnvWithValue("DD_ENV", "prod"),
+					mutatecommon.FakeEnvWithFieldRefValue("DD_AGENT_HOST", "status.hostIP"),
+					mutatecommon.FakeEnvWithValue("DD_TRACE_AGENT_URL", "unix:///var/run/datadog/apm.socket"),
+					mutatecommon.FakeEnvWithValue("DD_DOGSTATSD_PORT", "8125"),
+				}
+				return pod
+			},
+		},
+		{
+			name: "missing and mismatching unified service tagging",
+			pod: func() *corev1.Pod {
+				pod := labelledPod()
+				delete(pod.Labels, "tags.datadoghq.com/version")
+				pod.Spec.Containers[0].Env = []corev1.EnvVar{
+					mutatecommon.FakeEnvWithValue("DD_SERVICE", "api"),
+				}
+				return pod
+			},
+			want: []string{
+				`container app: DD_SERVICE="api" doesn't match label tags.datadoghq.com/service="web"`,
+				"missing label tags.datadoghq.com/version",
+			},
+		},
+		{
+			name: "required tags are configurable",
+			pod: func() *corev1.Pod {
+				pod := labelledPod()
+				delete(pod.Labels, "tags.datadoghq.com/version")
+				return pod
+			},
+			setConf: func(mockConfig model.Config) {
+				mockConfig.SetWithoutSource("admission_controller.validation.hygiene.unified_service_tagging.required_tags", []string{"env", "service"})
+			},
+		},
+		{
+			name: "invalid autodiscovery annotations",
+			pod: func() *corev1.Pod {
+				pod := labelledPod()
+				pod.Annotations = map[string]string{
+					"ad.datadoghq.com/app.checks":     `{"http_check": {"instances": [`,
+					"ad.datadoghq.com/unknown.checks": `{}`,
+				}
+				return pod
+			},
+			want: []string{
+				"annotation ad.datadoghq.com/unknown.checks is invalid: unknown doesn't match a container identifier [app]",
+				"invalid autodiscovery annotations for app: cannot parse check configuration: unexpected end of JSON input",
+			},
+		},
+		{
+			name: "invalid APM and DogStatsD environment",
+			pod: func() *corev1.Pod {
+				pod := labelledPod()
+				pod.Spec.Containers[0].Env = []corev1.EnvVar{
+					mutatecommon.FakeEnvWithValue("DD_AGENT_HOST", "http://datadog-agent"),
+					mutatecommon.FakeEnvWithValue("DD_TRACE_AGENT_PORT", "http"),
+					mutatecommon.FakeEnvWithValue("DD_DOGSTATSD_PORT", "70000"),
+					mutatecommon.FakeEnvWithValue("DD_TRACE_AGENT_URL", "tcp://localhost:8126"),
+					mutatecommon.FakeEnvWithValue("DD_DOGSTATSD_URL", "unix://"),
+				}
+				return pod
+			},
+			want: []string{
+				`container app: invalid DD_AGENT_HOST "http://datadog-agent": expected a host, not a URL`,
+				`container app: invalid DD_DOGSTATSD_PORT "70000": out of range`,
+				`container app: invalid DD_DOGSTATSD_URL "unix://": missing socket path`,
+				`container app: invalid DD_TRACE_AGENT_PORT "http": not a number`,
+				`container app: invalid DD_TRACE_AGENT_URL "tcp://localhost:8126": unsupported scheme "tcp", expected one of http, https, unix`,
+			},
+		},
+		{
+			name: "disabled checks",
+			pod: func() *corev1.Pod {
+				pod := labelledPod()
+				pod.Labels = nil
+				pod.Spec.Containers[0].Env = []corev1.EnvVar{
+					mutatecommon.FakeEnvWithValue("DD_TRACE_AGENT_PORT", "http"),
+				}
+				return pod
+			},
+			setConf: func(mockConfig model.Config) {
+				mockConfig.SetWithoutSource("admission_controller.validation.hygiene.unified_service_tagging.enabled", false)
+				mockConfig.SetWithoutSource("admission_controller.validation.hygiene.apm_dogstatsd_env.enabled", false)
+			},
+		},
+		{
+			name: "opted out pod",
+			pod: func() *corev1.Pod {
+				pod := labelledPod()
+				pod.Labels = map[string]string{"admission.datadoghq.com/enabled": "false"}
+				return pod
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig := configmock.New(t)
+			if tt.setConf != nil {
+				tt.setConf(mockConfig)
+			}
+
+			w := NewWebhook(mockConfig)
+			assert.Equal(t, tt.want, w.violations(tt.pod()))
+		})
+	}
+}
+
+func TestWebhookFunc(t *testing.T) {
+	pod := labelledPod()
+	delete(pod.Labels, "tags.datadoghq.com/env")
+	rawPod, err := json.Marshal(pod)
+	require.NoError(t, err)
+
+	tests := []struct {
+		mode         string
+		wantAllowed  bool
+		wantWarnings []string
+		wantMessage  string
+	}{
+		{
+			mode:        "enforce",
+			wantAllowed: false,
+			wantMessage: "rejected by the Datadog hygiene webhook: missing label tags.datadoghq.com/env",
+		},
+		{
+			mode:         "warn",
+			wantAllowed:  true,
+			wantWarnings: []string{"datadog: missing label tags.datadoghq.com/env"},
+		},
+		{
+			mode:        "dry_run",
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mockConfig := configmock.New(t)
+			mockConfig.SetWithoutSource("admission_controller.validation.hygiene.mode", tt.mode)
+
+			w := NewWebhook(mockConfig)
+			response := w.WebhookFunc()(&admission.Request{Raw: rawPod, Namespace: "default"})
+
+			assert.Equal(t, tt.wantAllowed, response.Allowed)
+			assert.Equal(t, tt.wantWarnings, response.Warnings)
+			if tt.wantMessage != "" {
+				require.NotNil(t, response.Result)
+				assert.Equal(t, tt.wantMessage, response.Result.Message)
+			} else {
+				assert.Nil(t, response.Result)
+			}
+		})
+	}
+
+	// invalid objects are only rejected in enforce mode
+	mockConfig := configmock.New(t)
+	w := NewWebhook(mockConfig)
+	response := w.WebhookFunc()(&admission.Request{Raw: []byte("{"), Namespace: "default"})
+	assert.True(t, response.Allowed)
+}
+
+func TestLabelSelectors(t *testing.T) {
+	optOut := metav1.LabelSelectorRequirement{
+		Key:      "admission.datadoghq.com/enabled",
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{"false"},
+	}
+
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("admission_controller.validation.hygiene.disabled_namespaces", []string{"kube-system"})
+	w := NewWebhook(mockConfig)
+
+	nsSelector, objSelector := w.LabelSelectors(false)
+	assert.Equal(t, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "kubernetes.io/metadata.name", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"kube-system"}},
+		},
+	}, nsSelector)
+	assert.Equal(t, &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{optOut}}, objSelector)
+
+	nsSelector, objSelector = w.LabelSelectors(true)
+	assert.Equal(t, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "kubernetes.io/metadata.name", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"kube-system"}},
+			optOut,
+		},
+	}, nsSelector)
+	assert.Nil(t, objSelector)
+
+	mockConfig.SetWithoutSource("admission_controller.validation.hygiene.enabled_namespaces", []string{"apps"})
+	w = NewWebhook(mockConfig)
+
+	nsSelector, _ = w.LabelSelectors(false)
+	assert.Equal(t, &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "kubernetes.io/metadata.name", Operator: metav1.LabelSelectorOpIn, Values: []string{"apps"}},
+		},
+	}, nsSelector)
+}
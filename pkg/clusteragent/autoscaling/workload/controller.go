@@ -82,6 +82,7 @@ func newController(
 	podWatcher podWatcher,
 	localSender sender.Sender,
 	limitHeap *autoscaling.HashHeap,
+	localRecommender *localRecommender,
 ) (*Controller, error) {
 	c := &Controller{
 		clusterID:     clusterID,
@@ -113,7 +114,7 @@ func newController(
 	c.podWatcher = podWatcher
 
 	// TODO: Ensure that controllers do not take action before the podwatcher is synced
-	c.horizontalController = newHorizontalReconciler(c.clock, eventRecorder, restMapper, scaleClient)
+	c.horizontalController = newHorizontalReconciler(c.clock, eventRecorder, restMapper, scaleClient, localRecommender)
 	c.verticalController = newVerticalController(c.clock, eventRecorder, dynamicClient, c.podWatcher)
 
 	return c, nil
@@ -47,13 +47,17 @@ type horizontalController struct {
 	clock         clock.Clock
 	eventRecorder record.EventRecorder
 	scaler        scaler
+
+	// localRecommender is optional, nil if local recommendations are not available
+	localRecommender *localRecommender
 }
 
-func newHorizontalReconciler(clock clock.Clock, eventRecorder record.EventRecorder, restMapper apimeta.RESTMapper, scaleGetter scaleclient.ScalesGetter) *horizontalController {
+func newHorizontalReconciler(clock clock.Clock, eventRecorder record.EventRecorder, restMapper apimeta.RESTMapper, scaleGetter scaleclient.ScalesGetter, localRecommender *localRecommender) *horizontalController {
 	return &horizontalController{
-		clock:         clock,
-		eventRecorder: eventRecorder,
-		scaler:        newScaler(restMapper, scaleGetter),
+		clock:            clock,
+		eventRecorder:    eventRecorder,
+		scaler:           newScaler(restMapper, scaleGetter),
+		localRecommender: localRecommender,
 	}
 }
 
@@ -79,7 +83,14 @@ func (hr *horizontalController) sync(ctx context.Context, podAutoscaler *datadog
 		return autoscaling.Requeue, err
 	}
 
-	return hr.performScaling(ctx, podAutoscaler, autoscalerInternal, gr, scale)
+	// Compute local recommendations if remote values are not available
+	localRes := autoscaling.NoRequeue
+	if hr.localRecommender != nil {
+		localRes = hr.localRecommender.sync(podAutoscaler, autoscalerInternal, scale.Spec.Replicas)
+	}
+
+	res, err := hr.performScaling(ctx, podAutoscaler, autoscalerInternal, gr, scale)
+	return localRes.Merge(res), err
 }
 
 func (hr *horizontalController) performScaling(ctx context.Context, podAutoscaler *datadoghq.DatadogPodAutoscaler, autoscalerInternal *model.PodAutoscalerInternal, gr schema.GroupResource, scale *autoscalingv1.Scale) (autoscaling.ProcessResult, error) {
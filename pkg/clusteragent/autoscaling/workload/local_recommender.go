@@ -0,0 +1,372 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package workload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	datadoghq "github.com/DataDog/datadog-operator/api/datadoghq/v1alpha1"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	le "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	defaultDownscaleStabilizationWindow = 5 * time.Minute
+	maxStabilizationWindow              = 30 * time.Minute
+)
+
+// localMetricTargetType defines how the value of a metric query is compared to its target
+type localMetricTargetType string
+
+const (
+	// valueLocalMetricTargetType is used when the query returns a per-POD value (e.g. average requests per POD).
+	// The number of replicas is scaled by the ratio between the query value and the target.
+	valueLocalMetricTargetType localMetricTargetType = "Value"
+
+	// averageValueLocalMetricTargetType is used when the query returns a value for the whole workload (e.g. queue length).
+	// The number of replicas is the query value divided by the target.
+	averageValueLocalMetricTargetType localMetricTargetType = "AverageValue"
+)
+
+// localMetricTarget is a metric query and the value it should be kept at
+type localMetricTarget struct {
+	Query string                `json:"query"`
+	Type  localMetricTargetType `json:"type,omitempty"`
+	Value float64               `json:"value"`
+}
+
+// localRecommenderSettings are read from the LocalRecommenderAnnotation of a DatadogPodAutoscaler
+type localRecommenderSettings struct {
+	// Metrics are the queries to compute recommendations from, the highest recommendation wins
+	Metrics []localMetricTarget `json:"metrics"`
+
+	// UpscaleStabilizationWindowSeconds is the time during which past recommendations are considered before scaling up, defaults to 0
+	UpscaleStabilizationWindowSeconds *int32 `json:"upscaleStabilizationWindowSeconds,omitempty"`
+
+	// DownscaleStabilizationWindowSeconds is the time during which past recommendations are considered before scaling down, defaults to 300
+	DownscaleStabilizationWindowSeconds *int32 `json:"downscaleStabilizationWindowSeconds,omitempty"`
+
+	// Upscale limits the replicas added per period to local recommendations, same format as the DatadogPodAutoscaler policy
+	Upscale *datadoghq.DatadogPodAutoscalerScalingPolicy `json:"upscale,omitempty"`
+
+	// Downscale limits the replicas removed per period to local recommendations, same format as the DatadogPodAutoscaler policy
+	Downscale *datadoghq.DatadogPodAutoscalerScalingPolicy `json:"downscale,omitempty"`
+}
+
+func parseLocalRecommenderSettings(rawSettings string) (*localRecommenderSettings, error) {
+	settings := &localRecommenderSettings{}
+	if err := json.Unmarshal([]byte(rawSettings), settings); err != nil {
+		return nil, err
+	}
+
+	if len(settings.Metrics) == 0 {
+		return nil, errors.New("at least one metric is required")
+	}
+
+	for i := range settings.Metrics {
+		metric := &settings.Metrics[i]
+		if metric.Query == "" {
+			return nil, fmt.Errorf("metric #%d has no query", i)
+		}
+		if metric.Value <= 0 {
+			return nil, fmt.Errorf("metric %q must have a target value greater than 0", metric.Query)
+		}
+
+		switch metric.Type {
+		case "":
+			metric.Type = valueLocalMetricTargetType
+		case valueLocalMetricTargetType, averageValueLocalMetricTargetType:
+		default:
+			return nil, fmt.Errorf("metric %q has unknown target type %q", metric.Query, metric.Type)
+		}
+	}
+
+	for _, window := range []*int32{settings.UpscaleStabilizationWindowSeconds, settings.DownscaleStabilizationWindowSeconds} {
+		if window != nil && (*window < 0 || time.Duration(*window)*time.Second > maxStabilizationWindow) {
+			return nil, fmt.Errorf("stabilization windows must be between 0 and %d seconds", int(maxStabilizationWindow.Seconds()))
+		}
+	}
+
+	if err := validateLocalScalingPolicy("upscale", settings.Upscale); err != nil {
+		return nil, err
+	}
+	if err := validateLocalScalingPolicy("downscale", settings.Downscale); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func validateLocalScalingPolicy(name string, policy *datadoghq.DatadogPodAutoscalerScalingPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.Strategy != nil {
+		switch *policy.Strategy {
+		case datadoghq.DatadogPodAutoscalerMaxChangeStrategySelect, datadoghq.DatadogPodAutoscalerMinChangeStrategySelect, datadoghq.DatadogPodAutoscalerDisabledStrategySelect:
+		default:
+			return fmt.Errorf("%s policy has unknown strategy %q", name, *policy.Strategy)
+		}
+	}
+
+	for i, rule := range policy.Rules {
+		switch rule.Type {
+		case datadoghq.DatadogPodAutoscalerPodsScalingRuleType, datadoghq.DatadogPodAutoscalerPercentScalingRuleType:
+		default:
+			return fmt.Errorf("%s policy rule #%d has unknown type %q", name, i, rule.Type)
+		}
+		if rule.Value <= 0 {
+			return fmt.Errorf("%s policy rule #%d must have a value greater than 0", name, i)
+		}
+		// Past scaling actions are only kept for an hour
+		if rule.PeriodSeconds <= 0 || time.Duration(rule.PeriodSeconds)*time.Second > time.Hour {
+			return fmt.Errorf("%s policy rule #%d must have a period between 1 and 3600 seconds", name, i)
+		}
+	}
+
+	return nil
+}
+
+func (s *localRecommenderSettings) stabilizationWindows() (upscale, downscale time.Duration) {
+	downscale = defaultDownscaleStabilizationWindow
+	if s.UpscaleStabilizationWindowSeconds != nil {
+		upscale = time.Duration(*s.UpscaleStabilizationWindowSeconds) * time.Second
+	}
+	if s.DownscaleStabilizationWindowSeconds != nil {
+		downscale = time.Duration(*s.DownscaleStabilizationWindowSeconds) * time.Second
+	}
+	return upscale, downscale
+}
+
+// metricsQuerier is the subset of autoscalers.ProcessorInterface used to query metrics from Datadog
+type metricsQuerier interface {
+	QueryExternalMetric(queries []string, timeWindow time.Duration) (map[string]autoscalers.Point, error)
+}
+
+type timestampedReplicas struct {
+	timestamp time.Time
+	replicas  int32
+}
+
+// localRecommender computes horizontal scaling values from metric queries,
+// so that scaling keeps working when remote recommendations are unavailable.
+type localRecommender struct {
+	clock   clock.Clock
+	querier metricsQuerier
+
+	refreshPeriod      time.Duration
+	remoteValuesMaxAge time.Duration
+
+	// history holds past recommendations per autoscaler, used for stabilization
+	mutex   sync.Mutex
+	history map[string][]timestampedReplicas
+}
+
+func newLocalRecommender(clock clock.Clock, querier metricsQuerier, refreshPeriod, remoteValuesMaxAge time.Duration) *localRecommender {
+	return &localRecommender{
+		clock:              clock,
+		querier:            querier,
+		refreshPeriod:      refreshPeriod,
+		remoteValuesMaxAge: remoteValuesMaxAge,
+		history:            make(map[string][]timestampedReplicas),
+	}
+}
+
+// sync replaces the horizontal scaling values of the autoscaler with local values if it has local recommender settings
+// and remote values are not available.
+func (lr *localRecommender) sync(podAutoscaler *datadoghq.DatadogPodAutoscaler, autoscalerInternal *model.PodAutoscalerInternal, currentReplicas int32) autoscaling.ProcessResult {
+	rawSettings, found := podAutoscaler.Annotations[model.LocalRecommenderAnnotation]
+	if !found {
+		lr.forget(autoscalerInternal.ID())
+
+		// Local recommendations have been disabled, we stop using local values
+		if horizontal := autoscalerInternal.ScalingValues().Horizontal; horizontal != nil && horizontal.Source == model.LocalValueSource {
+			autoscalerInternal.UpdateFromLocalValues(nil, nil)
+		}
+		return autoscaling.NoRequeue
+	}
+
+	// Remote values always take precedence, re-evaluating later in case they become stale
+	if lr.remoteValuesAvailable(autoscalerInternal.ScalingValues()) {
+		return autoscaling.Requeue.After(lr.refreshPeriod)
+	}
+
+	settings, err := parseLocalRecommenderSettings(rawSettings)
+	if err != nil {
+		autoscalerInternal.UpdateFromLocalValues(nil, fmt.Errorf("invalid %s annotation: %w", model.LocalRecommenderAnnotation, err))
+		return autoscaling.NoRequeue
+	}
+
+	recommendation, err := lr.computeReplicas(settings, currentReplicas)
+	if err != nil {
+		autoscalerInternal.UpdateFromLocalValues(nil, fmt.Errorf("unable to compute local recommendation: %w", err))
+		return autoscaling.Requeue.After(lr.refreshPeriod)
+	}
+
+	stabilizedReplicas := lr.stabilize(autoscalerInternal.ID(), settings, currentReplicas, recommendation)
+	replicas, limitReason := lr.applyPolicies(settings, autoscalerInternal.HorizontalLastActions(), currentReplicas, stabilizedReplicas)
+	log.Debugf("Local recommendation for autoscaler %s: %d replicas (recommended %d, stabilized to %d)", autoscalerInternal.ID(), replicas, recommendation, stabilizedReplicas)
+	if limitReason != "" {
+		log.Debugf("Local recommendation for autoscaler %s limited: %s", autoscalerInternal.ID(), limitReason)
+	}
+
+	autoscalerInternal.UpdateFromLocalValues(&model.HorizontalScalingValues{
+		Source:    model.LocalValueSource,
+		Timestamp: lr.clock.Now(),
+		Replicas:  replicas,
+	}, nil)
+	telemetryHorizontalScaleReceivedRecommendations.Set(
+		float64(replicas),
+		autoscalerInternal.Namespace(),
+		autoscalerInternal.Spec().TargetRef.Name,
+		autoscalerInternal.Name(),
+		string(model.LocalValueSource),
+		le.JoinLeaderValue,
+	)
+
+	return autoscaling.Requeue.After(lr.refreshPeriod)
+}
+
+// remoteValuesAvailable returns true if the horizontal values have been received from Datadog and are recent enough
+func (lr *localRecommender) remoteValuesAvailable(values model.ScalingValues) bool {
+	if values.Horizontal == nil || values.Horizontal.Source == model.LocalValueSource {
+		return false
+	}
+
+	if values.Error != nil || values.HorizontalError != nil {
+		return false
+	}
+
+	return lr.clock.Since(values.Horizontal.Timestamp) <= lr.remoteValuesMaxAge
+}
+
+// computeReplicas returns the highest number of replicas recommended by the metrics
+func (lr *localRecommender) computeReplicas(settings *localRecommenderSettings, currentReplicas int32) (int32, error) {
+	queries := make([]string, 0, len(settings.Metrics))
+	for _, metric := range settings.Metrics {
+		queries = append(queries, metric.Query)
+	}
+
+	points, err := lr.querier.QueryExternalMetric(queries, autoscalers.GetDefaultTimeWindow())
+	if err != nil {
+		return 0, err
+	}
+
+	var recommendation int32
+	for _, metric := range settings.Metrics {
+		point, found := points[metric.Query]
+		if !found || !point.Valid {
+			if point.Error != nil {
+				return 0, fmt.Errorf("no valid value for query %q: %w", metric.Query, point.Error)
+			}
+			return 0, fmt.Errorf("no valid value for query %q", metric.Query)
+		}
+
+		var replicas float64
+		switch metric.Type {
+		case averageValueLocalMetricTargetType:
+			replicas = point.Value / metric.Value
+		default:
+			replicas = float64(currentReplicas) * point.Value / metric.Value
+		}
+
+		recommendation = max(recommendation, int32(math.Min(math.Ceil(replicas), math.MaxInt32)))
+	}
+
+	return recommendation, nil
+}
+
+// stabilize records the recommendation and returns the number of replicas to scale to, considering past recommendations.
+// Like the HorizontalPodAutoscaler, we only scale up to the lowest recommendation of the upscale window,
+// and only scale down to the highest recommendation of the downscale window.
+func (lr *localRecommender) stabilize(id string, settings *localRecommenderSettings, currentReplicas, recommendation int32) int32 {
+	now := lr.clock.Now()
+	upscaleWindow, downscaleWindow := settings.stabilizationWindows()
+	retention := max(upscaleWindow, downscaleWindow)
+
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	upscaleReplicas, downscaleReplicas := recommendation, recommendation
+	history := lr.history[id][:0]
+	for _, past := range lr.history[id] {
+		age := now.Sub(past.timestamp)
+		if age > retention {
+			continue
+		}
+		history = append(history, past)
+
+		if age <= upscaleWindow {
+			upscaleReplicas = min(upscaleReplicas, past.replicas)
+		}
+		if age <= downscaleWindow {
+			downscaleReplicas = max(downscaleReplicas, past.replicas)
+		}
+	}
+	lr.history[id] = append(history, timestampedReplicas{timestamp: now, replicas: recommendation})
+
+	// Clearing history of autoscalers that are gone
+	for otherID, otherHistory := range lr.history {
+		if now.Sub(otherHistory[len(otherHistory)-1].timestamp) > maxStabilizationWindow {
+			delete(lr.history, otherID)
+		}
+	}
+
+	replicas := currentReplicas
+	if replicas < upscaleReplicas {
+		replicas = upscaleReplicas
+	}
+	if replicas > downscaleReplicas {
+		replicas = downscaleReplicas
+	}
+	return replicas
+}
+
+// applyPolicies limits the change of replicas according to the upscale and downscale policies of the settings,
+// considering the scaling actions that happened in the period of each rule.
+func (lr *localRecommender) applyPolicies(settings *localRecommenderSettings, lastActions []datadoghq.DatadogPodAutoscalerHorizontalAction, currentReplicas, replicas int32) (int32, string) {
+	var policy *datadoghq.DatadogPodAutoscalerScalingPolicy
+	var applyPolicy func(time.Time, []datadoghq.DatadogPodAutoscalerHorizontalAction, *datadoghq.DatadogPodAutoscalerScalingPolicy, int32, int32) (int32, time.Duration, string)
+	switch {
+	case replicas > currentReplicas:
+		policy, applyPolicy = settings.Upscale, applyScaleUpPolicy
+	case replicas < currentReplicas:
+		policy, applyPolicy = settings.Downscale, applyScaleDownPolicy
+	default:
+		return replicas, ""
+	}
+
+	if policy == nil {
+		return replicas, ""
+	}
+	if policy.Strategy != nil && *policy.Strategy == datadoghq.DatadogPodAutoscalerDisabledStrategySelect {
+		return currentReplicas, fmt.Sprintf("scaling to %d replicas disabled by strategy", replicas)
+	}
+
+	limitedReplicas, _, limitReason := applyPolicy(lr.clock.Now(), lastActions, policy, currentReplicas, replicas)
+	return limitedReplicas, limitReason
+}
+
+func (lr *localRecommender) forget(id string) {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	delete(lr.history, id)
+}
@@ -0,0 +1,365 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package workload
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	datadoghq "github.com/DataDog/datadog-operator/api/datadoghq/v1alpha1"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+type fakeMetricsQuerier struct {
+	points map[string]autoscalers.Point
+	err    error
+}
+
+func (q *fakeMetricsQuerier) QueryExternalMetric(queries []string, _ time.Duration) (map[string]autoscalers.Point, error) {
+	res := make(map[string]autoscalers.Point, len(queries))
+	for _, query := range queries {
+		if point, found := q.points[query]; found {
+			res[query] = point
+		}
+	}
+	return res, q.err
+}
+
+func TestParseLocalRecommenderSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		want     *localRecommenderSettings
+		wantErr  string
+	}{
+		{
+			name:     "valid settings",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "value": 100}, {"query": "sum:queue{*}", "type": "AverageValue", "value": 10}], "downscaleStabilizationWindowSeconds": 60}`,
+			want: &localRecommenderSettings{
+				Metrics: []localMetricTarget{
+					{Query: "avg:requests{*}", Type: valueLocalMetricTargetType, Value: 100},
+					{Query: "sum:queue{*}", Type: averageValueLocalMetricTargetType, Value: 10},
+				},
+				DownscaleStabilizationWindowSeconds: pointer.Ptr[int32](60),
+			},
+		},
+		{
+			name:     "invalid json",
+			settings: `{"metrics": [`,
+			wantErr:  "unexpected end of JSON input",
+		},
+		{
+			name:     "no metrics",
+			settings: `{}`,
+			wantErr:  "at least one metric is required",
+		},
+		{
+			name:     "missing target value",
+			settings: `{"metrics": [{"query": "avg:requests{*}"}]}`,
+			wantErr:  `metric "avg:requests{*}" must have a target value greater than 0`,
+		},
+		{
+			name:     "unknown target type",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "type": "Utilization", "value": 50}]}`,
+			wantErr:  `metric "avg:requests{*}" has unknown target type "Utilization"`,
+		},
+		{
+			name:     "scaling policies",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "value": 50}], "upscale": {"strategy": "Min", "rules": [{"type": "Pods", "value": 2, "periodSeconds": 60}]}, "downscale": {"rules": [{"type": "Percent", "value": 50, "periodSeconds": 300}]}}`,
+			want: &localRecommenderSettings{
+				Metrics: []localMetricTarget{
+					{Query: "avg:requests{*}", Type: valueLocalMetricTargetType, Value: 50},
+				},
+				Upscale: &datadoghq.DatadogPodAutoscalerScalingPolicy{
+					Strategy: pointer.Ptr(datadoghq.DatadogPodAutoscalerMinChangeStrategySelect),
+					Rules: []datadoghq.DatadogPodAutoscalerScalingRule{
+						{Type: datadoghq.DatadogPodAutoscalerPodsScalingRuleType, Value: 2, PeriodSeconds: 60},
+					},
+				},
+				Downscale: &datadoghq.DatadogPodAutoscalerScalingPolicy{
+					Rules: []datadoghq.DatadogPodAutoscalerScalingRule{
+						{Type: datadoghq.DatadogPodAutoscalerPercentScalingRuleType, Value: 50, PeriodSeconds: 300},
+					},
+				},
+			},
+		},
+		{
+			name:     "unknown policy strategy",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "value": 50}], "upscale": {"strategy": "Fastest"}}`,
+			wantErr:  `upscale policy has unknown strategy "Fastest"`,
+		},
+		{
+			name:     "unknown policy rule type",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "value": 50}], "downscale": {"rules": [{"type": "Nodes", "value": 1, "periodSeconds": 60}]}}`,
+			wantErr:  `downscale policy rule #0 has unknown type "Nodes"`,
+		},
+		{
+			name:     "invalid policy rule value",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "value": 50}], "upscale": {"rules": [{"type": "Pods", "value": 0, "periodSeconds": 60}]}}`,
+			wantErr:  "upscale policy rule #0 must have a value greater than 0",
+		},
+		{
+			name:     "invalid policy rule period",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "value": 50}], "upscale": {"rules": [{"type": "Pods", "value": 2}]}}`,
+			wantErr:  "upscale policy rule #0 must have a period between 1 and 3600 seconds",
+		},
+		{
+			name:     "stabilization window too long",
+			settings: `{"metrics": [{"query": "avg:requests{*}", "value": 50}], "upscaleStabilizationWindowSeconds": 3600}`,
+			wantErr:  "stabilization windows must be between 0 and 1800 seconds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := parseLocalRecommenderSettings(tt.settings)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, settings)
+		})
+	}
+}
+
+func TestLocalRecommenderStabilize(t *testing.T) {
+	f := newHorizontalControllerFixture(t, time.Now())
+	recommender := newLocalRecommender(f.clock, &fakeMetricsQuerier{}, 30*time.Second, 10*time.Minute)
+	settings := &localRecommenderSettings{
+		UpscaleStabilizationWindowSeconds:   pointer.Ptr[int32](60),
+		DownscaleStabilizationWindowSeconds: pointer.Ptr[int32](180),
+	}
+
+	// First recommendation, nothing to stabilize against
+	assert.Equal(t, int32(8), recommender.stabilize("default/test", settings, 5, 8))
+
+	// Upscale is limited to the lowest recommendation of the last minute
+	f.clock.Step(30 * time.Second)
+	assert.Equal(t, int32(8), recommender.stabilize("default/test", settings, 8, 12))
+
+	// Downscale is limited to the highest recommendation of the last 3 minutes
+	f.clock.Step(time.Minute)
+	assert.Equal(t, int32(12), recommender.stabilize("default/test", settings, 12, 4))
+	f.clock.Step(2*time.Minute + time.Second)
+	assert.Equal(t, int32(4), recommender.stabilize("default/test", settings, 12, 4))
+
+	// History is per autoscaler and cleared once expired
+	assert.Equal(t, int32(2), recommender.stabilize("default/other", settings, 4, 2))
+	f.clock.Step(maxStabilizationWindow + time.Second)
+	recommender.stabilize("default/other", settings, 2, 2)
+	assert.NotContains(t, recommender.history, "default/test")
+}
+
+// newLocalRecommenderTestAutoscaler returns an autoscaler with the given local recommender settings and a function
+// syncing it with the horizontal controller of the fixture, scaling from currentReplicas to scaleReplicas
+func newLocalRecommenderTestAutoscaler(t *testing.T, f *horizontalControllerFixture, settings string) (*model.FakePodAutoscalerInternal, *datadoghq.DatadogPodAutoscaler, func(currentReplicas, scaleReplicas int32) (model.PodAutoscalerInternal, autoscaling.ProcessResult, error)) {
+	expectedGVK := schema.GroupVersionKind{
+		Group:   "apps",
+		Version: "v1",
+		Kind:    "Deployment",
+	}
+	fakePai := &model.FakePodAutoscalerInternal{
+		Namespace: "default",
+		Name:      "test",
+		Spec: &datadoghq.DatadogPodAutoscalerSpec{
+			TargetRef: v2.CrossVersionObjectReference{
+				Name:       "test",
+				Kind:       expectedGVK.Kind,
+				APIVersion: expectedGVK.Group + "/" + expectedGVK.Version,
+			},
+		},
+		TargetGVK: expectedGVK,
+	}
+	podAutoscaler := &datadoghq.DatadogPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test",
+			Annotations: map[string]string{
+				model.LocalRecommenderAnnotation: settings,
+			},
+		},
+	}
+
+	runSync := func(currentReplicas, scaleReplicas int32) (model.PodAutoscalerInternal, autoscaling.ProcessResult, error) {
+		t.Helper()
+		f.resetFakeScaler()
+		f.scaler.mockGet(*fakePai, currentReplicas, currentReplicas, nil)
+		expectedUpdateCalls := 0
+		if currentReplicas != scaleReplicas {
+			f.scaler.mockUpdate(*fakePai, scaleReplicas, currentReplicas, nil)
+			expectedUpdateCalls = 1
+		}
+
+		autoscalerInternal := fakePai.Build()
+		res, err := f.controller.sync(context.Background(), podAutoscaler, &autoscalerInternal)
+		f.scaler.AssertNumberOfCalls(t, "get", 1)
+		f.scaler.AssertNumberOfCalls(t, "update", expectedUpdateCalls)
+
+		// Keep state between syncs, as the store would
+		fakePai.ScalingValues = autoscalerInternal.ScalingValues()
+		fakePai.HorizontalLastActions = autoscalerInternal.HorizontalLastActions()
+		return autoscalerInternal, res, err
+	}
+
+	return fakePai, podAutoscaler, runSync
+}
+
+func TestHorizontalControllerLocalRecommendations(t *testing.T) {
+	testTime := time.Now()
+	f := newHorizontalControllerFixture(t, testTime)
+	querier := &fakeMetricsQuerier{
+		points: map[string]autoscalers.Point{
+			"avg:requests{*}": {Value: 150, Valid: true},
+		},
+	}
+	f.controller.localRecommender = newLocalRecommender(f.clock, querier, 30*time.Second, 10*time.Minute)
+
+	fakePai, podAutoscaler, runSync := newLocalRecommenderTestAutoscaler(t, f, `{"metrics": [{"query": "avg:requests{*}", "value": 100}], "downscaleStabilizationWindowSeconds": 120}`)
+
+	// No remote values: scaling from local recommendation, 4 * 150 / 100 = 6 replicas
+	autoscaler, res, err := runSync(4, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, autoscaling.Requeue.After(30*time.Second), res)
+	assert.Equal(t, &model.HorizontalScalingValues{
+		Source:    model.LocalValueSource,
+		Timestamp: testTime,
+		Replicas:  6,
+	}, autoscaler.ScalingValues().Horizontal)
+
+	// Metric went down, but downscale is stabilized
+	f.clock.Step(time.Minute)
+	querier.points["avg:requests{*}"] = autoscalers.Point{Value: 50, Valid: true}
+	autoscaler, _, err = runSync(6, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	// Once the stabilization window is over, downscaling to 6 * 50 / 100 = 3 replicas
+	f.clock.Step(2 * time.Minute)
+	autoscaler, _, err = runSync(6, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	// Query errors are surfaced, no scaling happens
+	querier.err = errors.New("rate limited")
+	autoscaler, res, err = runSync(3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, autoscaling.Requeue.After(30*time.Second), res)
+	assert.Nil(t, autoscaler.ScalingValues().Horizontal)
+	assert.EqualError(t, autoscaler.ScalingValues().HorizontalError, "unable to compute local recommendation: rate limited")
+	querier.err = nil
+
+	// Remote values are fresh: they take precedence over local recommendations
+	fakePai.ScalingValues = model.ScalingValues{
+		Horizontal: &model.HorizontalScalingValues{
+			Source:    datadoghq.DatadogPodAutoscalerAutoscalingValueSource,
+			Timestamp: f.clock.Now().Add(-time.Minute),
+			Replicas:  5,
+		},
+	}
+	autoscaler, res, err = runSync(3, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, autoscaling.Requeue.After(30*time.Second), res)
+	assert.Equal(t, datadoghq.DatadogPodAutoscalerAutoscalingValueSource, autoscaler.ScalingValues().Horizontal.Source)
+
+	// Remote values are stale: falling back to local recommendation, 5 * 50 / 100 = 3 replicas
+	fakePai.ScalingValues.Horizontal.Timestamp = f.clock.Now().Add(-time.Hour)
+	autoscaler, _, err = runSync(5, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LocalValueSource, autoscaler.ScalingValues().Horizontal.Source)
+	assert.Equal(t, int32(3), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	// Local recommendations are disabled: local values are cleared
+	delete(podAutoscaler.Annotations, model.LocalRecommenderAnnotation)
+	autoscaler, res, err = runSync(3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, autoscaling.NoRequeue, res)
+	assert.Nil(t, autoscaler.ScalingValues().Horizontal)
+}
+
+func TestHorizontalControllerLocalRecommendationsPolicies(t *testing.T) {
+	testTime := time.Now()
+	f := newHorizontalControllerFixture(t, testTime)
+	querier := &fakeMetricsQuerier{
+		points: map[string]autoscalers.Point{
+			"sum:queue{*}": {Value: 100, Valid: true},
+		},
+	}
+	f.controller.localRecommender = newLocalRecommender(f.clock, querier, 30*time.Second, 10*time.Minute)
+	_, _, runSync := newLocalRecommenderTestAutoscaler(t, f, `{
+		"metrics": [{"query": "sum:queue{*}", "type": "AverageValue", "value": 10}],
+		"downscaleStabilizationWindowSeconds": 0,
+		"upscale": {"rules": [{"type": "Pods", "value": 2, "periodSeconds": 60}]},
+		"downscale": {"rules": [{"type": "Percent", "value": 50, "periodSeconds": 120}]}
+	}`)
+
+	// 100 / 10 = 10 replicas recommended, upscale limited to 2 more replicas per minute
+	autoscaler, _, err := runSync(4, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	// Still in the same period, no more replicas can be added
+	f.clock.Step(30 * time.Second)
+	autoscaler, _, err = runSync(6, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	// Next period
+	f.clock.Step(31 * time.Second)
+	autoscaler, _, err = runSync(6, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(8), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	// 10 / 10 = 1 replica recommended, downscale limited to half of the replicas per 2 minutes
+	f.clock.Step(2 * time.Minute)
+	querier.points["sum:queue{*}"] = autoscalers.Point{Value: 10, Valid: true}
+	autoscaler, _, err = runSync(8, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	f.clock.Step(time.Minute)
+	autoscaler, _, err = runSync(4, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), autoscaler.ScalingValues().Horizontal.Replicas)
+
+	f.clock.Step(time.Minute + time.Second)
+	autoscaler, _, err = runSync(4, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), autoscaler.ScalingValues().Horizontal.Replicas)
+}
+
+func TestLocalRecommenderDisabledPolicy(t *testing.T) {
+	f := newHorizontalControllerFixture(t, time.Now())
+	recommender := newLocalRecommender(f.clock, &fakeMetricsQuerier{}, 30*time.Second, 10*time.Minute)
+	settings := &localRecommenderSettings{
+		Downscale: &datadoghq.DatadogPodAutoscalerScalingPolicy{
+			Strategy: pointer.Ptr(datadoghq.DatadogPodAutoscalerDisabledStrategySelect),
+		},
+	}
+
+	replicas, reason := recommender.applyPolicies(settings, nil, 5, 3)
+	assert.Equal(t, int32(5), replicas)
+	assert.Equal(t, "scaling to 3 replicas disabled by strategy", reason)
+
+	// No upscale policy
+	replicas, reason = recommender.applyPolicies(settings, nil, 5, 8)
+	assert.Equal(t, int32(8), replicas)
+	assert.Empty(t, reason)
+}
@@ -7,6 +7,10 @@
 
 package model
 
+import (
+	datadoghq "github.com/DataDog/datadog-operator/api/datadoghq/v1alpha1"
+)
+
 const (
 	// RecommendationIDAnnotation is the annotation key used to store the recommendation ID
 	RecommendationIDAnnotation = "autoscaling.datadoghq.com/rec-id"
@@ -16,6 +20,8 @@ const (
 	RecommendationAppliedEventGeneratedAnnotation = "autoscaling.datadoghq.com/event"
 	// RolloutTimestampAnnotation is the annotation key used to store the rollout timestamp
 	RolloutTimestampAnnotation = "autoscaling.datadoghq.com/rolloutAt"
+	// LocalRecommenderAnnotation is the annotation key used to configure local recommendations on a DatadogPodAutoscaler
+	LocalRecommenderAnnotation = "autoscaling.datadoghq.com/local-recommender"
 
 	// RecommendationAppliedEventReason is the event reason when a recommendation is applied
 	RecommendationAppliedEventReason = "RecommendationApplied"
@@ -28,3 +34,6 @@ const (
 	// FailedTriggerRolloutEventReason is the event reason when a trigger rollout fails
 	FailedTriggerRolloutEventReason = "FailedTriggerRollout"
 )
+
+// LocalValueSource is the source of scaling values computed by the Cluster Agent from metric queries
+const LocalValueSource datadoghq.DatadogPodAutoscalerValueSource = "Local"
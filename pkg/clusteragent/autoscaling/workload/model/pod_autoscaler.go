@@ -153,6 +153,12 @@ func (p *PodAutoscalerInternal) UpdateFromValues(scalingValues ScalingValues) {
 	p.scalingValues = scalingValues
 }
 
+// UpdateFromLocalValues replaces the horizontal scaling values with values computed locally
+func (p *PodAutoscalerInternal) UpdateFromLocalValues(horizontal *HorizontalScalingValues, err error) {
+	p.scalingValues.Horizontal = horizontal
+	p.scalingValues.HorizontalError = err
+}
+
 // RemoveValues clears autoscaling values data from the PodAutoscalerInternal as we stopped autoscaling
 func (p *PodAutoscalerInternal) RemoveValues() {
 	p.scalingValues = ScalingValues{}
@@ -10,18 +10,25 @@ package workload
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 
+	datadogclient "github.com/DataDog/datadog-agent/comp/autoscaling/datadogclient/def"
 	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/autoscaling/workload/model"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/optional"
 )
 
 const maxDatadogPodAutoscalerObjects int = 100
@@ -34,6 +41,7 @@ func StartWorkloadAutoscaling(
 	rcClient rcClient,
 	wlm workloadmeta.Component,
 	senderManager sender.SenderManager,
+	datadogClient optional.Option[datadogclient.Component],
 ) (PodPatcher, error) {
 	if apiCl == nil {
 		return nil, fmt.Errorf("Impossible to start workload autoscaling without valid APIClient")
@@ -66,7 +74,20 @@ func StartWorkloadAutoscaling(
 
 	limitHeap := autoscaling.NewHashHeap(maxDatadogPodAutoscalerObjects, store)
 
-	controller, err := newController(clusterID, eventRecorder, apiCl.RESTMapper, apiCl.ScaleCl, apiCl.DynamicInformerCl, apiCl.DynamicInformerFactory, le.IsLeader, store, podWatcher, sender, limitHeap)
+	// Local recommendations require querying metrics from Datadog
+	var localRecommender *localRecommender
+	if dc, found := datadogClient.Get(); found {
+		localRecommender = newLocalRecommender(
+			clock.RealClock{},
+			autoscalers.NewProcessor(dc),
+			time.Duration(pkgconfigsetup.Datadog().GetInt64("autoscaling.workload.local_recommender.refresh_period"))*time.Second,
+			time.Duration(pkgconfigsetup.Datadog().GetInt64("autoscaling.workload.local_recommender.remote_values_max_age"))*time.Second,
+		)
+	} else {
+		log.Info("Datadog client is not available, local recommendations are disabled for workload autoscaling")
+	}
+
+	controller, err := newController(clusterID, eventRecorder, apiCl.RESTMapper, apiCl.ScaleCl, apiCl.DynamicInformerCl, apiCl.DynamicInformerFactory, le.IsLeader, store, podWatcher, sender, limitHeap, localRecommender)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to start workload autoscaling controller: %w", err)
 	}
@@ -10,6 +10,9 @@ package clusterchecks
 import (
 	"math"
 	"sort"
+
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // CheckStatus represents the status of a check
@@ -23,6 +26,9 @@ type RunnerStatus struct {
 	Workers     int
 	WorkersUsed float64
 	NumChecks   int
+
+	// checkNames holds the number of instances of each check on the runner
+	checkNames map[string]int
 }
 
 func (ns RunnerStatus) utilization() float64 {
@@ -38,6 +44,10 @@ func (ns RunnerStatus) utilization() float64 {
 type checksDistribution struct {
 	Checks  map[string]*CheckStatus
 	Runners map[string]*RunnerStatus
+
+	// constraints are honored when placing checks with addToLeastBusy, if
+	// at least one runner satisfies them
+	constraints placementConstraints
 }
 
 func newChecksDistribution(workersPerRunner map[string]int) checksDistribution {
@@ -47,6 +57,7 @@ func newChecksDistribution(workersPerRunner map[string]int) checksDistribution {
 			Workers:     runnerWorkers,
 			WorkersUsed: 0.0,
 			NumChecks:   0,
+			checkNames:  map[string]int{},
 		}
 	}
 
@@ -60,8 +71,20 @@ func newChecksDistribution(workersPerRunner map[string]int) checksDistribution {
 // several options, it gives preference to preferredRunner. If preferredRunner
 // is not among the runners with the lowest utilization, it gives precedence to
 // the runner with the lowest number of checks deployed. excludeRunner can be set
-// to avoid assigning a check to a specific runner.
-func (distribution *checksDistribution) leastBusyRunner(preferredRunner string, excludeRunner string) string {
+// to avoid assigning a check to a specific runner. Runners violating the
+// placement constraints of checkID are skipped, unless all of them do.
+func (distribution *checksDistribution) leastBusyRunner(checkID string, preferredRunner string, excludeRunner string) string {
+	if len(distribution.constraints) > 0 {
+		if runner := distribution.leastBusyRunnerWithConstraints(checkID, preferredRunner, excludeRunner, true); runner != "" {
+			return runner
+		}
+		log.Debugf("No runner satisfies the placement constraints of check %s, ignoring them", checkID)
+	}
+
+	return distribution.leastBusyRunnerWithConstraints(checkID, preferredRunner, excludeRunner, false)
+}
+
+func (distribution *checksDistribution) leastBusyRunnerWithConstraints(checkID string, preferredRunner string, excludeRunner string, withConstraints bool) string {
 	leastBusyRunner := ""
 	minUtilization := 0.0
 	numChecksLeastBusyRunner := 0
@@ -72,6 +95,10 @@ func (distribution *checksDistribution) leastBusyRunner(preferredRunner string,
 			continue
 		}
 
+		if withConstraints && !distribution.constraints.allows(checkID, runnerStatus.checkNames) {
+			continue
+		}
+
 		runnerUtilization := runnerStatus.utilization()
 		runnerNumChecks := runnerStatus.NumChecks
 
@@ -91,7 +118,7 @@ func (distribution *checksDistribution) leastBusyRunner(preferredRunner string,
 }
 
 func (distribution *checksDistribution) addToLeastBusy(checkID string, workersNeeded float64, preferredRunner string, excludeRunner string) {
-	leastBusy := distribution.leastBusyRunner(preferredRunner, excludeRunner)
+	leastBusy := distribution.leastBusyRunner(checkID, preferredRunner, excludeRunner)
 	if leastBusy == "" {
 		return
 	}
@@ -106,15 +133,16 @@ func (distribution *checksDistribution) addCheck(checkID string, workersNeeded f
 	}
 
 	runnerInfo, runnerExists := distribution.Runners[runner]
-	if runnerExists {
-		runnerInfo.WorkersUsed += workersNeeded
-		runnerInfo.NumChecks++
-	} else {
-		distribution.Runners[runner] = &RunnerStatus{
-			WorkersUsed: workersNeeded,
-			NumChecks:   1,
-		}
+	if !runnerExists {
+		runnerInfo = &RunnerStatus{}
+		distribution.Runners[runner] = runnerInfo
+	}
+	if runnerInfo.checkNames == nil {
+		runnerInfo.checkNames = map[string]int{}
 	}
+	runnerInfo.WorkersUsed += workersNeeded
+	runnerInfo.NumChecks++
+	runnerInfo.checkNames[checkid.IDToCheckName(checkid.ID(checkID))]++
 }
 
 func (distribution *checksDistribution) runnerWorkers() map[string]int {
@@ -202,6 +230,28 @@ func (distribution *checksDistribution) numRunnersWithHighUtilization() int {
 	return withHighUtilization
 }
 
+// numConstraintViolations returns the number of checks placed on a runner
+// that violates their placement constraints
+func (distribution *checksDistribution) numConstraintViolations() int {
+	if len(distribution.constraints) == 0 {
+		return 0
+	}
+
+	violations := 0
+	for checkID, checkStatus := range distribution.Checks {
+		runnerChecks := distribution.Runners[checkStatus.Runner].checkNames
+		// don't count the check itself
+		checkName := checkid.IDToCheckName(checkid.ID(checkID))
+		runnerChecks[checkName]--
+		if !distribution.constraints.allows(checkID, runnerChecks) {
+			violations++
+		}
+		runnerChecks[checkName]++
+	}
+
+	return violations
+}
+
 func (distribution *checksDistribution) utilizationStdDev() float64 {
 	totalUtilization := 0.0
 	for _, runnerStatus := range distribution.Runners {
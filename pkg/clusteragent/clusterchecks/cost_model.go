@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build clusterchecks
+
+package clusterchecks
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	busynessCostModelName = "busyness"
+	weightedCostModelName = "weighted"
+)
+
+// costModel computes the cost of running a check on a runner. The cost of a
+// runner is the sum of the costs of its checks, and the busyness based
+// rebalancing tries to even it out across runners.
+type costModel interface {
+	checkCost(checkID string, stats types.CLCRunnerStats) int
+}
+
+// busynessCostModel is the default cost model, based on busynessFunc
+type busynessCostModel struct{}
+
+func (busynessCostModel) checkCost(_ string, stats types.CLCRunnerStats) int {
+	return busynessFunc(stats)
+}
+
+// checkCostOverride adjusts the cost of the instances of a check
+type checkCostOverride struct {
+	// Name is the name of the check
+	Name string `mapstructure:"name"`
+	// Weight multiplies the cost of the check, 1 if not set
+	Weight float64 `mapstructure:"weight"`
+	// MemoryMB is the estimated memory used by an instance of the check. The
+	// runners don't report the memory used by each check, so it has to be
+	// configured.
+	MemoryMB float64 `mapstructure:"memory_mb"`
+}
+
+// weightedCostModel computes the cost of a check as a weighted sum of its
+// average execution time, the number of metric samples, histogram buckets
+// and events it submits, and its estimated memory usage. The sum can be
+// multiplied by a per-check weight.
+type weightedCostModel struct {
+	executionTimeWeight    float64
+	metricSamplesWeight    float64
+	histogramBucketsWeight float64
+	eventsWeight           float64
+	memoryWeight           float64
+
+	checks map[string]checkCostOverride
+}
+
+func (m *weightedCostModel) checkCost(checkID string, stats types.CLCRunnerStats) int {
+	override, found := m.checks[checkid.IDToCheckName(checkid.ID(checkID))]
+	if !found {
+		override.Weight = 1
+	}
+
+	cost := m.memoryWeight * override.MemoryMB
+	if !stats.LastExecFailed {
+		// like with busynessFunc, the stats of failing checks aren't
+		// representative of their cost
+		cost += m.executionTimeWeight*float64(stats.AverageExecutionTime) +
+			m.metricSamplesWeight*float64(stats.MetricSamples) +
+			m.histogramBucketsWeight*float64(stats.HistogramBuckets) +
+			m.eventsWeight*float64(stats.Events)
+	}
+
+	return int(override.Weight * cost)
+}
+
+// newCostModel returns the cost model selected in the configuration
+func newCostModel(cfg model.Reader) costModel {
+	switch modelName := cfg.GetString("cluster_checks.cost_model.type"); modelName {
+	case weightedCostModelName:
+	case busynessCostModelName, "":
+		return busynessCostModel{}
+	default:
+		log.Warnf("Unknown cluster_checks.cost_model.type %q, using %q", modelName, busynessCostModelName)
+		return busynessCostModel{}
+	}
+
+	var overrides []checkCostOverride
+	if err := structure.UnmarshalKey(cfg, "cluster_checks.cost_model.checks", &overrides); err != nil {
+		log.Errorf("Cannot parse cluster_checks.cost_model.checks, ignoring per-check weights: %v", err)
+	}
+
+	m := &weightedCostModel{
+		executionTimeWeight:    cfg.GetFloat64("cluster_checks.cost_model.execution_time_weight"),
+		metricSamplesWeight:    cfg.GetFloat64("cluster_checks.cost_model.metric_samples_weight"),
+		histogramBucketsWeight: cfg.GetFloat64("cluster_checks.cost_model.histogram_buckets_weight"),
+		eventsWeight:           cfg.GetFloat64("cluster_checks.cost_model.events_weight"),
+		memoryWeight:           cfg.GetFloat64("cluster_checks.cost_model.memory_weight"),
+		checks:                 make(map[string]checkCostOverride, len(overrides)),
+	}
+
+	for _, override := range overrides {
+		if override.Name == "" {
+			log.Warnf("Ignoring cluster_checks.cost_model.checks entry without a name")
+			continue
+		}
+		if override.Weight == 0 {
+			override.Weight = 1
+		}
+		m.checks[override.Name] = override
+	}
+
+	return m
+}
+
+// calculateBusyness returns the cost of all the checks running on a node
+func calculateBusyness(cost costModel, checkStats types.CLCRunnersStats) int {
+	busyness := 0
+	for checkID, stats := range checkStats {
+		busyness += cost.checkCost(checkID, stats)
+	}
+	return busyness
+}
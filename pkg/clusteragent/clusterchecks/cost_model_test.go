@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build clusterchecks
+
+package clusterchecks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func TestNewCostModel(t *testing.T) {
+	mockConfig := configmock.New(t)
+	assert.Equal(t, busynessCostModel{}, newCostModel(mockConfig))
+
+	mockConfig.SetWithoutSource("cluster_checks.cost_model.type", "unknown")
+	assert.Equal(t, busynessCostModel{}, newCostModel(mockConfig))
+
+	mockConfig.SetWithoutSource("cluster_checks.cost_model.type", "weighted")
+	mockConfig.SetWithoutSource("cluster_checks.cost_model.execution_time_weight", 0.5)
+	mockConfig.SetWithoutSource("cluster_checks.cost_model.checks", []map[string]interface{}{
+		{"name": "jmx", "weight": 2, "memory_mb": 300},
+		{"name": "http_check", "memory_mb": 10},
+		{"weight": 3},
+	})
+
+	model, ok := newCostModel(mockConfig).(*weightedCostModel)
+	require.True(t, ok)
+	assert.Equal(t, &weightedCostModel{
+		executionTimeWeight:    0.5,
+		metricSamplesWeight:    1,
+		histogramBucketsWeight: 1,
+		eventsWeight:           0.2,
+		memoryWeight:           1,
+		checks: map[string]checkCostOverride{
+			"jmx":        {Name: "jmx", Weight: 2, MemoryMB: 300},
+			"http_check": {Name: "http_check", Weight: 1, MemoryMB: 10},
+		},
+	}, model)
+}
+
+func TestWeightedCostModel(t *testing.T) {
+	model := &weightedCostModel{
+		executionTimeWeight:    0.5,
+		metricSamplesWeight:    1,
+		histogramBucketsWeight: 2,
+		eventsWeight:           0.5,
+		memoryWeight:           0.1,
+		checks: map[string]checkCostOverride{
+			"jmx": {Name: "jmx", Weight: 2, MemoryMB: 300},
+		},
+	}
+
+	stats := types.CLCRunnerStats{
+		AverageExecutionTime: 100,
+		MetricSamples:        20,
+		HistogramBuckets:     5,
+		Events:               4,
+	}
+
+	tests := []struct {
+		name    string
+		checkID string
+		stats   types.CLCRunnerStats
+		want    int
+	}{
+		{
+			name:    "no override",
+			checkID: "http_check:1234",
+			stats:   stats,
+			want:    50 + 20 + 10 + 2,
+		},
+		{
+			name:    "override",
+			checkID: "jmx:1234",
+			stats:   stats,
+			want:    2 * (30 + 50 + 20 + 10 + 2),
+		},
+		{
+			name:    "failing check only accounts for its memory",
+			checkID: "jmx:1234",
+			stats: types.CLCRunnerStats{
+				AverageExecutionTime: 100,
+				MetricSamples:        20,
+				LastExecFailed:       true,
+			},
+			want: 2 * 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, model.checkCost(tt.checkID, tt.stats))
+		})
+	}
+}
@@ -35,6 +35,7 @@ func (d *dispatcher) isolateCheck(isolateCheckID string) types.IsolateResponse {
 	}
 
 	proposedDistribution := newChecksDistribution(currentDistribution.runnerWorkers())
+	proposedDistribution.constraints = d.placementConstraints
 
 	for _, checkID := range currentDistribution.checksSortedByWorkersNeeded() {
 		if checkID == isolateCheckID {
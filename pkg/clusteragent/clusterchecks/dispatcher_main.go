@@ -32,6 +32,8 @@ type dispatcher struct {
 	excludedChecks                map[string]struct{}
 	excludedChecksFromDispatching map[string]struct{}
 	rebalancingPeriod             time.Duration
+	costModel                     costModel
+	placementConstraints          placementConstraints
 }
 
 func newDispatcher() *dispatcher {
@@ -60,6 +62,8 @@ func newDispatcher() *dispatcher {
 	}
 
 	d.rebalancingPeriod = pkgconfigsetup.Datadog().GetDuration("cluster_checks.rebalance_period")
+	d.costModel = newCostModel(pkgconfigsetup.Datadog())
+	d.placementConstraints = loadPlacementConstraints(pkgconfigsetup.Datadog())
 
 	hname, _ := hostname.Get(context.TODO())
 	clusterTagValue := clustername.GetClusterName(context.TODO(), hname)
@@ -42,41 +42,59 @@ func (w Weights) Len() int           { return len(w) }
 func (w Weights) Less(i, j int) bool { return w[i].busyness > w[j].busyness }
 func (w Weights) Swap(i, j int)      { w[i], w[j] = w[j], w[i] }
 
-func (d *dispatcher) calculateAvg() (int, error) {
-	busyness := 0
-	length := 0
+// runnersSnapshot holds a copy of the runner stats of each node. The
+// busyness based rebalancing plans the check moves on a snapshot, so that
+// they can be simulated without modifying the store.
+type runnersSnapshot map[string]types.CLCRunnersStats
 
+// snapshotRunners copies the runner stats of all the nodes
+func (d *dispatcher) snapshotRunners() runnersSnapshot {
 	d.store.RLock()
 	defer d.store.RUnlock()
 
-	for _, node := range d.store.nodes {
-		busyness += node.GetBusyness(busynessFunc)
-		length++
+	snapshot := make(runnersSnapshot, len(d.store.nodes))
+	for nodeName, node := range d.store.nodes {
+		node.RLock()
+		stats := make(types.CLCRunnersStats, len(node.clcRunnerStats))
+		for checkID, checkStats := range node.clcRunnerStats {
+			stats[checkID] = checkStats
+		}
+		node.RUnlock()
+		snapshot[nodeName] = stats
 	}
 
-	if length == 0 {
+	return snapshot
+}
+
+func (d *dispatcher) calculateAvg() (int, error) {
+	return d.snapshotRunners().average(d.costModel)
+}
+
+// average returns the average busyness of the nodes
+func (s runnersSnapshot) average(cost costModel) (int, error) {
+	if len(s) == 0 {
 		return -1, fmt.Errorf("zero nodes reporting")
 	}
 
-	return busyness / length, nil
+	busyness := 0
+	for _, stats := range s {
+		busyness += calculateBusyness(cost, stats)
+	}
+
+	return busyness / len(s), nil
 }
 
 // getDiffAndWeights creates a map that contains the difference between
 // the busyness on each node and the total average busyness, and a Weights
 // struct containing nodes and their busyness values
-func (d *dispatcher) getDiffAndWeights(avg int) (map[string]int, Weights) {
-	diffMap := make(map[string]int)
-	weights := Weights{}
-
-	d.store.RLock()
-	defer d.store.RUnlock()
+func (s runnersSnapshot) getDiffAndWeights(cost costModel, avg int) (map[string]int, Weights) {
+	diffMap := s.updateDiff(cost, avg)
+	weights := make(Weights, 0, len(diffMap))
 
-	for nodeName, node := range d.store.nodes {
-		busyness := node.GetBusyness(busynessFunc)
-		diffMap[nodeName] = busyness - avg
+	for nodeName, diff := range diffMap {
 		weights = append(weights, Weight{
 			nodeName: nodeName,
-			busyness: busyness,
+			busyness: diff + avg,
 		})
 	}
 	return diffMap, weights
@@ -84,15 +102,11 @@ func (d *dispatcher) getDiffAndWeights(avg int) (map[string]int, Weights) {
 
 // updateDiff creates a map that contains the difference between
 // the busyness on each node and the total average busyness.
-func (d *dispatcher) updateDiff(avg int) map[string]int {
-	diffMap := make(map[string]int)
+func (s runnersSnapshot) updateDiff(cost costModel, avg int) map[string]int {
+	diffMap := make(map[string]int, len(s))
 
-	d.store.RLock()
-	defer d.store.RUnlock()
-
-	for nodeName, node := range d.store.nodes {
-		busyness := node.GetBusyness(busynessFunc)
-		diffMap[nodeName] = busyness - avg
+	for nodeName, stats := range s {
+		diffMap[nodeName] = calculateBusyness(cost, stats) - avg
 	}
 
 	return diffMap
@@ -102,17 +116,44 @@ func (d *dispatcher) updateDiff(avg int) map[string]int {
 // A check Xi running on a node N is chosen to move to another node if it satisfies the following
 // Weight(Xi) >  Weight(Xj) (for each j != i, 0 <= j < len(weights))
 // where Weight(X) is the busyness value caused by running the check X.
-func (d *dispatcher) pickCheckToMove(nodeName string) (string, int, error) {
-	d.store.RLock()
-	node, found := d.store.getNodeStore(nodeName)
-	d.store.RUnlock()
-
+// Checks are considered in ID order to make the choice deterministic.
+func (s runnersSnapshot) pickCheckToMove(cost costModel, nodeName string) (string, int, error) {
+	stats, found := s[nodeName]
 	if !found {
 		log.Debugf("Node %s not found in store. Won't consider moving check", nodeName)
 		return "", -1, fmt.Errorf("node %s not found in store", nodeName)
 	}
 
-	return node.GetMostWeightedClusterCheck(busynessFunc)
+	checkID := ""
+	checkWeight := -1
+	for _, id := range orderedKeys(stats) {
+		if !stats[id].IsClusterCheck {
+			// Only consider Cluster Checks
+			continue
+		}
+		if weight := cost.checkCost(id, stats[id]); weight > checkWeight {
+			checkWeight = weight
+			checkID = id
+		}
+	}
+
+	if checkID == "" {
+		log.Debugf("Node %s has no check stats for cluster checks: %v", nodeName, stats)
+		return "", -1, fmt.Errorf("no cluster checks found on node %s", nodeName)
+	}
+
+	return checkID, checkWeight, nil
+}
+
+// move updates the snapshot as if the check was moved from src to dest
+func (s runnersSnapshot) move(src, dest, checkID string) {
+	stats, found := s[src][checkID]
+	if !found {
+		return
+	}
+
+	delete(s[src], checkID)
+	s[dest][checkID] = stats
 }
 
 // pickNode select the most appropriate node to receive a specific check.
@@ -120,7 +161,8 @@ func (d *dispatcher) pickCheckToMove(nodeName string) (string, int, error) {
 // if it satisfies the following
 // Diff(Ni) < Diff(Nj) (for each j != i, 0 <= j < len(nodes))
 // where Diff(N) is the difference between the busyness on N and the total average busyness.
-func pickNode(diffMap map[string]int, sourceNode string) string {
+// Nodes where the check would violate the placement constraints are skipped.
+func (s runnersSnapshot) pickNode(diffMap map[string]int, sourceNode string, checkID string, constraints placementConstraints) string {
 	firstItr := true
 	minDiff := 0
 	pickedNode := ""
@@ -128,6 +170,9 @@ func pickNode(diffMap map[string]int, sourceNode string) string {
 		if node == sourceNode {
 			continue
 		}
+		if !constraints.allows(checkID, countByCheckName(s[node], "")) {
+			continue
+		}
 		if diffMap[node] < minDiff || firstItr {
 			minDiff = diffMap[node]
 			pickedNode = node
@@ -179,6 +224,23 @@ func (d *dispatcher) rebalance(force bool) []types.RebalanceResponse {
 	return d.rebalanceUsingBusyness()
 }
 
+// simulateRebalance returns the check moves that rebalance would make,
+// without moving any check.
+func (d *dispatcher) simulateRebalance(force bool) []types.RebalanceResponse {
+	// Collect CLC runners stats and update cache before planning
+	d.updateRunnersStats()
+
+	if pkgconfigsetup.Datadog().GetBool("cluster_checks.rebalance_with_utilization") {
+		currentChecksDistribution, proposedDistribution, worthIt := d.planRebalanceUsingUtilization(force)
+		if !worthIt {
+			return []types.RebalanceResponse{}
+		}
+		return plannedMoves(proposedDistribution, currentChecksDistribution)
+	}
+
+	return d.planRebalanceUsingBusyness()
+}
+
 // rebalanceUsingBusyness tries to optimize the checks repartition on cluster
 // level check runners with less possible check moves based on the runner stats.
 func (d *dispatcher) rebalanceUsingBusyness() []types.RebalanceResponse {
@@ -190,28 +252,54 @@ func (d *dispatcher) rebalanceUsingBusyness() []types.RebalanceResponse {
 		rebalancingDuration.Set(time.Since(start).Seconds(), le.JoinLeaderValue)
 	}()
 
+	checksMoved := []types.RebalanceResponse{}
+	for _, move := range d.planRebalanceUsingBusyness() {
+		rebalancingDecisions.Inc(le.JoinLeaderValue)
+		err := d.moveCheck(move.SourceNodeName, move.DestNodeName, move.CheckID)
+		if err != nil {
+			log.Debugf("Cannot move check %s: %v", move.CheckID, err)
+			continue
+		}
+
+		successfulRebalancing.Inc(le.JoinLeaderValue)
+		checksMoved = append(checksMoved, move)
+	}
+
+	return checksMoved
+}
+
+// planRebalanceUsingBusyness plans the check moves of rebalanceUsingBusyness
+// on a snapshot of the runner stats. The checks violating the placement
+// constraints are moved first, then checks are moved from the nodes with a
+// busyness above the average to the least busy ones.
+func (d *dispatcher) planRebalanceUsingBusyness() []types.RebalanceResponse {
 	log.Trace("Trying to rebalance cluster checks distribution if needed")
-	totalAvg, err := d.calculateAvg()
+	snapshot := d.snapshotRunners()
+	totalAvg, err := snapshot.average(d.costModel)
 	if err != nil {
 		log.Debugf("Cannot rebalance checks: %v", err)
 		return nil
 	}
 
-	checksMoved := []types.RebalanceResponse{}
-	diffMap, weights := d.getDiffAndWeights(totalAvg)
+	checksMoved := snapshot.planConstraintMoves(d.costModel, d.placementConstraints, totalAvg)
+	diffMap, weights := snapshot.getDiffAndWeights(d.costModel, totalAvg)
 	sort.Sort(weights)
 
 	for _, nodeWeight := range weights {
 		for diffMap[nodeWeight.nodeName] > 0 {
 			// try to move checks from a node only of the node busyness is above the average
 			sourceNodeName := nodeWeight.nodeName
-			checkID, checkWeight, err := d.pickCheckToMove(sourceNodeName)
+			checkID, checkWeight, err := snapshot.pickCheckToMove(d.costModel, sourceNodeName)
 			if err != nil {
 				log.Debugf("Cannot pick a check to move from node %s: %v", sourceNodeName, err)
 				break
 			}
 
-			destNodeName := pickNode(diffMap, sourceNodeName)
+			destNodeName := snapshot.pickNode(diffMap, sourceNodeName, checkID, d.placementConstraints)
+			if destNodeName == "" {
+				log.Debugf("Cannot find a node to move check %s to", checkID)
+				break
+			}
 			sourceDiff := diffMap[sourceNodeName]
 			destDiff := diffMap[destNodeName]
 
@@ -220,30 +308,60 @@ func (d *dispatcher) rebalanceUsingBusyness() []types.RebalanceResponse {
 			// node's busyness multiplied by the tolerationMargin
 			// value the toleration margin is used to lean towards
 			// stability over perfectly optimal balance
-			if destDiff+checkWeight < int(float64(sourceDiff)*tolerationMargin) {
-				rebalancingDecisions.Inc(le.JoinLeaderValue)
-				err = d.moveCheck(sourceNodeName, destNodeName, checkID)
-				if err != nil {
-					log.Debugf("Cannot move check %s: %v", checkID, err)
-					continue
-				}
-
-				successfulRebalancing.Inc(le.JoinLeaderValue)
-				log.Tracef("Check %s with weight %d moved, total avg: %d, source diff: %d, dest diff: %d",
-					checkID, checkWeight, totalAvg, sourceDiff, destDiff)
-				// diffMap needs to be updated on every check moved
-				diffMap = d.updateDiff(totalAvg)
-				checksMoved = append(checksMoved, types.RebalanceResponse{
-					CheckID:        checkID,
-					CheckWeight:    checkWeight,
-					SourceNodeName: sourceNodeName,
-					SourceDiff:     sourceDiff,
-					DestNodeName:   destNodeName,
-					DestDiff:       destDiff,
-				})
-			} else {
+			if destDiff+checkWeight >= int(float64(sourceDiff)*tolerationMargin) {
 				break
 			}
+
+			snapshot.move(sourceNodeName, destNodeName, checkID)
+			log.Tracef("Check %s with weight %d moved, total avg: %d, source diff: %d, dest diff: %d",
+				checkID, checkWeight, totalAvg, sourceDiff, destDiff)
+			// diffMap needs to be updated on every check moved
+			diffMap = snapshot.updateDiff(d.costModel, totalAvg)
+			checksMoved = append(checksMoved, types.RebalanceResponse{
+				CheckID:        checkID,
+				CheckWeight:    checkWeight,
+				SourceNodeName: sourceNodeName,
+				SourceDiff:     sourceDiff,
+				DestNodeName:   destNodeName,
+				DestDiff:       destDiff,
+			})
+		}
+	}
+
+	return checksMoved
+}
+
+// planConstraintMoves plans the moves of the cluster checks violating the
+// placement constraints to the least busy node where they are allowed.
+func (s runnersSnapshot) planConstraintMoves(cost costModel, constraints placementConstraints, avg int) []types.RebalanceResponse {
+	checksMoved := []types.RebalanceResponse{}
+	if len(constraints) == 0 {
+		return checksMoved
+	}
+
+	for _, sourceNodeName := range orderedKeys(s) {
+		for _, checkID := range orderedKeys(s[sourceNodeName]) {
+			stats, found := s[sourceNodeName][checkID]
+			if !found || !stats.IsClusterCheck || constraints.allows(checkID, countByCheckName(s[sourceNodeName], checkID)) {
+				continue
+			}
+
+			diffMap := s.updateDiff(cost, avg)
+			destNodeName := s.pickNode(diffMap, sourceNodeName, checkID, constraints)
+			if destNodeName == "" {
+				log.Debugf("Check %s violates the placement constraints on node %s, but no other node satisfies them", checkID, sourceNodeName)
+				continue
+			}
+
+			s.move(sourceNodeName, destNodeName, checkID)
+			checksMoved = append(checksMoved, types.RebalanceResponse{
+				CheckID:        checkID,
+				CheckWeight:    cost.checkCost(checkID, stats),
+				SourceNodeName: sourceNodeName,
+				SourceDiff:     diffMap[sourceNodeName],
+				DestNodeName:   destNodeName,
+				DestDiff:       diffMap[destNodeName],
+			})
 		}
 	}
 
@@ -307,9 +425,24 @@ func (d *dispatcher) rebalanceUsingUtilization(force bool) []types.RebalanceResp
 		rebalancingDuration.Set(time.Since(start).Seconds(), le.JoinLeaderValue)
 	}()
 
+	currentChecksDistribution, proposedDistribution, worthIt := d.planRebalanceUsingUtilization(force)
+	if !worthIt {
+		setPredictedUtilization(currentChecksDistribution)
+		return nil
+	}
+
+	setPredictedUtilization(proposedDistribution)
+	return d.applyDistribution(proposedDistribution, currentChecksDistribution)
+}
+
+// planRebalanceUsingUtilization computes the distribution proposed by
+// rebalanceUsingUtilization, and whether it's worth moving checks to apply
+// it.
+func (d *dispatcher) planRebalanceUsingUtilization(force bool) (checksDistribution, checksDistribution, bool) {
 	currentChecksDistribution := d.currentDistribution()
 
 	proposedDistribution := newChecksDistribution(currentChecksDistribution.runnerWorkers())
+	proposedDistribution.constraints = d.placementConstraints
 
 	// First all the checks that are excluded from rebalancing are added to the
 	// same runner where they are currently running.
@@ -353,15 +486,12 @@ func (d *dispatcher) rebalanceUsingUtilization(force bool) []types.RebalanceResp
 				proposedUtilizationStdDev, currentUtilizationStdDev, jsonDistribution)
 		}
 
-		setPredictedUtilization(proposedDistribution)
-
-		return d.applyDistribution(proposedDistribution, currentChecksDistribution)
+		return currentChecksDistribution, proposedDistribution, true
 	}
 
 	log.Debugf("Didn't find a distribution better enough so that rescheduling checks is worth it (current utilization stddev: %.3f, found utilization stddev: %.3f)",
 		currentUtilizationStdDev, proposedUtilizationStdDev)
-	setPredictedUtilization(currentChecksDistribution)
-	return nil
+	return currentChecksDistribution, proposedDistribution, false
 }
 
 func (d *dispatcher) currentDistribution() checksDistribution {
@@ -375,6 +505,7 @@ func (d *dispatcher) currentDistribution() checksDistribution {
 	}
 
 	distribution := newChecksDistribution(currentWorkersPerRunner)
+	distribution.constraints = d.placementConstraints
 
 	for nodeName, nodeStoreInfo := range d.store.nodes {
 		for checkID, stats := range nodeStoreInfo.clcRunnerStats {
@@ -410,24 +541,36 @@ func (d *dispatcher) currentDistribution() checksDistribution {
 func (d *dispatcher) applyDistribution(proposedDistribution checksDistribution, currentDistribution checksDistribution) []types.RebalanceResponse {
 	var checksMoved []types.RebalanceResponse
 
-	for checkID, checkStatus := range proposedDistribution.Checks {
-		currentNode := currentDistribution.runnerForCheck(checkID)
-		proposedNode := checkStatus.Runner
-
-		if proposedNode == currentNode {
-			continue
-		}
-
+	for _, move := range plannedMoves(proposedDistribution, currentDistribution) {
 		rebalancingDecisions.Inc(le.JoinLeaderValue)
 
-		err := d.moveCheck(currentNode, proposedNode, checkID)
+		err := d.moveCheck(move.SourceNodeName, move.DestNodeName, move.CheckID)
 		if err != nil {
-			log.Warnf("Cannot move check %s: %v", checkID, err)
+			log.Warnf("Cannot move check %s: %v", move.CheckID, err)
 			continue
 		}
 
 		successfulRebalancing.Inc(le.JoinLeaderValue)
 
+		checksMoved = append(checksMoved, move)
+	}
+
+	return checksMoved
+}
+
+// plannedMoves returns the check moves needed to go from the current
+// distribution to the proposed one
+func plannedMoves(proposedDistribution checksDistribution, currentDistribution checksDistribution) []types.RebalanceResponse {
+	checksMoved := []types.RebalanceResponse{}
+
+	for _, checkID := range orderedKeys(proposedDistribution.Checks) {
+		currentNode := currentDistribution.runnerForCheck(checkID)
+		proposedNode := proposedDistribution.Checks[checkID].Runner
+
+		if proposedNode == currentNode {
+			continue
+		}
+
 		checksMoved = append(
 			checksMoved,
 			types.RebalanceResponse{
@@ -448,6 +591,11 @@ func setPredictedUtilization(distribution checksDistribution) {
 }
 
 func rebalanceIsWorthIt(currentDistribution checksDistribution, proposedDistribution checksDistribution, minPercImprovement int) bool {
+	// Fixing placement constraint violations is always worth it
+	if proposedDistribution.numConstraintViolations() < currentDistribution.numConstraintViolations() {
+		return true
+	}
+
 	// If the current utilization stddev is already good enough, consider that
 	// rescheduling checks is not worth it, unless the new distribution has
 	// fewer runners with a high utilization or leaves fewer runners empty.
@@ -1596,6 +1596,42 @@ func TestRebalanceUsingUtilization(t *testing.T) {
 	assert.Empty(t, checksMoved)
 }
 
+func TestSimulateRebalance(t *testing.T) {
+	testDispatcher := newDispatcher()
+	testDispatcher.costModel = busynessCostModel{}
+
+	testDispatcher.store.active = true
+	testDispatcher.store.nodes["node1"] = newNodeStore("node1", "")
+	testDispatcher.store.nodes["node2"] = newNodeStore("node2", "")
+	testDispatcher.store.nodes["node1"].clcRunnerStats = types.CLCRunnersStats{
+		"check1": {MetricSamples: 100, IsClusterCheck: true},
+		"check2": {MetricSamples: 40, IsClusterCheck: true},
+	}
+
+	expectedMoves := []types.RebalanceResponse{
+		{
+			CheckID:        "check1",
+			CheckWeight:    100,
+			SourceNodeName: "node1",
+			SourceDiff:     70,
+			DestNodeName:   "node2",
+			DestDiff:       -70,
+		},
+	}
+
+	// The simulation doesn't move any check
+	assert.Equal(t, expectedMoves, testDispatcher.simulateRebalance(false))
+	assert.Len(t, testDispatcher.store.nodes["node1"].clcRunnerStats, 2)
+	assert.Empty(t, testDispatcher.store.nodes["node2"].clcRunnerStats)
+	requireNotLocked(t, testDispatcher.store)
+
+	// The rebalance makes the simulated moves
+	assert.Equal(t, expectedMoves, testDispatcher.rebalance(false))
+	assert.Len(t, testDispatcher.store.nodes["node1"].clcRunnerStats, 1)
+	assert.Len(t, testDispatcher.store.nodes["node2"].clcRunnerStats, 1)
+	requireNotLocked(t, testDispatcher.store)
+}
+
 func TestRebalanceIsWorthIt(t *testing.T) {
 	workersPerRunner := map[string]int{
 		"runner1": 3,
@@ -120,6 +120,16 @@ func (h *Handler) RebalanceClusterChecks(force bool) ([]types.RebalanceResponse,
 	return response, nil
 }
 
+// SimulateRebalanceClusterChecks returns the check moves an attempt to
+// rebalance cluster checks would make, without moving any check
+func (h *Handler) SimulateRebalanceClusterChecks(force bool) ([]types.RebalanceResponse, error) {
+	if !h.dispatcher.advancedDispatching {
+		return nil, fmt.Errorf("no checks to rebalance: advanced dispatching is not enabled")
+	}
+
+	return h.dispatcher.simulateRebalance(force), nil
+}
+
 // IsolateCheck triggers an attempt to isolate a check in a runner. Other checks
 // will be redistributed to other runners using the existing rebalancing logic.
 func (h *Handler) IsolateCheck(isolateCheckID string) types.IsolateResponse {
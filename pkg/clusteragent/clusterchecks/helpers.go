@@ -42,15 +42,6 @@ func timestampNow() int64 {
 	return time.Now().Unix()
 }
 
-// calculateBusyness returns the busyness value of a node
-func calculateBusyness(checkStats types.CLCRunnersStats) int {
-	busyness := 0
-	for _, stats := range checkStats {
-		busyness += busynessFunc(stats)
-	}
-	return busyness
-}
-
 // busynessFunc returns the weight of a check
 func busynessFunc(s types.CLCRunnerStats) int {
 	if s.LastExecFailed {
@@ -64,7 +55,7 @@ func busynessFunc(s types.CLCRunnerStats) int {
 }
 
 // orderedKeys sorts the keys of a map and return them in a slice
-func orderedKeys(m map[string]int) []string {
+func orderedKeys[V any](m map[string]V) []string {
 	keys := []string{}
 	for key := range m {
 		keys = append(keys, key)
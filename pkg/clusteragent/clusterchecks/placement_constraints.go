@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build clusterchecks
+
+package clusterchecks
+
+import (
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// placementConstraint prevents the instances of a check from running on the
+// same runner as the instances of other checks. Listing the check itself in
+// AntiAffinity spreads its instances across runners.
+type placementConstraint struct {
+	Check        string   `mapstructure:"check"`
+	AntiAffinity []string `mapstructure:"anti_affinity"`
+}
+
+// placementConstraints maps a check name to the names of the checks it must
+// not share a runner with. Anti-affinity is symmetric.
+type placementConstraints map[string]map[string]struct{}
+
+func newPlacementConstraints(constraints []placementConstraint) placementConstraints {
+	res := placementConstraints{}
+
+	add := func(a, b string) {
+		if _, found := res[a]; !found {
+			res[a] = map[string]struct{}{}
+		}
+		res[a][b] = struct{}{}
+	}
+
+	for _, constraint := range constraints {
+		if constraint.Check == "" {
+			log.Warnf("Ignoring cluster_checks.placement_constraints entry without a check name")
+			continue
+		}
+		for _, other := range constraint.AntiAffinity {
+			add(constraint.Check, other)
+			add(other, constraint.Check)
+		}
+	}
+
+	return res
+}
+
+// loadPlacementConstraints returns the placement constraints set in the
+// configuration
+func loadPlacementConstraints(cfg model.Reader) placementConstraints {
+	var constraints []placementConstraint
+	if err := structure.UnmarshalKey(cfg, "cluster_checks.placement_constraints", &constraints); err != nil {
+		log.Errorf("Cannot parse cluster_checks.placement_constraints, ignoring them: %v", err)
+		return nil
+	}
+
+	return newPlacementConstraints(constraints)
+}
+
+// allows returns whether a check can run on a runner, given the number of
+// instances of each check already running there.
+func (c placementConstraints) allows(checkID string, runnerChecks map[string]int) bool {
+	antiAffinity, found := c[checkid.IDToCheckName(checkid.ID(checkID))]
+	if !found {
+		return true
+	}
+
+	for checkName := range antiAffinity {
+		if runnerChecks[checkName] > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// countByCheckName returns the number of instances of each check in the
+// given check IDs, excluding skipCheckID.
+func countByCheckName[T any](checks map[string]T, skipCheckID string) map[string]int {
+	res := make(map[string]int, len(checks))
+	for checkID := range checks {
+		if checkID == skipCheckID {
+			continue
+		}
+		res[checkid.IDToCheckName(checkid.ID(checkID))]++
+	}
+	return res
+}
@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build clusterchecks
+
+package clusterchecks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func TestPlacementConstraints(t *testing.T) {
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("cluster_checks.placement_constraints", []map[string]interface{}{
+		{"check": "kafka_consumer", "anti_affinity": []string{"kafka_consumer"}},
+		{"check": "jmx", "anti_affinity": []string{"postgres"}},
+		{"anti_affinity": []string{"redisdb"}},
+	})
+
+	constraints := loadPlacementConstraints(mockConfig)
+	assert.Equal(t, placementConstraints{
+		"kafka_consumer": {"kafka_consumer": {}},
+		"jmx":            {"postgres": {}},
+		"postgres":       {"jmx": {}},
+	}, constraints)
+
+	assert.True(t, constraints.allows("kafka_consumer:1", map[string]int{"jmx": 1}))
+	assert.False(t, constraints.allows("kafka_consumer:1", map[string]int{"kafka_consumer": 1}))
+	assert.False(t, constraints.allows("postgres:1", map[string]int{"jmx": 2}))
+	assert.True(t, constraints.allows("redisdb:1", map[string]int{"redisdb": 1}))
+
+	var noConstraints placementConstraints
+	assert.True(t, noConstraints.allows("kafka_consumer:1", map[string]int{"kafka_consumer": 1}))
+}
+
+func TestDistributionWithPlacementConstraints(t *testing.T) {
+	distribution := newChecksDistribution(map[string]int{
+		"runner1": 4,
+		"runner2": 4,
+	})
+	distribution.constraints = newPlacementConstraints([]placementConstraint{
+		{Check: "kafka_consumer", AntiAffinity: []string{"kafka_consumer"}},
+	})
+
+	distribution.addCheck("kafka_consumer:1", 0.1, "runner1")
+	distribution.addCheck("http_check:1", 2, "runner2")
+
+	// runner2 is busier, but runner1 already runs a replica
+	distribution.addToLeastBusy("kafka_consumer:2", 0.1, "", "")
+	assert.Equal(t, "runner2", distribution.runnerForCheck("kafka_consumer:2"))
+	assert.Zero(t, distribution.numConstraintViolations())
+
+	// no runner satisfies the constraints, they are ignored
+	distribution.addToLeastBusy("kafka_consumer:3", 0.1, "", "")
+	assert.Equal(t, "runner1", distribution.runnerForCheck("kafka_consumer:3"))
+	assert.Equal(t, 2, distribution.numConstraintViolations())
+}
+
+func TestPlanRebalanceWithPlacementConstraints(t *testing.T) {
+	testDispatcher := newDispatcher()
+	testDispatcher.costModel = busynessCostModel{}
+	testDispatcher.placementConstraints = newPlacementConstraints([]placementConstraint{
+		{Check: "kafka_consumer", AntiAffinity: []string{"kafka_consumer"}},
+	})
+
+	testDispatcher.store.active = true
+	for _, node := range []string{"node1", "node2", "node3"} {
+		testDispatcher.store.nodes[node] = newNodeStore(node, "")
+	}
+
+	// The nodes are balanced, but both kafka_consumer replicas run on node1
+	testDispatcher.store.nodes["node1"].clcRunnerStats = types.CLCRunnersStats{
+		"kafka_consumer:1": {MetricSamples: 10, IsClusterCheck: true},
+		"kafka_consumer:2": {MetricSamples: 10, IsClusterCheck: true},
+	}
+	testDispatcher.store.nodes["node2"].clcRunnerStats = types.CLCRunnersStats{
+		"http_check:1": {MetricSamples: 20, IsClusterCheck: true},
+	}
+	testDispatcher.store.nodes["node3"].clcRunnerStats = types.CLCRunnersStats{
+		"http_check:2": {MetricSamples: 25, IsClusterCheck: true},
+	}
+
+	moves := testDispatcher.planRebalanceUsingBusyness()
+	assert.Equal(t, []types.RebalanceResponse{
+		{
+			CheckID:        "kafka_consumer:1",
+			CheckWeight:    10,
+			SourceNodeName: "node1",
+			SourceDiff:     -1,
+			DestNodeName:   "node2",
+			DestDiff:       -1,
+		},
+	}, moves)
+
+	// planning doesn't modify the store
+	assert.Len(t, testDispatcher.store.nodes["node1"].clcRunnerStats, 2)
+	requireNotLocked(t, testDispatcher.store)
+}
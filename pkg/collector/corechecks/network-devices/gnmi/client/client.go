@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package client implements a gNMI client subscribing to network device telemetry
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client is a gNMI client
+type Client struct {
+	address     string
+	username    string
+	password    string
+	tlsConfig   *tls.Config
+	conn        *grpc.ClientConn
+	gnmiClient  gnmipb.GNMIClient
+	dialOptions []grpc.DialOption
+}
+
+// ClientOptions are the functional options for the gNMI client
+type ClientOptions func(*Client)
+
+// NewClient creates a new gNMI client, the connection is established on the first RPC
+func NewClient(address string, options ...ClientOptions) (*Client, error) {
+	client := &Client{
+		address: address,
+	}
+	for _, opt := range options {
+		opt(client)
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if client.tlsConfig != nil {
+		transportCredentials = credentials.NewTLS(client.tlsConfig)
+	}
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}, client.dialOptions...)
+
+	conn, err := grpc.NewClient(address, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create gNMI client for %s: %w", address, err)
+	}
+	client.conn = conn
+	client.gnmiClient = gnmipb.NewGNMIClient(conn)
+	return client, nil
+}
+
+// WithCredentials is a functional option to authenticate RPCs with a username and password
+func WithCredentials(username string, password string) ClientOptions {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithTLSConfig is a functional option to connect over TLS. A certificate and key enable mutual TLS.
+func WithTLSConfig(insecure bool, caFile string, certFile string, keyFile string, serverName string) (ClientOptions, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+		ServerName:         serverName,
+	}
+
+	if caFile != "" {
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("both a certificate and a key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return func(c *Client) {
+		c.tlsConfig = tlsConfig
+	}, nil
+}
+
+// WithDialOptions is a functional option to add gRPC dial options
+func WithDialOptions(dialOptions ...grpc.DialOption) ClientOptions {
+	return func(c *Client) {
+		c.dialOptions = append(c.dialOptions, dialOptions...)
+	}
+}
+
+// Subscribe opens a subscription and calls the handler with every response until the
+// context is cancelled, the stream ends or the handler returns an error
+func (c *Client) Subscribe(ctx context.Context, request *gnmipb.SubscribeRequest, handler func(*gnmipb.SubscribeResponse) error) error {
+	if c.username != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", c.username, "password", c.password)
+	}
+	stream, err := c.gnmiClient.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open subscription to %s: %w", c.address, err)
+	}
+	if err := stream.Send(request); err != nil {
+		return fmt.Errorf("unable to send subscription to %s: %w", c.address, err)
+	}
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("subscription closed by %s", c.address)
+		}
+		if err != nil {
+			return fmt.Errorf("subscription to %s failed: %w", c.address, err)
+		}
+		if err := handler(response); err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the connection to the device
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// ParsePath parses a gNMI path in its string form, e.g. `/interfaces/interface[name=Ethernet1/1]/state`.
+// An origin can be set before the first slash, e.g. `openconfig:/system/state`.
+func ParsePath(path string) (*gnmipb.Path, error) {
+	result := &gnmipb.Path{}
+	if i := strings.Index(path, ":/"); i > 0 && !strings.ContainsAny(path[:i], "/[") {
+		result.Origin = path[:i]
+		path = path[i+1:]
+	}
+	path = strings.TrimPrefix(path, "/")
+	for len(path) > 0 {
+		elem, rest, err := parsePathElem(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		result.Elem = append(result.Elem, elem)
+		path = rest
+	}
+	return result, nil
+}
+
+// parsePathElem parses the first element of the path and returns the remaining path
+func parsePathElem(path string) (*gnmipb.PathElem, string, error) {
+	end := strings.IndexAny(path, "/[")
+	if end == -1 {
+		end = len(path)
+	}
+	elem := &gnmipb.PathElem{Name: path[:end]}
+	if elem.Name == "" {
+		return nil, "", fmt.Errorf("empty element name")
+	}
+	path = path[end:]
+	for strings.HasPrefix(path, "[") {
+		eq := strings.Index(path, "=")
+		if eq == -1 {
+			return nil, "", fmt.Errorf("missing '=' in key of element %s", elem.Name)
+		}
+		name := path[1:eq]
+		var value strings.Builder
+		i := eq + 1
+		for ; i < len(path) && path[i] != ']'; i++ {
+			if path[i] == '\\' && i+1 < len(path) {
+				i++
+			}
+			value.WriteByte(path[i])
+		}
+		if i == len(path) {
+			return nil, "", fmt.Errorf("missing ']' in key of element %s", elem.Name)
+		}
+		if name == "" {
+			return nil, "", fmt.Errorf("empty key name in element %s", elem.Name)
+		}
+		if elem.Key == nil {
+			elem.Key = make(map[string]string)
+		}
+		elem.Key[name] = value.String()
+		path = path[i+1:]
+	}
+	if path != "" && path[0] != '/' {
+		return nil, "", fmt.Errorf("unexpected character after keys of element %s", elem.Name)
+	}
+	return elem, strings.TrimPrefix(path, "/"), nil
+}
+
+// PathToString returns the string form of a gNMI path, keys are sorted by name
+func PathToString(path *gnmipb.Path) string {
+	var builder strings.Builder
+	if path.GetOrigin() != "" {
+		builder.WriteString(path.GetOrigin())
+		builder.WriteByte(':')
+	}
+	for _, elem := range path.GetElem() {
+		builder.WriteByte('/')
+		builder.WriteString(elem.GetName())
+		keys := make([]string, 0, len(elem.GetKey()))
+		for key := range elem.GetKey() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			builder.WriteString("[" + key + "=")
+			builder.WriteString(strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(elem.GetKey()[key]))
+			builder.WriteByte(']')
+		}
+	}
+	if builder.Len() == 0 {
+		return "/"
+	}
+	return builder.String()
+}
+
+// JoinPaths returns the full path of an update sent with a notification prefix
+func JoinPaths(prefix *gnmipb.Path, path *gnmipb.Path) *gnmipb.Path {
+	origin := path.GetOrigin()
+	if origin == "" {
+		origin = prefix.GetOrigin()
+	}
+	elems := make([]*gnmipb.PathElem, 0, len(prefix.GetElem())+len(path.GetElem()))
+	elems = append(elems, prefix.GetElem()...)
+	elems = append(elems, path.GetElem()...)
+	return &gnmipb.Path{Origin: origin, Target: prefix.GetTarget(), Elem: elems}
+}
+
+// MatchPath returns true when the path is, or is under, the pattern. Keys missing from
+// the pattern, or set to `*`, match any value.
+func MatchPath(pattern *gnmipb.Path, path *gnmipb.Path) bool {
+	if len(pattern.GetElem()) > len(path.GetElem()) {
+		return false
+	}
+	for i, patternElem := range pattern.GetElem() {
+		elem := path.GetElem()[i]
+		if patternElem.GetName() != "*" && patternElem.GetName() != elem.GetName() {
+			return false
+		}
+		for key, value := range patternElem.GetKey() {
+			if value != "*" && elem.GetKey()[key] != value {
+				return false
+			}
+		}
+	}
+	return true
+}
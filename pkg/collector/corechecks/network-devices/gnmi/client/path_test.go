@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package client
+
+import (
+	"testing"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParsePath(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		expected *gnmipb.Path
+		str      string
+	}{
+		{
+			path:     "/",
+			expected: &gnmipb.Path{},
+		},
+		{
+			path: "/system/state/hostname",
+			expected: &gnmipb.Path{Elem: []*gnmipb.PathElem{
+				{Name: "system"}, {Name: "state"}, {Name: "hostname"},
+			}},
+		},
+		{
+			path: "openconfig:/interfaces/interface[name=Ethernet1/1]/state",
+			expected: &gnmipb.Path{Origin: "openconfig", Elem: []*gnmipb.PathElem{
+				{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": "Ethernet1/1"}}, {Name: "state"},
+			}},
+		},
+		{
+			path: "network-instances/network-instance[name=default]/protocols/protocol[name=BGP][identifier=BGP]",
+			expected: &gnmipb.Path{Elem: []*gnmipb.PathElem{
+				{Name: "network-instances"},
+				{Name: "network-instance", Key: map[string]string{"name": "default"}},
+				{Name: "protocols"},
+				{Name: "protocol", Key: map[string]string{"name": "BGP", "identifier": "BGP"}},
+			}},
+			str: "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=BGP]",
+		},
+		{
+			path: `/a/b[key=x\]y:/z]`,
+			expected: &gnmipb.Path{Elem: []*gnmipb.PathElem{
+				{Name: "a"}, {Name: "b", Key: map[string]string{"key": "x]y:/z"}},
+			}},
+		},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := ParsePath(tc.path)
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(tc.expected, path), "got %v", path)
+
+			expectedString := tc.str
+			if expectedString == "" {
+				expectedString = tc.path
+			}
+			assert.Equal(t, expectedString, PathToString(path))
+		})
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, path := range []string{
+		"/a//b",
+		"/a[name]",
+		"/a[name=x",
+		"/a[=x]",
+		"/a[name=x]b",
+	} {
+		_, err := ParsePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestJoinPaths(t *testing.T) {
+	prefix, _ := ParsePath("openconfig:/interfaces")
+	prefix.Target = "router1"
+	path, _ := ParsePath("/interface[name=eth0]/state")
+
+	assert.Equal(t, "openconfig:/interfaces/interface[name=eth0]/state", PathToString(JoinPaths(prefix, path)))
+	assert.Equal(t, "router1", JoinPaths(prefix, path).GetTarget())
+	assert.Equal(t, "/interface[name=eth0]/state", PathToString(JoinPaths(nil, path)))
+}
+
+func TestMatchPath(t *testing.T) {
+	path, _ := ParsePath("/interfaces/interface[name=eth0]/state/counters/in-octets")
+	for pattern, expected := range map[string]bool{
+		"/":                                                    true,
+		"/interfaces/interface/state":                          true,
+		"/interfaces/interface[name=*]":                        true,
+		"/interfaces/interface[name=eth0]":                     true,
+		"/interfaces/*/state/counters":                         true,
+		"/interfaces/interface[name=eth1]":                     false,
+		"/interfaces/interface/config":                         false,
+		"/interfaces/interface/state/counters/in-octets/value": false,
+	} {
+		parsed, err := ParsePath(pattern)
+		require.NoError(t, err)
+		assert.Equal(t, expected, MatchPath(parsed, path), pattern)
+	}
+}
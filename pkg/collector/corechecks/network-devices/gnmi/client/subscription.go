@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Subscription modes
+const (
+	ModeSample        = "sample"
+	ModeOnChange      = "on_change"
+	ModeTargetDefined = "target_defined"
+)
+
+// Subscription is a path subscribed to
+type Subscription struct {
+	Path           string
+	Mode           string
+	SampleInterval time.Duration
+}
+
+// NewSubscribeRequest builds a STREAM subscription request for the target
+func NewSubscribeRequest(target string, subscriptions []Subscription, encoding string) (*gnmipb.SubscribeRequest, error) {
+	encodingValue, ok := gnmipb.Encoding_value[strings.ToUpper(encoding)]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+
+	list := &gnmipb.SubscriptionList{
+		Mode:     gnmipb.SubscriptionList_STREAM,
+		Encoding: gnmipb.Encoding(encodingValue),
+	}
+	if target != "" {
+		list.Prefix = &gnmipb.Path{Target: target}
+	}
+	for _, subscription := range subscriptions {
+		path, err := ParsePath(subscription.Path)
+		if err != nil {
+			return nil, err
+		}
+		var mode gnmipb.SubscriptionMode
+		switch subscription.Mode {
+		case ModeSample:
+			mode = gnmipb.SubscriptionMode_SAMPLE
+		case ModeOnChange:
+			mode = gnmipb.SubscriptionMode_ON_CHANGE
+		case ModeTargetDefined, "":
+			mode = gnmipb.SubscriptionMode_TARGET_DEFINED
+		default:
+			return nil, fmt.Errorf("unknown mode %q for path %s, expected %s, %s or %s", subscription.Mode, subscription.Path, ModeSample, ModeOnChange, ModeTargetDefined)
+		}
+		list.Subscription = append(list.Subscription, &gnmipb.Subscription{
+			Path:           path,
+			Mode:           mode,
+			SampleInterval: uint64(subscription.SampleInterval.Nanoseconds()),
+		})
+	}
+	return &gnmipb.SubscribeRequest{
+		Request: &gnmipb.SubscribeRequest_Subscribe{Subscribe: list},
+	}, nil
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSubscribeRequest(t *testing.T) {
+	request, err := NewSubscribeRequest("router1", []Subscription{
+		{Path: "/interfaces/interface/state/counters", Mode: ModeSample, SampleInterval: 10 * time.Second},
+		{Path: "/interfaces/interface/state/oper-status", Mode: ModeOnChange},
+		{Path: "/system/state"},
+	}, "json_ietf")
+	require.NoError(t, err)
+
+	list := request.GetSubscribe()
+	assert.Equal(t, gnmipb.SubscriptionList_STREAM, list.GetMode())
+	assert.Equal(t, gnmipb.Encoding_JSON_IETF, list.GetEncoding())
+	assert.Equal(t, "router1", list.GetPrefix().GetTarget())
+	require.Len(t, list.GetSubscription(), 3)
+
+	assert.Equal(t, "/interfaces/interface/state/counters", PathToString(list.GetSubscription()[0].GetPath()))
+	assert.Equal(t, gnmipb.SubscriptionMode_SAMPLE, list.GetSubscription()[0].GetMode())
+	assert.Equal(t, uint64(10_000_000_000), list.GetSubscription()[0].GetSampleInterval())
+	assert.Equal(t, gnmipb.SubscriptionMode_ON_CHANGE, list.GetSubscription()[1].GetMode())
+	assert.Equal(t, gnmipb.SubscriptionMode_TARGET_DEFINED, list.GetSubscription()[2].GetMode())
+}
+
+func TestNewSubscribeRequestErrors(t *testing.T) {
+	_, err := NewSubscribeRequest("", []Subscription{{Path: "/system"}}, "xml")
+	assert.ErrorContains(t, err, `unknown encoding "xml"`)
+
+	_, err = NewSubscribeRequest("", []Subscription{{Path: "/system", Mode: "poll"}}, "proto")
+	assert.ErrorContains(t, err, `unknown mode "poll"`)
+
+	_, err = NewSubscribeRequest("", []Subscription{{Path: "/system[name"}}, "proto")
+	assert.ErrorContains(t, err, "invalid path")
+}
@@ -0,0 +1,294 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package gnmi implements NDM gNMI streaming telemetry corecheck
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/report"
+	"github.com/DataDog/datadog-agent/pkg/snmp/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/optional"
+)
+
+const (
+	// CheckName is the name of the check
+	CheckName             = "gnmi"
+	defaultReportInterval = 15 * time.Second
+	defaultSampleInterval = 10
+	defaultEncoding       = "json_ietf"
+	minReconnectDelay     = 1 * time.Second
+	maxReconnectDelay     = 1 * time.Minute
+)
+
+// defaultSubscriptions are the OpenConfig paths mapped to NDM metrics and metadata
+var defaultSubscriptions = []subscriptionCfg{
+	{Path: "/interfaces/interface/state/counters", Mode: client.ModeSample},
+	{Path: "/interfaces/interface/state/oper-status", Mode: client.ModeOnChange},
+	{Path: "/interfaces/interface/state/admin-status", Mode: client.ModeOnChange},
+	{Path: "/interfaces/interface/state/description", Mode: client.ModeOnChange},
+	{Path: "/interfaces/interface/state/ifindex", Mode: client.ModeOnChange},
+	{Path: "/interfaces/interface/ethernet/state/mac-address", Mode: client.ModeOnChange},
+	{Path: "/system/state", Mode: client.ModeOnChange},
+	{Path: "/system/cpus/cpu/state/total", Mode: client.ModeSample},
+	{Path: "/system/memory/state", Mode: client.ModeSample},
+}
+
+type subscriptionCfg struct {
+	Path           string `yaml:"path"`
+	Mode           string `yaml:"mode"`
+	SampleInterval int    `yaml:"sample_interval"`
+}
+
+type tlsCfg struct {
+	Enabled            bool   `yaml:"enabled"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+}
+
+// Configuration for the gNMI check
+type checkCfg struct {
+	Address               string            `yaml:"address"`
+	IPAddress             string            `yaml:"ip_address"`
+	Target                string            `yaml:"target"`
+	Username              string            `yaml:"username"`
+	Password              string            `yaml:"password"`
+	Namespace             string            `yaml:"namespace"`
+	Encoding              string            `yaml:"encoding"`
+	TLS                   tlsCfg            `yaml:"tls"`
+	Subscriptions         []subscriptionCfg `yaml:"subscriptions"`
+	Tags                  []string          `yaml:"tags"`
+	SendNDMMetadata       *bool             `yaml:"send_ndm_metadata"`
+	MinCollectionInterval int               `yaml:"min_collection_interval"`
+}
+
+// GNMICheck subscribes to the telemetry of a device and reports it as NDM metrics and metadata
+type GNMICheck struct {
+	core.CheckBase
+	config         checkCfg
+	reportInterval time.Duration
+	clientOptions  []client.ClientOptions
+	request        *gnmipb.SubscribeRequest
+	metricsSender  *report.GNMISender
+	stopCh         chan struct{}
+}
+
+// subscriptionEvent is a response received from the device, or the error ending the subscription
+type subscriptionEvent struct {
+	response *gnmipb.SubscribeResponse
+	err      error
+}
+
+// Run subscribes to the device until the check is cancelled
+func (c *GNMICheck) Run() error {
+	log.Infof("Starting long-running check %q", c.ID())
+	defer log.Infof("Shutting down long-running check %q", c.ID())
+
+	gnmiClient, err := client.NewClient(c.config.Address, c.clientOptions...)
+	if err != nil {
+		return err
+	}
+	defer gnmiClient.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan subscriptionEvent)
+	go c.subscribe(ctx, gnmiClient, events)
+
+	ticker := time.NewTicker(c.reportInterval)
+	defer ticker.Stop()
+	reachable := false
+	for {
+		select {
+		case <-c.stopCh:
+			return nil
+		case event := <-events:
+			if event.err != nil {
+				log.Warnf("[%s] %s", c.ID(), event.err)
+				reachable = false
+				continue
+			}
+			reachable = true
+			if notification := event.response.GetUpdate(); notification != nil {
+				c.metricsSender.ProcessNotification(notification)
+			}
+		case <-ticker.C:
+			c.metricsSender.SendDeviceMetrics(reachable)
+			if *c.config.SendNDMMetadata {
+				c.metricsSender.SendMetadata(reachable)
+			}
+		}
+	}
+}
+
+// subscribe keeps a subscription open, reconnecting with an exponential backoff
+func (c *GNMICheck) subscribe(ctx context.Context, gnmiClient *client.Client, events chan<- subscriptionEvent) {
+	delay := minReconnectDelay
+	for {
+		err := gnmiClient.Subscribe(ctx, c.request, func(response *gnmipb.SubscribeResponse) error {
+			delay = minReconnectDelay
+			select {
+			case events <- subscriptionEvent{response: response}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		select {
+		case events <- subscriptionEvent{err: err}:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// Configure the gNMI check
+func (c *GNMICheck) Configure(senderManager sender.SenderManager, integrationConfigDigest uint64, rawInstance integration.Data, rawInitConfig integration.Data, source string) error {
+	// Must be called before c.CommonConfigure
+	c.BuildID(integrationConfigDigest, rawInstance, rawInitConfig)
+
+	err := c.CommonConfigure(senderManager, rawInitConfig, rawInstance, source)
+	if err != nil {
+		return err
+	}
+
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	var instanceConfig checkCfg
+
+	// Set defaults before unmarshalling
+	instanceConfig.SendNDMMetadata = boolPointer(true)
+	instanceConfig.Encoding = defaultEncoding
+
+	err = yaml.Unmarshal(rawInstance, &instanceConfig)
+	if err != nil {
+		return err
+	}
+	c.config = instanceConfig
+
+	if c.config.Address == "" {
+		return errors.New("address is required")
+	}
+	if c.config.IPAddress == "" {
+		host, _, err := net.SplitHostPort(c.config.Address)
+		if err != nil {
+			return err
+		}
+		c.config.IPAddress = host
+	}
+
+	if c.config.Namespace == "" {
+		c.config.Namespace = "default"
+	} else {
+		namespace, err := utils.NormalizeNamespace(c.config.Namespace)
+		if err != nil {
+			return err
+		}
+		c.config.Namespace = namespace
+	}
+
+	if len(c.config.Subscriptions) == 0 {
+		c.config.Subscriptions = defaultSubscriptions
+	}
+	subscriptions := make([]client.Subscription, 0, len(c.config.Subscriptions))
+	for _, subscription := range c.config.Subscriptions {
+		sampleInterval := subscription.SampleInterval
+		if sampleInterval == 0 && subscription.Mode == client.ModeSample {
+			sampleInterval = defaultSampleInterval
+		}
+		subscriptions = append(subscriptions, client.Subscription{
+			Path:           subscription.Path,
+			Mode:           subscription.Mode,
+			SampleInterval: time.Duration(sampleInterval) * time.Second,
+		})
+	}
+	c.request, err = client.NewSubscribeRequest(c.config.Target, subscriptions, c.config.Encoding)
+	if err != nil {
+		return err
+	}
+
+	c.clientOptions, err = c.buildClientOptions()
+	if err != nil {
+		return err
+	}
+
+	if c.config.MinCollectionInterval != 0 {
+		c.reportInterval = time.Second * time.Duration(c.config.MinCollectionInterval)
+	}
+
+	c.metricsSender = report.NewGNMISender(sender, c.config.Namespace, c.config.IPAddress, c.config.Tags)
+
+	return nil
+}
+
+func (c *GNMICheck) buildClientOptions() ([]client.ClientOptions, error) {
+	var clientOptions []client.ClientOptions
+
+	if c.config.TLS.Enabled {
+		options, err := client.WithTLSConfig(c.config.TLS.InsecureSkipVerify, c.config.TLS.CAFile, c.config.TLS.CertFile, c.config.TLS.KeyFile, c.config.TLS.ServerName)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions = append(clientOptions, options)
+	}
+
+	if c.config.Username != "" {
+		clientOptions = append(clientOptions, client.WithCredentials(c.config.Username, c.config.Password))
+	}
+
+	return clientOptions, nil
+}
+
+// Cancel stops the gNMI check
+func (c *GNMICheck) Cancel() { close(c.stopCh) }
+
+// Interval returns 0, it makes gNMI a long-running check
+func (c *GNMICheck) Interval() time.Duration { return 0 }
+
+func boolPointer(b bool) *bool {
+	return &b
+}
+
+// Factory creates a new check factory
+func Factory() optional.Option[func() check.Check] {
+	return optional.NewOption(newCheck)
+}
+
+func newCheck() check.Check {
+	return core.NewLongRunningCheckWrapper(newGNMICheck())
+}
+
+func newGNMICheck() *GNMICheck {
+	return &GNMICheck{
+		CheckBase:      core.NewCheckBase(CheckName),
+		reportInterval: defaultReportInterval,
+		stopCh:         make(chan struct{}),
+	}
+}
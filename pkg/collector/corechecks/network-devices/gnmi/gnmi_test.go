@@ -0,0 +1,309 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package gnmi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer"
+	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer/demultiplexerimpl"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/testserver"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+type deps struct {
+	fx.In
+	Demultiplexer demultiplexer.Mock
+}
+
+func createDeps(t *testing.T) deps {
+	return fxutil.Test[deps](t, demultiplexerimpl.MockModule(), defaultforwarder.MockModule(), core.MockBundle())
+}
+
+func uintVal(v uint64) *gnmipb.TypedValue {
+	return &gnmipb.TypedValue{Value: &gnmipb.TypedValue_UintVal{UintVal: v}}
+}
+
+func stringVal(v string) *gnmipb.TypedValue {
+	return &gnmipb.TypedValue{Value: &gnmipb.TypedValue_StringVal{StringVal: v}}
+}
+
+func startDevice(t *testing.T, options testserver.Options) *testserver.Server {
+	server, err := testserver.Start("127.0.0.1:0", options)
+	require.NoError(t, err)
+	t.Cleanup(server.Stop)
+
+	for path, value := range map[string]*gnmipb.TypedValue{
+		"/system/state/hostname":                                    stringVal("router1"),
+		"/system/state/software-version":                            stringVal("4.30.1F"),
+		"/system/memory/state/physical":                             uintVal(8000),
+		"/system/memory/state/used":                                 uintVal(2000),
+		"/system/cpus/cpu[index=0]/state/total/instant":             uintVal(12),
+		"/interfaces/interface[name=eth0]/state/ifindex":            uintVal(1),
+		"/interfaces/interface[name=eth0]/state/description":        stringVal("uplink"),
+		"/interfaces/interface[name=eth0]/state/admin-status":       stringVal("UP"),
+		"/interfaces/interface[name=eth0]/state/oper-status":        stringVal("UP"),
+		"/interfaces/interface[name=eth0]/state/counters/in-octets": uintVal(1000),
+		"/interfaces/interface[name=eth0]/state/config/mtu":         uintVal(1500),
+	} {
+		require.NoError(t, server.Update(path, value))
+	}
+	return server
+}
+
+type metricCall struct {
+	method string
+	name   string
+	value  float64
+	tags   []string
+}
+
+// recorder records the metrics sent while the check runs
+type recorder struct {
+	mu    sync.Mutex
+	calls []metricCall
+}
+
+func (r *recorder) record(method string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, metricCall{method, args.String(0), args.Get(1).(float64), args.Get(3).([]string)})
+	}
+}
+
+// waitFor waits for a metric sent with the given value and tags
+func (r *recorder) waitFor(t *testing.T, method string, name string, value float64, tags ...string) {
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, call := range r.calls {
+			if call.method == method && call.name == name && call.value == value && isSubset(tags, call.tags) {
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 10*time.Millisecond, "%s %s %v not sent with %v", method, name, value, tags)
+}
+
+func isSubset(tags []string, of []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(of, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// runCheck configures and runs the check until the end of the test
+func runCheck(t *testing.T, rawInstanceConfig []byte) (*mocksender.MockSender, *recorder) {
+	deps := createDeps(t)
+	chk := newGNMICheck()
+
+	id := checkid.BuildID(CheckName, integration.FakeConfigHash, rawInstanceConfig, []byte(``))
+	sender := mocksender.NewMockSenderWithSenderManager(id, deps.Demultiplexer)
+	metrics := &recorder{}
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(metrics.record("Gauge")).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(metrics.record("MonotonicCount")).Return()
+	sender.On("Rate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(metrics.record("Rate")).Return()
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	sender.On("Commit").Return()
+	sender.On("SetCheckCustomTags", mock.Anything).Return()
+
+	err := chk.Configure(deps.Demultiplexer, integration.FakeConfigHash, rawInstanceConfig, []byte(``), "test")
+	require.NoError(t, err)
+	chk.reportInterval = 50 * time.Millisecond
+
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, chk.Run())
+		close(done)
+	}()
+	t.Cleanup(func() {
+		chk.Cancel()
+		<-done
+	})
+	return sender, metrics
+}
+
+func TestGNMICheck(t *testing.T) {
+	server := startDevice(t, testserver.Options{Username: "admin", Password: "secret"})
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+address: ` + server.Addr() + `
+username: admin
+password: secret
+namespace: test
+tags:
+  - site:paris
+subscriptions:
+  - path: /interfaces/interface/state
+    mode: sample
+    sample_interval: 1
+  - path: /system
+    mode: on_change
+`)
+	sender, metrics := runCheck(t, rawInstanceConfig)
+
+	deviceTags := []string{"device_namespace:test", "device_ip:127.0.0.1", "device_id:test:127.0.0.1", "snmp_device:127.0.0.1", "snmp_host:router1", "site:paris"}
+	interfaceTags := append(slices.Clone(deviceTags), "interface:eth0", "interface_alias:uplink")
+	metrics.waitFor(t, "Gauge", "snmp.interface.status", 1, append(slices.Clone(interfaceTags), "status:up", "admin_status:up", "oper_status:up", "interface_index:1")...)
+	metrics.waitFor(t, "MonotonicCount", "snmp.ifHCInOctets", 1000, interfaceTags...)
+	metrics.waitFor(t, "Rate", "snmp.ifHCInOctets.rate", 1000, append(slices.Clone(interfaceTags), "interface_index:1")...)
+	metrics.waitFor(t, "Gauge", "snmp.cpu.usage", 12, append(slices.Clone(deviceTags), "cpu:0")...)
+	metrics.waitFor(t, "Gauge", "snmp.memory.usage", 25, deviceTags...)
+	metrics.waitFor(t, "Gauge", "snmp.device.reachable", 1, deviceTags...)
+	sender.AssertCalled(t, "EventPlatformEvent", mock.Anything, "network-devices-metadata")
+
+	// state changes are streamed on change, counters at every sample
+	require.NoError(t, server.Update("/interfaces/interface[name=eth0]/state/oper-status", stringVal("DOWN")))
+	require.NoError(t, server.Update("/interfaces/interface[name=eth0]/state/counters/in-octets", uintVal(3000)))
+	metrics.waitFor(t, "Gauge", "snmp.interface.status", 1, "status:down", "oper_status:down")
+	metrics.waitFor(t, "MonotonicCount", "snmp.ifHCInOctets", 3000, interfaceTags...)
+
+	// the device is unreachable once the subscription is closed
+	server.Stop()
+	metrics.waitFor(t, "Gauge", "snmp.device.unreachable", 1, deviceTags...)
+}
+
+func TestGNMICheckInvalidCredentials(t *testing.T) {
+	server := startDevice(t, testserver.Options{Username: "admin", Password: "secret"})
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+address: ` + server.Addr() + `
+username: admin
+password: wrong
+`)
+	sender, metrics := runCheck(t, rawInstanceConfig)
+
+	metrics.waitFor(t, "Gauge", "snmp.device.unreachable", 1, "device_namespace:default", "device_ip:127.0.0.1")
+	sender.AssertNotCalled(t, "MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGNMICheckMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newCertificate(t, dir, "ca", nil, nil)
+	newCertificate(t, dir, "server", ca, caKey)
+	newCertificate(t, dir, "client", ca, caKey)
+
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	require.NoError(t, err)
+	caPool := x509.NewCertPool()
+	caPool.AddCert(ca)
+	server := startDevice(t, testserver.Options{TLSConfig: &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    caPool,
+	}})
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+address: ` + server.Addr() + `
+tls:
+  enabled: true
+  ca_file: ` + filepath.Join(dir, "ca.crt") + `
+  cert_file: ` + filepath.Join(dir, "client.crt") + `
+  key_file: ` + filepath.Join(dir, "client.key") + `
+  server_name: router1.example.com
+`)
+	_, metrics := runCheck(t, rawInstanceConfig)
+	metrics.waitFor(t, "Gauge", "snmp.interface.status", 1, "status:up", "interface:eth0")
+}
+
+func TestConfigure(t *testing.T) {
+	deps := createDeps(t)
+
+	chk := newGNMICheck()
+	err := chk.Configure(deps.Demultiplexer, integration.FakeConfigHash, []byte(`
+address: 10.0.0.1:6030
+target: router1
+namespace: my ns
+`), []byte(``), "test")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", chk.config.IPAddress)
+	assert.Equal(t, "my ns", chk.config.Namespace)
+	assert.True(t, *chk.config.SendNDMMetadata)
+	assert.Equal(t, defaultReportInterval, chk.reportInterval)
+
+	list := chk.request.GetSubscribe()
+	assert.Equal(t, gnmipb.Encoding_JSON_IETF, list.GetEncoding())
+	assert.Equal(t, "router1", list.GetPrefix().GetTarget())
+	require.Len(t, list.GetSubscription(), len(defaultSubscriptions))
+	assert.Equal(t, "/interfaces/interface/state/counters", client.PathToString(list.GetSubscription()[0].GetPath()))
+	assert.Equal(t, uint64(10*time.Second), list.GetSubscription()[0].GetSampleInterval())
+
+	for name, config := range map[string]string{
+		"missing address":  `username: admin`,
+		"invalid address":  `address: 10.0.0.1`,
+		"invalid mode":     "address: 10.0.0.1:6030\nsubscriptions: [{path: /system, mode: poll}]",
+		"invalid encoding": "address: 10.0.0.1:6030\nencoding: xml",
+		"missing key":      "address: 10.0.0.1:6030\ntls: {enabled: true, cert_file: client.crt}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := newGNMICheck().Configure(deps.Demultiplexer, integration.FakeConfigHash, []byte(config), []byte(``), "test")
+			assert.Error(t, err)
+		})
+	}
+}
+
+// newCertificate writes a certificate and its key signed by the given CA, or self-signed when it is nil
+func newCertificate(t *testing.T, dir string, name string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"router1.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		ca, caKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return cert, key
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package report
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// TimeNow useful for mocking
+var TimeNow = time.Now
+
+const integrationName = "gnmi"
+
+// SendMetadata sends the device and interface metadata
+func (s *GNMISender) SendMetadata(reachable bool) {
+	status := devicemetadata.DeviceStatusReachable
+	if !reachable {
+		status = devicemetadata.DeviceStatusUnreachable
+	}
+	devices := []devicemetadata.DeviceMetadata{{
+		ID:          s.deviceID,
+		IDTags:      []string{"device_namespace:" + s.namespace, "snmp_device:" + s.ipAddress},
+		Tags:        s.GetDeviceTags(),
+		IPAddress:   s.ipAddress,
+		Status:      status,
+		Name:        s.hostname,
+		OsHostname:  s.hostname,
+		OsVersion:   s.version,
+		Version:     s.version,
+		Integration: integrationName,
+	}}
+
+	interfaces := make([]devicemetadata.InterfaceMetadata, 0, len(s.interfaces))
+	for _, itf := range s.interfaces {
+		interfaces = append(interfaces, devicemetadata.InterfaceMetadata{
+			DeviceID:    s.deviceID,
+			IDTags:      s.interfaceTags(itf),
+			Index:       itf.index,
+			RawID:       itf.name,
+			Name:        itf.name,
+			Alias:       itf.description,
+			MacAddress:  itf.macAddress,
+			AdminStatus: itf.adminStatus,
+			OperStatus:  itf.operStatus,
+		})
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].Name < interfaces[j].Name
+	})
+
+	metadataPayloads := devicemetadata.BatchPayloads(s.namespace, "", TimeNow(), devicemetadata.PayloadMetadataBatchSize, devices, interfaces, nil, nil, nil, nil)
+	for _, payload := range metadataPayloads {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			log.Errorf("Error marshalling gNMI metadata : %s", err)
+			continue
+		}
+		s.sender.EventPlatformEvent(payloadBytes, eventplatform.EventTypeNetworkDevicesMetadata)
+	}
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendMetadata(t *testing.T) {
+	TimeNow = mockTimeNow
+	sender := newMockSender()
+	ms := NewGNMISender(sender, "my-ns", "10.0.0.1", []string{"site:paris"})
+
+	ms.ProcessNotification(&gnmipb.Notification{Update: []*gnmipb.Update{
+		newUpdate(t, "/system/state/hostname", stringVal("router1")),
+		newUpdate(t, "/system/state/software-version", stringVal("4.30.1F")),
+		newUpdate(t, "/interfaces/interface[name=eth1]/state/ifindex", uintVal(2)),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/ifindex", uintVal(1)),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/description", stringVal("uplink")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/admin-status", stringVal("UP")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/oper-status", stringVal("UP")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/ethernet/state/mac-address", stringVal("00:1c:73:aa:bb:cc")),
+	}})
+	ms.SendMetadata(true)
+
+	// language=json
+	event := []byte(`
+{
+  "namespace": "my-ns",
+  "devices": [
+    {
+      "id": "my-ns:10.0.0.1",
+      "id_tags": [
+        "device_namespace:my-ns",
+        "snmp_device:10.0.0.1"
+      ],
+      "tags": [
+        "device_namespace:my-ns",
+        "device_ip:10.0.0.1",
+        "device_id:my-ns:10.0.0.1",
+        "snmp_device:10.0.0.1",
+        "snmp_host:router1",
+        "site:paris"
+      ],
+      "ip_address": "10.0.0.1",
+      "status": 1,
+      "name": "router1",
+      "version": "4.30.1F",
+      "os_version": "4.30.1F",
+      "os_hostname": "router1",
+      "integration": "gnmi"
+    }
+  ],
+  "interfaces": [
+    {
+      "device_id": "my-ns:10.0.0.1",
+      "id_tags": [
+        "interface:eth0",
+        "interface_alias:uplink"
+      ],
+      "index": 1,
+      "raw_id": "eth0",
+      "name": "eth0",
+      "alias": "uplink",
+      "mac_address": "00:1c:73:aa:bb:cc",
+      "admin_status": 1,
+      "oper_status": 1
+    },
+    {
+      "device_id": "my-ns:10.0.0.1",
+      "id_tags": [
+        "interface:eth1"
+      ],
+      "index": 2,
+      "raw_id": "eth1",
+      "name": "eth1"
+    }
+  ],
+  "collect_timestamp": 946684800
+}
+`)
+	compactEvent := new(bytes.Buffer)
+	err := json.Compact(compactEvent, event)
+	assert.NoError(t, err)
+
+	sender.AssertEventPlatformEvent(t, compactEvent.Bytes(), "network-devices-metadata")
+}
@@ -0,0 +1,302 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package report maps OpenConfig telemetry to NDM metrics and metadata
+package report
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// counterMetrics maps OpenConfig interface counters to the SNMP metrics names
+var counterMetrics = map[string]string{
+	"in-octets":          "snmp.ifHCInOctets",
+	"out-octets":         "snmp.ifHCOutOctets",
+	"in-unicast-pkts":    "snmp.ifHCInUcastPkts",
+	"out-unicast-pkts":   "snmp.ifHCOutUcastPkts",
+	"in-multicast-pkts":  "snmp.ifHCInMulticastPkts",
+	"out-multicast-pkts": "snmp.ifHCOutMulticastPkts",
+	"in-broadcast-pkts":  "snmp.ifHCInBroadcastPkts",
+	"out-broadcast-pkts": "snmp.ifHCOutBroadcastPkts",
+	"in-errors":          "snmp.ifInErrors",
+	"out-errors":         "snmp.ifOutErrors",
+	"in-discards":        "snmp.ifInDiscards",
+	"out-discards":       "snmp.ifOutDiscards",
+}
+
+// bandwidthUsageMetrics maps the OpenConfig octets counters to the SNMP bandwidth usage metrics names
+var bandwidthUsageMetrics = map[string]string{
+	"in-octets":  "snmp.ifBandwidthInUsage.rate",
+	"out-octets": "snmp.ifBandwidthOutUsage.rate",
+}
+
+var adminStatuses = map[string]devicemetadata.IfAdminStatus{
+	"UP":      devicemetadata.AdminStatusUp,
+	"DOWN":    devicemetadata.AdminStatusDown,
+	"TESTING": devicemetadata.AdminStatusTesting,
+}
+
+var operStatuses = map[string]devicemetadata.IfOperStatus{
+	"UP":               devicemetadata.OperStatusUp,
+	"DOWN":             devicemetadata.OperStatusDown,
+	"TESTING":          devicemetadata.OperStatusTesting,
+	"UNKNOWN":          devicemetadata.OperStatusUnknown,
+	"DORMANT":          devicemetadata.OperStatusDormant,
+	"NOT_PRESENT":      devicemetadata.OperStatusNotPresent,
+	"LOWER_LAYER_DOWN": devicemetadata.OperStatusLowerLayerDown,
+}
+
+type interfaceState struct {
+	name        string
+	index       int32
+	description string
+	macAddress  string
+	adminStatus devicemetadata.IfAdminStatus
+	operStatus  devicemetadata.IfOperStatus
+	highSpeed   uint64 // Mb/s
+	portSpeed   uint64 // Mb/s
+}
+
+// speed returns the interface speed in b/s, preferring the configured high-speed over the Ethernet port speed
+func (itf *interfaceState) speed() uint64 {
+	if itf.highSpeed > 0 {
+		return itf.highSpeed * 1e6
+	}
+	return itf.portSpeed * 1e6
+}
+
+// GNMISender keeps the state of a device streamed over gNMI and reports it
+type GNMISender struct {
+	sender     sender.Sender
+	namespace  string
+	ipAddress  string
+	deviceID   string
+	tags       []string
+	hostname   string
+	version    string
+	bootTime   int64
+	memory     map[string]float64
+	interfaces map[string]*interfaceState
+}
+
+// NewGNMISender creates a new GNMISender for the device at the given IP address
+func NewGNMISender(sender sender.Sender, namespace string, ipAddress string, tags []string) *GNMISender {
+	return &GNMISender{
+		sender:     sender,
+		namespace:  namespace,
+		ipAddress:  ipAddress,
+		deviceID:   namespace + ":" + ipAddress,
+		tags:       tags,
+		memory:     make(map[string]float64),
+		interfaces: make(map[string]*interfaceState),
+	}
+}
+
+// ProcessNotification updates the device state from a notification and sends the counters and samples it contains
+func (s *GNMISender) ProcessNotification(notification *gnmipb.Notification) {
+	for _, deleted := range notification.GetDelete() {
+		path := &gnmipb.Path{Elem: append(append([]*gnmipb.PathElem{}, notification.GetPrefix().GetElem()...), deleted.GetElem()...)}
+		l := leaf{elems: path.GetElem()}
+		if l.is("interfaces", "interface") {
+			delete(s.interfaces, l.key(1, "name"))
+		}
+	}
+	// state leaves are processed first so that the samples of a notification are tagged with them
+	var leaves []leaf
+	for _, l := range notificationLeaves(notification) {
+		if l.name(0) == "interfaces" && l.key(1, "name") == "" {
+			continue
+		}
+		leaves = append(leaves, l)
+	}
+	for _, l := range leaves {
+		s.updateState(l)
+	}
+	for _, l := range leaves {
+		s.sendSample(l)
+	}
+}
+
+func (s *GNMISender) updateState(l leaf) {
+	switch {
+	case l.is("interfaces", "interface", "state", l.name(3)):
+		itf := s.getInterface(l.key(1, "name"))
+		switch l.name(3) {
+		case "ifindex":
+			if value, ok := l.asFloat(); ok {
+				itf.index = int32(value)
+			}
+		case "description":
+			itf.description = l.asString()
+		case "admin-status":
+			itf.adminStatus = adminStatuses[strings.ToUpper(trimModule(l.asString()))]
+		case "oper-status":
+			itf.operStatus = operStatuses[strings.ToUpper(trimModule(l.asString()))]
+		case "high-speed":
+			if value, ok := l.asFloat(); ok {
+				itf.highSpeed = uint64(value)
+			}
+		}
+	case l.is("interfaces", "interface", "ethernet", "state", "mac-address"):
+		s.getInterface(l.key(1, "name")).macAddress = l.asString()
+	case l.is("interfaces", "interface", "ethernet", "state", "port-speed"):
+		s.getInterface(l.key(1, "name")).portSpeed = parsePortSpeed(l.asString())
+	case l.is("system", "state", "hostname"):
+		s.hostname = l.asString()
+	case l.is("system", "state", "software-version"):
+		s.version = l.asString()
+	case l.is("system", "state", "boot-time"):
+		if value, ok := l.asFloat(); ok {
+			s.bootTime = int64(value)
+		}
+	case l.is("system", "memory", "state", l.name(3)):
+		if value, ok := l.asFloat(); ok {
+			s.memory[l.name(3)] = value
+		}
+	}
+}
+
+func (s *GNMISender) sendSample(l leaf) {
+	switch {
+	case l.is("interfaces", "interface", "state", "counters", l.name(4)):
+		metric, ok := counterMetrics[l.name(4)]
+		if !ok {
+			return
+		}
+		value, ok := l.asFloat()
+		if !ok {
+			return
+		}
+		itf := s.getInterface(l.key(1, "name"))
+		tags := append(append(s.GetDeviceTags(), s.interfaceTags(itf)...), interfaceIndexTags(itf)...)
+		s.sender.MonotonicCount(metric, value, "", tags)
+		s.sender.Rate(metric+".rate", value, "", tags)
+		if usageMetric, ok := bandwidthUsageMetrics[l.name(4)]; ok && itf.speed() > 0 {
+			// the rate of the octets in percent of the speed is the bandwidth usage
+			s.sender.Rate(usageMetric, value*8/float64(itf.speed())*100, "", tags)
+		}
+	case l.is("system", "cpus", "cpu", "state", "total", "instant"):
+		if value, ok := l.asFloat(); ok {
+			s.sender.Gauge("snmp.cpu.usage", value, "", append(s.GetDeviceTags(), "cpu:"+l.key(2, "index")))
+		}
+	}
+}
+
+func (s *GNMISender) getInterface(name string) *interfaceState {
+	itf, ok := s.interfaces[name]
+	if !ok {
+		itf = &interfaceState{name: name}
+		s.interfaces[name] = itf
+	}
+	return itf
+}
+
+// GetDeviceTags returns the tags of the device metrics
+func (s *GNMISender) GetDeviceTags() []string {
+	tags := []string{
+		"device_namespace:" + s.namespace,
+		"device_ip:" + s.ipAddress,
+		"device_id:" + s.deviceID,
+		"snmp_device:" + s.ipAddress,
+	}
+	if s.hostname != "" {
+		tags = append(tags, "snmp_host:"+s.hostname)
+	}
+	return append(tags, s.tags...)
+}
+
+func (s *GNMISender) interfaceTags(itf *interfaceState) []string {
+	tags := []string{"interface:" + itf.name}
+	if itf.description != "" {
+		tags = append(tags, "interface_alias:"+itf.description)
+	}
+	return tags
+}
+
+func interfaceIndexTags(itf *interfaceState) []string {
+	if itf.index == 0 {
+		return nil
+	}
+	return []string{"interface_index:" + strconv.Itoa(int(itf.index))}
+}
+
+// parsePortSpeed returns the speed in Mb/s of an OpenConfig ETHERNET_SPEED identity, such as SPEED_10GB or SPEED_2500MB
+func parsePortSpeed(identity string) uint64 {
+	speed, found := strings.CutPrefix(trimModule(identity), "SPEED_")
+	if !found {
+		return 0
+	}
+	var multiplier uint64
+	switch {
+	case strings.HasSuffix(speed, "GB"):
+		multiplier = 1000
+	case strings.HasSuffix(speed, "MB"):
+		multiplier = 1
+	default:
+		return 0
+	}
+	value, err := strconv.ParseUint(speed[:len(speed)-2], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value * multiplier
+}
+
+// SendDeviceMetrics sends the reachability, uptime, memory and interface status metrics of the device
+func (s *GNMISender) SendDeviceMetrics(reachable bool) {
+	tags := s.GetDeviceTags()
+	s.sender.Gauge("snmp.devices_monitored", 1, "", tags)
+	s.sender.Gauge("snmp.device.reachable", boolToFloat(reachable), "", tags)
+	s.sender.Gauge("snmp.device.unreachable", boolToFloat(!reachable), "", tags)
+	if !reachable {
+		return
+	}
+
+	if s.bootTime > 0 {
+		// boot-time is in nanoseconds since epoch, sysUpTime is in hundredths of a second
+		uptime := TimeNow().Sub(time.Unix(0, s.bootTime))
+		s.sender.Gauge("snmp.sysUpTimeInstance", float64(uptime.Milliseconds()/10), "", tags)
+	}
+
+	if physical, ok := s.memory["physical"]; ok && physical > 0 {
+		used, ok := s.memory["used"]
+		if !ok {
+			if free, hasFree := s.memory["free"]; hasFree {
+				used, ok = physical-free, true
+			}
+		}
+		s.sender.Gauge("snmp.memory.total", physical, "", tags)
+		if ok {
+			s.sender.Gauge("snmp.memory.used", used, "", tags)
+			s.sender.Gauge("snmp.memory.free", physical-used, "", tags)
+			s.sender.Gauge("snmp.memory.usage", used/physical*100, "", tags)
+		}
+	}
+
+	for _, itf := range s.interfaces {
+		interfaceTags := []string{
+			"status:" + string(devicemetadata.ComputeInterfaceStatus(itf.adminStatus, itf.operStatus)),
+			"admin_status:" + itf.adminStatus.AsString(),
+			"oper_status:" + itf.operStatus.AsString(),
+		}
+		interfaceTags = append(interfaceTags, interfaceIndexTags(itf)...)
+		interfaceTags = append(interfaceTags, s.interfaceTags(itf)...)
+		s.sender.Gauge("snmp.interface.status", 1, "", append(interfaceTags, tags...))
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+)
+
+// mockTimeNow mocks time.Now
+var mockTimeNow = func() time.Time {
+	layout := "2006-01-02 15:04:05"
+	str := "2000-01-01 00:00:00"
+	t, _ := time.Parse(layout, str)
+	return t
+}
+
+func newUpdate(t *testing.T, path string, value *gnmipb.TypedValue) *gnmipb.Update {
+	parsed, err := client.ParsePath(path)
+	require.NoError(t, err)
+	return &gnmipb.Update{Path: parsed, Val: value}
+}
+
+func uintVal(v uint64) *gnmipb.TypedValue {
+	return &gnmipb.TypedValue{Value: &gnmipb.TypedValue_UintVal{UintVal: v}}
+}
+
+func stringVal(v string) *gnmipb.TypedValue {
+	return &gnmipb.TypedValue{Value: &gnmipb.TypedValue_StringVal{StringVal: v}}
+}
+
+func newMockSender() *mocksender.MockSender {
+	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("Rate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	return sender
+}
+
+var deviceTags = []string{
+	"device_namespace:my-ns",
+	"device_ip:10.0.0.1",
+	"device_id:my-ns:10.0.0.1",
+	"snmp_device:10.0.0.1",
+	"snmp_host:router1",
+	"site:paris",
+}
+
+func TestProcessNotification(t *testing.T) {
+	sender := newMockSender()
+	ms := NewGNMISender(sender, "my-ns", "10.0.0.1", []string{"site:paris"})
+
+	ms.ProcessNotification(&gnmipb.Notification{Update: []*gnmipb.Update{
+		newUpdate(t, "/system/state/hostname", stringVal("router1")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/description", stringVal("uplink")),
+	}})
+	ms.ProcessNotification(&gnmipb.Notification{
+		Prefix: &gnmipb.Path{Elem: []*gnmipb.PathElem{{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": "eth0"}}}},
+		Update: []*gnmipb.Update{
+			newUpdate(t, "/state/counters/in-octets", uintVal(1000)),
+			newUpdate(t, "/state/counters/out-errors", uintVal(3)),
+			newUpdate(t, "/state/counters/carrier-transitions", uintVal(2)),
+		},
+	})
+	ms.ProcessNotification(&gnmipb.Notification{Update: []*gnmipb.Update{
+		newUpdate(t, "/system/cpus/cpu[index=0]/state/total/instant", &gnmipb.TypedValue{Value: &gnmipb.TypedValue_UintVal{UintVal: 42}}),
+	}})
+
+	interfaceTags := append(append([]string{}, deviceTags...), "interface:eth0", "interface_alias:uplink")
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifHCInOctets", 1000, "", interfaceTags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifOutErrors", 3, "", interfaceTags)
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 2)
+	sender.AssertMetric(t, "Rate", "snmp.ifHCInOctets.rate", 1000, "", interfaceTags)
+	sender.AssertMetric(t, "Rate", "snmp.ifOutErrors.rate", 3, "", interfaceTags)
+	// the bandwidth usage requires the interface speed
+	sender.AssertNumberOfCalls(t, "Rate", 2)
+	sender.AssertMetric(t, "Gauge", "snmp.cpu.usage", 42, "", append(append([]string{}, deviceTags...), "cpu:0"))
+}
+
+func TestProcessNotificationJSON(t *testing.T) {
+	sender := newMockSender()
+	ms := NewGNMISender(sender, "my-ns", "10.0.0.1", []string{"site:paris"})
+
+	// language=json
+	interfaces := []byte(`{
+  "openconfig-interfaces:interface": [
+    {
+      "name": "Ethernet1",
+      "state": {
+        "description": "uplink",
+        "ifindex": 3,
+        "admin-status": "UP",
+        "oper-status": "LOWER_LAYER_DOWN",
+        "counters": {"in-octets": "18446744073709551000", "in-discards": "5"}
+      }
+    }
+  ]
+}`)
+	ms.ProcessNotification(&gnmipb.Notification{Update: []*gnmipb.Update{
+		newUpdate(t, "/system/state", &gnmipb.TypedValue{Value: &gnmipb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(`{"openconfig-system:hostname": "router1"}`)}}),
+		newUpdate(t, "/interfaces", &gnmipb.TypedValue{Value: &gnmipb.TypedValue_JsonIetfVal{JsonIetfVal: interfaces}}),
+	}})
+
+	interfaceTags := append(append([]string{}, deviceTags...), "interface:Ethernet1", "interface_alias:uplink")
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifHCInOctets", 18446744073709551000, "", interfaceTags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifInDiscards", 5, "", interfaceTags)
+
+	ms.SendDeviceMetrics(true)
+	sender.AssertMetric(t, "Gauge", "snmp.interface.status", 1, "", append(interfaceTags,
+		"status:warning", "admin_status:up", "oper_status:lower_layer_down", "interface_index:3"))
+}
+
+func TestProcessNotificationBandwidthUsage(t *testing.T) {
+	sender := newMockSender()
+	ms := NewGNMISender(sender, "my-ns", "10.0.0.1", []string{"site:paris"})
+
+	ms.ProcessNotification(&gnmipb.Notification{Update: []*gnmipb.Update{
+		newUpdate(t, "/system/state/hostname", stringVal("router1")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/ifindex", uintVal(1)),
+		newUpdate(t, "/interfaces/interface[name=eth0]/ethernet/state/port-speed", stringVal("openconfig-if-ethernet:SPEED_10GB")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/counters/in-octets", uintVal(1250000000)),
+		newUpdate(t, "/interfaces/interface[name=eth1]/state/high-speed", uintVal(100)),
+		newUpdate(t, "/interfaces/interface[name=eth1]/ethernet/state/port-speed", stringVal("SPEED_1GB")),
+		newUpdate(t, "/interfaces/interface[name=eth1]/state/counters/out-octets", uintVal(12500000)),
+	}})
+
+	eth0Tags := append(append([]string{}, deviceTags...), "interface:eth0", "interface_index:1")
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifHCInOctets", 1250000000, "", eth0Tags)
+	sender.AssertMetric(t, "Rate", "snmp.ifHCInOctets.rate", 1250000000, "", eth0Tags)
+	sender.AssertMetric(t, "Rate", "snmp.ifBandwidthInUsage.rate", 100, "", eth0Tags)
+
+	// high-speed takes precedence over the port speed
+	eth1Tags := append(append([]string{}, deviceTags...), "interface:eth1")
+	sender.AssertMetric(t, "Rate", "snmp.ifBandwidthOutUsage.rate", 100, "", eth1Tags)
+}
+
+func TestParsePortSpeed(t *testing.T) {
+	for identity, expected := range map[string]uint64{
+		"openconfig-if-ethernet:SPEED_10GB": 10000,
+		"SPEED_2500MB":                      2500,
+		"SPEED_100MB":                       100,
+		"SPEED_UNKNOWN":                     0,
+		"":                                  0,
+	} {
+		assert.Equal(t, expected, parsePortSpeed(identity), identity)
+	}
+}
+
+func TestSendDeviceMetrics(t *testing.T) {
+	TimeNow = mockTimeNow
+	sender := newMockSender()
+	ms := NewGNMISender(sender, "my-ns", "10.0.0.1", []string{"site:paris"})
+
+	bootTime := mockTimeNow().Add(-time.Hour).UnixNano()
+	ms.ProcessNotification(&gnmipb.Notification{Update: []*gnmipb.Update{
+		newUpdate(t, "/system/state/hostname", stringVal("router1")),
+		newUpdate(t, "/system/state/boot-time", uintVal(uint64(bootTime))),
+		newUpdate(t, "/system/memory/state/physical", uintVal(8000)),
+		newUpdate(t, "/system/memory/state/free", uintVal(2000)),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/admin-status", stringVal("UP")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/oper-status", stringVal("DOWN")),
+		newUpdate(t, "/interfaces/interface[name=eth0]/state/ifindex", uintVal(1)),
+	}})
+	ms.SendDeviceMetrics(true)
+
+	sender.AssertMetric(t, "Gauge", "snmp.devices_monitored", 1, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.device.reachable", 1, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.device.unreachable", 0, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", 360000, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.memory.total", 8000, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.memory.used", 6000, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.memory.usage", 75, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.interface.status", 1, "", append(append([]string{}, deviceTags...),
+		"status:down", "admin_status:up", "oper_status:down", "interface_index:1", "interface:eth0"))
+
+	// interfaces removed from the device are no longer reported
+	ms.ProcessNotification(&gnmipb.Notification{Delete: []*gnmipb.Path{newUpdate(t, "/interfaces/interface[name=eth0]", nil).GetPath()}})
+	sender.ResetCalls()
+	ms.SendDeviceMetrics(false)
+	sender.AssertMetric(t, "Gauge", "snmp.device.unreachable", 1, "", deviceTags)
+	sender.AssertNotCalled(t, "Gauge", "snmp.interface.status", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNotCalled(t, "Gauge", "snmp.sysUpTimeInstance", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// leaf is a scalar value of the data tree, values are string, float64 or bool
+type leaf struct {
+	elems []*gnmipb.PathElem
+	value interface{}
+}
+
+// name returns the name of the element at the given position, without YANG module prefix
+func (l leaf) name(i int) string {
+	if i >= len(l.elems) {
+		return ""
+	}
+	return trimModule(l.elems[i].GetName())
+}
+
+// key returns the key of the element at the given position
+func (l leaf) key(i int, key string) string {
+	if i >= len(l.elems) {
+		return ""
+	}
+	return l.elems[i].GetKey()[key]
+}
+
+// is returns true when the element names of the leaf are exactly the given ones
+func (l leaf) is(names ...string) bool {
+	if len(l.elems) != len(names) {
+		return false
+	}
+	for i, name := range names {
+		if l.name(i) != name {
+			return false
+		}
+	}
+	return true
+}
+
+// asFloat returns the leaf value as a float, uint64 encoded in JSON as strings are parsed
+func (l leaf) asFloat() (float64, bool) {
+	switch value := l.value.(type) {
+	case float64:
+		return value, true
+	case string:
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// asString returns the leaf value as a string
+func (l leaf) asString() string {
+	switch value := l.value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
+
+// trimModule removes the YANG module prefix from a JSON_IETF name or identity
+func trimModule(name string) string {
+	if i := strings.Index(name, ":"); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// notificationLeaves returns the leaves updated by a notification. JSON values are
+// flattened into leaves; list entries are keyed by their `name` or `index` member,
+// which covers the OpenConfig lists used by this check.
+func notificationLeaves(notification *gnmipb.Notification) []leaf {
+	var leaves []leaf
+	for _, update := range notification.GetUpdate() {
+		path := client.JoinPaths(notification.GetPrefix(), update.GetPath())
+		value, err := decodeTypedValue(update.GetVal())
+		if err != nil {
+			log.Debugf("Skipping gNMI update of %s: %s", client.PathToString(path), err)
+			continue
+		}
+		leaves = flatten(leaves, path.GetElem(), value)
+	}
+	return leaves
+}
+
+func decodeTypedValue(value *gnmipb.TypedValue) (interface{}, error) {
+	switch v := value.GetValue().(type) {
+	case *gnmipb.TypedValue_StringVal:
+		return v.StringVal, nil
+	case *gnmipb.TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	case *gnmipb.TypedValue_IntVal:
+		return float64(v.IntVal), nil
+	case *gnmipb.TypedValue_UintVal:
+		return float64(v.UintVal), nil
+	case *gnmipb.TypedValue_BoolVal:
+		return v.BoolVal, nil
+	case *gnmipb.TypedValue_FloatVal:
+		return float64(v.FloatVal), nil
+	case *gnmipb.TypedValue_DoubleVal:
+		return v.DoubleVal, nil
+	case *gnmipb.TypedValue_DecimalVal:
+		decimal := v.DecimalVal
+		value := float64(decimal.GetDigits())
+		for i := uint32(0); i < decimal.GetPrecision(); i++ {
+			value /= 10
+		}
+		return value, nil
+	case *gnmipb.TypedValue_JsonVal:
+		return decodeJSON(v.JsonVal)
+	case *gnmipb.TypedValue_JsonIetfVal:
+		return decodeJSON(v.JsonIetfVal)
+	}
+	return nil, fmt.Errorf("unsupported value type %T", value.GetValue())
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func flatten(leaves []leaf, elems []*gnmipb.PathElem, value interface{}) []leaf {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, member := range v {
+			if list, ok := member.([]interface{}); ok {
+				for _, entry := range list {
+					leaves = flatten(leaves, appendElem(elems, &gnmipb.PathElem{Name: name, Key: listKey(entry)}), entry)
+				}
+				continue
+			}
+			leaves = flatten(leaves, appendElem(elems, &gnmipb.PathElem{Name: name}), member)
+		}
+	case []interface{}:
+		// leaf-lists are not used by this check
+	case json.Number:
+		f, err := v.Float64()
+		if err == nil {
+			leaves = append(leaves, leaf{elems: elems, value: f})
+		}
+	case nil:
+	default:
+		leaves = append(leaves, leaf{elems: elems, value: v})
+	}
+	return leaves
+}
+
+func listKey(entry interface{}) map[string]string {
+	object, ok := entry.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, key := range []string{"name", "index"} {
+		if value, ok := object[key]; ok {
+			return map[string]string{key: fmt.Sprint(value)}
+		}
+	}
+	return nil
+}
+
+func appendElem(elems []*gnmipb.PathElem, elem *gnmipb.PathElem) []*gnmipb.PathElem {
+	result := make([]*gnmipb.PathElem, 0, len(elems)+1)
+	return append(append(result, elems...), elem)
+}
@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package testserver implements a gNMI server standing in for network devices in tests
+package testserver
+
+import (
+	"crypto/tls"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+)
+
+// defaultSampleInterval is used for SAMPLE subscriptions without interval
+const defaultSampleInterval = time.Second
+
+// Options of the server
+type Options struct {
+	// Username and Password are required in the RPC metadata when set
+	Username string
+	Password string
+	// TLSConfig serves over TLS when set, require client certificates in it for mutual TLS
+	TLSConfig *tls.Config
+}
+
+// Server is a gNMI server streaming the leaves it is given. Subscriptions receive the
+// matching leaves, a sync response, then every update of the leaves they subscribed to
+// on change, or all the matching leaves at every sample interval.
+type Server struct {
+	gnmipb.UnimplementedGNMIServer
+
+	options     Options
+	listener    net.Listener
+	grpcServer  *grpc.Server
+	mu          sync.Mutex
+	leaves      map[string]*gnmipb.Update
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	target        string
+	onChangePaths []*gnmipb.Path
+	notifications chan *gnmipb.Notification
+}
+
+// Start starts a server listening on the given address, e.g. `127.0.0.1:0`
+func Start(addr string, options Options) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	var serverOptions []grpc.ServerOption
+	if options.TLSConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(options.TLSConfig)))
+	}
+	server := &Server{
+		options:     options,
+		listener:    listener,
+		grpcServer:  grpc.NewServer(serverOptions...),
+		leaves:      make(map[string]*gnmipb.Update),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	gnmipb.RegisterGNMIServer(server.grpcServer, server)
+	go func() {
+		_ = server.grpcServer.Serve(listener)
+	}()
+	return server, nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Stop closes the subscriptions and stops the server
+func (s *Server) Stop() {
+	s.grpcServer.Stop()
+}
+
+// Update sets the value of a leaf and streams it to the on-change subscriptions of its path
+func (s *Server) Update(path string, value *gnmipb.TypedValue) error {
+	parsedPath, err := client.ParsePath(path)
+	if err != nil {
+		return err
+	}
+	update := &gnmipb.Update{Path: parsedPath, Val: value}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leaves[client.PathToString(parsedPath)] = update
+	for sub := range s.subscribers {
+		for _, pattern := range sub.onChangePaths {
+			if client.MatchPath(pattern, parsedPath) {
+				select {
+				case sub.notifications <- newNotification(sub.target, []*gnmipb.Update{update}):
+				default:
+					// the subscriber is too slow, drop the update as a device would coalesce it
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe implements the gNMI Subscribe RPC, only STREAM subscriptions are supported
+func (s *Server) Subscribe(stream gnmipb.GNMI_SubscribeServer) error {
+	if err := s.authenticate(stream); err != nil {
+		return err
+	}
+	request, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	list := request.GetSubscribe()
+	if list == nil {
+		return status.Error(codes.InvalidArgument, "the first request must be a subscription list")
+	}
+	if list.GetMode() != gnmipb.SubscriptionList_STREAM {
+		return status.Errorf(codes.Unimplemented, "unsupported subscription mode %s", list.GetMode())
+	}
+
+	sub := &subscriber{
+		target:        list.GetPrefix().GetTarget(),
+		notifications: make(chan *gnmipb.Notification, 1000),
+	}
+	var allPaths []*gnmipb.Path
+	for _, subscription := range list.GetSubscription() {
+		path := client.JoinPaths(list.GetPrefix(), subscription.GetPath())
+		allPaths = append(allPaths, path)
+		if subscription.GetMode() == gnmipb.SubscriptionMode_SAMPLE {
+			interval := time.Duration(subscription.GetSampleInterval())
+			if interval == 0 {
+				interval = defaultSampleInterval
+			}
+			go s.sample(stream, sub, path, interval)
+			continue
+		}
+		sub.onChangePaths = append(sub.onChangePaths, path)
+	}
+
+	s.mu.Lock()
+	initial := newNotification(sub.target, s.matchingLeaves(allPaths...))
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}()
+
+	if err := stream.Send(&gnmipb.SubscribeResponse{Response: &gnmipb.SubscribeResponse_Update{Update: initial}}); err != nil {
+		return err
+	}
+	if err := stream.Send(&gnmipb.SubscribeResponse{Response: &gnmipb.SubscribeResponse_SyncResponse{SyncResponse: true}}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case notification := <-sub.notifications:
+			if err := stream.Send(&gnmipb.SubscribeResponse{Response: &gnmipb.SubscribeResponse_Update{Update: notification}}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) sample(stream gnmipb.GNMI_SubscribeServer, sub *subscriber, path *gnmipb.Path, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			updates := s.matchingLeaves(path)
+			s.mu.Unlock()
+			if len(updates) == 0 {
+				continue
+			}
+			select {
+			case sub.notifications <- newNotification(sub.target, updates):
+			default:
+			}
+		}
+	}
+}
+
+func (s *Server) authenticate(stream gnmipb.GNMI_SubscribeServer) error {
+	if s.options.Username == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if len(md.Get("username")) != 1 || md.Get("username")[0] != s.options.Username ||
+		len(md.Get("password")) != 1 || md.Get("password")[0] != s.options.Password {
+		return status.Error(codes.Unauthenticated, "invalid username or password")
+	}
+	return nil
+}
+
+// matchingLeaves returns the leaves under any of the paths sorted by path, mu must be held
+func (s *Server) matchingLeaves(paths ...*gnmipb.Path) []*gnmipb.Update {
+	var keys []string
+	for key, update := range s.leaves {
+		for _, path := range paths {
+			if client.MatchPath(path, update.GetPath()) {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	updates := make([]*gnmipb.Update, 0, len(keys))
+	for _, key := range keys {
+		updates = append(updates, s.leaves[key])
+	}
+	return updates
+}
+
+func newNotification(target string, updates []*gnmipb.Update) *gnmipb.Notification {
+	notification := &gnmipb.Notification{
+		Timestamp: time.Now().UnixNano(),
+		Update:    updates,
+	}
+	if target != "" {
+		notification.Prefix = &gnmipb.Path{Target: target}
+	}
+	return notification
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute"
 )
 
 const (
@@ -42,6 +43,10 @@ type InstanceConfig struct {
 
 	Protocol string `yaml:"protocol"`
 
+	IPVersion uint8 `yaml:"ip_version"`
+
+	NumPaths uint16 `yaml:"num_paths"`
+
 	SourceService      string `yaml:"source_service"`
 	DestinationService string `yaml:"destination_service"`
 
@@ -63,6 +68,8 @@ type CheckConfig struct {
 	DestinationService    string
 	MaxTTL                uint8
 	Protocol              payload.Protocol
+	IPVersion             uint8
+	NumPaths              uint16
 	Timeout               time.Duration
 	MinCollectionInterval time.Duration
 	Tags                  []string
@@ -92,6 +99,18 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 	c.DestinationService = instance.DestinationService
 	c.Protocol = payload.Protocol(strings.ToUpper(instance.Protocol))
 
+	c.IPVersion = instance.IPVersion
+	switch c.IPVersion {
+	case traceroute.IPVersionAuto, traceroute.IPVersion4, traceroute.IPVersion6:
+	default:
+		return nil, fmt.Errorf("ip_version must be %d or %d", traceroute.IPVersion4, traceroute.IPVersion6)
+	}
+
+	c.NumPaths = instance.NumPaths
+	if c.NumPaths > traceroute.MaxNumPaths {
+		return nil, fmt.Errorf("num_paths must be at most %d", traceroute.MaxNumPaths)
+	}
+
 	c.MinCollectionInterval = firstNonZero(
 		time.Duration(instance.MinCollectionInterval)*time.Second,
 		time.Duration(initConfig.MinCollectionInterval)*time.Second,
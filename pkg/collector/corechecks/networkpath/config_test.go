@@ -170,6 +170,56 @@ protocol: TCP
 				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
 			},
 		},
+		{
+			name: "icmp over ipv6",
+			rawInstance: []byte(`
+hostname: example.com
+protocol: icmp
+ip_version: 6
+`),
+			rawInitConfig: []byte(``),
+			expectedConfig: &CheckConfig{
+				DestHostname:          "example.com",
+				MinCollectionInterval: time.Duration(60) * time.Second,
+				Namespace:             "my-namespace",
+				Protocol:              payload.ProtocolICMP,
+				IPVersion:             6,
+				Timeout:               setup.DefaultNetworkPathTimeout * time.Millisecond,
+				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
+			},
+		},
+		{
+			name: "invalid ip_version",
+			rawInstance: []byte(`
+hostname: example.com
+ip_version: 5
+`),
+			expectedError: "ip_version must be 4 or 6",
+		},
+		{
+			name: "multipath",
+			rawInstance: []byte(`
+hostname: example.com
+num_paths: 8
+`),
+			rawInitConfig: []byte(``),
+			expectedConfig: &CheckConfig{
+				DestHostname:          "example.com",
+				MinCollectionInterval: time.Duration(60) * time.Second,
+				Namespace:             "my-namespace",
+				NumPaths:              8,
+				Timeout:               setup.DefaultNetworkPathTimeout * time.Millisecond,
+				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
+			},
+		},
+		{
+			name: "too many paths",
+			rawInstance: []byte(`
+hostname: example.com
+num_paths: 17
+`),
+			expectedError: "num_paths must be at most 16",
+		},
 		{
 			name: "timeout from instance config",
 			rawInstance: []byte(`
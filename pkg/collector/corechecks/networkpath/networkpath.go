@@ -56,6 +56,8 @@ func (c *Check) Run() error {
 		MaxTTL:       c.config.MaxTTL,
 		Timeout:      c.config.Timeout,
 		Protocol:     c.config.Protocol,
+		IPVersion:    c.config.IPVersion,
+		NumPaths:     c.config.NumPaths,
 	}
 
 	tr, err := traceroute.New(cfg, c.telemetryComp)
@@ -79,6 +81,11 @@ func (c *Check) Run() error {
 	for i := range path.Hops {
 		path.Hops[i].Hostname = traceroute.GetHostname(path.Hops[i].IPAddress)
 	}
+	if path.Multipath != nil {
+		for i := range path.Multipath.Nodes {
+			path.Multipath.Nodes[i].Hostname = traceroute.GetHostname(path.Multipath.Nodes[i].IPAddress)
+		}
+	}
 
 	// send to EP
 	err = c.SendNetPathMDToEP(senderInstance, path)
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmp
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/common"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/profile"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpsim"
+)
+
+func TestSimulatedDevice_f5(t *testing.T) {
+	pkgconfigsetup.Datadog().SetWithoutSource("run_path", t.TempDir())
+	timeNow = common.MockTimeNow
+	deps := createDeps(t)
+	senderManager := deps.Demultiplexer
+	profile.SetConfdPathAndCleanProfiles()
+
+	device, err := snmpsim.LoadDevice(filepath.Join("internal", "test", "snmprec", "f5-big-ip.snmprec"))
+	require.NoError(t, err)
+	sim, err := snmpsim.Start("127.0.0.1:0", snmpsim.Config{Devices: map[string]*snmpsim.Device{"public": device}})
+	require.NoError(t, err)
+	defer sim.Stop()
+
+	chk := Check{sessionFactory: session.NewGosnmpSession}
+	// the profile is detected from the sysObjectID served by the simulator
+	// language=yaml
+	rawInstanceConfig := []byte(fmt.Sprintf(`
+ip_address: 127.0.0.1
+port: %d
+community_string: public
+collect_topology: false
+`, sim.Addr().Port))
+	// language=yaml
+	rawInitConfig := []byte(`
+profiles:
+  f5-big-ip:
+    definition_file: f5-big-ip.yaml
+`)
+	err = chk.Configure(senderManager, integration.FakeConfigHash, rawInstanceConfig, rawInitConfig, "test")
+	require.NoError(t, err)
+
+	sender := mocksender.NewMockSenderWithSenderManager(chk.ID(), senderManager)
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	sender.On("Commit").Return()
+
+	err = chk.Run()
+	assert.NoError(t, err)
+
+	deviceTags := []string{"snmp_device:127.0.0.1", "snmp_profile:f5-big-ip", "device_vendor:f5", "snmp_host:foo_sys_name"}
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", float64(20), "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "snmp.sysStatMemoryTotal", float64(60), "", deviceTags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifInErrors", float64(70.5), "", append(deviceTags, "interface:nameRow1", "interface_alias:descRow1"))
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifInDiscards", float64(132), "", append(deviceTags, "interface:nameRow2", "interface_alias:descRow2"))
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 	"github.com/DataDog/datadog-agent/pkg/version"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
@@ -1369,7 +1370,8 @@ profiles:
                 },
                 "interface": {
                     "dd_id": "profile-metadata:1.2.3.4:1",
-					"id": ""
+                    "id": "nameRow1",
+                    "id_type": "interface_name"
                 }
             },
             "remote": {
@@ -1392,7 +1394,8 @@ profiles:
                 },
                 "interface": {
                     "dd_id": "profile-metadata:1.2.3.4:2",
-                    "id": ""
+                    "id": "nameRow2",
+                    "id_type": "interface_name"
                 }
             },
             "remote": {
@@ -1422,6 +1425,16 @@ profiles:
 	assert.NoError(t, err)
 
 	sender.AssertEventPlatformEvent(t, compactEvent.Bytes(), "network-devices-metadata")
+
+	// the links are also kept for the topology graph of the agent API
+	topology.TimeNow = common.MockTimeNow
+	defer func() { topology.TimeNow = time.Now }()
+	graph := topology.Default().Graph()
+	assert.Contains(t, graph.Links, topology.Link{
+		Source:      topology.Endpoint{Device: "neighbor:K10-ITV.tine.no", Interface: "GE0/1"},
+		Target:      topology.Endpoint{Device: "profile-metadata:1.2.3.4", Interface: "nameRow1"},
+		SourceTypes: []string{"cdp"},
+	})
 }
 
 // we have different data for LLDP and CDP to test that we're only using LLDP to build the links
@@ -19,6 +19,7 @@ import (
 
 	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/utils"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
@@ -62,6 +63,15 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 	interfaces := buildNetworkInterfacesMetadata(config.DeviceID, metadataStore)
 	ipAddresses := buildNetworkIPAddressesMetadata(config.DeviceID, metadataStore)
 	topologyLinks := buildNetworkTopologyMetadata(config.DeviceID, metadataStore, interfaces)
+	if config.CollectTopology {
+		// keep the links of the device for the topology graph served by the agent API
+		topology.Default().SetDevice(topology.DeviceTopology{
+			Device:      devices[0],
+			Interfaces:  interfaces,
+			Links:       topologyLinks,
+			CollectTime: collectTime,
+		})
+	}
 
 	metadataPayloads := devicemetadata.BatchPayloads(config.Namespace, config.ResolvedSubnetName, collectTime, devicemetadata.PayloadMetadataBatchSize, devices, interfaces, ipAddresses, topologyLinks, nil, diagnoses)
 
@@ -76,7 +86,7 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 
 	// Telemetry
 	for _, interfaceStatus := range interfaces {
-		status := string(computeInterfaceStatus(interfaceStatus.AdminStatus, interfaceStatus.OperStatus))
+		status := string(devicemetadata.ComputeInterfaceStatus(interfaceStatus.AdminStatus, interfaceStatus.OperStatus))
 		interfaceIndex := strconv.Itoa(int(interfaceStatus.Index))
 		interfaceTags := []string{
 			"status:" + status,
@@ -103,34 +113,6 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 	}
 }
 
-func computeInterfaceStatus(adminStatus devicemetadata.IfAdminStatus, operStatus devicemetadata.IfOperStatus) devicemetadata.InterfaceStatus {
-	if adminStatus == devicemetadata.AdminStatusUp {
-		switch {
-		case operStatus == devicemetadata.OperStatusUp:
-			return devicemetadata.InterfaceStatusUp
-		case operStatus == devicemetadata.OperStatusDown:
-			return devicemetadata.InterfaceStatusDown
-		}
-		return devicemetadata.InterfaceStatusWarning
-	}
-	if adminStatus == devicemetadata.AdminStatusDown {
-		switch {
-		case operStatus == devicemetadata.OperStatusUp:
-			return devicemetadata.InterfaceStatusDown
-		case operStatus == devicemetadata.OperStatusDown:
-			return devicemetadata.InterfaceStatusOff
-		}
-		return devicemetadata.InterfaceStatusWarning
-	}
-	if adminStatus == devicemetadata.AdminStatusTesting {
-		switch {
-		case operStatus != devicemetadata.OperStatusDown:
-			return devicemetadata.InterfaceStatusWarning
-		}
-	}
-	return devicemetadata.InterfaceStatusDown
-}
-
 func buildMetadataStore(metadataConfigs profiledefinition.MetadataConfig, values *valuestore.ResultValueStore) *metadata.Store {
 	metadataStore := metadata.NewMetadataStore()
 	if values == nil {
@@ -415,8 +397,12 @@ func buildNetworkTopologyMetadataWithLLDP(deviceID string, store *metadata.Store
 	return links
 }
 
-//nolint:revive // TODO(NDM) Fix revive linter
-func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store, _ []devicemetadata.InterfaceMetadata) []devicemetadata.TopologyLinkMetadata {
+func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store, interfaces []devicemetadata.InterfaceMetadata) []devicemetadata.TopologyLinkMetadata {
+	interfaceNameByIndex := make(map[string]string, len(interfaces))
+	for _, devInterface := range interfaces {
+		interfaceNameByIndex[strconv.Itoa(int(devInterface.Index))] = devInterface.Name
+	}
+
 	indexes := store.GetColumnIndexes("cdp_remote.interface_id") // using `cdp_remote.interface_id` to get indexes since it's expected to be always present
 	if len(indexes) == 0 {
 		log.Debugf("Unable to build links metadata: no cdp_remote indexes found")
@@ -440,6 +426,13 @@ func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store,
 
 		resolvedLocalInterfaceID := deviceID + ":" + cdpCacheIfIndex
 
+		// cdpCacheIfIndex is the ifIndex of the local interface, its name is only known when the interface is collected
+		var localInterfaceIDType string
+		localInterfaceID := interfaceNameByIndex[cdpCacheIfIndex]
+		if localInterfaceID != "" {
+			localInterfaceIDType = devicemetadata.IDTypeInterfaceName
+		}
+
 		// remEntryUniqueID: The combination of cdpCacheIfIndex and cdpCacheDeviceIndex is expected to be unique for each entry in cdpCacheTable
 		remEntryUniqueID := cdpCacheIfIndex + "." + cdpCacheDeviceIndex
 
@@ -463,8 +456,8 @@ func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store,
 			Local: &devicemetadata.TopologyLinkSide{
 				Interface: &devicemetadata.TopologyLinkInterface{
 					DDID:   resolvedLocalInterfaceID,
-					ID:     "",
-					IDType: "",
+					ID:     localInterfaceID,
+					IDType: localInterfaceIDType,
 				},
 				Device: &devicemetadata.TopologyLinkDevice{
 					DDID: deviceID,
@@ -772,7 +772,7 @@ func TestComputeInterfaceStatus(t *testing.T) {
 		{100, metadata.OperStatusTesting, metadata.InterfaceStatusDown},
 	}
 	for _, test := range allTests {
-		assert.Equal(t, test.status, computeInterfaceStatus(test.ifAdminStatus, test.ifOperStatus))
+		assert.Equal(t, test.status, metadata.ComputeInterfaceStatus(test.ifAdminStatus, test.ifOperStatus))
 	}
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/optional"
+	systemdutil "github.com/DataDog/datadog-agent/pkg/util/systemd"
 
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 )
@@ -178,7 +179,7 @@ type systemdStats interface {
 type defaultSystemdStats struct{}
 
 func (s *defaultSystemdStats) PrivateSocketConnection(privateSocket string) (*dbus.Conn, error) {
-	return NewSystemdConnection(privateSocket)
+	return systemdutil.NewSystemdConnection(privateSocket)
 }
 
 func (s *defaultSystemdStats) SystemBusSocketConnection() (*dbus.Conn, error) {
@@ -271,13 +272,12 @@ func (c *SystemdCheck) getDbusConnection() (*dbus.Conn, error) {
 	if c.config.instance.PrivateSocket != "" {
 		conn, err = c.getPrivateSocketConnection(c.config.instance.PrivateSocket)
 	} else {
-		defaultPrivateSocket := "/run/systemd/private"
 		if env.IsContainerized() {
-			conn, err = c.getPrivateSocketConnection("/host" + defaultPrivateSocket)
+			conn, err = c.getPrivateSocketConnection("/host" + systemdutil.DefaultPrivateSocket)
 		} else {
 			conn, err = c.getSystemBusSocketConnection()
 			if err != nil {
-				conn, err = c.getPrivateSocketConnection(defaultPrivateSocket)
+				conn, err = c.getPrivateSocketConnection(systemdutil.DefaultPrivateSocket)
 			}
 		}
 	}
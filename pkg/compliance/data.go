@@ -245,6 +245,9 @@ type (
 		KubeApiserver *InputSpecKubeapiserver `yaml:"kubeApiserver,omitempty" json:"kubeApiserver,omitempty"`
 		Package       *InputSpecPackage       `yaml:"package,omitempty" json:"package,omitempty"`
 		XCCDF         *InputSpecXCCDF         `yaml:"xccdf,omitempty" json:"xccdf,omitempty"`
+		Sysctl        *InputSpecSysctl        `yaml:"sysctl,omitempty" json:"sysctl,omitempty"`
+		Systemd       *InputSpecSystemd       `yaml:"systemd,omitempty" json:"systemd,omitempty"`
+		Socket        *InputSpecSocket        `yaml:"socket,omitempty" json:"socket,omitempty"`
 		Constants     *InputSpecConstants     `yaml:"constants,omitempty" json:"constants,omitempty"`
 
 		TagName string `yaml:"tag,omitempty" json:"tag,omitempty"`
@@ -308,6 +311,25 @@ type (
 		Rules   []string `yaml:"rules,omitempty" json:"rules,omitempty"`
 	}
 
+	// InputSpecSysctl describes the spec to resolve kernel parameters. The name
+	// uses the dotted sysctl notation and may contain wildcards.
+	InputSpecSysctl struct {
+		Name string `yaml:"name" json:"name"`
+	}
+
+	// InputSpecSystemd describes the spec to resolve the state of systemd
+	// units. The unit name may contain wildcards.
+	InputSpecSystemd struct {
+		Unit string `yaml:"unit" json:"unit"`
+	}
+
+	// InputSpecSocket describes the spec to resolve the listening sockets of
+	// the host, optionally filtered by protocol (tcp or udp) and port.
+	InputSpecSocket struct {
+		Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+		Port     uint16 `yaml:"port,omitempty" json:"port,omitempty"`
+	}
+
 	// InputSpecConstants can be used to pass constants data to the evaluator.
 	InputSpecConstants map[string]interface{}
 )
@@ -399,17 +421,30 @@ func (i *InputSpec) Valid() error {
 	// NOTE(jinroh): the current semantics allow to specify the result type as
 	// an "array". Here we enforce that the specified result type is
 	// constrained to a specific input type.
-	if i.KubeApiserver != nil || i.Docker != nil || i.Audit != nil {
+	if i.KubeApiserver != nil || i.Docker != nil || i.Audit != nil || i.Socket != nil {
 		if i.Type != "array" {
-			return fmt.Errorf("input of types kubeApiserver docker and audit have to be arrays")
+			return fmt.Errorf("input of types kubeApiserver docker audit and socket have to be arrays")
 		}
 	} else if i.Type == "array" {
-		if i.File == nil {
+		switch {
+		case i.File != nil:
+			if isGlob := i.File.Glob != "" || strings.Contains(i.File.Path, "*"); !isGlob {
+				return fmt.Errorf("file input results defined as array has to be a glob path")
+			}
+		case i.Sysctl != nil:
+			if !strings.Contains(i.Sysctl.Name, "*") {
+				return fmt.Errorf("sysctl input results defined as array has to be a glob name")
+			}
+		case i.Systemd != nil:
+			if !strings.Contains(i.Systemd.Unit, "*") {
+				return fmt.Errorf("systemd input results defined as array has to be a glob unit")
+			}
+		default:
 			return fmt.Errorf("bad input results `array`")
 		}
-		if isGlob := i.File.Glob != "" || strings.Contains(i.File.Path, "*"); !isGlob {
-			return fmt.Errorf("file input results defined as array has to be a glob path")
-		}
+	}
+	if i.Socket != nil && i.Socket.Protocol != "" && i.Socket.Protocol != "tcp" && i.Socket.Protocol != "udp" {
+		return fmt.Errorf("unsupported socket protocol %q", i.Socket.Protocol)
 	}
 	return nil
 }
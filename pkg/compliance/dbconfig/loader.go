@@ -33,6 +33,12 @@ const (
 
 	mongoDBResourceType = "db_mongodb"
 	mongoDBConfigPath   = "/etc/mongod.conf"
+
+	mysqlResourceType = "db_mysql"
+
+	redisResourceType = "db_redis"
+
+	nginxResourceType = "db_nginx"
 )
 
 func relPath(hostroot, configPath string) string {
@@ -69,6 +75,12 @@ func GetProcResourceType(proc *process.Process) (string, bool) {
 		return postgresqlResourceType, true
 	case "mongod":
 		return mongoDBResourceType, true
+	case "mysqld", "mariadbd":
+		return mysqlResourceType, true
+	case "redis-server":
+		return redisResourceType, true
+	case "nginx":
+		return nginxResourceType, true
 	case "java":
 		cmdline, _ := proc.CmdlineSlice()
 		if len(cmdline) > 0 && cmdline[len(cmdline)-1] == "org.apache.cassandra.service.CassandraDaemon" {
@@ -93,6 +105,12 @@ func LoadConfiguration(ctx context.Context, rootPath string, proc *process.Proce
 		conf, ok = LoadMongoDBConfig(ctx, rootPath, proc)
 	case cassandraResourceType:
 		conf, ok = LoadCassandraConfig(ctx, rootPath, proc)
+	case mysqlResourceType:
+		conf, ok = LoadMySQLConfig(ctx, rootPath, proc)
+	case redisResourceType:
+		conf, ok = LoadRedisConfig(ctx, rootPath, proc)
+	case nginxResourceType:
+		conf, ok = LoadNginxConfig(ctx, rootPath, proc)
 	default:
 		ok = false
 	}
@@ -129,6 +147,12 @@ func LoadDBResourceFromPID(ctx context.Context, pid int32) (*DBResource, bool) {
 		conf, ok = LoadMongoDBConfig(ctx, hostroot, proc)
 	case cassandraResourceType:
 		conf, ok = LoadCassandraConfig(ctx, hostroot, proc)
+	case mysqlResourceType:
+		conf, ok = LoadMySQLConfig(ctx, hostroot, proc)
+	case redisResourceType:
+		conf, ok = LoadRedisConfig(ctx, hostroot, proc)
+	case nginxResourceType:
+		conf, ok = LoadNginxConfig(ctx, hostroot, proc)
 	default:
 		ok = false
 	}
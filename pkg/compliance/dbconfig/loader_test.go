@@ -309,6 +309,224 @@ func TestMongoDBConfParsing(t *testing.T) {
 	assert.Equal(t, "/var/log/mongodb/mongod.log", *configData.SystemLog.Path)
 }
 
+func TestMySQLConfParsing(t *testing.T) {
+	hostroot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(hostroot, "/etc/mysql/conf.d"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/mysql/my.cnf"), []byte(mysqlConfigSample), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/mysql/conf.d/mysqld.cnf"), []byte(mysqlConfigIncluded), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/mysql/conf.d/ignored.txt"), []byte("[mysqld]\nport = 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	proc, stop := launchFakeProcess(context.Background(), t, "mysqld")
+	defer stop()
+
+	resourceType, ok := GetProcResourceType(proc)
+	assert.True(t, ok)
+	assert.Equal(t, mysqlResourceType, resourceType)
+
+	c, ok := LoadMySQLConfig(context.Background(), hostroot, proc)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0600), c.ConfigFileMode)
+	assert.Equal(t, "/etc/mysql/my.cnf", c.ConfigFilePath)
+	assert.NotEmpty(t, c.ConfigFileUser)
+	configData := c.ConfigData.(map[string]map[string]string)
+	assert.Equal(t, "3306", configData["mysqld"]["port"])
+	assert.Equal(t, "/var/log/mysql/error.log", configData["mysqld"]["log_error"])
+	assert.Equal(t, "0", configData["mysqld"]["local_infile"])
+	assert.Equal(t, "ON", configData["mysqld"]["skip_symbolic_links"])
+	assert.Equal(t, "TLSv1.2,TLSv1.3", configData["mysqld"]["tls_version"])
+	assert.Equal(t, "<redacted>", configData["client"]["password"])
+	assert.Equal(t, "/var/run/mysqld/mysqld.sock", configData["client"]["socket"])
+}
+
+func TestMySQLConfParsingDefaultsFile(t *testing.T) {
+	hostroot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(hostroot, "/etc/mysql"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/mysql/my.cnf"), []byte(mysqlConfigSample), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/mysql/custom.cnf"), []byte("[mariadbd]\nport=3307\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	proc, stop := launchFakeProcess(context.Background(), t, "mariadbd", "--defaults-file=/etc/mysql/custom.cnf")
+	defer stop()
+
+	c, ok := LoadMySQLConfig(context.Background(), hostroot, proc)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0640), c.ConfigFileMode)
+	assert.Equal(t, "/etc/mysql/custom.cnf", c.ConfigFilePath)
+	configData := c.ConfigData.(map[string]map[string]string)
+	assert.Equal(t, map[string]map[string]string{"mariadbd": {"port": "3307"}}, configData)
+}
+
+func TestRedisConfParsing(t *testing.T) {
+	hostroot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(hostroot, "/etc/redis"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/redis/redis.conf"), []byte(redisConfigSample), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/redis/local.conf"), []byte("bind 127.0.0.1 -::1\nprotected-mode no\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	proc, stop := launchFakeProcess(context.Background(), t, "redis-server", "/etc/redis/redis.conf")
+	defer stop()
+
+	resourceType, ok := GetProcResourceType(proc)
+	assert.True(t, ok)
+	assert.Equal(t, redisResourceType, resourceType)
+
+	c, ok := LoadRedisConfig(context.Background(), hostroot, proc)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0640), c.ConfigFileMode)
+	assert.Equal(t, "/etc/redis/redis.conf", c.ConfigFilePath)
+	configData := c.ConfigData.(map[string]interface{})
+	assert.Equal(t, "6379", configData["port"])
+	assert.Equal(t, "127.0.0.1 -::1", configData["bind"])
+	assert.Equal(t, "no", configData["protected-mode"])
+	assert.Equal(t, "<redacted>", configData["requirepass"])
+	assert.Equal(t, []string{"3600 1", "300 100"}, configData["save"])
+	assert.Equal(t, []string{"CONFIG \"\"", "FLUSHALL \"\""}, configData["rename-command"])
+	assert.Equal(t, []string{"default on ><redacted> ~* +@all"}, configData["user"])
+	assert.Equal(t, "/var/log/redis/redis server.log", configData["logfile"])
+}
+
+func TestNginxConfParsing(t *testing.T) {
+	hostroot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(hostroot, "/etc/nginx/sites-enabled"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/nginx/custom.conf"), []byte(nginxConfigSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hostroot, "/etc/nginx/sites-enabled/default"), []byte(nginxSiteSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	proc, stop := launchFakeProcess(context.Background(), t, "nginx", "-c", "/etc/nginx/custom.conf")
+	defer stop()
+
+	resourceType, ok := GetProcResourceType(proc)
+	assert.True(t, ok)
+	assert.Equal(t, nginxResourceType, resourceType)
+
+	c, ok := LoadNginxConfig(context.Background(), hostroot, proc)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0644), c.ConfigFileMode)
+	assert.Equal(t, "/etc/nginx/custom.conf", c.ConfigFilePath)
+	configData := c.ConfigData.(*nginxConfig)
+	expected := []*nginxDirective{
+		{Name: "user", Args: []string{"www-data"}},
+		{Name: "events", Block: []*nginxDirective{
+			{Name: "worker_connections", Args: []string{"768"}},
+		}},
+		{Name: "http", Block: []*nginxDirective{
+			{Name: "server_tokens", Args: []string{"off"}},
+			{Name: "log_format", Args: []string{"main", "$remote_addr - ${remote_user} [$time_local]"}},
+			{Name: "server", Block: []*nginxDirective{
+				{Name: "listen", Args: []string{"443", "ssl"}},
+				{Name: "ssl_protocols", Args: []string{"TLSv1.2", "TLSv1.3"}},
+				{Name: "location", Args: []string{"/"}, Block: []*nginxDirective{
+					{Name: "return", Args: []string{"200", "it's ok"}},
+				}},
+			}},
+		}},
+	}
+	assert.Equal(t, expected, configData.Directives)
+}
+
+func TestNginxConfParsingInvalid(t *testing.T) {
+	for _, config := range []string{
+		"http {\n",
+		"http }\n",
+		"user www-data\n",
+		"user 'www-data;\n",
+		"{ user www-data; }\n",
+	} {
+		hostroot := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(hostroot, "/etc/nginx"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(hostroot, nginxConfigPath), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		p := &nginxConfParser{hostroot: hostroot, confPrefix: nginxConfigPrefix}
+		_, ok := p.parseFile(nginxConfigPath, 0)
+		assert.False(t, ok, config)
+	}
+}
+
+const mysqlConfigSample = `
+# The MySQL database server configuration file.
+[client]
+socket   = /var/run/mysqld/mysqld.sock
+password = "s3cr3t"
+
+[mysqld]
+port = 3306 # default port
+skip-symbolic-links
+local-infile=1
+
+!includedir /etc/mysql/conf.d/
+`
+
+const mysqlConfigIncluded = `
+[mysqld]
+log-error = /var/log/mysql/error.log
+local_infile = 0
+tls_version = 'TLSv1.2,TLSv1.3'
+`
+
+const redisConfigSample = `
+# Redis configuration file example.
+
+port 6379
+protected-mode yes
+requirepass foobared
+save 3600 1
+save 300 100
+rename-command CONFIG ""
+rename-command FLUSHALL ""
+user default on >s3cr3t ~* +@all
+logfile "/var/log/redis/redis server.log"
+
+include /etc/redis/local.conf
+`
+
+const nginxConfigSample = `
+user www-data;
+
+events {
+	worker_connections 768; # multi_accept on;
+}
+
+http {
+	server_tokens off;
+	log_format main '$remote_addr - ${remote_user} [$time_local]';
+
+	include sites-enabled/*;
+}
+`
+
+const nginxSiteSample = `
+server {
+	listen 443 ssl;
+	ssl_protocols TLSv1.2 TLSv1.3;
+
+	location / {
+		return 200 "it\'s ok";
+	}
+}
+`
+
 const pgConfigCommon = `
 # -----------------------------
 # PostgreSQL configuration file
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dbconfig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/utils"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// mysqlConfigPaths are the global option files read by MySQL and MariaDB
+// servers, in order.
+var mysqlConfigPaths = []string{
+	"/etc/my.cnf",
+	"/etc/mysql/my.cnf",
+}
+
+// LoadMySQLConfig loads and extracts the MySQL or MariaDB configuration data
+// found on the system. Just like the server, all the global option files are
+// read and merged, unless the --defaults-file flag is specified. The
+// configuration data is indexed by option group (section).
+func LoadMySQLConfig(ctx context.Context, hostroot string, proc *process.Process) (*DBConfig, bool) {
+	var result DBConfig
+	result.ProcessUser, _ = proc.UsernameWithContext(ctx)
+	result.ProcessName, _ = proc.NameWithContext(ctx)
+
+	configPaths := mysqlConfigPaths
+	cmdline, _ := proc.CmdlineSlice()
+	for _, arg := range cmdline {
+		if path, ok := strings.CutPrefix(arg, "--defaults-file="); ok {
+			configPaths = []string{filepath.Clean(path)}
+			break
+		}
+		if path, ok := strings.CutPrefix(arg, "--defaults-extra-file="); ok {
+			configPaths = append(append([]string{}, mysqlConfigPaths...), filepath.Clean(path))
+		}
+	}
+
+	configData := make(map[string]map[string]string)
+	for _, configLocalPath := range configPaths {
+		configPath := filepath.Join(hostroot, configLocalPath)
+		fi, err := os.Stat(configPath)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		if !parseMySQLConfig(hostroot, configLocalPath, configData, 0) {
+			continue
+		}
+		// the reported file is the first option file found
+		if result.ConfigFilePath == "" {
+			result.ConfigFileUser = utils.GetFileUser(fi)
+			result.ConfigFileGroup = utils.GetFileGroup(fi)
+			result.ConfigFileMode = uint32(fi.Mode())
+			result.ConfigFilePath = relPath(hostroot, configPath)
+		}
+	}
+
+	if result.ConfigFilePath == "" {
+		// mysqld can run with its compiled-in defaults only.
+		result.ConfigFileUser = "<none>"
+		result.ConfigFileGroup = "<none>"
+		result.ConfigData = map[string]interface{}{}
+		return &result, true
+	}
+	result.ConfigData = configData
+	return &result, true
+}
+
+// parseMySQLConfig parses the given option file into the config map, following
+// the !include and !includedir directives. Option names are normalized to
+// use underscores, as dashes and underscores are interchangeable. Options
+// specified without a value are enabled boolean options and are set to "ON".
+// The values of password options are redacted.
+//
+// reference: https://dev.mysql.com/doc/refman/8.0/en/option-files.html
+func parseMySQLConfig(hostroot, configPath string, config map[string]map[string]string, includeDepth int) bool {
+	// Protect ourselves from circular includes.
+	if includeDepth > 10 {
+		return false
+	}
+
+	b, err := readFileLimit(filepath.Join(hostroot, configPath))
+	if err != nil {
+		return false
+	}
+
+	var section map[string]string
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Split(bufio.ScanLines)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if includedPath, ok := strings.CutPrefix(line, "!includedir"); ok {
+			includedPath = mysqlIncludedPath(configPath, includedPath)
+			matches, _ := filepath.Glob(filepath.Join(hostroot, includedPath, "*.cnf"))
+			for _, match := range matches {
+				parseMySQLConfig(hostroot, relPath(hostroot, match), config, includeDepth+1)
+			}
+			continue
+		}
+		if includedPath, ok := strings.CutPrefix(line, "!include"); ok {
+			parseMySQLConfig(hostroot, mysqlIncludedPath(configPath, includedPath), config, includeDepth+1)
+			continue
+		}
+
+		if line[0] == '[' {
+			name, _, ok := strings.Cut(line[1:], "]")
+			if !ok {
+				section = nil
+				continue
+			}
+			name = strings.ToLower(strings.TrimSpace(name))
+			if config[name] == nil {
+				config[name] = make(map[string]string)
+			}
+			section = config[name]
+			continue
+		}
+
+		// options outside of a group are invalid
+		if section == nil {
+			continue
+		}
+
+		key, val, hasValue := strings.Cut(line, "=")
+		key = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+		if key == "" {
+			continue
+		}
+		if !hasValue {
+			section[key] = "ON"
+		} else if strings.Contains(key, "password") {
+			section[key] = "<redacted>"
+		} else {
+			section[key] = parseMySQLValue(val)
+		}
+	}
+	return true
+}
+
+func mysqlIncludedPath(configPath, includedPath string) string {
+	includedPath = strings.TrimSpace(includedPath)
+	if !filepath.IsAbs(includedPath) {
+		includedPath = filepath.Join(filepath.Dir(configPath), includedPath)
+	}
+	return filepath.Clean(includedPath)
+}
+
+// parseMySQLValue removes the trailing comment and the enclosing quotes of an
+// option value.
+func parseMySQLValue(val string) string {
+	val = strings.TrimSpace(val)
+	if len(val) > 0 && (val[0] == '"' || val[0] == '\'') {
+		if end := strings.IndexByte(val[1:], val[0]); end >= 0 {
+			return val[1 : end+1]
+		}
+	}
+	if i := strings.IndexByte(val, '#'); i >= 0 {
+		val = strings.TrimSpace(val[:i])
+	}
+	return val
+}
@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dbconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/utils"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	nginxConfigPath   = "/etc/nginx/nginx.conf"
+	nginxConfigPrefix = "/etc/nginx"
+)
+
+// LoadNginxConfig loads and extracts the Nginx configuration data found on
+// the system. The configuration is exported as a tree of directives, the
+// include directives being replaced by the directives of the included files.
+func LoadNginxConfig(ctx context.Context, hostroot string, proc *process.Process) (*DBConfig, bool) {
+	var result DBConfig
+	result.ProcessUser, _ = proc.UsernameWithContext(ctx)
+	result.ProcessName, _ = proc.NameWithContext(ctx)
+
+	configLocalPath := nginxConfigPath
+	prefix := nginxConfigPrefix
+	cmdline := nginxCmdline(ctx, proc)
+	for i, arg := range cmdline {
+		if arg == "-c" && i+1 < len(cmdline) {
+			configLocalPath = cmdline[i+1]
+		}
+		if arg == "-p" && i+1 < len(cmdline) {
+			prefix = cmdline[i+1]
+		}
+	}
+	if !filepath.IsAbs(configLocalPath) {
+		configLocalPath = filepath.Join(prefix, configLocalPath)
+	}
+	configLocalPath = filepath.Clean(configLocalPath)
+
+	configPath := filepath.Join(hostroot, configLocalPath)
+	fi, err := os.Stat(configPath)
+	if err != nil || fi.IsDir() {
+		return nil, false
+	}
+
+	// relative include paths are resolved from the directory of the main
+	// configuration file
+	p := &nginxConfParser{
+		hostroot:   hostroot,
+		confPrefix: filepath.Dir(configLocalPath),
+	}
+	directives, ok := p.parseFile(configLocalPath, 0)
+	if !ok {
+		return nil, false
+	}
+	result.ConfigFileUser = utils.GetFileUser(fi)
+	result.ConfigFileGroup = utils.GetFileGroup(fi)
+	result.ConfigFileMode = uint32(fi.Mode())
+	result.ConfigFilePath = relPath(hostroot, configPath)
+	result.ConfigData = &nginxConfig{Directives: directives}
+	return &result, true
+}
+
+// nginxCmdline returns the command line of the nginx master process. Nginx
+// overwrites its process title ("nginx: master process /usr/sbin/nginx -c
+// ..."), and the worker processes do not show the flags of the master.
+func nginxCmdline(ctx context.Context, proc *process.Process) []string {
+	if parent, err := proc.ParentWithContext(ctx); err == nil {
+		if name, _ := parent.NameWithContext(ctx); name == "nginx" {
+			proc = parent
+		}
+	}
+	cmdline, _ := proc.CmdlineSliceWithContext(ctx)
+	if len(cmdline) == 1 {
+		cmdline = strings.Fields(cmdline[0])
+	}
+	return cmdline
+}
+
+type nginxConfParser struct {
+	hostroot   string
+	confPrefix string
+}
+
+func (p *nginxConfParser) parseFile(configPath string, includeDepth int) ([]*nginxDirective, bool) {
+	// Protect ourselves from circular includes.
+	if includeDepth > 10 {
+		return nil, false
+	}
+	b, err := readFileLimit(filepath.Join(p.hostroot, configPath))
+	if err != nil {
+		return nil, false
+	}
+	t := &nginxConfLexer{buf: b}
+	directives, closed, ok := p.parseBlock(t, includeDepth)
+	if !ok || closed || t.unterminated {
+		return nil, false
+	}
+	return directives, true
+}
+
+// parseBlock parses directives until the end of the current block. It
+// reports whether the block was closed by a brace, or by the end of file.
+func (p *nginxConfParser) parseBlock(t *nginxConfLexer, includeDepth int) ([]*nginxDirective, bool, bool) {
+	var directives []*nginxDirective
+	for {
+		tok, quoted, ok := t.next()
+		if !ok {
+			return directives, false, true
+		}
+		if !quoted {
+			switch tok {
+			case "}":
+				return directives, true, true
+			case ";", "{":
+				return nil, false, false
+			}
+		}
+
+		d := &nginxDirective{Name: tok}
+	args:
+		for {
+			tok, quoted, ok := t.next()
+			if !ok {
+				return nil, false, false
+			}
+			if quoted {
+				d.Args = append(d.Args, tok)
+				continue
+			}
+			switch tok {
+			case ";":
+				break args
+			case "{":
+				block, closed, ok := p.parseBlock(t, includeDepth)
+				if !ok || !closed {
+					return nil, false, false
+				}
+				d.Block = block
+				break args
+			case "}":
+				return nil, false, false
+			default:
+				d.Args = append(d.Args, tok)
+			}
+		}
+
+		if d.Name == "include" && len(d.Args) == 1 && d.Block == nil {
+			directives = append(directives, p.parseInclude(d.Args[0], includeDepth)...)
+		} else {
+			directives = append(directives, d)
+		}
+	}
+}
+
+func (p *nginxConfParser) parseInclude(pattern string, includeDepth int) []*nginxDirective {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(p.confPrefix, pattern)
+	}
+	var directives []*nginxDirective
+	matches, _ := filepath.Glob(filepath.Join(p.hostroot, pattern))
+	for _, match := range matches {
+		included, ok := p.parseFile(relPath(p.hostroot, match), includeDepth+1)
+		if ok {
+			directives = append(directives, included...)
+		}
+	}
+	return directives
+}
+
+// Simple ASCII lexer for nginx configuration files
+//
+// reference: https://github.com/nginx/nginx/blob/release-1.25.3/src/core/ngx_conf_file.c#L513
+type nginxConfLexer struct {
+	buf []byte
+	pos int
+
+	// unterminated is set when the end of file is reached within a quoted
+	// string
+	unterminated bool
+}
+
+// next returns the next token and whether it was quoted. Quoted tokens are
+// always directive names or arguments, while unquoted ";", "{" and "}"
+// tokens are part of the syntax.
+func (t *nginxConfLexer) next() (string, bool, bool) {
+	t.skipWhitespaceAndComments()
+	if t.pos >= len(t.buf) {
+		return "", false, false
+	}
+	c := t.buf[t.pos]
+	switch c {
+	case ';', '{', '}':
+		t.pos++
+		return string(c), false, true
+	case '"', '\'':
+		return t.scanQuotedString(c)
+	}
+	return t.scanWord()
+}
+
+func (t *nginxConfLexer) scanWord() (string, bool, bool) {
+	from := t.pos
+	for t.pos < len(t.buf) {
+		c := t.buf[t.pos]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ';' || c == '}' {
+			break
+		}
+		if c == '{' {
+			// variables can be written as ${name}
+			if t.pos == from || t.buf[t.pos-1] != '$' {
+				break
+			}
+			if end := strings.IndexByte(string(t.buf[t.pos:]), '}'); end > 0 {
+				t.pos += end
+			}
+		}
+		t.pos++
+	}
+	return string(t.buf[from:t.pos]), false, true
+}
+
+func (t *nginxConfLexer) scanQuotedString(quote byte) (string, bool, bool) {
+	t.pos++ // skipping the first quote
+	var out strings.Builder
+	for ; t.pos < len(t.buf); t.pos++ {
+		c := t.buf[t.pos]
+		if c == '\\' && t.pos+1 < len(t.buf) {
+			t.pos++
+			switch peeked := t.buf[t.pos]; peeked {
+			case '"', '\'', '\\':
+				out.WriteByte(peeked)
+			case 't':
+				out.WriteByte('\t')
+			case 'r':
+				out.WriteByte('\r')
+			case 'n':
+				out.WriteByte('\n')
+			default:
+				out.WriteByte(c)
+				out.WriteByte(peeked)
+			}
+			continue
+		}
+		if c == quote {
+			t.pos++
+			return out.String(), true, true
+		}
+		out.WriteByte(c)
+	}
+	t.unterminated = true
+	return "", false, false
+}
+
+func (t *nginxConfLexer) skipWhitespaceAndComments() {
+	for t.pos < len(t.buf) {
+		c := t.buf[t.pos]
+		if c == '#' {
+			for t.pos < len(t.buf) && t.buf[t.pos] != '\n' {
+				t.pos++
+			}
+			continue
+		}
+		if !isWhiteSpace(c) && c != '\n' {
+			break
+		}
+		t.pos++
+	}
+}
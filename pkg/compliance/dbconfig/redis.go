@@ -0,0 +1,217 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dbconfig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/utils"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+var redisConfigPaths = []string{
+	"/etc/redis/redis.conf",
+	"/etc/redis.conf",
+	"/usr/local/etc/redis/redis.conf",
+}
+
+// redisMultiDirectives are the directives that may be specified multiple
+// times, all of their occurrences being kept.
+var redisMultiDirectives = map[string]bool{
+	"save":                       true,
+	"rename-command":             true,
+	"client-output-buffer-limit": true,
+	"loadmodule":                 true,
+	"user":                       true,
+}
+
+// LoadRedisConfig loads and extracts the Redis configuration data found on
+// the system.
+func LoadRedisConfig(ctx context.Context, hostroot string, proc *process.Process) (*DBConfig, bool) {
+	var result DBConfig
+	result.ProcessUser, _ = proc.UsernameWithContext(ctx)
+	result.ProcessName, _ = proc.NameWithContext(ctx)
+
+	// The configuration file is given as argument of redis-server. It may
+	// not be visible when the process title is overwritten (set-proc-title
+	// directive), in which case we look for it at the usual locations.
+	configPaths := redisConfigPaths
+	cmdline, _ := proc.CmdlineSlice()
+	for _, arg := range cmdline {
+		if !strings.HasPrefix(arg, "-") && strings.HasSuffix(arg, ".conf") {
+			configPaths = []string{filepath.Clean(arg)}
+			break
+		}
+	}
+
+	for _, configLocalPath := range configPaths {
+		configPath := filepath.Join(hostroot, configLocalPath)
+		fi, err := os.Stat(configPath)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		configData := make(map[string]interface{})
+		if !parseRedisConfig(hostroot, configLocalPath, configData, 0) {
+			return nil, false
+		}
+		result.ConfigFileUser = utils.GetFileUser(fi)
+		result.ConfigFileGroup = utils.GetFileGroup(fi)
+		result.ConfigFileMode = uint32(fi.Mode())
+		result.ConfigFilePath = relPath(hostroot, configPath)
+		result.ConfigData = configData
+		return &result, true
+	}
+
+	// redis-server can be started without a configuration file.
+	result.ConfigFileUser = "<none>"
+	result.ConfigFileGroup = "<none>"
+	result.ConfigData = map[string]interface{}{}
+	return &result, true
+}
+
+// parseRedisConfig parses the given configuration file into the config map,
+// following the include directives. Directive names are lowercased and their
+// arguments joined by a space, empty arguments being quoted. The last
+// occurrence of a directive wins, except for the directives that can be
+// repeated which are kept as a list. Passwords are redacted.
+//
+// reference: https://redis.io/docs/management/config-file/
+func parseRedisConfig(hostroot, configPath string, config map[string]interface{}, includeDepth int) bool {
+	// Protect ourselves from circular includes.
+	if includeDepth > 10 {
+		return false
+	}
+
+	b, err := readFileLimit(filepath.Join(hostroot, configPath))
+	if err != nil {
+		return false
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Split(bufio.ScanLines)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		args, ok := splitRedisArgs(line)
+		if !ok || len(args) == 0 {
+			continue
+		}
+		key := strings.ToLower(args[0])
+		args = args[1:]
+
+		switch key {
+		case "include":
+			if len(args) != 1 {
+				continue
+			}
+			includedPath := args[0]
+			if !filepath.IsAbs(includedPath) {
+				includedPath = filepath.Join(filepath.Dir(configPath), includedPath)
+			}
+			parseRedisConfig(hostroot, filepath.Clean(includedPath), config, includeDepth+1)
+			continue
+		case "requirepass", "masterauth", "masteruser":
+			config[key] = "<redacted>"
+			continue
+		case "user":
+			args = redactRedisUserRules(args)
+		}
+
+		for i, arg := range args {
+			// keep the empty arguments visible, as in rename-command CONFIG ""
+			if arg == "" {
+				args[i] = `""`
+			}
+		}
+		val := strings.Join(args, " ")
+		if redisMultiDirectives[key] {
+			vals, _ := config[key].([]string)
+			config[key] = append(vals, val)
+		} else {
+			config[key] = val
+		}
+	}
+	return true
+}
+
+// redactRedisUserRules redacts the passwords and password hashes of an ACL
+// user definition.
+func redactRedisUserRules(rules []string) []string {
+	redacted := make([]string, len(rules))
+	for i, rule := range rules {
+		if len(rule) > 1 && (rule[0] == '>' || rule[0] == '<' || rule[0] == '#' || rule[0] == '!') {
+			rule = rule[:1] + "<redacted>"
+		}
+		redacted[i] = rule
+	}
+	return redacted
+}
+
+// splitRedisArgs splits a configuration line into its arguments, supporting
+// single and double quoted strings like redis sdssplitargs.
+func splitRedisArgs(line string) ([]string, bool) {
+	var args []string
+	for i := 0; i < len(line); {
+		c := line[i]
+		if c == ' ' || c == '\t' {
+			i++
+			continue
+		}
+		var arg strings.Builder
+		if c == '"' || c == '\'' {
+			quote := c
+			i++
+			closed := false
+			for i < len(line) {
+				c = line[i]
+				if c == '\\' && quote == '"' && i+1 < len(line) {
+					switch line[i+1] {
+					case 'n':
+						arg.WriteByte('\n')
+					case 'r':
+						arg.WriteByte('\r')
+					case 't':
+						arg.WriteByte('\t')
+					default:
+						arg.WriteByte(line[i+1])
+					}
+					i += 2
+					continue
+				}
+				if c == '\\' && quote == '\'' && i+1 < len(line) && line[i+1] == '\'' {
+					arg.WriteByte('\'')
+					i += 2
+					continue
+				}
+				i++
+				if c == quote {
+					closed = true
+					break
+				}
+				arg.WriteByte(c)
+			}
+			// closing quotes must be followed by a space or nothing at all
+			if !closed || (i < len(line) && line[i] != ' ' && line[i] != '\t') {
+				return nil, false
+			}
+		} else {
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				arg.WriteByte(line[i])
+				i++
+			}
+		}
+		args = append(args, arg.String())
+	}
+	return args, true
+}
@@ -230,3 +230,13 @@ type cassandraDBConfig struct {
 		InternodeEncryption string `yaml:"internode_encryption" json:"internode_encryption"`
 	} `yaml:"server_encryption_options" json:"server_encryption_options"`
 }
+
+type nginxConfig struct {
+	Directives []*nginxDirective `json:"directives"`
+}
+
+type nginxDirective struct {
+	Name  string            `json:"name"`
+	Args  []string          `json:"args,omitempty"`
+	Block []*nginxDirective `json:"block,omitempty"`
+}
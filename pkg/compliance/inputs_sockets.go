@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compliance
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// procNetDir is the network directory of the host init process, so that
+	// the sockets of the host network namespace are resolved even from a
+	// container
+	procNetDir = "/proc/1/net"
+
+	tcpListenState = "0A"
+	udpListenState = "07"
+)
+
+type procNetFile struct {
+	name        string
+	protocol    string
+	family      string
+	listenState string
+}
+
+var procNetFiles = []procNetFile{
+	{name: "tcp", protocol: "tcp", family: "ipv4", listenState: tcpListenState},
+	{name: "tcp6", protocol: "tcp", family: "ipv6", listenState: tcpListenState},
+	{name: "udp", protocol: "udp", family: "ipv4", listenState: udpListenState},
+	{name: "udp6", protocol: "udp", family: "ipv6", listenState: udpListenState},
+}
+
+func (r *defaultResolver) resolveSocket(_ context.Context, spec InputSpecSocket) (interface{}, error) {
+	var resolved []interface{}
+	for _, file := range procNetFiles {
+		if spec.Protocol != "" && spec.Protocol != file.protocol {
+			continue
+		}
+		sockets, err := parseProcNetFile(r.pathNormalizeToHostRoot(procNetDir+"/"+file.name), file)
+		if err != nil {
+			return nil, err
+		}
+		for _, socket := range sockets {
+			if spec.Port != 0 && socket["port"] != spec.Port {
+				continue
+			}
+			resolved = append(resolved, socket)
+		}
+	}
+	return resolved, nil
+}
+
+// parseProcNetFile returns the listening sockets of a /proc/net/{tcp,udp}[6]
+// file.
+func parseProcNetFile(path string, file procNetFile) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sockets []map[string]interface{}
+	s := bufio.NewScanner(f)
+	// skip the header line
+	s.Scan()
+	for s.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(s.Text())
+		if len(fields) < 10 || fields[3] != file.listenState {
+			continue
+		}
+		ip, port, err := parseProcNetAddress(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed socket address in %s: %w", path, err)
+		}
+		uid, _ := strconv.Atoi(fields[7])
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
+		sockets = append(sockets, map[string]interface{}{
+			"protocol": file.protocol,
+			"family":   file.family,
+			"address":  ip.String(),
+			"port":     port,
+			"uid":      uid,
+			"inode":    inode,
+		})
+	}
+	return sockets, s.Err()
+}
+
+// parseProcNetAddress parses an address of the form 0100007F:0016. The IP is
+// made of 32 bits words in host byte order (little endian).
+func parseProcNetAddress(addr string) (net.IP, uint16, error) {
+	ipHex, portHex, ok := strings.Cut(addr, ":")
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid address %q", addr)
+	}
+	ip, err := hex.DecodeString(ipHex)
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address %q", addr)
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port in address %q", addr)
+	}
+	return net.IP(ip), uint16(port), nil
+}
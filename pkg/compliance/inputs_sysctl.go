@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compliance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const procSysDir = "/proc/sys"
+
+// sysctlNameToPath converts a dotted sysctl name to its path relative to
+// /proc/sys. Names already using slashes as separators are kept untouched so
+// that parameters of interfaces containing a dot can be specified.
+func sysctlNameToPath(name string) string {
+	if strings.Contains(name, "/") {
+		return strings.TrimPrefix(name, "/")
+	}
+	return strings.ReplaceAll(name, ".", "/")
+}
+
+func sysctlPathToName(path string) string {
+	return strings.ReplaceAll(path, "/", ".")
+}
+
+func (r *defaultResolver) resolveSysctl(ctx context.Context, spec InputSpecSysctl) (interface{}, error) {
+	name := strings.TrimSpace(spec.Name)
+	if name == "" {
+		return nil, errors.New("sysctl input requires a name")
+	}
+
+	procSys := r.pathNormalizeToHostRoot(procSysDir)
+	if !strings.Contains(name, "*") {
+		return r.resolveSysctlPath(ctx, procSys, sysctlNameToPath(name))
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(procSys, sysctlNameToPath(name))) // We ignore errors from Glob which are never I/O errors
+	var resolved []interface{}
+	for _, path := range paths {
+		rel, err := filepath.Rel(procSys, path)
+		if err != nil {
+			continue
+		}
+		param, err := r.resolveSysctlPath(ctx, procSys, rel)
+		if err != nil || param == nil {
+			continue
+		}
+		resolved = append(resolved, param)
+	}
+	return resolved, nil
+}
+
+// resolveSysctlPath reads a kernel parameter. The value is an integer when the
+// parameter holds a single integer, a string otherwise. The raw value is
+// always available as a string.
+func (r *defaultResolver) resolveSysctlPath(_ context.Context, procSys, rel string) (interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(procSys, rel))
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	raw := strings.TrimSpace(string(data))
+	var value interface{} = raw
+	if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		value = i
+	}
+
+	return map[string]interface{}{
+		"name":  sysctlPathToName(filepath.ToSlash(rel)),
+		"value": value,
+		"raw":   raw,
+	}, nil
+}
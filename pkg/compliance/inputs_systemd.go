@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/systemd"
+)
+
+// newSystemdClient connects to systemd through its private socket under the
+// host root when it is set, as when running in a container, and through the
+// system bus otherwise.
+func newSystemdClient(ctx context.Context, hostRoot string) (SystemdClient, error) {
+	var conn *dbus.Conn
+	var err error
+	if hostRoot != "" {
+		conn, err = systemd.NewSystemdConnection(filepath.Join(hostRoot, systemd.DefaultPrivateSocket))
+	} else {
+		conn, err = dbus.NewSystemConnectionContext(ctx)
+		if err != nil {
+			log.Debugf("systemd: failed to connect to the system bus, trying private socket: %v", err)
+			conn, err = systemd.NewSystemdConnection(systemd.DefaultPrivateSocket)
+		}
+	}
+	if err != nil {
+		return nil, ErrIncompatibleEnvironment
+	}
+	return &systemdClient{conn: conn}, nil
+}
+
+type systemdClient struct {
+	conn *dbus.Conn
+}
+
+func (c *systemdClient) Close() error {
+	c.conn.Close()
+	return nil
+}
+
+// ListUnits returns the units, loaded or only installed, matching the given
+// pattern.
+func (c *systemdClient) ListUnits(ctx context.Context, pattern string) ([]*SystemdUnit, error) {
+	units := make(map[string]*SystemdUnit)
+
+	files, err := c.conn.ListUnitFilesByPatternsContext(ctx, nil, []string{pattern})
+	if err != nil {
+		return nil, fmt.Errorf("systemd: failed to list unit files: %w", err)
+	}
+	for _, file := range files {
+		name := filepath.Base(file.Path)
+		units[name] = &SystemdUnit{
+			Name:          name,
+			Path:          file.Path,
+			UnitFileState: file.Type,
+		}
+	}
+
+	statuses, err := c.conn.ListUnitsByPatternsContext(ctx, nil, []string{pattern})
+	if err != nil {
+		return nil, fmt.Errorf("systemd: failed to list units: %w", err)
+	}
+	for _, status := range statuses {
+		unit, ok := units[status.Name]
+		if !ok {
+			unit = &SystemdUnit{Name: status.Name}
+			units[status.Name] = unit
+		}
+		unit.LoadState = status.LoadState
+		unit.ActiveState = status.ActiveState
+		unit.SubState = status.SubState
+	}
+
+	list := make([]*SystemdUnit, 0, len(units))
+	for _, unit := range units {
+		list = append(list, unit)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
@@ -64,6 +64,42 @@ type LinuxAuditClient interface {
 	Close() error
 }
 
+// SystemdProvider is a function returning a systemd client, given the path
+// to the mountpoint of the host root filesystem.
+type SystemdProvider func(ctx context.Context, hostRoot string) (SystemdClient, error)
+
+// SystemdClient is an interface that implements the capability of listing
+// the systemd units and their states.
+type SystemdClient interface {
+	ListUnits(ctx context.Context, pattern string) ([]*SystemdUnit, error)
+	Close() error
+}
+
+// SystemdUnit holds the state of a systemd unit. Units that are installed but
+// not loaded have empty load, active and sub states.
+type SystemdUnit struct {
+	Name          string
+	Path          string
+	LoadState     string
+	ActiveState   string
+	SubState      string
+	UnitFileState string
+}
+
+// Resolve the systemd unit
+func (u *SystemdUnit) Resolve() interface{} {
+	return map[string]interface{}{
+		"name":            u.Name,
+		"path":            u.Path,
+		"load_state":      u.LoadState,
+		"active_state":    u.ActiveState,
+		"sub_state":       u.SubState,
+		"unit_file_state": u.UnitFileState,
+		"active":          u.ActiveState == "active",
+		"enabled":         u.UnitFileState == "enabled" || u.UnitFileState == "enabled-runtime",
+	}
+}
+
 // DefaultDockerProvider returns the default Docker client.
 func DefaultDockerProvider(ctx context.Context) (docker.CommonAPIClient, error) {
 	return newDockerClient(ctx)
@@ -74,6 +110,12 @@ func DefaultLinuxAuditProvider(_ context.Context) (LinuxAuditClient, error) {
 	return newLinuxAuditClient()
 }
 
+// DefaultSystemdProvider returns the default systemd client, connected
+// through D-Bus.
+func DefaultSystemdProvider(ctx context.Context, hostRoot string) (SystemdClient, error) {
+	return newSystemdClient(ctx, hostRoot)
+}
+
 // ResolverOptions is an options struct required to instantiate a Resolver
 // instance.
 type ResolverOptions struct {
@@ -95,6 +137,7 @@ type ResolverOptions struct {
 	DockerProvider
 	KubernetesProvider
 	LinuxAuditProvider
+	SystemdProvider
 }
 
 // Resolver interface defines a generic method to resolve the inputs
@@ -118,6 +161,7 @@ type defaultResolver struct {
 	kubernetesCl      kubedynamic.Interface
 	kubernetesDiscoCl kubediscovery.DiscoveryInterface
 	linuxAuditCl      LinuxAuditClient
+	systemdCl         SystemdClient
 }
 
 type fileMeta struct {
@@ -146,6 +190,9 @@ func NewResolver(ctx context.Context, opts ResolverOptions) Resolver {
 	if opts.LinuxAuditProvider != nil {
 		r.linuxAuditCl, _ = opts.LinuxAuditProvider(ctx)
 	}
+	if opts.SystemdProvider != nil {
+		r.systemdCl, _ = opts.SystemdProvider(ctx, opts.HostRoot)
+	}
 	return r
 }
 
@@ -158,6 +205,10 @@ func (r *defaultResolver) Close() {
 		r.linuxAuditCl.Close()
 		r.linuxAuditCl = nil
 	}
+	if r.systemdCl != nil {
+		r.systemdCl.Close()
+		r.systemdCl = nil
+	}
 	r.kubernetesCl = nil
 	r.kubernetesDiscoCl = nil
 
@@ -238,6 +289,15 @@ func (r *defaultResolver) ResolveInputs(ctx context.Context, rule *Rule) (Resolv
 		case spec.Package != nil:
 			resultType = "package"
 			result, err = r.resolvePackage(ctx, *spec.Package)
+		case spec.Sysctl != nil:
+			resultType = "sysctl"
+			result, err = r.resolveSysctl(ctx, *spec.Sysctl)
+		case spec.Systemd != nil:
+			resultType = "systemd"
+			result, err = r.resolveSystemd(ctx, *spec.Systemd)
+		case spec.Socket != nil:
+			resultType = "socket"
+			result, err = r.resolveSocket(ctx, *spec.Socket)
 		case spec.Constants != nil:
 			resultType = "constants"
 			result = *spec.Constants
@@ -556,6 +616,33 @@ func (r *defaultResolver) resolveAudit(_ context.Context, spec InputSpecAudit) (
 	return resolved, nil
 }
 
+func (r *defaultResolver) resolveSystemd(ctx context.Context, spec InputSpecSystemd) (interface{}, error) {
+	cl := r.systemdCl
+	if cl == nil {
+		return nil, ErrIncompatibleEnvironment
+	}
+	if spec.Unit == "" {
+		return nil, fmt.Errorf("systemd input requires a unit")
+	}
+	units, err := cl.ListUnits(ctx, spec.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(spec.Unit, "*") {
+		for _, unit := range units {
+			if unit.Name == spec.Unit {
+				return unit.Resolve(), nil
+			}
+		}
+		return nil, nil
+	}
+	var resolved []interface{}
+	for _, unit := range units {
+		resolved = append(resolved, unit.Resolve())
+	}
+	return resolved, nil
+}
+
 func (r *defaultResolver) resolveDocker(ctx context.Context, spec InputSpecDocker) (interface{}, error) {
 	cl := r.dockerCl
 	if cl == nil {
@@ -29,11 +29,13 @@ import (
 type suite struct {
 	t        *testing.T
 	hostname string
+	hostRoot string
 	rootDir  string
 
-	dockerClient docker.CommonAPIClient
-	auditClient  compliance.LinuxAuditClient
-	kubeClient   dynamic.Interface
+	dockerClient  docker.CommonAPIClient
+	auditClient   compliance.LinuxAuditClient
+	systemdClient compliance.SystemdClient
+	kubeClient    dynamic.Interface
 
 	rules []*assertedRule
 }
@@ -67,6 +69,11 @@ func (s *suite) WithHostname(hostname string) *suite {
 	return s
 }
 
+func (s *suite) WithHostRoot(hostRoot string) *suite {
+	s.hostRoot = hostRoot
+	return s
+}
+
 func (s *suite) WithDockerClient(cl docker.CommonAPIClient) *suite {
 	s.dockerClient = cl
 	return s
@@ -77,6 +84,11 @@ func (s *suite) WithAuditClient(cl compliance.LinuxAuditClient) *suite {
 	return s
 }
 
+func (s *suite) WithSystemdClient(cl compliance.SystemdClient) *suite {
+	s.systemdClient = cl
+	return s
+}
+
 func (s *suite) WithKubeClient(cl dynamic.Interface) *suite {
 	s.kubeClient = cl
 	return s
@@ -105,10 +117,14 @@ func (s *suite) Run() {
 		s.t.Run(c.name, func(t *testing.T) {
 			options := compliance.ResolverOptions{
 				Hostname: s.hostname,
+				HostRoot: s.hostRoot,
 			}
 			if s.auditClient != nil {
 				options.LinuxAuditProvider = func(context.Context) (compliance.LinuxAuditClient, error) { return s.auditClient, nil }
 			}
+			if s.systemdClient != nil {
+				options.SystemdProvider = func(context.Context, string) (compliance.SystemdClient, error) { return s.systemdClient, nil }
+			}
 			if s.dockerClient != nil {
 				options.DockerProvider = func(context.Context) (docker.CommonAPIClient, error) { return s.dockerClient, nil }
 			}
@@ -217,6 +233,13 @@ func (c *assertedRule) AssertErrorEvent() *assertedRule {
 	return c
 }
 
+func (c *assertedRule) AssertSkippedEvent() *assertedRule {
+	c.asserts = append(c.asserts, func(t *testing.T, evt *compliance.CheckEvent) {
+		assert.Equal(t, compliance.CheckSkipped, evt.Result)
+	})
+	return c
+}
+
 func (c *assertedRule) AssertNoEvent() *assertedRule {
 	c.noEvent = true
 	return c
@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testProcNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 21345 1 0000000000000000 100 0 0 10 0
+   1: 0F02000A:0016 0100000A:D3A2 01 00000000:00000000 02:000A7B6D 00000000     0        0 31234 4 0000000000000000 20 4 31 10 -1
+`
+
+const testProcNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 18234 1 0000000000000000 100 0 0 10 0
+`
+
+const testProcNetUDP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  12: 00000000:0044 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 17012 2 0000000000000000 0
+`
+
+func TestSocketInput(t *testing.T) {
+	hostRoot := t.TempDir()
+	writeHostFiles(t, hostRoot, map[string]string{
+		"proc/1/net/tcp":  testProcNetTCP,
+		"proc/1/net/tcp6": testProcNetTCP6,
+		"proc/1/net/udp":  testProcNetUDP,
+	})
+
+	b := newTestBench(t).
+		WithHostRoot(hostRoot)
+	defer b.Run()
+
+	b.AddRule("SocketAll").
+		WithInput(`
+- socket: {}
+	type: array
+	tag: sockets
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	count(input.sockets) == 3
+	s := input.sockets[_]
+	s.address == "127.0.0.1"
+	s.port == 3306
+	s.protocol == "tcp"
+	s.family == "ipv4"
+	s.uid == 999
+	s.inode == 21345
+	f := dd.passed_finding(
+		"socket",
+		sprintf("%%s:%%d", [s.address, s.port]),
+		{}
+	)
+}
+`).
+		AssertPassedEvent(func(t *testing.T, evt *compliance.CheckEvent) {
+			assert.Equal(t, "127.0.0.1:3306", evt.ResourceID)
+		})
+
+	b.AddRule("SocketPort").
+		WithInput(`
+- socket:
+		protocol: tcp
+		port: 22
+	type: array
+	tag: ssh
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	count(input.ssh) == 1
+	s := input.ssh[0]
+	s.address == "::"
+	s.family == "ipv6"
+	f := dd.failing_finding(
+		"socket",
+		"ssh",
+		{}
+	)
+}
+`).
+		AssertFailedEvent(nil)
+
+	b.AddRule("SocketUDP").
+		WithInput(`
+- socket:
+		protocol: udp
+		port: 53
+	type: array
+	tag: dns
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+has_key(o, k) {
+	_ := o[k]
+}
+
+findings[f] {
+	not has_key(input, "dns")
+	f := dd.passed_finding(
+		"socket",
+		"dns",
+		{}
+	)
+}
+`).
+		AssertPassedEvent(nil)
+
+	b.AddRule("SocketNotArray").
+		WithInput(`
+- socket:
+		port: 22
+`).
+		WithRego(`
+package datadog
+`).
+		AssertError()
+}
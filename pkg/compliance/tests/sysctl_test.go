@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeHostFiles(t *testing.T, hostRoot string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(hostRoot, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSysctlInput(t *testing.T) {
+	hostRoot := t.TempDir()
+	writeHostFiles(t, hostRoot, map[string]string{
+		"proc/sys/net/ipv4/ip_forward":             "0\n",
+		"proc/sys/net/ipv4/conf/all/rp_filter":     "1\n",
+		"proc/sys/net/ipv4/conf/default/rp_filter": "0\n",
+		"proc/sys/kernel/core_pattern":             "|/usr/lib/systemd/systemd-coredump %P\n",
+	})
+
+	b := newTestBench(t).
+		WithHostRoot(hostRoot)
+	defer b.Run()
+
+	b.AddRule("SysctlInteger").
+		WithInput(`
+- sysctl:
+		name: net.ipv4.ip_forward
+	tag: ip_forward
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	input.ip_forward.name == "net.ipv4.ip_forward"
+	input.ip_forward.value == 0
+	input.ip_forward.raw == "0"
+	f := dd.passed_finding(
+		"sysctl",
+		input.ip_forward.name,
+		{}
+	)
+}
+`).
+		AssertPassedEvent(func(t *testing.T, evt *compliance.CheckEvent) {
+			assert.Equal(t, "net.ipv4.ip_forward", evt.ResourceID)
+		})
+
+	b.AddRule("SysctlString").
+		WithInput(`
+- sysctl:
+		name: kernel/core_pattern
+	tag: core_pattern
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	input.core_pattern.value == "|/usr/lib/systemd/systemd-coredump %%P"
+	f := dd.passed_finding(
+		"sysctl",
+		input.core_pattern.name,
+		{}
+	)
+}
+`).
+		AssertPassedEvent(func(t *testing.T, evt *compliance.CheckEvent) {
+			assert.Equal(t, "kernel.core_pattern", evt.ResourceID)
+		})
+
+	b.AddRule("SysctlGlob").
+		WithInput(`
+- sysctl:
+		name: net.ipv4.conf.*.rp_filter
+	type: array
+	tag: rp_filters
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	count(input.rp_filters) == 2
+	p := input.rp_filters[_]
+	p.value != 1
+	f := dd.failing_finding(
+		"sysctl",
+		p.name,
+		{"value": p.value}
+	)
+}
+`).
+		AssertFailedEvent(func(t *testing.T, evt *compliance.CheckEvent) {
+			assert.Equal(t, "net.ipv4.conf.default.rp_filter", evt.ResourceID)
+		})
+
+	b.AddRule("SysctlNotFound").
+		WithInput(`
+- sysctl:
+		name: net.ipv6.conf.all.forwarding
+	tag: forwarding
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+has_key(o, k) {
+	_ := o[k]
+}
+
+findings[f] {
+	not has_key(input, "forwarding")
+	f := dd.passed_finding(
+		"sysctl",
+		"net.ipv6.conf.all.forwarding",
+		{}
+	)
+}
+`).
+		AssertPassedEvent(nil)
+
+	b.AddRule("SysctlArrayWithoutGlob").
+		WithInput(`
+- sysctl:
+		name: net.ipv4.ip_forward
+	type: array
+`).
+		WithRego(`
+package datadog
+`).
+		AssertError()
+}
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tests
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSystemdClient struct {
+	units []*compliance.SystemdUnit
+}
+
+func (cl *fakeSystemdClient) ListUnits(_ context.Context, pattern string) ([]*compliance.SystemdUnit, error) {
+	var units []*compliance.SystemdUnit
+	for _, unit := range cl.units {
+		if ok, _ := path.Match(pattern, unit.Name); ok {
+			units = append(units, unit)
+		}
+	}
+	return units, nil
+}
+
+func (cl *fakeSystemdClient) Close() error {
+	return nil
+}
+
+func TestSystemdInput(t *testing.T) {
+	b := newTestBench(t).
+		WithSystemdClient(&fakeSystemdClient{
+			units: []*compliance.SystemdUnit{
+				{
+					Name:          "auditd.service",
+					Path:          "/usr/lib/systemd/system/auditd.service",
+					LoadState:     "loaded",
+					ActiveState:   "active",
+					SubState:      "running",
+					UnitFileState: "enabled",
+				},
+				{
+					Name:          "rsync.service",
+					Path:          "/usr/lib/systemd/system/rsync.service",
+					UnitFileState: "disabled",
+				},
+				{
+					Name:          "telnet.socket",
+					Path:          "/usr/lib/systemd/system/telnet.socket",
+					LoadState:     "loaded",
+					ActiveState:   "active",
+					SubState:      "listening",
+					UnitFileState: "enabled",
+				},
+			},
+		})
+	defer b.Run()
+
+	b.AddRule("SystemdUnit").
+		WithInput(`
+- systemd:
+		unit: auditd.service
+	tag: auditd
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	input.auditd.enabled
+	input.auditd.active
+	input.auditd.sub_state == "running"
+	f := dd.passed_finding(
+		"systemd_unit",
+		input.auditd.name,
+		{"path": input.auditd.path}
+	)
+}
+`).
+		AssertPassedEvent(func(t *testing.T, evt *compliance.CheckEvent) {
+			assert.Equal(t, "auditd.service", evt.ResourceID)
+			assert.Equal(t, "/usr/lib/systemd/system/auditd.service", evt.Data["path"])
+		})
+
+	b.AddRule("SystemdUnitNotFound").
+		WithInput(`
+- systemd:
+		unit: telnet.service
+	tag: telnet
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+has_key(o, k) {
+	_ := o[k]
+}
+
+findings[f] {
+	not has_key(input, "telnet")
+	f := dd.passed_finding(
+		"systemd_unit",
+		"telnet.service",
+		{}
+	)
+}
+`).
+		AssertPassedEvent(nil)
+
+	b.AddRule("SystemdUnitGlob").
+		WithInput(`
+- systemd:
+		unit: "*.socket"
+	type: array
+	tag: sockets
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	u := input.sockets[_]
+	u.active
+	f := dd.failing_finding(
+		"systemd_unit",
+		u.name,
+		{}
+	)
+}
+`).
+		AssertFailedEvent(func(t *testing.T, evt *compliance.CheckEvent) {
+			assert.Equal(t, "telnet.socket", evt.ResourceID)
+		})
+}
+
+func TestSystemdInputNoClient(t *testing.T) {
+	b := newTestBench(t)
+	defer b.Run()
+
+	b.AddRule("SystemdNoClient").
+		WithInput(`
+- systemd:
+		unit: auditd.service
+`).
+		WithRego(`
+package datadog
+import data.datadog as dd
+
+findings[f] {
+	f := dd.passed_finding(
+		"systemd_unit",
+		"auditd.service",
+		{}
+	)
+}
+`).
+		AssertSkippedEvent()
+}
@@ -74,6 +74,20 @@ func DiscoverComponentsFromConfig() ([]pkgconfigsetup.ConfigurationProviders, []
 		}
 	}
 
+	// Auto-add the Nomad config provider when the Nomad collector is enabled
+	if pkgconfigsetup.Datadog().GetString("nomad_agent_url") != "" && flavor.GetFlavor() == flavor.DefaultAgent {
+		detectedProviders = append(detectedProviders, pkgconfigsetup.ConfigurationProviders{Name: names.NomadRegisterName})
+		log.Info("Nomad agent URL is set: Adding the Nomad config provider")
+	}
+
+	// Auto-add the systemd config provider and listener when the systemd
+	// units are collected
+	if pkgconfigsetup.Datadog().GetBool("systemd_units.enabled") && flavor.GetFlavor() == flavor.DefaultAgent {
+		detectedProviders = append(detectedProviders, pkgconfigsetup.ConfigurationProviders{Name: names.SystemdRegisterName})
+		detectedListeners = append(detectedListeners, pkgconfigsetup.Listeners{Name: "systemd"})
+		log.Info("systemd units collection is enabled: Adding the systemd config provider and listener")
+	}
+
 	// Auto-activate autodiscovery without listeners: - snmp
 	snmpConfig, err := snmplistener.NewListenerConfig()
 
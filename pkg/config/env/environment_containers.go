@@ -37,6 +37,7 @@ func init() {
 	registerFeature(Docker)
 	registerFeature(Containerd)
 	registerFeature(Cri)
+	registerFeature(Crio)
 	registerFeature(Kubernetes)
 	registerFeature(ECSEC2)
 	registerFeature(ECSFargate)
@@ -134,6 +135,10 @@ func detectContainerd(features FeatureMap, cfg model.Reader) {
 		if strings.Contains(criSocket, "containerd") {
 			features[Containerd] = struct{}{}
 		}
+
+		if strings.Contains(criSocket, "crio") {
+			features[Crio] = struct{}{}
+		}
 	}
 
 	// Merge containerd_namespace with containerd_namespaces
@@ -163,6 +163,11 @@ func GetDefaultSecurityProfilesDir() string {
 	return filepath.Join(defaultRunPath, "runtime-security", "profiles")
 }
 
+// GetDefaultSecurityQuarantineDir is the default directory used to store the files quarantined by the runtime security module
+func GetDefaultSecurityQuarantineDir() string {
+	return filepath.Join(defaultRunPath, "runtime-security", "quarantine")
+}
+
 // List of integrations allowed to be configured by RC by default
 var defaultAllowedRCIntegrations = []string{}
 
@@ -280,6 +285,8 @@ func initCommonWithServerless(config pkgconfigmodel.Setup) {
 // (in particular more than just the serverless agent).
 func InitConfig(config pkgconfigmodel.Setup) {
 	initCommonWithServerless(config)
+	nomad(config)
+	systemdUnits(config)
 
 	// Auto exit configuration
 	config.BindEnvAndSetDefault("auto_exit.validation_period", 60)
@@ -371,6 +378,20 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("kubernetes_node_label_as_cluster_name", "")
 	config.BindEnvAndSetDefault("kubernetes_namespace_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("kubernetes_namespace_annotations_as_tags", map[string]string{})
+	// tagger_enrichment_rules is a list of rules deriving, renaming or dropping
+	// entity tags before they are stored by the tagger
+	config.BindEnv("tagger_enrichment_rules")
+	config.ParseEnvAsSlice("tagger_enrichment_rules", func(in string) []interface{} {
+		var rules []interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"tagger_enrichment_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
+	// tagger_tombstone_grace_period is how long the tags of entities removed
+	// from the tagger are still served, for data arriving after the removal
+	config.BindEnvAndSetDefault("tagger_tombstone_grace_period", time.Duration(0))
+	config.BindEnvAndSetDefault("tagger_tombstone_max_entities", 10000)
 	// kubernetes_resources_annotations_as_tags should be parseable as map[string]map[string]string
 	// it maps group resources to annotations as tags maps
 	// a group resource has the format `{resource}.{group}`, or simply `{resource}` if it belongs to the empty group
@@ -448,6 +469,7 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.bind_host", "0.0.0.0")
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.stop_timeout", 5) // in seconds
 	config.SetKnown("network_devices.snmp_traps.users")
+	config.SetKnown("network_devices.snmp_traps.rules")
 
 	// NetFlow
 	config.SetKnown("network_devices.netflow.listeners")
@@ -460,6 +482,9 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 	config.BindEnvAndSetDefault("network_devices.netflow.reverse_dns_enrichment_enabled", false)
+	config.SetKnown("network_devices.netflow.enrichment.asn_database_path")
+	config.SetKnown("network_devices.netflow.enrichment.geoip_database_path")
+	config.SetKnown("network_devices.netflow.enrichment.cidr_labels")
 
 	// Network Path
 	config.BindEnvAndSetDefault("network_path.connections_monitoring.enabled", false)
@@ -474,6 +499,8 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("network_path.collector.flush_interval", "10s")
 	config.BindEnvAndSetDefault("network_path.collector.reverse_dns_enrichment.enabled", true)
 	config.BindEnvAndSetDefault("network_path.collector.reverse_dns_enrichment.timeout", 5000)
+	config.BindEnvAndSetDefault("network_path.collector.protocol", "")
+	config.BindEnvAndSetDefault("network_path.collector.ipv6_enabled", false)
 	bindEnvAndSetLogsConfigKeys(config, "network_path.forwarder.")
 
 	// Kube ApiServer
@@ -678,6 +705,8 @@ func InitConfig(config pkgconfigmodel.Setup) {
 
 	// Autoscaling product
 	config.BindEnvAndSetDefault("autoscaling.workload.enabled", false)
+	config.BindEnvAndSetDefault("autoscaling.workload.local_recommender.refresh_period", 30)         // value in seconds. Frequency of local recommendations computed from metric queries
+	config.BindEnvAndSetDefault("autoscaling.workload.local_recommender.remote_values_max_age", 600) // value in seconds. Remote recommendations older than this are replaced by local recommendations
 	config.BindEnvAndSetDefault("autoscaling.failover.enabled", false)
 	config.BindEnv("autoscaling.failover.metrics")
 
@@ -722,6 +751,30 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("cluster_checks.exclude_checks", []string{})
 	config.BindEnvAndSetDefault("cluster_checks.exclude_checks_from_dispatching", []string{})
 	config.BindEnvAndSetDefault("cluster_checks.rebalance_period", 10*time.Minute)
+	config.BindEnvAndSetDefault("cluster_checks.cost_model.type", "busyness") // possible values: busyness / weighted
+	config.BindEnvAndSetDefault("cluster_checks.cost_model.execution_time_weight", 1.0)
+	config.BindEnvAndSetDefault("cluster_checks.cost_model.metric_samples_weight", 1.0)
+	config.BindEnvAndSetDefault("cluster_checks.cost_model.histogram_buckets_weight", 1.0)
+	config.BindEnvAndSetDefault("cluster_checks.cost_model.events_weight", 0.2)
+	config.BindEnvAndSetDefault("cluster_checks.cost_model.memory_weight", 1.0)
+	// cluster_checks.cost_model.checks is a list of per-check weights and memory estimates
+	config.BindEnv("cluster_checks.cost_model.checks")
+	config.ParseEnvAsSlice("cluster_checks.cost_model.checks", func(in string) []interface{} {
+		var checks []interface{}
+		if err := json.Unmarshal([]byte(in), &checks); err != nil {
+			log.Errorf(`"cluster_checks.cost_model.checks" can not be parsed: %v`, err)
+		}
+		return checks
+	})
+	// cluster_checks.placement_constraints is a list of anti-affinity rules between checks
+	config.BindEnv("cluster_checks.placement_constraints")
+	config.ParseEnvAsSlice("cluster_checks.placement_constraints", func(in string) []interface{} {
+		var constraints []interface{}
+		if err := json.Unmarshal([]byte(in), &constraints); err != nil {
+			log.Errorf(`"cluster_checks.placement_constraints" can not be parsed: %v`, err)
+		}
+		return constraints
+	})
 
 	// Cluster check runner
 	config.BindEnvAndSetDefault("clc_runner_enabled", false)
@@ -735,6 +788,15 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	// Admission controller
 	config.BindEnvAndSetDefault("admission_controller.enabled", false)
 	config.BindEnvAndSetDefault("admission_controller.validation.enabled", true)
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.endpoint", "/validate-hygiene")
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.mode", "warn") // possible values: enforce / warn / dry_run
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.enabled_namespaces", []string{})
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.disabled_namespaces", []string{})
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.unified_service_tagging.enabled", true)
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.unified_service_tagging.required_tags", []string{"env", "service", "version"})
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.autodiscovery_annotations.enabled", true)
+	config.BindEnvAndSetDefault("admission_controller.validation.hygiene.apm_dogstatsd_env.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.mutation.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.mutate_unlabelled", false)
 	config.BindEnvAndSetDefault("admission_controller.port", 8000)
@@ -1655,6 +1717,17 @@ func podman(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("podman_db_path", "")
 }
 
+func nomad(config pkgconfigmodel.Setup) {
+	config.BindEnvAndSetDefault("nomad_agent_url", "") // empty is disabled
+	config.BindEnvAndSetDefault("nomad_token", "")
+	config.BindEnvAndSetDefault("nomad_query_timeout", int64(5)) // in seconds
+}
+
+func systemdUnits(config pkgconfigmodel.Setup) {
+	config.BindEnvAndSetDefault("systemd_units.enabled", false)
+	config.BindEnvAndSetDefault("systemd_units.private_socket", "") // empty uses the system bus
+}
+
 // LoadProxyFromEnv overrides the proxy settings with environment variables
 func LoadProxyFromEnv(config pkgconfigmodel.Config) {
 	// Viper doesn't handle mixing nested variables from files and set
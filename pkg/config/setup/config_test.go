@@ -675,6 +675,8 @@ func TestNetworkPathDefaults(t *testing.T) {
 	assert.Equal(t, 10*time.Second, config.GetDuration("network_path.collector.flush_interval"))
 	assert.Equal(t, true, config.GetBool("network_path.collector.reverse_dns_enrichment.enabled"))
 	assert.Equal(t, 5000, config.GetInt("network_path.collector.reverse_dns_enrichment.timeout"))
+	assert.Equal(t, "", config.GetString("network_path.collector.protocol"))
+	assert.Equal(t, false, config.GetBool("network_path.collector.ipv6_enabled"))
 }
 
 func TestUsePodmanLogsAndDockerPathOverride(t *testing.T) {
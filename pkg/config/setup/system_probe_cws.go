@@ -140,6 +140,8 @@ func initCWSSystemProbeConfig(cfg pkgconfigmodel.Config) {
 	cfg.BindEnvAndSetDefault("runtime_security_config.enforcement.disarmer.executable.enabled", true)
 	cfg.BindEnvAndSetDefault("runtime_security_config.enforcement.disarmer.executable.max_allowed", 5)
 	cfg.BindEnvAndSetDefault("runtime_security_config.enforcement.disarmer.executable.period", "1m")
+	cfg.BindEnvAndSetDefault("runtime_security_config.enforcement.network_isolation.enabled", false)
+	cfg.BindEnvAndSetDefault("runtime_security_config.enforcement.quarantine.directory", GetDefaultSecurityQuarantineDir())
 
 	cfg.BindEnvAndSetDefault("runtime_security_config.network_monitoring.enabled", false)
 }
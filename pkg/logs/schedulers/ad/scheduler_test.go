@@ -103,6 +103,27 @@ func TestScheduleUDPConfig(t *testing.T) {
 	assert.Equal(t, "a1887023ed72a2b0d083ef465e8edfe4932a25731d4bda2f39f288f70af3405b", logSource.Config.Identifier)
 }
 
+func TestScheduleSystemdConfig(t *testing.T) {
+	scheduler, spy := setup()
+	configSource := integration.Config{
+		Name:          "redisdb",
+		LogsConfig:    []byte(`[{"service":"redis","source":"redis"}]`),
+		ADIdentifiers: []string{"systemd_unit://redis.service"},
+		Provider:      names.Systemd,
+		ServiceID:     "systemd_unit://redis.service",
+	}
+
+	scheduler.Schedule([]integration.Config{configSource})
+
+	require.Equal(t, 1, len(spy.Events))
+	require.True(t, spy.Events[0].Add)
+	logSource := spy.Events[0].Source
+	assert.Equal(t, "redisdb", logSource.Name)
+	assert.Equal(t, "redis", logSource.Config.Service)
+	assert.Equal(t, config.JournaldType, logSource.Config.Type)
+	assert.Equal(t, []string{"redis.service"}, logSource.Config.IncludeSystemUnits)
+}
+
 func TestScheduleConfigCreatesNewSourceServiceFallback(t *testing.T) {
 	scheduler, spy := setup()
 	configSource := integration.Config{
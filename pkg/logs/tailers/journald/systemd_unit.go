@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package journald
+
+import (
+	"github.com/coreos/go-systemd/sdjournal"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// getSystemdUnit returns the systemd unit which logged the journal entry.
+func (t *Tailer) getSystemdUnit(entry *sdjournal.JournalEntry) string {
+	return entry.Fields[sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT]
+}
+
+// getSystemdUnitTags returns all the tags of a given systemd unit, they are
+// only known when the systemd units are collected by workloadmeta.
+func (t *Tailer) getSystemdUnitTags(unit string) []string {
+	tags, err := t.tagger.Tag(types.NewEntityID(types.SystemdUnit, unit), types.HighCardinality)
+	if err != nil {
+		log.Debug(err)
+	}
+	return tags
+}
@@ -440,6 +440,8 @@ func (t *Tailer) getTags(entry *sdjournal.JournalEntry) []string {
 	var tags []string
 	if t.isContainerEntry(entry) {
 		tags = t.getContainerTags(t.getContainerID(entry))
+	} else if unit := t.getSystemdUnit(entry); unit != "" {
+		tags = t.getSystemdUnitTags(unit)
 	}
 	return tags
 }
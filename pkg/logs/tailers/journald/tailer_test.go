@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/DataDog/datadog-agent/comp/core/tagger/mock"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -442,6 +443,27 @@ func TestApplicationNameShouldBeDockerWhenTagNotFound(t *testing.T) {
 	assert.False(t, hit)
 }
 
+func TestGetTagsForSystemdUnitEntries(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	fakeTagger := mock.SetupFakeTagger(t)
+	fakeTagger.SetTags(types.NewEntityID(types.SystemdUnit, "foo.service"), "fooSource", []string{"systemd_unit:foo.service", "service:foo"}, nil, nil, nil)
+	tailer := NewTailer(source, nil, nil, true, fakeTagger)
+
+	assert.ElementsMatch(t, []string{"systemd_unit:foo.service", "service:foo"}, tailer.getTags(
+		&sdjournal.JournalEntry{
+			Fields: map[string]string{
+				sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT: "foo.service",
+			},
+		}))
+
+	assert.Empty(t, tailer.getTags(
+		&sdjournal.JournalEntry{
+			Fields: map[string]string{
+				sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT: "bar.service",
+			},
+		}))
+}
+
 func TestWrongTypeFromCache(t *testing.T) {
 	containerID := "bar3"
 
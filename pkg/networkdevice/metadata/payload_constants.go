@@ -73,3 +73,32 @@ const (
 	InterfaceStatusWarning InterfaceStatus = "warning"
 	InterfaceStatusOff     InterfaceStatus = "off"
 )
+
+// ComputeInterfaceStatus computes the interface status from its admin and oper statuses
+func ComputeInterfaceStatus(adminStatus IfAdminStatus, operStatus IfOperStatus) InterfaceStatus {
+	if adminStatus == AdminStatusUp {
+		switch {
+		case operStatus == OperStatusUp:
+			return InterfaceStatusUp
+		case operStatus == OperStatusDown:
+			return InterfaceStatusDown
+		}
+		return InterfaceStatusWarning
+	}
+	if adminStatus == AdminStatusDown {
+		switch {
+		case operStatus == OperStatusUp:
+			return InterfaceStatusDown
+		case operStatus == OperStatusDown:
+			return InterfaceStatusOff
+		}
+		return InterfaceStatusWarning
+	}
+	if adminStatus == AdminStatusTesting {
+		switch {
+		case operStatus != OperStatusDown:
+			return InterfaceStatusWarning
+		}
+	}
+	return InterfaceStatusDown
+}
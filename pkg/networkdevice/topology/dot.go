@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the graph in the Graphviz DOT format. Devices that aren't
+// monitored are dashed, links are labeled with their interfaces and protocols.
+func (g Graph) WriteDOT(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "graph topology {")
+	fmt.Fprintln(out, "  node [shape=box];")
+	for _, node := range g.Nodes {
+		label := []string{node.ID}
+		if node.Name != "" {
+			label = []string{node.Name}
+		}
+		if node.IPAddress != "" {
+			label = append(label, node.IPAddress)
+		}
+		attributes := "label=" + dotQuote(label...)
+		if !node.Monitored {
+			attributes += ", style=dashed"
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", dotQuote(node.ID), attributes)
+	}
+	for _, link := range g.Links {
+		fmt.Fprintf(out, "  %s -- %s [taillabel=%s, headlabel=%s, label=%s];\n",
+			dotQuote(link.Source.Device), dotQuote(link.Target.Device),
+			dotQuote(link.Source.Interface), dotQuote(link.Target.Interface),
+			dotQuote(strings.Join(link.SourceTypes, ",")))
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// dotQuote returns a DOT quoted string, lines are separated by newlines
+func dotQuote(lines ...string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	for i, line := range lines {
+		lines[i] = escaper.Replace(line)
+	}
+	return `"` + strings.Join(lines, `\n`) + `"`
+}
@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, BuildGraph(testDevices).WriteDOT(&buf))
+
+	assert.Equal(t, `graph topology {
+  node [shape=box];
+  "default:10.0.0.1" [label="switch1\n10.0.0.1"];
+  "default:10.0.0.2" [label="switch2\n10.0.0.2"];
+  "neighbor:router1.example.com" [label="router1.example.com\n10.0.0.254", style=dashed];
+  "default:10.0.0.1" -- "default:10.0.0.2" [taillabel="swp1", headlabel="Ethernet48", label="lldp"];
+  "default:10.0.0.2" -- "neighbor:router1.example.com" [taillabel="Ethernet49", headlabel="GigabitEthernet0/1", label="cdp"];
+}
+`, buf.String())
+}
+
+func TestDOTQuote(t *testing.T) {
+	assert.Equal(t, `"a \"quoted\" C:\\name\nline"`, dotQuote(`a "quoted" C:\name`, "line"))
+}
@@ -0,0 +1,256 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// Graph is the network topology: devices and the links between their interfaces
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Links []Link `json:"links"`
+}
+
+// Node is a device of the topology. Devices only known as LLDP/CDP neighbors
+// of monitored devices are not monitored.
+type Node struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	IPAddress   string `json:"ip_address,omitempty"`
+	Description string `json:"description,omitempty"`
+	Monitored   bool   `json:"monitored"`
+}
+
+// Endpoint is one side of a link
+type Endpoint struct {
+	Device    string `json:"device"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// Link is a link between two devices, reported by one or both sides
+type Link struct {
+	Source      Endpoint `json:"source"`
+	Target      Endpoint `json:"target"`
+	SourceTypes []string `json:"source_types"`
+}
+
+// graphBuilder resolves LLDP/CDP neighbors to monitored devices
+type graphBuilder struct {
+	nodes map[string]*Node
+	links map[string]*Link
+
+	devicesByIP   map[string]string
+	devicesByName map[string]string
+	devicesByMAC  map[string]string
+	interfaces    map[string][]metadata.InterfaceMetadata
+}
+
+// BuildGraph builds the topology graph from the topologies of the monitored
+// devices. Neighbors are matched to monitored devices by IP address, MAC address
+// or name, and links reported by both sides are only listed once.
+func BuildGraph(devices []DeviceTopology) Graph {
+	b := &graphBuilder{
+		nodes:         make(map[string]*Node),
+		links:         make(map[string]*Link),
+		devicesByIP:   make(map[string]string),
+		devicesByName: make(map[string]string),
+		devicesByMAC:  make(map[string]string),
+		interfaces:    make(map[string][]metadata.InterfaceMetadata),
+	}
+	for _, device := range devices {
+		b.addDevice(device)
+	}
+	for _, device := range devices {
+		for _, link := range device.Links {
+			b.addLink(device.Device.ID, link)
+		}
+	}
+	return b.graph()
+}
+
+func (b *graphBuilder) addDevice(device DeviceTopology) {
+	id := device.Device.ID
+	b.nodes[id] = &Node{
+		ID:          id,
+		Name:        device.Device.Name,
+		IPAddress:   device.Device.IPAddress,
+		Description: device.Device.Description,
+		Monitored:   true,
+	}
+	b.interfaces[id] = device.Interfaces
+	if device.Device.IPAddress != "" {
+		b.devicesByIP[device.Device.IPAddress] = id
+	}
+	if device.Device.Name != "" {
+		b.devicesByName[normalizeName(device.Device.Name)] = id
+	}
+	for _, itf := range device.Interfaces {
+		if itf.MacAddress != "" {
+			b.devicesByMAC[strings.ToLower(itf.MacAddress)] = id
+		}
+	}
+}
+
+func (b *graphBuilder) addLink(deviceID string, link metadata.TopologyLinkMetadata) {
+	if link.Local == nil || link.Remote == nil || link.Remote.Device == nil {
+		return
+	}
+	local := Endpoint{Device: deviceID, Interface: b.localInterface(deviceID, link.Local.Interface)}
+	remote := Endpoint{Device: b.remoteDevice(link.Remote.Device)}
+	if remote.Device == "" {
+		return
+	}
+	remote.Interface = b.remoteInterface(remote.Device, link.Remote.Interface)
+
+	// links are keyed by their sorted endpoints so that the link reported by the
+	// neighbor is merged with the local one
+	source, target := local, remote
+	if endpointKey(target) < endpointKey(source) {
+		source, target = target, source
+	}
+	key := endpointKey(source) + "|" + endpointKey(target)
+	existing, ok := b.links[key]
+	if !ok {
+		existing = &Link{Source: source, Target: target}
+		b.links[key] = existing
+	}
+	for _, sourceType := range existing.SourceTypes {
+		if sourceType == link.SourceType {
+			return
+		}
+	}
+	existing.SourceTypes = append(existing.SourceTypes, link.SourceType)
+	sort.Strings(existing.SourceTypes)
+}
+
+// localInterface returns the name of the local interface of a link
+func (b *graphBuilder) localInterface(deviceID string, itf *metadata.TopologyLinkInterface) string {
+	if itf == nil {
+		return ""
+	}
+	for _, candidate := range b.interfaces[deviceID] {
+		if itf.DDID != "" && itf.DDID == interfaceDDID(deviceID, candidate) {
+			return interfaceName(candidate)
+		}
+	}
+	return itf.ID
+}
+
+// remoteDevice returns the node of the neighbor, adding it when it's not monitored
+func (b *graphBuilder) remoteDevice(device *metadata.TopologyLinkDevice) string {
+	if device.IDType == metadata.IDTypeMacAddress {
+		if id, ok := b.devicesByMAC[strings.ToLower(device.ID)]; ok {
+			return id
+		}
+	}
+	if id, ok := b.devicesByIP[device.IPAddress]; ok && device.IPAddress != "" {
+		return id
+	}
+	for _, name := range []string{device.Name, device.ID} {
+		if id, ok := b.devicesByName[normalizeName(name)]; ok && name != "" {
+			return id
+		}
+	}
+
+	name := device.Name
+	if name == "" && device.IDType != metadata.IDTypeMacAddress {
+		name = device.ID
+	}
+	var id string
+	for _, candidate := range []string{name, device.ID, device.IPAddress} {
+		if candidate != "" {
+			id = "neighbor:" + candidate
+			break
+		}
+	}
+	if id == "" {
+		return ""
+	}
+	if _, ok := b.nodes[id]; !ok {
+		b.nodes[id] = &Node{
+			ID:          id,
+			Name:        name,
+			IPAddress:   device.IPAddress,
+			Description: device.Description,
+		}
+	}
+	return id
+}
+
+// remoteInterface returns the name of the neighbor interface, resolved using the
+// interfaces of the neighbor when it's monitored
+func (b *graphBuilder) remoteInterface(deviceID string, itf *metadata.TopologyLinkInterface) string {
+	if itf == nil {
+		return ""
+	}
+	for _, candidate := range b.interfaces[deviceID] {
+		switch itf.IDType {
+		case metadata.IDTypeMacAddress:
+			if strings.EqualFold(candidate.MacAddress, itf.ID) {
+				return interfaceName(candidate)
+			}
+		case metadata.IDTypeInterfaceAlias:
+			if candidate.Alias == itf.ID {
+				return interfaceName(candidate)
+			}
+		}
+	}
+	if itf.ID == "" || itf.IDType == metadata.IDTypeMacAddress && itf.Description != "" {
+		return itf.Description
+	}
+	return itf.ID
+}
+
+func (b *graphBuilder) graph() Graph {
+	g := Graph{Nodes: []Node{}, Links: []Link{}}
+	for _, node := range b.nodes {
+		g.Nodes = append(g.Nodes, *node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	keys := make([]string, 0, len(b.links))
+	for key := range b.links {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		g.Links = append(g.Links, *b.links[key])
+	}
+	return g
+}
+
+func endpointKey(e Endpoint) string {
+	return e.Device + "|" + e.Interface
+}
+
+func interfaceDDID(deviceID string, itf metadata.InterfaceMetadata) string {
+	return deviceID + ":" + strconv.Itoa(int(itf.Index))
+}
+
+func interfaceName(itf metadata.InterfaceMetadata) string {
+	if itf.Name != "" {
+		return itf.Name
+	}
+	return strconv.Itoa(int(itf.Index))
+}
+
+// normalizeName lowercases device names and removes their domain, CDP device IDs
+// are usually fully qualified while LLDP system names are not
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	if net.ParseIP(name) != nil {
+		return name
+	}
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// switch1 and switch2 are connected by LLDP on swp1/Ethernet48, switch2 also has a
+// CDP neighbor that isn't monitored
+var testDevices = []DeviceTopology{
+	{
+		Device: metadata.DeviceMetadata{ID: "default:10.0.0.1", Name: "switch1", IPAddress: "10.0.0.1"},
+		Interfaces: []metadata.InterfaceMetadata{
+			{DeviceID: "default:10.0.0.1", Index: 1, Name: "swp1", MacAddress: "00:00:00:00:01:01"},
+			{DeviceID: "default:10.0.0.1", Index: 2, Name: "swp2", MacAddress: "00:00:00:00:01:02"},
+		},
+		Links: []metadata.TopologyLinkMetadata{
+			{
+				ID:         "default:10.0.0.1:1.1",
+				SourceType: "lldp",
+				Local: &metadata.TopologyLinkSide{
+					Device:    &metadata.TopologyLinkDevice{DDID: "default:10.0.0.1"},
+					Interface: &metadata.TopologyLinkInterface{DDID: "default:10.0.0.1:1", ID: "swp1", IDType: "interface_name"},
+				},
+				Remote: &metadata.TopologyLinkSide{
+					Device:    &metadata.TopologyLinkDevice{ID: "00:00:00:00:02:00", IDType: "mac_address", Name: "switch2.example.com"},
+					Interface: &metadata.TopologyLinkInterface{ID: "00:00:00:00:02:30", IDType: "mac_address", Description: "uplink"},
+				},
+			},
+		},
+	},
+	{
+		Device: metadata.DeviceMetadata{ID: "default:10.0.0.2", Name: "switch2", IPAddress: "10.0.0.2"},
+		Interfaces: []metadata.InterfaceMetadata{
+			{DeviceID: "default:10.0.0.2", Index: 48, Name: "Ethernet48", Alias: "uplink", MacAddress: "00:00:00:00:02:30"},
+			{DeviceID: "default:10.0.0.2", Index: 49, Name: "Ethernet49", MacAddress: "00:00:00:00:02:31"},
+		},
+		Links: []metadata.TopologyLinkMetadata{
+			{
+				ID:         "default:10.0.0.2:48.1",
+				SourceType: "lldp",
+				Local: &metadata.TopologyLinkSide{
+					Device:    &metadata.TopologyLinkDevice{DDID: "default:10.0.0.2"},
+					Interface: &metadata.TopologyLinkInterface{DDID: "default:10.0.0.2:48", ID: "uplink", IDType: "interface_alias"},
+				},
+				Remote: &metadata.TopologyLinkSide{
+					Device:    &metadata.TopologyLinkDevice{ID: "switch1", IDType: "local", IPAddress: "10.0.0.1"},
+					Interface: &metadata.TopologyLinkInterface{ID: "swp1", IDType: "interface_name"},
+				},
+			},
+			{
+				ID:         "default:10.0.0.2:49.3",
+				SourceType: "cdp",
+				Local: &metadata.TopologyLinkSide{
+					Device:    &metadata.TopologyLinkDevice{DDID: "default:10.0.0.2"},
+					Interface: &metadata.TopologyLinkInterface{DDID: "default:10.0.0.2:49"},
+				},
+				Remote: &metadata.TopologyLinkSide{
+					Device:    &metadata.TopologyLinkDevice{ID: "router1.example.com", IPAddress: "10.0.0.254"},
+					Interface: &metadata.TopologyLinkInterface{ID: "GigabitEthernet0/1", IDType: "interface_name"},
+				},
+			},
+		},
+	},
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := BuildGraph(testDevices)
+
+	assert.Equal(t, []Node{
+		{ID: "default:10.0.0.1", Name: "switch1", IPAddress: "10.0.0.1", Monitored: true},
+		{ID: "default:10.0.0.2", Name: "switch2", IPAddress: "10.0.0.2", Monitored: true},
+		{ID: "neighbor:router1.example.com", Name: "router1.example.com", IPAddress: "10.0.0.254"},
+	}, g.Nodes)
+	assert.Equal(t, []Link{
+		{
+			Source:      Endpoint{Device: "default:10.0.0.1", Interface: "swp1"},
+			Target:      Endpoint{Device: "default:10.0.0.2", Interface: "Ethernet48"},
+			SourceTypes: []string{"lldp"},
+		},
+		{
+			Source:      Endpoint{Device: "default:10.0.0.2", Interface: "Ethernet49"},
+			Target:      Endpoint{Device: "neighbor:router1.example.com", Interface: "GigabitEthernet0/1"},
+			SourceTypes: []string{"cdp"},
+		},
+	}, g.Links)
+}
+
+func TestBuildGraphMergesProtocols(t *testing.T) {
+	devices := []DeviceTopology{testDevices[0]}
+	cdpLink := testDevices[0].Links[0]
+	cdpLink.SourceType = "cdp"
+	cdpLink.Remote = &metadata.TopologyLinkSide{
+		Device:    &metadata.TopologyLinkDevice{ID: "SWITCH2", Name: "SWITCH2"},
+		Interface: &metadata.TopologyLinkInterface{ID: "uplink", IDType: "interface_name"},
+	}
+	devices[0].Links = append([]metadata.TopologyLinkMetadata{}, testDevices[0].Links[0], cdpLink)
+
+	g := BuildGraph(devices)
+
+	// switch2 isn't monitored, the neighbor is matched by name only
+	assert.Equal(t, []Node{
+		{ID: "default:10.0.0.1", Name: "switch1", IPAddress: "10.0.0.1", Monitored: true},
+		{ID: "neighbor:SWITCH2", Name: "SWITCH2"},
+		{ID: "neighbor:switch2.example.com", Name: "switch2.example.com"},
+	}, g.Nodes)
+	assert.Len(t, g.Links, 2)
+
+	devices[0].Links[1].Remote.Device.Name = "switch2.example.com"
+	g = BuildGraph(devices)
+	assert.Equal(t, []Link{{
+		Source:      Endpoint{Device: "default:10.0.0.1", Interface: "swp1"},
+		Target:      Endpoint{Device: "neighbor:switch2.example.com", Interface: "uplink"},
+		SourceTypes: []string{"cdp", "lldp"},
+	}}, g.Links)
+}
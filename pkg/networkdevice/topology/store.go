@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package topology keeps the LLDP/CDP links reported by the monitored devices
+// and builds the network topology graph across all of them.
+package topology
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// staleAfter is the delay after which devices that didn't report their topology
+// are no longer part of the graph, e.g. devices no longer monitored.
+const staleAfter = 1 * time.Hour
+
+// TimeNow is used to compute the age of device topologies, mocked in tests
+var TimeNow = time.Now
+
+// DeviceTopology is the topology data reported by a monitored device
+type DeviceTopology struct {
+	Device      metadata.DeviceMetadata
+	Interfaces  []metadata.InterfaceMetadata
+	Links       []metadata.TopologyLinkMetadata
+	CollectTime time.Time
+}
+
+// Store keeps the latest topology of each monitored device
+type Store struct {
+	mu      sync.Mutex
+	devices map[string]DeviceTopology
+}
+
+var defaultStore = NewStore()
+
+// NewStore returns an empty Store
+func NewStore() *Store {
+	return &Store{devices: make(map[string]DeviceTopology)}
+}
+
+// Default returns the Store fed by the device checks of the agent
+func Default() *Store {
+	return defaultStore
+}
+
+// SetDevice replaces the topology of a device. Stale devices are evicted
+// too, so that the store doesn't grow when the graph is never built.
+func (s *Store) SetDevice(topology DeviceTopology) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictStale()
+	s.devices[topology.Device.ID] = topology
+}
+
+// Devices returns the topologies reported recently, sorted by device ID
+func (s *Store) Devices() []DeviceTopology {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictStale()
+	devices := make([]DeviceTopology, 0, len(s.devices))
+	for _, device := range s.devices {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Device.ID < devices[j].Device.ID })
+	return devices
+}
+
+// evictStale removes the devices that didn't report their topology since
+// staleAfter, the lock must be held
+func (s *Store) evictStale() {
+	minCollectTime := TimeNow().Add(-staleAfter)
+	for id, device := range s.devices {
+		if device.CollectTime.Before(minCollectTime) {
+			delete(s.devices, id)
+		}
+	}
+}
+
+// Graph builds the topology graph of the devices reported recently
+func (s *Store) Graph() Graph {
+	return BuildGraph(s.Devices())
+}
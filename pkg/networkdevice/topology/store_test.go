@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	TimeNow = func() time.Time { return now }
+	defer func() { TimeNow = time.Now }()
+
+	store := NewStore()
+	stale := testDevices[0]
+	stale.CollectTime = now.Add(-2 * staleAfter)
+	store.SetDevice(stale)
+	recent := testDevices[1]
+	recent.CollectTime = now.Add(-time.Minute)
+	store.SetDevice(recent)
+
+	devices := store.Devices()
+	assert.Len(t, devices, 1)
+	assert.Equal(t, "default:10.0.0.2", devices[0].Device.ID)
+	// the stale device is still a neighbor of the recent one
+	nodes := store.Graph().Nodes
+	assert.Len(t, nodes, 3)
+	assert.Equal(t, Node{ID: "neighbor:switch1", Name: "switch1", IPAddress: "10.0.0.1"}, nodes[2])
+}
+
+func TestStoreEvictsOnSetDevice(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	TimeNow = func() time.Time { return now }
+	defer func() { TimeNow = time.Now }()
+
+	store := NewStore()
+	old := testDevices[0]
+	old.CollectTime = now
+	store.SetDevice(old)
+	assert.Len(t, store.devices, 1)
+
+	now = now.Add(2 * staleAfter)
+	recent := testDevices[1]
+	recent.CollectTime = now
+	store.SetDevice(recent)
+
+	// the stale device is gone even though the graph wasn't built
+	assert.Len(t, store.devices, 1)
+	assert.Contains(t, store.devices, "default:10.0.0.2")
+}
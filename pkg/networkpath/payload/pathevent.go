@@ -18,6 +18,8 @@ const (
 	ProtocolTCP Protocol = "TCP"
 	// ProtocolUDP is the UDP protocol.
 	ProtocolUDP Protocol = "UDP"
+	// ProtocolICMP is the ICMP protocol.
+	ProtocolICMP Protocol = "ICMP"
 )
 
 // PathOrigin origin of the path e.g. network_traffic, network_path_integration
@@ -44,6 +46,43 @@ type NetworkPathHop struct {
 	Reachable bool    `json:"reachable"`
 }
 
+// NetworkPathNode is a node of the multipath graph, it
+// represents a hop seen at a given TTL by one or more flows
+type NetworkPathNode struct {
+	ID        string `json:"id"`
+	TTL       int    `json:"ttl"`
+	IPAddress string `json:"ip_address"`
+
+	// hostname is the reverse DNS of the ip_address
+	Hostname string `json:"hostname,omitempty"`
+
+	// RTT is the average round trip time over the flows going through this node
+	RTT       float64 `json:"rtt,omitempty"`
+	Reachable bool    `json:"reachable"`
+	FlowCount int     `json:"flow_count"`
+}
+
+// NetworkPathEdge links two nodes of the multipath
+// graph at consecutive TTLs
+type NetworkPathEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	FlowCount int    `json:"flow_count"`
+}
+
+// NetworkPathMultipath encapsulates the paths discovered by
+// varying the flow identifiers of the probes, as a DAG
+type NetworkPathMultipath struct {
+	// PathsProbed is the number of flows used to probe the destination
+	PathsProbed int `json:"paths_probed"`
+	// DistinctPaths is the number of distinct hop sequences observed
+	DistinctPaths int `json:"distinct_paths"`
+	// MaxWidth is the largest number of distinct nodes at a single TTL
+	MaxWidth int               `json:"max_width"`
+	Nodes    []NetworkPathNode `json:"nodes"`
+	Edges    []NetworkPathEdge `json:"edges"`
+}
+
 // NetworkPathSource encapsulates information
 // about the source of a path
 type NetworkPathSource struct {
@@ -77,4 +116,8 @@ type NetworkPath struct {
 	Destination  NetworkPathDestination `json:"destination"`
 	Hops         []NetworkPathHop       `json:"hops"`
 	Tags         []string               `json:"tags,omitempty"`
+
+	// Multipath is only set when more than one path is probed,
+	// Hops then holds the path taken by the first flow
+	Multipath *NetworkPathMultipath `json:"multipath,omitempty"`
 }
@@ -64,4 +64,9 @@ func SubmitNetworkPathTelemetry(sender metricsender.MetricSender, path payload.N
 		sender.Gauge("datadog.network_path.path.reachable", float64(utils.BoolToFloat64(lastHop.Reachable)), newTags)
 		sender.Gauge("datadog.network_path.path.unreachable", float64(utils.BoolToFloat64(!lastHop.Reachable)), newTags)
 	}
+
+	if path.Multipath != nil {
+		sender.Gauge("datadog.network_path.path.distinct_paths", float64(path.Multipath.DistinctPaths), newTags)
+		sender.Gauge("datadog.network_path.path.max_width", float64(path.Multipath.MaxWidth), newTags)
+	}
 }
@@ -155,6 +155,69 @@ func TestSubmitNetworkPathTelemetry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with multipath",
+			path: payload.NetworkPath{
+				Origin:      payload.PathOriginNetworkPathIntegration,
+				Destination: payload.NetworkPathDestination{Hostname: "abc", IPAddress: "10.0.0.1"},
+				Protocol:    payload.ProtocolUDP,
+				Hops: []payload.NetworkPathHop{
+					{Hostname: "hop_1", IPAddress: "1.1.1.1", Reachable: true},
+				},
+				Multipath: &payload.NetworkPathMultipath{
+					PathsProbed:   4,
+					DistinctPaths: 3,
+					MaxWidth:      2,
+				},
+			},
+			checkDuration: 10 * time.Second,
+			checkInterval: 0,
+			tags:          metricTags,
+			expectedMetrics: []metricsender.MockReceivedMetric{
+				{
+					MetricType: metrics.GaugeType,
+					Name:       "datadog.network_path.check_duration",
+					Value:      float64(10),
+					Tags:       expectedTags,
+				},
+				{
+					MetricType: metrics.GaugeType,
+					Name:       "datadog.network_path.path.monitored",
+					Value:      float64(1),
+					Tags:       expectedTags,
+				},
+				{
+					MetricType: metrics.GaugeType,
+					Name:       "datadog.network_path.path.hops",
+					Value:      float64(1),
+					Tags:       expectedTags,
+				},
+				{
+					MetricType: metrics.GaugeType,
+					Name:       "datadog.network_path.path.reachable",
+					Value:      float64(1),
+					Tags:       expectedTags,
+				},
+				{
+					MetricType: metrics.GaugeType,
+					Name:       "datadog.network_path.path.unreachable",
+					Value:      float64(0),
+					Tags:       expectedTags,
+				},
+				{
+					MetricType: metrics.GaugeType,
+					Name:       "datadog.network_path.path.distinct_paths",
+					Value:      float64(3),
+					Tags:       expectedTags,
+				},
+				{
+					MetricType: metrics.GaugeType,
+					Name:       "datadog.network_path.path.max_width",
+					Value:      float64(2),
+					Tags:       expectedTags,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
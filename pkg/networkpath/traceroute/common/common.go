@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package common holds the hop discovery loop shared by the traceroute
+// implementations of each protocol and address family
+package common
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/gopacket/layers"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type (
+	// Prober sends the probes of a single flow and receives their
+	// responses, it is implemented for each protocol and address family
+	Prober interface {
+		// SendProbe sends a probe with the given TTL, or hop limit for IPv6
+		SendProbe(ttl int) error
+		// ReceiveProbe waits for the response to the last probe sent, the
+		// IP of the response is empty if none was received within the timeout
+		ReceiveProbe(timeout time.Duration) (*ProbeResponse, error)
+	}
+
+	// ProbeResponse encapsulates the response to a probe
+	ProbeResponse struct {
+		IP   net.IP
+		Port uint16
+		// ICMPType is only set for TCP over IPv4
+		ICMPType layers.ICMPv4TypeCode
+		// Time is when the response was received
+		Time time.Time
+	}
+
+	// Hop encapsulates information about a single
+	// hop of a traceroute
+	Hop struct {
+		IP   net.IP
+		Port uint16
+		// ICMPType is only set for TCP over IPv4
+		ICMPType layers.ICMPv4TypeCode
+		RTT      time.Duration
+		IsDest   bool
+	}
+
+	// Params are the parameters of a traceroute
+	Params struct {
+		Target  net.IP
+		MinTTL  uint8
+		MaxTTL  uint8
+		Timeout time.Duration // timeout for each hop
+	}
+)
+
+// TracePath discovers the hops of the path taken by the flow of the
+// prober, sending a probe for each TTL and waiting for its response
+// before sending the next one, until the target is reached
+func TracePath(prober Prober, params Params) ([]*Hop, error) {
+	hops := make([]*Hop, 0, int(params.MaxTTL)-int(params.MinTTL)+1)
+
+	for ttl := int(params.MinTTL); ttl <= int(params.MaxTTL); ttl++ {
+		hop, err := sendAndReceive(prober, params, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run traceroute: %w", err)
+		}
+		hops = append(hops, hop)
+		log.Tracef("Discovered hop: %+v", hop)
+		// if we've reached our destination,
+		// we're done
+		if hop.IsDest {
+			break
+		}
+	}
+
+	return hops, nil
+}
+
+func sendAndReceive(prober Prober, params Params, ttl int) (*Hop, error) {
+	if err := prober.SendProbe(ttl); err != nil {
+		return nil, fmt.Errorf("failed to send probe with TTL %d: %w", ttl, err)
+	}
+	// the RTT is measured from when the probe was handed to the
+	// kernel, building the packet isn't part of it and responses
+	// are timestamped as soon as they are read
+	start := time.Now()
+
+	resp, err := prober.ReceiveProbe(params.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for packets: %w", err)
+	}
+
+	hop := &Hop{
+		IP:       resp.IP,
+		Port:     resp.Port,
+		ICMPType: resp.ICMPType,
+		IsDest:   resp.IP.Equal(params.Target),
+	}
+	if !resp.IP.Equal(net.IP{}) {
+		hop.RTT = resp.Time.Sub(start)
+	}
+	return hop, nil
+}
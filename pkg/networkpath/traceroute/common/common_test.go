@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package common
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProber answers the probe of each TTL with the given hop IP
+type fakeProber struct {
+	hops    map[int]net.IP
+	sendErr error
+	sent    []int
+}
+
+func (p *fakeProber) SendProbe(ttl int) error {
+	p.sent = append(p.sent, ttl)
+	return p.sendErr
+}
+
+func (p *fakeProber) ReceiveProbe(_ time.Duration) (*ProbeResponse, error) {
+	ip, ok := p.hops[p.sent[len(p.sent)-1]]
+	if !ok {
+		return &ProbeResponse{IP: net.IP{}}, nil
+	}
+	return &ProbeResponse{IP: ip, Port: 443, Time: time.Now().Add(time.Millisecond)}, nil
+}
+
+func TestTracePath(t *testing.T) {
+	target := net.ParseIP("10.0.0.3")
+	prober := &fakeProber{
+		hops: map[int]net.IP{
+			1: net.ParseIP("10.0.0.1"),
+			3: target,
+		},
+	}
+
+	hops, err := TracePath(prober, Params{Target: target, MinTTL: 1, MaxTTL: 30, Timeout: time.Second})
+	require.NoError(t, err)
+
+	// probing stops once the target is reached
+	assert.Equal(t, []int{1, 2, 3}, prober.sent)
+	require.Len(t, hops, 3)
+	assert.Equal(t, net.ParseIP("10.0.0.1"), hops[0].IP)
+	assert.Positive(t, hops[0].RTT)
+	assert.False(t, hops[0].IsDest)
+	// hops that didn't respond have an empty IP and no RTT
+	assert.Equal(t, net.IP{}, hops[1].IP)
+	assert.Zero(t, hops[1].RTT)
+	assert.True(t, hops[2].IsDest)
+	assert.Equal(t, uint16(443), hops[2].Port)
+}
+
+func TestTracePathMaxTTL(t *testing.T) {
+	prober := &fakeProber{}
+
+	hops, err := TracePath(prober, Params{Target: net.ParseIP("10.0.0.3"), MinTTL: 2, MaxTTL: 4, Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, prober.sent)
+	assert.Len(t, hops, 3)
+}
+
+func TestTracePathError(t *testing.T) {
+	prober := &fakeProber{sendErr: errors.New("network is unreachable")}
+
+	_, err := TracePath(prober, Params{Target: net.ParseIP("10.0.0.3"), MinTTL: 1, MaxTTL: 4, Timeout: time.Second})
+	assert.EqualError(t, err, "failed to run traceroute: failed to send probe with TTL 1: network is unreachable")
+}
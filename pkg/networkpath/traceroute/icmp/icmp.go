@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package icmp adds an ICMP echo traceroute implementation to the agent
+package icmp
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"golang.org/x/net/icmp"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+)
+
+type (
+	// ICMP encapsulates the data needed to run
+	// an ICMP echo traceroute, over IPv4 or IPv6
+	// depending on the target address
+	ICMP struct {
+		Target   net.IP
+		srcIP    net.IP // calculated internally
+		id       uint16 // calculated internally
+		NumPaths uint16
+		MinTTL   uint8
+		MaxTTL   uint8
+		Delay    time.Duration // delay between sending packets (not applicable if we go the serial send/receive route)
+		Timeout  time.Duration // timeout for each hop
+	}
+
+	// Results encapsulates a response from the ICMP
+	// traceroute
+	Results struct {
+		Source net.IP
+		Target net.IP
+		Hops   []*Hop
+	}
+
+	// Hop encapsulates information about a single
+	// hop in an ICMP traceroute
+	Hop = common.Hop
+
+	// echoProber sends the echo request probes of a
+	// single flow, over IPv4 or IPv6
+	echoProber struct {
+		conn   *icmp.PacketConn
+		family *ipFamily
+		target net.IP
+		id     uint16
+		seqNum uint16
+	}
+)
+
+// TracerouteSequential runs a traceroute sequentially where an echo
+// request is sent and we wait for a response before sending the next one
+func (t *ICMP) TracerouteSequential() (*Results, error) {
+	paths, err := t.traceroute(1)
+	if err != nil {
+		return nil, err
+	}
+	return paths[0], nil
+}
+
+// TracerouteMultipath runs NumPaths sequential traceroutes, each with a
+// different echo identifier. Only routers that include the identifier in
+// their ECMP hash will spread them over different paths.
+func (t *ICMP) TracerouteMultipath() ([]*Results, error) {
+	return t.traceroute(max(int(t.NumPaths), 1))
+}
+
+func (t *ICMP) traceroute(numPaths int) ([]*Results, error) {
+	srcIP, err := localAddrForHost(t.Target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local address for target: %w", err)
+	}
+	t.srcIP = srcIP
+
+	// A raw ICMP socket receives echo replies as well as the
+	// errors sent by the routers along the path
+	conn, err := icmp.ListenPacket(t.family().network, srcIP.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ICMP listener: %w", err)
+	}
+	defer conn.Close()
+
+	// flows are identified by consecutive echo identifiers
+	baseID := uint16(rand.Intn(0xffff - numPaths))
+
+	paths := make([]*Results, 0, numPaths)
+	for p := 0; p < numPaths; p++ {
+		t.id = baseID + uint16(p)
+		hops, err := t.tracePath(conn)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, &Results{
+			Source: t.srcIP,
+			Target: t.Target,
+			Hops:   hops,
+		})
+	}
+
+	return paths, nil
+}
+
+// tracePath discovers the hops of the path taken by the current flow
+func (t *ICMP) tracePath(conn *icmp.PacketConn) ([]*Hop, error) {
+	prober := &echoProber{
+		conn:   conn,
+		family: t.family(),
+		target: t.Target,
+		id:     t.id,
+		// sequence numbers only need to be unique within a run
+		seqNum: uint16(rand.Intn(0xffff)),
+	}
+	return common.TracePath(prober, common.Params{
+		Target:  t.Target,
+		MinTTL:  t.MinTTL,
+		MaxTTL:  t.MaxTTL,
+		Timeout: t.Timeout,
+	})
+}
+
+// SendProbe sends an echo request with the given TTL and the next sequence number
+func (p *echoProber) SendProbe(ttl int) error {
+	if err := p.family.setTTL(p.conn, ttl); err != nil {
+		return fmt.Errorf("failed to set TTL: %w", err)
+	}
+
+	p.seqNum++
+	packet, err := createEchoRequest(p.family, p.id, p.seqNum)
+	if err != nil {
+		return fmt.Errorf("failed to create echo request: %w", err)
+	}
+
+	_, err = p.conn.WriteTo(packet, &net.IPAddr{IP: p.target})
+	return err
+}
+
+// ReceiveProbe waits for the echo reply or the ICMP error answering the last echo request
+func (p *echoProber) ReceiveProbe(timeout time.Duration) (*common.ProbeResponse, error) {
+	hopIP, end, err := listenPackets(p.conn, p.family, timeout, p.target, p.id, p.seqNum)
+	if err != nil {
+		return nil, err
+	}
+	return &common.ProbeResponse{IP: hopIP, Time: end}, nil
+}
+
+func (t *ICMP) family() *ipFamily {
+	if t.Target.To4() != nil {
+		return ipv4Family
+	}
+	return ipv6Family
+}
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package icmp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	// IPProtoICMP is the ICMP protocol number
+	IPProtoICMP = 1
+	// IPProtoICMPv6 is the ICMPv6 protocol number
+	IPProtoICMPv6 = 58
+
+	// echoHeaderLen is the length of the ICMP echo header
+	// quoted in ICMP errors
+	echoHeaderLen = 8
+)
+
+type (
+	// ipFamily holds what differs between ICMP
+	// and ICMPv6 for the traceroute
+	ipFamily struct {
+		network     string
+		proto       int
+		echoRequest icmp.Type
+		echoReply   icmp.Type
+		setTTL      func(conn *icmp.PacketConn, ttl int) error
+	}
+
+	// icmpResponse encapsulates the data from
+	// an ICMP response packet needed for matching
+	icmpResponse struct {
+		SrcIP      net.IP
+		Type       icmp.Type
+		InnerDstIP net.IP
+		ID         uint16
+		Seq        uint16
+	}
+
+	packetConnWrapper interface {
+		SetReadDeadline(t time.Time) error
+		ReadFrom(b []byte) (int, net.Addr, error)
+	}
+)
+
+var (
+	ipv4Family = &ipFamily{
+		network:     "ip4:icmp",
+		proto:       IPProtoICMP,
+		echoRequest: ipv4.ICMPTypeEcho,
+		echoReply:   ipv4.ICMPTypeEchoReply,
+		setTTL: func(conn *icmp.PacketConn, ttl int) error {
+			return conn.IPv4PacketConn().SetTTL(ttl)
+		},
+	}
+	ipv6Family = &ipFamily{
+		network:     "ip6:ipv6-icmp",
+		proto:       IPProtoICMPv6,
+		echoRequest: ipv6.ICMPTypeEchoRequest,
+		echoReply:   ipv6.ICMPTypeEchoReply,
+		setTTL: func(conn *icmp.PacketConn, ttl int) error {
+			return conn.IPv6PacketConn().SetHopLimit(ttl)
+		},
+	}
+)
+
+func localAddrForHost(destIP net.IP) (net.IP, error) {
+	// this is a quick way to get the local address for connecting to the host
+	// using UDP as the network type to avoid actually creating a connection to
+	// the host, the port doesn't matter since no packet is sent
+	conn, err := net.Dial("udp", net.JoinHostPort(destIP.String(), "33434"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	localAddr := conn.LocalAddr()
+
+	localUDPAddr, ok := localAddr.(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("invalid address type for %s: want %T, got %T", localAddr, localUDPAddr, localAddr)
+	}
+
+	return localUDPAddr.IP, nil
+}
+
+// echoPayload is the data of echo requests, followed by the checksum balance
+const echoPayload = "datadog-agent-traceroute"
+
+// createEchoRequest creates an ICMP echo request, the checksum is
+// computed here for ICMP and by the kernel for ICMPv6.
+//
+// Like Paris traceroute, the checksum is the same for all the sequence
+// numbers of a flow, since routers may include it in their ECMP hash: the
+// payload ends with the complement of the sequence number, and the one's
+// complement sum of a word and its complement is always 0xffff.
+func createEchoRequest(family *ipFamily, id uint16, seqNum uint16) ([]byte, error) {
+	data := make([]byte, len(echoPayload)+2)
+	copy(data, echoPayload)
+	binary.BigEndian.PutUint16(data[len(echoPayload):], ^seqNum)
+
+	msg := icmp.Message{
+		Type: family.echoRequest,
+		Code: 0,
+		Body: &icmp.Echo{
+			ID:   int(id),
+			Seq:  int(seqNum),
+			Data: data,
+		},
+	}
+
+	return msg.Marshal(nil)
+}
+
+// listenPackets reads packets from the connection until a response matching
+// the echo request is received. If no matching packet is received within the
+// timeout, a blank response is returned.
+func listenPackets(conn packetConnWrapper, family *ipFamily, timeout time.Duration, target net.IP, id uint16, seqNum uint16) (net.IP, time.Time, error) {
+	buf := make([]byte, 1500)
+	deadline := time.Now().Add(timeout)
+	for {
+		now := time.Now()
+		if now.After(deadline) {
+			return net.IP{}, time.Time{}, nil
+		}
+		readDeadline := now.Add(time.Millisecond * 100)
+		if readDeadline.After(deadline) {
+			readDeadline = deadline
+		}
+		err := conn.SetReadDeadline(readDeadline)
+		if err != nil {
+			return net.IP{}, time.Time{}, fmt.Errorf("failed to read: %w", err)
+		}
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if nerr, ok := err.(*net.OpError); ok {
+				if nerr.Timeout() {
+					continue
+				}
+			}
+			return net.IP{}, time.Time{}, err
+		}
+		// once we have a packet, take a timestamp to know when
+		// the response was received, if it matches, we will
+		// return this timestamp
+		received := time.Now()
+
+		ipAddr, ok := addr.(*net.IPAddr)
+		if !ok {
+			continue
+		}
+		resp, err := parseICMP(family, ipAddr.IP, buf[:n])
+		if err != nil {
+			continue
+		}
+		if icmpMatch(target, id, seqNum, resp) {
+			return resp.SrcIP, received, nil
+		}
+	}
+}
+
+// parseICMP parses an ICMP message received from srcIP, it returns the
+// fields of the echo request it answers to
+func parseICMP(family *ipFamily, srcIP net.IP, payload []byte) (*icmpResponse, error) {
+	msg, err := icmp.ParseMessage(family.proto, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ICMP message: %w", err)
+	}
+
+	resp := &icmpResponse{
+		SrcIP: srcIP,
+		Type:  msg.Type,
+	}
+
+	var quoted []byte
+	switch body := msg.Body.(type) {
+	case *icmp.Echo:
+		if msg.Type != family.echoReply {
+			return nil, fmt.Errorf("unexpected ICMP echo message type: %v", msg.Type)
+		}
+		// echo replies come from the target itself
+		resp.InnerDstIP = srcIP
+		resp.ID = uint16(body.ID)
+		resp.Seq = uint16(body.Seq)
+		return resp, nil
+	case *icmp.TimeExceeded:
+		quoted = body.Data
+	case *icmp.DstUnreach:
+		quoted = body.Data
+	default:
+		return nil, fmt.Errorf("unsupported ICMP message type: %v", msg.Type)
+	}
+
+	// errors quote the IP header and at least 8 bytes of the echo request
+	var echo []byte
+	if family.proto == IPProtoICMP {
+		header, err := ipv4.ParseHeader(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse inner IP header: %w", err)
+		}
+		resp.InnerDstIP = header.Dst
+		echo = quoted[header.Len:]
+	} else {
+		header, err := ipv6.ParseHeader(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse inner IPv6 header: %w", err)
+		}
+		resp.InnerDstIP = header.Dst
+		echo = quoted[ipv6.HeaderLen:]
+	}
+	if len(echo) < echoHeaderLen {
+		return nil, fmt.Errorf("inner ICMP payload is too short: %d bytes", len(echo))
+	}
+	if echo[0] != icmpTypeByte(family.echoRequest) {
+		return nil, fmt.Errorf("inner ICMP message is not an echo request: %d", echo[0])
+	}
+	resp.ID = binary.BigEndian.Uint16(echo[4:6])
+	resp.Seq = binary.BigEndian.Uint16(echo[6:8])
+
+	return resp, nil
+}
+
+func icmpMatch(target net.IP, id uint16, seqNum uint16, resp *icmpResponse) bool {
+	return target.Equal(resp.InnerDstIP) &&
+		id == resp.ID &&
+		seqNum == resp.Seq
+}
+
+func icmpTypeByte(typ icmp.Type) byte {
+	switch t := typ.(type) {
+	case ipv4.ICMPType:
+		return byte(t)
+	case ipv6.ICMPType:
+		return byte(t)
+	}
+	return 0
+}
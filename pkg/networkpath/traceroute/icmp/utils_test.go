@@ -0,0 +1,252 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package icmp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+var (
+	hopIP    = net.ParseIP("10.0.0.1")
+	localIP  = net.ParseIP("10.0.0.2")
+	targetIP = net.ParseIP("10.0.1.2")
+
+	hopIPv6    = net.ParseIP("fd00::1")
+	localIPv6  = net.ParseIP("fd00::2")
+	targetIPv6 = net.ParseIP("fd00:1::2")
+)
+
+func Test_parseICMP(t *testing.T) {
+	echoRequest, err := createEchoRequest(ipv4Family, 1234, 42)
+	require.NoError(t, err)
+	echoRequestV6, err := createEchoRequest(ipv6Family, 1234, 42)
+	require.NoError(t, err)
+
+	tt := []struct {
+		description string
+		family      *ipFamily
+		srcIP       net.IP
+		inPayload   []byte
+		expected    *icmpResponse
+		errMsg      string
+	}{
+		{
+			description: "empty payload should return an error",
+			family:      ipv4Family,
+			srcIP:       hopIP,
+			inPayload:   []byte{},
+			errMsg:      "failed to parse ICMP message",
+		},
+		{
+			description: "echo request should return an error",
+			family:      ipv4Family,
+			srcIP:       targetIP,
+			inPayload:   echoRequest,
+			errMsg:      "unexpected ICMP echo message type",
+		},
+		{
+			description: "echo reply should create icmpResponse",
+			family:      ipv4Family,
+			srcIP:       targetIP,
+			inPayload:   createMockEchoReply(t, ipv4.ICMPTypeEchoReply, 1234, 42),
+			expected: &icmpResponse{
+				SrcIP:      targetIP,
+				InnerDstIP: targetIP,
+				ID:         1234,
+				Seq:        42,
+			},
+		},
+		{
+			description: "time exceeded should create icmpResponse",
+			family:      ipv4Family,
+			srcIP:       hopIP,
+			inPayload: createMockICMPError(t, ipv4.ICMPTypeTimeExceeded, &icmp.TimeExceeded{
+				Data: append(createMockIPv4Header(t, localIP, targetIP), echoRequest[:echoHeaderLen]...),
+			}),
+			expected: &icmpResponse{
+				SrcIP:      hopIP,
+				InnerDstIP: targetIP,
+				ID:         1234,
+				Seq:        42,
+			},
+		},
+		{
+			description: "time exceeded with truncated echo should return an error",
+			family:      ipv4Family,
+			srcIP:       hopIP,
+			inPayload: createMockICMPError(t, ipv4.ICMPTypeTimeExceeded, &icmp.TimeExceeded{
+				Data: append(createMockIPv4Header(t, localIP, targetIP), echoRequest[:4]...),
+			}),
+			errMsg: "inner ICMP payload is too short",
+		},
+		{
+			description: "echo reply over IPv6 should create icmpResponse",
+			family:      ipv6Family,
+			srcIP:       targetIPv6,
+			inPayload:   createMockEchoReply(t, ipv6.ICMPTypeEchoReply, 1234, 42),
+			expected: &icmpResponse{
+				SrcIP:      targetIPv6,
+				InnerDstIP: targetIPv6,
+				ID:         1234,
+				Seq:        42,
+			},
+		},
+		{
+			description: "time exceeded over IPv6 should create icmpResponse",
+			family:      ipv6Family,
+			srcIP:       hopIPv6,
+			inPayload: createMockICMPError(t, ipv6.ICMPTypeTimeExceeded, &icmp.TimeExceeded{
+				Data: append(createMockIPv6Header(localIPv6, targetIPv6), echoRequestV6...),
+			}),
+			expected: &icmpResponse{
+				SrcIP:      hopIPv6,
+				InnerDstIP: targetIPv6,
+				ID:         1234,
+				Seq:        42,
+			},
+		},
+		{
+			description: "destination unreachable over IPv6 quoting an ICMPv4 echo should return an error",
+			family:      ipv6Family,
+			srcIP:       hopIPv6,
+			inPayload: createMockICMPError(t, ipv6.ICMPTypeDestinationUnreachable, &icmp.DstUnreach{
+				Data: append(createMockIPv6Header(localIPv6, targetIPv6), echoRequest...),
+			}),
+			errMsg: "inner ICMP message is not an echo request",
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := parseICMP(test.family, test.srcIP, test.inPayload)
+			if test.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errMsg)
+				assert.Nil(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, actual)
+			assert.Truef(t, test.expected.SrcIP.Equal(actual.SrcIP), "mismatch source IPs: expected %s, got %s", test.expected.SrcIP, actual.SrcIP)
+			assert.Truef(t, test.expected.InnerDstIP.Equal(actual.InnerDstIP), "mismatch inner dest IPs: expected %s, got %s", test.expected.InnerDstIP, actual.InnerDstIP)
+			assert.Equal(t, test.expected.ID, actual.ID)
+			assert.Equal(t, test.expected.Seq, actual.Seq)
+		})
+	}
+}
+
+func Test_createEchoRequestChecksum(t *testing.T) {
+	for _, family := range []*ipFamily{ipv4Family, ipv6Family} {
+		// the kernel computes the ICMPv6 checksum, the pseudo-header
+		// is the same for all the probes of a flow so it is left out
+		var checksums []uint16
+		for seqNum := uint16(0xfffe); seqNum != 3; seqNum++ {
+			packet, err := createEchoRequest(family, 1234, seqNum)
+			require.NoError(t, err)
+			packet[2], packet[3] = 0, 0
+			checksums = append(checksums, checksum(packet))
+		}
+		for _, c := range checksums {
+			assert.Equal(t, checksums[0], c, "%s", family.network)
+		}
+
+		// flows still have different checksums
+		packet, err := createEchoRequest(family, 1235, 0)
+		require.NoError(t, err)
+		packet[2], packet[3] = 0, 0
+		assert.NotEqual(t, checksums[0], checksum(packet), "%s", family.network)
+	}
+}
+
+// checksum computes the internet checksum of a packet
+func checksum(packet []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(packet); i += 2 {
+		sum += uint32(packet[i])<<8 | uint32(packet[i+1])
+	}
+	if len(packet)%2 == 1 {
+		sum += uint32(packet[len(packet)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+func Test_icmpMatch(t *testing.T) {
+	resp := &icmpResponse{
+		SrcIP:      hopIP,
+		InnerDstIP: targetIP,
+		ID:         1234,
+		Seq:        42,
+	}
+
+	assert.True(t, icmpMatch(targetIP, 1234, 42, resp))
+	assert.False(t, icmpMatch(localIP, 1234, 42, resp))
+	assert.False(t, icmpMatch(targetIP, 4321, 42, resp))
+	assert.False(t, icmpMatch(targetIP, 1234, 43, resp))
+}
+
+func Test_family(t *testing.T) {
+	assert.Equal(t, ipv4Family, (&ICMP{Target: targetIP}).family())
+	assert.Equal(t, ipv6Family, (&ICMP{Target: targetIPv6}).family())
+}
+
+func createMockEchoReply(t *testing.T, typ icmp.Type, id int, seq int) []byte {
+	msg := icmp.Message{
+		Type: typ,
+		Body: &icmp.Echo{
+			ID:   id,
+			Seq:  seq,
+			Data: []byte("datadog-agent-traceroute"),
+		},
+	}
+	b, err := msg.Marshal(nil)
+	require.NoError(t, err)
+	return b
+}
+
+func createMockICMPError(t *testing.T, typ icmp.Type, body icmp.MessageBody) []byte {
+	msg := icmp.Message{
+		Type: typ,
+		Body: body,
+	}
+	b, err := msg.Marshal(nil)
+	require.NoError(t, err)
+	return b
+}
+
+func createMockIPv4Header(t *testing.T, srcIP, dstIP net.IP) []byte {
+	header := &ipv4.Header{
+		Version:  ipv4.Version,
+		Len:      ipv4.HeaderLen,
+		TotalLen: ipv4.HeaderLen + echoHeaderLen,
+		TTL:      1,
+		Protocol: IPProtoICMP,
+		Src:      srcIP,
+		Dst:      dstIP,
+	}
+	b, err := header.Marshal()
+	require.NoError(t, err)
+	return b
+}
+
+func createMockIPv6Header(srcIP, dstIP net.IP) []byte {
+	b := make([]byte, ipv6.HeaderLen)
+	b[0] = ipv6.Version << 4
+	b[6] = IPProtoICMPv6 // next header
+	b[7] = 1             // hop limit
+	copy(b[8:24], srcIP.To16())
+	copy(b[24:40], dstIP.To16())
+	return b
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package traceroute
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+const (
+	// MaxNumPaths is the maximum number of flows
+	// probed by a single multipath traceroute
+	MaxNumPaths = 16
+)
+
+// buildMultipath merges the hops discovered by each flow into a DAG.
+// Nodes are keyed by TTL and IP address so that a router seen at
+// different TTLs by different flows shows up as distinct nodes, hops
+// that didn't respond at a given TTL are merged into a single node.
+func buildMultipath(flows [][]payload.NetworkPathHop) *payload.NetworkPathMultipath {
+	type nodeStats struct {
+		node     payload.NetworkPathNode
+		rttSum   float64
+		rttCount int
+	}
+
+	nodes := make(map[string]*nodeStats)
+	edges := make(map[[2]string]int)
+	paths := make(map[string]struct{})
+
+	for _, hops := range flows {
+		if len(hops) == 0 {
+			continue
+		}
+		ips := make([]string, 0, len(hops))
+		prevID := ""
+		for _, hop := range hops {
+			id := nodeID(hop.TTL, hop.IPAddress)
+			stats, ok := nodes[id]
+			if !ok {
+				stats = &nodeStats{
+					node: payload.NetworkPathNode{
+						ID:        id,
+						TTL:       hop.TTL,
+						IPAddress: hop.IPAddress,
+						Hostname:  hop.Hostname,
+						Reachable: hop.Reachable,
+					},
+				}
+				nodes[id] = stats
+			}
+			stats.node.FlowCount++
+			if hop.Reachable {
+				stats.rttSum += hop.RTT
+				stats.rttCount++
+			}
+
+			if prevID != "" {
+				edges[[2]string{prevID, id}]++
+			}
+			prevID = id
+			ips = append(ips, hop.IPAddress)
+		}
+		paths[strings.Join(ips, ",")] = struct{}{}
+	}
+
+	multipath := &payload.NetworkPathMultipath{
+		PathsProbed:   len(flows),
+		DistinctPaths: len(paths),
+		Nodes:         make([]payload.NetworkPathNode, 0, len(nodes)),
+		Edges:         make([]payload.NetworkPathEdge, 0, len(edges)),
+	}
+
+	width := make(map[int]int)
+	for _, stats := range nodes {
+		if stats.rttCount > 0 {
+			stats.node.RTT = stats.rttSum / float64(stats.rttCount)
+		}
+		multipath.Nodes = append(multipath.Nodes, stats.node)
+		width[stats.node.TTL]++
+		if width[stats.node.TTL] > multipath.MaxWidth {
+			multipath.MaxWidth = width[stats.node.TTL]
+		}
+	}
+	sort.Slice(multipath.Nodes, func(i, j int) bool {
+		if multipath.Nodes[i].TTL != multipath.Nodes[j].TTL {
+			return multipath.Nodes[i].TTL < multipath.Nodes[j].TTL
+		}
+		return multipath.Nodes[i].IPAddress < multipath.Nodes[j].IPAddress
+	})
+
+	for edge, count := range edges {
+		multipath.Edges = append(multipath.Edges, payload.NetworkPathEdge{
+			From:      edge[0],
+			To:        edge[1],
+			FlowCount: count,
+		})
+	}
+	sort.Slice(multipath.Edges, func(i, j int) bool {
+		fromI, fromJ := nodes[multipath.Edges[i].From].node, nodes[multipath.Edges[j].From].node
+		if fromI.TTL != fromJ.TTL {
+			return fromI.TTL < fromJ.TTL
+		}
+		if multipath.Edges[i].From != multipath.Edges[j].From {
+			return multipath.Edges[i].From < multipath.Edges[j].From
+		}
+		return multipath.Edges[i].To < multipath.Edges[j].To
+	})
+
+	return multipath
+}
+
+func nodeID(ttl int, ipAddress string) string {
+	return fmt.Sprintf("%d/%s", ttl, ipAddress)
+}
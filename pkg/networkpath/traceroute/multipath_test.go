@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package traceroute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+func TestBuildMultipath(t *testing.T) {
+	hop := func(ttl int, ip string, rtt float64) payload.NetworkPathHop {
+		return payload.NetworkPathHop{TTL: ttl, IPAddress: ip, RTT: rtt, Reachable: true}
+	}
+	unknownHop := func(ttl int) payload.NetworkPathHop {
+		return payload.NetworkPathHop{TTL: ttl, IPAddress: "unknown_hop_2"}
+	}
+
+	flows := [][]payload.NetworkPathHop{
+		{hop(1, "10.0.0.1", 1), hop(2, "10.0.1.1", 2), hop(3, "10.0.2.2", 3)},
+		{hop(1, "10.0.0.1", 3), hop(2, "10.0.1.2", 2), hop(3, "10.0.2.2", 5)},
+		{hop(1, "10.0.0.1", 2), hop(2, "10.0.1.1", 4), hop(3, "10.0.2.2", 4)},
+		{hop(1, "10.0.0.1", 2), unknownHop(2), hop(3, "10.0.2.2", 4)},
+	}
+
+	expected := &payload.NetworkPathMultipath{
+		PathsProbed:   4,
+		DistinctPaths: 3,
+		MaxWidth:      3,
+		Nodes: []payload.NetworkPathNode{
+			{ID: "1/10.0.0.1", TTL: 1, IPAddress: "10.0.0.1", RTT: 2, Reachable: true, FlowCount: 4},
+			{ID: "2/10.0.1.1", TTL: 2, IPAddress: "10.0.1.1", RTT: 3, Reachable: true, FlowCount: 2},
+			{ID: "2/10.0.1.2", TTL: 2, IPAddress: "10.0.1.2", RTT: 2, Reachable: true, FlowCount: 1},
+			{ID: "2/unknown_hop_2", TTL: 2, IPAddress: "unknown_hop_2", FlowCount: 1},
+			{ID: "3/10.0.2.2", TTL: 3, IPAddress: "10.0.2.2", RTT: 4, Reachable: true, FlowCount: 4},
+		},
+		Edges: []payload.NetworkPathEdge{
+			{From: "1/10.0.0.1", To: "2/10.0.1.1", FlowCount: 2},
+			{From: "1/10.0.0.1", To: "2/10.0.1.2", FlowCount: 1},
+			{From: "1/10.0.0.1", To: "2/unknown_hop_2", FlowCount: 1},
+			{From: "2/10.0.1.1", To: "3/10.0.2.2", FlowCount: 2},
+			{From: "2/10.0.1.2", To: "3/10.0.2.2", FlowCount: 1},
+			{From: "2/unknown_hop_2", To: "3/10.0.2.2", FlowCount: 1},
+		},
+	}
+
+	assert.Equal(t, expected, buildMultipath(flows))
+}
+
+func TestBuildMultipathEdgesSortedByTTL(t *testing.T) {
+	var hops []payload.NetworkPathHop
+	for ttl := 1; ttl <= 11; ttl++ {
+		hops = append(hops, payload.NetworkPathHop{TTL: ttl, IPAddress: "10.0.0.1", Reachable: true})
+	}
+
+	multipath := buildMultipath([][]payload.NetworkPathHop{hops, hops})
+	assert.Equal(t, 1, multipath.DistinctPaths)
+	assert.Equal(t, 1, multipath.MaxWidth)
+	assert.Len(t, multipath.Edges, 10)
+	for i, edge := range multipath.Edges {
+		assert.Equal(t, nodeID(i+1, "10.0.0.1"), edge.From)
+		assert.Equal(t, 2, edge.FlowCount)
+	}
+}
+
+func TestSetPathHops(t *testing.T) {
+	flow := []payload.NetworkPathHop{{TTL: 1, IPAddress: "10.0.0.1", Reachable: true}}
+
+	path := payload.NetworkPath{}
+	setPathHops(&path, nil)
+	assert.Nil(t, path.Hops)
+	assert.Nil(t, path.Multipath)
+
+	setPathHops(&path, [][]payload.NetworkPathHop{flow})
+	assert.Equal(t, flow, path.Hops)
+	assert.Nil(t, path.Multipath)
+
+	setPathHops(&path, [][]payload.NetworkPathHop{flow, flow})
+	assert.Equal(t, flow, path.Hops)
+	if assert.NotNil(t, path.Multipath) {
+		assert.Equal(t, 2, path.Multipath.PathsProbed)
+		assert.Equal(t, 1, path.Multipath.DistinctPaths)
+	}
+}
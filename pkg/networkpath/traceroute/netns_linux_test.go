@@ -0,0 +1,228 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux && test
+
+package traceroute
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netns"
+
+	"github.com/DataDog/datadog-agent/pkg/network/testutil"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/icmp"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/tcp"
+	"github.com/DataDog/datadog-agent/pkg/util/kernel"
+)
+
+var (
+	netnsRouterIPv4 = net.ParseIP("10.200.1.1")
+	netnsServerIPv4 = net.ParseIP("10.200.2.2")
+	netnsRouterIPv6 = net.ParseIP("fd00:1::1")
+	netnsServerIPv6 = net.ParseIP("fd00:2::2")
+)
+
+const (
+	netnsMACC0 = "02:00:00:00:01:02"
+	netnsMACR0 = "02:00:00:00:01:01"
+	netnsMACR1 = "02:00:00:00:02:01"
+	netnsMACS0 = "02:00:00:00:02:02"
+)
+
+// setupRoutedNetNS creates a client and a server network namespace, connected
+// through a router namespace with veth pairs, and returns the client namespace:
+//
+//	client (10.200.1.2, fd00:1::2) <-> router <-> (10.200.2.2, fd00:2::2) server
+func setupRoutedNetNS(t *testing.T) netns.NsHandle {
+	if os.Geteuid() != 0 {
+		t.Skip("creating network namespaces requires root")
+	}
+
+	suffix := rand.Intn(99999)
+	client := fmt.Sprintf("np-client-%d", suffix)
+	router := fmt.Sprintf("np-router-%d", suffix)
+	server := fmt.Sprintf("np-server-%d", suffix)
+	t.Cleanup(func() {
+		testutil.RunCommands(t, []string{
+			"ip netns del " + client,
+			"ip netns del " + router,
+			"ip netns del " + server,
+		}, true)
+	})
+
+	testutil.RunCommands(t, []string{
+		"ip netns add " + client,
+		"ip netns add " + router,
+		"ip netns add " + server,
+		fmt.Sprintf("ip -n %s link add r0 address %s type veth peer name c0 address %s netns %s", router, netnsMACR0, netnsMACC0, client),
+		fmt.Sprintf("ip -n %s link add r1 address %s type veth peer name s0 address %s netns %s", router, netnsMACR1, netnsMACS0, server),
+		fmt.Sprintf("ip -n %s addr add 10.200.1.2/24 dev c0", client),
+		fmt.Sprintf("ip -n %s addr add fd00:1::2/64 dev c0 nodad", client),
+		fmt.Sprintf("ip -n %s addr add 10.200.1.1/24 dev r0", router),
+		fmt.Sprintf("ip -n %s addr add fd00:1::1/64 dev r0 nodad", router),
+		fmt.Sprintf("ip -n %s addr add 10.200.2.1/24 dev r1", router),
+		fmt.Sprintf("ip -n %s addr add fd00:2::1/64 dev r1 nodad", router),
+		fmt.Sprintf("ip -n %s addr add 10.200.2.2/24 dev s0", server),
+		fmt.Sprintf("ip -n %s addr add fd00:2::2/64 dev s0 nodad", server),
+		fmt.Sprintf("ip -n %s link set lo up", client),
+		fmt.Sprintf("ip -n %s link set c0 up", client),
+		fmt.Sprintf("ip -n %s link set lo up", router),
+		fmt.Sprintf("ip -n %s link set r0 up", router),
+		fmt.Sprintf("ip -n %s link set r1 up", router),
+		fmt.Sprintf("ip -n %s link set lo up", server),
+		fmt.Sprintf("ip -n %s link set s0 up", server),
+		fmt.Sprintf("ip -n %s route add default via 10.200.1.1", client),
+		fmt.Sprintf("ip -n %s -6 route add default via fd00:1::1", client),
+		fmt.Sprintf("ip -n %s route add default via 10.200.2.1", server),
+		fmt.Sprintf("ip -n %s -6 route add default via fd00:2::1", server),
+		fmt.Sprintf("ip netns exec %s sysctl -w net.ipv4.ip_forward=1", router),
+		fmt.Sprintf("ip netns exec %s sysctl -w net.ipv6.conf.all.forwarding=1", router),
+		// the router must answer every expired probe
+		fmt.Sprintf("ip netns exec %s sysctl -w net.ipv4.icmp_ratelimit=0", router),
+		fmt.Sprintf("ip netns exec %s sysctl -w net.ipv6.icmp.ratelimit=0", router),
+		// neighbors are static, probes queued during neighbor discovery are
+		// answered after the next probe was sent and don't match it
+		fmt.Sprintf("ip -n %s neigh add 10.200.1.1 lladdr %s dev c0 nud permanent", client, netnsMACR0),
+		fmt.Sprintf("ip -n %s neigh add fd00:1::1 lladdr %s dev c0 nud permanent", client, netnsMACR0),
+		fmt.Sprintf("ip -n %s neigh add 10.200.1.2 lladdr %s dev r0 nud permanent", router, netnsMACC0),
+		fmt.Sprintf("ip -n %s neigh add fd00:1::2 lladdr %s dev r0 nud permanent", router, netnsMACC0),
+		fmt.Sprintf("ip -n %s neigh add 10.200.2.2 lladdr %s dev r1 nud permanent", router, netnsMACS0),
+		fmt.Sprintf("ip -n %s neigh add fd00:2::2 lladdr %s dev r1 nud permanent", router, netnsMACS0),
+		fmt.Sprintf("ip -n %s neigh add 10.200.2.1 lladdr %s dev s0 nud permanent", server, netnsMACR1),
+		fmt.Sprintf("ip -n %s neigh add fd00:2::1 lladdr %s dev s0 nud permanent", server, netnsMACR1),
+	}, false)
+
+	ns, err := netns.GetFromName(client)
+	require.NoError(t, err)
+	t.Cleanup(func() { ns.Close() })
+	return ns
+}
+
+// assertRoutedHops checks that the hops are the router then the server
+func assertRoutedHops(t *testing.T, hops []*common.Hop, router, server net.IP) {
+	require.Len(t, hops, 2)
+	assert.Equal(t, router.String(), hops[0].IP.String())
+	assert.False(t, hops[0].IsDest)
+	assert.Positive(t, hops[0].RTT)
+	assert.Equal(t, server.String(), hops[1].IP.String())
+	assert.True(t, hops[1].IsDest)
+	assert.Positive(t, hops[1].RTT)
+}
+
+func TestTracerouteNetNS(t *testing.T) {
+	clientNS := setupRoutedNetNS(t)
+
+	tests := []struct {
+		name   string
+		router net.IP
+		server net.IP
+		run    func(target net.IP) ([][]*common.Hop, error)
+	}{
+		{
+			name:   "tcpv4",
+			router: netnsRouterIPv4,
+			server: netnsServerIPv4,
+			run: func(target net.IP) ([][]*common.Hop, error) {
+				tr := &tcp.TCPv4{Target: target, DestPort: 443, NumPaths: 2, MinTTL: 1, MaxTTL: 5, Timeout: time.Second}
+				return tcpHops(tr.TracerouteMultipath())
+			},
+		},
+		{
+			name:   "tcpv6",
+			router: netnsRouterIPv6,
+			server: netnsServerIPv6,
+			run: func(target net.IP) ([][]*common.Hop, error) {
+				tr := &tcp.TCPv6{Target: target, DestPort: 443, NumPaths: 2, MinTTL: 1, MaxTTL: 5, Timeout: time.Second}
+				return tcpHops(tr.TracerouteMultipath())
+			},
+		},
+		{
+			name:   "icmpv4",
+			router: netnsRouterIPv4,
+			server: netnsServerIPv4,
+			run: func(target net.IP) ([][]*common.Hop, error) {
+				tr := &icmp.ICMP{Target: target, NumPaths: 2, MinTTL: 1, MaxTTL: 5, Timeout: time.Second}
+				return icmpHops(tr.TracerouteMultipath())
+			},
+		},
+		{
+			name:   "icmpv6",
+			router: netnsRouterIPv6,
+			server: netnsServerIPv6,
+			run: func(target net.IP) ([][]*common.Hop, error) {
+				tr := &icmp.ICMP{Target: target, NumPaths: 2, MinTTL: 1, MaxTTL: 5, Timeout: time.Second}
+				return icmpHops(tr.TracerouteMultipath())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths [][]*common.Hop
+			require.NoError(t, kernel.WithNS(clientNS, func() error {
+				var err error
+				paths, err = tt.run(tt.server)
+				return err
+			}))
+
+			require.Len(t, paths, 2)
+			for _, hops := range paths {
+				assertRoutedHops(t, hops, tt.router, tt.server)
+			}
+		})
+	}
+}
+
+func TestTracerouteNetNSUnreachable(t *testing.T) {
+	clientNS := setupRoutedNetNS(t)
+
+	// the router has no route to this network, the probes never reach a destination
+	var results *tcp.Results
+	require.NoError(t, kernel.WithNS(clientNS, func() error {
+		tr := &tcp.TCPv4{Target: net.ParseIP("10.200.3.3"), DestPort: 443, MinTTL: 1, MaxTTL: 2, Timeout: 500 * time.Millisecond}
+		var err error
+		results, err = tr.TracerouteSequential()
+		return err
+	}))
+
+	assert.Equal(t, "10.200.1.2", results.Source.String())
+	require.Len(t, results.Hops, 2)
+	for _, hop := range results.Hops {
+		// the router answers with ICMP errors
+		assert.Equal(t, netnsRouterIPv4.String(), hop.IP.String())
+		assert.False(t, hop.IsDest)
+	}
+}
+
+func tcpHops(results []*tcp.Results, err error) ([][]*common.Hop, error) {
+	if err != nil {
+		return nil, err
+	}
+	paths := make([][]*common.Hop, 0, len(results))
+	for _, res := range results {
+		paths = append(paths, res.Hops)
+	}
+	return paths, nil
+}
+
+func icmpHops(results []*icmp.Results, err error) ([][]*common.Hop, error) {
+	if err != nil {
+		return nil, err
+	}
+	paths := make([][]*common.Hop, 0, len(results))
+	for _, res := range results {
+		paths = append(paths, res.Hops)
+	}
+	return paths, nil
+}
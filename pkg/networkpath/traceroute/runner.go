@@ -17,6 +17,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/version"
 	"github.com/Datadog/dublin-traceroute/go/dublintraceroute/probes/probev4"
+	"github.com/Datadog/dublin-traceroute/go/dublintraceroute/probes/probev6"
 	"github.com/Datadog/dublin-traceroute/go/dublintraceroute/results"
 	"github.com/vishvananda/netns"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/icmp"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/tcp"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
@@ -102,17 +104,16 @@ func NewRunner(telemetryComp telemetryComponent.Component) (*Runner, error) {
 // complete implementation.
 func (r *Runner) RunTraceroute(ctx context.Context, cfg Config) (payload.NetworkPath, error) {
 	defer tracerouteRunnerTelemetry.runs.Inc()
-	dests, err := net.DefaultResolver.LookupIP(ctx, "ip4", cfg.DestHostname)
-	if err != nil || len(dests) == 0 {
+	dest, err := resolveDestination(ctx, cfg.DestHostname, cfg.IPVersion)
+	if err != nil {
 		tracerouteRunnerTelemetry.failedRuns.Inc()
-		return payload.NetworkPath{}, fmt.Errorf("cannot resolve %s: %v", cfg.DestHostname, err)
+		return payload.NetworkPath{}, err
 	}
 
-	//TODO: should we get smarter about IP address resolution?
-	// if it's a hostname, perhaps we could run multiple traces
-	// for each of the different IPs it resolves to up to a threshold?
-	// use first resolved IP for now
-	dest := dests[0]
+	if cfg.NumPaths > MaxNumPaths {
+		tracerouteRunnerTelemetry.failedRuns.Inc()
+		return payload.NetworkPath{}, fmt.Errorf("invalid number of paths %d, must be at most %d", cfg.NumPaths, MaxNumPaths)
+	}
 
 	maxTTL := cfg.MaxTTL
 	if maxTTL == 0 {
@@ -155,6 +156,13 @@ func (r *Runner) RunTraceroute(ctx context.Context, cfg Config) (payload.Network
 			tracerouteRunnerTelemetry.failedRuns.Inc()
 			return payload.NetworkPath{}, err
 		}
+	case payload.ProtocolICMP:
+		log.Tracef("Running ICMP traceroute for: %+v", cfg)
+		pathResult, err = r.runICMP(cfg, hname, dest, maxTTL, timeout)
+		if err != nil {
+			tracerouteRunnerTelemetry.failedRuns.Inc()
+			return payload.NetworkPath{}, err
+		}
 	default:
 		log.Errorf("Invalid protocol for: %+v", cfg)
 		tracerouteRunnerTelemetry.failedRuns.Inc()
@@ -164,20 +172,70 @@ func (r *Runner) RunTraceroute(ctx context.Context, cfg Config) (payload.Network
 	return pathResult, nil
 }
 
+// resolveDestination returns the address to run a traceroute to. Without
+// an IP version, IPv4 addresses are preferred over IPv6 ones.
+func resolveDestination(ctx context.Context, hostname string, ipVersion uint8) (net.IP, error) {
+	var network string
+	switch ipVersion {
+	case IPVersionAuto:
+		network = "ip"
+	case IPVersion4:
+		network = "ip4"
+	case IPVersion6:
+		network = "ip6"
+	default:
+		return nil, fmt.Errorf("invalid IP version: %d", ipVersion)
+	}
+
+	dests, err := net.DefaultResolver.LookupIP(ctx, network, hostname)
+	if err != nil || len(dests) == 0 {
+		return nil, fmt.Errorf("cannot resolve %s: %v", hostname, err)
+	}
+
+	//TODO: should we get smarter about IP address resolution?
+	// if it's a hostname, perhaps we could run multiple traces
+	// for each of the different IPs it resolves to up to a threshold?
+	// use first resolved IP for now
+	for _, dest := range dests {
+		if dest.To4() != nil {
+			return dest.To4(), nil
+		}
+	}
+	return dests[0], nil
+}
+
 func (r *Runner) runUDP(cfg Config, hname string, dest net.IP, maxTTL uint8, timeout time.Duration) (payload.NetworkPath, error) {
 	destPort, srcPort, useSourcePort := getPorts(cfg.DestPort)
 
-	dt := &probev4.UDPv4{
-		Target:     dest,
-		SrcPort:    srcPort,
-		DstPort:    destPort,
-		UseSrcPort: useSourcePort,
-		NumPaths:   uint16(DefaultNumPaths),
-		MinTTL:     uint8(DefaultMinTTL), // TODO: what's a good value?
-		MaxTTL:     maxTTL,
-		Delay:      time.Duration(DefaultDelay) * time.Millisecond, // TODO: what's a good value?
-		Timeout:    timeout,                                        // TODO: what's a good value?
-		BrokenNAT:  false,
+	var dt interface {
+		Traceroute() (*results.Results, error)
+	}
+	if dest.To4() != nil {
+		dt = &probev4.UDPv4{
+			Target:     dest,
+			SrcPort:    srcPort,
+			DstPort:    destPort,
+			UseSrcPort: useSourcePort,
+			NumPaths:   numPaths(cfg),
+			MinTTL:     uint8(DefaultMinTTL), // TODO: what's a good value?
+			MaxTTL:     maxTTL,
+			Delay:      time.Duration(DefaultDelay) * time.Millisecond, // TODO: what's a good value?
+			Timeout:    timeout,                                        // TODO: what's a good value?
+			BrokenNAT:  false,
+		}
+	} else {
+		dt = &probev6.UDPv6{
+			Target:      dest,
+			SrcPort:     srcPort,
+			DstPort:     destPort,
+			UseSrcPort:  useSourcePort,
+			NumPaths:    numPaths(cfg),
+			MinHopLimit: uint8(DefaultMinTTL),
+			MaxHopLimit: maxTTL,
+			Delay:       time.Duration(DefaultDelay) * time.Millisecond,
+			Timeout:     timeout,
+			BrokenNAT:   false,
+		}
 	}
 
 	results, err := dt.Traceroute()
@@ -200,31 +258,70 @@ func (r *Runner) runTCP(cfg Config, hname string, target net.IP, maxTTL uint8, t
 		destPort = 80 // TODO: is this the default we want?
 	}
 
-	tr := tcp.TCPv4{
+	var tr interface {
+		TracerouteMultipath() ([]*tcp.Results, error)
+	}
+	if target.To4() != nil {
+		tr = &tcp.TCPv4{
+			Target:   target,
+			DestPort: destPort,
+			NumPaths: numPaths(cfg),
+			MinTTL:   uint8(DefaultMinTTL),
+			MaxTTL:   maxTTL,
+			Delay:    time.Duration(DefaultDelay) * time.Millisecond,
+			Timeout:  timeout,
+		}
+	} else {
+		tr = &tcp.TCPv6{
+			Target:   target,
+			DestPort: destPort,
+			NumPaths: numPaths(cfg),
+			MinTTL:   uint8(DefaultMinTTL),
+			MaxTTL:   maxTTL,
+			Delay:    time.Duration(DefaultDelay) * time.Millisecond,
+			Timeout:  timeout,
+		}
+	}
+
+	results, err := tr.TracerouteMultipath()
+	if err != nil {
+		return payload.NetworkPath{}, err
+	}
+
+	pathResult, err := r.processTCPResults(results, hname, cfg.DestHostname, destPort, target)
+	if err != nil {
+		return payload.NetworkPath{}, err
+	}
+	log.Tracef("TCP Results: %+v", pathResult)
+
+	return pathResult, nil
+}
+
+func (r *Runner) runICMP(cfg Config, hname string, target net.IP, maxTTL uint8, timeout time.Duration) (payload.NetworkPath, error) {
+	tr := icmp.ICMP{
 		Target:   target,
-		DestPort: destPort,
-		NumPaths: 1,
+		NumPaths: numPaths(cfg),
 		MinTTL:   uint8(DefaultMinTTL),
 		MaxTTL:   maxTTL,
 		Delay:    time.Duration(DefaultDelay) * time.Millisecond,
 		Timeout:  timeout,
 	}
 
-	results, err := tr.TracerouteSequential()
+	results, err := tr.TracerouteMultipath()
 	if err != nil {
 		return payload.NetworkPath{}, err
 	}
 
-	pathResult, err := r.processTCPResults(results, hname, cfg.DestHostname, destPort, target)
+	pathResult, err := r.processICMPResults(results, hname, cfg.DestHostname, target)
 	if err != nil {
 		return payload.NetworkPath{}, err
 	}
-	log.Tracef("TCP Results: %+v", pathResult)
+	log.Tracef("ICMP Results: %+v", pathResult)
 
 	return pathResult, nil
 }
 
-func (r *Runner) processTCPResults(res *tcp.Results, hname string, destinationHost string, destinationPort uint16, destinationIP net.IP) (payload.NetworkPath, error) {
+func (r *Runner) processTCPResults(paths []*tcp.Results, hname string, destinationHost string, destinationPort uint16, destinationIP net.IP) (payload.NetworkPath, error) {
 	traceroutePath := payload.NetworkPath{
 		AgentVersion: version.AgentVersion,
 		PathtraceID:  payload.NewPathtraceID(),
@@ -250,38 +347,85 @@ func (r *Runner) processTCPResults(res *tcp.Results, hname string, destinationHo
 	// might be worth also looking in to sharing a router between
 	// the gateway lookup and here or exposing a local IP lookup
 	// function
-	if r.gatewayLookup != nil {
-		src := util.AddressFromNetIP(res.Source)
-		dst := util.AddressFromNetIP(res.Target)
+	if r.gatewayLookup != nil && len(paths) > 0 {
+		src := util.AddressFromNetIP(paths[0].Source)
+		dst := util.AddressFromNetIP(paths[0].Target)
 
 		traceroutePath.Source.Via = r.gatewayLookup.LookupWithIPs(src, dst, r.nsIno)
 	}
 
-	for i, hop := range res.Hops {
-		ttl := i + 1
-		isReachable := false
-		hopname := fmt.Sprintf("unknown_hop_%d", ttl)
-		hostname := hopname
-
-		if !hop.IP.Equal(net.IP{}) {
-			isReachable = true
-			hopname = hop.IP.String()
-			hostname = hopname // setting to ip address for now, reverse DNS lookup will override hostname field later
+	flows := make([][]payload.NetworkPathHop, 0, len(paths))
+	for _, res := range paths {
+		hops := make([]payload.NetworkPathHop, 0, len(res.Hops))
+		for i, hop := range res.Hops {
+			hops = append(hops, sequentialHop(i+1, hop.IP, hop.RTT))
 		}
+		flows = append(flows, hops)
+	}
+	setPathHops(&traceroutePath, flows)
+
+	return traceroutePath, nil
+}
+
+func (r *Runner) processICMPResults(paths []*icmp.Results, hname string, destinationHost string, destinationIP net.IP) (payload.NetworkPath, error) {
+	traceroutePath := payload.NetworkPath{
+		AgentVersion: version.AgentVersion,
+		PathtraceID:  payload.NewPathtraceID(),
+		Protocol:     payload.ProtocolICMP,
+		Timestamp:    time.Now().UnixMilli(),
+		Source: payload.NetworkPathSource{
+			Hostname:  hname,
+			NetworkID: r.networkID,
+		},
+		Destination: payload.NetworkPathDestination{
+			Hostname:  destinationHost,
+			IPAddress: destinationIP.String(),
+		},
+	}
 
-		npHop := payload.NetworkPathHop{
-			TTL:       ttl,
-			IPAddress: hopname,
-			Hostname:  hostname,
-			RTT:       float64(hop.RTT.Microseconds()) / float64(1000),
-			Reachable: isReachable,
+	// get hardware interface info
+	if r.gatewayLookup != nil && len(paths) > 0 {
+		src := util.AddressFromNetIP(paths[0].Source)
+		dst := util.AddressFromNetIP(paths[0].Target)
+
+		traceroutePath.Source.Via = r.gatewayLookup.LookupWithIPs(src, dst, r.nsIno)
+	}
+
+	flows := make([][]payload.NetworkPathHop, 0, len(paths))
+	for _, res := range paths {
+		hops := make([]payload.NetworkPathHop, 0, len(res.Hops))
+		for i, hop := range res.Hops {
+			hops = append(hops, sequentialHop(i+1, hop.IP, hop.RTT))
 		}
-		traceroutePath.Hops = append(traceroutePath.Hops, npHop)
+		flows = append(flows, hops)
 	}
+	setPathHops(&traceroutePath, flows)
 
 	return traceroutePath, nil
 }
 
+// sequentialHop converts a hop discovered by a sequential traceroute,
+// hops that didn't respond have an empty IP
+func sequentialHop(ttl int, ip net.IP, rtt time.Duration) payload.NetworkPathHop {
+	isReachable := false
+	hopname := fmt.Sprintf("unknown_hop_%d", ttl)
+	hostname := hopname
+
+	if !ip.Equal(net.IP{}) {
+		isReachable = true
+		hopname = ip.String()
+		hostname = hopname // setting to ip address for now, reverse DNS lookup will override hostname field later
+	}
+
+	return payload.NetworkPathHop{
+		TTL:       ttl,
+		IPAddress: hopname,
+		Hostname:  hostname,
+		RTT:       float64(rtt.Microseconds()) / float64(1000),
+		Reachable: isReachable,
+	}
+}
+
 func (r *Runner) processUDPResults(res *results.Results, hname string, destinationHost string, destinationPort uint16, destinationIP net.IP) (payload.NetworkPath, error) {
 	type node struct {
 		node  string
@@ -310,6 +454,7 @@ func (r *Runner) processUDPResults(res *results.Results, hname string, destinati
 	}
 	sort.Ints(flowIDs)
 
+	flows := make([][]payload.NetworkPathHop, 0, len(flowIDs))
 	for _, flowID := range flowIDs {
 		hops := res.Flows[uint16(flowID)]
 		if len(hops) == 0 {
@@ -351,6 +496,7 @@ func (r *Runner) processUDPResults(res *results.Results, hname string, destinati
 		}
 
 		// start at node 1. Each node back-references the previous one
+		var flowHops []payload.NetworkPathHop
 		for idx := 1; idx < len(nodes); idx++ {
 			if idx >= len(nodes) {
 				// we are at the second-to-last node
@@ -382,13 +528,34 @@ func (r *Runner) processUDPResults(res *results.Results, hname string, destinati
 				RTT:       durationMs,
 				Reachable: isReachable,
 			}
-			traceroutePath.Hops = append(traceroutePath.Hops, hop)
+			flowHops = append(flowHops, hop)
 		}
+		flows = append(flows, flowHops)
 	}
+	setPathHops(&traceroutePath, flows)
 
 	return traceroutePath, nil
 }
 
+// setPathHops sets the hops of the path from the hops discovered by each
+// flow, the multipath graph is only built when more than one flow was probed
+func setPathHops(path *payload.NetworkPath, flows [][]payload.NetworkPathHop) {
+	if len(flows) == 0 {
+		return
+	}
+	path.Hops = flows[0]
+	if len(flows) > 1 {
+		path.Multipath = buildMultipath(flows)
+	}
+}
+
+func numPaths(cfg Config) uint16 {
+	if cfg.NumPaths == 0 {
+		return DefaultNumPaths
+	}
+	return cfg.NumPaths
+}
+
 func getPorts(configDestPort uint16) (uint16, uint16, bool) {
 	var destPort uint16
 	var srcPort uint16
@@ -14,8 +14,8 @@ import (
 
 	"golang.org/x/net/ipv4"
 
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
-	"github.com/google/gopacket/layers"
 )
 
 type (
@@ -45,29 +45,53 @@ type (
 
 	// Hop encapsulates information about a single
 	// hop in a TCP traceroute
-	Hop struct {
-		IP       net.IP
-		Port     uint16
-		ICMPType layers.ICMPv4TypeCode
-		RTT      time.Duration
-		IsDest   bool
+	Hop = common.Hop
+
+	// tcpv4Prober sends the TCP SYN probes of a
+	// single flow over IPv4
+	tcpv4Prober struct {
+		icmpConn rawConnWrapper
+		tcpConn  rawConnWrapper
+		srcIP    net.IP
+		srcPort  uint16
+		target   net.IP
+		destPort uint16
+		seqNum   uint32
 	}
 )
 
 // TracerouteSequential runs a traceroute sequentially where a packet is
 // sent and we wait for a response before sending the next packet
 func (t *TCPv4) TracerouteSequential() (*Results, error) {
+	paths, err := t.traceroute(1)
+	if err != nil {
+		return nil, err
+	}
+	return paths[0], nil
+}
+
+// TracerouteMultipath runs NumPaths sequential traceroutes, each with a
+// different source port, so that ECMP routers hash them to different paths
+func (t *TCPv4) TracerouteMultipath() ([]*Results, error) {
+	return t.traceroute(max(int(t.NumPaths), 1))
+}
+
+func (t *TCPv4) traceroute(numPaths int) ([]*Results, error) {
 	// Get local address for the interface that connects to this
 	// host and store in in the probe
-	//
-	// TODO: do this once for the probe and hang on to the
-	// listener until we decide to close the probe
 	addr, err := localAddrForHost(t.Target, t.DestPort)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get local address for target: %w", err)
 	}
 	t.srcIP = addr.IP
-	t.srcPort = addr.AddrPort().Port()
+
+	// each flow gets its own source port, held by a listener for the whole
+	// traceroute so that the OS doesn't hand it to another socket
+	listeners, err := reserveLocalPorts(addr, numPaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve source ports: %w", err)
+	}
+	defer closeListeners(listeners)
 
 	// So far I haven't had success trying to simply create a socket
 	// using syscalls directly, but in theory doing so would allow us
@@ -93,72 +117,67 @@ func (t *TCPv4) TracerouteSequential() (*Results, error) {
 		return nil, fmt.Errorf("failed to create TCP listener: %w", err)
 	}
 	defer tcpConn.Close()
-	log.Tracef("Listening for TCP on: %s\n", addr.IP.String()+":"+addr.AddrPort().String())
+	log.Tracef("Listening for TCP on: %s\n", addr.IP.String())
 	// RawConn is necessary to set the TTL and ID fields
 	rawTCPConn, err := ipv4.NewRawConn(tcpConn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get raw TCP listener: %w", err)
 	}
 
-	// hops should be of length # of hops
-	hops := make([]*Hop, 0, t.MaxTTL-t.MinTTL)
-
-	for i := int(t.MinTTL); i <= int(t.MaxTTL); i++ {
-		seqNumber := rand.Uint32()
-		hop, err := t.sendAndReceive(rawIcmpConn, rawTCPConn, i, seqNumber, t.Timeout)
+	paths := make([]*Results, 0, numPaths)
+	for _, listener := range listeners {
+		t.srcPort = uint16(listener.Addr().(*net.TCPAddr).Port)
+		hops, err := t.tracePath(rawIcmpConn, rawTCPConn)
 		if err != nil {
-			return nil, fmt.Errorf("failed to run traceroute: %w", err)
-		}
-		hops = append(hops, hop)
-		log.Tracef("Discovered hop: %+v", hop)
-		// if we've reached our destination,
-		// we're done
-		if hop.IsDest {
-			break
+			return nil, err
 		}
+		paths = append(paths, &Results{
+			Source:     t.srcIP,
+			SourcePort: t.srcPort,
+			Target:     t.Target,
+			DstPort:    t.DestPort,
+			Hops:       hops,
+		})
 	}
 
-	return &Results{
-		Source:     t.srcIP,
-		SourcePort: t.srcPort,
-		Target:     t.Target,
-		DstPort:    t.DestPort,
-		Hops:       hops,
-	}, nil
+	return paths, nil
 }
 
-func (t *TCPv4) sendAndReceive(rawIcmpConn *ipv4.RawConn, rawTCPConn *ipv4.RawConn, ttl int, seqNum uint32, timeout time.Duration) (*Hop, error) {
-	tcpHeader, tcpPacket, err := createRawTCPSyn(t.srcIP, t.srcPort, t.Target, t.DestPort, seqNum, ttl)
-	if err != nil {
-		log.Errorf("failed to create TCP packet with TTL: %d, error: %s", ttl, err.Error())
-		return nil, err
+// tracePath discovers the hops of the path taken by the current flow
+func (t *TCPv4) tracePath(rawIcmpConn *ipv4.RawConn, rawTCPConn *ipv4.RawConn) ([]*Hop, error) {
+	prober := &tcpv4Prober{
+		icmpConn: rawIcmpConn,
+		tcpConn:  rawTCPConn,
+		srcIP:    t.srcIP,
+		srcPort:  t.srcPort,
+		target:   t.Target,
+		destPort: t.DestPort,
 	}
+	return common.TracePath(prober, common.Params{
+		Target:  t.Target,
+		MinTTL:  t.MinTTL,
+		MaxTTL:  t.MaxTTL,
+		Timeout: t.Timeout,
+	})
+}
 
-	err = sendPacket(rawTCPConn, tcpHeader, tcpPacket)
+// SendProbe sends a TCP SYN with the given TTL and a random sequence number
+func (p *tcpv4Prober) SendProbe(ttl int) error {
+	p.seqNum = rand.Uint32()
+	tcpHeader, tcpPacket, err := createRawTCPSyn(p.srcIP, p.srcPort, p.target, p.destPort, p.seqNum, ttl)
 	if err != nil {
-		log.Errorf("failed to send TCP SYN: %s", err.Error())
-		return nil, err
+		return fmt.Errorf("failed to create TCP packet: %w", err)
 	}
+	return sendPacket(p.tcpConn, tcpHeader, tcpPacket)
+}
 
-	start := time.Now() // TODO: is this the best place to start?
-	hopIP, hopPort, icmpType, end, err := listenPackets(rawIcmpConn, rawTCPConn, timeout, t.srcIP, t.srcPort, t.Target, t.DestPort, seqNum)
+// ReceiveProbe waits for the ICMP error or the TCP response to the last SYN
+func (p *tcpv4Prober) ReceiveProbe(timeout time.Duration) (*common.ProbeResponse, error) {
+	hopIP, hopPort, icmpType, end, err := listenPackets(p.icmpConn, p.tcpConn, timeout, p.srcIP, p.srcPort, p.target, p.destPort, p.seqNum)
 	if err != nil {
-		log.Errorf("failed to listen for packets: %s", err.Error())
 		return nil, err
 	}
-
-	rtt := time.Duration(0)
-	if !hopIP.Equal(net.IP{}) {
-		rtt = end.Sub(start)
-	}
-
-	return &Hop{
-		IP:       hopIP,
-		Port:     hopPort,
-		ICMPType: icmpType,
-		RTT:      rtt,
-		IsDest:   hopIP.Equal(t.Target),
-	}, nil
+	return &common.ProbeResponse{IP: hopIP, Port: hopPort, ICMPType: icmpType, Time: end}, nil
 }
 
 // Close doesn't to anything yet, but we should
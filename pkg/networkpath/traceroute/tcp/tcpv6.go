@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tcp
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"golang.org/x/net/ipv6"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type (
+	// TCPv6 encapsulates the data needed to run
+	// a TCPv6 traceroute
+	TCPv6 struct {
+		Target   net.IP
+		srcIP    net.IP // calculated internally
+		srcPort  uint16 // calculated internally
+		DestPort uint16
+		NumPaths uint16
+		MinTTL   uint8
+		MaxTTL   uint8
+		Delay    time.Duration // delay between sending packets (not applicable if we go the serial send/receive route)
+		Timeout  time.Duration // full timeout for all packets
+	}
+
+	// tcpv6Prober sends the TCP SYN probes of a
+	// single flow over IPv6
+	tcpv6Prober struct {
+		icmpConn rawConnV6Wrapper
+		tcpConn  rawConnV6Wrapper
+		srcIP    net.IP
+		srcPort  uint16
+		target   net.IP
+		destPort uint16
+		seqNum   uint32
+	}
+)
+
+// TracerouteSequential runs a traceroute sequentially where a packet is
+// sent and we wait for a response before sending the next packet
+func (t *TCPv6) TracerouteSequential() (*Results, error) {
+	paths, err := t.traceroute(1)
+	if err != nil {
+		return nil, err
+	}
+	return paths[0], nil
+}
+
+// TracerouteMultipath runs NumPaths sequential traceroutes, each with a
+// different source port, so that ECMP routers hash them to different paths
+func (t *TCPv6) TracerouteMultipath() ([]*Results, error) {
+	return t.traceroute(max(int(t.NumPaths), 1))
+}
+
+func (t *TCPv6) traceroute(numPaths int) ([]*Results, error) {
+	// Get local address for the interface that connects to this
+	// host and store in in the probe
+	addr, err := localAddrForHost(t.Target, t.DestPort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local address for target: %w", err)
+	}
+	t.srcIP = addr.IP
+
+	// each flow gets its own source port, held by a listener for the whole
+	// traceroute so that the OS doesn't hand it to another socket
+	listeners, err := reserveLocalPorts(addr, numPaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve source ports: %w", err)
+	}
+	defer closeListeners(listeners)
+
+	// Unlike IPv4, raw IPv6 sockets never expose the IP header, the
+	// hop limit is set with ancillary data when sending instead
+	//
+	// Create a raw ICMPv6 listener to catch ICMPv6 responses
+	icmpConn, err := net.ListenPacket("ip6:ipv6-icmp", addr.IP.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ICMPv6 listener: %w", err)
+	}
+	defer icmpConn.Close()
+	rawIcmpConn := ipv6.NewPacketConn(icmpConn)
+
+	// Create a raw TCP listener to send the SYN packets and catch
+	// the TCP response from our final hop if we get one
+	tcpConn, err := net.ListenPacket("ip6:tcp", addr.IP.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TCP listener: %w", err)
+	}
+	defer tcpConn.Close()
+	log.Tracef("Listening for TCP on: %s\n", addr.IP.String())
+	rawTCPConn := ipv6.NewPacketConn(tcpConn)
+	// the destination address of responses is only
+	// available through ancillary data
+	if err := rawTCPConn.SetControlMessage(ipv6.FlagDst, true); err != nil {
+		return nil, fmt.Errorf("failed to enable control messages on TCP listener: %w", err)
+	}
+
+	paths := make([]*Results, 0, numPaths)
+	for _, listener := range listeners {
+		t.srcPort = uint16(listener.Addr().(*net.TCPAddr).Port)
+		hops, err := t.tracePath(rawIcmpConn, rawTCPConn)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, &Results{
+			Source:     t.srcIP,
+			SourcePort: t.srcPort,
+			Target:     t.Target,
+			DstPort:    t.DestPort,
+			Hops:       hops,
+		})
+	}
+
+	return paths, nil
+}
+
+// tracePath discovers the hops of the path taken by the current flow
+func (t *TCPv6) tracePath(rawIcmpConn rawConnV6Wrapper, rawTCPConn rawConnV6Wrapper) ([]*Hop, error) {
+	prober := &tcpv6Prober{
+		icmpConn: rawIcmpConn,
+		tcpConn:  rawTCPConn,
+		srcIP:    t.srcIP,
+		srcPort:  t.srcPort,
+		target:   t.Target,
+		destPort: t.DestPort,
+	}
+	return common.TracePath(prober, common.Params{
+		Target:  t.Target,
+		MinTTL:  t.MinTTL,
+		MaxTTL:  t.MaxTTL,
+		Timeout: t.Timeout,
+	})
+}
+
+// SendProbe sends a TCP SYN with the given hop limit and a random sequence number
+func (p *tcpv6Prober) SendProbe(ttl int) error {
+	p.seqNum = rand.Uint32()
+	tcpPacket, err := createRawTCPSynV6(p.srcIP, p.srcPort, p.target, p.destPort, p.seqNum)
+	if err != nil {
+		return fmt.Errorf("failed to create TCP packet: %w", err)
+	}
+	return sendPacketV6(p.tcpConn, tcpPacket, p.target, ttl)
+}
+
+// ReceiveProbe waits for the ICMPv6 error or the TCP response to the last SYN
+func (p *tcpv6Prober) ReceiveProbe(timeout time.Duration) (*common.ProbeResponse, error) {
+	hopIP, hopPort, _, end, err := listenPacketsV6(p.icmpConn, p.tcpConn, timeout, p.srcIP, p.srcPort, p.target, p.destPort, p.seqNum)
+	if err != nil {
+		return nil, err
+	}
+	return &common.ProbeResponse{IP: hopIP, Port: hopPort, Time: end}, nil
+}
+
+// Close doesn't to anything yet, but we should
+// use this to close out long running sockets
+// when we're done with a path test
+func (t *TCPv6) Close() error {
+	return nil
+}
@@ -71,7 +71,7 @@ func localAddrForHost(destIP net.IP, destPort uint16) (*net.UDPAddr, error) {
 	// this is a quick way to get the local address for connecting to the host
 	// using UDP as the network type to avoid actually creating a connection to
 	// the host, just get the OS to give us a local IP and local ephemeral port
-	conn, err := net.Dial("udp4", net.JoinHostPort(destIP.String(), strconv.Itoa(int(destPort))))
+	conn, err := net.Dial("udp", net.JoinHostPort(destIP.String(), strconv.Itoa(int(destPort))))
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +86,32 @@ func localAddrForHost(destIP net.IP, destPort uint16) (*net.UDPAddr, error) {
 	return localUDPAddr, nil
 }
 
+// reserveLocalPorts binds a TCP listener to a free port of the local address
+// for each flow, so that no other socket of the host uses the source port of
+// the probes, and gets the responses to them, while the traceroute runs. The
+// listeners have to be closed once the traceroute is done.
+func reserveLocalPorts(localAddr *net.UDPAddr, count int) ([]*net.TCPListener, error) {
+	listeners := make([]*net.TCPListener, 0, count)
+	for i := 0; i < count; i++ {
+		listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: localAddr.IP, Zone: localAddr.Zone})
+		if err != nil {
+			closeListeners(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// closeListeners closes the listeners reserving the source ports of flows
+func closeListeners(listeners []*net.TCPListener) {
+	for _, listener := range listeners {
+		if err := listener.Close(); err != nil {
+			log.Debugf("failed to close the listener reserving %s: %s", listener.Addr(), err)
+		}
+	}
+}
+
 // createRawTCPSyn creates a TCP packet with the specified parameters
 func createRawTCPSyn(sourceIP net.IP, sourcePort uint16, destIP net.IP, destPort uint16, seqNum uint32, ttl int) (*ipv4.Header, []byte, error) {
 	ipLayer := &layers.IPv4{
@@ -145,6 +171,23 @@ func sendPacket(rawConn rawConnWrapper, header *ipv4.Header, payload []byte) err
 // Once a matching packet is received by a listener, it will cause the other listener
 // to be canceled, and data from the matching packet will be returned to the caller
 func listenPackets(icmpConn rawConnWrapper, tcpConn rawConnWrapper, timeout time.Duration, localIP net.IP, localPort uint16, remoteIP net.IP, remotePort uint16, seqNum uint32) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
+	return raceListeners(timeout,
+		func(ctx context.Context) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
+			return handlePackets(ctx, tcpConn, "tcp", localIP, localPort, remoteIP, remotePort, seqNum)
+		},
+		func(ctx context.Context) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
+			return handlePackets(ctx, icmpConn, "icmp", localIP, localPort, remoteIP, remotePort, seqNum)
+		},
+	)
+}
+
+// packetHandler listens for the first matching packet until
+// the context is canceled
+type packetHandler func(ctx context.Context) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error)
+
+// raceListeners runs the TCP and ICMP handlers concurrently, the first
+// handler that receives a matching packet cancels the other one
+func raceListeners(timeout time.Duration, tcpHandler packetHandler, icmpHandler packetHandler) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
 	var tcpErr error
 	var icmpErr error
 	var wg sync.WaitGroup
@@ -160,12 +203,12 @@ func listenPackets(icmpConn rawConnWrapper, tcpConn rawConnWrapper, timeout time
 	go func() {
 		defer wg.Done()
 		defer cancel()
-		tcpIP, port, _, tcpFinished, tcpErr = handlePackets(ctx, tcpConn, "tcp", localIP, localPort, remoteIP, remotePort, seqNum)
+		tcpIP, port, _, tcpFinished, tcpErr = tcpHandler(ctx)
 	}()
 	go func() {
 		defer wg.Done()
 		defer cancel()
-		icmpIP, _, icmpCode, icmpFinished, icmpErr = handlePackets(ctx, icmpConn, "icmp", localIP, localPort, remoteIP, remotePort, seqNum)
+		icmpIP, _, icmpCode, icmpFinished, icmpErr = icmpHandler(ctx)
 	}()
 	wg.Wait()
 
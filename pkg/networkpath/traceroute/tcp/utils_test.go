@@ -180,6 +180,31 @@ func Test_handlePackets(t *testing.T) {
 	}
 }
 
+func Test_reserveLocalPorts(t *testing.T) {
+	listeners, err := reserveLocalPorts(&net.UDPAddr{IP: net.ParseIP("127.0.0.1")}, 3)
+	require.NoError(t, err)
+	require.Len(t, listeners, 3)
+
+	ports := make(map[int]struct{})
+	for _, listener := range listeners {
+		port := listener.Addr().(*net.TCPAddr).Port
+		ports[port] = struct{}{}
+
+		// the port is held until the listeners are closed
+		_, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+		assert.Error(t, err)
+	}
+	assert.Len(t, ports, 3)
+
+	closeListeners(listeners)
+	for port := range ports {
+		listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: port})
+		if assert.NoError(t, err) {
+			listener.Close()
+		}
+	}
+}
+
 func Test_parseICMP(t *testing.T) {
 	ipv4Header := createMockIPv4Header(srcIP, dstIP, 1)
 	icmpLayer := createMockICMPLayer(layers.ICMPv4CodeTTLExceeded)
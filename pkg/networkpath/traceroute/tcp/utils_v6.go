@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv6"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// icmpv6UnusedLen is the length of the unused field preceding
+// the invoking packet in ICMPv6 error messages
+const icmpv6UnusedLen = 4
+
+// rawConnV6Wrapper is implemented by ipv6.PacketConn, raw IPv6 sockets
+// only deal with payloads, addresses are passed separately
+type rawConnV6Wrapper interface {
+	SetReadDeadline(t time.Time) error
+	ReadFrom(b []byte) (int, *ipv6.ControlMessage, net.Addr, error)
+	WriteTo(b []byte, cm *ipv6.ControlMessage, dst net.Addr) (int, error)
+}
+
+// createRawTCPSynV6 creates a TCP SYN segment with the specified parameters,
+// the kernel doesn't compute TCP checksums for raw IPv6 sockets so it is
+// computed here using the IPv6 pseudo-header
+func createRawTCPSynV6(sourceIP net.IP, sourcePort uint16, destIP net.IP, destPort uint16, seqNum uint32) ([]byte, error) {
+	ipLayer := &layers.IPv6{
+		Version:    6,
+		NextHeader: layers.IPProtocolTCP,
+		SrcIP:      sourceIP,
+		DstIP:      destIP,
+	}
+
+	tcpLayer := &layers.TCP{
+		SrcPort: layers.TCPPort(sourcePort),
+		DstPort: layers.TCPPort(destPort),
+		Seq:     seqNum,
+		Ack:     0,
+		SYN:     true,
+		Window:  1024,
+	}
+
+	err := tcpLayer.SetNetworkLayerForChecksum(ipLayer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create packet checksum: %w", err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	err = gopacket.SerializeLayers(buf, opts, tcpLayer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize packet: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// sendPacketV6 sends a TCP segment to the destination with the given hop limit
+func sendPacketV6(rawConn rawConnV6Wrapper, payload []byte, destIP net.IP, hopLimit int) error {
+	cm := &ipv6.ControlMessage{HopLimit: hopLimit}
+	if _, err := rawConn.WriteTo(payload, cm, &net.IPAddr{IP: destIP}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// listenPacketsV6 is the IPv6 counterpart of listenPackets, ICMPv6 type
+// codes are not returned
+func listenPacketsV6(icmpConn rawConnV6Wrapper, tcpConn rawConnV6Wrapper, timeout time.Duration, localIP net.IP, localPort uint16, remoteIP net.IP, remotePort uint16, seqNum uint32) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
+	return raceListeners(timeout,
+		func(ctx context.Context) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
+			return handlePacketsV6(ctx, tcpConn, "tcp", localIP, localPort, remoteIP, remotePort, seqNum)
+		},
+		func(ctx context.Context) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
+			return handlePacketsV6(ctx, icmpConn, "icmp", localIP, localPort, remoteIP, remotePort, seqNum)
+		},
+	)
+}
+
+// handlePacketsV6 is the IPv6 counterpart of handlePackets
+func handlePacketsV6(ctx context.Context, conn rawConnV6Wrapper, listener string, localIP net.IP, localPort uint16, remoteIP net.IP, remotePort uint16, seqNum uint32) (net.IP, uint16, layers.ICMPv4TypeCode, time.Time, error) {
+	buf := make([]byte, 1500)
+	tp := newTCPParser()
+	for {
+		select {
+		case <-ctx.Done():
+			return net.IP{}, 0, 0, time.Time{}, canceledError("listener canceled")
+		default:
+		}
+		now := time.Now()
+		err := conn.SetReadDeadline(now.Add(time.Millisecond * 100))
+		if err != nil {
+			return net.IP{}, 0, 0, time.Time{}, fmt.Errorf("failed to read: %w", err)
+		}
+		n, cm, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if nerr, ok := err.(*net.OpError); ok {
+				if nerr.Timeout() {
+					continue
+				}
+			}
+			return net.IP{}, 0, 0, time.Time{}, err
+		}
+		// once we have a packet, take a timestamp to know when
+		// the response was received, if it matches, we will
+		// return this timestamp
+		received := time.Now()
+		ipAddr, ok := addr.(*net.IPAddr)
+		if !ok {
+			continue
+		}
+		if listener == "icmp" {
+			icmpResponse, err := parseICMPv6(ipAddr.IP, buf[:n])
+			if err != nil {
+				log.Tracef("failed to parse ICMPv6 packet: %s", err)
+				continue
+			}
+			if icmpMatch(localIP, localPort, remoteIP, remotePort, seqNum, icmpResponse) {
+				return icmpResponse.SrcIP, 0, 0, received, nil
+			}
+		} else if listener == "tcp" {
+			if cm == nil {
+				continue
+			}
+			tcpResp, err := tp.parseTCPv6(ipAddr.IP, cm.Dst, buf[:n])
+			if err != nil {
+				log.Tracef("failed to parse TCP packet: %s", err)
+				continue
+			}
+			if tcpMatch(localIP, localPort, remoteIP, remotePort, seqNum, tcpResp) {
+				return tcpResp.SrcIP, uint16(tcpResp.TCPResponse.SrcPort), 0, received, nil
+			}
+		} else {
+			return net.IP{}, 0, 0, received, fmt.Errorf("unsupported listener type")
+		}
+	}
+}
+
+// parseICMPv6 takes in the source address and payload of an ICMPv6 message and
+// returns the fields of the TCP segment that triggered it, if it is a time
+// exceeded or destination unreachable error
+func parseICMPv6(srcIP net.IP, payload []byte) (*icmpResponse, error) {
+	var icmpv6Layer layers.ICMPv6
+	if err := icmpv6Layer.DecodeFromBytes(payload, gopacket.NilDecodeFeedback); err != nil {
+		return nil, fmt.Errorf("failed to decode ICMPv6 packet: %w", err)
+	}
+
+	switch icmpv6Layer.TypeCode.Type() {
+	case layers.ICMPv6TypeTimeExceeded, layers.ICMPv6TypeDestinationUnreachable:
+	default:
+		return nil, fmt.Errorf("unsupported ICMPv6 type: %s", icmpv6Layer.TypeCode)
+	}
+	if len(icmpv6Layer.Payload) < icmpv6UnusedLen {
+		return nil, fmt.Errorf("ICMPv6 payload is too short: %d bytes", len(icmpv6Layer.Payload))
+	}
+
+	// the invoking packet may be truncated after the first 8 bytes of
+	// the TCP header, which hold the ports and the sequence number, it
+	// is extended to a full TCP header for the parser to work
+	invoking := icmpv6Layer.Payload[icmpv6UnusedLen:]
+	if len(invoking) < 60 {
+		padded := make([]byte, 60)
+		copy(padded, invoking)
+		padded[52] = 5 << 4 // set data offset
+		invoking = padded
+	}
+
+	var innerIPLayer layers.IPv6
+	var innerTCPLayer layers.TCP
+	decoded := []gopacket.LayerType{}
+	innerIPParser := gopacket.NewDecodingLayerParser(layers.LayerTypeIPv6, &innerIPLayer, &innerTCPLayer)
+	if err := innerIPParser.DecodeLayers(invoking, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode inner ICMPv6 payload: %w", err)
+	}
+
+	return &icmpResponse{
+		SrcIP:        srcIP,
+		InnerSrcIP:   innerIPLayer.SrcIP,
+		InnerDstIP:   innerIPLayer.DstIP,
+		InnerSrcPort: uint16(innerTCPLayer.SrcPort),
+		InnerDstPort: uint16(innerTCPLayer.DstPort),
+		InnerSeqNum:  innerTCPLayer.Seq,
+	}, nil
+}
+
+func (tp *tcpParser) parseTCPv6(srcIP net.IP, dstIP net.IP, payload []byte) (*tcpResponse, error) {
+	if srcIP == nil || dstIP == nil {
+		return nil, fmt.Errorf("missing addresses for TCP packet: src=%s dst=%s", srcIP, dstIP)
+	}
+
+	if err := tp.decodingLayerParser.DecodeLayers(payload, &tp.decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode TCP packet: %w", err)
+	}
+
+	resp := &tcpResponse{
+		SrcIP:       srcIP,
+		DstIP:       dstIP,
+		TCPResponse: tp.layer,
+	}
+	// make sure the TCP layer is cleared between runs
+	tp.layer = layers.TCP{}
+
+	return resp, nil
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package tcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	srcIPv6      = net.ParseIP("fd00::1")
+	innerSrcIPv6 = net.ParseIP("fd00:1::2")
+	innerDstIPv6 = net.ParseIP("fd00:2::2")
+)
+
+func Test_createRawTCPSynV6(t *testing.T) {
+	packet, err := createRawTCPSynV6(innerSrcIPv6, 12345, innerDstIPv6, 443, 28394)
+	require.NoError(t, err)
+
+	pkt := gopacket.NewPacket(packet, layers.LayerTypeTCP, gopacket.Default)
+	tcpLayer, ok := pkt.Layer(layers.LayerTypeTCP).(*layers.TCP)
+	require.True(t, ok)
+	assert.Equal(t, layers.TCPPort(12345), tcpLayer.SrcPort)
+	assert.Equal(t, layers.TCPPort(443), tcpLayer.DstPort)
+	assert.Equal(t, uint32(28394), tcpLayer.Seq)
+	assert.True(t, tcpLayer.SYN)
+	assert.NotZero(t, tcpLayer.Checksum)
+}
+
+func Test_parseICMPv6(t *testing.T) {
+	innerTCPLayer := createMockTCPLayer(12345, 443, 28394, 0, true, false, false)
+
+	tt := []struct {
+		description string
+		inPayload   []byte
+		expected    *icmpResponse
+		errMsg      string
+	}{
+		{
+			description: "empty payload should return an error",
+			inPayload:   []byte{},
+			errMsg:      "failed to decode ICMPv6 packet",
+		},
+		{
+			description: "echo reply should return an error",
+			inPayload:   createMockICMPv6Packet(layers.ICMPv6TypeEchoReply, 0, nil),
+			errMsg:      "unsupported ICMPv6 type",
+		},
+		{
+			description: "missing inner layers should return an error",
+			inPayload:   createMockICMPv6Packet(layers.ICMPv6TypeTimeExceeded, 0, nil),
+			errMsg:      "failed to decode inner ICMPv6 payload",
+		},
+		{
+			description: "time exceeded with partial TCP header should create icmpResponse",
+			inPayload:   createMockICMPv6Packet(layers.ICMPv6TypeTimeExceeded, 0, createMockIPv6TCPPacket(innerTCPLayer, true)),
+			expected: &icmpResponse{
+				SrcIP:        srcIPv6,
+				InnerSrcIP:   innerSrcIPv6,
+				InnerDstIP:   innerDstIPv6,
+				InnerSrcPort: 12345,
+				InnerDstPort: 443,
+				InnerSeqNum:  28394,
+			},
+		},
+		{
+			description: "destination unreachable with full TCP header should create icmpResponse",
+			inPayload:   createMockICMPv6Packet(layers.ICMPv6TypeDestinationUnreachable, layers.ICMPv6CodeAdminProhibited, createMockIPv6TCPPacket(innerTCPLayer, false)),
+			expected: &icmpResponse{
+				SrcIP:        srcIPv6,
+				InnerSrcIP:   innerSrcIPv6,
+				InnerDstIP:   innerDstIPv6,
+				InnerSrcPort: 12345,
+				InnerDstPort: 443,
+				InnerSeqNum:  28394,
+			},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := parseICMPv6(srcIPv6, test.inPayload)
+			if test.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errMsg)
+				assert.Nil(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, actual)
+			assert.Truef(t, test.expected.SrcIP.Equal(actual.SrcIP), "mismatch source IPs: expected %s, got %s", test.expected.SrcIP, actual.SrcIP)
+			assert.Truef(t, test.expected.InnerSrcIP.Equal(actual.InnerSrcIP), "mismatch inner source IPs: expected %s, got %s", test.expected.InnerSrcIP, actual.InnerSrcIP)
+			assert.Truef(t, test.expected.InnerDstIP.Equal(actual.InnerDstIP), "mismatch inner dest IPs: expected %s, got %s", test.expected.InnerDstIP, actual.InnerDstIP)
+			assert.Equal(t, test.expected.InnerSrcPort, actual.InnerSrcPort)
+			assert.Equal(t, test.expected.InnerDstPort, actual.InnerDstPort)
+			assert.Equal(t, test.expected.InnerSeqNum, actual.InnerSeqNum)
+			assert.True(t, icmpMatch(innerSrcIPv6, 12345, innerDstIPv6, 443, 28394, actual))
+		})
+	}
+}
+
+func Test_parseTCPv6(t *testing.T) {
+	// SYN-ACK from the destination, acknowledging our SYN
+	tcpLayer := createMockTCPLayer(443, 12345, 0, 28395, true, true, false)
+	tcpLayer.SetNetworkLayerForChecksum(&layers.IPv6{
+		Version:    6,
+		NextHeader: layers.IPProtocolTCP,
+		SrcIP:      innerDstIPv6,
+		DstIP:      innerSrcIPv6,
+	})
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	require.NoError(t, gopacket.SerializeLayers(buf, opts, tcpLayer))
+	payload := buf.Bytes()
+
+	tp := newTCPParser()
+	resp, err := tp.parseTCPv6(innerDstIPv6, innerSrcIPv6, payload)
+	require.NoError(t, err)
+	assert.True(t, tcpMatch(innerSrcIPv6, 12345, innerDstIPv6, 443, 28394, resp))
+
+	_, err = tp.parseTCPv6(innerDstIPv6, nil, payload)
+	assert.ErrorContains(t, err, "missing addresses for TCP packet")
+}
+
+func createMockIPv6TCPPacket(tcpLayer *layers.TCP, partialTCPHeader bool) []byte {
+	ipLayer := &layers.IPv6{
+		Version:    6,
+		NextHeader: layers.IPProtocolTCP,
+		HopLimit:   1,
+		SrcIP:      innerSrcIPv6,
+		DstIP:      innerDstIPv6,
+	}
+	tcpLayer.SetNetworkLayerForChecksum(ipLayer)
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	gopacket.SerializeLayers(buf, opts, ipLayer, tcpLayer)
+	packet := buf.Bytes()
+
+	// if partialTCP is set, truncate
+	// the payload to include only the
+	// first 8 bytes of the TCP header
+	if partialTCPHeader {
+		packet = packet[:48]
+	}
+	return packet
+}
+
+func createMockICMPv6Packet(typ uint8, code uint8, invokingPacket []byte) []byte {
+	// type, code, checksum and unused field
+	packet := []byte{typ, code, 0, 0, 0, 0, 0, 0}
+	return append(packet, invokingPacket...)
+}
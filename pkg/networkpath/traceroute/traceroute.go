@@ -13,6 +13,16 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 )
 
+const (
+	// IPVersionAuto uses IPv4 if the destination
+	// resolves to an IPv4 address, IPv6 otherwise
+	IPVersionAuto uint8 = 0
+	// IPVersion4 only uses IPv4
+	IPVersion4 uint8 = 4
+	// IPVersion6 only uses IPv6
+	IPVersion6 uint8 = 6
+)
+
 type (
 	// Config specifies the configuration of an instance
 	// of Traceroute
@@ -35,6 +45,12 @@ type (
 		// Protocol is the protocol to use
 		// for traceroute, default is UDP
 		Protocol payload.Protocol
+		// IPVersion is the IP version to use to
+		// reach the destination, see IPVersionAuto
+		IPVersion uint8
+		// NumPaths is the number of flows to probe
+		// to discover ECMP paths, up to MaxNumPaths
+		NumPaths uint16
 	}
 
 	// Traceroute defines an interface for running
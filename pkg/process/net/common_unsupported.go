@@ -104,6 +104,6 @@ func (r *RemoteSysProbeUtil) GetPing(clientID string, host string, count int, in
 	return nil, ErrNotImplemented
 }
 
-func (r *RemoteSysProbeUtil) GetTraceroute(clientID string, host string, port uint16, protocol nppayload.Protocol, maxTTL uint8, timeout time.Duration) ([]byte, error) {
+func (r *RemoteSysProbeUtil) GetTraceroute(clientID string, host string, port uint16, protocol nppayload.Protocol, ipVersion uint8, numPaths uint16, maxTTL uint8, timeout time.Duration) ([]byte, error) {
 	return nil, ErrNotImplemented
 }
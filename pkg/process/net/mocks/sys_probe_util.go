@@ -779,9 +779,9 @@ func (_c *SysProbeUtil_GetTelemetry_Call) RunAndReturn(run func() ([]byte, error
 	return _c
 }
 
-// GetTraceroute provides a mock function with given fields: clientID, host, port, protocol, maxTTL, timeout
-func (_m *SysProbeUtil) GetTraceroute(clientID string, host string, port uint16, protocol payload.Protocol, maxTTL uint8, timeout time.Duration) ([]byte, error) {
-	ret := _m.Called(clientID, host, port, protocol, maxTTL, timeout)
+// GetTraceroute provides a mock function with given fields: clientID, host, port, protocol, ipVersion, numPaths, maxTTL, timeout
+func (_m *SysProbeUtil) GetTraceroute(clientID string, host string, port uint16, protocol payload.Protocol, ipVersion uint8, numPaths uint16, maxTTL uint8, timeout time.Duration) ([]byte, error) {
+	ret := _m.Called(clientID, host, port, protocol, ipVersion, numPaths, maxTTL, timeout)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetTraceroute")
@@ -789,19 +789,19 @@ func (_m *SysProbeUtil) GetTraceroute(clientID string, host string, port uint16,
 
 	var r0 []byte
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, uint16, payload.Protocol, uint8, time.Duration) ([]byte, error)); ok {
-		return rf(clientID, host, port, protocol, maxTTL, timeout)
+	if rf, ok := ret.Get(0).(func(string, string, uint16, payload.Protocol, uint8, uint16, uint8, time.Duration) ([]byte, error)); ok {
+		return rf(clientID, host, port, protocol, ipVersion, numPaths, maxTTL, timeout)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, uint16, payload.Protocol, uint8, time.Duration) []byte); ok {
-		r0 = rf(clientID, host, port, protocol, maxTTL, timeout)
+	if rf, ok := ret.Get(0).(func(string, string, uint16, payload.Protocol, uint8, uint16, uint8, time.Duration) []byte); ok {
+		r0 = rf(clientID, host, port, protocol, ipVersion, numPaths, maxTTL, timeout)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]byte)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, uint16, payload.Protocol, uint8, time.Duration) error); ok {
-		r1 = rf(clientID, host, port, protocol, maxTTL, timeout)
+	if rf, ok := ret.Get(1).(func(string, string, uint16, payload.Protocol, uint8, uint16, uint8, time.Duration) error); ok {
+		r1 = rf(clientID, host, port, protocol, ipVersion, numPaths, maxTTL, timeout)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -819,15 +819,17 @@ type SysProbeUtil_GetTraceroute_Call struct {
 //   - host string
 //   - port uint16
 //   - protocol payload.Protocol
+//   - ipVersion uint8
+//   - numPaths uint16
 //   - maxTTL uint8
 //   - timeout time.Duration
-func (_e *SysProbeUtil_Expecter) GetTraceroute(clientID interface{}, host interface{}, port interface{}, protocol interface{}, maxTTL interface{}, timeout interface{}) *SysProbeUtil_GetTraceroute_Call {
-	return &SysProbeUtil_GetTraceroute_Call{Call: _e.mock.On("GetTraceroute", clientID, host, port, protocol, maxTTL, timeout)}
+func (_e *SysProbeUtil_Expecter) GetTraceroute(clientID interface{}, host interface{}, port interface{}, protocol interface{}, ipVersion interface{}, numPaths interface{}, maxTTL interface{}, timeout interface{}) *SysProbeUtil_GetTraceroute_Call {
+	return &SysProbeUtil_GetTraceroute_Call{Call: _e.mock.On("GetTraceroute", clientID, host, port, protocol, ipVersion, numPaths, maxTTL, timeout)}
 }
 
-func (_c *SysProbeUtil_GetTraceroute_Call) Run(run func(clientID string, host string, port uint16, protocol payload.Protocol, maxTTL uint8, timeout time.Duration)) *SysProbeUtil_GetTraceroute_Call {
+func (_c *SysProbeUtil_GetTraceroute_Call) Run(run func(clientID string, host string, port uint16, protocol payload.Protocol, ipVersion uint8, numPaths uint16, maxTTL uint8, timeout time.Duration)) *SysProbeUtil_GetTraceroute_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string), args[2].(uint16), args[3].(payload.Protocol), args[4].(uint8), args[5].(time.Duration))
+		run(args[0].(string), args[1].(string), args[2].(uint16), args[3].(payload.Protocol), args[4].(uint8), args[5].(uint16), args[6].(uint8), args[7].(time.Duration))
 	})
 	return _c
 }
@@ -837,7 +839,7 @@ func (_c *SysProbeUtil_GetTraceroute_Call) Return(_a0 []byte, _a1 error) *SysPro
 	return _c
 }
 
-func (_c *SysProbeUtil_GetTraceroute_Call) RunAndReturn(run func(string, string, uint16, payload.Protocol, uint8, time.Duration) ([]byte, error)) *SysProbeUtil_GetTraceroute_Call {
+func (_c *SysProbeUtil_GetTraceroute_Call) RunAndReturn(run func(string, string, uint16, payload.Protocol, uint8, uint16, uint8, time.Duration) ([]byte, error)) *SysProbeUtil_GetTraceroute_Call {
 	_c.Call.Return(run)
 	return _c
 }
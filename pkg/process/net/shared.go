@@ -36,5 +36,5 @@ type SysProbeUtil interface {
 	GetDiscoveryServices() (*discoverymodel.ServicesResponse, error)
 	GetCheck(module sysconfigtypes.ModuleName) (interface{}, error)
 	GetPing(clientID string, host string, count int, interval time.Duration, timeout time.Duration) ([]byte, error)
-	GetTraceroute(clientID string, host string, port uint16, protocol nppayload.Protocol, maxTTL uint8, timeout time.Duration) ([]byte, error)
+	GetTraceroute(clientID string, host string, port uint16, protocol nppayload.Protocol, ipVersion uint8, numPaths uint16, maxTTL uint8, timeout time.Duration) ([]byte, error)
 }
@@ -254,6 +254,10 @@ type RuntimeSecurityConfig struct {
 	EnforcementDisarmerExecutableMaxAllowed int
 	// EnforcementDisarmerExecutablePeriod defines the period during which EnforcementDisarmerExecutableMaxAllowed is checked
 	EnforcementDisarmerExecutablePeriod time.Duration
+	// EnforcementNetworkIsolationEnabled defines if the cgroup egress program used by the network isolation action should be loaded
+	EnforcementNetworkIsolationEnabled bool
+	// EnforcementQuarantineDirectory defines the directory where the files quarantined by the quarantine action are moved
+	EnforcementQuarantineDirectory string
 
 	//WindowsFilenameCacheSize is the max number of filenames to cache
 	WindowsFilenameCacheSize int
@@ -449,6 +453,8 @@ func NewRuntimeSecurityConfig() (*RuntimeSecurityConfig, error) {
 		EnforcementDisarmerExecutableEnabled:    pkgconfigsetup.SystemProbe().GetBool("runtime_security_config.enforcement.disarmer.executable.enabled"),
 		EnforcementDisarmerExecutableMaxAllowed: pkgconfigsetup.SystemProbe().GetInt("runtime_security_config.enforcement.disarmer.executable.max_allowed"),
 		EnforcementDisarmerExecutablePeriod:     pkgconfigsetup.SystemProbe().GetDuration("runtime_security_config.enforcement.disarmer.executable.period"),
+		EnforcementNetworkIsolationEnabled:      pkgconfigsetup.SystemProbe().GetBool("runtime_security_config.enforcement.network_isolation.enabled"),
+		EnforcementQuarantineDirectory:          pkgconfigsetup.SystemProbe().GetString("runtime_security_config.enforcement.quarantine.directory"),
 
 		// User Sessions
 		UserSessionsCacheSize: pkgconfigsetup.SystemProbe().GetInt("runtime_security_config.user_sessions.cache_size"),
@@ -76,6 +76,12 @@ enum TC_TAIL_CALL_KEYS
 #define INGRESS 2
 #define ACT_OK TC_ACT_UNSPEC
 #define ACT_SHOT TC_ACT_SHOT
+
+#define CGROUP_SKB_DROP 0
+#define CGROUP_SKB_PASS 1
+
+#define ISOLATION_MAX_CGROUPS 1024
+#define ISOLATION_MAX_ALLOWED_NETWORKS 4096
 #define PACKET_KEY 0
 #define IMDS_EVENT_KEY 0
 #define IMDS_MAX_LENGTH 2048
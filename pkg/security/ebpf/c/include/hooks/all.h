@@ -38,6 +38,7 @@
 
 #include "network/bind.h"
 #include "network/connect.h"
+#include "network/isolation.h"
 
 #ifndef DO_NOT_USE_TC
 #include "network/dns.h"
@@ -0,0 +1,66 @@
+#ifndef _HOOKS_NETWORK_ISOLATION_H_
+#define _HOOKS_NETWORK_ISOLATION_H_
+
+#include "maps.h"
+
+// offsets of the destination address in the IPv4 and IPv6 headers, cgroup_skb programs see the packet from the L3 header
+#define IPV4_DADDR_OFFSET 16
+#define IPV6_DADDR_OFFSET 24
+
+// prefix length of a full match of the cgroup ID followed by the IPv4-mapped prefix (::ffff:0:0/96)
+#define ISOLATION_CGROUP_ID_PREFIXLEN 64
+#define ISOLATION_IPV4_MAPPED_PREFIXLEN (ISOLATION_CGROUP_ID_PREFIXLEN + 96)
+
+SEC("cgroup_skb/egress")
+int cgroup_skb_egress_isolation(struct __sk_buff *skb) {
+    u64 cgroup_id = bpf_skb_cgroup_id(skb);
+
+    u64 *dropped = bpf_map_lookup_elem(&isolated_cgroups, &cgroup_id);
+    if (dropped == NULL) {
+        return CGROUP_SKB_PASS;
+    }
+
+    struct isolation_allow_key_t key = {
+        .cgroup_id = cgroup_id,
+    };
+
+    switch (skb->protocol) {
+    case htons(ETH_P_IP):
+        key.prefixlen = ISOLATION_IPV4_MAPPED_PREFIXLEN + 32;
+        key.addr[10] = 0xff;
+        key.addr[11] = 0xff;
+        if (bpf_skb_load_bytes(skb, IPV4_DADDR_OFFSET, &key.addr[12], 4) < 0) {
+            return CGROUP_SKB_DROP;
+        }
+
+        // always allow the loopback network
+        if (key.addr[12] == 127) {
+            return CGROUP_SKB_PASS;
+        }
+        break;
+    case htons(ETH_P_IPV6):
+        key.prefixlen = ISOLATION_CGROUP_ID_PREFIXLEN + 128;
+        if (bpf_skb_load_bytes(skb, IPV6_DADDR_OFFSET, &key.addr, 16) < 0) {
+            return CGROUP_SKB_DROP;
+        }
+
+        // always allow the loopback address
+        u64 *addr = (u64 *)key.addr;
+        if (addr[0] == 0 && addr[1] == cpu_to_be64(1)) {
+            return CGROUP_SKB_PASS;
+        }
+        break;
+    default:
+        return CGROUP_SKB_PASS;
+    }
+
+    if (bpf_map_lookup_elem(&isolation_allow_list, &key) != NULL) {
+        return CGROUP_SKB_PASS;
+    }
+
+    __sync_fetch_and_add(dropped, 1);
+
+    return CGROUP_SKB_DROP;
+}
+
+#endif
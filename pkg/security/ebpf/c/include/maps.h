@@ -63,6 +63,7 @@ BPF_LRU_MAP(exec_file_cache, u64, struct file_t, 4096)
 BPF_LRU_MAP(syscall_monitor, struct syscall_monitor_key_t, struct syscall_monitor_entry_t, 2048)
 BPF_LRU_MAP(syscall_table, struct syscall_table_key_t, u8, 50)
 BPF_LRU_MAP(kill_list, u32, u32, 32)
+BPF_HASH_MAP(isolated_cgroups, u64, u64, ISOLATION_MAX_CGROUPS)
 BPF_LRU_MAP(user_sessions, struct user_session_key_t, struct user_session_t, 1024)
 BPF_LRU_MAP(dentry_resolver_inputs, u64, struct dentry_resolver_input_t, 256)
 
@@ -90,6 +91,8 @@ BPF_PERCPU_ARRAY_MAP(is_new_kthread, u32, 1)
 BPF_PERCPU_ARRAY_MAP(syscalls_stats, struct syscalls_stats_t, EVENT_MAX)
 BPF_PERCPU_ARRAY_MAP(raw_packets, struct raw_packet_t, 1)
 
+BPF_MAP(isolation_allow_list, BPF_MAP_TYPE_LPM_TRIE, struct isolation_allow_key_t, u8, ISOLATION_MAX_ALLOWED_NETWORKS, 0, BPF_F_NO_PREALLOC)
+
 BPF_PROG_ARRAY(args_envs_progs, 3)
 BPF_PROG_ARRAY(dentry_resolver_kprobe_or_fentry_callbacks, EVENT_MAX)
 BPF_PROG_ARRAY(dentry_resolver_tracepoint_callbacks, EVENT_MAX)
@@ -94,4 +94,12 @@ struct raw_packet_t {
     char data[256];
 };
 
+// the cgroup ID is part of the key so that each isolated cgroup has its own allow list, IPv4 addresses are stored
+// as IPv4-mapped IPv6 addresses
+struct isolation_allow_key_t {
+    u32 prefixlen;
+    u64 cgroup_id;
+    u8 addr[16];
+} __attribute__((packed));
+
 #endif
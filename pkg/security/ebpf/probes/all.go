@@ -82,6 +82,7 @@ func AllProbes(fentry bool) []*manager.Probe {
 	allProbes = append(allProbes, getSyscallMonitorProbes()...)
 	allProbes = append(allProbes, getChdirProbes(fentry)...)
 	allProbes = append(allProbes, GetOnDemandProbes()...)
+	allProbes = append(allProbes, getNetworkIsolationProbes()...)
 
 	allProbes = append(allProbes,
 		&manager.Probe{
@@ -130,6 +131,9 @@ func AllMaps() []*manager.Map {
 		// Syscall stats monitor (inflight syscall)
 		{Name: "syscalls_stats_enabled"},
 		{Name: "kill_list"},
+		// Network isolation action
+		{Name: "isolated_cgroups"},
+		{Name: "isolation_allow_list"},
 		// used by raw packet filters
 		{Name: "packets"},
 	}
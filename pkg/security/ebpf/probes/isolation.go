@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package probes holds probes related files
+package probes
+
+import manager "github.com/DataDog/ebpf-manager"
+
+// NetworkIsolationFuncName is the name of the cgroup egress program used by the network isolation action
+const NetworkIsolationFuncName = "cgroup_skb_egress_isolation"
+
+// NetworkIsolationProbeID is the identification pair of the network isolation probe
+var NetworkIsolationProbeID = manager.ProbeIdentificationPair{
+	UID:          SecurityAgentUID,
+	EBPFFuncName: NetworkIsolationFuncName,
+}
+
+func getNetworkIsolationProbes() []*manager.Probe {
+	return []*manager.Probe{
+		{
+			// the cgroup path is set at runtime, the program is attached to the root of the cgroup v2 hierarchy
+			// so that it applies to all the cgroups of the host
+			ProbeIdentificationPair: NetworkIsolationProbeID,
+		},
+	}
+}
+
+// NetworkIsolationSelectors is the list of probes that should be activated when the network isolation action is enabled
+var NetworkIsolationSelectors = []manager.ProbesSelector{
+	&manager.ProbeSelector{ProbeIdentificationPair: NetworkIsolationProbeID},
+}
@@ -10,10 +10,12 @@ package events
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
 )
 
 // AgentContext serializes the agent context to JSON
@@ -22,6 +24,7 @@ type AgentContext struct {
 	RuleID        string            `json:"rule_id"`
 	RuleVersion   string            `json:"rule_version,omitempty"`
 	RuleActions   []json.RawMessage `json:"rule_actions,omitempty"`
+	RuleSequence  []SequenceStep    `json:"rule_sequence,omitempty"`
 	PolicyName    string            `json:"policy_name,omitempty"`
 	PolicyVersion string            `json:"policy_version,omitempty"`
 	Version       string            `json:"version,omitempty"`
@@ -30,6 +33,40 @@ type AgentContext struct {
 	Origin        string            `json:"origin,omitempty"`
 }
 
+// SequenceStep serializes a step of a matched sequence rule. Only the steps matched by previous events are included,
+// the event completing the sequence being the event itself.
+// easyjson:json
+type SequenceStep struct {
+	Index     int             `json:"index"`
+	MatchedAt time.Time       `json:"matched_at"`
+	Event     json.RawMessage `json:"event,omitempty"`
+}
+
+// NewSequenceSteps returns the serializable steps of the sequence rule matched by the event, the captured events of
+// the steps are serialized with the given function
+func NewSequenceSteps(ruleID eval.RuleID, steps []*model.MatchedSequenceStep, marshal func(*model.Event) ([]byte, error)) []SequenceStep {
+	var result []SequenceStep
+	for _, step := range steps {
+		if step.RuleID != ruleID {
+			continue
+		}
+		serialized := SequenceStep{
+			Index:     step.Index,
+			MatchedAt: step.MatchedAt,
+		}
+		if step.Event != nil {
+			data, err := marshal(step.Event)
+			if err != nil {
+				seclog.Errorf("failed to serialize the event matching the step %d of `%s`: %v", step.Index, ruleID, err)
+			} else {
+				serialized.Event = data
+			}
+		}
+		result = append(result, serialized)
+	}
+	return result
+}
+
 // BackendEvent - Rule event wrapper used to send an event to the backend
 // easyjson:json
 type BackendEvent struct {
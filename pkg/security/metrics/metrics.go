@@ -343,11 +343,20 @@ var (
 	// Tags: rule_id
 	MetricEnforcementProcessKilled = newRuntimeMetric(".enforcement.process_killed")
 	// MetricEnforcementRuleDisarmed is the name of the metric used to report that a rule was disarmed
-	// Tags: rule_id, disarmer_type ('executable', 'container')
+	// Tags: rule_id, action, disarmer_type ('executable', 'container')
 	MetricEnforcementRuleDisarmed = newRuntimeMetric(".enforcement.rule_disarmed")
 	// MetricEnforcementRuleRearmed is the name of the metric used to report that a rule was rearmed
-	// Tags: rule_id
+	// Tags: rule_id, action
 	MetricEnforcementRuleRearmed = newRuntimeMetric(".enforcement.rule_rearmed")
+	// MetricEnforcementNetworkIsolated is the name of the metric used to report the number of cgroups isolated from the network
+	// Tags: rule_id
+	MetricEnforcementNetworkIsolated = newRuntimeMetric(".enforcement.network_isolated")
+	// MetricEnforcementNetworkIsolationDropped is the name of the metric used to report the number of egress packets dropped by the network isolation
+	// Tags: -
+	MetricEnforcementNetworkIsolationDropped = newRuntimeMetric(".enforcement.network_isolation.dropped")
+	// MetricEnforcementFileQuarantined is the name of the metric used to report the number of files quarantined
+	// Tags: rule_id
+	MetricEnforcementFileQuarantined = newRuntimeMetric(".enforcement.file_quarantined")
 
 	// Others
 
@@ -9,6 +9,7 @@
 package probe
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -89,3 +90,141 @@ func (k *HashActionReport) PatchEvent(ev *serializers.EventSerializer) {
 	ev.FileEventSerializer.HashState = k.fileEvent.HashState.String()
 	ev.FileEventSerializer.Hashes = k.fileEvent.Hashes
 }
+
+// NetworkIsolateActionStatus defines the status of a network isolation action
+type NetworkIsolateActionStatus string
+
+const (
+	// NetworkIsolateActionStatusPerformed indicates the network isolation action was performed
+	NetworkIsolateActionStatusPerformed NetworkIsolateActionStatus = "performed"
+	// NetworkIsolateActionStatusAlreadyIsolated indicates the cgroup was already isolated by a previous action
+	NetworkIsolateActionStatusAlreadyIsolated NetworkIsolateActionStatus = "already_isolated"
+	// NetworkIsolateActionStatusRuleDisarmed indicates the network isolation action was skipped because the rule was disarmed
+	NetworkIsolateActionStatusRuleDisarmed NetworkIsolateActionStatus = "rule_disarmed"
+	// NetworkIsolateActionStatusError indicates the network isolation action failed
+	NetworkIsolateActionStatusError NetworkIsolateActionStatus = "error"
+)
+
+// NetworkIsolateActionReport defines a network isolation action report
+// easyjson:json
+type NetworkIsolateActionReport struct {
+	sync.RWMutex
+
+	Type         string                     `json:"type"`
+	Scope        string                     `json:"scope"`
+	Status       NetworkIsolateActionStatus `json:"status"`
+	DisarmerType string                     `json:"disarmer_type,omitempty"`
+	CGroupID     uint64                     `json:"cgroup_id,omitempty"`
+	Allow        []string                   `json:"allow,omitempty"`
+	IsolatedAt   *utils.EasyjsonTime        `json:"isolated_at,omitempty"`
+	Error        string                     `json:"error,omitempty"`
+
+	// internal
+	rule *rules.Rule
+}
+
+// IsResolved return if the action is resolved
+func (k *NetworkIsolateActionReport) IsResolved() bool {
+	// the isolation is applied synchronously
+	return true
+}
+
+// ToJSON marshal the action
+func (k *NetworkIsolateActionReport) ToJSON() ([]byte, error) {
+	k.Lock()
+	defer k.Unlock()
+
+	k.Type = rules.NetworkIsolateAction
+
+	data, err := utils.MarshalEasyJSON(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// IsMatchingRule returns true if this action report is targeted at the given rule ID
+func (k *NetworkIsolateActionReport) IsMatchingRule(ruleID eval.RuleID) bool {
+	k.RLock()
+	defer k.RUnlock()
+
+	return k.rule.ID == ruleID
+}
+
+// QuarantineActionStatus defines the status of a quarantine action
+type QuarantineActionStatus string
+
+const (
+	// QuarantineActionStatusPerformed indicates the file was moved to the quarantine directory
+	QuarantineActionStatusPerformed QuarantineActionStatus = "performed"
+	// QuarantineActionStatusRuleDisarmed indicates the quarantine action was skipped because the rule was disarmed
+	QuarantineActionStatusRuleDisarmed QuarantineActionStatus = "rule_disarmed"
+	// QuarantineActionStatusError indicates the quarantine action failed
+	QuarantineActionStatusError QuarantineActionStatus = "error"
+)
+
+// QuarantineActionReport defines a quarantine action report
+// easyjson:json
+type QuarantineActionReport struct {
+	sync.RWMutex
+
+	Type           string                 `json:"type"`
+	Path           string                 `json:"path"`
+	Status         QuarantineActionStatus `json:"status"`
+	DisarmerType   string                 `json:"disarmer_type,omitempty"`
+	QuarantinePath string                 `json:"quarantine_path,omitempty"`
+	SHA256         string                 `json:"sha256,omitempty"`
+	QuarantinedAt  *utils.EasyjsonTime    `json:"quarantined_at,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+
+	// internal
+	resolved bool
+	rule     *rules.Rule
+}
+
+// IsResolved return if the action is resolved
+func (k *QuarantineActionReport) IsResolved() bool {
+	k.RLock()
+	defer k.RUnlock()
+
+	return k.resolved
+}
+
+// ToJSON marshal the action
+func (k *QuarantineActionReport) ToJSON() ([]byte, error) {
+	k.Lock()
+	defer k.Unlock()
+
+	k.Type = rules.QuarantineAction
+
+	data, err := utils.MarshalEasyJSON(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// IsMatchingRule returns true if this action report is targeted at the given rule ID
+func (k *QuarantineActionReport) IsMatchingRule(ruleID eval.RuleID) bool {
+	k.RLock()
+	defer k.RUnlock()
+
+	return k.rule.ID == ruleID
+}
+
+// PatchEvent implements the EventSerializerPatcher interface
+func (k *QuarantineActionReport) PatchEvent(ev *serializers.EventSerializer) {
+	k.RLock()
+	defer k.RUnlock()
+
+	if ev.FileEventSerializer == nil || k.SHA256 == "" {
+		return
+	}
+
+	hash := model.SHA256.String() + ":" + k.SHA256
+	if !slices.Contains(ev.FileEventSerializer.Hashes, hash) {
+		ev.FileEventSerializer.Hashes = append(ev.FileEventSerializer.Hashes, hash)
+	}
+}
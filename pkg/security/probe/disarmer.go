@@ -0,0 +1,294 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux || windows
+
+// Package probe holds probe related files
+package probe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/jellydator/ttlcache/v3"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+)
+
+type disarmerState int
+
+const (
+	stopped disarmerState = iota
+	running
+)
+
+type disarmerType string
+
+const (
+	containerDisarmerType  disarmerType = "container"
+	executableDisarmerType disarmerType = "executable"
+)
+
+type ruleDisarmer struct {
+	sync.Mutex
+	disarmed        bool
+	container       disarmerParams
+	containerCache  *disarmerCache[string, bool]
+	executable      disarmerParams
+	executableCache *disarmerCache[string, bool]
+	// stats
+	disarmedCount map[disarmerType]int64
+	rearmedCount  int64
+}
+
+type disarmerParams struct {
+	enabled  bool
+	capacity uint64
+	period   time.Duration
+}
+
+type disarmerCache[K comparable, V any] struct {
+	*ttlcache.Cache[K, V]
+	capacity uint64
+}
+
+func newDisarmerCache[K comparable, V any](params *disarmerParams) *disarmerCache[K, V] {
+	cacheOpts := []ttlcache.Option[K, V]{
+		ttlcache.WithCapacity[K, V](params.capacity),
+	}
+
+	if params.period > 0 {
+		cacheOpts = append(cacheOpts, ttlcache.WithTTL[K, V](params.period))
+	}
+
+	return &disarmerCache[K, V]{
+		Cache:    ttlcache.New(cacheOpts...),
+		capacity: params.capacity,
+	}
+}
+
+func (c *disarmerCache[K, V]) flush() int {
+	c.DeleteExpired()
+	return c.Len()
+}
+
+func newRuleDisarmer(containerParams *disarmerParams, executableParams *disarmerParams) *ruleDisarmer {
+	kd := &ruleDisarmer{
+		disarmed:      false,
+		container:     *containerParams,
+		executable:    *executableParams,
+		disarmedCount: make(map[disarmerType]int64),
+	}
+
+	if kd.container.enabled {
+		kd.containerCache = newDisarmerCache[string, bool](containerParams)
+	}
+
+	if kd.executable.enabled {
+		kd.executableCache = newDisarmerCache[string, bool](executableParams)
+	}
+
+	return kd
+}
+
+func (rd *ruleDisarmer) allow(cache *disarmerCache[string, bool], key string, onDisarm func()) bool {
+	rd.Lock()
+	defer rd.Unlock()
+
+	if cache == nil {
+		return true
+	}
+
+	cache.DeleteExpired()
+	// if the key is not in the cache, check if the new key causes the number of keys to exceed the capacity
+	// otherwise, the key is already in the cache and cache.Get will update its TTL
+	if cache.Get(key) == nil {
+		alreadyAtCapacity := uint64(cache.Len()) >= cache.capacity
+		cache.Set(key, true, ttlcache.DefaultTTL)
+		if alreadyAtCapacity && !rd.disarmed {
+			rd.disarmed = true
+			onDisarm()
+		}
+	}
+
+	return !rd.disarmed
+}
+
+// rearm flushes the disarmer caches and re-arms the disarmer if the caches are empty, returns true if the disarmer
+// has been re-armed
+func (rd *ruleDisarmer) rearm() bool {
+	rd.Lock()
+	defer rd.Unlock()
+
+	var cLength, eLength int
+	if rd.container.enabled {
+		cLength = rd.containerCache.flush()
+	}
+	if rd.executable.enabled {
+		eLength = rd.executableCache.flush()
+	}
+	if rd.disarmed && cLength == 0 && eLength == 0 {
+		rd.disarmed = false
+		rd.rearmedCount++
+		return true
+	}
+	return false
+}
+
+// sendStats sends the disarmer statistics of the given rule
+func (rd *ruleDisarmer) sendStats(statsd statsd.ClientInterface, tags []string) {
+	rd.Lock()
+	defer rd.Unlock()
+
+	for disarmerType, count := range rd.disarmedCount {
+		if count > 0 {
+			tags := append([]string{"disarmer_type:" + string(disarmerType)}, tags...)
+			_ = statsd.Count(metrics.MetricEnforcementRuleDisarmed, count, tags, 1)
+			rd.disarmedCount[disarmerType] = 0
+		}
+	}
+	if rd.rearmedCount > 0 {
+		_ = statsd.Count(metrics.MetricEnforcementRuleRearmed, rd.rearmedCount, tags, 1)
+		rd.rearmedCount = 0
+	}
+}
+
+// getDisarmerParams returns the disarmer parameters of an action. The parameters defined by the action take precedence
+// over the ones of the configuration.
+func getDisarmerParams(def *rules.KillDisarmerDefinition, cfg *config.RuntimeSecurityConfig) (*disarmerParams, *disarmerParams) {
+	var containerParams, executableParams disarmerParams
+
+	if def != nil && def.Container != nil && def.Container.MaxAllowed > 0 {
+		containerParams.enabled = true
+		containerParams.capacity = uint64(def.Container.MaxAllowed)
+		containerParams.period = def.Container.Period
+	} else if cfg.EnforcementDisarmerContainerEnabled {
+		containerParams.enabled = true
+		containerParams.capacity = uint64(cfg.EnforcementDisarmerContainerMaxAllowed)
+		containerParams.period = cfg.EnforcementDisarmerContainerPeriod
+	}
+
+	if def != nil && def.Executable != nil && def.Executable.MaxAllowed > 0 {
+		executableParams.enabled = true
+		executableParams.capacity = uint64(def.Executable.MaxAllowed)
+		executableParams.period = def.Executable.Period
+	} else if cfg.EnforcementDisarmerExecutableEnabled {
+		executableParams.enabled = true
+		executableParams.capacity = uint64(cfg.EnforcementDisarmerExecutableMaxAllowed)
+		executableParams.period = cfg.EnforcementDisarmerExecutablePeriod
+	}
+
+	return &containerParams, &executableParams
+}
+
+// actionDisarmers holds the per rule disarmers of an enforcement action
+type actionDisarmers struct {
+	sync.Mutex
+
+	action    rules.ActionName
+	cfg       *config.RuntimeSecurityConfig
+	disarmers map[rules.RuleID]*ruleDisarmer
+}
+
+func newActionDisarmers(action rules.ActionName, cfg *config.RuntimeSecurityConfig) *actionDisarmers {
+	return &actionDisarmers{
+		action:    action,
+		cfg:       cfg,
+		disarmers: make(map[rules.RuleID]*ruleDisarmer),
+	}
+}
+
+// allow returns whether the action of the given rule can be performed for the given event. When the action is blocked,
+// the type of the disarmer that blocked it is returned.
+func (ad *actionDisarmers) allow(rule *rules.Rule, def *rules.KillDisarmerDefinition, ev *model.Event, entry *model.ProcessCacheEntry) (disarmerType, bool) {
+	var disarmer *ruleDisarmer
+	ad.Lock()
+	if disarmer = ad.disarmers[rule.ID]; disarmer == nil {
+		containerParams, executableParams := getDisarmerParams(def, ad.cfg)
+		disarmer = newRuleDisarmer(containerParams, executableParams)
+		ad.disarmers[rule.ID] = disarmer
+	}
+	ad.Unlock()
+
+	if disarmer.container.enabled {
+		if containerID := ev.FieldHandlers.ResolveContainerID(ev, ev.ContainerContext); containerID != "" {
+			if !disarmer.allow(disarmer.containerCache, containerID, func() {
+				disarmer.disarmedCount[containerDisarmerType]++
+				seclog.Warnf("disarming %s action of rule `%s` because more than %d different containers triggered it in the last %s", ad.action, rule.ID, disarmer.container.capacity, disarmer.container.period)
+			}) {
+				return containerDisarmerType, false
+			}
+		}
+	}
+
+	if disarmer.executable.enabled {
+		executable := entry.Process.FileEvent.PathnameStr
+		if !disarmer.allow(disarmer.executableCache, executable, func() {
+			disarmer.disarmedCount[executableDisarmerType]++
+			seclog.Warnf("disarmed %s action of rule `%s` because more than %d different executables triggered it in the last %s", ad.action, rule.ID, disarmer.executable.capacity, disarmer.executable.period)
+		}) {
+			return executableDisarmerType, false
+		}
+	}
+
+	return "", true
+}
+
+// flush flushes the disarmer caches and re-arms the rules that are not triggered anymore
+func (ad *actionDisarmers) flush() {
+	ad.Lock()
+	defer ad.Unlock()
+
+	for ruleID, disarmer := range ad.disarmers {
+		if disarmer.rearm() {
+			seclog.Infof("%s action of rule `%s` has been re-armed", ad.action, ruleID)
+		}
+	}
+}
+
+// reset drops all the disarmers
+func (ad *actionDisarmers) reset() {
+	ad.Lock()
+	defer ad.Unlock()
+
+	clear(ad.disarmers)
+}
+
+// sendStats sends the disarmer statistics of all the rules
+func (ad *actionDisarmers) sendStats(statsd statsd.ClientInterface) {
+	ad.Lock()
+	defer ad.Unlock()
+
+	for ruleID, disarmer := range ad.disarmers {
+		disarmer.sendStats(statsd, []string{
+			"rule_id:" + string(ruleID),
+			"action:" + ad.action,
+		})
+	}
+}
+
+// startFlushing periodically flushes the disarmers until the context is done
+func (ad *actionDisarmers) startFlushing(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(disarmerCacheFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ad.flush()
+			}
+		}
+	}()
+}
@@ -0,0 +1,403 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package probe holds probe related files
+package probe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/containerutils"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+const (
+	quarantineQueueSize = 64
+	quarantineDirMode   = 0700
+	quarantineFileMode  = 0400
+)
+
+// quarantineRequest holds a pending quarantine
+type quarantineRequest struct {
+	report *QuarantineActionReport
+	// root is a handle on the root directory of the process, nil when the path is resolved from the host root
+	root        *os.File
+	path        string
+	containerID containerutils.ContainerID
+}
+
+// sourcePath returns the path of the file to quarantine, resolved from the root of the process
+func (req *quarantineRequest) sourcePath() string {
+	if req.root == nil {
+		return req.path
+	}
+	return filepath.Join(fmt.Sprintf("/proc/self/fd/%d", req.root.Fd()), req.path)
+}
+
+func (req *quarantineRequest) close() {
+	if req.root != nil {
+		req.root.Close()
+	}
+}
+
+// quarantineMetadata is stored next to each quarantined file so that it can be restored
+type quarantineMetadata struct {
+	Path          string    `json:"path"`
+	ContainerID   string    `json:"container_id,omitempty"`
+	RuleID        string    `json:"rule_id"`
+	SHA256        string    `json:"sha256"`
+	QuarantinedAt time.Time `json:"quarantined_at"`
+}
+
+// FileQuarantiner defines a file quarantiner structure. Files are copied to the quarantine directory, named after
+// their SHA256 digest, and then removed from their original location.
+type FileQuarantiner struct {
+	sync.Mutex
+
+	cfg              *config.Config
+	enabled          bool
+	directory        string
+	sourceAllowed    []string
+	binariesExcluded []*eval.Glob
+	disarmers        *actionDisarmers
+	queue            chan *quarantineRequest
+
+	perRuleStatsLock sync.Mutex
+	perRuleStats     map[rules.RuleID]int64
+}
+
+// NewFileQuarantiner returns a new FileQuarantiner
+func NewFileQuarantiner(cfg *config.Config) (*FileQuarantiner, error) {
+	q := &FileQuarantiner{
+		cfg:           cfg,
+		enabled:       true,
+		directory:     cfg.RuntimeSecurity.EnforcementQuarantineDirectory,
+		sourceAllowed: cfg.RuntimeSecurity.EnforcementRuleSourceAllowed,
+		disarmers:     newActionDisarmers(rules.QuarantineAction, cfg.RuntimeSecurity),
+		queue:         make(chan *quarantineRequest, quarantineQueueSize),
+		perRuleStats:  make(map[rules.RuleID]int64),
+	}
+
+	binaries := append(binariesExcluded, cfg.RuntimeSecurity.EnforcementBinaryExcluded...)
+	for _, str := range binaries {
+		glob, err := eval.NewGlob(str, false, false)
+		if err != nil {
+			return nil, err
+		}
+		q.binariesExcluded = append(q.binariesExcluded, glob)
+	}
+
+	return q, nil
+}
+
+// Start starts the go routines responsible for quarantining the files and flushing the disarmer caches
+func (q *FileQuarantiner) Start(ctx context.Context, wg *sync.WaitGroup) {
+	if !q.cfg.RuntimeSecurity.EnforcementEnabled {
+		return
+	}
+
+	q.disarmers.startFlushing(ctx, wg)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case req := <-q.queue:
+				q.quarantine(req)
+			}
+		}
+	}()
+}
+
+// SetState sets the state - enabled or disabled - for the file quarantiner
+func (q *FileQuarantiner) SetState(enabled bool) {
+	q.Lock()
+	defer q.Unlock()
+
+	q.enabled = enabled
+}
+
+func (q *FileQuarantiner) isEnabled() bool {
+	q.Lock()
+	defer q.Unlock()
+
+	return q.enabled
+}
+
+func (q *FileQuarantiner) isRuleAllowed(rule *rules.Rule) bool {
+	return slices.Contains(q.sourceAllowed, rule.Policy.Source)
+}
+
+// getQuarantineTarget returns the file targeted by the quarantine action for the given event
+func getQuarantineTarget(ev *model.Event) *model.FileEvent {
+	switch ev.GetEventType() {
+	case model.FileOpenEventType:
+		return &ev.Open.File
+	case model.ExecEventType:
+		return &ev.Exec.FileEvent
+	case model.FileChmodEventType:
+		return &ev.Chmod.File
+	case model.FileChownEventType:
+		return &ev.Chown.File
+	case model.FileUtimesEventType:
+		return &ev.Utimes.File
+	case model.FileRenameEventType:
+		return &ev.Rename.New
+	case model.FileLinkEventType:
+		return &ev.Link.Target
+	default:
+		return nil
+	}
+}
+
+// QuarantineAndReport quarantines and report, returns true if a quarantine was requested
+func (q *FileQuarantiner) QuarantineAndReport(def *rules.QuarantineDefinition, rule *rules.Rule, ev *model.Event) bool {
+	if !q.isRuleAllowed(rule) {
+		seclog.Warnf("unable to quarantine, the source is not allowed: %v", rule)
+		return false
+	}
+
+	file := getQuarantineTarget(ev)
+	if file == nil {
+		return false
+	}
+
+	entry, exists := ev.ResolveProcessCacheEntry(nil)
+	if !exists {
+		return false
+	}
+
+	path := ev.FieldHandlers.ResolveFilePath(ev, file)
+	report := &QuarantineActionReport{
+		Path: path,
+		rule: rule,
+	}
+	ev.ActionReports = append(ev.ActionReports, report)
+
+	if dt, allowed := q.disarmers.allow(rule, def.Disarmer, ev, entry); !allowed {
+		seclog.Warnf("skipping quarantine action of rule `%s` because it has been disarmed", rule.ID)
+		report.Status = QuarantineActionStatusRuleDisarmed
+		report.DisarmerType = string(dt)
+		report.resolved = true
+		return false
+	}
+
+	if err := q.isQuarantineAllowed(path); err != nil {
+		seclog.Warnf("unable to quarantine: %v", err)
+		report.Status = QuarantineActionStatusError
+		report.Error = err.Error()
+		report.resolved = true
+		return false
+	}
+
+	// the root of the process is resolved now, the process may have exited by the time the file is quarantined
+	root, err := openProcessRoot(ev.ProcessContext.Pid, entry.ContainerID)
+	if err != nil {
+		seclog.Warnf("unable to quarantine: %v", err)
+		report.Status = QuarantineActionStatusError
+		report.Error = err.Error()
+		report.resolved = true
+		return false
+	}
+
+	req := &quarantineRequest{
+		report:      report,
+		root:        root,
+		path:        path,
+		containerID: entry.ContainerID,
+	}
+
+	select {
+	case q.queue <- req:
+	default:
+		req.close()
+		report.Status = QuarantineActionStatusError
+		report.Error = "too many pending quarantine requests"
+		report.resolved = true
+		return false
+	}
+
+	return true
+}
+
+func (q *FileQuarantiner) isQuarantineAllowed(path string) error {
+	if !q.isEnabled() {
+		return errors.New("the enforcement capability is disabled")
+	}
+
+	if path == "" || !filepath.IsAbs(path) {
+		return fmt.Errorf("invalid path `%s`", path)
+	}
+
+	if slices.ContainsFunc(q.binariesExcluded, func(glob *eval.Glob) bool {
+		return glob.Matches(path)
+	}) {
+		return fmt.Errorf("file `%s` is protected", path)
+	}
+
+	if path == q.directory || strings.HasPrefix(path, q.directory+"/") {
+		return fmt.Errorf("file `%s` is already quarantined", path)
+	}
+
+	return nil
+}
+
+// openProcessRoot returns a handle on the root directory of the given process, so that the files of containers are
+// resolved in their mount namespace. Files of host processes that already exited are resolved from the host root.
+func openProcessRoot(pid uint32, containerID containerutils.ContainerID) (*os.File, error) {
+	root, err := os.OpenFile(utils.ProcRootPath(pid), unix.O_PATH|unix.O_DIRECTORY, 0)
+	if err == nil {
+		return root, nil
+	}
+	if containerID != "" {
+		return nil, fmt.Errorf("failed to resolve the root of container `%s`: %w", containerID, err)
+	}
+	return nil, nil
+}
+
+func (q *FileQuarantiner) quarantine(req *quarantineRequest) {
+	defer req.close()
+	report := req.report
+
+	digest, quarantinePath, err := q.moveToQuarantine(req)
+
+	report.Lock()
+	defer report.Unlock()
+
+	report.resolved = true
+	if err != nil {
+		seclog.Warnf("failed to quarantine `%s`: %v", req.path, err)
+		report.Status = QuarantineActionStatusError
+		report.Error = err.Error()
+		return
+	}
+
+	report.Status = QuarantineActionStatusPerformed
+	report.SHA256 = digest
+	report.QuarantinePath = quarantinePath
+	report.QuarantinedAt = utils.NewEasyjsonTimeIfNotZero(time.Now())
+
+	q.perRuleStatsLock.Lock()
+	q.perRuleStats[report.rule.ID]++
+	q.perRuleStatsLock.Unlock()
+}
+
+// moveToQuarantine copies the file to the quarantine directory and removes the original one. The original file is
+// accessed through the root of the process captured when the action fired.
+func (q *FileQuarantiner) moveToQuarantine(req *quarantineRequest) (string, string, error) {
+	if err := os.MkdirAll(q.directory, quarantineDirMode); err != nil {
+		return "", "", fmt.Errorf("failed to create the quarantine directory: %w", err)
+	}
+
+	src := req.sourcePath()
+	f, err := os.OpenFile(src, os.O_RDONLY|syscall.O_NOFOLLOW, 0)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", "", fmt.Errorf("`%s` is not a regular file", req.path)
+	}
+
+	tmp, err := os.CreateTemp(q.directory, ".quarantine-*")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hasher), f); err != nil {
+		tmp.Close()
+		return "", "", err
+	}
+	if err := tmp.Chmod(quarantineFileMode); err != nil {
+		tmp.Close()
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", err
+	}
+
+	digest := hex.EncodeToString(hasher.Sum(nil))
+	quarantinePath := filepath.Join(q.directory, digest)
+	if err := os.Rename(tmp.Name(), quarantinePath); err != nil {
+		return "", "", err
+	}
+
+	metadata, err := json.Marshal(quarantineMetadata{
+		Path:          req.path,
+		ContainerID:   string(req.containerID),
+		RuleID:        req.report.rule.ID,
+		SHA256:        digest,
+		QuarantinedAt: time.Now(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(quarantinePath+".json", metadata, quarantineFileMode); err != nil {
+		seclog.Debugf("failed to write the quarantine metadata of `%s`: %v", req.path, err)
+	}
+
+	// the copy is safe, we can now remove the original file
+	if err := os.Remove(src); err != nil {
+		return digest, quarantinePath, fmt.Errorf("file copied to `%s` but failed to remove the original file: %w", quarantinePath, err)
+	}
+
+	return digest, quarantinePath, nil
+}
+
+// Reset the statistics and the disarmers of the file quarantiner
+func (q *FileQuarantiner) Reset() {
+	q.perRuleStatsLock.Lock()
+	clear(q.perRuleStats)
+	q.perRuleStatsLock.Unlock()
+	q.disarmers.reset()
+}
+
+// SendStats sends the quarantine statistics to Datadog
+func (q *FileQuarantiner) SendStats(statsd statsd.ClientInterface) {
+	q.perRuleStatsLock.Lock()
+	for ruleID, count := range q.perRuleStats {
+		if count > 0 {
+			_ = statsd.Count(metrics.MetricEnforcementFileQuarantined, count, []string{"rule_id:" + string(ruleID)}, 1)
+			q.perRuleStats[ruleID] = 0
+		}
+	}
+	q.perRuleStatsLock.Unlock()
+
+	q.disarmers.sendStats(statsd)
+}
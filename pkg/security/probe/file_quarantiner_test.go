@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package probe holds probe related files
+package probe
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func TestFileQuarantinerExclusion(t *testing.T) {
+	q, err := NewFileQuarantiner(
+		&config.Config{
+			RuntimeSecurity: &config.RuntimeSecurityConfig{
+				EnforcementBinaryExcluded:      []string{"/usr/sbin/*"},
+				EnforcementQuarantineDirectory: "/var/run/datadog-agent/runtime-security/quarantine",
+			},
+		},
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, q.isQuarantineAllowed("/tmp/malware"))
+	assert.Error(t, q.isQuarantineAllowed("/usr/sbin/sshd"))
+	assert.Error(t, q.isQuarantineAllowed("/var/run/datadog-agent/runtime-security/quarantine/abc"))
+	assert.Error(t, q.isQuarantineAllowed("relative/path"))
+}
+
+func TestFileQuarantinerExitedProcess(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewFileQuarantiner(
+		&config.Config{
+			RuntimeSecurity: &config.RuntimeSecurityConfig{
+				EnforcementQuarantineDirectory: filepath.Join(dir, "quarantine"),
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "malware")
+	require.NoError(t, os.WriteFile(path, []byte("malware"), 0700))
+
+	// the root of the process is captured when the action fires
+	cmd := exec.Command("sleep", "60")
+	require.NoError(t, cmd.Start())
+	root, err := openProcessRoot(uint32(cmd.Process.Pid), "container")
+	require.NoError(t, err)
+	require.NoError(t, cmd.Process.Kill())
+	_ = cmd.Wait()
+
+	_, err = openProcessRoot(uint32(cmd.Process.Pid), "container")
+	assert.Error(t, err)
+
+	req := &quarantineRequest{
+		report: &QuarantineActionReport{
+			Path: path,
+			rule: &rules.Rule{Rule: &eval.Rule{ID: "test_rule"}},
+		},
+		root: root,
+		path: path,
+	}
+	q.quarantine(req)
+
+	assert.Equal(t, QuarantineActionStatusPerformed, req.report.Status, req.report.Error)
+	assert.NoFileExists(t, path)
+	assert.FileExists(t, req.report.QuarantinePath)
+
+	// files of host processes that already exited are resolved from the host root
+	root, err = openProcessRoot(uint32(cmd.Process.Pid), "")
+	assert.NoError(t, err)
+	assert.Nil(t, root)
+}
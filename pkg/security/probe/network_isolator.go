@@ -0,0 +1,425 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package probe holds probe related files
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	manager "github.com/DataDog/ebpf-manager"
+	lib "github.com/cilium/ebpf"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/probe/managerhelper"
+	cgroupModel "github.com/DataDog/datadog-agent/pkg/security/resolvers/cgroup/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/containerutils"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+const (
+	// isolationCGroupIDPrefixLen is the length of the cgroup ID part of the allow list LPM keys, in bits
+	isolationCGroupIDPrefixLen = 64
+	// isolationIPv4MappedPrefixLen is the length of the IPv4-mapped IPv6 prefix (::ffff:0:0/96), in bits
+	isolationIPv4MappedPrefixLen = 96
+	// isolationAllowKeySize is the size of the isolation_allow_key_t structure
+	isolationAllowKeySize = 4 + 8 + 16
+)
+
+var errNetworkIsolationDisabled = errors.New("network isolation is disabled, see runtime_security_config.enforcement.network_isolation.enabled")
+
+// isolationMap defines the operations used on the maps of the network isolation program, *lib.Map implements it
+type isolationMap interface {
+	Put(key, value interface{}) error
+	Delete(key interface{}) error
+	Lookup(key, valueOut interface{}) error
+}
+
+// isolatedCGroup holds the state of a cgroup isolated from the network. A cgroup isolated with the container scope
+// is released when the container is deleted, otherwise it is released when all the processes that triggered its
+// isolation exited.
+type isolatedCGroup struct {
+	cgroupID    uint64
+	containerID containerutils.ContainerID
+	scope       string
+	pids        map[uint32]struct{}
+	allowKeys   [][]byte
+}
+
+// NetworkIsolator defines a network isolator structure. It isolates cgroups from the network using a cgroup egress
+// program attached to the root of the cgroup v2 hierarchy.
+type NetworkIsolator struct {
+	sync.Mutex
+
+	cfg           *config.Config
+	enabled       bool
+	sourceAllowed []string
+	disarmers     *actionDisarmers
+
+	isolatedCGroupsMap isolationMap
+	allowListMap       isolationMap
+	isolated           map[uint64]*isolatedCGroup
+	isolatedPIDs       map[uint32]*isolatedCGroup
+
+	perRuleStatsLock sync.Mutex
+	perRuleStats     map[rules.RuleID]int64
+}
+
+// NewNetworkIsolator returns a new NetworkIsolator
+func NewNetworkIsolator(cfg *config.Config) *NetworkIsolator {
+	return &NetworkIsolator{
+		cfg:           cfg,
+		enabled:       true,
+		sourceAllowed: cfg.RuntimeSecurity.EnforcementRuleSourceAllowed,
+		disarmers:     newActionDisarmers(rules.NetworkIsolateAction, cfg.RuntimeSecurity),
+		isolated:      make(map[uint64]*isolatedCGroup),
+		isolatedPIDs:  make(map[uint32]*isolatedCGroup),
+		perRuleStats:  make(map[rules.RuleID]int64),
+	}
+}
+
+// getCGroup2Root returns the root of the cgroup v2 hierarchy, the network isolation program is attached to it
+func getCGroup2Root() string {
+	// hybrid hierarchy, the cgroup v2 hierarchy is mounted in the unified directory
+	if _, err := os.Stat(utils.CgroupSysPath("unified", "", "cgroup.controllers")); err == nil {
+		return utils.CgroupSysPath("unified", "", "")
+	}
+	return utils.CgroupSysPath("", "", "")
+}
+
+// IsEnabled returns true if the network isolation program should be loaded
+func (n *NetworkIsolator) IsEnabled() bool {
+	return n.cfg.RuntimeSecurity.EnforcementEnabled && n.cfg.RuntimeSecurity.EnforcementNetworkIsolationEnabled
+}
+
+// Init resolves the maps used by the network isolation program
+func (n *NetworkIsolator) Init(m *manager.Manager) error {
+	if !n.IsEnabled() {
+		return nil
+	}
+
+	isolatedCGroupsMap, err := managerhelper.Map(m, "isolated_cgroups")
+	if err != nil {
+		return err
+	}
+	allowListMap, err := managerhelper.Map(m, "isolation_allow_list")
+	if err != nil {
+		return err
+	}
+	n.isolatedCGroupsMap, n.allowListMap = isolatedCGroupsMap, allowListMap
+	return nil
+}
+
+// Start starts the go routine responsible for flushing the disarmer caches
+func (n *NetworkIsolator) Start(ctx context.Context, wg *sync.WaitGroup) {
+	if !n.IsEnabled() {
+		return
+	}
+	n.disarmers.startFlushing(ctx, wg)
+}
+
+// SetState sets the state - enabled or disabled - for the network isolator
+func (n *NetworkIsolator) SetState(enabled bool) {
+	n.Lock()
+	defer n.Unlock()
+
+	n.enabled = enabled
+}
+
+func (n *NetworkIsolator) isRuleAllowed(rule *rules.Rule) bool {
+	return slices.Contains(n.sourceAllowed, rule.Policy.Source)
+}
+
+// newIsolationAllowKey returns the LPM key matching the given network for the given cgroup, the layout must match
+// the one of the isolation_allow_key_t structure
+func newIsolationAllowKey(cgroupID uint64, ipNet *net.IPNet) []byte {
+	ones, _ := ipNet.Mask.Size()
+	prefixLen := isolationCGroupIDPrefixLen + ones
+	if ipNet.IP.To4() != nil {
+		prefixLen += isolationIPv4MappedPrefixLen
+	}
+
+	key := make([]byte, isolationAllowKeySize)
+	binary.NativeEndian.PutUint32(key[0:4], uint32(prefixLen))
+	binary.NativeEndian.PutUint64(key[4:12], cgroupID)
+	copy(key[12:], ipNet.IP.To16())
+
+	return key
+}
+
+// isolate isolates the given cgroup, the pid is the process that triggered the isolation. It returns true if the
+// cgroup was already isolated.
+func (n *NetworkIsolator) isolate(cgroupID uint64, containerID containerutils.ContainerID, scope string, pid uint32, allow []string) (bool, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	if !n.enabled {
+		return false, errors.New("the enforcement capability is disabled")
+	}
+
+	if n.isolatedCGroupsMap == nil || n.allowListMap == nil {
+		return false, errNetworkIsolationDisabled
+	}
+
+	if entry, exists := n.isolated[cgroupID]; exists {
+		switch {
+		case scope == "container":
+			// the isolation now lasts until the container is deleted
+			entry.scope = scope
+			n.clearPIDs(entry)
+		case entry.scope == "process":
+			n.addPID(entry, pid)
+		}
+		return true, nil
+	}
+
+	entry := &isolatedCGroup{
+		cgroupID:    cgroupID,
+		containerID: containerID,
+		scope:       scope,
+		pids:        make(map[uint32]struct{}),
+	}
+
+	// push the allow list first so that allowed traffic is never dropped
+	for _, allowed := range allow {
+		ipNet, err := rules.ParseIsolationAllowedNetwork(allowed)
+		if err != nil {
+			n.releaseEntry(entry)
+			return false, err
+		}
+
+		key := newIsolationAllowKey(cgroupID, ipNet)
+		if err := n.allowListMap.Put(key, uint8(1)); err != nil {
+			n.releaseEntry(entry)
+			return false, fmt.Errorf("failed to push allowed network %s: %w", allowed, err)
+		}
+		entry.allowKeys = append(entry.allowKeys, key)
+	}
+
+	if err := n.isolatedCGroupsMap.Put(cgroupID, uint64(0)); err != nil {
+		n.releaseEntry(entry)
+		return false, fmt.Errorf("failed to isolate cgroup %d: %w", cgroupID, err)
+	}
+	n.isolated[cgroupID] = entry
+	if scope == "process" {
+		n.addPID(entry, pid)
+	}
+
+	return false, nil
+}
+
+// addPID records a process that triggered the isolation of a cgroup, the lock must be held
+func (n *NetworkIsolator) addPID(entry *isolatedCGroup, pid uint32) {
+	if previous := n.isolatedPIDs[pid]; previous != nil && previous != entry {
+		// the pid was reused, its previous process exited
+		delete(previous.pids, pid)
+	}
+	entry.pids[pid] = struct{}{}
+	n.isolatedPIDs[pid] = entry
+}
+
+// clearPIDs forgets the processes that triggered the isolation of a cgroup, the lock must be held
+func (n *NetworkIsolator) clearPIDs(entry *isolatedCGroup) {
+	for pid := range entry.pids {
+		if n.isolatedPIDs[pid] == entry {
+			delete(n.isolatedPIDs, pid)
+		}
+	}
+	clear(entry.pids)
+}
+
+// releaseEntry removes the kernel state of an isolated cgroup, the lock must be held
+func (n *NetworkIsolator) releaseEntry(entry *isolatedCGroup) {
+	if err := n.isolatedCGroupsMap.Delete(entry.cgroupID); err != nil && !errors.Is(err, lib.ErrKeyNotExist) {
+		seclog.Debugf("failed to release the network isolation of cgroup %d: %v", entry.cgroupID, err)
+	}
+	for _, key := range entry.allowKeys {
+		_ = n.allowListMap.Delete(key)
+	}
+	n.clearPIDs(entry)
+	if n.isolated[entry.cgroupID] == entry {
+		delete(n.isolated, entry.cgroupID)
+		seclog.Infof("network isolation of cgroup %d released", entry.cgroupID)
+	}
+}
+
+// Release releases the network isolation of the given cgroup
+func (n *NetworkIsolator) Release(cgroupID uint64) error {
+	n.Lock()
+	defer n.Unlock()
+
+	entry, exists := n.isolated[cgroupID]
+	if !exists {
+		return fmt.Errorf("cgroup %d isn't isolated", cgroupID)
+	}
+	n.releaseEntry(entry)
+	return nil
+}
+
+// ReleaseAll releases the network isolation of all the isolated cgroups
+func (n *NetworkIsolator) ReleaseAll() {
+	n.Lock()
+	defer n.Unlock()
+
+	for _, entry := range n.isolated {
+		n.releaseEntry(entry)
+	}
+}
+
+// IsolateAndReport isolates and report, returns true if the isolation was performed
+func (n *NetworkIsolator) IsolateAndReport(def *rules.NetworkIsolateDefinition, rule *rules.Rule, ev *model.Event) bool {
+	if !n.isRuleAllowed(rule) {
+		seclog.Warnf("unable to isolate, the source is not allowed: %v", rule)
+		return false
+	}
+
+	entry, exists := ev.ResolveProcessCacheEntry(nil)
+	if !exists {
+		return false
+	}
+
+	scope := "process"
+	switch def.Scope {
+	case "container", "process":
+		scope = def.Scope
+	}
+
+	report := &NetworkIsolateActionReport{
+		Scope: scope,
+		Allow: def.Allow,
+		rule:  rule,
+	}
+	ev.ActionReports = append(ev.ActionReports, report)
+
+	if dt, allowed := n.disarmers.allow(rule, def.Disarmer, ev, entry); !allowed {
+		seclog.Warnf("skipping network_isolate action of rule `%s` because it has been disarmed", rule.ID)
+		report.Status = NetworkIsolateActionStatusRuleDisarmed
+		report.DisarmerType = string(dt)
+		return false
+	}
+
+	if scope == "container" && entry.ContainerID == "" {
+		report.Status = NetworkIsolateActionStatusError
+		report.Error = "the process doesn't run in a container"
+		return false
+	}
+
+	// on cgroup v2, the ID of a cgroup is the inode of its directory in the cgroup filesystem
+	cgroupID := entry.Process.CGroup.CGroupFile.Inode
+	if cgroupID == 0 {
+		report.Status = NetworkIsolateActionStatusError
+		report.Error = "unable to resolve the cgroup of the process"
+		return false
+	}
+	report.CGroupID = cgroupID
+
+	alreadyIsolated, err := n.isolate(cgroupID, entry.ContainerID, scope, entry.Pid, def.Allow)
+	if err != nil {
+		seclog.Warnf("unable to isolate cgroup %d: %v", cgroupID, err)
+		report.Status = NetworkIsolateActionStatusError
+		report.Error = err.Error()
+		return false
+	}
+
+	if alreadyIsolated {
+		report.Status = NetworkIsolateActionStatusAlreadyIsolated
+		return true
+	}
+
+	report.Status = NetworkIsolateActionStatusPerformed
+	report.IsolatedAt = utils.NewEasyjsonTimeIfNotZero(time.Now())
+
+	n.perRuleStatsLock.Lock()
+	n.perRuleStats[rule.ID]++
+	n.perRuleStatsLock.Unlock()
+
+	return true
+}
+
+// HandleCGroupDeleted releases the isolation of the cgroups of a deleted container, whatever their scope
+func (n *NetworkIsolator) HandleCGroupDeleted(cgce *cgroupModel.CacheEntry) {
+	n.Lock()
+	defer n.Unlock()
+
+	if cgce.ContainerID == "" {
+		return
+	}
+
+	for _, entry := range n.isolated {
+		if entry.containerID == cgce.ContainerID {
+			n.releaseEntry(entry)
+		}
+	}
+}
+
+// HandleProcessExited releases the isolation of the cgroups isolated with the process scope once all the processes
+// that triggered their isolation exited
+func (n *NetworkIsolator) HandleProcessExited(pid uint32) {
+	n.Lock()
+	defer n.Unlock()
+
+	entry, exists := n.isolatedPIDs[pid]
+	if !exists {
+		return
+	}
+	delete(n.isolatedPIDs, pid)
+	delete(entry.pids, pid)
+
+	if entry.scope == "process" && len(entry.pids) == 0 {
+		n.releaseEntry(entry)
+	}
+}
+
+// Reset the statistics and the disarmers of the network isolator. Isolated cgroups remain isolated.
+func (n *NetworkIsolator) Reset() {
+	n.perRuleStatsLock.Lock()
+	clear(n.perRuleStats)
+	n.perRuleStatsLock.Unlock()
+	n.disarmers.reset()
+}
+
+// SendStats sends the network isolation statistics to Datadog
+func (n *NetworkIsolator) SendStats(statsd statsd.ClientInterface) {
+	n.perRuleStatsLock.Lock()
+	for ruleID, count := range n.perRuleStats {
+		if count > 0 {
+			_ = statsd.Count(metrics.MetricEnforcementNetworkIsolated, count, []string{"rule_id:" + string(ruleID)}, 1)
+			n.perRuleStats[ruleID] = 0
+		}
+	}
+	n.perRuleStatsLock.Unlock()
+
+	n.disarmers.sendStats(statsd)
+
+	n.Lock()
+	defer n.Unlock()
+
+	if n.isolatedCGroupsMap == nil || len(n.isolated) == 0 {
+		return
+	}
+
+	var dropped, count uint64
+	for cgroupID := range n.isolated {
+		if err := n.isolatedCGroupsMap.Lookup(cgroupID, &count); err == nil {
+			dropped += count
+		}
+	}
+	_ = statsd.Gauge(metrics.MetricEnforcementNetworkIsolationDropped, float64(dropped), []string{}, 1.0)
+}
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package probe holds probe related files
+package probe
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	lib "github.com/cilium/ebpf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	cgroupModel "github.com/DataDog/datadog-agent/pkg/security/resolvers/cgroup/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/containerutils"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func TestNewIsolationAllowKey(t *testing.T) {
+	t.Run("ipv4", func(t *testing.T) {
+		ipNet, err := rules.ParseIsolationAllowedNetwork("10.0.0.0/8")
+		assert.NoError(t, err)
+
+		key := newIsolationAllowKey(42, ipNet)
+		assert.Len(t, key, isolationAllowKeySize)
+		assert.Equal(t, uint32(64+96+8), binary.NativeEndian.Uint32(key[0:4]))
+		assert.Equal(t, uint64(42), binary.NativeEndian.Uint64(key[4:12]))
+		assert.Equal(t, []byte(net.ParseIP("10.0.0.0").To16()), key[12:])
+	})
+
+	t.Run("ipv6", func(t *testing.T) {
+		ipNet, err := rules.ParseIsolationAllowedNetwork("fd00::1")
+		assert.NoError(t, err)
+
+		key := newIsolationAllowKey(42, ipNet)
+		assert.Equal(t, uint32(64+128), binary.NativeEndian.Uint32(key[0:4]))
+		assert.Equal(t, []byte(net.ParseIP("fd00::1")), key[12:])
+	})
+}
+
+// fakeIsolationMap is an in memory isolationMap
+type fakeIsolationMap map[string]interface{}
+
+func fakeIsolationMapKey(key interface{}) string {
+	if b, ok := key.([]byte); ok {
+		return string(b)
+	}
+	return string(binary.NativeEndian.AppendUint64(nil, key.(uint64)))
+}
+
+func (m fakeIsolationMap) Put(key, value interface{}) error {
+	m[fakeIsolationMapKey(key)] = value
+	return nil
+}
+
+func (m fakeIsolationMap) Delete(key interface{}) error {
+	k := fakeIsolationMapKey(key)
+	if _, ok := m[k]; !ok {
+		return lib.ErrKeyNotExist
+	}
+	delete(m, k)
+	return nil
+}
+
+func (m fakeIsolationMap) Lookup(key, valueOut interface{}) error {
+	value, ok := m[fakeIsolationMapKey(key)]
+	if !ok {
+		return lib.ErrKeyNotExist
+	}
+	*valueOut.(*uint64) = value.(uint64)
+	return nil
+}
+
+// isolationFieldHandlers resolves the process cache entry set on the event
+type isolationFieldHandlers struct {
+	model.FakeFieldHandlers
+}
+
+func (fh *isolationFieldHandlers) ResolveProcessCacheEntry(ev *model.Event, _ func(*model.ProcessCacheEntry, error)) (*model.ProcessCacheEntry, bool) {
+	return ev.ProcessCacheEntry, ev.ProcessCacheEntry != nil
+}
+
+func newTestNetworkIsolator() (*NetworkIsolator, fakeIsolationMap, fakeIsolationMap) {
+	n := NewNetworkIsolator(&config.Config{
+		RuntimeSecurity: &config.RuntimeSecurityConfig{
+			EnforcementRuleSourceAllowed: []string{"test"},
+		},
+	})
+	isolatedCGroups, allowList := fakeIsolationMap{}, fakeIsolationMap{}
+	n.isolatedCGroupsMap, n.allowListMap = isolatedCGroups, allowList
+	return n, isolatedCGroups, allowList
+}
+
+func newIsolationTestEvent(pid uint32, cgroupID uint64, containerID containerutils.ContainerID) *model.Event {
+	entry := &model.ProcessCacheEntry{}
+	entry.Pid = pid
+	entry.ContainerID = containerID
+	entry.CGroup.CGroupFile.Inode = cgroupID
+
+	ev := model.NewFakeEvent()
+	ev.FieldHandlers = &isolationFieldHandlers{}
+	ev.ProcessCacheEntry = entry
+	return ev
+}
+
+func isolate(t *testing.T, n *NetworkIsolator, def *rules.NetworkIsolateDefinition, ev *model.Event) *NetworkIsolateActionReport {
+	rule := &rules.Rule{
+		PolicyRule: &rules.PolicyRule{Policy: &rules.Policy{Source: "test"}},
+		Rule:       &eval.Rule{ID: "isolate"},
+	}
+	n.IsolateAndReport(def, rule, ev)
+	require.NotEmpty(t, ev.ActionReports)
+	return ev.ActionReports[len(ev.ActionReports)-1].(*NetworkIsolateActionReport)
+}
+
+func TestNetworkIsolatorProcessScope(t *testing.T) {
+	n, isolatedCGroups, allowList := newTestNetworkIsolator()
+	def := &rules.NetworkIsolateDefinition{Scope: "process", Allow: []string{"10.0.0.0/8"}}
+
+	// the scope defaults to process
+	report := isolate(t, n, &rules.NetworkIsolateDefinition{Allow: def.Allow}, newIsolationTestEvent(100, 42, ""))
+	assert.Equal(t, NetworkIsolateActionStatusPerformed, report.Status)
+	assert.Equal(t, "process", report.Scope)
+	assert.Equal(t, uint64(42), report.CGroupID)
+	assert.Len(t, isolatedCGroups, 1)
+	assert.Len(t, allowList, 1)
+
+	// another process of the same cgroup
+	report = isolate(t, n, def, newIsolationTestEvent(101, 42, ""))
+	assert.Equal(t, NetworkIsolateActionStatusAlreadyIsolated, report.Status)
+
+	// the cgroup remains isolated until both processes exited
+	n.HandleProcessExited(100)
+	n.HandleProcessExited(200)
+	assert.Contains(t, n.isolated, uint64(42))
+	n.HandleProcessExited(101)
+	assert.Empty(t, n.isolated)
+	assert.Empty(t, n.isolatedPIDs)
+	assert.Empty(t, isolatedCGroups)
+	assert.Empty(t, allowList)
+
+	// a process scope isolation of a container is also released when the container is deleted
+	report = isolate(t, n, def, newIsolationTestEvent(102, 43, "abc"))
+	assert.Equal(t, NetworkIsolateActionStatusPerformed, report.Status)
+	n.HandleCGroupDeleted(&cgroupModel.CacheEntry{ContainerContext: model.ContainerContext{ContainerID: "abc"}})
+	assert.Empty(t, n.isolated)
+	assert.Empty(t, n.isolatedPIDs)
+	assert.Empty(t, isolatedCGroups)
+}
+
+func TestNetworkIsolatorContainerScope(t *testing.T) {
+	n, isolatedCGroups, allowList := newTestNetworkIsolator()
+	def := &rules.NetworkIsolateDefinition{Scope: "container", Allow: []string{"10.0.0.0/8", "fd00::1"}}
+
+	report := isolate(t, n, def, newIsolationTestEvent(100, 42, ""))
+	assert.Equal(t, NetworkIsolateActionStatusError, report.Status)
+	assert.Empty(t, isolatedCGroups)
+
+	report = isolate(t, n, def, newIsolationTestEvent(101, 43, "abc"))
+	assert.Equal(t, NetworkIsolateActionStatusPerformed, report.Status)
+	assert.Len(t, allowList, 2)
+
+	// the process exit doesn't release a container isolation
+	n.HandleProcessExited(101)
+	n.HandleCGroupDeleted(&cgroupModel.CacheEntry{ContainerContext: model.ContainerContext{ContainerID: "def"}})
+	assert.Contains(t, n.isolated, uint64(43))
+
+	n.HandleCGroupDeleted(&cgroupModel.CacheEntry{ContainerContext: model.ContainerContext{ContainerID: "abc"}})
+	assert.Empty(t, n.isolated)
+	assert.Empty(t, isolatedCGroups)
+	assert.Empty(t, allowList)
+}
+
+func TestNetworkIsolatorScopeUpgrade(t *testing.T) {
+	n, _, _ := newTestNetworkIsolator()
+
+	isolate(t, n, &rules.NetworkIsolateDefinition{Scope: "process"}, newIsolationTestEvent(100, 42, "abc"))
+	report := isolate(t, n, &rules.NetworkIsolateDefinition{Scope: "container"}, newIsolationTestEvent(101, 42, "abc"))
+	assert.Equal(t, NetworkIsolateActionStatusAlreadyIsolated, report.Status)
+
+	// the container scope now applies
+	n.HandleProcessExited(100)
+	assert.Contains(t, n.isolated, uint64(42))
+	assert.Empty(t, n.isolatedPIDs)
+}
+
+func TestNetworkIsolatorRelease(t *testing.T) {
+	n, isolatedCGroups, allowList := newTestNetworkIsolator()
+	def := &rules.NetworkIsolateDefinition{Allow: []string{"10.0.0.0/8"}}
+
+	isolate(t, n, def, newIsolationTestEvent(100, 42, ""))
+	isolate(t, n, def, newIsolationTestEvent(101, 43, ""))
+	isolate(t, n, &rules.NetworkIsolateDefinition{Scope: "container"}, newIsolationTestEvent(102, 44, "abc"))
+
+	assert.Error(t, n.Release(1))
+	assert.NoError(t, n.Release(42))
+	assert.NotContains(t, n.isolated, uint64(42))
+	assert.NotContains(t, n.isolatedPIDs, uint32(100))
+	assert.Len(t, isolatedCGroups, 2)
+	assert.Len(t, allowList, 1)
+
+	n.ReleaseAll()
+	assert.Empty(t, n.isolated)
+	assert.Empty(t, n.isolatedPIDs)
+	assert.Empty(t, isolatedCGroups)
+	assert.Empty(t, allowList)
+}
+
+func TestNetworkIsolatorDisabled(t *testing.T) {
+	n, isolatedCGroups, _ := newTestNetworkIsolator()
+
+	n.SetState(false)
+	report := isolate(t, n, &rules.NetworkIsolateDefinition{}, newIsolationTestEvent(100, 42, ""))
+	assert.Equal(t, NetworkIsolateActionStatusError, report.Status)
+	assert.Empty(t, isolatedCGroups)
+
+	n.SetState(true)
+	n.isolatedCGroupsMap, n.allowListMap = nil, nil
+	report = isolate(t, n, &rules.NetworkIsolateDefinition{}, newIsolationTestEvent(100, 42, ""))
+	assert.Equal(t, NetworkIsolateActionStatusError, report.Status)
+	assert.Equal(t, errNetworkIsolationDisabled.Error(), report.Error)
+}
@@ -378,6 +378,20 @@ func (p *Probe) GetService(ev *model.Event) string {
 	return p.Config.RuntimeSecurity.HostServiceName
 }
 
+// captureSequenceEvent retains a copy of an event matching a step of a sequence rule, the event itself is reused once
+// the evaluation is done. The copy is only serialized if the sequence completes.
+func (p *Probe) captureSequenceEvent(_ *rules.Rule, event eval.Event) *model.Event {
+	ev, ok := event.(*model.Event)
+	if !ok {
+		return nil
+	}
+
+	captured := ev.Retain()
+	captured.SequenceSteps = nil
+	captured.ActionReports = nil
+	return &captured
+}
+
 func (p *Probe) onRuleActionPerformed(rule *rules.Rule, action *rules.ActionDefinition) {
 	p.ruleActionStatsLock.Lock()
 	defer p.ruleActionStatsLock.Unlock()
@@ -404,6 +418,7 @@ func (p *Probe) NewRuleSet(eventTypeEnabled map[eval.EventType]bool) *rules.Rule
 	ruleOpts.WithSupportedDiscarders(SupportedDiscarders)
 	ruleOpts.WithSupportedMultiDiscarder(SupportedMultiDiscarder)
 	ruleOpts.WithRuleActionPerformedCb(p.onRuleActionPerformed)
+	ruleOpts.WithSequenceEventCaptureCb(p.captureSequenceEvent)
 
 	eventCtor := func() eval.Event {
 		return p.PlatformProbe.NewEvent()
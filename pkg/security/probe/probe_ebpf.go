@@ -51,6 +51,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/security/probe/kfilters"
 	"github.com/DataDog/datadog-agent/pkg/security/probe/managerhelper"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers"
+	"github.com/DataDog/datadog-agent/pkg/security/resolvers/cgroup"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/mount"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/netns"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/path"
@@ -142,6 +143,10 @@ type EBPFProbe struct {
 	supportsBPFSendSignal bool
 	processKiller         *ProcessKiller
 
+	// network_isolate and quarantine actions
+	networkIsolator *NetworkIsolator
+	fileQuarantiner *FileQuarantiner
+
 	isRuntimeDiscarded bool
 	constantOffsets    map[string]uint64
 	runtimeCompiled    bool
@@ -342,6 +347,15 @@ func (p *EBPFProbe) Init() error {
 
 	p.processKiller.Start(p.ctx, &p.wg)
 
+	if err := p.networkIsolator.Init(p.Manager); err != nil {
+		return err
+	}
+	if err := p.Resolvers.CGroupResolver.RegisterListener(cgroup.CGroupDeleted, p.networkIsolator.HandleCGroupDeleted); err != nil {
+		return err
+	}
+	p.networkIsolator.Start(p.ctx, &p.wg)
+	p.fileQuarantiner.Start(p.ctx, &p.wg)
+
 	return nil
 }
 
@@ -515,6 +529,8 @@ func (p *EBPFProbe) SendStats() error {
 	p.Resolvers.TCResolver.SendTCProgramsStats(p.statsdClient)
 
 	p.processKiller.SendStats(p.statsdClient)
+	p.networkIsolator.SendStats(p.statsdClient)
+	p.fileQuarantiner.SendStats(p.statsdClient)
 
 	if err := p.profileManagers.SendStats(); err != nil {
 		return err
@@ -1141,6 +1157,7 @@ func (p *EBPFProbe) handleEvent(CPU int, data []byte) {
 	p.DispatchEvent(event, true)
 
 	if eventType == model.ExitEventType {
+		p.networkIsolator.HandleProcessExited(event.ProcessContext.Pid)
 		p.Resolvers.ProcessResolver.DeleteEntry(event.ProcessContext.Pid, event.ResolveEventTime())
 	}
 
@@ -1349,6 +1366,10 @@ func (p *EBPFProbe) updateProbes(ruleEventTypes []eval.EventType, needRawSyscall
 
 	activatedProbes = append(activatedProbes, p.Resolvers.TCResolver.SelectTCProbes())
 
+	if p.networkIsolator.IsEnabled() {
+		activatedProbes = append(activatedProbes, probes.NetworkIsolationSelectors...)
+	}
+
 	// on-demand probes
 	if p.config.RuntimeSecurity.OnDemandEnabled {
 		p.onDemandManager.updateProbes()
@@ -1506,6 +1527,8 @@ func (p *EBPFProbe) Close() error {
 	// we wait until both the reorderer and the monitor are stopped
 	p.wg.Wait()
 
+	p.networkIsolator.ReleaseAll()
+
 	ddebpf.RemoveNameMappings(p.Manager)
 	ebpftelemetry.UnregisterTelemetry(p.Manager)
 	// Stopping the manager will stop the perf map reader and unload eBPF programs
@@ -1739,6 +1762,8 @@ func (p *EBPFProbe) ApplyRuleSet(rs *rules.RuleSet) (*kfilters.ApplyRuleSetRepor
 // OnNewRuleSetLoaded resets statistics and states once a new rule set is loaded
 func (p *EBPFProbe) OnNewRuleSetLoaded(rs *rules.RuleSet) {
 	p.processKiller.Reset(rs)
+	p.networkIsolator.Reset()
+	p.fileQuarantiner.Reset()
 }
 
 // NewEvent returns a new event
@@ -1759,6 +1784,8 @@ func (p *EBPFProbe) DumpProcessCache(withArgs bool) (string, error) {
 // EnableEnforcement sets the enforcement mode
 func (p *EBPFProbe) EnableEnforcement(state bool) {
 	p.processKiller.SetState(state)
+	p.networkIsolator.SetState(state)
+	p.fileQuarantiner.SetState(state)
 }
 
 // NewEBPFProbe instantiates a new runtime security agent probe
@@ -1778,6 +1805,11 @@ func NewEBPFProbe(probe *Probe, config *config.Config, opts Opts, telemetry tele
 		return nil, err
 	}
 
+	fileQuarantiner, err := NewFileQuarantiner(config)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancelFnc := context.WithCancel(context.Background())
 
 	p := &EBPFProbe{
@@ -1795,6 +1827,8 @@ func NewEBPFProbe(probe *Probe, config *config.Config, opts Opts, telemetry tele
 		cancelFnc:            cancelFnc,
 		newTCNetDevices:      make(chan model.NetDevice, 16),
 		processKiller:        processKiller,
+		networkIsolator:      NewNetworkIsolator(config),
+		fileQuarantiner:      fileQuarantiner,
 		onDemandRateLimiter:  rate.NewLimiter(onDemandRate, 1),
 		playSnapShotState:    atomic.NewBool(false),
 	}
@@ -2007,6 +2041,12 @@ func NewEBPFProbe(probe *Probe, config *config.Config, opts Opts, telemetry tele
 		p.managerOptions.ExcludedFunctions = append(p.managerOptions.ExcludedFunctions, probes.GetAllTCProgramFunctions()...)
 	}
 
+	if !p.networkIsolator.IsEnabled() {
+		p.managerOptions.ExcludedFunctions = append(p.managerOptions.ExcludedFunctions, probes.NetworkIsolationFuncName)
+	} else if probe, found := p.Manager.GetProbe(probes.NetworkIsolationProbeID); found {
+		probe.CGroupPath = getCGroup2Root()
+	}
+
 	if p.useFentry {
 		afBasedExcluder, err := newAvailableFunctionsBasedExcluder()
 		if err != nil {
@@ -2375,6 +2415,26 @@ func (p *EBPFProbe) HandleActions(ctx *eval.Context, rule *rules.Rule) {
 			if p.fileHasher.HashAndReport(rule, ev) {
 				p.probe.onRuleActionPerformed(rule, action.Def)
 			}
+
+		case action.Def.NetworkIsolate != nil:
+			// do not handle network isolation on event with error
+			if ev.Error != nil {
+				return
+			}
+
+			if p.networkIsolator.IsolateAndReport(action.Def.NetworkIsolate, rule, ev) {
+				p.probe.onRuleActionPerformed(rule, action.Def)
+			}
+
+		case action.Def.Quarantine != nil:
+			// do not handle quarantine on event with error
+			if ev.Error != nil {
+				return
+			}
+
+			if p.fileQuarantiner.QuarantineAndReport(action.Def.Quarantine, rule, ev) {
+				p.probe.onRuleActionPerformed(rule, action.Def)
+			}
 		}
 	}
 }
@@ -15,7 +15,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/jellydator/ttlcache/v3"
 	"go.uber.org/atomic"
 
 	"github.com/DataDog/datadog-go/v5/statsd"
@@ -46,10 +45,9 @@ type ProcessKiller struct {
 	binariesExcluded []*eval.Glob
 	sourceAllowed    []string
 
-	useDisarmers      *atomic.Bool
-	disarmerStateCh   chan disarmerState
-	ruleDisarmersLock sync.Mutex
-	ruleDisarmers     map[rules.RuleID]*ruleDisarmer
+	useDisarmers    *atomic.Bool
+	disarmerStateCh chan disarmerState
+	disarmers       *actionDisarmers
 
 	perRuleStatsLock sync.Mutex
 	perRuleStats     map[rules.RuleID]*processKillerStats
@@ -66,7 +64,7 @@ func NewProcessKiller(cfg *config.Config) (*ProcessKiller, error) {
 		enabled:         true,
 		useDisarmers:    atomic.NewBool(false),
 		disarmerStateCh: make(chan disarmerState, 1),
-		ruleDisarmers:   make(map[rules.RuleID]*ruleDisarmer),
+		disarmers:       newActionDisarmers(rules.KillAction, cfg.RuntimeSecurity),
 		sourceAllowed:   cfg.RuntimeSecurity.EnforcementRuleSourceAllowed,
 		perRuleStats:    make(map[rules.RuleID]*processKillerStats),
 	}
@@ -181,16 +179,7 @@ func (p *ProcessKiller) KillAndReport(kill *rules.KillDefinition, rule *rules.Ru
 	}
 
 	if p.useDisarmers.Load() {
-		var disarmer *ruleDisarmer
-		p.ruleDisarmersLock.Lock()
-		if disarmer = p.ruleDisarmers[rule.ID]; disarmer == nil {
-			containerParams, executableParams := p.getDisarmerParams(kill)
-			disarmer = newRuleDisarmer(containerParams, executableParams)
-			p.ruleDisarmers[rule.ID] = disarmer
-		}
-		p.ruleDisarmersLock.Unlock()
-
-		onActionBlockedByDisarmer := func(dt disarmerType) {
+		if dt, allowed := p.disarmers.allow(rule, kill.Disarmer, ev, entry); !allowed {
 			seclog.Warnf("skipping kill action of rule `%s` because it has been disarmed", rule.ID)
 			ev.ActionReports = append(ev.ActionReports, &KillActionReport{
 				Scope:        scope,
@@ -202,29 +191,7 @@ func (p *ProcessKiller) KillAndReport(kill *rules.KillDefinition, rule *rules.Ru
 				Pid:          ev.ProcessContext.Pid,
 				rule:         rule,
 			})
-		}
-
-		if disarmer.container.enabled {
-			if containerID := ev.FieldHandlers.ResolveContainerID(ev, ev.ContainerContext); containerID != "" {
-				if !disarmer.allow(disarmer.containerCache, containerID, func() {
-					disarmer.disarmedCount[containerDisarmerType]++
-					seclog.Warnf("disarming kill action of rule `%s` because more than %d different containers triggered it in the last %s", rule.ID, disarmer.container.capacity, disarmer.container.period)
-				}) {
-					onActionBlockedByDisarmer(containerDisarmerType)
-					return false
-				}
-			}
-		}
-
-		if disarmer.executable.enabled {
-			executable := entry.Process.FileEvent.PathnameStr
-			if !disarmer.allow(disarmer.executableCache, executable, func() {
-				disarmer.disarmedCount[executableDisarmerType]++
-				seclog.Warnf("disarmed kill action of rule `%s` because more than %d different executables triggered it in the last %s", rule.ID, disarmer.executable.capacity, disarmer.executable.period)
-			}) {
-				onActionBlockedByDisarmer(executableDisarmerType)
-				return false
-			}
+			return false
 		}
 	}
 
@@ -318,9 +285,7 @@ func (p *ProcessKiller) Reset(rs *rules.RuleSet) {
 	p.perRuleStatsLock.Lock()
 	clear(p.perRuleStats)
 	p.perRuleStatsLock.Unlock()
-	p.ruleDisarmersLock.Lock()
-	clear(p.ruleDisarmers)
-	p.ruleDisarmersLock.Unlock()
+	p.disarmers.reset()
 }
 
 // SendStats sends runtime security enforcement statistics to Datadog
@@ -338,27 +303,7 @@ func (p *ProcessKiller) SendStats(statsd statsd.ClientInterface) {
 	}
 	p.perRuleStatsLock.Unlock()
 
-	p.ruleDisarmersLock.Lock()
-	for ruleID, disarmer := range p.ruleDisarmers {
-		ruleIDTag := []string{
-			"rule_id:" + string(ruleID),
-		}
-
-		disarmer.Lock()
-		for disarmerType, count := range disarmer.disarmedCount {
-			if count > 0 {
-				tags := append([]string{"disarmer_type:" + string(disarmerType)}, ruleIDTag...)
-				_ = statsd.Count(metrics.MetricEnforcementRuleDisarmed, count, tags, 1)
-				disarmer.disarmedCount[disarmerType] = 0
-			}
-		}
-		if disarmer.rearmedCount > 0 {
-			_ = statsd.Count(metrics.MetricEnforcementRuleRearmed, disarmer.rearmedCount, ruleIDTag, 1)
-			disarmer.rearmedCount = 0
-		}
-		disarmer.Unlock()
-	}
-	p.ruleDisarmersLock.Unlock()
+	p.disarmers.sendStats(statsd)
 }
 
 // Start starts the go rountine responsible for flushing the disarmer caches
@@ -395,151 +340,9 @@ func (p *ProcessKiller) Start(ctx context.Context, wg *sync.WaitGroup) {
 				case <-ctx.Done():
 					return
 				case <-ticker.C:
-					p.ruleDisarmersLock.Lock()
-					for ruleID, disarmer := range p.ruleDisarmers {
-						disarmer.Lock()
-						var cLength, eLength int
-						if disarmer.container.enabled {
-							cLength = disarmer.containerCache.flush()
-						}
-						if disarmer.executable.enabled {
-							eLength = disarmer.executableCache.flush()
-						}
-						if disarmer.disarmed && cLength == 0 && eLength == 0 {
-							disarmer.disarmed = false
-							disarmer.rearmedCount++
-							seclog.Infof("kill action of rule `%s` has been re-armed", ruleID)
-						}
-						disarmer.Unlock()
-					}
-					p.ruleDisarmersLock.Unlock()
+					p.disarmers.flush()
 				}
 			}
 		}
 	}()
 }
-
-func (p *ProcessKiller) getDisarmerParams(kill *rules.KillDefinition) (*disarmerParams, *disarmerParams) {
-	var containerParams, executableParams disarmerParams
-
-	if kill.Disarmer != nil && kill.Disarmer.Container != nil && kill.Disarmer.Container.MaxAllowed > 0 {
-		containerParams.enabled = true
-		containerParams.capacity = uint64(kill.Disarmer.Container.MaxAllowed)
-		containerParams.period = kill.Disarmer.Container.Period
-	} else if p.cfg.RuntimeSecurity.EnforcementDisarmerContainerEnabled {
-		containerParams.enabled = true
-		containerParams.capacity = uint64(p.cfg.RuntimeSecurity.EnforcementDisarmerContainerMaxAllowed)
-		containerParams.period = p.cfg.RuntimeSecurity.EnforcementDisarmerContainerPeriod
-	}
-
-	if kill.Disarmer != nil && kill.Disarmer.Executable != nil && kill.Disarmer.Executable.MaxAllowed > 0 {
-		executableParams.enabled = true
-		executableParams.capacity = uint64(kill.Disarmer.Executable.MaxAllowed)
-		executableParams.period = kill.Disarmer.Executable.Period
-	} else if p.cfg.RuntimeSecurity.EnforcementDisarmerExecutableEnabled {
-		executableParams.enabled = true
-		executableParams.capacity = uint64(p.cfg.RuntimeSecurity.EnforcementDisarmerExecutableMaxAllowed)
-		executableParams.period = p.cfg.RuntimeSecurity.EnforcementDisarmerExecutablePeriod
-	}
-
-	return &containerParams, &executableParams
-}
-
-type disarmerState int
-
-const (
-	stopped disarmerState = iota
-	running
-)
-
-type disarmerType string
-
-const (
-	containerDisarmerType  disarmerType = "container"
-	executableDisarmerType disarmerType = "executable"
-)
-
-type ruleDisarmer struct {
-	sync.Mutex
-	disarmed        bool
-	container       disarmerParams
-	containerCache  *disarmerCache[string, bool]
-	executable      disarmerParams
-	executableCache *disarmerCache[string, bool]
-	// stats
-	disarmedCount map[disarmerType]int64
-	rearmedCount  int64
-}
-
-type disarmerParams struct {
-	enabled  bool
-	capacity uint64
-	period   time.Duration
-}
-
-type disarmerCache[K comparable, V any] struct {
-	*ttlcache.Cache[K, V]
-	capacity uint64
-}
-
-func newDisarmerCache[K comparable, V any](params *disarmerParams) *disarmerCache[K, V] {
-	cacheOpts := []ttlcache.Option[K, V]{
-		ttlcache.WithCapacity[K, V](params.capacity),
-	}
-
-	if params.period > 0 {
-		cacheOpts = append(cacheOpts, ttlcache.WithTTL[K, V](params.period))
-	}
-
-	return &disarmerCache[K, V]{
-		Cache:    ttlcache.New(cacheOpts...),
-		capacity: params.capacity,
-	}
-}
-
-func (c *disarmerCache[K, V]) flush() int {
-	c.DeleteExpired()
-	return c.Len()
-}
-
-func newRuleDisarmer(containerParams *disarmerParams, executableParams *disarmerParams) *ruleDisarmer {
-	kd := &ruleDisarmer{
-		disarmed:      false,
-		container:     *containerParams,
-		executable:    *executableParams,
-		disarmedCount: make(map[disarmerType]int64),
-	}
-
-	if kd.container.enabled {
-		kd.containerCache = newDisarmerCache[string, bool](containerParams)
-	}
-
-	if kd.executable.enabled {
-		kd.executableCache = newDisarmerCache[string, bool](executableParams)
-	}
-
-	return kd
-}
-
-func (rd *ruleDisarmer) allow(cache *disarmerCache[string, bool], key string, onDisarm func()) bool {
-	rd.Lock()
-	defer rd.Unlock()
-
-	if cache == nil {
-		return true
-	}
-
-	cache.DeleteExpired()
-	// if the key is not in the cache, check if the new key causes the number of keys to exceed the capacity
-	// otherwise, the key is already in the cache and cache.Get will update its TTL
-	if cache.Get(key) == nil {
-		alreadyAtCapacity := uint64(cache.Len()) >= cache.capacity
-		cache.Set(key, true, ttlcache.DefaultTTL)
-		if alreadyAtCapacity && !rd.disarmed {
-			rd.disarmed = true
-			onDisarm()
-		}
-	}
-
-	return !rd.disarmed
-}
@@ -170,10 +170,19 @@ type RuleState struct {
 	Status     string            `json:"status"`
 	Message    string            `json:"message,omitempty"`
 	Tags       map[string]string `json:"tags,omitempty"`
+	Sequence   *RuleSequence     `json:"sequence,omitempty"`
 	Actions    []RuleAction      `json:"actions,omitempty"`
 	ModifiedBy []*PolicyState    `json:"modified_by,omitempty"`
 }
 
+// RuleSequence is used to report the steps of a sequence rule
+// easyjson:json
+type RuleSequence struct {
+	Steps  []string `json:"steps"`
+	Within string   `json:"within"`
+	Scope  string   `json:"scope,omitempty"`
+}
+
 // PolicyState is used to report policy was loaded
 // easyjson:json
 type PolicyState struct {
@@ -186,11 +195,13 @@ type PolicyState struct {
 // RuleAction is used to report policy was loaded
 // easyjson:json
 type RuleAction struct {
-	Filter   *string         `json:"filter,omitempty"`
-	Set      *RuleSetAction  `json:"set,omitempty"`
-	Kill     *RuleKillAction `json:"kill,omitempty"`
-	Hash     *HashAction     `json:"hash,omitempty"`
-	CoreDump *CoreDumpAction `json:"coredump,omitempty"`
+	Filter         *string                   `json:"filter,omitempty"`
+	Set            *RuleSetAction            `json:"set,omitempty"`
+	Kill           *RuleKillAction           `json:"kill,omitempty"`
+	Hash           *HashAction               `json:"hash,omitempty"`
+	CoreDump       *CoreDumpAction           `json:"coredump,omitempty"`
+	NetworkIsolate *RuleNetworkIsolateAction `json:"network_isolate,omitempty"`
+	Quarantine     *QuarantineAction         `json:"quarantine,omitempty"`
 }
 
 // HashAction is used to report 'hash' action
@@ -225,6 +236,19 @@ type CoreDumpAction struct {
 	NoCompression bool `json:"no_compression,omitempty"`
 }
 
+// RuleNetworkIsolateAction is used to report the 'network_isolate' action
+// easyjson:json
+type RuleNetworkIsolateAction struct {
+	Scope string   `json:"scope,omitempty"`
+	Allow []string `json:"allow,omitempty"`
+}
+
+// QuarantineAction is used to report the 'quarantine' action
+// easyjson:json
+type QuarantineAction struct {
+	Enabled bool `json:"enabled,omitempty"`
+}
+
 // RulesetLoadedEvent is used to report that a new ruleset was loaded
 // easyjson:json
 type RulesetLoadedEvent struct {
@@ -269,6 +293,14 @@ func RuleStateFromRule(rule *rules.PolicyRule, status string, message string) *R
 		Tags:       rule.Def.Tags,
 	}
 
+	if sequence := rule.Def.Sequence; sequence != nil {
+		ruleState.Sequence = &RuleSequence{
+			Steps:  sequence.Steps,
+			Within: sequence.Within.String(),
+			Scope:  string(sequence.Scope),
+		}
+	}
+
 	for _, action := range rule.Actions {
 		ruleAction := RuleAction{Filter: action.Def.Filter}
 		switch {
@@ -296,6 +328,15 @@ func RuleStateFromRule(rule *rules.PolicyRule, status string, message string) *R
 				Dentry:        action.Def.CoreDump.Dentry,
 				NoCompression: action.Def.CoreDump.NoCompression,
 			}
+		case action.Def.NetworkIsolate != nil:
+			ruleAction.NetworkIsolate = &RuleNetworkIsolateAction{
+				Scope: action.Def.NetworkIsolate.Scope,
+				Allow: action.Def.NetworkIsolate.Allow,
+			}
+		case action.Def.Quarantine != nil:
+			ruleAction.Quarantine = &QuarantineAction{
+				Enabled: true,
+			}
 		}
 		ruleState.Actions = append(ruleState.Actions, ruleAction)
 	}
@@ -44,6 +44,8 @@ var bpfMapNames = []string{
 	"io_uring_ctx_pi",
 	"is_discarded_by",
 	"is_new_kthread",
+	"isolated_cgroup",
+	"isolation_allow",
 	"kill_list",
 	"mmap_flags_appr",
 	"mmap_protection",
@@ -120,17 +120,18 @@ type SpanContext struct {
 
 // BaseEvent represents an event sent from the kernel
 type BaseEvent struct {
-	ID            string         `field:"-"`
-	Type          uint32         `field:"-"`
-	Flags         uint32         `field:"-"`
-	TimestampRaw  uint64         `field:"event.timestamp,handler:ResolveEventTimestamp"` // SECLDoc[event.timestamp] Definition:`Timestamp of the event`
-	Timestamp     time.Time      `field:"timestamp,opts:getters_only,handler:ResolveEventTime"`
-	Rules         []*MatchedRule `field:"-"`
-	ActionReports []ActionReport `field:"-"`
-	Os            string         `field:"event.os"`                                          // SECLDoc[event.os] Definition:`Operating system of the event`
-	Origin        string         `field:"event.origin"`                                      // SECLDoc[event.origin] Definition:`Origin of the event`
-	Service       string         `field:"event.service,handler:ResolveService,opts:skip_ad"` // SECLDoc[event.service] Definition:`Service associated with the event`
-	Hostname      string         `field:"event.hostname,handler:ResolveHostname"`            // SECLDoc[event.hostname] Definition:`Hostname associated with the event`
+	ID            string                 `field:"-"`
+	Type          uint32                 `field:"-"`
+	Flags         uint32                 `field:"-"`
+	TimestampRaw  uint64                 `field:"event.timestamp,handler:ResolveEventTimestamp"` // SECLDoc[event.timestamp] Definition:`Timestamp of the event`
+	Timestamp     time.Time              `field:"timestamp,opts:getters_only,handler:ResolveEventTime"`
+	Rules         []*MatchedRule         `field:"-"`
+	ActionReports []ActionReport         `field:"-"`
+	SequenceSteps []*MatchedSequenceStep `field:"-"`
+	Os            string                 `field:"event.os"`                                          // SECLDoc[event.os] Definition:`Operating system of the event`
+	Origin        string                 `field:"event.origin"`                                      // SECLDoc[event.origin] Definition:`Origin of the event`
+	Service       string                 `field:"event.service,handler:ResolveService,opts:skip_ad"` // SECLDoc[event.service] Definition:`Service associated with the event`
+	Hostname      string                 `field:"event.hostname,handler:ResolveHostname"`            // SECLDoc[event.hostname] Definition:`Hostname associated with the event`
 
 	// context shared with all events
 	ProcessContext         *ProcessContext        `field:"process"`
@@ -318,6 +319,16 @@ type MatchedRule struct {
 	PolicyVersion string
 }
 
+// MatchedSequenceStep describes a step of a sequence rule matched by a previous event
+type MatchedSequenceStep struct {
+	RuleID    string
+	Index     int
+	MatchedAt time.Time
+	// Event holds a copy of the event that matched the step, if an event capture callback was provided. It's only
+	// valid while the listeners are notified of the event completing the sequence.
+	Event *Event
+}
+
 // ActionReport defines an action report
 type ActionReport interface {
 	ToJSON() ([]byte, error)
@@ -9,6 +9,8 @@ package rules
 import (
 	"errors"
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
@@ -25,8 +27,8 @@ type Action struct {
 
 // Check returns an error if the action in invalid
 func (a *ActionDefinition) Check(opts PolicyLoaderOpts) error {
-	if a.Set == nil && a.Kill == nil && a.Hash == nil && a.CoreDump == nil {
-		return errors.New("either 'set', 'kill', 'hash' or 'coredump' section of an action must be specified")
+	if a.Set == nil && a.Kill == nil && a.Hash == nil && a.CoreDump == nil && a.NetworkIsolate == nil && a.Quarantine == nil {
+		return errors.New("either 'set', 'kill', 'hash', 'coredump', 'network_isolate' or 'quarantine' section of an action must be specified")
 	}
 
 	if a.Set != nil {
@@ -54,11 +56,55 @@ func (a *ActionDefinition) Check(opts PolicyLoaderOpts) error {
 		if _, found := model.SignalConstants[a.Kill.Signal]; !found {
 			return fmt.Errorf("unsupported signal '%s'", a.Kill.Signal)
 		}
+	} else if a.NetworkIsolate != nil {
+		if opts.DisableEnforcement {
+			a.NetworkIsolate = nil
+			return errors.New("'network_isolate' action is disabled globally")
+		}
+
+		switch a.NetworkIsolate.Scope {
+		case "", "process", "container":
+		default:
+			return fmt.Errorf("unsupported scope '%s' for the 'network_isolate' action", a.NetworkIsolate.Scope)
+		}
+
+		for _, allowed := range a.NetworkIsolate.Allow {
+			if _, err := ParseIsolationAllowedNetwork(allowed); err != nil {
+				return err
+			}
+		}
+	} else if a.Quarantine != nil {
+		if opts.DisableEnforcement {
+			a.Quarantine = nil
+			return errors.New("'quarantine' action is disabled globally")
+		}
 	}
 
 	return nil
 }
 
+// ParseIsolationAllowedNetwork parses an entry of the allow list of a 'network_isolate' action. Both plain IP
+// addresses and CIDRs are accepted.
+func ParseIsolationAllowedNetwork(allowed string) (*net.IPNet, error) {
+	if !strings.Contains(allowed, "/") {
+		ip := net.ParseIP(allowed)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address '%s' in the 'network_isolate' allow list", allowed)
+		}
+
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(allowed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR '%s' in the 'network_isolate' allow list: %w", allowed, err)
+	}
+	return ipNet, nil
+}
+
 // CompileFilter compiles the filter expression
 func (a *Action) CompileFilter(parsingContext *ast.ParsingContext, model eval.Model, evalOpts *eval.Opts) error {
 	if a.Def.Filter == nil || *a.Def.Filter == "" {
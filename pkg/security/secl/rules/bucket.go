@@ -21,7 +21,8 @@ type RuleBucket struct {
 // AddRule adds a rule to the bucket
 func (rb *RuleBucket) AddRule(rule *Rule) error {
 	for _, r := range rb.rules {
-		if r.Def.ID == rule.Def.ID {
+		// steps of the same sequence rule share the same ID
+		if r.Def.ID == rule.Def.ID && (r.sequenceStep == nil || rule.sequenceStep == nil || r.sequenceStep.tracker != rule.sequenceStep.tracker) {
 			return &ErrRuleLoad{Rule: rule.PolicyRule, Err: ErrDefinitionIDConflict}
 		}
 	}
@@ -21,6 +21,9 @@ var (
 	// ErrRuleWithoutExpression is returned when there is no expression
 	ErrRuleWithoutExpression = errors.New("no rule expression")
 
+	// ErrRuleWithExpressionAndSequence is returned when both an expression and a sequence are defined
+	ErrRuleWithExpressionAndSequence = errors.New("a rule can't have both an expression and a sequence")
+
 	// ErrRuleIDPattern is returned when there is no expression
 	ErrRuleIDPattern = errors.New("rule ID pattern error")
 
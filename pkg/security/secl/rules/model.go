@@ -29,7 +29,7 @@ type OverrideField = string
 const (
 	// OverrideAllFields used to override all the fields
 	OverrideAllFields OverrideField = "all"
-	// OverrideExpressionField used to override the expression
+	// OverrideExpressionField used to override the expression, or the sequence
 	OverrideExpressionField OverrideField = "expression"
 	// OverrideActionFields used to override the actions
 	OverrideActionFields OverrideField = "actions"
@@ -75,6 +75,7 @@ type RuleDefinition struct {
 	RateLimiterToken       []string            `yaml:"limiter_token,omitempty" json:"limiter_token,omitempty"`
 	Silent                 bool                `yaml:"silent,omitempty" json:"silent,omitempty"`
 	GroupID                string              `yaml:"group_id,omitempty" json:"group_id,omitempty"`
+	Sequence               *SequenceDefinition `yaml:"sequence,omitempty" json:"sequence,omitempty"`
 }
 
 // GetTag returns the tag value associated with a tag key
@@ -98,15 +99,21 @@ const (
 	CoreDumpAction ActionName = "coredump"
 	// HashAction name of the hash action
 	HashAction ActionName = "hash"
+	// NetworkIsolateAction name of the network isolation action
+	NetworkIsolateAction ActionName = "network_isolate"
+	// QuarantineAction name of the quarantine action
+	QuarantineAction ActionName = "quarantine"
 )
 
 // ActionDefinition describes a rule action section
 type ActionDefinition struct {
-	Filter   *string             `yaml:"filter" json:"filter,omitempty"`
-	Set      *SetDefinition      `yaml:"set" json:"set,omitempty" jsonschema:"oneof_required=SetAction"`
-	Kill     *KillDefinition     `yaml:"kill" json:"kill,omitempty" jsonschema:"oneof_required=KillAction"`
-	CoreDump *CoreDumpDefinition `yaml:"coredump" json:"coredump,omitempty" jsonschema:"oneof_required=CoreDumpAction"`
-	Hash     *HashDefinition     `yaml:"hash" json:"hash,omitempty" jsonschema:"oneof_required=HashAction"`
+	Filter         *string                   `yaml:"filter" json:"filter,omitempty"`
+	Set            *SetDefinition            `yaml:"set" json:"set,omitempty" jsonschema:"oneof_required=SetAction"`
+	Kill           *KillDefinition           `yaml:"kill" json:"kill,omitempty" jsonschema:"oneof_required=KillAction"`
+	CoreDump       *CoreDumpDefinition       `yaml:"coredump" json:"coredump,omitempty" jsonschema:"oneof_required=CoreDumpAction"`
+	Hash           *HashDefinition           `yaml:"hash" json:"hash,omitempty" jsonschema:"oneof_required=HashAction"`
+	NetworkIsolate *NetworkIsolateDefinition `yaml:"network_isolate" json:"network_isolate,omitempty" jsonschema:"oneof_required=NetworkIsolateAction"`
+	Quarantine     *QuarantineDefinition     `yaml:"quarantine" json:"quarantine,omitempty" jsonschema:"oneof_required=QuarantineAction"`
 }
 
 // Name returns the name of the action
@@ -120,6 +127,10 @@ func (a *ActionDefinition) Name() ActionName {
 		return CoreDumpAction
 	case a.Hash != nil:
 		return HashAction
+	case a.NetworkIsolate != nil:
+		return NetworkIsolateAction
+	case a.Quarantine != nil:
+		return QuarantineAction
 	default:
 		return ""
 	}
@@ -128,6 +139,15 @@ func (a *ActionDefinition) Name() ActionName {
 // Scope describes the scope variables
 type Scope string
 
+// SequenceDefinition describes the 'sequence' section of a rule. The rule matches once all the steps matched, in order,
+// within the given time window and for the same scope
+type SequenceDefinition struct {
+	Steps      []string      `yaml:"steps" json:"steps" jsonschema:"minItems=2"`
+	Within     time.Duration `yaml:"within" json:"within"`
+	Scope      Scope         `yaml:"scope" json:"scope,omitempty" jsonschema:"enum=process_tree,enum=container,enum=cgroup"`
+	MaxPending int           `yaml:"max_pending" json:"max_pending,omitempty" jsonschema:"description=Maximum number of partial matches tracked at the same time"`
+}
+
 // SetDefinition describes the 'set' section of a rule action
 type SetDefinition struct {
 	Name   string        `yaml:"name" json:"name"`
@@ -169,6 +189,20 @@ type CoreDumpDefinition struct {
 // HashDefinition describes the 'hash' section of a rule action
 type HashDefinition struct{}
 
+// NetworkIsolateDefinition describes the 'network_isolate' section of a rule action. Isolation applies to a cgroup:
+// the 'process' scope isolates the cgroup of the process, including the other processes of this cgroup, until the
+// process exits, the 'container' scope isolates the cgroup of the container until the container is deleted.
+type NetworkIsolateDefinition struct {
+	Scope    string                  `yaml:"scope" json:"scope,omitempty" jsonschema:"enum=process,enum=container,description=process isolates the cgroup of the process until the process exits and container isolates the container until it is deleted"`
+	Allow    []string                `yaml:"allow" json:"allow,omitempty" jsonschema:"description=List of IP addresses or CIDRs that remain reachable,example=10.0.0.0/8"`
+	Disarmer *KillDisarmerDefinition `yaml:"disarmer" json:"disarmer,omitempty"`
+}
+
+// QuarantineDefinition describes the 'quarantine' section of a rule action
+type QuarantineDefinition struct {
+	Disarmer *KillDisarmerDefinition `yaml:"disarmer" json:"disarmer,omitempty"`
+}
+
 // OnDemandHookPoint represents a hook point definition
 type OnDemandHookPoint struct {
 	Name      string         `yaml:"name" json:"name"`
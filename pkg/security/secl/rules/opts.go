@@ -7,6 +7,9 @@
 package rules
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/log"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
@@ -20,6 +23,16 @@ type VariableProvider interface {
 // VariableProviderFactory describes a function called to instantiate a variable provider
 type VariableProviderFactory func() VariableProvider
 
+// SequenceScopeKeysFunc describes a function returning the keys of the scope of an event for sequence rules. The first
+// key identifies the scope of the event itself, the following ones identify its parent scopes, e.g. the ancestors of
+// the process of the event.
+type SequenceScopeKeysFunc func(event eval.Event) []string
+
+// SequenceEventCaptureCb describes the callback function called to capture an event matching a step of a sequence rule.
+// It's called in the evaluation path, the returned copy of the event is only serialized once the sequence completes
+// and is released when the step is dropped.
+type SequenceEventCaptureCb func(r *Rule, event eval.Event) *model.Event
+
 // RuleActionPerformedCb describes the callback function called after a rule action is performed
 type RuleActionPerformedCb func(r *Rule, action *ActionDefinition)
 
@@ -30,8 +43,10 @@ type Opts struct {
 	ReservedRuleIDs          []RuleID
 	EventTypeEnabled         map[eval.EventType]bool
 	StateScopes              map[Scope]VariableProviderFactory
+	SequenceScopes           map[Scope]SequenceScopeKeysFunc
 	Logger                   log.Logger
 	ruleActionPerformedCb    RuleActionPerformedCb
+	sequenceEventCaptureCb   SequenceEventCaptureCb
 }
 
 // WithSupportedDiscarders set supported discarders
@@ -70,6 +85,18 @@ func (o *Opts) WithStateScopes(stateScopes map[Scope]VariableProviderFactory) *O
 	return o
 }
 
+// WithSequenceScopes set sequence scopes
+func (o *Opts) WithSequenceScopes(sequenceScopes map[Scope]SequenceScopeKeysFunc) *Opts {
+	o.SequenceScopes = sequenceScopes
+	return o
+}
+
+// WithSequenceEventCaptureCb sets the callback used to capture the events matching the steps of sequence rules
+func (o *Opts) WithSequenceEventCaptureCb(cb SequenceEventCaptureCb) *Opts {
+	o.sequenceEventCaptureCb = cb
+	return o
+}
+
 // WithRuleActionPerformedCb sets the rule action performed callback
 func (o *Opts) WithRuleActionPerformedCb(cb RuleActionPerformedCb) *Opts {
 	o.ruleActionPerformedCb = cb
@@ -92,6 +119,38 @@ func NewRuleOpts(eventTypeEnabled map[eval.EventType]bool) *Opts {
 					return ctx.Event.(*model.Event).ContainerContext
 				})
 			},
+		}).
+		WithSequenceScopes(map[Scope]SequenceScopeKeysFunc{
+			"process_tree": func(event eval.Event) []string {
+				var keys []string
+				if pid, err := event.GetFieldValue("process.pid"); err == nil {
+					keys = append(keys, fmt.Sprint(pid))
+				}
+				if pids, err := event.GetFieldValue("process.ancestors.pid"); err == nil {
+					if pids, ok := pids.([]int); ok {
+						for _, pid := range pids {
+							keys = append(keys, strconv.Itoa(pid))
+						}
+					}
+				}
+				return keys
+			},
+			"container": func(event eval.Event) []string {
+				if id, err := event.GetFieldValue("container.id"); err == nil {
+					if key := fmt.Sprint(id); key != "" {
+						return []string{key}
+					}
+				}
+				return nil
+			},
+			"cgroup": func(event eval.Event) []string {
+				if id, err := event.GetFieldValue("cgroup.id"); err == nil {
+					if key := fmt.Sprint(id); key != "" {
+						return []string{key}
+					}
+				}
+				return nil
+			},
 		})
 
 	return &ruleOpts
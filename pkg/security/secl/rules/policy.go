@@ -66,11 +66,13 @@ func applyOverride(rd1, rd2 *PolicyRule) {
 	// for backward compatibility, by default only the expression is copied if no options
 	if len(rd2.Def.OverrideOptions.Fields) == 0 {
 		rd1.Def.Expression = rd2.Def.Expression
+		rd1.Def.Sequence = rd2.Def.Sequence
 	} else if slices.Contains(rd2.Def.OverrideOptions.Fields, OverrideAllFields) {
 		*rd1.Def = *rd2.Def
 	} else {
 		if slices.Contains(rd2.Def.OverrideOptions.Fields, OverrideExpressionField) {
 			rd1.Def.Expression = rd2.Def.Expression
+			rd1.Def.Sequence = rd2.Def.Sequence
 		}
 		if slices.Contains(rd2.Def.OverrideOptions.Fields, OverrideActionFields) {
 			rd1.Def.Actions = rd2.Def.Actions
@@ -224,11 +226,17 @@ RULES:
 			continue
 		}
 
-		if ruleDef.Expression == "" && !ruleDef.Disabled && ruleDef.Combine == "" {
+		if ruleDef.Expression == "" && ruleDef.Sequence == nil && !ruleDef.Disabled && ruleDef.Combine == "" {
 			rule.Error = &ErrRuleLoad{Rule: rule, Err: ErrRuleWithoutExpression}
 			errs = multierror.Append(errs, rule.Error)
 			continue
 		}
+
+		if ruleDef.Expression != "" && ruleDef.Sequence != nil {
+			rule.Error = &ErrRuleLoad{Rule: rule, Err: ErrRuleWithExpressionAndSequence}
+			errs = multierror.Append(errs, rule.Error)
+			continue
+		}
 	}
 
 	p.onDemandHookPoints = p.Def.OnDemandHookPoints
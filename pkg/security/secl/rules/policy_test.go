@@ -811,6 +811,82 @@ func TestActionSetVariableInvalid(t *testing.T) {
 	})
 }
 
+func TestActionNetworkIsolateInvalid(t *testing.T) {
+	t.Run("invalid-scope", func(t *testing.T) {
+		testPolicy := &PolicyDef{
+			Rules: []*RuleDefinition{{
+				ID:         "test_rule",
+				Expression: `open.file.path == "/tmp/test"`,
+				Actions: []*ActionDefinition{{
+					NetworkIsolate: &NetworkIsolateDefinition{
+						Scope: "host",
+					},
+				}},
+			}},
+		}
+
+		if _, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{}); err == nil {
+			t.Error("expected policy to fail to load")
+		} else {
+			t.Log(err)
+		}
+	})
+
+	t.Run("invalid-allow-list", func(t *testing.T) {
+		testPolicy := &PolicyDef{
+			Rules: []*RuleDefinition{{
+				ID:         "test_rule",
+				Expression: `open.file.path == "/tmp/test"`,
+				Actions: []*ActionDefinition{{
+					NetworkIsolate: &NetworkIsolateDefinition{
+						Scope: "container",
+						Allow: []string{"10.0.0.0/8", "10.0.0.300"},
+					},
+				}},
+			}},
+		}
+
+		if _, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{}); err == nil {
+			t.Error("expected policy to fail to load")
+		} else {
+			t.Log(err)
+		}
+	})
+}
+
+func TestActionQuarantineInvalid(t *testing.T) {
+	t.Run("enforcement-disabled", func(t *testing.T) {
+		testPolicy := &PolicyDef{
+			Rules: []*RuleDefinition{{
+				ID:         "test_rule",
+				Expression: `open.file.path == "/tmp/test"`,
+				Actions: []*ActionDefinition{{
+					Quarantine: &QuarantineDefinition{},
+				}},
+			}},
+		}
+
+		if _, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{DisableEnforcement: true}); err == nil {
+			t.Error("expected policy to fail to load")
+		} else {
+			t.Log(err)
+		}
+	})
+}
+
+func TestParseIsolationAllowedNetwork(t *testing.T) {
+	ipNet, err := ParseIsolationAllowedNetwork("192.168.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.1/32", ipNet.String())
+
+	ipNet, err = ParseIsolationAllowedNetwork("fd00::/8")
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::/8", ipNet.String())
+
+	_, err = ParseIsolationAllowedNetwork("foo/bar")
+	assert.Error(t, err)
+}
+
 // go test -v github.com/DataDog/datadog-agent/pkg/security/secl/rules --run="TestLoadPolicy"
 func TestLoadPolicy(t *testing.T) {
 	type args struct {
@@ -1118,6 +1194,28 @@ rules:
     expression: exec.file.name == "foo"
     actions:
       - hash: {}
+  - id: with_network_isolate_action
+    description: Rule with a network isolation action
+    expression: exec.file.name == "foo"
+    actions:
+      - network_isolate:
+          scope: container
+          allow:
+            - 10.0.0.0/8
+            - 169.254.169.254
+          disarmer:
+            container:
+              max_allowed: 5
+              period: 1m
+  - id: with_quarantine_action
+    description: Rule with a quarantine action
+    expression: open.file.path == "/tmp/foo"
+    actions:
+      - quarantine:
+          disarmer:
+            executable:
+              max_allowed: 5
+              period: 1m
 `
 const policyWithMissingRequiredRuleID = `
 version: 1.2.3
@@ -12,6 +12,7 @@ import (
 	"reflect"
 	"slices"
 	"sync"
+	"sync/atomic"
 
 	"github.com/spf13/cast"
 
@@ -29,6 +30,14 @@ type Rule struct {
 	*PolicyRule
 	*eval.Rule
 	NoDiscarder bool
+
+	// set when the rule is a step of a sequence rule
+	sequenceStep *sequenceStep
+}
+
+// IsSequence returns whether the rule is a sequence rule
+func (r *Rule) IsSequence() bool {
+	return r.sequenceStep != nil
 }
 
 // RuleSetListener describes the methods implemented by an object used to be
@@ -62,6 +71,9 @@ type RuleSet struct {
 	// event collector, used for tests
 	eventCollector EventCollector
 
+	// incremented for each evaluated event, used to track sequence rules
+	evalID atomic.Uint64
+
 	OnDemandHookPoints []OnDemandHookPoint
 }
 
@@ -312,48 +324,115 @@ func (rs *RuleSet) AddRule(parsingContext *ast.ParsingContext, pRule *PolicyRule
 		tags = append(tags, k+":"+v)
 	}
 
+	if pRule.Def.Sequence != nil {
+		return rs.addSequenceRule(parsingContext, pRule, tags)
+	}
+
+	rule, eventType, err := rs.compileRule(parsingContext, pRule, pRule.Def.Expression, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rs.compileRuleActions(parsingContext, rule, eventType); err != nil {
+		return nil, err
+	}
+
+	if err := rs.addRuleToBucket(rule, eventType); err != nil {
+		return nil, err
+	}
+
+	rs.rules[pRule.Def.ID] = rule
+
+	return rule.Rule, nil
+}
+
+// addSequenceRule compiles each step of a sequence rule and adds them to the buckets of their events. The rule
+// registered in the rule set is the one of the last step, it's the one reported when the sequence matches.
+func (rs *RuleSet) addSequenceRule(parsingContext *ast.ParsingContext, pRule *PolicyRule, tags []string) (*eval.Rule, error) {
+	tracker, err := newSequenceTracker(pRule.Def.ID, pRule.Def.Sequence, rs.opts.SequenceScopes)
+	if err != nil {
+		return nil, &ErrRuleLoad{Rule: pRule, Err: err}
+	}
+
+	var eventTypes []eval.EventType
+	for i, expression := range pRule.Def.Sequence.Steps {
+		rule, eventType, err := rs.compileRule(parsingContext, pRule, expression, tags)
+		if err != nil {
+			return nil, err
+		}
+		rule.sequenceStep = &sequenceStep{tracker: tracker, index: i}
+
+		tracker.rules = append(tracker.rules, rule)
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	last := len(tracker.rules) - 1
+	if err := rs.compileRuleActions(parsingContext, tracker.rules[last], eventTypes[last]); err != nil {
+		return nil, err
+	}
+
+	// the steps are added in reverse order so that an event matching several steps first advances the pending
+	// sequences before starting a new one
+	for i := last; i >= 0; i-- {
+		if err := rs.addRuleToBucket(tracker.rules[i], eventTypes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	rs.rules[pRule.Def.ID] = tracker.rules[last]
+
+	return tracker.rules[last].Rule, nil
+}
+
+// compileRule creates the evaluator of the given expression and checks that its event type is supported
+func (rs *RuleSet) compileRule(parsingContext *ast.ParsingContext, pRule *PolicyRule, expression string, tags []string) (*Rule, eval.EventType, error) {
 	rule := &Rule{
 		PolicyRule: pRule,
-		Rule:       eval.NewRule(pRule.Def.ID, pRule.Def.Expression, rs.evalOpts, tags...),
+		Rule:       eval.NewRule(pRule.Def.ID, expression, rs.evalOpts, tags...),
 	}
 
 	if err := rule.Parse(parsingContext); err != nil {
-		return nil, &ErrRuleLoad{Rule: pRule, Err: &ErrRuleSyntax{Err: err}}
+		return nil, "", &ErrRuleLoad{Rule: pRule, Err: &ErrRuleSyntax{Err: err}}
 	}
 
 	if err := rule.GenEvaluator(rs.model, parsingContext); err != nil {
-		return nil, &ErrRuleLoad{Rule: pRule, Err: err}
+		return nil, "", &ErrRuleLoad{Rule: pRule, Err: err}
 	}
 
 	eventType, err := GetRuleEventType(rule.Rule)
 	if err != nil {
-		return nil, &ErrRuleLoad{Rule: pRule, Err: err}
+		return nil, "", &ErrRuleLoad{Rule: pRule, Err: err}
 	}
 
 	// validate event context against event type
 	for _, field := range rule.GetFields() {
 		restrictions := rs.model.GetFieldRestrictions(field)
 		if len(restrictions) > 0 && !slices.Contains(restrictions, eventType) {
-			return nil, &ErrRuleLoad{Rule: pRule, Err: &ErrFieldNotAvailable{Field: field, EventType: eventType, RestrictedTo: restrictions}}
+			return nil, "", &ErrRuleLoad{Rule: pRule, Err: &ErrFieldNotAvailable{Field: field, EventType: eventType, RestrictedTo: restrictions}}
 		}
 	}
 
 	// ignore event types not supported
 	if _, exists := rs.opts.EventTypeEnabled["*"]; !exists {
 		if enabled, exists := rs.opts.EventTypeEnabled[eventType]; !exists || !enabled {
-			return nil, &ErrRuleLoad{Rule: pRule, Err: ErrEventTypeNotEnabled}
+			return nil, "", &ErrRuleLoad{Rule: pRule, Err: ErrEventTypeNotEnabled}
 		}
 	}
 
+	return rule, eventType, nil
+}
+
+// compileRuleActions checks that the actions of the rule are available for its event type and compiles their filters
+func (rs *RuleSet) compileRuleActions(parsingContext *ast.ParsingContext, rule *Rule, eventType eval.EventType) error {
 	for _, action := range rule.PolicyRule.Actions {
 		if !rs.isActionAvailable(eventType, action) {
-			return nil, &ErrRuleLoad{Rule: pRule, Err: &ErrActionNotAvailable{ActionName: action.Def.Name(), EventType: eventType}}
+			return &ErrRuleLoad{Rule: rule.PolicyRule, Err: &ErrActionNotAvailable{ActionName: action.Def.Name(), EventType: eventType}}
 		}
 
 		// compile action filter
 		if action.Def.Filter != nil {
 			if err := action.CompileFilter(parsingContext, rs.model, rs.evalOpts); err != nil {
-				return nil, &ErrRuleLoad{Rule: pRule, Err: err}
+				return &ErrRuleLoad{Rule: rule.PolicyRule, Err: err}
 			}
 		}
 
@@ -361,13 +440,17 @@ func (rs *RuleSet) AddRule(parsingContext *ast.ParsingContext, pRule *PolicyRule
 			if _, found := rs.fieldEvaluators[action.Def.Set.Field]; !found {
 				evaluator, err := rs.model.GetEvaluator(action.Def.Set.Field, "")
 				if err != nil {
-					return nil, err
+					return err
 				}
 				rs.fieldEvaluators[action.Def.Set.Field] = evaluator
 			}
 		}
 	}
 
+	return nil
+}
+
+func (rs *RuleSet) addRuleToBucket(rule *Rule, eventType eval.EventType) error {
 	bucket, exists := rs.eventRuleBuckets[eventType]
 	if !exists {
 		bucket = &RuleBucket{}
@@ -375,15 +458,13 @@ func (rs *RuleSet) AddRule(parsingContext *ast.ParsingContext, pRule *PolicyRule
 	}
 
 	if err := bucket.AddRule(rule); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Merge the fields of the new rule with the existing list of fields of the ruleset
 	rs.AddFields(rule.GetEvaluator().GetFields())
 
-	rs.rules[pRule.Def.ID] = rule
-
-	return rule.Rule, nil
+	return nil
 }
 
 // NotifyRuleMatch notifies all the ruleset listeners that an event matched a rule
@@ -467,6 +548,13 @@ func (rs *RuleSet) GetFieldValues(field eval.Field) []eval.FieldValue {
 	var values []eval.FieldValue
 
 	for _, rule := range rs.rules {
+		if rule.sequenceStep != nil {
+			for _, step := range rule.sequenceStep.tracker.rules {
+				values = append(values, step.GetFieldValues(field)...)
+			}
+			continue
+		}
+
 		rv := rule.GetFieldValues(field)
 		if len(rv) > 0 {
 			values = append(values, rv...)
@@ -582,10 +670,19 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 	}
 
 	result := false
+	evalID := rs.evalID.Add(1)
 
 	for _, rule := range bucket.rules {
 		utils.PprofDoWithoutContext(rule.GetPprofLabels(), func() {
 			if rule.GetEvaluator().Eval(ctx) {
+				if rule.sequenceStep != nil {
+					// a step matching is enough to prevent the event from being discarded
+					result = true
+
+					if !rs.matchSequenceStep(event, rule, evalID) {
+						return
+					}
+				}
 
 				if rs.logger.IsTracing() {
 					rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
@@ -605,9 +702,41 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 	// for debugging purposes
 	rs.eventCollector.CollectEvent(rs, event, result)
 
+	// the listeners are done with the steps of the sequences completed by the event
+	if ev, ok := event.(*model.Event); ok && len(ev.SequenceSteps) > 0 {
+		releaseSequenceSteps(ev.SequenceSteps)
+		ev.SequenceSteps = nil
+	}
+
 	return result
 }
 
+// matchSequenceStep tracks the step of the sequence rule matched by the event, it returns true when the whole
+// sequence matched. In that case, the events matching the previous steps are added to the event.
+func (rs *RuleSet) matchSequenceStep(event eval.Event, rule *Rule, evalID uint64) bool {
+	step := rule.sequenceStep
+
+	pm := step.tracker.match(step.index, event, evalID, func() *model.Event {
+		if rs.opts.sequenceEventCaptureCb == nil {
+			return nil
+		}
+		return rs.opts.sequenceEventCaptureCb(rule, event)
+	})
+	if pm == nil {
+		return false
+	}
+
+	if rs.logger.IsTracing() {
+		rs.logger.Tracef("Sequence `%s` completed by event `%s`\n", rule.ID, event)
+	}
+
+	if ev, ok := event.(*model.Event); ok {
+		ev.SequenceSteps = append(ev.SequenceSteps, pm.steps...)
+	}
+
+	return true
+}
+
 // EvaluateDiscarders evaluates the discarders for the given event if any
 func (rs *RuleSet) EvaluateDiscarders(event eval.Event) {
 	ctx := rs.pool.Get(event)
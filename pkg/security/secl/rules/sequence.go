@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package rules holds rules related files
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+const (
+	// DefaultSequenceScope is the scope used by sequence rules when none is specified
+	DefaultSequenceScope Scope = "process_tree"
+	// DefaultSequenceMaxPending is the default maximum number of partial matches tracked per sequence rule
+	DefaultSequenceMaxPending = 128
+	// MaxSequenceMaxPending is the upper bound of the number of partial matches tracked per sequence rule
+	MaxSequenceMaxPending = 4096
+)
+
+// sequenceStep links a rule to a step of a sequence
+type sequenceStep struct {
+	tracker *sequenceTracker
+	index   int
+}
+
+// sequencePartialMatch holds the state of a sequence for which the first steps matched
+type sequencePartialMatch struct {
+	key       string
+	startedAt time.Time
+	next      int
+	evalID    uint64
+	steps     []*model.MatchedSequenceStep
+}
+
+// release releases the events captured by the steps of a partial match that is dropped
+func (pm *sequencePartialMatch) release() {
+	releaseSequenceSteps(pm.steps)
+}
+
+func releaseSequenceSteps(steps []*model.MatchedSequenceStep) {
+	for _, step := range steps {
+		if step.Event != nil {
+			step.Event.Release()
+			step.Event = nil
+		}
+	}
+}
+
+// sequenceTracker tracks the partial matches of a sequence rule. The number of partial matches is bounded, the oldest
+// ones are evicted first.
+type sequenceTracker struct {
+	sync.Mutex
+
+	ruleID     RuleID
+	within     time.Duration
+	maxPending int
+	scopeKeys  SequenceScopeKeysFunc
+	rules      []*Rule
+	now        func() time.Time
+
+	// ordered by start time
+	pending []*sequencePartialMatch
+}
+
+func newSequenceTracker(ruleID RuleID, def *SequenceDefinition, scopes map[Scope]SequenceScopeKeysFunc) (*sequenceTracker, error) {
+	if len(def.Steps) < 2 {
+		return nil, errors.New("a sequence requires at least 2 steps")
+	}
+
+	if def.Within <= 0 {
+		return nil, errors.New("the time window of a sequence must be strictly positive")
+	}
+
+	scope := def.Scope
+	if scope == "" {
+		scope = DefaultSequenceScope
+	}
+
+	scopeKeys := scopes[scope]
+	if scopeKeys == nil {
+		return nil, fmt.Errorf("invalid sequence scope '%s'", scope)
+	}
+
+	maxPending := def.MaxPending
+	if maxPending == 0 {
+		maxPending = DefaultSequenceMaxPending
+	} else if maxPending < 0 || maxPending > MaxSequenceMaxPending {
+		return nil, fmt.Errorf("the maximum number of pending sequences must be between 1 and %d", MaxSequenceMaxPending)
+	}
+
+	return &sequenceTracker{
+		ruleID:     ruleID,
+		within:     def.Within,
+		maxPending: maxPending,
+		scopeKeys:  scopeKeys,
+		now:        time.Now,
+	}, nil
+}
+
+func (st *sequenceTracker) lastStep() int {
+	return len(st.rules) - 1
+}
+
+// expire drops the partial matches that are out of the time window
+func (st *sequenceTracker) expire(now time.Time) {
+	var expired int
+	for _, pm := range st.pending {
+		if now.Sub(pm.startedAt) <= st.within {
+			break
+		}
+		pm.release()
+		expired++
+	}
+	st.pending = st.pending[expired:]
+}
+
+// match records that the given event matched the step at the given index. It returns the partial match that
+// completed the sequence, if any. The capture callback is only called for the steps that are recorded.
+func (st *sequenceTracker) match(index int, event eval.Event, evalID uint64, capture func() *model.Event) *sequencePartialMatch {
+	keys := st.scopeKeys(event)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	st.Lock()
+	defer st.Unlock()
+
+	now := st.now()
+	st.expire(now)
+
+	newStep := func() *model.MatchedSequenceStep {
+		return &model.MatchedSequenceStep{
+			RuleID:    st.ruleID,
+			Index:     index,
+			MatchedAt: now,
+			Event:     capture(),
+		}
+	}
+
+	if index == 0 {
+		// restart the sequence of this scope if it didn't progress yet, so that it gets the whole time window
+		st.pending = slices.DeleteFunc(st.pending, func(pm *sequencePartialMatch) bool {
+			if pm.key == keys[0] && pm.next == 1 {
+				pm.release()
+				return true
+			}
+			return false
+		})
+
+		st.pending = append(st.pending, &sequencePartialMatch{
+			key:       keys[0],
+			startedAt: now,
+			next:      1,
+			evalID:    evalID,
+			steps:     []*model.MatchedSequenceStep{newStep()},
+		})
+
+		if evicted := len(st.pending) - st.maxPending; evicted > 0 {
+			for _, pm := range st.pending[:evicted] {
+				pm.release()
+			}
+			st.pending = st.pending[evicted:]
+		}
+
+		return nil
+	}
+
+	for i, pm := range st.pending {
+		// a single event can't match two steps of the same sequence
+		if pm.next != index || pm.evalID == evalID || !slices.Contains(keys, pm.key) {
+			continue
+		}
+
+		if index == st.lastStep() {
+			st.pending = slices.Delete(st.pending, i, i+1)
+			return pm
+		}
+
+		pm.steps = append(pm.steps, newStep())
+		pm.next++
+		pm.evalID = evalID
+
+		return nil
+	}
+
+	return nil
+}
+
+// pendingCount returns the number of partial matches
+func (st *sequenceTracker) pendingCount() int {
+	st.Lock()
+	defer st.Unlock()
+
+	return len(st.pending)
+}
@@ -0,0 +1,298 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package rules holds rules related files
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+type sequenceTestHandler struct {
+	matches []*model.Event
+	// types of the captured events of the steps, they are only valid while the listeners are notified
+	steps [][]model.EventType
+}
+
+func (h *sequenceTestHandler) RuleMatch(_ *Rule, event eval.Event) bool {
+	ev := event.(*model.Event)
+	h.matches = append(h.matches, ev)
+
+	var steps []model.EventType
+	for _, step := range ev.SequenceSteps {
+		steps = append(steps, step.Event.GetEventType())
+	}
+	h.steps = append(h.steps, steps)
+	return true
+}
+
+func (h *sequenceTestHandler) EventDiscarderFound(_ *RuleSet, _ eval.Event, _ eval.Field, _ eval.EventType) {
+}
+
+func newSequenceTestRuleSet(t *testing.T, def *SequenceDefinition) (*RuleSet, *sequenceTestHandler) {
+	t.Helper()
+
+	ruleOpts, evalOpts := NewBothOpts(map[eval.EventType]bool{"*": true})
+	ruleOpts.WithSequenceEventCaptureCb(func(_ *Rule, event eval.Event) *model.Event {
+		captured := event.(*model.Event).Retain()
+		return &captured
+	})
+	rs := NewRuleSet(&model.Model{}, newFakeEvent, ruleOpts, evalOpts)
+
+	handler := &sequenceTestHandler{}
+	rs.AddListener(handler)
+
+	rule := &PolicyRule{
+		Def: &RuleDefinition{
+			ID:       "curl_shadow",
+			Sequence: def,
+		},
+	}
+
+	if _, err := rs.AddRule(ast.NewParsingContext(false), rule); err != nil {
+		t.Fatal(err)
+	}
+
+	return rs, handler
+}
+
+func newSequenceTestEvent(eventType model.EventType, pid uint32, ancestors ...uint32) *model.Event {
+	ev := model.NewFakeEvent()
+	ev.Type = uint32(eventType)
+	ev.ProcessContext = &model.ProcessContext{}
+	ev.ProcessContext.Pid = pid
+
+	var parent *model.ProcessCacheEntry
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		entry := &model.ProcessCacheEntry{}
+		entry.Pid = ancestors[i]
+		entry.Ancestor = parent
+		parent = entry
+	}
+	ev.ProcessContext.Ancestor = parent
+
+	return ev
+}
+
+func newCurlShadowSequence() *SequenceDefinition {
+	return &SequenceDefinition{
+		Steps: []string{
+			`exec.file.name == "curl"`,
+			`open.file.path == "/etc/shadow"`,
+			`chmod.file.path == "/etc/shadow"`,
+		},
+		Within: 30 * time.Second,
+	}
+}
+
+func TestSequenceRule(t *testing.T) {
+	exec := func(pid uint32, ancestors ...uint32) *model.Event {
+		ev := newSequenceTestEvent(model.ExecEventType, pid, ancestors...)
+		ev.SetFieldValue("exec.file.name", "curl")
+		return ev
+	}
+	open := func(pid uint32, ancestors ...uint32) *model.Event {
+		ev := newSequenceTestEvent(model.FileOpenEventType, pid, ancestors...)
+		ev.SetFieldValue("open.file.path", "/etc/shadow")
+		return ev
+	}
+	chmod := func(pid uint32, ancestors ...uint32) *model.Event {
+		ev := newSequenceTestEvent(model.FileChmodEventType, pid, ancestors...)
+		ev.SetFieldValue("chmod.file.path", "/etc/shadow")
+		return ev
+	}
+
+	t.Run("match", func(t *testing.T) {
+		rs, handler := newSequenceTestRuleSet(t, newCurlShadowSequence())
+
+		assert.True(t, rs.Evaluate(exec(42)))
+		assert.True(t, rs.Evaluate(open(42)))
+		assert.Empty(t, handler.matches)
+
+		last := chmod(42)
+		assert.True(t, rs.Evaluate(last))
+		if assert.Len(t, handler.matches, 1) {
+			// the event completing the sequence isn't a step, the captured events are released once notified
+			assert.Equal(t, []model.EventType{model.ExecEventType, model.FileOpenEventType}, handler.steps[0])
+			assert.Empty(t, handler.matches[0].SequenceSteps)
+		}
+		assert.Zero(t, rs.rules["curl_shadow"].sequenceStep.tracker.pendingCount())
+	})
+
+	t.Run("process-tree", func(t *testing.T) {
+		rs, handler := newSequenceTestRuleSet(t, newCurlShadowSequence())
+
+		rs.Evaluate(exec(42, 1))
+		rs.Evaluate(open(43, 42, 1))
+		rs.Evaluate(chmod(44, 43, 42, 1))
+		assert.Len(t, handler.matches, 1)
+	})
+
+	t.Run("other-process-tree", func(t *testing.T) {
+		rs, handler := newSequenceTestRuleSet(t, newCurlShadowSequence())
+
+		rs.Evaluate(exec(42, 1))
+		rs.Evaluate(open(43, 1))
+		rs.Evaluate(chmod(42, 1))
+		assert.Empty(t, handler.matches)
+	})
+
+	t.Run("out-of-order", func(t *testing.T) {
+		rs, handler := newSequenceTestRuleSet(t, newCurlShadowSequence())
+
+		rs.Evaluate(open(42))
+		rs.Evaluate(exec(42))
+		rs.Evaluate(chmod(42))
+		assert.Empty(t, handler.matches)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		rs, handler := newSequenceTestRuleSet(t, newCurlShadowSequence())
+
+		now := time.Now()
+		rs.rules["curl_shadow"].sequenceStep.tracker.now = func() time.Time {
+			return now
+		}
+
+		rs.Evaluate(exec(42))
+		rs.Evaluate(open(42))
+		now = now.Add(time.Minute)
+		rs.Evaluate(chmod(42))
+		assert.Empty(t, handler.matches)
+		assert.Zero(t, rs.rules["curl_shadow"].sequenceStep.tracker.pendingCount())
+	})
+
+	t.Run("max-pending", func(t *testing.T) {
+		def := newCurlShadowSequence()
+		def.MaxPending = 2
+		rs, handler := newSequenceTestRuleSet(t, def)
+
+		for pid := uint32(42); pid < 45; pid++ {
+			rs.Evaluate(exec(pid))
+		}
+		assert.Equal(t, 2, rs.rules["curl_shadow"].sequenceStep.tracker.pendingCount())
+
+		// the oldest partial match was evicted
+		rs.Evaluate(open(42))
+		rs.Evaluate(chmod(42))
+		assert.Empty(t, handler.matches)
+
+		rs.Evaluate(open(44))
+		rs.Evaluate(chmod(44))
+		assert.Len(t, handler.matches, 1)
+	})
+}
+
+func TestSequenceRuleSameEventType(t *testing.T) {
+	rs, handler := newSequenceTestRuleSet(t, &SequenceDefinition{
+		Steps: []string{
+			`open.file.path =~ "/etc/*"`,
+			`open.file.path == "/etc/shadow"`,
+		},
+		Within: time.Second,
+		Scope:  "process_tree",
+	})
+
+	open := func(path string) *model.Event {
+		ev := newSequenceTestEvent(model.FileOpenEventType, 42)
+		ev.SetFieldValue("open.file.path", path)
+		return ev
+	}
+
+	// a single event can't match both steps
+	rs.Evaluate(open("/etc/shadow"))
+	assert.Empty(t, handler.matches)
+
+	rs.Evaluate(open("/etc/shadow"))
+	assert.Len(t, handler.matches, 1)
+}
+
+func TestSequenceRuleInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		def  *SequenceDefinition
+	}{
+		{
+			name: "single-step",
+			def:  &SequenceDefinition{Steps: []string{`open.file.path == "/etc/shadow"`}, Within: time.Second},
+		},
+		{
+			name: "no-window",
+			def:  &SequenceDefinition{Steps: []string{`exec.file.name == "curl"`, `open.file.path == "/etc/shadow"`}},
+		},
+		{
+			name: "invalid-scope",
+			def:  &SequenceDefinition{Steps: []string{`exec.file.name == "curl"`, `open.file.path == "/etc/shadow"`}, Within: time.Second, Scope: "host"},
+		},
+		{
+			name: "max-pending",
+			def:  &SequenceDefinition{Steps: []string{`exec.file.name == "curl"`, `open.file.path == "/etc/shadow"`}, Within: time.Second, MaxPending: MaxSequenceMaxPending + 1},
+		},
+		{
+			name: "invalid-step",
+			def:  &SequenceDefinition{Steps: []string{`exec.file.name == "curl"`, `open.file.path ==`}, Within: time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs := newRuleSet()
+			_, err := rs.AddRule(ast.NewParsingContext(false), &PolicyRule{
+				Def: &RuleDefinition{ID: fmt.Sprintf("sequence_%s", test.name), Sequence: test.def},
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSequenceRulePolicy(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		policy, err := LoadPolicy("sequence.policy", PolicyProviderTypeRC, strings.NewReader(`
+version: 1.2.3
+rules:
+  - id: curl_shadow
+    sequence:
+      within: 30s
+      scope: container
+      steps:
+        - exec.file.name == "curl"
+        - open.file.path == "/etc/shadow"
+`), nil, nil)
+		assert.NoError(t, err)
+
+		rules := policy.GetAcceptedRules()
+		if assert.Len(t, rules, 1) && assert.NotNil(t, rules[0].Def.Sequence) {
+			assert.Equal(t, 30*time.Second, rules[0].Def.Sequence.Within)
+			assert.Equal(t, Scope("container"), rules[0].Def.Sequence.Scope)
+			assert.Len(t, rules[0].Def.Sequence.Steps, 2)
+		}
+	})
+
+	t.Run("expression-and-sequence", func(t *testing.T) {
+		_, err := LoadPolicy("sequence.policy", PolicyProviderTypeRC, strings.NewReader(`
+version: 1.2.3
+rules:
+  - id: curl_shadow
+    expression: exec.file.name == "curl"
+    sequence:
+      within: 30s
+      steps:
+        - exec.file.name == "curl"
+        - open.file.path == "/etc/shadow"
+`), nil, nil)
+		assert.ErrorContains(t, err, ErrRuleWithExpressionAndSequence.Error())
+	})
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package activitytree holds activitytree related files
+package activitytree
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+// Replay generates an event for each node of the activity tree: an exec event per process node, followed by the open,
+// dns and imds events of the process, and then by the events of its children. The events are allocated with the
+// provided constructor.
+func (at *ActivityTree) Replay(newEvent func() *model.Event, cb func(event *model.Event)) {
+	for _, pn := range at.ProcessNodes {
+		replayProcessNode(pn, nil, newEvent, cb)
+	}
+}
+
+func replayProcessNode(pn *ProcessNode, ancestor *model.ProcessCacheEntry, newEvent func() *model.Event, cb func(event *model.Event)) {
+	entry := &model.ProcessCacheEntry{}
+	entry.Process = pn.Process
+	entry.Ancestor = ancestor
+	if ancestor != nil {
+		entry.Parent = &ancestor.Process
+	}
+
+	newProcessEvent := func(eventType model.EventType) *model.Event {
+		event := newEvent()
+		event.Type = uint32(eventType)
+		event.ProcessCacheEntry = entry
+		event.ProcessContext = &entry.ProcessContext
+		return event
+	}
+
+	exec := newProcessEvent(model.ExecEventType)
+	exec.Exec.Process = &entry.Process
+	cb(exec)
+
+	for _, name := range sortedKeys(pn.Files) {
+		visitFileNodes(pn.Files[name], func(fn *FileNode) {
+			if fn.Open == nil || fn.File == nil {
+				return
+			}
+
+			open := newProcessEvent(model.FileOpenEventType)
+			open.Open.SyscallEvent = fn.Open.SyscallEvent
+			open.Open.File = *fn.File
+			open.Open.Flags = fn.Open.Flags
+			open.Open.Mode = fn.Open.Mode
+			cb(open)
+		})
+	}
+
+	for _, name := range sortedKeys(pn.DNSNames) {
+		for _, request := range pn.DNSNames[name].Requests {
+			dns := newProcessEvent(model.DNSEventType)
+			dns.DNS = request
+			cb(dns)
+		}
+	}
+
+	for imds := range pn.IMDSEvents {
+		event := newProcessEvent(model.IMDSEventType)
+		event.IMDS = imds
+		cb(event)
+	}
+
+	for _, child := range pn.Children {
+		replayProcessNode(child, entry, newEvent, cb)
+	}
+}
+
+// visitFileNodes calls the callback on the file node and all its descendants, sorted by name
+func visitFileNodes(fn *FileNode, cb func(fn *FileNode)) {
+	cb(fn)
+	for _, name := range sortedKeys(fn.Children) {
+		visitFileNodes(fn.Children[name], cb)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -9,9 +9,12 @@ package serializers
 
 import (
 	json "encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
+	secconfig "github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/containerutils"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
 )
@@ -36,8 +39,6 @@ func newFileEvent(fs *FileSerializer) model.FileEvent {
 			Group:        fs.Group,
 			InUpperLayer: getPointerValue(fs.InUpperLayer),
 			Mode:         uint16(getPointerValue(fs.Mode)),
-			MTime:        uint64(fs.Mtime.GetInnerTime().UnixMicro()),
-			CTime:        uint64(fs.Ctime.GetInnerTime().UnixMicro()),
 			PathKey: model.PathKey{
 				Inode:   getPointerValue(fs.Inode),
 				MountID: getPointerValue(fs.MountID),
@@ -49,15 +50,44 @@ func newFileEvent(fs *FileSerializer) model.FileEvent {
 		IsBasenameStrResolved: true,
 		HashState:             model.NoHash,
 	}
+	if fs.Mtime != nil {
+		file.MTime = uint64(fs.Mtime.GetInnerTime().UnixMicro())
+	}
+	if fs.Ctime != nil {
+		file.CTime = uint64(fs.Ctime.GetInnerTime().UnixMicro())
+	}
 	return file
 }
 
+func newCredentials(cs *ProcessCredentialsSerializer) model.Credentials {
+	return model.Credentials{
+		UID:          uint32(cs.UID),
+		GID:          uint32(cs.GID),
+		User:         cs.User,
+		Group:        cs.Group,
+		EUID:         uint32(cs.EUID),
+		EGID:         uint32(cs.EGID),
+		EUser:        cs.EUser,
+		EGroup:       cs.EGroup,
+		FSUID:        uint32(cs.FSUID),
+		FSGID:        uint32(cs.FSGID),
+		FSUser:       cs.FSUser,
+		FSGroup:      cs.FSGroup,
+		AUID:         uint32(cs.AUID),
+		CapEffective: uint64(parseConstants(cs.CapEffective)),
+		CapPermitted: uint64(parseConstants(cs.CapPermitted)),
+	}
+}
+
 func newProcess(ps *ProcessSerializer) model.Process {
+	if ps == nil {
+		return model.Process{}
+	}
+
 	p := model.Process{
 		PPid:          getPointerValue(ps.PPid),
 		Comm:          ps.Comm,
 		TTYName:       ps.TTY,
-		FileEvent:     newFileEvent(ps.Executable),
 		Argv0:         ps.Argv0,
 		Argv:          ps.Args,
 		ArgsTruncated: ps.ArgsTruncated,
@@ -71,6 +101,15 @@ func newProcess(ps *ProcessSerializer) model.Process {
 			IsKworker: ps.IsKworker,
 		},
 	}
+	if ps.Executable != nil {
+		p.FileEvent = newFileEvent(ps.Executable)
+	}
+	if ps.Interpreter != nil {
+		p.LinuxBinprm.FileEvent = newFileEvent(ps.Interpreter)
+	}
+	if ps.Credentials != nil {
+		p.Credentials = newCredentials(ps.Credentials)
+	}
 	if ps.ForkTime != nil {
 		p.ForkTime = ps.ForkTime.GetInnerTime()
 	}
@@ -84,37 +123,108 @@ func newProcess(ps *ProcessSerializer) model.Process {
 		p.ContainerID = containerutils.ContainerID(ps.Container.ID)
 	}
 
-	// TODO: credentials
 	return p
 }
 
-// UnmarshalEvent unmarshal an model.Event (only exec one for now)
+// parseConstants converts a list of SECL constants, such as open flags or capabilities, to the corresponding bitmask
+func parseConstants(names []string) int {
+	constants := model.SECLConstants()
+
+	var value int
+	for _, name := range names {
+		if evaluator, ok := constants[name].(*eval.IntEvaluator); ok {
+			value |= evaluator.Value
+		}
+	}
+	return value
+}
+
+// setFileEventFields restores the fields of the file event types
+func setFileEventFields(event *model.Event, fs *FileEventSerializer) error {
+	if fs == nil {
+		return fmt.Errorf("missing file context for %s event", event.GetEventType())
+	}
+
+	file := newFileEvent(&fs.FileSerializer)
+	var destination model.FileEvent
+	if fs.Destination != nil {
+		destination = newFileEvent(fs.Destination)
+	}
+
+	switch event.GetEventType() {
+	case model.FileOpenEventType:
+		event.Open.File = file
+		event.Open.Flags = uint32(parseConstants(fs.Flags))
+		event.Open.Mode = uint32(destination.Mode)
+	case model.FileChmodEventType:
+		event.Chmod.File = file
+		event.Chmod.Mode = uint32(destination.Mode)
+	case model.FileChownEventType:
+		event.Chown.File = file
+		if fs.Destination != nil {
+			event.Chown.UID = fs.Destination.UID
+			event.Chown.GID = fs.Destination.GID
+		}
+	case model.FileMkdirEventType:
+		event.Mkdir.File = file
+		event.Mkdir.Mode = uint32(destination.Mode)
+	case model.FileRmdirEventType:
+		event.Rmdir.File = file
+	case model.FileUnlinkEventType:
+		event.Unlink.File = file
+		event.Unlink.Flags = uint32(parseConstants(fs.Flags))
+	case model.FileRenameEventType:
+		event.Rename.Old = file
+		event.Rename.New = destination
+	case model.FileLinkEventType:
+		event.Link.Source = file
+		event.Link.Target = destination
+	case model.FileUtimesEventType:
+		event.Utimes.File = file
+	case model.FileChdirEventType:
+		event.Chdir.File = file
+	}
+
+	return nil
+}
+
+// UnmarshalEvent unmarshal an model.Event. The process context is restored for all the event types, the event
+// specific fields only for the exec, exit, file and DNS events.
 func UnmarshalEvent(raw []byte) (*model.Event, error) {
 	rawEvent := EventSerializer{}
 	err := json.Unmarshal(raw, &rawEvent)
 	if err != nil {
 		return nil, err
 	}
-	if rawEvent.EventContextSerializer.Name != "exec" {
-		return nil, fmt.Errorf("Unmarshalling of event %v is not yet supported", rawEvent.EventContextSerializer.Name)
+	if rawEvent.BaseEventSerializer == nil || rawEvent.ProcessContextSerializer == nil {
+		return nil, errors.New("missing event or process context")
+	}
+
+	eventType := secconfig.ParseEvalEventType(rawEvent.EventContextSerializer.Name)
+	if eventType == model.UnknownEventType {
+		return nil, fmt.Errorf("unknown event type `%s`", rawEvent.EventContextSerializer.Name)
 	}
 
-	parent := newProcess(rawEvent.ProcessContextSerializer.Parent)
+	var parent *model.Process
+	if rawEvent.ProcessContextSerializer.Parent != nil {
+		p := newProcess(rawEvent.ProcessContextSerializer.Parent)
+		parent = &p
+	}
 	process := newProcess(rawEvent.ProcessContextSerializer.ProcessSerializer)
 	event := model.Event{
 		BaseEvent: model.BaseEvent{
-			Type:             uint32(model.ExecEventType),
+			Type:             uint32(eventType),
 			FieldHandlers:    &model.FakeFieldHandlers{},
 			ContainerContext: &model.ContainerContext{},
 			ProcessContext: &model.ProcessContext{
 				Process:  process,
-				Parent:   &parent,
+				Parent:   parent,
 				Ancestor: nil,
 			},
 		},
 	}
-	event.BaseEvent.ProcessCacheEntry = &model.ProcessCacheEntry{
-		ProcessContext: *event.BaseEvent.ProcessContext,
+	if rawEvent.ContainerContextSerializer != nil {
+		event.ContainerContext.ContainerID = containerutils.ContainerID(rawEvent.ContainerContextSerializer.ID)
 	}
 
 	// Fill ancestors
@@ -136,6 +246,36 @@ func UnmarshalEvent(raw []byte) (*model.Event, error) {
 		ProcessContext: *event.BaseEvent.ProcessContext,
 	}
 
+	switch eventType {
+	case model.ExecEventType:
+		event.Exec.Process = &event.ProcessContext.Process
+	case model.ExitEventType:
+		event.Exit.Process = &event.ProcessContext.Process
+		if rawEvent.ExitEventSerializer != nil {
+			event.Exit.Cause = uint32(parseConstants([]string{rawEvent.ExitEventSerializer.Cause}))
+			event.Exit.Code = rawEvent.ExitEventSerializer.Code
+		}
+	case model.DNSEventType:
+		if rawEvent.DNSEventSerializer == nil {
+			return nil, errors.New("missing DNS context")
+		}
+		question := rawEvent.DNSEventSerializer.Question
+		event.DNS = model.DNSEvent{
+			ID:    rawEvent.DNSEventSerializer.ID,
+			Name:  question.Name,
+			Type:  uint16(model.DNSQTypeConstants[question.Type]),
+			Class: uint16(model.DNSQClassConstants[question.Class]),
+			Size:  question.Size,
+			Count: question.Count,
+		}
+	case model.FileOpenEventType, model.FileChmodEventType, model.FileChownEventType, model.FileMkdirEventType,
+		model.FileRmdirEventType, model.FileUnlinkEventType, model.FileRenameEventType, model.FileLinkEventType,
+		model.FileUtimesEventType, model.FileChdirEventType:
+		if err := setFileEventFields(&event, rawEvent.FileEventSerializer); err != nil {
+			return nil, err
+		}
+	}
+
 	return &event, nil
 }
 
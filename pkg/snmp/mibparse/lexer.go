@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package mibparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenNumber
+	tokenString
+	// tokenBinaryString is a hex ('0F'H) or binary ('01'B) string, only found in DEFVAL clauses
+	tokenBinaryString
+	tokenPunctuation
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q (line %d)", t.text, t.line)
+}
+
+// tokenize splits the content of a MIB file into ASN.1 tokens, comments are dropped
+func tokenize(content string) ([]token, error) {
+	var tokens []token
+	line := 1
+	i := 0
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(content[i:], "--"):
+			// a comment ends at the end of the line or at the next "--"
+			i += 2
+			for i < len(content) && content[i] != '\n' {
+				if strings.HasPrefix(content[i:], "--") {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start := line
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(content) {
+					return nil, fmt.Errorf("unterminated string starting at line %d", start)
+				}
+				if content[i] == '"' {
+					// a doubled quote is an escaped quote
+					if i+1 < len(content) && content[i+1] == '"' {
+						sb.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if content[i] == '\n' {
+					line++
+				}
+				sb.WriteByte(content[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), line: start})
+		case c == '\'':
+			end := strings.IndexByte(content[i+1:], '\'')
+			if end < 0 || i+end+2 >= len(content) {
+				return nil, fmt.Errorf("unterminated binary string at line %d", line)
+			}
+			text := content[i : i+end+3]
+			line += strings.Count(text, "\n")
+			tokens = append(tokens, token{kind: tokenBinaryString, text: text, line: line})
+			i += end + 3
+		case strings.HasPrefix(content[i:], "::="):
+			tokens = append(tokens, token{kind: tokenPunctuation, text: "::=", line: line})
+			i += 3
+		case strings.HasPrefix(content[i:], ".."):
+			tokens = append(tokens, token{kind: tokenPunctuation, text: "..", line: line})
+			i += 2
+		case isDigit(c) || (c == '-' && i+1 < len(content) && isDigit(content[i+1])):
+			start := i
+			i++
+			for i < len(content) && isDigit(content[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: content[start:i], line: line})
+		case isLetter(c):
+			start := i
+			for i < len(content) && (isLetter(content[i]) || isDigit(content[i]) || content[i] == '-' || content[i] == '_') {
+				// "--" starts a comment, even right after an identifier
+				if strings.HasPrefix(content[i:], "--") {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: strings.TrimRight(content[start:i], "-"), line: line})
+		default:
+			tokens = append(tokens, token{kind: tokenPunctuation, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
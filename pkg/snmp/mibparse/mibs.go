@@ -0,0 +1,437 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package mibparse
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// wellKnownNodes are the OID registrations of the base SMI modules (SNMPv2-SMI, RFC1155-SMI...)
+// so that MIBs can be resolved without loading them
+var wellKnownNodes = map[string]string{
+	"ccitt":           "0",
+	"zeroDotZero":     "0.0",
+	"iso":             "1",
+	"joint-iso-ccitt": "2",
+	"org":             "1.3",
+	"dod":             "1.3.6",
+	"internet":        "1.3.6.1",
+	"directory":       "1.3.6.1.1",
+	"mgmt":            "1.3.6.1.2",
+	"mib-2":           "1.3.6.1.2.1",
+	"system":          "1.3.6.1.2.1.1",
+	"interfaces":      "1.3.6.1.2.1.2",
+	"ip":              "1.3.6.1.2.1.4",
+	"transmission":    "1.3.6.1.2.1.10",
+	"snmp":            "1.3.6.1.2.1.11",
+	"experimental":    "1.3.6.1.3",
+	"private":         "1.3.6.1.4",
+	"enterprises":     "1.3.6.1.4.1",
+	"security":        "1.3.6.1.5",
+	"snmpV2":          "1.3.6.1.6",
+	"snmpDomains":     "1.3.6.1.6.1",
+	"snmpProxys":      "1.3.6.1.6.2",
+	"snmpModules":     "1.3.6.1.6.3",
+}
+
+// SMI base types
+const (
+	TypeInteger          = "INTEGER"
+	TypeInteger32        = "Integer32"
+	TypeUnsigned32       = "Unsigned32"
+	TypeCounter          = "Counter"
+	TypeCounter32        = "Counter32"
+	TypeCounter64        = "Counter64"
+	TypeGauge            = "Gauge"
+	TypeGauge32          = "Gauge32"
+	TypeTimeTicks        = "TimeTicks"
+	TypeOctetString      = "OCTET STRING"
+	TypeObjectIdentifier = "OBJECT IDENTIFIER"
+	TypeIPAddress        = "IpAddress"
+	TypeNetworkAddress   = "NetworkAddress"
+	TypeOpaque           = "Opaque"
+	TypeBits             = "BITS"
+)
+
+var baseTypes = map[string]bool{
+	TypeInteger:          true,
+	TypeInteger32:        true,
+	TypeUnsigned32:       true,
+	TypeCounter:          true,
+	TypeCounter32:        true,
+	TypeCounter64:        true,
+	TypeGauge:            true,
+	TypeGauge32:          true,
+	TypeTimeTicks:        true,
+	TypeOctetString:      true,
+	TypeObjectIdentifier: true,
+	TypeIPAddress:        true,
+	TypeNetworkAddress:   true,
+	TypeOpaque:           true,
+	TypeBits:             true,
+}
+
+// wellKnownTypes are the textual conventions of the base SMI modules (SNMPv2-TC, RFC1213-MIB...)
+// so that MIBs can be resolved without loading them
+var wellKnownTypes = map[string]*Syntax{
+	"DisplayString":        {Type: TypeOctetString},
+	"PhysAddress":          {Type: TypeOctetString},
+	"MacAddress":           {Type: TypeOctetString},
+	"DateAndTime":          {Type: TypeOctetString},
+	"SnmpAdminString":      {Type: TypeOctetString},
+	"InetAddress":          {Type: TypeOctetString},
+	"TruthValue":           {Type: TypeInteger, NamedNumbers: []NamedNumber{{"true", 1}, {"false", 2}}},
+	"RowStatus":            {Type: TypeInteger},
+	"StorageType":          {Type: TypeInteger},
+	"TestAndIncr":          {Type: TypeInteger},
+	"TimeInterval":         {Type: TypeInteger},
+	"TimeStamp":            {Type: TypeTimeTicks},
+	"AutonomousType":       {Type: TypeObjectIdentifier},
+	"VariablePointer":      {Type: TypeObjectIdentifier},
+	"RowPointer":           {Type: TypeObjectIdentifier},
+	"InterfaceIndex":       {Type: TypeInteger32},
+	"InterfaceIndexOrZero": {Type: TypeInteger32},
+	"InetAddressType":      {Type: TypeInteger},
+	"InetPortNumber":       {Type: TypeUnsigned32},
+}
+
+// ResolveError is returned for an object whose OID cannot be resolved
+type ResolveError struct {
+	Module string
+	Object string
+	Err    error
+}
+
+func (e *ResolveError) Error() string {
+	return fmt.Sprintf("%s::%s: %v", e.Module, e.Object, e.Err)
+}
+
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// MIBs is a set of MIB modules, objects are resolved across modules through their imports
+type MIBs struct {
+	modules []*Module
+	byName  map[string]*Module
+	byOID   map[string]*Object
+}
+
+// NewMIBs returns an empty set of MIB modules
+func NewMIBs() *MIBs {
+	return &MIBs{
+		byName: make(map[string]*Module),
+		byOID:  make(map[string]*Object),
+	}
+}
+
+// LoadFile parses a MIB file and adds its modules to the set, it returns the loaded modules
+func (m *MIBs) LoadFile(path string) ([]*Module, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := m.Load(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	return modules, nil
+}
+
+// Load parses the content of a MIB file and adds its modules to the set, it returns the loaded modules.
+// A module already in the set is replaced.
+func (m *MIBs) Load(content string) ([]*Module, error) {
+	modules, err := parseModules(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range modules {
+		if _, ok := m.byName[module.Name]; ok {
+			for i := range m.modules {
+				if m.modules[i].Name == module.Name {
+					m.modules[i] = module
+				}
+			}
+		} else {
+			m.modules = append(m.modules, module)
+		}
+		m.byName[module.Name] = module
+	}
+	return modules, nil
+}
+
+// Modules returns the modules of the set, in load order
+func (m *MIBs) Modules() []*Module {
+	return m.modules
+}
+
+// Module returns the module with the given name, or nil
+func (m *MIBs) Module(name string) *Module {
+	return m.byName[name]
+}
+
+// Resolve computes the OID of all objects. Objects whose OID cannot be resolved
+// (e.g. because a module they depend on is not loaded) are left without OID and reported as errors.
+func (m *MIBs) Resolve() []*ResolveError {
+	var errs []*ResolveError
+	m.byOID = make(map[string]*Object)
+	for _, module := range m.modules {
+		for _, object := range module.Objects {
+			object.OID = ""
+		}
+	}
+	for _, module := range m.modules {
+		for _, object := range module.Objects {
+			oid, err := m.resolveOID(object, make(map[*Object]bool))
+			if err != nil {
+				errs = append(errs, &ResolveError{Module: module.Name, Object: object.Name, Err: err})
+				continue
+			}
+			object.OID = oid
+			if _, ok := m.byOID[oid]; !ok || object.Kind != KindNode {
+				m.byOID[oid] = object
+			}
+		}
+	}
+	return errs
+}
+
+func (m *MIBs) resolveOID(object *Object, visiting map[*Object]bool) (string, error) {
+	if object.OID != "" {
+		return object.OID, nil
+	}
+	if visiting[object] {
+		return "", fmt.Errorf("circular OID definition")
+	}
+	visiting[object] = true
+
+	if object.trapNumber != "" {
+		// SMIv1 trap OIDs are `<enterprise>.0.<trap number>` (RFC 3584 section 3)
+		enterpriseOID, err := m.resolveName(object.Module, object.trapEnterprise, visiting)
+		if err != nil {
+			return "", err
+		}
+		return enterpriseOID + ".0." + object.trapNumber, nil
+	}
+
+	var parts []string
+	for i, component := range object.oidValue {
+		if component.hasNumber {
+			parts = append(parts, strconv.FormatUint(component.number, 10))
+			continue
+		}
+		if i > 0 {
+			return "", fmt.Errorf("OID component %s has no number", component.name)
+		}
+		parentOID, err := m.resolveName(object.Module, component.name, visiting)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, parentOID)
+	}
+	object.OID = strings.Join(parts, ".")
+	return object.OID, nil
+}
+
+// resolveName resolves the OID of a name referenced by a module
+func (m *MIBs) resolveName(moduleName string, name string, visiting map[*Object]bool) (string, error) {
+	if object := m.lookupObject(moduleName, name); object != nil {
+		return m.resolveOID(object, visiting)
+	}
+	if oid, ok := wellKnownNodes[name]; ok {
+		return oid, nil
+	}
+	if module := m.byName[moduleName]; module != nil {
+		if from, ok := module.Imports[name]; ok {
+			return "", fmt.Errorf("unknown object %s, imported from %s", name, from)
+		}
+	}
+	return "", fmt.Errorf("unknown object %s", name)
+}
+
+// lookupObject returns the object referenced by name in a module: defined by the module itself,
+// by the module it is imported from, or otherwise by any loaded module
+func (m *MIBs) lookupObject(moduleName string, name string) *Object {
+	if module := m.byName[moduleName]; module != nil {
+		if object := module.Object(name); object != nil {
+			return object
+		}
+		if from, ok := module.Imports[name]; ok {
+			if fromModule := m.byName[from]; fromModule != nil {
+				if object := fromModule.Object(name); object != nil {
+					return object
+				}
+			}
+		}
+	}
+	for _, other := range m.modules {
+		if object := other.Object(name); object != nil {
+			return object
+		}
+	}
+	return nil
+}
+
+// Object returns an object referenced by name in a module, or nil.
+// Name can be qualified with the module defining it, e.g. `IF-MIB::ifTable`.
+func (m *MIBs) Object(moduleName string, name string) *Object {
+	if qualifiedModule, qualifiedName, ok := strings.Cut(name, "::"); ok {
+		if module := m.byName[qualifiedModule]; module != nil {
+			return module.Object(qualifiedName)
+		}
+		return nil
+	}
+	return m.lookupObject(moduleName, name)
+}
+
+// ObjectByOID returns the object with the given OID, or nil. Resolve must be called first.
+func (m *MIBs) ObjectByOID(oid string) *Object {
+	return m.byOID[oid]
+}
+
+// Children returns the objects whose OID is directly under the given object, sorted by OID
+func (m *MIBs) Children(parent *Object) []*Object {
+	var children []*Object
+	for oid, object := range m.byOID {
+		if parentOID, _, ok := cutLastArc(oid); ok && parentOID == parent.OID {
+			children = append(children, object)
+		}
+	}
+	sortByOID(children)
+	return children
+}
+
+// Descendants returns the object and all the objects whose OID is under it, sorted by OID
+func (m *MIBs) Descendants(root *Object) []*Object {
+	var objects []*Object
+	for oid, object := range m.byOID {
+		if oid == root.OID || strings.HasPrefix(oid, root.OID+".") {
+			objects = append(objects, object)
+		}
+	}
+	sortByOID(objects)
+	return objects
+}
+
+// Table returns the table and the entry of a column or an entry, or nil if the object is not part of a table
+func (m *MIBs) Table(object *Object) (*Object, *Object) {
+	entry := object
+	if !object.IsEntry() {
+		entry = m.parent(object)
+		if entry == nil || !entry.IsEntry() {
+			return nil, nil
+		}
+	}
+	table := m.parent(entry)
+	if table == nil || !table.IsTable() {
+		return nil, nil
+	}
+	return table, entry
+}
+
+// EntryIndex returns the INDEX of a table entry, following AUGMENTS
+func (m *MIBs) EntryIndex(entry *Object) []string {
+	for i := 0; i < 10 && entry != nil; i++ {
+		if len(entry.Index) > 0 || entry.Augments == "" {
+			return entry.Index
+		}
+		entry = m.lookupObject(entry.Module, entry.Augments)
+	}
+	return nil
+}
+
+func (m *MIBs) parent(object *Object) *Object {
+	parentOID, _, ok := cutLastArc(object.OID)
+	if !ok {
+		return nil
+	}
+	return m.byOID[parentOID]
+}
+
+// BaseType returns the SMI base type of an object (e.g. `Counter32` or `OCTET STRING`) following
+// textual conventions, and its named numbers. The base type is empty if it cannot be resolved.
+func (m *MIBs) BaseType(object *Object) (string, []NamedNumber) {
+	if object.Syntax == nil {
+		return "", nil
+	}
+	syntax := object.Syntax
+	namedNumbers := syntax.NamedNumbers
+	moduleName := object.Module
+	for i := 0; i < 10; i++ {
+		if baseTypes[syntax.Type] {
+			return syntax.Type, namedNumbers
+		}
+		var typeSyntax *Syntax
+		typeSyntax, moduleName = m.lookupType(moduleName, syntax.Type)
+		if typeSyntax == nil {
+			return "", namedNumbers
+		}
+		syntax = typeSyntax
+		if len(namedNumbers) == 0 {
+			namedNumbers = syntax.NamedNumbers
+		}
+	}
+	return "", namedNumbers
+}
+
+func (m *MIBs) lookupType(moduleName string, name string) (*Syntax, string) {
+	if module := m.byName[moduleName]; module != nil {
+		if syntax, ok := module.Types[name]; ok {
+			return syntax, moduleName
+		}
+		if from, ok := module.Imports[name]; ok {
+			if fromModule := m.byName[from]; fromModule != nil {
+				if syntax, ok := fromModule.Types[name]; ok {
+					return syntax, from
+				}
+			}
+		}
+	}
+	if syntax, ok := wellKnownTypes[name]; ok {
+		return syntax, moduleName
+	}
+	for _, module := range m.modules {
+		if syntax, ok := module.Types[name]; ok {
+			return syntax, module.Name
+		}
+	}
+	return nil, moduleName
+}
+
+func cutLastArc(oid string) (string, string, bool) {
+	idx := strings.LastIndexByte(oid, '.')
+	if idx < 0 {
+		return "", "", false
+	}
+	return oid[:idx], oid[idx+1:], true
+}
+
+// sortByOID sorts objects by numeric OID
+func sortByOID(objects []*Object) {
+	sort.Slice(objects, func(i, j int) bool {
+		return compareOIDs(objects[i].OID, objects[j].OID) < 0
+	})
+}
+
+func compareOIDs(a string, b string) int {
+	aArcs := strings.Split(a, ".")
+	bArcs := strings.Split(b, ".")
+	for i := 0; i < len(aArcs) && i < len(bArcs); i++ {
+		aArc, _ := strconv.ParseUint(aArcs[i], 10, 64)
+		bArc, _ := strconv.ParseUint(bArcs[i], 10, 64)
+		if aArc != bArc {
+			if aArc < bArc {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(aArcs) - len(bArcs)
+}
@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package mibparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestMIBs(t *testing.T) *MIBs {
+	mibs := NewMIBs()
+	_, err := mibs.LoadFile("testdata/DATADOG-TEST-MIB.my")
+	require.NoError(t, err)
+	_, err = mibs.LoadFile("testdata/DATADOG-TEST-V1-MIB.my")
+	require.NoError(t, err)
+	require.Empty(t, mibs.Resolve())
+	return mibs
+}
+
+func TestMIBs_LoadFile(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	require.Len(t, mibs.Modules(), 2)
+	module := mibs.Module("DATADOG-TEST-MIB")
+	require.NotNil(t, module)
+	assert.Equal(t, "SNMPv2-SMI", module.Imports["Counter64"])
+	assert.Equal(t, "SNMP-FRAMEWORK-MIB", module.Imports["SnmpAdminString"])
+	assert.Contains(t, module.Types, "TestStatus")
+	assert.Contains(t, module.Types, "TestPortEntry")
+
+	identity := module.Object("datadogTestMIB")
+	require.NotNil(t, identity)
+	assert.Equal(t, KindNode, identity.Kind)
+	assert.Equal(t, "1.3.6.1.4.1.99999", identity.OID)
+	assert.Equal(t, "Test MIB for the profile generator.", identity.Description)
+
+	scalar := module.Object("testTotalRequests")
+	require.NotNil(t, scalar)
+	assert.Equal(t, &Object{
+		Name:        "testTotalRequests",
+		Module:      "DATADOG-TEST-MIB",
+		Kind:        KindObjectType,
+		OID:         "1.3.6.1.4.1.99999.1.2",
+		Syntax:      &Syntax{Type: "Counter64"},
+		Access:      "read-only",
+		Status:      "current",
+		Units:       "requests",
+		Description: "Total requests.",
+		oidValue:    []oidComponent{{name: "testObjects"}, {number: 2, hasNumber: true}},
+	}, scalar)
+
+	table := module.Object("testPortTable")
+	require.NotNil(t, table)
+	assert.True(t, table.IsTable())
+	assert.Equal(t, &Syntax{Type: "SEQUENCE OF", Entry: "TestPortEntry"}, table.Syntax)
+
+	entry := module.Object("testPortEntry")
+	assert.True(t, entry.IsEntry())
+	assert.Equal(t, []string{"testPortIndex"}, entry.Index)
+	assert.Equal(t, []string{"testUserName"}, module.Object("testUserEntry").Index)
+	assert.Equal(t, "testPortEntry", module.Object("testPortXEntry").Augments)
+
+	assert.Equal(t, "Octets received\n                 on the port.", module.Object("testPortInOctets").Description)
+
+	notification := module.Object("testPortDown")
+	assert.Equal(t, KindNotification, notification.Kind)
+	assert.Equal(t, "1.3.6.1.4.1.99999.0.1", notification.OID)
+	assert.Equal(t, []string{"testPortName", "testPortStatus", "testPortFlags"}, notification.Objects)
+}
+
+func TestMIBs_SMIv1(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	module := mibs.Module("DATADOG-TEST-V1-MIB")
+	require.NotNil(t, module)
+
+	errors := module.Object("testV1Errors")
+	assert.Equal(t, "1.3.6.1.4.1.99998.1.1", errors.OID)
+	assert.Equal(t, "read-only", errors.Access)
+	assert.Equal(t, "mandatory", errors.Status)
+
+	trap := module.Object("testV1StateChange")
+	assert.Equal(t, KindNotification, trap.Kind)
+	assert.Equal(t, "1.3.6.1.4.1.99998.0.2", trap.OID)
+	assert.Equal(t, []string{"testV1State"}, trap.Objects)
+	assert.Equal(t, "The state changed.", trap.Description)
+}
+
+func TestMIBs_BaseType(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	tests := []struct {
+		object               string
+		expectedType         string
+		expectedNamedNumbers []NamedNumber
+	}{
+		{"testUptime", TypeTimeTicks, nil},
+		{"testVersion", TypeOctetString, nil},
+		{"testPortStatus", TypeInteger, []NamedNumber{{"ok", 1}, {"failed", 2}, {"unknown", -1}}},
+		{"testPortFlags", TypeBits, []NamedNumber{{"active", 0}, {"shutdown", 1}}},
+		{"testPortEnabled", TypeInteger, []NamedNumber{{"true", 1}, {"false", 2}}},
+		{"testUserName", TypeOctetString, nil},
+		{"testPortTable", "", nil},
+		{"testV1Load", TypeGauge, nil},
+		{"testV1State", TypeInteger, []NamedNumber{{"running", 1}, {"stopped", 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.object, func(t *testing.T) {
+			object := mibs.Object("DATADOG-TEST-MIB", tt.object)
+			require.NotNil(t, object)
+			baseType, namedNumbers := mibs.BaseType(object)
+			assert.Equal(t, tt.expectedType, baseType)
+			assert.Equal(t, tt.expectedNamedNumbers, namedNumbers)
+		})
+	}
+}
+
+func TestMIBs_Tables(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	column := mibs.Object("", "DATADOG-TEST-MIB::testPortOutOctets")
+	require.NotNil(t, column)
+	table, entry := mibs.Table(column)
+	require.NotNil(t, table)
+	assert.Equal(t, "testPortXTable", table.Name)
+	assert.Equal(t, "testPortXEntry", entry.Name)
+	assert.Equal(t, []string{"testPortIndex"}, mibs.EntryIndex(entry))
+
+	table, _ = mibs.Table(mibs.Object("DATADOG-TEST-MIB", "testTotalRequests"))
+	assert.Nil(t, table)
+
+	var columns []string
+	for _, child := range mibs.Children(mibs.Object("DATADOG-TEST-MIB", "testPortEntry")) {
+		columns = append(columns, child.Name)
+	}
+	assert.Equal(t, []string{"testPortIndex", "testPortName", "testPortStatus", "testPortFlags", "testPortInOctets", "testPortQueueDepth", "testPortEnabled"}, columns)
+
+	assert.Len(t, mibs.Descendants(mibs.Object("DATADOG-TEST-MIB", "testUserTable")), 4)
+	assert.Equal(t, "testPortTable", mibs.ObjectByOID("1.3.6.1.4.1.99999.1.10").Name)
+}
+
+func TestMIBs_Resolve_Unresolved(t *testing.T) {
+	mibs := NewMIBs()
+	_, err := mibs.Load(`
+TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS vendorRoot FROM VENDOR-MIB;
+testRoot OBJECT IDENTIFIER ::= { vendorRoot 1 }
+testChild OBJECT IDENTIFIER ::= { testRoot 2 }
+testOther OBJECT IDENTIFIER ::= { iso 3 6 1 4 1 12345 }
+END
+`)
+	require.NoError(t, err)
+	errs := mibs.Resolve()
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "TEST-MIB::testRoot: unknown object vendorRoot, imported from VENDOR-MIB")
+	assert.EqualError(t, errs[1], "TEST-MIB::testChild: unknown object vendorRoot, imported from VENDOR-MIB")
+	assert.Equal(t, "1.3.6.1.4.1.12345", mibs.Module("TEST-MIB").Object("testOther").OID)
+}
+
+func TestMIBs_Load_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			content:       "-- only a comment",
+			expectedError: "no MIB module found",
+		},
+		{
+			name:          "missing END",
+			content:       "TEST-MIB DEFINITIONS ::= BEGIN\ntestRoot OBJECT IDENTIFIER ::= { enterprises 1 }\n",
+			expectedError: "module TEST-MIB: missing END",
+		},
+		{
+			name:          "unterminated string",
+			content:       "TEST-MIB DEFINITIONS ::= BEGIN\n\"description\nEND\n",
+			expectedError: "unterminated string starting at line 2",
+		},
+		{
+			name:          "invalid OID value",
+			content:       "TEST-MIB DEFINITIONS ::= BEGIN\ntestRoot OBJECT IDENTIFIER ::= { enterprises , }\nEND\n",
+			expectedError: "module TEST-MIB: testRoot: unexpected \",\" (line 2) in OID value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMIBs().Load(tt.content)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
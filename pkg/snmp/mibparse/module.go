@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package mibparse parses SMIv1 and SMIv2 MIB modules, without requiring any external tool,
+// to resolve their objects OIDs, types, indexes and notifications.
+package mibparse
+
+// ObjectKind is the kind of definition of a MIB object
+type ObjectKind int
+
+const (
+	// KindNode is an OID registration without data (OBJECT IDENTIFIER, MODULE-IDENTITY, OBJECT-GROUP...)
+	KindNode ObjectKind = iota
+	// KindObjectType is an OBJECT-TYPE: a table, a table entry, a column or a scalar
+	KindObjectType
+	// KindNotification is a NOTIFICATION-TYPE (SMIv2) or a TRAP-TYPE (SMIv1)
+	KindNotification
+)
+
+// Access values of OBJECT-TYPE objects
+const (
+	AccessNotAccessible       = "not-accessible"
+	AccessAccessibleForNotify = "accessible-for-notify"
+)
+
+// Module is a MIB module
+type Module struct {
+	Name string
+	// Imports maps imported symbols to the module they are imported from
+	Imports map[string]string
+	// Objects are the objects defined by the module, in definition order
+	Objects []*Object
+	// Types are the types (textual conventions and type assignments) defined by the module
+	Types map[string]*Syntax
+
+	objectsByName map[string]*Object
+}
+
+// Object is an object defined in a MIB module
+type Object struct {
+	Name   string
+	Module string
+	Kind   ObjectKind
+	// OID is the resolved OID in the numeric form (e.g. `1.3.6.1.2.1.2.2`), without leading dot
+	OID string
+
+	Syntax      *Syntax
+	Access      string
+	Status      string
+	Units       string
+	Description string
+	// Index are the INDEX objects of a table entry, and Augments the entry it augments
+	Index    []string
+	Augments string
+	// Objects are the OBJECTS (SMIv2) or VARIABLES (SMIv1) of a notification
+	Objects []string
+
+	oidValue []oidComponent
+	// SMIv1 traps are identified by an enterprise and a trap number instead of an OID value
+	trapEnterprise string
+	trapNumber     string
+}
+
+// Syntax is the SYNTAX of an object or of a type
+type Syntax struct {
+	// Type is the type name, e.g. `Counter32`, `OCTET STRING`, `DisplayString` or `SEQUENCE OF`
+	Type string
+	// Entry is the entry type name of a `SEQUENCE OF` syntax
+	Entry string
+	// NamedNumbers are the enumeration of an INTEGER or the bits of BITS
+	NamedNumbers []NamedNumber
+}
+
+// NamedNumber is a named value of an enumeration or bits
+type NamedNumber struct {
+	Name  string
+	Value int
+}
+
+type oidComponent struct {
+	name      string
+	number    uint64
+	hasNumber bool
+}
+
+// IsTable returns true if the object is a conceptual table
+func (o *Object) IsTable() bool {
+	return o.Kind == KindObjectType && o.Syntax != nil && o.Syntax.Type == "SEQUENCE OF"
+}
+
+// IsEntry returns true if the object is a conceptual row of a table
+func (o *Object) IsEntry() bool {
+	return o.Kind == KindObjectType && (len(o.Index) > 0 || o.Augments != "")
+}
+
+// IsReadable returns true if the object value can be read from an agent
+func (o *Object) IsReadable() bool {
+	return o.Kind == KindObjectType && o.Access != AccessNotAccessible && o.Access != AccessAccessibleForNotify
+}
+
+func newModule(name string) *Module {
+	return &Module{
+		Name:          name,
+		Imports:       make(map[string]string),
+		Types:         make(map[string]*Syntax),
+		objectsByName: make(map[string]*Object),
+	}
+}
+
+func (m *Module) addObject(object *Object) {
+	object.Module = m.Name
+	m.Objects = append(m.Objects, object)
+	m.objectsByName[object.Name] = object
+}
+
+// Object returns the object with the given name defined by the module, or nil
+func (m *Module) Object(name string) *Object {
+	return m.objectsByName[name]
+}
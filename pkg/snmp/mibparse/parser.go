@@ -0,0 +1,492 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package mibparse
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseModules parses all the modules defined in the content of a MIB file
+func parseModules(content string) ([]*Module, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	var modules []*Module
+	for p.peek().kind != tokenEOF {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, module)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no MIB module found")
+	}
+	return modules, nil
+}
+
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return token{kind: tokenEOF}
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) peekIs(text string) bool {
+	tok := p.peek()
+	return tok.kind != tokenEOF && tok.kind != tokenString && tok.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if tok := p.next(); tok.kind == tokenEOF || tok.kind == tokenString || tok.text != text {
+		return fmt.Errorf("expected %q, found %s", text, tok)
+	}
+	return nil
+}
+
+func (p *parser) expectKind(kind tokenKind, what string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, fmt.Errorf("expected %s, found %s", what, tok)
+	}
+	return tok, nil
+}
+
+// skipBalanced skips tokens until the closing delimiter matching the opening one at the current position
+func (p *parser) skipBalanced(opening, closing string) error {
+	depth := 0
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return fmt.Errorf("missing %q", closing)
+		case tok.kind != tokenPunctuation:
+		case tok.text == opening:
+			depth++
+		case tok.text == closing:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// parseModule parses `NAME DEFINITIONS ::= BEGIN ... END`
+func (p *parser) parseModule() (*Module, error) {
+	nameToken, err := p.expectKind(tokenIdentifier, "module name")
+	if err != nil {
+		return nil, err
+	}
+	module := newModule(nameToken.text)
+	if p.peekIs("{") {
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return nil, err
+		}
+	}
+	for _, keyword := range []string{"DEFINITIONS", "::=", "BEGIN"} {
+		if err := p.expect(keyword); err != nil {
+			return nil, fmt.Errorf("module %s: %w", module.Name, err)
+		}
+	}
+	for {
+		switch {
+		case p.peek().kind == tokenEOF:
+			return nil, fmt.Errorf("module %s: missing END", module.Name)
+		case p.peekIs("END"):
+			p.next()
+			return module, nil
+		case p.peekIs("IMPORTS"):
+			p.next()
+			if err := p.parseImports(module); err != nil {
+				return nil, fmt.Errorf("module %s: %w", module.Name, err)
+			}
+		case p.peekIs("EXPORTS"):
+			for tok := p.next(); tok.kind != tokenEOF && tok.text != ";"; tok = p.next() {
+			}
+		default:
+			if err := p.parseAssignment(module); err != nil {
+				return nil, fmt.Errorf("module %s: %w", module.Name, err)
+			}
+		}
+	}
+}
+
+// parseImports parses `a, b FROM MODULE-A c FROM MODULE-B ;`
+func (p *parser) parseImports(module *Module) error {
+	var symbols []string
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return fmt.Errorf("missing \";\" after IMPORTS")
+		case tok.text == ";":
+			return nil
+		case tok.text == ",":
+		case tok.text == "FROM":
+			from, err := p.expectKind(tokenIdentifier, "module name")
+			if err != nil {
+				return err
+			}
+			for _, symbol := range symbols {
+				module.Imports[symbol] = from.text
+			}
+			symbols = nil
+		case tok.kind == tokenIdentifier:
+			symbols = append(symbols, tok.text)
+		}
+	}
+}
+
+// parseAssignment parses a type assignment, a value assignment or a macro invocation (OBJECT-TYPE...)
+func (p *parser) parseAssignment(module *Module) error {
+	nameToken, err := p.expectKind(tokenIdentifier, "definition name")
+	if err != nil {
+		return err
+	}
+	name := nameToken.text
+
+	switch {
+	case p.peekIs("MACRO"):
+		// macro definitions (e.g. OBJECT-TYPE in SNMPv2-SMI) are built into the parser
+		for tok := p.next(); tok.text != "END"; tok = p.next() {
+			if tok.kind == tokenEOF {
+				return fmt.Errorf("macro %s: missing END", name)
+			}
+		}
+		return nil
+	case p.peekIs("::="):
+		p.next()
+		syntax, err := p.parseTypeAssignment()
+		if err != nil {
+			return fmt.Errorf("type %s: %w", name, err)
+		}
+		module.Types[name] = syntax
+		return nil
+	case p.peekIs("OBJECT") && p.peekAt(1).text == "IDENTIFIER":
+		p.next()
+		p.next()
+		if err := p.expect("::="); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		oidValue, err := p.parseOIDValue()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		module.addObject(&Object{Name: name, Kind: KindNode, oidValue: oidValue})
+		return nil
+	}
+
+	macroToken, err := p.expectKind(tokenIdentifier, "macro name")
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	object := &Object{Name: name}
+	switch macroToken.text {
+	case "OBJECT-TYPE":
+		object.Kind = KindObjectType
+	case "NOTIFICATION-TYPE", "TRAP-TYPE":
+		object.Kind = KindNotification
+	default:
+		object.Kind = KindNode
+	}
+	if err := p.parseClauses(object, false); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if macroToken.text == "TRAP-TYPE" {
+		numberToken, err := p.expectKind(tokenNumber, "trap number")
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		object.trapNumber = numberToken.text
+	} else if p.peekIs("{") {
+		if object.oidValue, err = p.parseOIDValue(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	} else {
+		// value assignment of another type, e.g. `x INTEGER ::= 1`
+		p.next()
+		return nil
+	}
+	module.addObject(object)
+	return nil
+}
+
+// parseTypeAssignment parses the right side of `Type ::= ...`
+func (p *parser) parseTypeAssignment() (*Syntax, error) {
+	if !p.peekIs("TEXTUAL-CONVENTION") {
+		return p.parseSyntax()
+	}
+	p.next()
+	object := &Object{}
+	if err := p.parseClauses(object, true); err != nil {
+		return nil, err
+	}
+	if object.Syntax == nil {
+		return nil, fmt.Errorf("missing SYNTAX")
+	}
+	return object.Syntax, nil
+}
+
+// parseClauses parses the clauses of a macro invocation until `::=`, or until SYNTAX for a
+// textual convention since SYNTAX is its last clause
+func (p *parser) parseClauses(object *Object, textualConvention bool) error {
+	for {
+		tok := p.next()
+		if tok.kind == tokenEOF {
+			return fmt.Errorf("missing \"::=\"")
+		}
+		if tok.kind == tokenString || tok.kind == tokenBinaryString {
+			continue
+		}
+		var err error
+		switch tok.text {
+		case "::=":
+			return nil
+		case "SYNTAX":
+			syntax, syntaxErr := p.parseSyntax()
+			if syntaxErr != nil {
+				return syntaxErr
+			}
+			// MODULE-COMPLIANCE and AGENT-CAPABILITIES refine the SYNTAX of other objects
+			if object.Syntax == nil {
+				object.Syntax = syntax
+			}
+			if textualConvention {
+				return nil
+			}
+		case "DESCRIPTION":
+			if object.Description == "" {
+				object.Description, err = p.parseString()
+			}
+		case "UNITS":
+			object.Units, err = p.parseString()
+		case "MAX-ACCESS", "ACCESS":
+			if object.Access == "" {
+				object.Access = p.next().text
+			}
+		case "STATUS":
+			if object.Status == "" {
+				object.Status = p.next().text
+			}
+		case "INDEX":
+			object.Index, err = p.parseNameList(true)
+		case "AUGMENTS":
+			var augments []string
+			if augments, err = p.parseNameList(false); err == nil && len(augments) > 0 {
+				object.Augments = augments[0]
+			}
+		case "OBJECTS", "VARIABLES":
+			if object.Objects == nil {
+				object.Objects, err = p.parseNameList(false)
+			}
+		case "ENTERPRISE":
+			if p.peekIs("{") {
+				// the enterprise of a trap is usually a name, but can also be an OID value
+				var oidValue []oidComponent
+				if oidValue, err = p.parseOIDValue(); err == nil && len(oidValue) > 0 {
+					object.trapEnterprise = oidValue[0].name
+				}
+			} else {
+				object.trapEnterprise = p.next().text
+			}
+		case "{":
+			p.pos--
+			err = p.skipBalanced("{", "}")
+		case "(":
+			p.pos--
+			err = p.skipBalanced("(", ")")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseString() (string, error) {
+	tok, err := p.expectKind(tokenString, "string")
+	return tok.text, err
+}
+
+// parseNameList parses `{ a, b }`, IMPLIED markers are dropped if allowed
+func (p *parser) parseNameList(allowImplied bool) ([]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, fmt.Errorf("missing \"}\"")
+		case tok.text == "}":
+			return names, nil
+		case tok.text == ",":
+		case tok.text == "IMPLIED" && allowImplied:
+		case tok.kind == tokenIdentifier:
+			names = append(names, tok.text)
+		default:
+			return nil, fmt.Errorf("unexpected %s in list", tok)
+		}
+	}
+}
+
+// parseSyntax parses a type, with its optional named numbers and constraints
+func (p *parser) parseSyntax() (*Syntax, error) {
+	if p.peekIs("[") {
+		// tagged types, e.g. `[APPLICATION 1] IMPLICIT INTEGER (0..4294967295)` in SNMPv2-SMI
+		if err := p.skipBalanced("[", "]"); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekIs("IMPLICIT") {
+		p.next()
+	}
+	typeToken, err := p.expectKind(tokenIdentifier, "type")
+	if err != nil {
+		return nil, err
+	}
+	syntax := &Syntax{Type: typeToken.text}
+	switch typeToken.text {
+	case "OCTET":
+		if err := p.expect("STRING"); err != nil {
+			return nil, err
+		}
+		syntax.Type = "OCTET STRING"
+	case "OBJECT":
+		if err := p.expect("IDENTIFIER"); err != nil {
+			return nil, err
+		}
+		syntax.Type = "OBJECT IDENTIFIER"
+	case "SEQUENCE", "CHOICE":
+		if p.peekIs("OF") {
+			p.next()
+			entryToken, err := p.expectKind(tokenIdentifier, "entry type")
+			if err != nil {
+				return nil, err
+			}
+			syntax.Type = "SEQUENCE OF"
+			syntax.Entry = entryToken.text
+			return syntax, nil
+		}
+		if err := p.skipBalanced("{", "}"); err != nil {
+			return nil, err
+		}
+		return syntax, nil
+	}
+	if p.peekIs("{") {
+		if syntax.NamedNumbers, err = p.parseNamedNumbers(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peekIs("(") {
+		if err := p.skipBalanced("(", ")"); err != nil {
+			return nil, err
+		}
+	}
+	return syntax, nil
+}
+
+// parseNamedNumbers parses `{ up(1), down(2) }`
+func (p *parser) parseNamedNumbers() ([]NamedNumber, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var namedNumbers []NamedNumber
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, fmt.Errorf("missing \"}\"")
+		case tok.text == "}":
+			return namedNumbers, nil
+		case tok.text == ",":
+		case tok.kind == tokenIdentifier:
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			numberToken, err := p.expectKind(tokenNumber, "number")
+			if err != nil {
+				return nil, err
+			}
+			value, err := strconv.Atoi(numberToken.text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s: %w", numberToken, err)
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			namedNumbers = append(namedNumbers, NamedNumber{Name: tok.text, Value: value})
+		default:
+			return nil, fmt.Errorf("unexpected %s in named numbers", tok)
+		}
+	}
+}
+
+// parseOIDValue parses `{ parent 1 }`, `{ iso org(3) dod(6) 1 }` or `{ 1 3 6 1 }`
+func (p *parser) parseOIDValue() ([]oidComponent, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var components []oidComponent
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, fmt.Errorf("missing \"}\"")
+		case tok.text == "}":
+			if len(components) == 0 {
+				return nil, fmt.Errorf("empty OID value")
+			}
+			return components, nil
+		case tok.kind == tokenNumber:
+			number, err := strconv.ParseUint(tok.text, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid OID component %s: %w", tok, err)
+			}
+			components = append(components, oidComponent{number: number, hasNumber: true})
+		case tok.kind == tokenIdentifier:
+			component := oidComponent{name: tok.text}
+			if p.peekIs("(") {
+				p.next()
+				numberToken, err := p.expectKind(tokenNumber, "number")
+				if err != nil {
+					return nil, err
+				}
+				if component.number, err = strconv.ParseUint(numberToken.text, 10, 64); err != nil {
+					return nil, fmt.Errorf("invalid OID component %s: %w", numberToken, err)
+				}
+				component.hasNumber = true
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+			}
+			components = append(components, component)
+		default:
+			return nil, fmt.Errorf("unexpected %s in OID value", tok)
+		}
+	}
+}
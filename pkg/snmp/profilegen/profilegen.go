@@ -0,0 +1,310 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package profilegen generates SNMP profiles and trap definitions from parsed MIB modules.
+package profilegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mibparse"
+)
+
+// Options selects what is generated
+type Options struct {
+	Name         string
+	SysObjectIDs []string
+	Extends      []string
+	// Modules are the modules to generate the profile from
+	Modules []string
+	// Objects are the objects to include: tables, entries, columns, scalars or nodes (for all objects under them).
+	// Names can be qualified with their module, e.g. `IF-MIB::ifTable`. All objects of Modules are included if empty.
+	Objects []string
+}
+
+// GenerateProfile builds a profile collecting the numeric objects selected in the options.
+// Table metrics are tagged by their INDEX, objects that cannot be collected are reported as warnings.
+func GenerateProfile(mibs *mibparse.MIBs, options Options) (*profiledefinition.ProfileDefinition, []string, error) {
+	objects, err := selectObjects(mibs, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile := profiledefinition.NewProfileDefinition()
+	profile.Name = options.Name
+	profile.SysObjectIDs = options.SysObjectIDs
+	profile.Extends = options.Extends
+
+	var warnings []string
+	// tableMetrics maps tables to the index of their metric in profile.Metrics
+	tableMetrics := make(map[*mibparse.Object]int)
+	for _, object := range objects {
+		if object.Kind != mibparse.KindObjectType || object.IsTable() || object.IsEntry() {
+			continue
+		}
+		metricType, ok := inferMetricType(mibs, object)
+		if !ok || !object.IsReadable() {
+			continue
+		}
+		symbol := profiledefinition.SymbolConfig{OID: object.OID, Name: object.Name}
+
+		table, entry := mibs.Table(object)
+		if table == nil {
+			profile.Metrics = append(profile.Metrics, profiledefinition.MetricsConfig{
+				MIB:        object.Module,
+				Symbol:     profiledefinition.SymbolConfig{OID: object.OID + ".0", Name: object.Name},
+				MetricType: metricType,
+			})
+			continue
+		}
+		if isIndexColumn(mibs, entry, object) {
+			continue
+		}
+		metricIndex, ok := tableMetrics[table]
+		if !ok {
+			metricTags, tagWarnings := buildIndexTags(mibs, entry)
+			warnings = append(warnings, tagWarnings...)
+			profile.Metrics = append(profile.Metrics, profiledefinition.MetricsConfig{
+				MIB:        table.Module,
+				Table:      profiledefinition.SymbolConfig{OID: table.OID, Name: table.Name},
+				MetricTags: metricTags,
+			})
+			metricIndex = len(profile.Metrics) - 1
+			tableMetrics[table] = metricIndex
+		}
+		symbol.MetricType = metricType
+		profile.Metrics[metricIndex].Symbols = append(profile.Metrics[metricIndex].Symbols, symbol)
+	}
+	for i := range profile.Metrics {
+		hoistMetricType(&profile.Metrics[i])
+	}
+
+	if len(profile.Metrics) == 0 {
+		return nil, warnings, fmt.Errorf("no numeric object to collect found")
+	}
+	return profile, warnings, nil
+}
+
+// GenerateTrapsDB builds trap definitions, in the format of the traps OID resolver, for the
+// notifications defined by the given modules and the variables they reference
+func GenerateTrapsDB(mibs *mibparse.MIBs, modules []string) oidresolver.TrapDBFileContent {
+	content := oidresolver.TrapDBFileContent{
+		Traps:     make(oidresolver.TrapSpec),
+		Variables: make(oidresolver.VariableSpec),
+	}
+	for _, moduleName := range modules {
+		module := mibs.Module(moduleName)
+		if module == nil {
+			continue
+		}
+		for _, notification := range module.Objects {
+			if notification.Kind != mibparse.KindNotification || notification.OID == "" {
+				continue
+			}
+			content.Traps[notification.OID] = oidresolver.TrapMetadata{
+				Name:        notification.Name,
+				MIBName:     module.Name,
+				Description: normalizeDescription(notification.Description),
+			}
+			for _, name := range notification.Objects {
+				variable := mibs.Object(module.Name, name)
+				if variable == nil || variable.OID == "" {
+					continue
+				}
+				content.Variables[variable.OID] = buildVariableMetadata(mibs, variable)
+			}
+		}
+	}
+	return content
+}
+
+func buildVariableMetadata(mibs *mibparse.MIBs, variable *mibparse.Object) oidresolver.VariableMetadata {
+	metadata := oidresolver.VariableMetadata{
+		Name:        variable.Name,
+		Description: normalizeDescription(variable.Description),
+	}
+	baseType, namedNumbers := mibs.BaseType(variable)
+	if len(namedNumbers) == 0 {
+		return metadata
+	}
+	values := make(map[int]string, len(namedNumbers))
+	for _, namedNumber := range namedNumbers {
+		values[namedNumber.Value] = namedNumber.Name
+	}
+	if baseType == mibparse.TypeBits {
+		metadata.Bits = values
+	} else {
+		metadata.Enumeration = values
+	}
+	return metadata
+}
+
+// selectObjects returns the object types selected in the options, in selection order and without duplicates
+func selectObjects(mibs *mibparse.MIBs, options Options) ([]*mibparse.Object, error) {
+	var roots []*mibparse.Object
+	if len(options.Objects) == 0 {
+		for _, moduleName := range options.Modules {
+			module := mibs.Module(moduleName)
+			if module == nil {
+				return nil, fmt.Errorf("unknown module %s", moduleName)
+			}
+			for _, object := range module.Objects {
+				if object.Kind == mibparse.KindObjectType {
+					roots = append(roots, object)
+				}
+			}
+		}
+	}
+	for _, name := range options.Objects {
+		var object *mibparse.Object
+		for _, moduleName := range options.Modules {
+			if object = mibs.Object(moduleName, name); object != nil {
+				break
+			}
+		}
+		if object == nil {
+			object = mibs.Object("", name)
+		}
+		if object == nil {
+			return nil, fmt.Errorf("unknown object %s", name)
+		}
+		if object.OID == "" {
+			return nil, fmt.Errorf("the OID of object %s could not be resolved", name)
+		}
+		roots = append(roots, object)
+	}
+
+	seen := make(map[*mibparse.Object]bool)
+	var objects []*mibparse.Object
+	for _, root := range roots {
+		if root.OID == "" {
+			continue
+		}
+		for _, object := range mibs.Descendants(root) {
+			if !seen[object] && object.Kind == mibparse.KindObjectType {
+				seen[object] = true
+				objects = append(objects, object)
+			}
+		}
+	}
+	return objects, nil
+}
+
+// inferMetricType returns the metric type of an object from its SYNTAX, false if it isn't numeric
+func inferMetricType(mibs *mibparse.MIBs, object *mibparse.Object) (profiledefinition.ProfileMetricType, bool) {
+	baseType, _ := mibs.BaseType(object)
+	switch baseType {
+	case mibparse.TypeCounter, mibparse.TypeCounter32, mibparse.TypeCounter64:
+		return profiledefinition.ProfileMetricTypeMonotonicCount, true
+	case mibparse.TypeGauge, mibparse.TypeGauge32, mibparse.TypeUnsigned32, mibparse.TypeInteger, mibparse.TypeInteger32, mibparse.TypeTimeTicks:
+		return profiledefinition.ProfileMetricTypeGauge, true
+	}
+	return "", false
+}
+
+func isIndexColumn(mibs *mibparse.MIBs, entry *mibparse.Object, column *mibparse.Object) bool {
+	for _, name := range mibs.EntryIndex(entry) {
+		if mibs.Object(entry.Module, name) == column {
+			return true
+		}
+	}
+	return false
+}
+
+// buildIndexTags builds a tag per INDEX object of a table entry. Integer indexes are read from
+// the row index, as long as they are preceded by integer indexes only since other types are encoded
+// with a variable number of sub-identifiers. Other indexes are read from their column when it is readable.
+func buildIndexTags(mibs *mibparse.MIBs, entry *mibparse.Object) (profiledefinition.MetricTagConfigList, []string) {
+	var tags profiledefinition.MetricTagConfigList
+	var warnings []string
+	fixedPosition := true
+	for position, name := range mibs.EntryIndex(entry) {
+		index := mibs.Object(entry.Module, name)
+		if index == nil || index.OID == "" {
+			warnings = append(warnings, fmt.Sprintf("%s: unknown index %s", entry.Name, name))
+			fixedPosition = false
+			continue
+		}
+		tag := profiledefinition.MetricTagConfig{Tag: toTagName(index.Name)}
+		baseType, namedNumbers := mibs.BaseType(index)
+		isInteger := isIntegerType(baseType)
+		switch {
+		case isInteger && fixedPosition:
+			tag.Index = uint(position + 1)
+			if len(namedNumbers) > 0 {
+				tag.Mapping = make(profiledefinition.ListMap[string], len(namedNumbers))
+				for _, namedNumber := range namedNumbers {
+					tag.Mapping[strconv.Itoa(namedNumber.Value)] = namedNumber.Name
+				}
+			}
+		case index.IsReadable():
+			tag.Symbol = profiledefinition.SymbolConfigCompat{OID: index.OID, Name: index.Name}
+		default:
+			warnings = append(warnings, fmt.Sprintf("%s: index %s is not readable and can't be decoded from the row index, no tag generated", entry.Name, index.Name))
+		}
+		if !isInteger {
+			fixedPosition = false
+		}
+		if tag.Index != 0 || tag.Symbol.OID != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, warnings
+}
+
+func isIntegerType(baseType string) bool {
+	switch baseType {
+	case mibparse.TypeInteger, mibparse.TypeInteger32, mibparse.TypeUnsigned32, mibparse.TypeGauge, mibparse.TypeGauge32, mibparse.TypeTimeTicks:
+		return true
+	}
+	return false
+}
+
+// hoistMetricType moves the metric type of table symbols to the metric when they all share the same one
+func hoistMetricType(metric *profiledefinition.MetricsConfig) {
+	if len(metric.Symbols) == 0 {
+		return
+	}
+	metricType := metric.Symbols[0].MetricType
+	for _, symbol := range metric.Symbols {
+		if symbol.MetricType != metricType {
+			return
+		}
+	}
+	metric.MetricType = metricType
+	for i := range metric.Symbols {
+		metric.Symbols[i].MetricType = ""
+	}
+}
+
+// toTagName converts an object name to a tag name, e.g. `ifIndex` to `if_index`
+func toTagName(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// split before an uppercase letter that starts a word, e.g. `ifIndex` or `HTTPServer`
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		if r == '-' {
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func normalizeDescription(description string) string {
+	return strings.Join(strings.Fields(description), " ")
+}
@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package profilegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/snmp/mibparse"
+)
+
+func loadTestMIBs(t *testing.T) *mibparse.MIBs {
+	mibs := mibparse.NewMIBs()
+	_, err := mibs.LoadFile("../mibparse/testdata/DATADOG-TEST-MIB.my")
+	require.NoError(t, err)
+	_, err = mibs.LoadFile("../mibparse/testdata/DATADOG-TEST-V1-MIB.my")
+	require.NoError(t, err)
+	require.Empty(t, mibs.Resolve())
+	return mibs
+}
+
+func TestGenerateProfile_AllObjects(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	profile, warnings, err := GenerateProfile(mibs, Options{
+		Name:         "datadog-test",
+		SysObjectIDs: []string{"1.3.6.1.4.1.99999.*"},
+		Extends:      []string{"_base.yaml"},
+		Modules:      []string{"DATADOG-TEST-MIB"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"testUserEntry: index testUserName is not readable and can't be decoded from the row index, no tag generated"}, warnings)
+
+	expected := profiledefinition.NewProfileDefinition()
+	expected.Name = "datadog-test"
+	expected.SysObjectIDs = []string{"1.3.6.1.4.1.99999.*"}
+	expected.Extends = []string{"_base.yaml"}
+	expected.Metrics = []profiledefinition.MetricsConfig{
+		{
+			MIB:        "DATADOG-TEST-MIB",
+			Symbol:     profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99999.1.1.0", Name: "testUptime"},
+			MetricType: profiledefinition.ProfileMetricTypeGauge,
+		},
+		{
+			MIB:        "DATADOG-TEST-MIB",
+			Symbol:     profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99999.1.2.0", Name: "testTotalRequests"},
+			MetricType: profiledefinition.ProfileMetricTypeMonotonicCount,
+		},
+		{
+			MIB:   "DATADOG-TEST-MIB",
+			Table: profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99999.1.10", Name: "testPortTable"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.3.6.1.4.1.99999.1.10.1.3", Name: "testPortStatus", MetricType: profiledefinition.ProfileMetricTypeGauge},
+				{OID: "1.3.6.1.4.1.99999.1.10.1.5", Name: "testPortInOctets", MetricType: profiledefinition.ProfileMetricTypeMonotonicCount},
+				{OID: "1.3.6.1.4.1.99999.1.10.1.6", Name: "testPortQueueDepth", MetricType: profiledefinition.ProfileMetricTypeGauge},
+				{OID: "1.3.6.1.4.1.99999.1.10.1.7", Name: "testPortEnabled", MetricType: profiledefinition.ProfileMetricTypeGauge},
+			},
+			MetricTags: profiledefinition.MetricTagConfigList{
+				{Tag: "test_port_index", Index: 1},
+			},
+		},
+		{
+			MIB:   "DATADOG-TEST-MIB",
+			Table: profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99999.1.11", Name: "testPortXTable"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.3.6.1.4.1.99999.1.11.1.1", Name: "testPortOutOctets"},
+			},
+			MetricTags: profiledefinition.MetricTagConfigList{
+				{Tag: "test_port_index", Index: 1},
+			},
+			MetricType: profiledefinition.ProfileMetricTypeMonotonicCount,
+		},
+		{
+			MIB:   "DATADOG-TEST-MIB",
+			Table: profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99999.1.12", Name: "testUserTable"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.3.6.1.4.1.99999.1.12.1.2", Name: "testUserSessions"},
+			},
+			MetricType: profiledefinition.ProfileMetricTypeGauge,
+		},
+	}
+	assert.Equal(t, expected, profile)
+}
+
+func TestGenerateProfile_SelectedObjects(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	profile, warnings, err := GenerateProfile(mibs, Options{
+		Name:    "datadog-test",
+		Modules: []string{"DATADOG-TEST-MIB"},
+		Objects: []string{"testPortInOctets", "testPortQueueDepth", "DATADOG-TEST-V1-MIB::testV1Objects"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	expected := profiledefinition.NewProfileDefinition()
+	expected.Name = "datadog-test"
+	expected.Metrics = []profiledefinition.MetricsConfig{
+		{
+			MIB:   "DATADOG-TEST-MIB",
+			Table: profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99999.1.10", Name: "testPortTable"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.3.6.1.4.1.99999.1.10.1.5", Name: "testPortInOctets", MetricType: profiledefinition.ProfileMetricTypeMonotonicCount},
+				{OID: "1.3.6.1.4.1.99999.1.10.1.6", Name: "testPortQueueDepth", MetricType: profiledefinition.ProfileMetricTypeGauge},
+			},
+			MetricTags: profiledefinition.MetricTagConfigList{
+				{Tag: "test_port_index", Index: 1},
+			},
+		},
+		{
+			MIB:        "DATADOG-TEST-V1-MIB",
+			Symbol:     profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99998.1.1.0", Name: "testV1Errors"},
+			MetricType: profiledefinition.ProfileMetricTypeMonotonicCount,
+		},
+		{
+			MIB:        "DATADOG-TEST-V1-MIB",
+			Symbol:     profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99998.1.2.0", Name: "testV1Load"},
+			MetricType: profiledefinition.ProfileMetricTypeGauge,
+		},
+		{
+			MIB:        "DATADOG-TEST-V1-MIB",
+			Symbol:     profiledefinition.SymbolConfig{OID: "1.3.6.1.4.1.99998.1.3.0", Name: "testV1State"},
+			MetricType: profiledefinition.ProfileMetricTypeGauge,
+		},
+	}
+	assert.Equal(t, expected, profile)
+}
+
+func TestGenerateProfile_IndexTags(t *testing.T) {
+	mibs := mibparse.NewMIBs()
+	_, err := mibs.Load(`
+TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS OBJECT-TYPE, Integer32, Gauge32, enterprises FROM SNMPv2-SMI
+        DisplayString FROM SNMPv2-TC;
+
+testQueueTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF TestQueueEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "Queues."
+    ::= { enterprises 99997 1 }
+
+testQueueEntry OBJECT-TYPE
+    SYNTAX TestQueueEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A queue."
+    INDEX { testQueueType, testQueueName, testQueueIndex }
+    ::= { testQueueTable 1 }
+
+testQueueType OBJECT-TYPE
+    SYNTAX INTEGER { unicast(1), multicast(2) }
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "Queue type."
+    ::= { testQueueEntry 1 }
+
+testQueueName OBJECT-TYPE
+    SYNTAX DisplayString
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Queue name."
+    ::= { testQueueEntry 2 }
+
+testQueueIndex OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Queue index."
+    ::= { testQueueEntry 3 }
+
+testQueueDepth OBJECT-TYPE
+    SYNTAX Gauge32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Queue depth."
+    ::= { testQueueEntry 4 }
+END
+`)
+	require.NoError(t, err)
+	require.Empty(t, mibs.Resolve())
+
+	profile, warnings, err := GenerateProfile(mibs, Options{Name: "test", Modules: []string{"TEST-MIB"}})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	require.Len(t, profile.Metrics, 1)
+	assert.Equal(t, profiledefinition.MetricTagConfigList{
+		{Tag: "test_queue_type", Index: 1, Mapping: profiledefinition.ListMap[string]{"1": "unicast", "2": "multicast"}},
+		{Tag: "test_queue_name", Symbol: profiledefinition.SymbolConfigCompat{OID: "1.3.6.1.4.1.99997.1.1.2", Name: "testQueueName"}},
+		// the name before it has a variable length, the index is read from its column
+		{Tag: "test_queue_index", Symbol: profiledefinition.SymbolConfigCompat{OID: "1.3.6.1.4.1.99997.1.1.3", Name: "testQueueIndex"}},
+	}, profile.Metrics[0].MetricTags)
+	assert.Equal(t, []profiledefinition.SymbolConfig{{OID: "1.3.6.1.4.1.99997.1.1.4", Name: "testQueueDepth"}}, profile.Metrics[0].Symbols)
+}
+
+func TestGenerateProfile_Errors(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	_, _, err := GenerateProfile(mibs, Options{Modules: []string{"UNKNOWN-MIB"}})
+	assert.EqualError(t, err, "unknown module UNKNOWN-MIB")
+
+	_, _, err = GenerateProfile(mibs, Options{Modules: []string{"DATADOG-TEST-MIB"}, Objects: []string{"unknownObject"}})
+	assert.EqualError(t, err, "unknown object unknownObject")
+
+	_, _, err = GenerateProfile(mibs, Options{Modules: []string{"DATADOG-TEST-MIB"}, Objects: []string{"testVersion"}})
+	assert.EqualError(t, err, "no numeric object to collect found")
+}
+
+func TestGenerateTrapsDB(t *testing.T) {
+	mibs := loadTestMIBs(t)
+
+	content := GenerateTrapsDB(mibs, []string{"DATADOG-TEST-MIB", "DATADOG-TEST-V1-MIB"})
+	assert.Equal(t, oidresolver.TrapDBFileContent{
+		Traps: oidresolver.TrapSpec{
+			"1.3.6.1.4.1.99999.0.1": {Name: "testPortDown", MIBName: "DATADOG-TEST-MIB", Description: "A port went down."},
+			"1.3.6.1.4.1.99998.0.2": {Name: "testV1StateChange", MIBName: "DATADOG-TEST-V1-MIB", Description: "The state changed."},
+		},
+		Variables: oidresolver.VariableSpec{
+			"1.3.6.1.4.1.99999.1.10.1.2": {Name: "testPortName", Description: "Port name."},
+			"1.3.6.1.4.1.99999.1.10.1.3": {Name: "testPortStatus", Description: "Port status.", Enumeration: map[int]string{1: "ok", 2: "failed", -1: "unknown"}},
+			"1.3.6.1.4.1.99999.1.10.1.4": {Name: "testPortFlags", Description: "Port flags.", Bits: map[int]string{0: "active", 1: "shutdown"}},
+			"1.3.6.1.4.1.99998.1.3":      {Name: "testV1State", Description: "State.", Enumeration: map[int]string{1: "running", 2: "stopped"}},
+		},
+	}, content)
+}
+
+func TestToTagName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"ifIndex":         "if_index",
+		"cpmCPUTotal5min": "cpm_cpu_total5min",
+		"HTTPServerPort":  "http_server_port",
+		"ent-index":       "ent_index",
+		"index":           "index",
+	} {
+		assert.Equal(t, expected, toTagName(name), name)
+	}
+}
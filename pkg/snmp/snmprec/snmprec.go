@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package snmprec reads and writes SNMP data in the snmprec format used by snmpsim:
+// one `OID|TAG|VALUE` line per OID, where TAG is the ASN.1 BER type of the value,
+// suffixed with `x` when the value is hex encoded.
+package snmprec
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+)
+
+// hexSuffix marks hex encoded values
+const hexSuffix = "x"
+
+// Writer writes PDUs as snmprec lines
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer writing to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes a PDU as a snmprec line
+func (w *Writer) Write(pdu gosnmp.SnmpPDU) error {
+	line, err := FormatPDU(pdu)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w.w, line+"\n")
+	return err
+}
+
+// FormatPDU returns the snmprec line of a PDU, without line terminator
+func FormatPDU(pdu gosnmp.SnmpPDU) (string, error) {
+	oid := strings.TrimLeft(pdu.Name, ".")
+	tag := strconv.Itoa(int(pdu.Type))
+	var value string
+	switch pdu.Type {
+	case gosnmp.Integer:
+		value = gosnmp.ToBigInt(pdu.Value).String()
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64:
+		value = gosnmp.ToBigInt(pdu.Value).String()
+	case gosnmp.OctetString, gosnmp.Opaque:
+		bytesValue, ok := pdu.Value.([]byte)
+		if !ok {
+			return "", fmt.Errorf("oid %s: %s should be []byte type but got type `%T`", oid, pdu.Type, pdu.Value)
+		}
+		if pdu.Type == gosnmp.OctetString && isPrintable(bytesValue) {
+			value = string(bytesValue)
+		} else {
+			tag += hexSuffix
+			value = hex.EncodeToString(bytesValue)
+		}
+	case gosnmp.ObjectIdentifier, gosnmp.IPAddress:
+		stringValue, ok := pdu.Value.(string)
+		if !ok {
+			return "", fmt.Errorf("oid %s: %s should be string type but got type `%T`", oid, pdu.Type, pdu.Value)
+		}
+		value = strings.TrimLeft(stringValue, ".")
+	case gosnmp.Null:
+	default:
+		return "", fmt.Errorf("oid %s: unsupported type: %s", oid, pdu.Type)
+	}
+	return oid + "|" + tag + "|" + value, nil
+}
+
+// isPrintable returns true if a string can be written as is on a single line
+func isPrintable(value []byte) bool {
+	for _, b := range value {
+		if b < 32 || b > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadFile reads the PDUs of a snmprec file, see Read
+func ReadFile(path string) ([]gosnmp.SnmpPDU, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pdus, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	return pdus, nil
+}
+
+// Read reads snmprec lines and returns their PDUs sorted by OID, in file order for duplicate OIDs. Lines using snmpsim
+// variation modules (e.g. `2:delay`), other encodings than hex, types unsupported by SNMP
+// agents or values invalid for their type are skipped, like snmpsim does when serving them.
+// Comments and empty lines are ignored.
+func Read(r io.Reader) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	var oids [][]int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("line %d: expected OID|TAG|VALUE, got %q", lineNumber, line)
+		}
+		oid, err := gosnmplib.OIDToInts(strings.TrimLeft(parts[0], "."))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		pdu, ok := parseRecord(parts[0], parts[1], parts[2])
+		if !ok {
+			continue
+		}
+		pdus = append(pdus, pdu)
+		oids = append(oids, oid)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Stable(byOID{pdus: pdus, oids: oids})
+	return pdus, nil
+}
+
+// parseRecord returns the PDU of a snmprec record, or false if it is not supported or invalid
+func parseRecord(oid string, tag string, value string) (gosnmp.SnmpPDU, bool) {
+	if strings.Contains(tag, ":") {
+		return gosnmp.SnmpPDU{}, false
+	}
+	isHex := strings.HasSuffix(tag, hexSuffix)
+	tagNumber, err := strconv.Atoi(strings.TrimSuffix(tag, hexSuffix))
+	if err != nil {
+		return gosnmp.SnmpPDU{}, false
+	}
+	var rawValue []byte
+	if isHex {
+		if rawValue, err = hex.DecodeString(value); err != nil {
+			return gosnmp.SnmpPDU{}, false
+		}
+		value = string(rawValue)
+	}
+
+	// names and OID values have a leading dot, like the PDUs returned by gosnmp
+	pdu := gosnmp.SnmpPDU{Name: "." + strings.TrimLeft(oid, "."), Type: gosnmp.Asn1BER(tagNumber)}
+	switch pdu.Type {
+	case gosnmp.Integer:
+		var intValue int64
+		intValue, err = strconv.ParseInt(value, 10, 32)
+		pdu.Value = int(intValue)
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks:
+		var uintValue uint64
+		uintValue, err = strconv.ParseUint(value, 10, 32)
+		pdu.Value = uint32(uintValue)
+	case gosnmp.Counter64:
+		pdu.Value, err = strconv.ParseUint(value, 10, 64)
+	case gosnmp.OctetString, gosnmp.Opaque:
+		pdu.Value = []byte(value)
+	case gosnmp.ObjectIdentifier:
+		_, err = gosnmplib.OIDToInts(strings.TrimLeft(value, "."))
+		pdu.Value = "." + strings.TrimLeft(value, ".")
+	case gosnmp.IPAddress:
+		if isHex && len(rawValue) == net.IPv4len {
+			value = net.IP(rawValue).String()
+		}
+		if ip := net.ParseIP(value); ip == nil || ip.To4() == nil {
+			return gosnmp.SnmpPDU{}, false
+		}
+		pdu.Value = value
+	case gosnmp.Null:
+	default:
+		return gosnmp.SnmpPDU{}, false
+	}
+	return pdu, err == nil
+}
+
+// byOID sorts PDUs by OID
+type byOID struct {
+	pdus []gosnmp.SnmpPDU
+	oids [][]int
+}
+
+func (b byOID) Len() int {
+	return len(b.pdus)
+}
+
+func (b byOID) Less(i, j int) bool {
+	return gosnmplib.CmpOIDs(b.oids[i], b.oids[j]).IsBefore()
+}
+
+func (b byOID) Swap(i, j int) {
+	b.pdus[i], b.pdus[j] = b.pdus[j], b.pdus[i]
+	b.oids[i], b.oids[j] = b.oids[j], b.oids[i]
+}
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmprec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPDU(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		pdu      gosnmp.SnmpPDU
+		expected string
+	}{
+		{"integer", gosnmp.SnmpPDU{Name: ".1.2.1", Type: gosnmp.Integer, Value: -3}, "1.2.1|2|-3"},
+		{"counter32", gosnmp.SnmpPDU{Name: ".1.2.2", Type: gosnmp.Counter32, Value: uint(42)}, "1.2.2|65|42"},
+		{"counter64", gosnmp.SnmpPDU{Name: ".1.2.3", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)}, "1.2.3|70|18446744073709551615"},
+		{"timeticks", gosnmp.SnmpPDU{Name: ".1.2.4", Type: gosnmp.TimeTicks, Value: uint32(1234)}, "1.2.4|67|1234"},
+		{"printable string", gosnmp.SnmpPDU{Name: ".1.2.5", Type: gosnmp.OctetString, Value: []byte("eth0 | up")}, "1.2.5|4|eth0 | up"},
+		{"binary string", gosnmp.SnmpPDU{Name: ".1.2.6", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1c, 0x73, 0xff}}, "1.2.6|4x|001c73ff"},
+		{"multiline string", gosnmp.SnmpPDU{Name: ".1.2.7", Type: gosnmp.OctetString, Value: []byte("a\nb")}, "1.2.7|4x|610a62"},
+		{"oid", gosnmp.SnmpPDU{Name: ".1.2.8", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9"}, "1.2.8|6|1.3.6.1.4.1.9"},
+		{"ip address", gosnmp.SnmpPDU{Name: ".1.2.9", Type: gosnmp.IPAddress, Value: "10.0.0.1"}, "1.2.9|64|10.0.0.1"},
+		{"null", gosnmp.SnmpPDU{Name: ".1.2.10", Type: gosnmp.Null}, "1.2.10|5|"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			line, err := FormatPDU(tc.pdu)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, line)
+		})
+	}
+}
+
+func TestFormatPDUErrors(t *testing.T) {
+	_, err := FormatPDU(gosnmp.SnmpPDU{Name: ".1.2", Type: gosnmp.OctetString, Value: 3})
+	assert.ErrorContains(t, err, "should be []byte type")
+
+	_, err = FormatPDU(gosnmp.SnmpPDU{Name: ".1.2", Type: gosnmp.NoSuchObject})
+	assert.ErrorContains(t, err, "unsupported type")
+}
+
+func TestRead(t *testing.T) {
+	content := `# comment
+1.3.6.1.2.1.1.5.0|4|router
+1.3.6.1.2.1.1.1.0|4x|4c696e75780a
+.1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.8072
+1.3.6.1.2.1.1.3.0|67|1234
+
+1.3.6.1.2.1.2.2.1.10.2|65|20
+1.3.6.1.2.1.2.2.1.10.10|65|100
+1.3.6.1.2.1.31.1.1.1.6.1|70|12345678901234
+1.3.6.1.2.1.4.20.1.1.10.0.0.1|64x|0a000001
+1.3.6.1.2.1.4.20.1.2.10.0.0.1|2|
+1.3.6.1.2.1.4.20.1.3.10.0.0.1|64|167772161
+1.3.6.1.4.1.1.1|2:numeric|rate=10
+1.3.6.1.4.1.1.2|4e|unknown encoding
+1.3.6.1.4.1.1.3|63|opaque float
+`
+	pdus, err := Read(strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Linux\n")},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1234)},
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("router")},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint32(20)},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.10", Type: gosnmp.Counter32, Value: uint32(100)},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(12345678901234)},
+	}, pdus)
+}
+
+func TestReadErrors(t *testing.T) {
+	_, err := Read(strings.NewReader("1.3.6.1.2.1.1.5.0|4\n"))
+	assert.ErrorContains(t, err, "line 1: expected OID|TAG|VALUE")
+
+	_, err = Read(strings.NewReader("1.3.6.1.2.1.1.5.0|4|router\n1.3.6.a|4|router\n"))
+	assert.ErrorContains(t, err, "line 2:")
+}
+
+func TestWriteRead(t *testing.T) {
+	pdus := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Linux 5.10 | x86_64")},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.3.2.10"},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte{0xde, 0xad, 0xbe, 0xef}},
+		{Name: ".1.3.6.1.2.1.2.2.1.8.1", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint32(4294967295)},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+	}
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	for _, pdu := range pdus {
+		require.NoError(t, writer.Write(pdu))
+	}
+
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, pdus, read)
+}
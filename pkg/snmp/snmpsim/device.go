@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmpsim
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmprec"
+)
+
+// Device holds the OIDs served for a simulated device
+type Device struct {
+	pdus []gosnmp.SnmpPDU
+	oids [][]int
+}
+
+// NewDevice returns a device serving the given PDUs. When an OID is given several times, its first PDU is served.
+func NewDevice(pdus []gosnmp.SnmpPDU) (*Device, error) {
+	device := &Device{
+		pdus: make([]gosnmp.SnmpPDU, len(pdus)),
+		oids: make([][]int, len(pdus)),
+	}
+	copy(device.pdus, pdus)
+	for i, pdu := range device.pdus {
+		oid, err := gosnmplib.OIDToInts(strings.TrimLeft(pdu.Name, "."))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %s: %w", pdu.Name, err)
+		}
+		device.oids[i] = oid
+	}
+	sort.Stable((*byOID)(device))
+
+	// keep the first record of duplicate OIDs
+	n := 0
+	for i := range device.oids {
+		if n > 0 && gosnmplib.CmpOIDs(device.oids[n-1], device.oids[i]) == gosnmplib.EQUAL {
+			continue
+		}
+		device.pdus[n], device.oids[n] = device.pdus[i], device.oids[i]
+		n++
+	}
+	device.pdus, device.oids = device.pdus[:n], device.oids[:n]
+	return device, nil
+}
+
+// LoadDevice returns a device serving the OIDs of a snmprec file
+func LoadDevice(path string) (*Device, error) {
+	pdus, err := snmprec.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewDevice(pdus)
+}
+
+// byOID sorts the OIDs of a device
+type byOID Device
+
+func (b *byOID) Len() int {
+	return len(b.pdus)
+}
+
+func (b *byOID) Less(i, j int) bool {
+	return gosnmplib.CmpOIDs(b.oids[i], b.oids[j]).IsBefore()
+}
+
+func (b *byOID) Swap(i, j int) {
+	b.pdus[i], b.pdus[j] = b.pdus[j], b.pdus[i]
+	b.oids[i], b.oids[j] = b.oids[j], b.oids[i]
+}
+
+// Get returns the PDU of an OID, or false if the device doesn't have it
+func (d *Device) Get(oid []int) (gosnmp.SnmpPDU, bool) {
+	i := d.search(oid)
+	if i < len(d.oids) && gosnmplib.CmpOIDs(d.oids[i], oid) == gosnmplib.EQUAL {
+		return d.pdus[i], true
+	}
+	return gosnmp.SnmpPDU{}, false
+}
+
+// GetNext returns the PDU of the first OID after the given one, or false at the end of the MIB view
+func (d *Device) GetNext(oid []int) (gosnmp.SnmpPDU, bool) {
+	i := d.search(oid)
+	if i < len(d.oids) && gosnmplib.CmpOIDs(d.oids[i], oid) == gosnmplib.EQUAL {
+		i++
+	}
+	if i < len(d.oids) {
+		return d.pdus[i], true
+	}
+	return gosnmp.SnmpPDU{}, false
+}
+
+// search returns the index of the first OID that is not before the given one
+func (d *Device) search(oid []int) int {
+	return sort.Search(len(d.oids), func(i int) bool {
+		return !gosnmplib.CmpOIDs(d.oids[i], oid).IsBefore()
+	})
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmpsim
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevice(t *testing.T) {
+	device, err := NewDevice([]gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("first")},
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("descr")},
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("duplicate")},
+	})
+	require.NoError(t, err)
+
+	pdu, ok := device.Get([]int{1, 3, 6, 1, 2, 1, 1, 5, 0})
+	assert.True(t, ok)
+	assert.Equal(t, []byte("first"), pdu.Value)
+	_, ok = device.Get([]int{1, 3, 6, 1, 2, 1, 1, 5})
+	assert.False(t, ok)
+
+	pdu, ok = device.GetNext(nil)
+	assert.True(t, ok)
+	assert.Equal(t, ".1.3.6.1.2.1.1.1.0", pdu.Name)
+	pdu, ok = device.GetNext([]int{1, 3, 6, 1, 2, 1, 1, 1, 0})
+	assert.True(t, ok)
+	assert.Equal(t, ".1.3.6.1.2.1.1.5.0", pdu.Name)
+	pdu, ok = device.GetNext([]int{1, 3, 6, 1, 2, 1, 1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, ".1.3.6.1.2.1.1.5.0", pdu.Name)
+	_, ok = device.GetNext([]int{1, 3, 6, 1, 2, 1, 1, 5, 0})
+	assert.False(t, ok)
+
+	_, err = NewDevice([]gosnmp.SnmpPDU{{Name: ".1.3.a"}})
+	assert.ErrorContains(t, err, "invalid OID")
+}
@@ -0,0 +1,387 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package snmpsim implements a lightweight SNMP agent serving devices recorded in snmprec files,
+// to test profiles and SNMP integrations without real devices.
+package snmpsim
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// usmStatsUnknownEngineIDs is the counter reported to SNMPv3 engine discovery requests
+	usmStatsUnknownEngineIDs = ".1.3.6.1.6.3.15.1.1.4.0"
+	// maxBulkVariables bounds the work done for a GetBulk request, the response is then truncated to maxMessageSize
+	maxBulkVariables = 1000
+	// maxMessageSize is the largest payload of a UDP datagram, responses are truncated or replaced by a tooBig error
+	// so that they fit
+	maxMessageSize = 65507
+	// engineIDPrefix is the prefix of generated engine IDs: enterprise 0 and the octets format
+	engineIDPrefix = "\x80\x00\x00\x00\x05"
+)
+
+// User is the SNMPv3 user accepted by a simulator
+type User struct {
+	Name         string
+	AuthProtocol gosnmp.SnmpV3AuthProtocol
+	AuthKey      string
+	PrivProtocol gosnmp.SnmpV3PrivProtocol
+	PrivKey      string
+}
+
+// Config configures a simulator
+type Config struct {
+	// Devices maps the community (SNMP v1/v2c) or the context name (SNMPv3) of requests to the device
+	// serving them. SNMPv3 requests without context name are served by the device when there is only one.
+	Devices map[string]*Device
+	// User is the SNMPv3 user, SNMPv3 requests are ignored if nil
+	User *User
+	// EngineID is the authoritative engine ID of the simulator, a random one is used if empty
+	EngineID string
+}
+
+// Simulator is a running SNMP agent simulator
+type Simulator struct {
+	config    Config
+	conn      *net.UDPConn
+	decoder   *gosnmp.GoSNMP
+	usm       *gosnmp.UsmSecurityParameters
+	startTime time.Time
+	wg        sync.WaitGroup
+}
+
+// Start starts a simulator listening for SNMP requests on a UDP address, e.g. `127.0.0.1:1161`.
+// Use port 0 to listen on a random port and Addr to retrieve it.
+func Start(addr string, config Config) (*Simulator, error) {
+	if len(config.Devices) == 0 {
+		return nil, errors.New("no device to simulate")
+	}
+	if config.EngineID == "" {
+		random := make([]byte, 8)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("unable to generate engine ID: %w", err)
+		}
+		config.EngineID = engineIDPrefix + string(random)
+	}
+
+	usm := &gosnmp.UsmSecurityParameters{
+		AuthoritativeEngineID:    config.EngineID,
+		AuthoritativeEngineBoots: 1,
+	}
+	if config.User != nil {
+		usm.UserName = config.User.Name
+		usm.AuthenticationProtocol = config.User.AuthProtocol
+		usm.AuthenticationPassphrase = config.User.AuthKey
+		usm.PrivacyProtocol = config.User.PrivProtocol
+		usm.PrivacyPassphrase = config.User.PrivKey
+		if err := usm.InitSecurityKeys(); err != nil {
+			return nil, fmt.Errorf("invalid SNMPv3 user: %w", err)
+		}
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Simulator{
+		config: config,
+		conn:   conn,
+		decoder: &gosnmp.GoSNMP{
+			Version:            gosnmp.Version3,
+			SecurityModel:      gosnmp.UserSecurityModel,
+			SecurityParameters: usm,
+		},
+		usm:       usm,
+		startTime: time.Now(),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the simulator listens on
+func (s *Simulator) Addr() *net.UDPAddr {
+	return s.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Stop stops the simulator
+func (s *Simulator) Stop() {
+	_ = s.conn.Close()
+	s.wg.Wait()
+}
+
+func (s *Simulator) serve() {
+	defer s.wg.Done()
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Debugf("snmpsim: unable to read request: %s", err)
+			continue
+		}
+		out, err := s.handleMessage(buf[:n])
+		if err != nil {
+			log.Debugf("snmpsim: ignoring request from %s: %s", addr, err)
+			continue
+		}
+		if _, err := s.conn.WriteToUDP(out, addr); err != nil {
+			log.Debugf("snmpsim: unable to send response to %s: %s", addr, err)
+		}
+	}
+}
+
+// handleMessage decodes a request and returns the encoded response to send back
+func (s *Simulator) handleMessage(msg []byte) ([]byte, error) {
+	request, err := s.decoder.UnmarshalTrap(msg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var device *Device
+	if request.Version == gosnmp.Version3 {
+		if s.config.User == nil {
+			return nil, errors.New("SNMPv3 is not enabled")
+		}
+		sp, ok := request.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		if !ok {
+			return nil, errors.New("unsupported security model")
+		}
+		if sp.AuthoritativeEngineID != s.config.EngineID {
+			return s.buildDiscoveryReport(request, sp).MarshalMsg()
+		}
+		if sp.UserName != s.config.User.Name {
+			return nil, fmt.Errorf("unknown user %q", sp.UserName)
+		}
+		if request.MsgFlags&gosnmp.AuthPriv < s.securityLevel() {
+			return nil, fmt.Errorf("security level %s is lower than the one of user %q", request.MsgFlags&gosnmp.AuthPriv, sp.UserName)
+		}
+		device = s.config.Devices[request.ContextName]
+		if device == nil && request.ContextName == "" && len(s.config.Devices) == 1 {
+			for _, onlyDevice := range s.config.Devices {
+				device = onlyDevice
+			}
+		}
+		if device == nil {
+			return nil, fmt.Errorf("unknown context %q", request.ContextName)
+		}
+	} else {
+		device = s.config.Devices[request.Community]
+		if device == nil {
+			return nil, fmt.Errorf("unknown community %q", request.Community)
+		}
+	}
+
+	response := &gosnmp.SnmpPacket{
+		Version:            request.Version,
+		Community:          request.Community,
+		MsgID:              request.MsgID,
+		MsgFlags:           request.MsgFlags &^ gosnmp.Reportable,
+		SecurityModel:      request.SecurityModel,
+		SecurityParameters: request.SecurityParameters,
+		ContextEngineID:    request.ContextEngineID,
+		ContextName:        request.ContextName,
+		PDUType:            gosnmp.GetResponse,
+		RequestID:          request.RequestID,
+	}
+	if err := s.handlePDU(device, request, response); err != nil {
+		return nil, err
+	}
+	if request.Version == gosnmp.Version3 {
+		sp := response.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		sp.AuthoritativeEngineBoots = s.usm.AuthoritativeEngineBoots
+		sp.AuthoritativeEngineTime = s.engineTime()
+		if err := s.usm.InitPacket(response); err != nil {
+			return nil, err
+		}
+	}
+	return marshalResponse(request, response)
+}
+
+// marshalResponse encodes a response so that it fits in a datagram. As per RFC 3416, the trailing repetitions of
+// GetBulk responses are dropped, and the other responses that don't fit are replaced by a tooBig error.
+func marshalResponse(request *gosnmp.SnmpPacket, response *gosnmp.SnmpPacket) ([]byte, error) {
+	out, err := response.MarshalMsg()
+	if err != nil || len(out) <= maxMessageSize {
+		return out, err
+	}
+
+	if request.PDUType == gosnmp.GetBulkRequest {
+		nonRepeaters := min(int(request.NonRepeaters), len(request.Variables))
+		repeaters := len(request.Variables) - nonRepeaters
+		for len(out) > maxMessageSize && repeaters > 0 && len(response.Variables) > nonRepeaters {
+			// keep the whole repetitions that are expected to fit, and at least one less than now
+			keep := min(len(response.Variables)*maxMessageSize/len(out), len(response.Variables)-1)
+			keep = nonRepeaters + max(keep-nonRepeaters, 0)/repeaters*repeaters
+			response.Variables = response.Variables[:keep]
+			if out, err = response.MarshalMsg(); err != nil {
+				return nil, err
+			}
+		}
+		if len(out) <= maxMessageSize {
+			return out, nil
+		}
+	}
+
+	response.Variables = nil
+	if request.Version == gosnmp.Version1 {
+		response.Variables = request.Variables
+	}
+	response.Error = gosnmp.TooBig
+	response.ErrorIndex = 0
+	return response.MarshalMsg()
+}
+
+// handlePDU fills the variables of the response to a request
+func (s *Simulator) handlePDU(device *Device, request *gosnmp.SnmpPacket, response *gosnmp.SnmpPacket) error {
+	switch request.PDUType {
+	case gosnmp.GetRequest:
+		for i, variable := range request.Variables {
+			pdu, ok := device.Get(parseOID(variable.Name))
+			if !ok {
+				if request.Version == gosnmp.Version1 {
+					setV1Error(request, response, gosnmp.NoSuchName, i)
+					return nil
+				}
+				pdu = gosnmp.SnmpPDU{Name: variable.Name, Type: gosnmp.NoSuchObject}
+			}
+			response.Variables = append(response.Variables, pdu)
+		}
+	case gosnmp.GetNextRequest:
+		for i, variable := range request.Variables {
+			pdu, ok := device.GetNext(parseOID(variable.Name))
+			if !ok {
+				if request.Version == gosnmp.Version1 {
+					setV1Error(request, response, gosnmp.NoSuchName, i)
+					return nil
+				}
+				pdu = gosnmp.SnmpPDU{Name: variable.Name, Type: gosnmp.EndOfMibView}
+			}
+			response.Variables = append(response.Variables, pdu)
+		}
+	case gosnmp.GetBulkRequest:
+		if request.Version == gosnmp.Version1 {
+			return errors.New("GetBulk is not supported by SNMP v1")
+		}
+		nonRepeaters := min(int(request.NonRepeaters), len(request.Variables))
+		for _, variable := range request.Variables[:nonRepeaters] {
+			response.Variables = append(response.Variables, getNextOrEnd(device, variable.Name))
+		}
+		repeaters := make([]string, 0, len(request.Variables)-nonRepeaters)
+		for _, variable := range request.Variables[nonRepeaters:] {
+			repeaters = append(repeaters, variable.Name)
+		}
+		for repetition := 0; repetition < int(request.MaxRepetitions) && len(repeaters) > 0; repetition++ {
+			if len(response.Variables)+len(repeaters) > maxBulkVariables {
+				break
+			}
+			endOfMibView := true
+			for i, name := range repeaters {
+				pdu := getNextOrEnd(device, name)
+				if pdu.Type != gosnmp.EndOfMibView {
+					endOfMibView = false
+				}
+				response.Variables = append(response.Variables, pdu)
+				repeaters[i] = pdu.Name
+			}
+			if endOfMibView {
+				break
+			}
+		}
+	case gosnmp.SetRequest:
+		if request.Version == gosnmp.Version1 {
+			setV1Error(request, response, gosnmp.NoSuchName, 0)
+		} else {
+			response.Variables = request.Variables
+			response.Error = gosnmp.NotWritable
+			response.ErrorIndex = 1
+		}
+	default:
+		return fmt.Errorf("unsupported PDU type %s", request.PDUType)
+	}
+	return nil
+}
+
+// buildDiscoveryReport returns the report sent to SNMPv3 requests that don't use the engine ID
+// of the simulator, so that managers can discover it, along with its boots and time
+func (s *Simulator) buildDiscoveryReport(request *gosnmp.SnmpPacket, sp *gosnmp.UsmSecurityParameters) *gosnmp.SnmpPacket {
+	return &gosnmp.SnmpPacket{
+		Version:       gosnmp.Version3,
+		MsgID:         request.MsgID,
+		MsgFlags:      gosnmp.NoAuthNoPriv,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    s.config.EngineID,
+			AuthoritativeEngineBoots: s.usm.AuthoritativeEngineBoots,
+			AuthoritativeEngineTime:  s.engineTime(),
+			UserName:                 sp.UserName,
+		},
+		ContextEngineID: s.config.EngineID,
+		ContextName:     request.ContextName,
+		PDUType:         gosnmp.Report,
+		RequestID:       request.RequestID,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: usmStatsUnknownEngineIDs, Type: gosnmp.Counter32, Value: uint32(1)},
+		},
+	}
+}
+
+// securityLevel returns the minimum security level of the requests of the SNMPv3 user
+func (s *Simulator) securityLevel() gosnmp.SnmpV3MsgFlags {
+	switch {
+	case s.config.User.PrivProtocol > gosnmp.NoPriv:
+		return gosnmp.AuthPriv
+	case s.config.User.AuthProtocol > gosnmp.NoAuth:
+		return gosnmp.AuthNoPriv
+	}
+	return gosnmp.NoAuthNoPriv
+}
+
+func (s *Simulator) engineTime() uint32 {
+	return uint32(time.Since(s.startTime).Seconds())
+}
+
+// setV1Error sets an SNMP v1 error status on a response, which echoes the variables of the request
+func setV1Error(request *gosnmp.SnmpPacket, response *gosnmp.SnmpPacket, status gosnmp.SNMPError, index int) {
+	response.Variables = request.Variables
+	response.Error = status
+	response.ErrorIndex = uint8(index + 1)
+}
+
+func getNextOrEnd(device *Device, name string) gosnmp.SnmpPDU {
+	pdu, ok := device.GetNext(parseOID(name))
+	if !ok {
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView}
+	}
+	return pdu
+}
+
+// parseOID converts a request OID to ints, invalid OIDs are considered as the root of the MIB tree
+func parseOID(oid string) []int {
+	ints, err := gosnmplib.OIDToInts(strings.TrimLeft(oid, "."))
+	if err != nil {
+		return nil
+	}
+	return ints
+}
@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmpsim
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testUser = &User{
+	Name:         "datadog",
+	AuthProtocol: gosnmp.SHA,
+	AuthKey:      "auth-passphrase",
+	PrivProtocol: gosnmp.AES,
+	PrivKey:      "priv-passphrase",
+}
+
+func startTestSimulator(t *testing.T) *Simulator {
+	device, err := LoadDevice("testdata/device.snmprec")
+	require.NoError(t, err)
+	sim, err := Start("127.0.0.1:0", Config{
+		Devices: map[string]*Device{"public": device},
+		User:    testUser,
+	})
+	require.NoError(t, err)
+	t.Cleanup(sim.Stop)
+	return sim
+}
+
+func connect(t *testing.T, sim *Simulator, session *gosnmp.GoSNMP) *gosnmp.GoSNMP {
+	session.Target = sim.Addr().IP.String()
+	session.Port = uint16(sim.Addr().Port)
+	session.Timeout = 500 * time.Millisecond
+	session.Retries = 0
+	require.NoError(t, session.Connect())
+	t.Cleanup(func() { _ = session.Conn.Close() })
+	return session
+}
+
+func TestSimulatorV2c(t *testing.T) {
+	sim := startTestSimulator(t)
+	session := connect(t, sim, &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"})
+
+	result, err := session.Get([]string{".1.3.6.1.2.1.1.5.0", ".1.3.6.1.2.1.1.4.0", ".1.3.6.1.2.1.2.2.1.10.2"})
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.NoError, result.Error)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("sim-device")},
+		{Name: ".1.3.6.1.2.1.1.4.0", Type: gosnmp.NoSuchObject},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(2000)},
+	}, result.Variables)
+
+	result, err = session.GetNext([]string{".1.3.6.1.2.1.2.2.1.2", ".1.3.6.1.2.1.31.1.1.1.6.2"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.2", Type: gosnmp.EndOfMibView},
+	}, result.Variables)
+
+	result, err = session.GetBulk([]string{".1.3.6.1.2.1.1.1.0", ".1.3.6.1.2.1.2.2.1.2", ".1.3.6.1.2.1.2.2.1.10"}, 1, 2)
+	require.NoError(t, err)
+	var names []string
+	for _, variable := range result.Variables {
+		names = append(names, variable.Name)
+	}
+	assert.Equal(t, []string{
+		".1.3.6.1.2.1.1.2.0",
+		".1.3.6.1.2.1.2.2.1.2.1", ".1.3.6.1.2.1.2.2.1.10.1",
+		".1.3.6.1.2.1.2.2.1.2.2", ".1.3.6.1.2.1.2.2.1.10.2",
+	}, names)
+
+	walked, err := session.BulkWalkAll("")
+	require.NoError(t, err)
+	require.Len(t, walked, 13)
+	assert.Equal(t, gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"}, walked[10])
+	assert.Equal(t, gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.31.1.1.1.6.2", Type: gosnmp.Counter64, Value: uint64(98765432109876)}, walked[12])
+}
+
+func TestSimulatorLargeResponses(t *testing.T) {
+	var pdus []gosnmp.SnmpPDU
+	for i := 1; i <= 100; i++ {
+		pdus = append(pdus, gosnmp.SnmpPDU{
+			Name:  fmt.Sprintf(".1.3.6.1.2.1.2.2.1.2.%d", i),
+			Type:  gosnmp.OctetString,
+			Value: []byte(strings.Repeat("x", 2000)),
+		})
+	}
+	device, err := NewDevice(pdus)
+	require.NoError(t, err)
+	sim, err := Start("127.0.0.1:0", Config{Devices: map[string]*Device{"public": device}})
+	require.NoError(t, err)
+	t.Cleanup(sim.Stop)
+	session := connect(t, sim, &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"})
+
+	// the trailing repetitions that don't fit in a datagram are dropped
+	result, err := session.GetBulk([]string{".1.3.6.1.2.1.2.2.1.2"}, 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.NoError, result.Error)
+	assert.Greater(t, len(result.Variables), 10)
+	assert.Less(t, len(result.Variables), 33)
+	assert.Equal(t, ".1.3.6.1.2.1.2.2.1.2.1", result.Variables[0].Name)
+
+	// the other requests are answered with tooBig
+	var oids []string
+	for i := 1; i <= 40; i++ {
+		oids = append(oids, fmt.Sprintf(".1.3.6.1.2.1.2.2.1.2.%d", i))
+	}
+	result, err = session.Get(oids)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.TooBig, result.Error)
+	assert.Empty(t, result.Variables)
+}
+
+func TestSimulatorV1(t *testing.T) {
+	sim := startTestSimulator(t)
+	session := connect(t, sim, &gosnmp.GoSNMP{Version: gosnmp.Version1, Community: "public"})
+
+	result, err := session.Get([]string{".1.3.6.1.2.1.1.5.0", ".1.3.6.1.2.1.1.4.0"})
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.NoSuchName, result.Error)
+	assert.Equal(t, uint8(2), result.ErrorIndex)
+
+	walked, err := session.WalkAll(".1.3.6.1.2.1.2.2")
+	require.NoError(t, err)
+	assert.Len(t, walked, 6)
+}
+
+func TestSimulatorUnknownCommunity(t *testing.T) {
+	sim := startTestSimulator(t)
+	session := connect(t, sim, &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "private"})
+
+	_, err := session.Get([]string{".1.3.6.1.2.1.1.5.0"})
+	assert.Error(t, err)
+}
+
+func TestSimulatorV3(t *testing.T) {
+	sim := startTestSimulator(t)
+	session := connect(t, sim, &gosnmp.GoSNMP{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.AuthPriv,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 testUser.Name,
+			AuthenticationProtocol:   testUser.AuthProtocol,
+			AuthenticationPassphrase: testUser.AuthKey,
+			PrivacyProtocol:          testUser.PrivProtocol,
+			PrivacyPassphrase:        testUser.PrivKey,
+		},
+	})
+
+	result, err := session.Get([]string{".1.3.6.1.2.1.1.1.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Simulated device")},
+	}, result.Variables)
+
+	walked, err := session.BulkWalkAll(".1.3.6.1.2.1.2.2.1.2")
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: []byte("eth1\n")},
+	}, walked)
+}
+
+func TestSimulatorV3InvalidCredentials(t *testing.T) {
+	sim := startTestSimulator(t)
+	for name, sp := range map[string]*gosnmp.UsmSecurityParameters{
+		"wrong passphrase": {
+			UserName:                 testUser.Name,
+			AuthenticationProtocol:   testUser.AuthProtocol,
+			AuthenticationPassphrase: "wrong-passphrase",
+			PrivacyProtocol:          testUser.PrivProtocol,
+			PrivacyPassphrase:        testUser.PrivKey,
+		},
+		"unknown user": {
+			UserName:                 "unknown",
+			AuthenticationProtocol:   testUser.AuthProtocol,
+			AuthenticationPassphrase: testUser.AuthKey,
+			PrivacyProtocol:          testUser.PrivProtocol,
+			PrivacyPassphrase:        testUser.PrivKey,
+		},
+		"lower security level": {
+			UserName:                 testUser.Name,
+			AuthenticationProtocol:   testUser.AuthProtocol,
+			AuthenticationPassphrase: testUser.AuthKey,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			msgFlags := gosnmp.AuthPriv
+			if sp.PrivacyProtocol <= gosnmp.NoPriv {
+				msgFlags = gosnmp.AuthNoPriv
+			}
+			session := connect(t, sim, &gosnmp.GoSNMP{
+				Version:            gosnmp.Version3,
+				MsgFlags:           msgFlags,
+				SecurityModel:      gosnmp.UserSecurityModel,
+				SecurityParameters: sp,
+			})
+			_, err := session.Get([]string{".1.3.6.1.2.1.1.1.0"})
+			assert.Error(t, err)
+		})
+	}
+}
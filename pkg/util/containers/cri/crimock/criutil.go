@@ -9,6 +9,8 @@
 package crimock
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
@@ -30,6 +32,36 @@ func (m *MockCRIClient) GetContainerStats(containerID string) (*criv1.ContainerS
 	return args.Get(0).(*criv1.ContainerStats), args.Error(1)
 }
 
+// ListContainers is a mock of ListContainers
+func (m *MockCRIClient) ListContainers(ctx context.Context) ([]*criv1.Container, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*criv1.Container), args.Error(1)
+}
+
+// GetContainerStatus is a mock of GetContainerStatus
+func (m *MockCRIClient) GetContainerStatus(ctx context.Context, containerID string) (*criv1.ContainerStatusResponse, error) {
+	args := m.Called(ctx, containerID)
+	return args.Get(0).(*criv1.ContainerStatusResponse), args.Error(1)
+}
+
+// ListPodSandboxes is a mock of ListPodSandboxes
+func (m *MockCRIClient) ListPodSandboxes(ctx context.Context) ([]*criv1.PodSandbox, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*criv1.PodSandbox), args.Error(1)
+}
+
+// ListImages is a mock of ListImages
+func (m *MockCRIClient) ListImages(ctx context.Context) ([]*criv1.Image, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*criv1.Image), args.Error(1)
+}
+
+// GetImageStatus is a mock of GetImageStatus
+func (m *MockCRIClient) GetImageStatus(ctx context.Context, imageID string) (*criv1.ImageStatusResponse, error) {
+	args := m.Called(ctx, imageID)
+	return args.Get(0).(*criv1.ImageStatusResponse), args.Error(1)
+}
+
 // GetRuntime is a mock of GetRuntime
 func (m *MockCRIClient) GetRuntime() string {
 	return "fakeruntime"
@@ -34,6 +34,11 @@ var (
 type CRIClient interface {
 	ListContainerStats() (map[string]*criv1.ContainerStats, error)
 	GetContainerStats(containerID string) (*criv1.ContainerStats, error)
+	ListContainers(ctx context.Context) ([]*criv1.Container, error)
+	GetContainerStatus(ctx context.Context, containerID string) (*criv1.ContainerStatusResponse, error)
+	ListPodSandboxes(ctx context.Context) ([]*criv1.PodSandbox, error)
+	ListImages(ctx context.Context) ([]*criv1.Image, error)
+	GetImageStatus(ctx context.Context, imageID string) (*criv1.ImageStatusResponse, error)
 	GetRuntime() string
 	GetRuntimeVersion() string
 }
@@ -46,6 +51,7 @@ type CRIUtil struct {
 
 	sync.Mutex
 	clientV1          criv1.RuntimeServiceClient
+	imageClientV1     criv1.ImageServiceClient
 	runtime           string
 	runtimeVersion    string
 	queryTimeout      time.Duration
@@ -104,6 +110,20 @@ func (c *CRIUtil) init() error {
 	return nil
 }
 
+// NewCRIUtil returns a CRIUtil connected to the CRI socket at the given path,
+// it isn't shared and the connection isn't retried
+func NewCRIUtil(socketPath string, connectionTimeout, queryTimeout time.Duration) (*CRIUtil, error) {
+	c := &CRIUtil{
+		queryTimeout:      queryTimeout,
+		connectionTimeout: connectionTimeout,
+		socketPath:        socketPath,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 // GetUtil returns a ready to use CRIUtil. It is backed by a shared singleton.
 func GetUtil() (*CRIUtil, error) {
 	once.Do(func() {
@@ -148,6 +168,67 @@ func (c *CRIUtil) ListContainerStats() (map[string]*criv1.ContainerStats, error)
 	return c.listContainerStatsWithFilter(&criv1.ContainerStatsFilter{})
 }
 
+// ListContainers returns all the containers known by the runtime, including
+// the exited ones
+func (c *CRIUtil) ListContainers(ctx context.Context) ([]*criv1.Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	r, err := c.clientV1.ListContainers(ctx, &criv1.ListContainersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetContainers(), nil
+}
+
+// GetContainerStatus returns the status of a container, along with the
+// verbose information exposed by the runtime
+func (c *CRIUtil) GetContainerStatus(ctx context.Context, containerID string) (*criv1.ContainerStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	return c.clientV1.ContainerStatus(ctx, &criv1.ContainerStatusRequest{
+		ContainerId: containerID,
+		Verbose:     true,
+	})
+}
+
+// ListPodSandboxes returns all the pod sandboxes known by the runtime
+func (c *CRIUtil) ListPodSandboxes(ctx context.Context) ([]*criv1.PodSandbox, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	r, err := c.clientV1.ListPodSandbox(ctx, &criv1.ListPodSandboxRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetItems(), nil
+}
+
+// ListImages returns all the images stored by the runtime
+func (c *CRIUtil) ListImages(ctx context.Context) ([]*criv1.Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	r, err := c.imageClientV1.ListImages(ctx, &criv1.ListImagesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetImages(), nil
+}
+
+// GetImageStatus returns the status of an image, along with the verbose
+// information exposed by the runtime
+func (c *CRIUtil) GetImageStatus(ctx context.Context, imageID string) (*criv1.ImageStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	return c.imageClientV1.ImageStatus(ctx, &criv1.ImageStatusRequest{
+		Image:   &criv1.ImageSpec{Image: imageID},
+		Verbose: true,
+	})
+}
+
 // GetRuntime returns the CRI runtime
 func (c *CRIUtil) GetRuntime() string {
 	return c.runtime
@@ -163,6 +244,7 @@ func (c *CRIUtil) detectAPIVersion(conn *grpc.ClientConn) error {
 	defer cancel()
 
 	c.clientV1 = criv1.NewRuntimeServiceClient(conn)
+	c.imageClientV1 = criv1.NewImageServiceClient(conn)
 
 	_, err := c.clientV1.Version(ctx, &criv1.VersionRequest{})
 	return err
@@ -12,6 +12,7 @@
 package cri
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -56,6 +57,21 @@ func TestCRIUtilListContainerStats(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCRIUtilListContainersAndImages(t *testing.T) {
+	fakeRuntime, endpoint := createAndStartFakeRemoteRuntime(t)
+	defer fakeRuntime.Stop()
+	util, err := NewCRIUtil(strings.TrimPrefix(endpoint, "unix://"), 1*time.Second, 1*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "fakeRuntime", util.GetRuntime())
+
+	_, err = util.ListContainers(context.Background())
+	require.NoError(t, err)
+	_, err = util.ListPodSandboxes(context.Background())
+	require.NoError(t, err)
+	_, err = util.ListImages(context.Background())
+	require.NoError(t, err)
+}
+
 // createAndStartFakeRemoteRuntime creates and starts fakeremote.RemoteRuntime.
 // It returns the RemoteRuntime, endpoint on success.
 // Users should call fakeRuntime.Stop() to cleanup the server.
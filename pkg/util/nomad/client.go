@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package nomad provides a client for the HTTP API of the local Nomad agent.
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"time"
+)
+
+const (
+	// tokenHeader is the header used to authenticate against the Nomad API
+	tokenHeader = "X-Nomad-Token"
+
+	agentSelfPath       = "/agent/self"
+	nodeAllocationsPath = "/node/%s/allocations"
+)
+
+// Client is an interface for clients of the Nomad agent HTTP API.
+type Client interface {
+	// GetAgent returns the configuration of the local agent
+	GetAgent(ctx context.Context) (*Agent, error)
+	// GetNodeAllocations returns the allocations placed on the given node,
+	// across all namespaces
+	GetNodeAllocations(ctx context.Context, nodeID string) ([]Allocation, error)
+}
+
+type client struct {
+	agentURL   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new client for the Nomad agent listening on the given
+// URL. The token is optional, it is only required when the ACLs are enabled.
+func NewClient(agentURL, token string, timeout time.Duration) Client {
+	return &client{
+		agentURL:   agentURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetAgent returns the configuration of the local agent
+func (c *client) GetAgent(ctx context.Context) (*Agent, error) {
+	var a Agent
+	if err := c.get(ctx, agentSelfPath, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetNodeAllocations returns the allocations placed on the given node
+func (c *client) GetNodeAllocations(ctx context.Context, nodeID string) ([]Allocation, error) {
+	if nodeID == "" {
+		return nil, errors.New("empty Nomad node ID")
+	}
+
+	var allocs []Allocation
+	if err := c.get(ctx, fmt.Sprintf(nodeAllocationsPath, url.PathEscape(nodeID)), &allocs); err != nil {
+		return nil, err
+	}
+	return allocs, nil
+}
+
+func (c *client) makeURL(requestPath string) (string, error) {
+	u, err := url.Parse(c.agentURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join("/v1", requestPath)
+	return u.String(), nil
+}
+
+func (c *client) get(ctx context.Context, path string, v interface{}) error {
+	url, err := c.makeURL(path)
+	if err != nil {
+		return fmt.Errorf("error constructing Nomad request URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create new request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set(tokenHeader, c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status code in Nomad reply to %s: %d", path, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode Nomad JSON payload to type %s: %w", reflect.TypeOf(v), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNomadServer(t *testing.T, token string, files map[string]string) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		file, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestGetAgent(t *testing.T) {
+	ts := newNomadServer(t, "", map[string]string{
+		"/v1/agent/self": "./testdata/agent_self.json",
+	})
+
+	agent, err := NewClient(ts.URL, "", time.Second).GetAgent(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, &Agent{
+		Config: AgentConfig{
+			Datacenter: "dc1",
+			NodeName:   "nomad-client-1",
+			Region:     "global",
+		},
+		Stats: AgentStats{
+			Client: &AgentClientStats{
+				NodeID: "f7476465-4d6e-c0de-26d0-e383c49be941",
+			},
+		},
+	}, agent)
+}
+
+func TestGetNodeAllocations(t *testing.T) {
+	const nodeID = "f7476465-4d6e-c0de-26d0-e383c49be941"
+	ts := newNomadServer(t, "secret", map[string]string{
+		"/v1/node/" + nodeID + "/allocations": "./testdata/node_allocations.json",
+	})
+
+	allocs, err := NewClient(ts.URL, "secret", time.Second).GetNodeAllocations(context.Background(), nodeID)
+	require.NoError(t, err)
+	require.Len(t, allocs, 1)
+
+	alloc := allocs[0]
+	assert.Equal(t, "5456bd7a-9fc0-c0dd-6131-cbee77f57577", alloc.ID)
+	assert.Equal(t, "web.frontend[0]", alloc.Name)
+	assert.Equal(t, "default", alloc.Namespace)
+	assert.Equal(t, "web", alloc.JobID)
+	assert.Equal(t, "frontend", alloc.TaskGroup)
+	assert.Equal(t, "running", alloc.ClientStatus)
+	assert.Equal(t, map[string]TaskState{"nginx": {State: "running"}}, alloc.TaskStates)
+
+	require.NotNil(t, alloc.Job)
+	group := alloc.Job.TaskGroup("frontend")
+	require.NotNil(t, group)
+	assert.Equal(t, []Task{{Name: "nginx", Driver: "docker"}}, group.Tasks)
+	require.Len(t, group.Services, 1)
+	assert.Equal(t, "nginx", group.Services[0].Name)
+	assert.Equal(t, "http", group.Services[0].PortLabel)
+	assert.Equal(t, `["nginx"]`, group.Services[0].Meta["com.datadoghq.ad.check_names"])
+
+	assert.Nil(t, alloc.Job.TaskGroup("backend"))
+}
+
+func TestGetNodeAllocationsErrors(t *testing.T) {
+	ts := newNomadServer(t, "secret", map[string]string{})
+
+	_, err := NewClient(ts.URL, "secret", time.Second).GetNodeAllocations(context.Background(), "")
+	assert.Error(t, err)
+
+	_, err = NewClient(ts.URL, "wrong", time.Second).GetNodeAllocations(context.Background(), "node")
+	assert.ErrorContains(t, err, "403")
+
+	_, err = NewClient(ts.URL, "secret", time.Second).GetNodeAllocations(context.Background(), "node")
+	assert.ErrorContains(t, err, "404")
+}
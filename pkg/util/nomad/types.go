@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package nomad
+
+// Agent represents the configuration of the local Nomad agent, as returned by
+// the /v1/agent/self endpoint.
+type Agent struct {
+	Config AgentConfig `json:"config"`
+	Stats  AgentStats  `json:"stats"`
+}
+
+// AgentConfig represents the configuration of a Nomad agent.
+type AgentConfig struct {
+	Datacenter string `json:"Datacenter"`
+	NodeName   string `json:"NodeName"`
+	Region     string `json:"Region"`
+}
+
+// AgentStats represents the statistics of a Nomad agent. The client stats are
+// only set when the agent runs in client mode.
+type AgentStats struct {
+	Client *AgentClientStats `json:"client,omitempty"`
+}
+
+// AgentClientStats represents the statistics of a Nomad agent running in
+// client mode.
+type AgentClientStats struct {
+	NodeID string `json:"node_id"`
+}
+
+// Allocation represents a Nomad allocation, an instance of a task group of a
+// job placed on a client node.
+type Allocation struct {
+	ID           string               `json:"ID"`
+	Name         string               `json:"Name"`
+	Namespace    string               `json:"Namespace"`
+	NodeID       string               `json:"NodeID"`
+	NodeName     string               `json:"NodeName"`
+	JobID        string               `json:"JobID"`
+	TaskGroup    string               `json:"TaskGroup"`
+	ClientStatus string               `json:"ClientStatus"`
+	Job          *Job                 `json:"Job,omitempty"`
+	TaskStates   map[string]TaskState `json:"TaskStates,omitempty"`
+}
+
+// Job represents the specification of a Nomad job.
+type Job struct {
+	ID          string      `json:"ID"`
+	Name        string      `json:"Name"`
+	Namespace   string      `json:"Namespace"`
+	Datacenters []string    `json:"Datacenters"`
+	TaskGroups  []TaskGroup `json:"TaskGroups"`
+}
+
+// TaskGroup returns the task group of the job with the given name, or nil if
+// it doesn't exist.
+func (j *Job) TaskGroup(name string) *TaskGroup {
+	for i := range j.TaskGroups {
+		if j.TaskGroups[i].Name == name {
+			return &j.TaskGroups[i]
+		}
+	}
+	return nil
+}
+
+// TaskGroup represents a group of tasks of a Nomad job, which are placed
+// together in the same allocation.
+type TaskGroup struct {
+	Name     string    `json:"Name"`
+	Tasks    []Task    `json:"Tasks"`
+	Services []Service `json:"Services"`
+}
+
+// Task represents a task of a Nomad task group.
+type Task struct {
+	Name     string    `json:"Name"`
+	Driver   string    `json:"Driver"`
+	Services []Service `json:"Services"`
+}
+
+// Service represents a service registered by a Nomad task group or task.
+type Service struct {
+	Name      string            `json:"Name"`
+	PortLabel string            `json:"PortLabel"`
+	Provider  string            `json:"Provider"`
+	TaskName  string            `json:"TaskName"`
+	Tags      []string          `json:"Tags"`
+	Meta      map[string]string `json:"Meta"`
+}
+
+// TaskState represents the state of a task of an allocation.
+type TaskState struct {
+	State  string `json:"State"`
+	Failed bool   `json:"Failed"`
+}
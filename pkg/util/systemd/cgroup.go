@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package systemd
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ServiceUnitFromCgroupPath returns the name of the systemd service running
+// the processes of the given cgroup, or an empty string if the cgroup doesn't
+// belong to a service. Services may delegate sub-cgroups to their processes,
+// so the service is the deepest component of the path ending with `.service`.
+func ServiceUnitFromCgroupPath(path string) string {
+	components := strings.Split(path, "/")
+	for i := len(components) - 1; i >= 0; i-- {
+		if strings.HasSuffix(components[i], ".service") {
+			return components[i]
+		}
+	}
+	return ""
+}
+
+// serviceUnitFromProcCgroup returns the name of the systemd service running a
+// process from the content of its /proc/<pid>/cgroup file. systemd manages the
+// unified hierarchy with cgroup v2, and the named `systemd` hierarchy with
+// cgroup v1.
+func serviceUnitFromProcCgroup(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		if parts[1] == "name=systemd" || (parts[0] == "0" && parts[1] == "") {
+			if unit := ServiceUnitFromCgroupPath(parts[2]); unit != "" {
+				return unit
+			}
+		}
+	}
+	return ""
+}
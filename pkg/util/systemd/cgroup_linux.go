@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package systemd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kernel"
+)
+
+// ServiceUnitForPID returns the name of the systemd service running the given
+// process, or an empty string if the process doesn't belong to a service.
+func ServiceUnitForPID(pid int) (string, error) {
+	f, err := os.Open(kernel.HostProc(strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return serviceUnitFromProcCgroup(f), nil
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package systemd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceUnitFromCgroupPath(t *testing.T) {
+	for path, unit := range map[string]string{
+		"/system.slice/nginx.service":                                         "nginx.service",
+		"/system.slice/postgresql@16-main.service":                            "postgresql@16-main.service",
+		"/system.slice/containerd.service/supervisor":                         "containerd.service",
+		"/user.slice/user-1000.slice/session-2.scope":                         "",
+		"/user.slice/user-1000.slice/user@1000.service/app.slice/foo.service": "foo.service",
+		"/": "",
+	} {
+		assert.Equal(t, unit, ServiceUnitFromCgroupPath(path), path)
+	}
+}
+
+func TestServiceUnitFromProcCgroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		unit    string
+	}{
+		{
+			name:    "cgroup v2",
+			content: "0::/system.slice/nginx.service\n",
+			unit:    "nginx.service",
+		},
+		{
+			name: "cgroup v1",
+			content: `12:memory:/system.slice/nginx.service
+11:cpu,cpuacct:/system.slice/nginx.service
+1:name=systemd:/system.slice/nginx.service
+0::/system.slice/nginx.service
+`,
+			unit: "nginx.service",
+		},
+		{
+			name:    "container",
+			content: "0::/system.slice/docker-0e5ecd7c1c0b.scope\n",
+			unit:    "",
+		},
+		{
+			name:    "empty",
+			content: "",
+			unit:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.unit, serviceUnitFromProcCgroup(strings.NewReader(tt.content)))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"context"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+
+	"github.com/DataDog/datadog-agent/pkg/config/env"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// NewConnection connects to systemd. When privateSocket is empty, it connects
+// to the private socket of the host when running in a container, and
+// otherwise to the system bus, falling back to the private socket.
+func NewConnection(privateSocket string) (*dbus.Conn, error) {
+	if privateSocket != "" {
+		return NewSystemdConnection(privateSocket)
+	}
+
+	if env.IsContainerized() {
+		return NewSystemdConnection("/host" + DefaultPrivateSocket)
+	}
+
+	conn, err := dbus.NewSystemConnectionContext(context.Background())
+	if err == nil {
+		return conn, nil
+	}
+	log.Debugf("Error getting new connection using system bus socket: %v", err)
+
+	return NewSystemdConnection(DefaultPrivateSocket)
+}
@@ -9,7 +9,7 @@
 // Use of this source code is governed by Apache License 2.0
 // license that can be found here: https://github.com/coreos/go-systemd/blob/master/LICENSE
 
-//go:build systemd
+//go:build linux
 
 package systemd
 
@@ -22,6 +22,9 @@ import (
 	godbus "github.com/godbus/dbus/v5"
 )
 
+// DefaultPrivateSocket is the path of the private socket of systemd
+const DefaultPrivateSocket = "/run/systemd/private"
+
 // NewSystemdConnection establishes a private, direct connection to systemd.
 // This can be used for communicating with systemd without a dbus daemon.
 // Callers should call Close() when done with the connection.
@@ -0,0 +1,18 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package systemd
+
+// EntityPrefix is the prefix of the autodiscovery entity names of the
+// systemd units
+const EntityPrefix = "systemd_unit://"
+
+// BuildEntityName builds the autodiscovery entity name of a systemd unit.
+func BuildEntityName(unit string) string {
+	if unit == "" {
+		return ""
+	}
+	return EntityPrefix + unit
+}
@@ -89,6 +89,7 @@ AGENT_CORECHECKS = [
     "orchestrator_pod",
     "orchestrator_ecs",
     "cisco_sdwan",
+    "gnmi",
     "network_path",
     "service_discovery",
 ]